@@ -238,6 +238,77 @@ webServer:
   login:
     #登录模式
     version: $loginVersion
+  #ldap登录配置，登录模式为ldap时生效
+  #ldap:
+  #  #目录服务地址，ldap://或ldaps://开头
+  #  url: ldaps://ad.example.com:636
+  #  #ldap://地址是否使用StartTLS
+  #  startTLS: false
+  #  insecureSkipVerify: false
+  #  caFile: /data/cmdb/cert/ldap-ca.pem
+  #  #查询用户的服务账号
+  #  bindDN: CN=cmdb,OU=Service Accounts,DC=example,DC=com
+  #  bindPassword: password
+  #  baseDN: DC=example,DC=com
+  #  #查找登录用户的过滤条件，%s为用户名
+  #  userFilter: (&(objectCategory=person)(objectClass=user)(sAMAccountName=%s))
+  #  #可选择的用户列表的过滤条件
+  #  userListFilter: (&(objectCategory=person)(objectClass=user))
+  #  usernameAttribute: sAMAccountName
+  #  displayNameAttribute: displayName
+  #  emailAttribute: mail
+  #  phoneAttribute: telephoneNumber
+  #  groupAttribute: memberOf
+  #  #用户组与开发商、角色的映射，第一个匹配的映射为用户当前的开发商
+  #  groupMappings:
+  #    - group: CN=cmdb-admins,OU=Groups,DC=example,DC=com
+  #      supplier: "0"
+  #      role: admin
+  #  #不在任何映射用户组中的用户是否禁止登录
+  #  requireGroup: false
+  #  #连接池大小
+  #  poolSize: 10
+  #  #请求超时时间，单位秒
+  #  timeout: 10
+  #  #登录有效期，单位秒
+  #  sessionTTL: 86400
+  #  #用户列表缓存时间，单位秒
+  #  userListCacheTTL: 300
+  #oidc单点登录配置，登录模式为oidc时生效
+  #oidc:
+  #  #身份提供方地址，从issuer/.well-known/openid-configuration发现各个端点
+  #  issuer: https://sso.example.com/realms/cmdb
+  #  clientID: cmdb
+  #  clientSecret: secret
+  #  #回调地址，默认为site.domainUrl/login/callback
+  #  redirectURL: http://cmdb.example.com/login/callback
+  #  #退出登录后跳转的地址，默认为site.domainUrl
+  #  postLogoutRedirectURL: http://cmdb.example.com
+  #  #部分身份提供方需要offline_access才会返回refresh token
+  #  scopes: [openid, profile, email, offline_access]
+  #  #id token中映射为用户信息的字段，嵌套字段用.分隔
+  #  usernameClaim: preferred_username
+  #  displayNameClaim: name
+  #  emailClaim: email
+  #  phoneClaim: phone_number
+  #  languageClaim: locale
+  #  #用户所属开发商的字段，为数组时第一个为当前开发商，不配置则为默认开发商
+  #  ownerClaim: cmdb_supplier
+  #  insecureSkipVerify: false
+  #  caFile: /data/cmdb/cert/sso-ca.pem
+  #  #请求超时时间，单位秒
+  #  timeout: 10
+  #  #登录有效期，过期后使用refresh token续期，单位秒，0表示与id token有效期一致
+  #  sessionTTL: 0
+#apiserver专属配置
+apiServer:
+  # apiserver前的代理服务器的ip或cidr，只有来自这些代理的请求才从X-Forwarded-For头获取客户端ip，为空时使用连接的地址
+  trustedProxies: []
+#topo_server专属配置
+topoServer:
+  setTemplateSync:
+    # 集群模板同步摘要回调地址允许的主机(host或host:port)，为空时不允许配置回调地址
+    notifyHosts: []
 # operation_server专属配置
 operationServer:
   timer:
@@ -276,6 +347,52 @@ datacollection:
     rateLimiter:
       qps: 40
       burst: 100
+    # 主机快照历史记录，只有快照中同步到主机的字段发生变化时才会记录
+    history:
+      # 每台主机最多保留的历史记录条数，默认值为100，最小值为1
+      maxRecords: 100
+      # 历史记录保留的天数，默认值为30，最小值为1
+      retentionDays: 30
+    # 人工维护的主机字段，多个字段用逗号分隔，如bk_host_name,bk_os_name。这些字段不会被快照数据更新，
+    # 当快照上报的值与主机上的值不一致时，会记录到配置漂移报告中，默认为空
+    manualFields:
+    # 非gse采集器的主机快照数据源，内置node_exporter和osquery两种数据源，可通过/api/v3/collector/hostsnap/source/{source}/action/report上报，
+    # 配置channel后也会从snap redis的该频道订阅数据。未配置fields时使用内置数据源的字段映射，transforms可选值为trim,lower,int,first,count,sum,
+    # hz_to_mhz,kb_to_mb,bytes_to_mb,bytes_to_gb,os_type,os_bit。http上报必须使用reporters中服务账号的api token，
+    # 未配置reporters的数据源不能通过http上报，例如:
+    # sources:
+    #   - source: node_exporter
+    #     channel: node_exporter_snapshot
+    #     reporters: ["svc_node_exporter"]
+    #   - source: custom_agent
+    #     channel: custom_agent_snapshot
+    #     cloudIDPath: cloud_id
+    #     ipPaths: ["addrs.#.ip"]
+    #     fields:
+    #       - bk_property_id: bk_host_name
+    #         path: hostname
+    #         transforms: ["trim"]
+
+eventServer:
+  notification:
+    # 变更通知邮件渠道使用的smtp服务器，未配置host时邮件渠道的通知会投递失败，user为空时不进行认证，from为空时使用user作为发件人
+    smtp:
+      host:
+      port: 25
+      user:
+      password:
+      from:
+
+cacheService:
+  instance:
+    # 需要缓存实例的自定义模型，多个模型用逗号分隔，为空时不缓存，内置模型由其他缓存处理
+    objects:
+    # 每个模型最多缓存的实例数量，超出的实例直接从数据库查询
+    maxCount: 100000
+    # 单个实例详情最大缓存字节数，超出的实例直接从数据库查询
+    maxDetailSize: 65536
+    # 缓存与数据库一致性检查并修复的间隔(分钟)，为0时不进行周期检查
+    checkIntervalMinutes: 60
     '''
 
     template = FileTemplate(common_file_template_str)
@@ -96,6 +96,15 @@ func (am *AuthManager) batchAuthorize(ctx context.Context, header http.Header, r
 	return nil
 }
 
+// AuthorizeResources authorizes the resources that are not parsed from the request, such as the
+// changes planned by the server.
+func (am *AuthManager) AuthorizeResources(ctx context.Context, header http.Header, resources ...meta.ResourceAttribute) error {
+	if !am.Enabled() || len(resources) == 0 {
+		return nil
+	}
+	return am.batchAuthorize(ctx, header, resources...)
+}
+
 func (am *AuthManager) Enabled() bool {
 	return auth.EnableAuthorize()
 }
@@ -0,0 +1,148 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package extensions
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"configcenter/src/ac/meta"
+	"configcenter/src/ac/parser"
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+)
+
+// GetNoPermissionRestrictedFields get the restricted fields which user has no permission to operate with action,
+// action can be meta.ViewRestrictedField or meta.EditRestrictedField, returns object id -> property ids.
+func (am *AuthManager) GetNoPermissionRestrictedFields(ctx context.Context, header http.Header, action meta.Action,
+	objIDs ...string) (map[string][]string, error) {
+
+	if !am.Enabled() || len(objIDs) == 0 {
+		return nil, nil
+	}
+	rid := util.ExtractRequestIDFromContext(ctx)
+
+	query := &metadata.QueryCondition{
+		Condition: mapstr.MapStr{
+			common.BKObjIDField:                 mapstr.MapStr{common.BKDBIN: util.StrArrayUnique(objIDs)},
+			metadata.AttributeFieldIsRestricted: true,
+		},
+		Fields: []string{common.BKObjIDField, common.BKPropertyIDField},
+		Page:   metadata.BasePage{Limit: common.BKNoLimit},
+	}
+	attrResp, err := am.clientSet.CoreService().Model().ReadModelAttrByCondition(ctx, header, query)
+	if err != nil {
+		blog.Errorf("get restricted attributes failed, objIDs: %v, err: %v, rid: %s", objIDs, err, rid)
+		return nil, err
+	}
+	if !attrResp.Result {
+		blog.Errorf("get restricted attributes failed, objIDs: %v, err: %s, rid: %s", objIDs, attrResp.ErrMsg, rid)
+		return nil, fmt.Errorf("get restricted attributes failed, err: %s", attrResp.ErrMsg)
+	}
+	if len(attrResp.Data.Info) == 0 {
+		return nil, nil
+	}
+
+	restricted := make(map[string][]string)
+	for _, attr := range attrResp.Data.Info {
+		restricted[attr.ObjectID] = append(restricted[attr.ObjectID], attr.PropertyID)
+	}
+
+	restrictedObjIDs := make([]string, 0)
+	for objID := range restricted {
+		restrictedObjIDs = append(restrictedObjIDs, objID)
+	}
+	objects, err := am.collectObjectsByObjectIDs(ctx, header, 0, restrictedObjIDs...)
+	if err != nil {
+		blog.Errorf("get restricted attribute objects failed, objIDs: %v, err: %v, rid: %s", restrictedObjIDs, err, rid)
+		return nil, err
+	}
+	resources, err := am.MakeResourcesByObjects(ctx, header, action, objects...)
+	if err != nil {
+		return nil, err
+	}
+
+	commonInfo, err := parser.ParseCommonInfo(&header)
+	if err != nil {
+		return nil, fmt.Errorf("authentication failed, parse user info from header failed, err: %+v", err)
+	}
+	decisions, err := am.Authorizer.AuthorizeBatch(ctx, header, commonInfo.User, resources...)
+	if err != nil {
+		blog.Errorf("authorize restricted fields failed, action: %s, err: %v, rid: %s", action, err, rid)
+		return nil, fmt.Errorf("authorize failed, err: %+v", err)
+	}
+
+	noPermission := make(map[string][]string)
+	for idx, decision := range decisions {
+		if decision.Authorized {
+			continue
+		}
+		objID := objects[idx].ObjectID
+		noPermission[objID] = restricted[objID]
+	}
+	return noPermission, nil
+}
+
+// FilterRestrictedFields remove the restricted fields that user has no permission to view from instances
+func (am *AuthManager) FilterRestrictedFields(ctx context.Context, header http.Header, objID string,
+	instances ...mapstr.MapStr) error {
+
+	if !am.Enabled() || len(instances) == 0 {
+		return nil
+	}
+
+	noPermission, err := am.GetNoPermissionRestrictedFields(ctx, header, meta.ViewRestrictedField, objID)
+	if err != nil {
+		return err
+	}
+	RemoveRestrictedFields(noPermission[objID], instances...)
+	return nil
+}
+
+// CheckRestrictedFieldsEdit returns the restricted fields in data that user has no permission to edit
+func (am *AuthManager) CheckRestrictedFieldsEdit(ctx context.Context, header http.Header, objID string,
+	data ...mapstr.MapStr) ([]string, error) {
+
+	if !am.Enabled() || len(data) == 0 {
+		return nil, nil
+	}
+
+	noPermission, err := am.GetNoPermissionRestrictedFields(ctx, header, meta.EditRestrictedField, objID)
+	if err != nil {
+		return nil, err
+	}
+
+	denied := make([]string, 0)
+	for _, field := range noPermission[objID] {
+		for _, item := range data {
+			if _, exist := item[field]; exist {
+				denied = append(denied, field)
+				break
+			}
+		}
+	}
+	return denied, nil
+}
+
+// RemoveRestrictedFields remove the fields from instances
+func RemoveRestrictedFields(fields []string, instances ...mapstr.MapStr) {
+	for _, field := range fields {
+		for _, inst := range instances {
+			delete(inst, field)
+		}
+	}
+}
@@ -338,6 +338,9 @@ var resourceActionMap = map[meta.ResourceType]map[meta.Action]ActionID{
 		meta.Create:   CreateSysModel,
 		meta.Find:     Skip,
 		meta.FindMany: Skip,
+
+		meta.ViewRestrictedField: ViewSysModelRestrictedField,
+		meta.EditRestrictedField: EditSysModelRestrictedField,
 	},
 	meta.AssociationType: {
 		meta.Delete:   DeleteAssociationType,
@@ -369,12 +372,13 @@ var resourceActionMap = map[meta.ResourceType]map[meta.Action]ActionID{
 		meta.ModelTopologyOperation: EditBusinessLayer,
 	},
 	meta.EventWatch: {
-		meta.WatchHost:         WatchHostEvent,
-		meta.WatchHostRelation: WatchHostRelationEvent,
-		meta.WatchBiz:          WatchBizEvent,
-		meta.WatchSet:          WatchSetEvent,
-		meta.WatchModule:       WatchModuleEvent,
-		meta.WatchSetTemplate:  WatchSetTemplateEvent,
+		meta.WatchHost:              WatchHostEvent,
+		meta.WatchHostRelation:      WatchHostRelationEvent,
+		meta.WatchBiz:               WatchBizEvent,
+		meta.WatchSet:               WatchSetEvent,
+		meta.WatchModule:            WatchModuleEvent,
+		meta.WatchSetTemplate:       WatchSetTemplateEvent,
+		meta.WatchHostSnapshotDrift: WatchHostSnapshotDriftEvent,
 	},
 	meta.UserCustom: {
 		meta.Find:   Skip,
@@ -333,6 +333,9 @@ func genResourceManageActionGroups() []ActionGroup {
 						{
 							ID: WatchSetTemplateEvent,
 						},
+						{
+							ID: WatchHostSnapshotDriftEvent,
+						},
 					},
 				},
 			},
@@ -386,6 +389,12 @@ func genModelManageActionGroups() []ActionGroup {
 						{
 							ID: DeleteSysModel,
 						},
+						{
+							ID: ViewSysModelRestrictedField,
+						},
+						{
+							ID: EditSysModelRestrictedField,
+						},
 					},
 				},
 				{
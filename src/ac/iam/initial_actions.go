@@ -976,6 +976,26 @@ func genModelActions() []ResourceAction {
 		Version:              1,
 	})
 
+	actions = append(actions, ResourceAction{
+		ID:                   ViewSysModelRestrictedField,
+		Name:                 "模型受限字段查看",
+		NameEn:               "View Model Restricted Field",
+		Type:                 View,
+		RelatedResourceTypes: relatedResource,
+		RelatedActions:       nil,
+		Version:              1,
+	})
+
+	actions = append(actions, ResourceAction{
+		ID:                   EditSysModelRestrictedField,
+		Name:                 "模型受限字段编辑",
+		NameEn:               "Edit Model Restricted Field",
+		Type:                 Edit,
+		RelatedResourceTypes: relatedResource,
+		RelatedActions:       nil,
+		Version:              1,
+	})
+
 	return actions
 }
 
@@ -1209,6 +1229,16 @@ func genEventWatchActions() []ResourceAction {
 		RelatedActions:       nil,
 		Version:              1,
 	})
+
+	actions = append(actions, ResourceAction{
+		ID:                   WatchHostSnapshotDriftEvent,
+		Name:                 "主机配置漂移事件监听",
+		NameEn:               "Host Snapshot Drift Event Listen",
+		Type:                 View,
+		RelatedResourceTypes: nil,
+		RelatedActions:       nil,
+		Version:              1,
+	})
 	return actions
 }
 
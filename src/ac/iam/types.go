@@ -286,6 +286,9 @@ const (
 	EditSysModel   ActionID = "edit_sys_model"
 	DeleteSysModel ActionID = "delete_sys_model"
 
+	ViewSysModelRestrictedField ActionID = "view_sys_model_restricted_field"
+	EditSysModelRestrictedField ActionID = "edit_sys_model_restricted_field"
+
 	CreateAssociationType ActionID = "create_association_type"
 	EditAssociationType   ActionID = "edit_association_type"
 	DeleteAssociationType ActionID = "delete_association_type"
@@ -303,13 +306,14 @@ const (
 
 	FindAuditLog ActionID = "find_audit_log"
 
-	WatchHostEvent         ActionID = "watch_host_event"
-	WatchHostRelationEvent ActionID = "watch_host_relation_event"
-	WatchBizEvent          ActionID = "watch_biz_event"
-	WatchSetEvent          ActionID = "watch_set_event"
-	WatchModuleEvent       ActionID = "watch_module_event"
-	WatchSetTemplateEvent  ActionID = "watch_set_template_event"
-	GlobalSettings         ActionID = "global_settings"
+	WatchHostEvent              ActionID = "watch_host_event"
+	WatchHostRelationEvent      ActionID = "watch_host_relation_event"
+	WatchBizEvent               ActionID = "watch_biz_event"
+	WatchSetEvent               ActionID = "watch_set_event"
+	WatchModuleEvent            ActionID = "watch_module_event"
+	WatchSetTemplateEvent       ActionID = "watch_set_template_event"
+	WatchHostSnapshotDriftEvent ActionID = "watch_host_snapshot_drift_event"
+	GlobalSettings              ActionID = "global_settings"
 
 	// Unknown is an action that can not be recognized
 	Unsupported ActionID = "unsupported"
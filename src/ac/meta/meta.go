@@ -123,12 +123,17 @@ const (
 	ModelTopologyOperation Action = "modelTopologyOperation"
 
 	// event watch
-	WatchHost         Action = "host"
-	WatchHostRelation Action = "host_relation"
-	WatchBiz          Action = "biz"
-	WatchSet          Action = "set"
-	WatchModule       Action = "module"
-	WatchSetTemplate  Action = "set_template"
+	WatchHost              Action = "host"
+	WatchHostRelation      Action = "host_relation"
+	WatchBiz               Action = "biz"
+	WatchSet               Action = "set"
+	WatchModule            Action = "module"
+	WatchSetTemplate       Action = "set_template"
+	WatchHostSnapshotDrift Action = "host_snapshot_drift"
+
+	// restricted fields of a model
+	ViewRestrictedField Action = "viewRestrictedField"
+	EditRestrictedField Action = "editRestrictedField"
 
 	// can view business related resources, including business and business collection resources
 	ViewBusinessResource Action = "viewBusinessResource"
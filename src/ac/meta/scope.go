@@ -0,0 +1,92 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package meta
+
+import (
+	"fmt"
+	"strings"
+)
+
+// APITokenAccess is the access level of an api token scope
+type APITokenAccess string
+
+const (
+	// APITokenRead allows the actions that only read the resources
+	APITokenRead APITokenAccess = "read"
+	// APITokenWrite allows all the actions, including the read actions
+	APITokenWrite APITokenAccess = "write"
+)
+
+// AnyResourceType matches all the resource types in an api token scope
+const AnyResourceType ResourceType = "*"
+
+// APITokenScope is the permission scope of an api token, in the format of resourceType:read or resourceType:write
+type APITokenScope struct {
+	Type   ResourceType
+	Access APITokenAccess
+}
+
+// ParseAPITokenScope parses the scope like hostInstance:read, *:write
+func ParseAPITokenScope(scope string) (APITokenScope, error) {
+	fields := strings.Split(scope, ":")
+	if len(fields) != 2 {
+		return APITokenScope{}, fmt.Errorf("scope %s should be in the format of resource:read or resource:write", scope)
+	}
+
+	s := APITokenScope{Type: ResourceType(fields[0]), Access: APITokenAccess(fields[1])}
+	if s.Type != AnyResourceType && !isResourceType(s.Type) {
+		return s, fmt.Errorf("scope %s has unknown resource type %s", scope, s.Type)
+	}
+	if s.Access != APITokenRead && s.Access != APITokenWrite {
+		return s, fmt.Errorf("scope %s has unknown access %s", scope, s.Access)
+	}
+	return s, nil
+}
+
+func (s APITokenScope) String() string {
+	return string(s.Type) + ":" + string(s.Access)
+}
+
+// Allow checks if the resource can be accessed with the scope, the resource without a type can only be accessed
+// by the scope of any resource type.
+func (s APITokenScope) Allow(resourceType ResourceType, access APITokenAccess) bool {
+	if s.Type != AnyResourceType && s.Type != resourceType {
+		return false
+	}
+	return s.Access == APITokenWrite || access == APITokenRead
+}
+
+// IsReadAction checks if the action only reads the resources, the skip and unknown actions are not decided by
+// the action itself.
+func IsReadAction(action Action) bool {
+	switch action {
+	case Find, FindMany, ModelTopologyView, ViewBusinessResource,
+		WatchHost, WatchHostRelation, WatchBiz, WatchSet, WatchModule, WatchSetTemplate, WatchHostSnapshotDrift:
+		return true
+	}
+	return false
+}
+
+func isResourceType(resourceType ResourceType) bool {
+	switch resourceType {
+	case Business, Model, ModelModule, ModelSet, MainlineModel, MainlineModelTopology, MainlineInstanceTopology,
+		MainlineInstance, AssociationType, ModelAssociation, ModelInstanceAssociation, ModelInstance,
+		ModelInstanceTopology, ModelTopology, ModelClassification, ModelAttributeGroup, ModelAttribute, ModelUnique,
+		HostFavorite, Process, ProcessServiceCategory, ProcessServiceTemplate, ProcessTemplate,
+		ProcessServiceInstance, BizTopology, HostInstance, NetDataCollector, DynamicGrouping, EventPushing,
+		EventWatch, CloudAreaInstance, AuditLog, UserCustom, SystemBase, InstallBK, SystemConfig, SetTemplate,
+		OperationStatistic, HostApply, ResourcePoolDirectory, CloudAccount, CloudResourceTask, ConfigAdmin:
+		return true
+	}
+	return false
+}
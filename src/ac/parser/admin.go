@@ -24,6 +24,10 @@ func (ps *parseStream) adminRelated() *parseStream {
 	}
 
 	ps.ConfigAdmin()
+	ps.apiToken()
+	ps.admissionWebhook()
+	ps.approval()
+	ps.changeFreeze()
 
 	return ps
 }
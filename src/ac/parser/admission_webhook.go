@@ -0,0 +1,64 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parser
+
+import (
+	"net/http"
+	"regexp"
+
+	"configcenter/src/ac/meta"
+)
+
+// the admission webhooks review the writes of all the users, so they are managed with the global settings permission
+var AdmissionWebhookConfigs = []AuthConfig{
+	{
+		Name:           "createAdmissionWebhook",
+		Description:    "创建准入webhook",
+		Pattern:        "/api/v3/create/admission_webhook",
+		HTTPMethod:     http.MethodPost,
+		ResourceType:   meta.ConfigAdmin,
+		ResourceAction: meta.Update,
+	}, {
+		Name:           "updateAdmissionWebhook",
+		Description:    "更新准入webhook",
+		Regex:          regexp.MustCompile(`^/api/v3/update/admission_webhook/[0-9]+/?$`),
+		HTTPMethod:     http.MethodPut,
+		ResourceType:   meta.ConfigAdmin,
+		ResourceAction: meta.Update,
+	}, {
+		Name:           "deleteAdmissionWebhook",
+		Description:    "删除准入webhook",
+		Regex:          regexp.MustCompile(`^/api/v3/delete/admission_webhook/[0-9]+/?$`),
+		HTTPMethod:     http.MethodDelete,
+		ResourceType:   meta.ConfigAdmin,
+		ResourceAction: meta.Update,
+	}, {
+		Name:           "findAdmissionWebhook",
+		Description:    "查询准入webhook",
+		Pattern:        "/api/v3/findmany/admission_webhook",
+		HTTPMethod:     http.MethodPost,
+		ResourceType:   meta.ConfigAdmin,
+		ResourceAction: meta.Find,
+	}, {
+		Name:           "findAdmissionWebhookLog",
+		Description:    "查询准入webhook决策日志",
+		Pattern:        "/api/v3/findmany/admission_webhook/log",
+		HTTPMethod:     http.MethodPost,
+		ResourceType:   meta.ConfigAdmin,
+		ResourceAction: meta.Find,
+	},
+}
+
+func (ps *parseStream) admissionWebhook() *parseStream {
+	return ParseStreamWithFramework(ps, AdmissionWebhookConfigs)
+}
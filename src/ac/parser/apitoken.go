@@ -0,0 +1,186 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parser
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+
+	"configcenter/src/ac/meta"
+	"configcenter/src/common/metadata"
+)
+
+const (
+	createAPITokenPattern = "/api/v3/create/api_token"
+	findAPITokenPattern   = "/api/v3/findmany/api_token"
+)
+
+var revokeAPITokenRegexp = regexp.MustCompile(`^/api/v3/update/api_token/[0-9]+/revoke/?$`)
+
+// apiToken parses the api token management apis served by apiserver, the users manage their own tokens,
+// and the service account tokens need the global settings permission.
+func (ps *parseStream) apiToken() *parseStream {
+	if ps.shouldReturn() {
+		return ps
+	}
+
+	if ps.hitPattern(createAPITokenPattern, http.MethodPost) {
+		kind, err := ps.RequestCtx.getValueFromBody("kind")
+		if err != nil {
+			ps.err = err
+			return ps
+		}
+		if kind.String() == string(metadata.APITokenKindService) {
+			ps.Attribute.Resources = []meta.ResourceAttribute{
+				{
+					Basic: meta.Basic{
+						Type:   meta.ConfigAdmin,
+						Action: meta.Update,
+					},
+				},
+			}
+			return ps
+		}
+		ps.Attribute.Resources = []meta.ResourceAttribute{
+			{
+				Basic: meta.Basic{
+					Action: meta.SkipAction,
+				},
+			},
+		}
+		return ps
+	}
+
+	if ps.hitPattern(findAPITokenPattern, http.MethodPost) || ps.hitRegexp(revokeAPITokenRegexp, http.MethodPut) {
+		ps.Attribute.Resources = []meta.ResourceAttribute{
+			{
+				Basic: meta.Basic{
+					Action: meta.SkipAction,
+				},
+			},
+		}
+		return ps
+	}
+
+	return ps
+}
+
+// IsAPITokenManageRequest checks if the request manages the api tokens
+func IsAPITokenManageRequest(req *http.Request) bool {
+	path := strings.TrimSuffix(req.URL.Path, "/")
+	return path == createAPITokenPattern || path == findAPITokenPattern || revokeAPITokenRegexp.MatchString(path)
+}
+
+// readOnlyPostPatterns and readOnlyPostRegexps are the POST apis which only read the resources but may be parsed with
+// the skip action, the other requests except GET with the skip or unknown actions are regarded as writes.
+var readOnlyPostPatterns = []string{
+	findAPITokenPattern,
+	"/api/v3/findmany/approval_request",
+	"/api/v3/findmany/cloud/account/validity",
+	"/api/v3/findmany/cloud/account",
+	"/api/v3/findmany/cloud/sync/task",
+	"/api/v3/findmany/cloud/sync/region",
+	"/api/v3/findmany/proc/proc_template",
+	getCloudResourceDirectoryPattern,
+	findNotifyRulePattern,
+	findNotifyDeliveryLogPattern,
+	queryHostLockPattern,
+	findHostSnapshotBatchPattern,
+	findHostSnapshotHistoryPattern,
+	findHostSnapshotDriftPattern,
+	searchTopologyTreePattern,
+	fullTextSearchPattern,
+	findManyCloudAreaPattern,
+	findCloudAreaHostCountPattern,
+}
+
+var readOnlyPostRegexps = []*regexp.Regexp{
+	regexp.MustCompile(`^/api/v3/findmany/cloud/account/vpc/([0-9]+)$`),
+	regexp.MustCompile(`^/api/v3/findmany/host_apply_rule/bk_biz_id/([0-9]+)/?$`),
+	regexp.MustCompile(`^/api/v3/findmany/host_apply_rule/bk_biz_id/([0-9]+)/host_related_rules/?$`),
+	findSubscribeRegexp,
+	findIdentifierAPIRegexp,
+	findObjectInstanceAssociationLatestRegexp,
+	findObjectInstancesLatestRegexp,
+	findBusinessTopoNodePathRegexp,
+	findBusinessInstanceTopologyLatestRegexp,
+	findBusinessInstanceTopologyPathRegexp,
+	findBusinessInstanceTopologyWithStatisticsLatestRegexp,
+	findBusinessRegexp,
+	findResourcePoolBusinessRegexp,
+}
+
+// isReadRequest decides the access of the request by its method and url when the resource action is skip or unknown,
+// only the GET requests and the POST requests in the read only list are regarded as reads.
+func isReadRequest(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet:
+		return true
+	case http.MethodPost:
+	default:
+		return false
+	}
+
+	path := strings.TrimSuffix(req.URL.Path, "/")
+	for _, pattern := range readOnlyPostPatterns {
+		if path == pattern {
+			return true
+		}
+	}
+	for _, reg := range readOnlyPostRegexps {
+		if reg.MatchString(req.URL.Path) {
+			return true
+		}
+	}
+	return false
+}
+
+// resourceAccess decides if the resource is only read by the request, the skip and unknown actions are decided by
+// the request itself.
+func resourceAccess(resource meta.ResourceAttribute, readRequest bool) meta.APITokenAccess {
+	switch resource.Action {
+	case meta.SkipAction, meta.Unknown, meta.EmptyAction:
+		if readRequest {
+			return meta.APITokenRead
+		}
+	default:
+		if meta.IsReadAction(resource.Action) {
+			return meta.APITokenRead
+		}
+	}
+	return meta.APITokenWrite
+}
+
+// CheckAPITokenScopes checks the resources of the request are all allowed by the api token scopes,
+// returns the first resource that is out of the scopes.
+func CheckAPITokenScopes(req *http.Request, attribute *meta.AuthAttribute,
+	scopes []meta.APITokenScope) (meta.ResourceAttribute, bool) {
+
+	readRequest := isReadRequest(req)
+	for _, resource := range attribute.Resources {
+		access := resourceAccess(resource, readRequest)
+
+		allowed := false
+		for _, scope := range scopes {
+			if scope.Allow(resource.Type, access) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return resource, false
+		}
+	}
+	return meta.ResourceAttribute{}, true
+}
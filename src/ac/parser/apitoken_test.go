@@ -0,0 +1,81 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parser
+
+import (
+	"net/http"
+	"testing"
+
+	"configcenter/src/ac/meta"
+)
+
+func TestCheckAPITokenScopes(t *testing.T) {
+	scopes := make([]meta.APITokenScope, 0)
+	for _, s := range []string{"hostInstance:read", "business:write"} {
+		scope, err := meta.ParseAPITokenScope(s)
+		if err != nil {
+			t.Fatalf("parse scope %s failed, err: %v", s, err)
+		}
+		scopes = append(scopes, scope)
+	}
+
+	cases := []struct {
+		method   string
+		url      string
+		resource meta.ResourceAttribute
+		allowed  bool
+	}{
+		{http.MethodPost, "/api/v3/hosts/search", meta.ResourceAttribute{Basic: meta.Basic{Type: meta.HostInstance, Action: meta.FindMany}}, true},
+		{http.MethodPut, "/api/v3/hosts/batch", meta.ResourceAttribute{Basic: meta.Basic{Type: meta.HostInstance, Action: meta.Update}}, false},
+		{http.MethodPut, "/api/v3/biz/0/1", meta.ResourceAttribute{Basic: meta.Basic{Type: meta.Business, Action: meta.Update}}, true},
+		{http.MethodPost, "/api/v3/find/topoinst/biz/1", meta.ResourceAttribute{Basic: meta.Basic{Action: meta.SkipAction}}, false},
+		{http.MethodPost, "/api/v3/create/instance/object/x", meta.ResourceAttribute{Basic: meta.Basic{Type: meta.ModelInstance, Action: meta.Create}}, false},
+	}
+	for _, c := range cases {
+		req, _ := http.NewRequest(c.method, c.url, nil)
+		attribute := &meta.AuthAttribute{Resources: []meta.ResourceAttribute{c.resource}}
+		if _, ok := CheckAPITokenScopes(req, attribute, scopes); ok != c.allowed {
+			t.Errorf("%s %s should be allowed: %v, but got %v", c.method, c.url, c.allowed, ok)
+		}
+	}
+
+	anyRead, _ := meta.ParseAPITokenScope("*:read")
+	req, _ := http.NewRequest(http.MethodPost, "/api/v3/find/topoinst/biz/1", nil)
+	attribute := &meta.AuthAttribute{Resources: []meta.ResourceAttribute{{Basic: meta.Basic{Action: meta.SkipAction}}}}
+	if _, ok := CheckAPITokenScopes(req, attribute, []meta.APITokenScope{anyRead}); !ok {
+		t.Errorf("skip action of find request should be allowed by *:read")
+	}
+	req, _ = http.NewRequest(http.MethodDelete, "/api/v3/delete/instance/object/x", nil)
+	if _, ok := CheckAPITokenScopes(req, attribute, []meta.APITokenScope{anyRead}); ok {
+		t.Errorf("skip action of delete request should not be allowed by *:read")
+	}
+	// the write routes are not regarded as reads even if they have find like elements
+	for _, url := range []string{"/api/v3/findmany/cloud/account/delete", "/api/v3/hosts/search/clear",
+		"/api/v3/update/listener/1"} {
+		req, _ = http.NewRequest(http.MethodPost, url, nil)
+		if _, ok := CheckAPITokenScopes(req, attribute, []meta.APITokenScope{anyRead}); ok {
+			t.Errorf("skip action of write request %s should not be allowed by *:read", url)
+		}
+	}
+	req, _ = http.NewRequest(http.MethodPost, "/api/v3/findmany/cloudarea/", nil)
+	if _, ok := CheckAPITokenScopes(req, attribute, []meta.APITokenScope{anyRead}); !ok {
+		t.Errorf("skip action of read only post request should be allowed by *:read")
+	}
+
+	if _, err := meta.ParseAPITokenScope("notExist:read"); err == nil {
+		t.Errorf("unknown resource type should be rejected")
+	}
+	if _, err := meta.ParseAPITokenScope("hostInstance:admin"); err == nil {
+		t.Errorf("unknown access should be rejected")
+	}
+}
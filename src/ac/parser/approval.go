@@ -0,0 +1,79 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parser
+
+import (
+	"net/http"
+	"regexp"
+
+	"configcenter/src/ac/meta"
+)
+
+// the approval policies guard the sensitive operations of all the users, so they are managed with the global settings
+// permission. the approval requests are checked by topo server, only the approvers can approve or reject them.
+var ApprovalConfigs = []AuthConfig{
+	{
+		Name:           "createApprovalPolicy",
+		Description:    "创建审批策略",
+		Pattern:        "/api/v3/create/approval_policy",
+		HTTPMethod:     http.MethodPost,
+		ResourceType:   meta.ConfigAdmin,
+		ResourceAction: meta.Update,
+	}, {
+		Name:           "updateApprovalPolicy",
+		Description:    "更新审批策略",
+		Regex:          regexp.MustCompile(`^/api/v3/update/approval_policy/[0-9]+/?$`),
+		HTTPMethod:     http.MethodPut,
+		ResourceType:   meta.ConfigAdmin,
+		ResourceAction: meta.Update,
+	}, {
+		Name:           "deleteApprovalPolicy",
+		Description:    "删除审批策略",
+		Regex:          regexp.MustCompile(`^/api/v3/delete/approval_policy/[0-9]+/?$`),
+		HTTPMethod:     http.MethodDelete,
+		ResourceType:   meta.ConfigAdmin,
+		ResourceAction: meta.Update,
+	}, {
+		Name:           "findApprovalPolicy",
+		Description:    "查询审批策略",
+		Pattern:        "/api/v3/findmany/approval_policy",
+		HTTPMethod:     http.MethodPost,
+		ResourceType:   meta.ConfigAdmin,
+		ResourceAction: meta.Find,
+	}, {
+		Name:           "findApprovalRequest",
+		Description:    "查询审批单",
+		Pattern:        "/api/v3/findmany/approval_request",
+		HTTPMethod:     http.MethodPost,
+		ResourceType:   meta.ConfigAdmin,
+		ResourceAction: meta.SkipAction,
+	}, {
+		Name:           "approveApprovalRequest",
+		Description:    "审批通过审批单",
+		Regex:          regexp.MustCompile(`^/api/v3/update/approval_request/[0-9]+/approve/?$`),
+		HTTPMethod:     http.MethodPut,
+		ResourceType:   meta.ConfigAdmin,
+		ResourceAction: meta.SkipAction,
+	}, {
+		Name:           "rejectApprovalRequest",
+		Description:    "审批拒绝审批单",
+		Regex:          regexp.MustCompile(`^/api/v3/update/approval_request/[0-9]+/reject/?$`),
+		HTTPMethod:     http.MethodPut,
+		ResourceType:   meta.ConfigAdmin,
+		ResourceAction: meta.SkipAction,
+	},
+}
+
+func (ps *parseStream) approval() *parseStream {
+	return ParseStreamWithFramework(ps, ApprovalConfigs)
+}
@@ -0,0 +1,190 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parser
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+
+	"configcenter/src/ac/meta"
+	"configcenter/src/common/metadata"
+)
+
+// the change freeze windows forbid the changes of all the users, so they are managed with the global settings
+// permission like the approval policies.
+var ChangeFreezeConfigs = []AuthConfig{
+	{
+		Name:           "createChangeFreezeWindow",
+		Description:    "创建变更冻结窗口",
+		Pattern:        "/api/v3/create/change_freeze_window",
+		HTTPMethod:     http.MethodPost,
+		ResourceType:   meta.ConfigAdmin,
+		ResourceAction: meta.Update,
+	}, {
+		Name:           "updateChangeFreezeWindow",
+		Description:    "更新变更冻结窗口",
+		Regex:          regexp.MustCompile(`^/api/v3/update/change_freeze_window/[0-9]+/?$`),
+		HTTPMethod:     http.MethodPut,
+		ResourceType:   meta.ConfigAdmin,
+		ResourceAction: meta.Update,
+	}, {
+		Name:           "deleteChangeFreezeWindow",
+		Description:    "删除变更冻结窗口",
+		Regex:          regexp.MustCompile(`^/api/v3/delete/change_freeze_window/[0-9]+/?$`),
+		HTTPMethod:     http.MethodDelete,
+		ResourceType:   meta.ConfigAdmin,
+		ResourceAction: meta.Update,
+	}, {
+		Name:           "findChangeFreezeWindow",
+		Description:    "查询变更冻结窗口",
+		Pattern:        "/api/v3/findmany/change_freeze_window",
+		HTTPMethod:     http.MethodPost,
+		ResourceType:   meta.ConfigAdmin,
+		ResourceAction: meta.Find,
+	},
+}
+
+func (ps *parseStream) changeFreeze() *parseStream {
+	return ParseStreamWithFramework(ps, ChangeFreezeConfigs)
+}
+
+// ChangeFreezeOverrideAttribute is the permission needed to make changes in an active change freeze window
+var ChangeFreezeOverrideAttribute = meta.ResourceAttribute{
+	Basic: meta.Basic{
+		Type:   meta.ConfigAdmin,
+		Action: meta.Update,
+	},
+}
+
+// FrozenResource is a resource changed by the request which can be frozen by the change freeze windows
+type FrozenResource struct {
+	BizID    int64
+	Resource metadata.ChangeFreezeResource
+}
+
+// changeFreezeResource returns the kind of the resource in the change freeze windows, the processes share the
+// resource type with the service instances, so they are told apart by the url.
+func changeFreezeResource(path string, resourceType meta.ResourceType) (metadata.ChangeFreezeResource, bool) {
+	switch resourceType {
+	case meta.HostInstance, meta.HostApply:
+		return metadata.ChangeFreezeHost, true
+	case meta.Business, meta.ModelSet, meta.ModelModule, meta.MainlineInstance, meta.MainlineInstanceTopology,
+		meta.BizTopology, meta.SetTemplate:
+		return metadata.ChangeFreezeTopology, true
+	case meta.ProcessServiceInstance:
+		if strings.Contains(path, "/process_instance") {
+			return metadata.ChangeFreezeProcess, true
+		}
+		return metadata.ChangeFreezeServiceInstance, true
+	case meta.Process:
+		return metadata.ChangeFreezeProcess, true
+	}
+	return "", false
+}
+
+// anyBizHostUpdatePatterns are the apis that update the hosts by their ids in the body, the businesses of the hosts
+// are not in the request, so the changes are checked against the windows of all the businesses.
+var anyBizHostUpdatePatterns = map[string]bool{
+	updateHostInfoBatchPattern:     true,
+	updateHostPropertyBatchPattern: true,
+	updateImportHostsPattern:       true,
+	cloneHostPropertyBatchPattern:  true,
+}
+
+// ChangeFreezeResources returns the resources changed by the request which can be frozen, the resources which are
+// only read by the request are ignored.
+func ChangeFreezeResources(req *http.Request, attribute *meta.AuthAttribute) []FrozenResource {
+	readRequest := isReadRequest(req)
+	anyBiz := req.Method == http.MethodPut && anyBizHostUpdatePatterns[strings.TrimRight(req.URL.Path, "/")]
+	resources := make([]FrozenResource, 0)
+	for _, resource := range attribute.Resources {
+		if resourceAccess(resource, readRequest) == meta.APITokenRead {
+			continue
+		}
+		kind, ok := changeFreezeResource(req.URL.Path, resource.Type)
+		if !ok {
+			continue
+		}
+		bizID := resource.BusinessID
+		if anyBiz && kind == metadata.ChangeFreezeHost && bizID == 0 {
+			bizID = metadata.ChangeFreezeAnyBizID
+		}
+		resources = append(resources, FrozenResource{BizID: bizID, Resource: kind})
+	}
+	return resources
+}
+
+// changeFreezePathTokens are the url segments of the apis that change the frozen resources
+var changeFreezePathTokens = map[string]metadata.ChangeFreezeResource{
+	"host":            metadata.ChangeFreezeHost,
+	"hosts":           metadata.ChangeFreezeHost,
+	"host_apply_rule": metadata.ChangeFreezeHost,
+	"biz":             metadata.ChangeFreezeTopology,
+	"app":             metadata.ChangeFreezeTopology,
+	"set":             metadata.ChangeFreezeTopology,
+	"module":          metadata.ChangeFreezeTopology,
+	"topo":            metadata.ChangeFreezeTopology,
+	"topoinst":        metadata.ChangeFreezeTopology,
+	"mainline":        metadata.ChangeFreezeTopology,
+	"set_template":    metadata.ChangeFreezeTopology,
+}
+
+// changeFreezeReadTokens are the url segments of the apis that only read the resources
+var changeFreezeReadTokens = map[string]bool{
+	"search":   true,
+	"find":     true,
+	"findmany": true,
+	"findone":  true,
+	"count":    true,
+	"preview":  true,
+}
+
+// ChangeFreezeResourcesByPath returns the resources that may be changed by the request by its url, it's used when
+// the auth attribute of the request can not be parsed. the business can not be told from the url, so the changes
+// are frozen by the windows of all the businesses.
+func ChangeFreezeResourcesByPath(req *http.Request) []FrozenResource {
+	if isReadRequest(req) {
+		return nil
+	}
+
+	path := strings.ToLower(req.URL.Path)
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for _, segment := range segments {
+		if changeFreezeReadTokens[segment] {
+			return nil
+		}
+	}
+
+	kinds := make(map[metadata.ChangeFreezeResource]bool)
+	if strings.Contains(path, "process_instance") {
+		kinds[metadata.ChangeFreezeProcess] = true
+	}
+	if strings.Contains(path, "service_instance") {
+		kinds[metadata.ChangeFreezeServiceInstance] = true
+	}
+	for _, segment := range segments {
+		if kind, exist := changeFreezePathTokens[segment]; exist {
+			kinds[kind] = true
+		}
+	}
+
+	resources := make([]FrozenResource, 0)
+	for _, kind := range []metadata.ChangeFreezeResource{metadata.ChangeFreezeHost, metadata.ChangeFreezeTopology,
+		metadata.ChangeFreezeServiceInstance, metadata.ChangeFreezeProcess} {
+		if kinds[kind] {
+			resources = append(resources, FrozenResource{BizID: metadata.ChangeFreezeAnyBizID, Resource: kind})
+		}
+	}
+	return resources
+}
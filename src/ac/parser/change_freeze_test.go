@@ -0,0 +1,124 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package parser
+
+import (
+	"net/http"
+	"testing"
+
+	"configcenter/src/ac/meta"
+	"configcenter/src/common/metadata"
+)
+
+func TestChangeFreezeResources(t *testing.T) {
+	cases := []struct {
+		method   string
+		url      string
+		resource meta.ResourceAttribute
+		frozen   []FrozenResource
+	}{
+		{http.MethodPost, "/api/v3/hosts/search",
+			meta.ResourceAttribute{Basic: meta.Basic{Type: meta.HostInstance, Action: meta.FindMany}}, nil},
+		{http.MethodPut, "/api/v3/hosts/batch",
+			meta.ResourceAttribute{Basic: meta.Basic{Type: meta.HostInstance, Action: meta.Update}, BusinessID: 2},
+			[]FrozenResource{{BizID: 2, Resource: metadata.ChangeFreezeHost}}},
+		{http.MethodDelete, "/api/v3/set/2/3",
+			meta.ResourceAttribute{Basic: meta.Basic{Type: meta.ModelSet, Action: meta.Delete}, BusinessID: 2},
+			[]FrozenResource{{BizID: 2, Resource: metadata.ChangeFreezeTopology}}},
+		{http.MethodPost, "/api/v3/create/proc/service_instance",
+			meta.ResourceAttribute{Basic: meta.Basic{Type: meta.ProcessServiceInstance, Action: meta.Create}, BusinessID: 2},
+			[]FrozenResource{{BizID: 2, Resource: metadata.ChangeFreezeServiceInstance}}},
+		{http.MethodPut, "/api/v3/update/proc/process_instance",
+			meta.ResourceAttribute{Basic: meta.Basic{Type: meta.ProcessServiceInstance, Action: meta.Update}, BusinessID: 2},
+			[]FrozenResource{{BizID: 2, Resource: metadata.ChangeFreezeProcess}}},
+		{http.MethodPost, "/api/v3/create/objectattr",
+			meta.ResourceAttribute{Basic: meta.Basic{Type: meta.ModelAttribute, Action: meta.Create}}, nil},
+	}
+	for _, c := range cases {
+		req, _ := http.NewRequest(c.method, c.url, nil)
+		attribute := &meta.AuthAttribute{Resources: []meta.ResourceAttribute{c.resource}}
+		frozen := ChangeFreezeResources(req, attribute)
+		if len(frozen) != len(c.frozen) {
+			t.Errorf("%s %s should change %v, but got %v", c.method, c.url, c.frozen, frozen)
+			continue
+		}
+		for index := range frozen {
+			if frozen[index] != c.frozen[index] {
+				t.Errorf("%s %s should change %v, but got %v", c.method, c.url, c.frozen, frozen)
+			}
+		}
+	}
+}
+
+func TestChangeFreezeHostUpdateInBizWindow(t *testing.T) {
+	window := metadata.ChangeFreezeWindow{BizID: 3, Resources: []metadata.ChangeFreezeResource{metadata.ChangeFreezeHost}}
+	// the host update apis do not tell the businesses of the hosts, the parser skips their authorization
+	hostUpdate := meta.ResourceAttribute{Basic: meta.Basic{Type: meta.HostInstance, Action: meta.SkipAction}}
+	urls := []string{
+		"/api/v3/hosts/batch",
+		"/api/v3/hosts/property/batch",
+		"/api/v3/hosts/update",
+		"/api/v3/hosts/property/clone",
+	}
+	for _, url := range urls {
+		req, _ := http.NewRequest(http.MethodPut, url, nil)
+		attribute := &meta.AuthAttribute{Resources: []meta.ResourceAttribute{hostUpdate}}
+		frozen := ChangeFreezeResources(req, attribute)
+		if len(frozen) != 1 || !window.Covers(frozen[0].BizID, frozen[0].Resource) {
+			t.Errorf("PUT %s should be frozen by the business window, but got %v", url, frozen)
+		}
+	}
+
+	// the hosts added to the resource pool are not in any business
+	req, _ := http.NewRequest(http.MethodPost, "/api/v3/hosts/add", nil)
+	attribute := &meta.AuthAttribute{Resources: []meta.ResourceAttribute{
+		{Basic: meta.Basic{Type: meta.HostInstance, Action: meta.Create}}}}
+	frozen := ChangeFreezeResources(req, attribute)
+	if len(frozen) != 1 || window.Covers(frozen[0].BizID, frozen[0].Resource) {
+		t.Errorf("POST /api/v3/hosts/add should not be frozen by the business window, but got %v", frozen)
+	}
+}
+
+func TestChangeFreezeResourcesByPath(t *testing.T) {
+	anyBiz := metadata.ChangeFreezeAnyBizID
+	cases := []struct {
+		method string
+		url    string
+		frozen []FrozenResource
+	}{
+		{http.MethodGet, "/api/v3/host/1", nil},
+		{http.MethodPost, "/api/v3/hosts/search", nil},
+		{http.MethodPost, "/api/v3/create/objectattr", nil},
+		{http.MethodPost, "/api/v3/create/proc/service_instance/preview", nil},
+		{http.MethodPut, "/api/v3/hosts/batch", []FrozenResource{{BizID: anyBiz, Resource: metadata.ChangeFreezeHost}}},
+		{http.MethodDelete, "/api/v3/set/2/3", []FrozenResource{{BizID: anyBiz, Resource: metadata.ChangeFreezeTopology}}},
+		{http.MethodPost, "/api/v3/host/transfer_with_auto_clear_service_instance/bk_biz_id/2", []FrozenResource{
+			{BizID: anyBiz, Resource: metadata.ChangeFreezeHost},
+			{BizID: anyBiz, Resource: metadata.ChangeFreezeServiceInstance}}},
+		{http.MethodPut, "/api/v3/update/proc/process_instance",
+			[]FrozenResource{{BizID: anyBiz, Resource: metadata.ChangeFreezeProcess}}},
+	}
+	for _, c := range cases {
+		req, _ := http.NewRequest(c.method, c.url, nil)
+		frozen := ChangeFreezeResourcesByPath(req)
+		if len(frozen) != len(c.frozen) {
+			t.Errorf("%s %s should change %v, but got %v", c.method, c.url, c.frozen, frozen)
+			continue
+		}
+		for index := range frozen {
+			if frozen[index] != c.frozen[index] {
+				t.Errorf("%s %s should change %v, but got %v", c.method, c.url, c.frozen, frozen)
+			}
+		}
+	}
+}
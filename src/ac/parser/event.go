@@ -26,7 +26,8 @@ func (ps *parseStream) eventRelated() *parseStream {
 		return ps
 	}
 
-	ps.subscribe()
+	ps.subscribe().
+		notifyRule()
 
 	return ps
 }
@@ -44,6 +45,57 @@ const (
 	pingEventTestPattern   = "/api/v3/event/subscribe/ping"
 )
 
+var (
+	notifyRuleRegexp = regexp.MustCompile(`^/api/v3/event/notify/rule/\d+/?$`)
+)
+
+const (
+	createNotifyRulePattern      = "/api/v3/event/notify/rule"
+	findNotifyRulePattern        = "/api/v3/event/notify/rule/search"
+	testNotifyRulePattern        = "/api/v3/event/notify/rule/test"
+	findNotifyDeliveryLogPattern = "/api/v3/event/notify/log/search"
+)
+
+// notifyRule change notification rules are managed with the event pushing permission, the rules are
+// further authorized by event server with their businesses and creators.
+func (ps *parseStream) notifyRule() *parseStream {
+	if ps.shouldReturn() {
+		return ps
+	}
+
+	// find notify rules or delivery logs, only the readable rules and their logs are returned.
+	if ps.hitPattern(findNotifyRulePattern, http.MethodPost) ||
+		ps.hitPattern(findNotifyDeliveryLogPattern, http.MethodPost) {
+		ps.Attribute.Resources = []meta.ResourceAttribute{
+			{
+				Basic: meta.Basic{
+					Type:   meta.EventPushing,
+					Action: meta.SkipAction,
+				},
+			},
+		}
+		return ps
+	}
+
+	// create, update, delete or test a notify rule, which may send messages to the channels.
+	if ps.hitPattern(createNotifyRulePattern, http.MethodPost) ||
+		ps.hitPattern(testNotifyRulePattern, http.MethodPost) ||
+		ps.hitRegexp(notifyRuleRegexp, http.MethodPut) ||
+		ps.hitRegexp(notifyRuleRegexp, http.MethodDelete) {
+		ps.Attribute.Resources = []meta.ResourceAttribute{
+			{
+				Basic: meta.Basic{
+					Type:   meta.EventPushing,
+					Action: meta.Create,
+				},
+			},
+		}
+		return ps
+	}
+
+	return ps
+}
+
 func (ps *parseStream) subscribe() *parseStream {
 	if ps.shouldReturn() {
 		return ps
@@ -350,6 +350,8 @@ var (
 	findHostsBySetTemplatesRegex     = regexp.MustCompile(`^/api/v3/findmany/hosts/by_set_templates/biz/\d+$`)
 	findHostModuleRelationsRegex     = regexp.MustCompile(`^/api/v3/findmany/module_relation/bk_biz_id/[0-9]+/?$`)
 	findHostsByTopoRegex             = regexp.MustCompile(`^/api/v3/findmany/hosts/by_topo/biz/\d+$`)
+	findHostsPrometheusSDRegex       = regexp.MustCompile(`^/api/v3/findmany/hosts/prometheus_sd/biz/\d+$`)
+	findHostInventoryRegex           = regexp.MustCompile(`^/api/v3/findmany/hosts/inventory/?$`)
 )
 
 func (ps *parseStream) host() *parseStream {
@@ -465,6 +467,53 @@ func (ps *parseStream) host() *parseStream {
 		return ps
 	}
 
+	if ps.hitRegexp(findHostsPrometheusSDRegex, http.MethodGet) {
+		bizID, err := strconv.ParseInt(ps.RequestCtx.Elements[6], 10, 64)
+		if err != nil {
+			ps.err = fmt.Errorf("prometheus service discovery, but got invalid business id: %s", ps.RequestCtx.Elements[6])
+			return ps
+		}
+
+		ps.Attribute.Resources = []meta.ResourceAttribute{
+			{
+				BusinessID: bizID,
+				Basic: meta.Basic{
+					Type:   meta.HostInstance,
+					Action: meta.FindMany,
+				},
+			},
+		}
+		return ps
+	}
+
+	if ps.hitRegexp(findHostInventoryRegex, http.MethodPost) {
+		val, err := ps.RequestCtx.getValueFromBody("bk_biz_ids")
+		if err != nil {
+			ps.err = err
+			return ps
+		}
+		bizIDs := val.Array()
+		if len(bizIDs) == 0 {
+			ps.err = errors.New("host inventory, but got empty bk_biz_ids")
+			return ps
+		}
+
+		for _, bizID := range bizIDs {
+			if bizID.Int() <= 0 {
+				ps.err = fmt.Errorf("host inventory, but got invalid business id: %s", bizID.Raw)
+				return ps
+			}
+			ps.Attribute.Resources = append(ps.Attribute.Resources, meta.ResourceAttribute{
+				BusinessID: bizID.Int(),
+				Basic: meta.Basic{
+					Type:   meta.HostInstance,
+					Action: meta.FindMany,
+				},
+			})
+		}
+		return ps
+	}
+
 	// host lock authorize filter
 	if ps.hitPattern(lockHostPattern, http.MethodPost) {
 		ps.Attribute.Resources = []meta.ResourceAttribute{
@@ -1276,6 +1325,11 @@ var (
 	findHostSnapshotAPIRegexp = regexp.MustCompile(`^/api/v3/hosts/snapshot/[0-9]+/?$`)
 
 	findHostSnapshotBatchPattern = "/api/v3/hosts/snapshot/batch"
+
+	findHostSnapshotHistoryPattern = "/api/v3/collector/hostsnap/history/action/search"
+	findHostSnapshotDriftPattern   = "/api/v3/collector/hostsnap/drift/action/search"
+
+	reportHostSnapshotRegexp = regexp.MustCompile(`^/api/v3/collector/hostsnap/source/[a-zA-Z][a-zA-Z0-9_]*/action/report/?$`)
 )
 
 func (ps *parseStream) hostSnapshot() *parseStream {
@@ -1313,6 +1367,52 @@ func (ps *parseStream) hostSnapshot() *parseStream {
 		return ps
 	}
 
+	if ps.hitPattern(findHostSnapshotHistoryPattern, http.MethodPost) {
+		ps.Attribute.Resources = []meta.ResourceAttribute{
+			{
+				Basic: meta.Basic{
+					Type:   meta.HostInstance,
+					Action: meta.SkipAction,
+				},
+			},
+		}
+
+		return ps
+	}
+
+	if ps.hitPattern(findHostSnapshotDriftPattern, http.MethodPost) {
+		ps.Attribute.Resources = []meta.ResourceAttribute{
+			{
+				Basic: meta.Basic{
+					Type:   meta.HostInstance,
+					Action: meta.SkipAction,
+				},
+			},
+		}
+
+		return ps
+	}
+
+	// snapshot reported by the collectors is treated the same as the one from gse channel, it can only be
+	// reported with the api token of the source reporters, which is checked by datacollection.
+	if ps.hitRegexp(reportHostSnapshotRegexp, http.MethodPost) {
+		if len(ps.RequestCtx.Header.Get(common.BKHTTPAPITokenID)) == 0 {
+			ps.err = errors.New("host snapshot can only be reported with api token")
+			return ps
+		}
+
+		ps.Attribute.Resources = []meta.ResourceAttribute{
+			{
+				Basic: meta.Basic{
+					Type:   meta.HostInstance,
+					Action: meta.SkipAction,
+				},
+			},
+		}
+
+		return ps
+	}
+
 	return ps
 }
 
@@ -48,7 +48,8 @@ func (ps *parseStream) topologyLatest() *parseStream {
 		objectAttributeLatest().
 		mainlineLatest().
 		setTemplate().
-		cache()
+		cache().
+		modelSchema()
 
 	return ps
 }
@@ -480,6 +481,8 @@ func (ps *parseStream) objectAssociationLatest() *parseStream {
 const (
 	findObjectInstanceAssociationLatestPattern        = "/api/v3/find/instassociation"
 	findObjectInstanceAssociationRelatedLatestPattern = "/api/v3/find/instassociation/related"
+	findObjectInstanceAssociationGraphLatestPattern   = "/api/v3/find/instassociation/graph"
+	exportObjectInstanceGraphLatestPattern            = "/api/v3/find/graph/export/instance"
 	createObjectInstanceAssociationLatestPattern      = "/api/v3/create/instassociation"
 )
 
@@ -516,7 +519,9 @@ func (ps *parseStream) objectInstanceAssociationLatest() *parseStream {
 	}
 
 	// find instance's association operation.
-	if ps.hitPattern(findObjectInstanceAssociationRelatedLatestPattern, http.MethodPost) {
+	if ps.hitPattern(findObjectInstanceAssociationRelatedLatestPattern, http.MethodPost) ||
+		ps.hitPattern(findObjectInstanceAssociationGraphLatestPattern, http.MethodPost) ||
+		ps.hitPattern(exportObjectInstanceGraphLatestPattern, http.MethodPost) {
 		bizID, err := ps.RequestCtx.getBizIDFromBody()
 		if err != nil {
 			ps.err = err
@@ -1119,6 +1124,7 @@ const (
 	createObjectLatestPattern       = "/api/v3/create/object"
 	findObjectsLatestPattern        = "/api/v3/find/object"
 	findObjectTopologyLatestPattern = "/api/v3/find/objecttopology"
+	exportObjectGraphLatestPattern  = "/api/v3/find/graph/export/model"
 )
 
 var (
@@ -1259,7 +1265,8 @@ func (ps *parseStream) objectLatest() *parseStream {
 	}
 
 	// find object's topology operation.
-	if ps.hitPattern(findObjectTopologyLatestPattern, http.MethodPost) {
+	if ps.hitPattern(findObjectTopologyLatestPattern, http.MethodPost) ||
+		ps.hitPattern(exportObjectGraphLatestPattern, http.MethodPost) {
 		bizID, err := ps.RequestCtx.getBizIDFromBody()
 		if err != nil {
 			ps.err = err
@@ -1635,6 +1642,7 @@ var (
 	updateObjectAttributeIndexLatestRegexp = regexp.MustCompile(`^/api/v3/update/objectattr/index/[^\s/]+/[0-9]+/?$`)
 	createBizCustomFieldLatestRegexp       = regexp.MustCompile(`^/api/v3/create/objectattr/biz/[0-9]+/?$`)
 	updateBizCustomFieldLatestRegexp       = regexp.MustCompile(`^/api/v3/update/objectattr/biz/[0-9]+/id/[0-9]+/?$`)
+	findNoPermRestrictedFieldLatestRegexp  = regexp.MustCompile(`^/api/v3/find/objectattr/restricted/no_permission/object/[^\s/]+/?$`)
 )
 
 func (ps *parseStream) objectAttributeLatest() *parseStream {
@@ -1880,6 +1888,30 @@ func (ps *parseStream) objectAttributeLatest() *parseStream {
 		return ps
 	}
 
+	// get the restricted fields of the object that user has no permission to view
+	if ps.hitRegexp(findNoPermRestrictedFieldLatestRegexp, http.MethodGet) {
+		if len(ps.RequestCtx.Elements) != 8 {
+			ps.err = errors.New("find no permission restricted fields, but got invalid url")
+			return ps
+		}
+
+		model, err := ps.getOneModel(mapstr.MapStr{common.BKObjIDField: ps.RequestCtx.Elements[7]})
+		if err != nil {
+			ps.err = err
+			return ps
+		}
+
+		ps.Attribute.Resources = append(ps.Attribute.Resources,
+			meta.ResourceAttribute{
+				Basic: meta.Basic{
+					Type:   meta.ModelAttribute,
+					Action: meta.FindMany,
+				},
+				Layers: []meta.Item{{Type: meta.Model, InstanceID: model.ID}},
+			})
+		return ps
+	}
+
 	// create business custom field operation
 	if ps.hitRegexp(createBizCustomFieldLatestRegexp, http.MethodPost) {
 		if len(ps.RequestCtx.Elements) != 6 {
@@ -1964,6 +1996,10 @@ const (
 var (
 	deleteMainlineObjectLatestRegexp                       = regexp.MustCompile(`^/api/v3/delete/topomodelmainline/object/[^\s/]+/?$`)
 	findBusinessInstanceTopologyLatestRegexp               = regexp.MustCompile(`^/api/v3/find/topoinst/biz/[0-9]+/?$`)
+	exportBusinessInstanceTopologyGraphLatestRegexp        = regexp.MustCompile(`^/api/v3/find/graph/export/biz/[0-9]+/?$`)
+	cloneBusinessTopologyLatestRegexp                      = regexp.MustCompile(`^/api/v3/createmany/topo/clone/biz/[0-9]+/?$`)
+	findCloneBusinessTopologyTaskLatestRegexp              = regexp.MustCompile(`^/api/v3/find/topo/clone/biz/[0-9]+/task/?$`)
+	diffBusinessTopologyLatestRegexp                       = regexp.MustCompile(`^/api/v3/find/topo/diff/biz/[0-9]+/?$`)
 	findBusinessInstanceTopologyPathRegexp                 = regexp.MustCompile(`^/api/v3/find/topopath/biz/[0-9]+/?$`)
 	findHostApplyRelatedObjectTopologyRegex                = regexp.MustCompile(`^/api/v3/find/topoinst/bk_biz_id/([0-9]+)/host_apply_rule_related/?$`)
 	findBusinessInstanceTopologyWithStatisticsLatestRegexp = regexp.MustCompile(`^/api/v3/find/topoinst_with_statistics/biz/[0-9]+/?$`)
@@ -2090,6 +2126,144 @@ func (ps *parseStream) mainlineLatest() *parseStream {
 		return ps
 	}
 
+	// export business instance topology graph operation.
+	if ps.hitRegexp(exportBusinessInstanceTopologyGraphLatestRegexp, http.MethodPost) {
+		if len(ps.RequestCtx.Elements) != 7 {
+			ps.err = errors.New("export business instance topology graph, but got invalid url")
+			return ps
+		}
+
+		bizID, err := strconv.ParseInt(ps.RequestCtx.Elements[6], 10, 64)
+		if err != nil {
+			ps.err = fmt.Errorf("export business instance topology graph, but got invalid business id %s", ps.RequestCtx.Elements[6])
+			return ps
+		}
+
+		ps.Attribute.Resources = []meta.ResourceAttribute{
+			{
+				BusinessID: bizID,
+				Basic: meta.Basic{
+					Type:   meta.ModelInstanceTopology,
+					Action: meta.Find,
+				},
+			},
+		}
+		return ps
+	}
+
+	// clone business topology, need find permission of the source business,
+	// and create business permission or update permission of the target business.
+	if ps.hitRegexp(cloneBusinessTopologyLatestRegexp, http.MethodPost) {
+		if len(ps.RequestCtx.Elements) != 7 {
+			ps.err = errors.New("clone business topology, but got invalid url")
+			return ps
+		}
+
+		bizID, err := strconv.ParseInt(ps.RequestCtx.Elements[6], 10, 64)
+		if err != nil {
+			ps.err = fmt.Errorf("clone business topology, but got invalid business id %s", ps.RequestCtx.Elements[6])
+			return ps
+		}
+
+		targetBizID, err := ps.RequestCtx.getValueFromBody("bk_target_biz_id")
+		if err != nil {
+			ps.err = err
+			return ps
+		}
+
+		ps.Attribute.Resources = []meta.ResourceAttribute{
+			{
+				BusinessID: bizID,
+				Basic: meta.Basic{
+					Type:   meta.ModelInstanceTopology,
+					Action: meta.Find,
+				},
+			},
+		}
+		if targetBizID.Int() > 0 {
+			ps.Attribute.Resources = append(ps.Attribute.Resources, meta.ResourceAttribute{
+				Basic: meta.Basic{
+					Type:       meta.Business,
+					Action:     meta.Update,
+					InstanceID: targetBizID.Int(),
+				},
+			})
+		} else {
+			ps.Attribute.Resources = append(ps.Attribute.Resources, meta.ResourceAttribute{
+				Basic: meta.Basic{
+					Type:   meta.Business,
+					Action: meta.Create,
+				},
+			})
+		}
+		return ps
+	}
+
+	// find the latest clone task of the target business
+	if ps.hitRegexp(findCloneBusinessTopologyTaskLatestRegexp, http.MethodPost) {
+		if len(ps.RequestCtx.Elements) != 8 {
+			ps.err = errors.New("find clone business topology task, but got invalid url")
+			return ps
+		}
+
+		bizID, err := strconv.ParseInt(ps.RequestCtx.Elements[6], 10, 64)
+		if err != nil {
+			ps.err = fmt.Errorf("find clone business topology task, but got invalid business id %s", ps.RequestCtx.Elements[6])
+			return ps
+		}
+
+		ps.Attribute.Resources = []meta.ResourceAttribute{
+			{
+				BusinessID: bizID,
+				Basic: meta.Basic{
+					Type:   meta.ModelInstanceTopology,
+					Action: meta.Find,
+				},
+			},
+		}
+		return ps
+	}
+
+	// diff business topology, need find permission of the business and the target business if compared with
+	if ps.hitRegexp(diffBusinessTopologyLatestRegexp, http.MethodPost) {
+		if len(ps.RequestCtx.Elements) != 7 {
+			ps.err = errors.New("diff business topology, but got invalid url")
+			return ps
+		}
+
+		bizID, err := strconv.ParseInt(ps.RequestCtx.Elements[6], 10, 64)
+		if err != nil {
+			ps.err = fmt.Errorf("diff business topology, but got invalid business id %s", ps.RequestCtx.Elements[6])
+			return ps
+		}
+
+		targetBizID, err := ps.RequestCtx.getValueFromBody("target_biz_id")
+		if err != nil {
+			ps.err = err
+			return ps
+		}
+
+		ps.Attribute.Resources = []meta.ResourceAttribute{
+			{
+				BusinessID: bizID,
+				Basic: meta.Basic{
+					Type:   meta.ModelInstanceTopology,
+					Action: meta.Find,
+				},
+			},
+		}
+		if targetBizID.Int() > 0 {
+			ps.Attribute.Resources = append(ps.Attribute.Resources, meta.ResourceAttribute{
+				BusinessID: targetBizID.Int(),
+				Basic: meta.Basic{
+					Type:   meta.ModelInstanceTopology,
+					Action: meta.Find,
+				},
+			})
+		}
+		return ps
+	}
+
 	return ps
 }
 
@@ -2133,3 +2307,44 @@ func (ps *parseStream) cache() *parseStream {
 
 	return ps
 }
+
+const (
+	exportModelSchemaPattern = "/api/v3/find/model/schema/export"
+	planModelSchemaPattern   = "/api/v3/find/model/schema/plan"
+	applyModelSchemaPattern  = "/api/v3/update/model/schema/apply"
+)
+
+func (ps *parseStream) modelSchema() *parseStream {
+	if ps.shouldReturn() {
+		return ps
+	}
+
+	// export and plan model schema only read the model.
+	if ps.hitPattern(exportModelSchemaPattern, http.MethodPost) || ps.hitPattern(planModelSchemaPattern, http.MethodPost) {
+		ps.Attribute.Resources = []meta.ResourceAttribute{
+			{
+				Basic: meta.Basic{
+					Type:   meta.ModelTopology,
+					Action: meta.SkipAction,
+				},
+			},
+		}
+		return ps
+	}
+
+	// apply model schema is authorized by topo server with the changes of the plan, each change needs the
+	// same permission as the api that makes the change.
+	if ps.hitPattern(applyModelSchemaPattern, http.MethodPost) {
+		ps.Attribute.Resources = []meta.ResourceAttribute{
+			{
+				Basic: meta.Basic{
+					Type:   meta.ModelTopology,
+					Action: meta.SkipAction,
+				},
+			},
+		}
+		return ps
+	}
+
+	return ps
+}
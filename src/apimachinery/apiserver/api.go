@@ -140,6 +140,21 @@ func (a *apiServer) GetObjectAttr(ctx context.Context, h http.Header, params map
 	return
 }
 
+func (a *apiServer) GetNoPermRestrictedFields(ctx context.Context, h http.Header, objID string) (resp *metadata.RestrictedFieldResult, err error) {
+
+	resp = new(metadata.RestrictedFieldResult)
+	subPath := "/find/objectattr/restricted/no_permission/object/%s"
+
+	err = a.client.Get().
+		WithContext(ctx).
+		Body(nil).
+		SubResourcef(subPath, objID).
+		WithHeaders(h).
+		Do().
+		Into(resp)
+	return
+}
+
 func (a *apiServer) GetHostData(ctx context.Context, h http.Header, params mapstr.MapStr) (resp *metadata.QueryInstResult, err error) {
 
 	resp = new(metadata.QueryInstResult)
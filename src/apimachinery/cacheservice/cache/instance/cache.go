@@ -0,0 +1,97 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package instance
+
+import (
+	"context"
+	"net/http"
+
+	"configcenter/src/common"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/metadata"
+)
+
+// ListInstances list the model's instances with id list and return with a json array string which is []string json.
+func (i *instanceCache) ListInstances(ctx context.Context, h http.Header, objID string,
+	opt *metadata.ListWithIDOption) (string, error) {
+
+	resp, err := i.client.Post().
+		WithContext(ctx).
+		Body(opt).
+		SubResourcef("/findmany/cache/instance/%s", objID).
+		WithHeaders(h).
+		Do().
+		IntoJsonString()
+
+	if err != nil {
+		return "", errors.New(common.CCErrCommHTTPDoRequestFailed, err.Error())
+	}
+
+	if !resp.Result {
+		return "", errors.New(resp.Code, resp.ErrMsg)
+	}
+
+	return resp.Data, nil
+}
+
+// SearchInstanceWithUniqueKey get the model's instance with the values of a unique rule of the model.
+func (i *instanceCache) SearchInstanceWithUniqueKey(ctx context.Context, h http.Header, objID string,
+	opt *metadata.SearchInstWithUniqueKeyOption) (string, error) {
+
+	resp, err := i.client.Post().
+		WithContext(ctx).
+		Body(opt).
+		SubResourcef("/find/cache/instance/%s/with_unique_key", objID).
+		WithHeaders(h).
+		Do().
+		IntoJsonString()
+
+	if err != nil {
+		return "", errors.New(common.CCErrCommHTTPDoRequestFailed, err.Error())
+	}
+
+	if !resp.Result {
+		return "", errors.New(resp.Code, resp.ErrMsg)
+	}
+
+	return resp.Data, nil
+}
+
+type consistencyResp struct {
+	metadata.BaseResp `json:",inline"`
+	Data              *metadata.InstCacheConsistencyResult `json:"data"`
+}
+
+// CheckConsistency check the model's cached instances with mongodb, and repair the cache if needed.
+func (i *instanceCache) CheckConsistency(ctx context.Context, h http.Header, objID string,
+	opt *metadata.CheckInstCacheConsistencyOption) (*metadata.InstCacheConsistencyResult, error) {
+
+	resp := new(consistencyResp)
+	err := i.client.Post().
+		WithContext(ctx).
+		Body(opt).
+		SubResourcef("/find/cache/instance/%s/consistency", objID).
+		WithHeaders(h).
+		Do().
+		Into(resp)
+
+	if err != nil {
+		return nil, errors.New(common.CCErrCommHTTPDoRequestFailed, err.Error())
+	}
+
+	if !resp.Result {
+		return nil, errors.New(resp.Code, resp.ErrMsg)
+	}
+
+	return resp.Data, nil
+}
@@ -0,0 +1,38 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package admission
+
+import (
+	"context"
+	"net/http"
+
+	"configcenter/src/apimachinery/rest"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/metadata"
+)
+
+type AdmissionClientInterface interface {
+	CreateAdmissionWebhook(ctx context.Context, h http.Header, webhook metadata.AdmissionWebhook) (*metadata.AdmissionWebhook, errors.CCErrorCoder)
+	UpdateAdmissionWebhook(ctx context.Context, h http.Header, id int64, webhook metadata.AdmissionWebhook) (*metadata.AdmissionWebhook, errors.CCErrorCoder)
+	DeleteAdmissionWebhook(ctx context.Context, h http.Header, id int64) errors.CCErrorCoder
+	SearchAdmissionWebhooks(ctx context.Context, h http.Header, option metadata.SearchAdmissionWebhookOption) (*metadata.SearchAdmissionWebhookResult, errors.CCErrorCoder)
+	SearchAdmissionWebhookLogs(ctx context.Context, h http.Header, option metadata.SearchAdmissionWebhookLogOption) (*metadata.SearchAdmissionWebhookLogResult, errors.CCErrorCoder)
+}
+
+func NewAdmissionClientInterface(client rest.ClientInterface) AdmissionClientInterface {
+	return &admission{client: client}
+}
+
+type admission struct {
+	client rest.ClientInterface
+}
@@ -0,0 +1,145 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package admission
+
+import (
+	"context"
+	"net/http"
+
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/metadata"
+)
+
+func (a *admission) CreateAdmissionWebhook(ctx context.Context, h http.Header, webhook metadata.AdmissionWebhook) (*metadata.AdmissionWebhook, errors.CCErrorCoder) {
+	ret := struct {
+		metadata.BaseResp `json:",inline"`
+		Data              *metadata.AdmissionWebhook `json:"data"`
+	}{}
+	subPath := "/create/admission/webhook"
+
+	err := a.client.Post().
+		WithContext(ctx).
+		Body(webhook).
+		SubResourcef(subPath).
+		WithHeaders(h).
+		Do().
+		Into(&ret)
+
+	if err != nil {
+		blog.Errorf("CreateAdmissionWebhook failed, http request failed, err: %+v", err)
+		return nil, errors.CCHttpError
+	}
+	if ret.CCError() != nil {
+		return nil, ret.CCError()
+	}
+
+	return ret.Data, nil
+}
+
+func (a *admission) UpdateAdmissionWebhook(ctx context.Context, h http.Header, id int64, webhook metadata.AdmissionWebhook) (*metadata.AdmissionWebhook, errors.CCErrorCoder) {
+	ret := struct {
+		metadata.BaseResp `json:",inline"`
+		Data              *metadata.AdmissionWebhook `json:"data"`
+	}{}
+	subPath := "/update/admission/webhook/%d"
+
+	err := a.client.Put().
+		WithContext(ctx).
+		Body(webhook).
+		SubResourcef(subPath, id).
+		WithHeaders(h).
+		Do().
+		Into(&ret)
+
+	if err != nil {
+		blog.Errorf("UpdateAdmissionWebhook failed, http request failed, err: %+v", err)
+		return nil, errors.CCHttpError
+	}
+	if ret.CCError() != nil {
+		return nil, ret.CCError()
+	}
+
+	return ret.Data, nil
+}
+
+func (a *admission) DeleteAdmissionWebhook(ctx context.Context, h http.Header, id int64) errors.CCErrorCoder {
+	ret := metadata.BaseResp{}
+	subPath := "/delete/admission/webhook/%d"
+
+	err := a.client.Delete().
+		WithContext(ctx).
+		SubResourcef(subPath, id).
+		WithHeaders(h).
+		Do().
+		Into(&ret)
+
+	if err != nil {
+		blog.Errorf("DeleteAdmissionWebhook failed, http request failed, err: %+v", err)
+		return errors.CCHttpError
+	}
+
+	return ret.CCError()
+}
+
+func (a *admission) SearchAdmissionWebhooks(ctx context.Context, h http.Header, option metadata.SearchAdmissionWebhookOption) (*metadata.SearchAdmissionWebhookResult, errors.CCErrorCoder) {
+	ret := struct {
+		metadata.BaseResp `json:",inline"`
+		Data              *metadata.SearchAdmissionWebhookResult `json:"data"`
+	}{}
+	subPath := "/findmany/admission/webhook"
+
+	err := a.client.Post().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef(subPath).
+		WithHeaders(h).
+		Do().
+		Into(&ret)
+
+	if err != nil {
+		blog.Errorf("SearchAdmissionWebhooks failed, http request failed, err: %+v", err)
+		return nil, errors.CCHttpError
+	}
+	if ret.CCError() != nil {
+		return nil, ret.CCError()
+	}
+
+	return ret.Data, nil
+}
+
+func (a *admission) SearchAdmissionWebhookLogs(ctx context.Context, h http.Header, option metadata.SearchAdmissionWebhookLogOption) (*metadata.SearchAdmissionWebhookLogResult, errors.CCErrorCoder) {
+	ret := struct {
+		metadata.BaseResp `json:",inline"`
+		Data              *metadata.SearchAdmissionWebhookLogResult `json:"data"`
+	}{}
+	subPath := "/findmany/admission/webhook/log"
+
+	err := a.client.Post().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef(subPath).
+		WithHeaders(h).
+		Do().
+		Into(&ret)
+
+	if err != nil {
+		blog.Errorf("SearchAdmissionWebhookLogs failed, http request failed, err: %+v", err)
+		return nil, errors.CCHttpError
+	}
+	if ret.CCError() != nil {
+		return nil, ret.CCError()
+	}
+
+	return ret.Data, nil
+}
@@ -0,0 +1,42 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package approval
+
+import (
+	"context"
+	"net/http"
+
+	"configcenter/src/apimachinery/rest"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/metadata"
+)
+
+type ApprovalClientInterface interface {
+	CreateApprovalPolicy(ctx context.Context, h http.Header, policy metadata.ApprovalPolicy) (*metadata.ApprovalPolicy, errors.CCErrorCoder)
+	UpdateApprovalPolicy(ctx context.Context, h http.Header, id int64, policy metadata.ApprovalPolicy) (*metadata.ApprovalPolicy, errors.CCErrorCoder)
+	DeleteApprovalPolicy(ctx context.Context, h http.Header, id int64) errors.CCErrorCoder
+	SearchApprovalPolicies(ctx context.Context, h http.Header, option metadata.SearchApprovalPolicyOption) (*metadata.SearchApprovalPolicyResult, errors.CCErrorCoder)
+	CreateApprovalRequest(ctx context.Context, h http.Header, request metadata.ApprovalRequest) (*metadata.ApprovalRequest, errors.CCErrorCoder)
+	GetApprovalRequest(ctx context.Context, h http.Header, id int64) (*metadata.ApprovalRequest, errors.CCErrorCoder)
+	SearchApprovalRequests(ctx context.Context, h http.Header, option metadata.SearchApprovalRequestOption) (*metadata.SearchApprovalRequestResult, errors.CCErrorCoder)
+	UpdateApprovalRequestStatus(ctx context.Context, h http.Header, id int64, option metadata.UpdateApprovalStatusOption) (*metadata.ApprovalRequest, errors.CCErrorCoder)
+	ExpireApprovalRequests(ctx context.Context, h http.Header) ([]metadata.ApprovalRequest, errors.CCErrorCoder)
+}
+
+func NewApprovalClientInterface(client rest.ClientInterface) ApprovalClientInterface {
+	return &approval{client: client}
+}
+
+type approval struct {
+	client rest.ClientInterface
+}
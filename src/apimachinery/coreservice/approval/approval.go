@@ -0,0 +1,247 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package approval
+
+import (
+	"context"
+	"net/http"
+
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/metadata"
+)
+
+func (a *approval) CreateApprovalPolicy(ctx context.Context, h http.Header, policy metadata.ApprovalPolicy) (*metadata.ApprovalPolicy, errors.CCErrorCoder) {
+	ret := struct {
+		metadata.BaseResp `json:",inline"`
+		Data              *metadata.ApprovalPolicy `json:"data"`
+	}{}
+	subPath := "/create/approval/policy"
+
+	err := a.client.Post().
+		WithContext(ctx).
+		Body(policy).
+		SubResourcef(subPath).
+		WithHeaders(h).
+		Do().
+		Into(&ret)
+
+	if err != nil {
+		blog.Errorf("CreateApprovalPolicy failed, http request failed, err: %+v", err)
+		return nil, errors.CCHttpError
+	}
+	if ret.CCError() != nil {
+		return nil, ret.CCError()
+	}
+
+	return ret.Data, nil
+}
+
+func (a *approval) UpdateApprovalPolicy(ctx context.Context, h http.Header, id int64, policy metadata.ApprovalPolicy) (*metadata.ApprovalPolicy, errors.CCErrorCoder) {
+	ret := struct {
+		metadata.BaseResp `json:",inline"`
+		Data              *metadata.ApprovalPolicy `json:"data"`
+	}{}
+	subPath := "/update/approval/policy/%d"
+
+	err := a.client.Put().
+		WithContext(ctx).
+		Body(policy).
+		SubResourcef(subPath, id).
+		WithHeaders(h).
+		Do().
+		Into(&ret)
+
+	if err != nil {
+		blog.Errorf("UpdateApprovalPolicy failed, http request failed, err: %+v", err)
+		return nil, errors.CCHttpError
+	}
+	if ret.CCError() != nil {
+		return nil, ret.CCError()
+	}
+
+	return ret.Data, nil
+}
+
+func (a *approval) DeleteApprovalPolicy(ctx context.Context, h http.Header, id int64) errors.CCErrorCoder {
+	ret := metadata.BaseResp{}
+	subPath := "/delete/approval/policy/%d"
+
+	err := a.client.Delete().
+		WithContext(ctx).
+		SubResourcef(subPath, id).
+		WithHeaders(h).
+		Do().
+		Into(&ret)
+
+	if err != nil {
+		blog.Errorf("DeleteApprovalPolicy failed, http request failed, err: %+v", err)
+		return errors.CCHttpError
+	}
+
+	return ret.CCError()
+}
+
+func (a *approval) SearchApprovalPolicies(ctx context.Context, h http.Header, option metadata.SearchApprovalPolicyOption) (*metadata.SearchApprovalPolicyResult, errors.CCErrorCoder) {
+	ret := struct {
+		metadata.BaseResp `json:",inline"`
+		Data              *metadata.SearchApprovalPolicyResult `json:"data"`
+	}{}
+	subPath := "/findmany/approval/policy"
+
+	err := a.client.Post().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef(subPath).
+		WithHeaders(h).
+		Do().
+		Into(&ret)
+
+	if err != nil {
+		blog.Errorf("SearchApprovalPolicies failed, http request failed, err: %+v", err)
+		return nil, errors.CCHttpError
+	}
+	if ret.CCError() != nil {
+		return nil, ret.CCError()
+	}
+
+	return ret.Data, nil
+}
+
+func (a *approval) CreateApprovalRequest(ctx context.Context, h http.Header, request metadata.ApprovalRequest) (*metadata.ApprovalRequest, errors.CCErrorCoder) {
+	ret := struct {
+		metadata.BaseResp `json:",inline"`
+		Data              *metadata.ApprovalRequest `json:"data"`
+	}{}
+	subPath := "/create/approval/request"
+
+	err := a.client.Post().
+		WithContext(ctx).
+		Body(request).
+		SubResourcef(subPath).
+		WithHeaders(h).
+		Do().
+		Into(&ret)
+
+	if err != nil {
+		blog.Errorf("CreateApprovalRequest failed, http request failed, err: %+v", err)
+		return nil, errors.CCHttpError
+	}
+	if ret.CCError() != nil {
+		return nil, ret.CCError()
+	}
+
+	return ret.Data, nil
+}
+
+func (a *approval) GetApprovalRequest(ctx context.Context, h http.Header, id int64) (*metadata.ApprovalRequest, errors.CCErrorCoder) {
+	ret := struct {
+		metadata.BaseResp `json:",inline"`
+		Data              *metadata.ApprovalRequest `json:"data"`
+	}{}
+	subPath := "/find/approval/request/%d"
+
+	err := a.client.Get().
+		WithContext(ctx).
+		SubResourcef(subPath, id).
+		WithHeaders(h).
+		Do().
+		Into(&ret)
+
+	if err != nil {
+		blog.Errorf("GetApprovalRequest failed, http request failed, err: %+v", err)
+		return nil, errors.CCHttpError
+	}
+	if ret.CCError() != nil {
+		return nil, ret.CCError()
+	}
+
+	return ret.Data, nil
+}
+
+func (a *approval) SearchApprovalRequests(ctx context.Context, h http.Header, option metadata.SearchApprovalRequestOption) (*metadata.SearchApprovalRequestResult, errors.CCErrorCoder) {
+	ret := struct {
+		metadata.BaseResp `json:",inline"`
+		Data              *metadata.SearchApprovalRequestResult `json:"data"`
+	}{}
+	subPath := "/findmany/approval/request"
+
+	err := a.client.Post().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef(subPath).
+		WithHeaders(h).
+		Do().
+		Into(&ret)
+
+	if err != nil {
+		blog.Errorf("SearchApprovalRequests failed, http request failed, err: %+v", err)
+		return nil, errors.CCHttpError
+	}
+	if ret.CCError() != nil {
+		return nil, ret.CCError()
+	}
+
+	return ret.Data, nil
+}
+
+func (a *approval) UpdateApprovalRequestStatus(ctx context.Context, h http.Header, id int64, option metadata.UpdateApprovalStatusOption) (*metadata.ApprovalRequest, errors.CCErrorCoder) {
+	ret := struct {
+		metadata.BaseResp `json:",inline"`
+		Data              *metadata.ApprovalRequest `json:"data"`
+	}{}
+	subPath := "/update/approval/request/%d/status"
+
+	err := a.client.Put().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef(subPath, id).
+		WithHeaders(h).
+		Do().
+		Into(&ret)
+
+	if err != nil {
+		blog.Errorf("UpdateApprovalRequestStatus failed, http request failed, err: %+v", err)
+		return nil, errors.CCHttpError
+	}
+	if ret.CCError() != nil {
+		return nil, ret.CCError()
+	}
+
+	return ret.Data, nil
+}
+
+func (a *approval) ExpireApprovalRequests(ctx context.Context, h http.Header) ([]metadata.ApprovalRequest, errors.CCErrorCoder) {
+	ret := struct {
+		metadata.BaseResp `json:",inline"`
+		Data              []metadata.ApprovalRequest `json:"data"`
+	}{}
+	subPath := "/update/approval/request/expire"
+
+	err := a.client.Put().
+		WithContext(ctx).
+		SubResourcef(subPath).
+		WithHeaders(h).
+		Do().
+		Into(&ret)
+
+	if err != nil {
+		blog.Errorf("ExpireApprovalRequests failed, http request failed, err: %+v", err)
+		return nil, errors.CCHttpError
+	}
+	if ret.CCError() != nil {
+		return nil, ret.CCError()
+	}
+
+	return ret.Data, nil
+}
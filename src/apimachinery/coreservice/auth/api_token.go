@@ -0,0 +1,113 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package auth
+
+import (
+	"context"
+	"net/http"
+
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/metadata"
+)
+
+func (a *auth) CreateAPIToken(ctx context.Context, h http.Header, option metadata.SaveAPITokenOption) (*metadata.APIToken, errors.CCErrorCoder) {
+	ret := struct {
+		metadata.BaseResp `json:",inline"`
+		Data              *metadata.APIToken `json:"data"`
+	}{}
+	subPath := "/create/auth/api_token"
+
+	err := a.client.Post().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef(subPath).
+		WithHeaders(h).
+		Do().
+		Into(&ret)
+
+	if err != nil {
+		blog.Errorf("CreateAPIToken failed, http request failed, err: %+v", err)
+		return nil, errors.CCHttpError
+	}
+	if ret.CCError() != nil {
+		return nil, ret.CCError()
+	}
+
+	return ret.Data, nil
+}
+
+func (a *auth) ListAPITokens(ctx context.Context, h http.Header, option metadata.ListAPITokenOption) (*metadata.MultipleAPIToken, errors.CCErrorCoder) {
+	ret := struct {
+		metadata.BaseResp `json:",inline"`
+		Data              *metadata.MultipleAPIToken `json:"data"`
+	}{}
+	subPath := "/findmany/auth/api_token"
+
+	err := a.client.Post().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef(subPath).
+		WithHeaders(h).
+		Do().
+		Into(&ret)
+
+	if err != nil {
+		blog.Errorf("ListAPITokens failed, http request failed, err: %+v", err)
+		return nil, errors.CCHttpError
+	}
+	if ret.CCError() != nil {
+		return nil, ret.CCError()
+	}
+
+	return ret.Data, nil
+}
+
+func (a *auth) RevokeAPIToken(ctx context.Context, h http.Header, id int64) errors.CCErrorCoder {
+	ret := metadata.BaseResp{}
+	subPath := "/update/auth/api_token/%d/revoke"
+
+	err := a.client.Put().
+		WithContext(ctx).
+		SubResourcef(subPath, id).
+		WithHeaders(h).
+		Do().
+		Into(&ret)
+
+	if err != nil {
+		blog.Errorf("RevokeAPIToken failed, http request failed, err: %+v", err)
+		return errors.CCHttpError
+	}
+
+	return ret.CCError()
+}
+
+func (a *auth) UpdateAPITokenLastUsed(ctx context.Context, h http.Header, id int64, option metadata.UpdateAPITokenLastUsedOption) errors.CCErrorCoder {
+	ret := metadata.BaseResp{}
+	subPath := "/update/auth/api_token/%d/last_used"
+
+	err := a.client.Put().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef(subPath, id).
+		WithHeaders(h).
+		Do().
+		Into(&ret)
+
+	if err != nil {
+		blog.Errorf("UpdateAPITokenLastUsed failed, http request failed, err: %+v", err)
+		return errors.CCHttpError
+	}
+
+	return ret.CCError()
+}
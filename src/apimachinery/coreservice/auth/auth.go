@@ -17,11 +17,17 @@ import (
 	"net/http"
 
 	"configcenter/src/apimachinery/rest"
+	"configcenter/src/common/errors"
 	"configcenter/src/common/metadata"
 )
 
 type AuthClientInterface interface {
 	SearchAuthResource(ctx context.Context, h http.Header, param metadata.PullResourceParam) (metadata.PullResourceResponse, error)
+
+	CreateAPIToken(ctx context.Context, h http.Header, option metadata.SaveAPITokenOption) (*metadata.APIToken, errors.CCErrorCoder)
+	ListAPITokens(ctx context.Context, h http.Header, option metadata.ListAPITokenOption) (*metadata.MultipleAPIToken, errors.CCErrorCoder)
+	RevokeAPIToken(ctx context.Context, h http.Header, id int64) errors.CCErrorCoder
+	UpdateAPITokenLastUsed(ctx context.Context, h http.Header, id int64, option metadata.UpdateAPITokenLastUsedOption) errors.CCErrorCoder
 }
 
 func NewAuthClientInterface(client rest.ClientInterface) AuthClientInterface {
@@ -0,0 +1,38 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package changefreeze
+
+import (
+	"context"
+	"net/http"
+
+	"configcenter/src/apimachinery/rest"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/metadata"
+)
+
+type ChangeFreezeClientInterface interface {
+	CreateChangeFreezeWindow(ctx context.Context, h http.Header, window metadata.ChangeFreezeWindow) (*metadata.ChangeFreezeWindow, errors.CCErrorCoder)
+	UpdateChangeFreezeWindow(ctx context.Context, h http.Header, id int64, window metadata.ChangeFreezeWindow) (*metadata.ChangeFreezeWindow, errors.CCErrorCoder)
+	DeleteChangeFreezeWindow(ctx context.Context, h http.Header, id int64) errors.CCErrorCoder
+	GetChangeFreezeWindow(ctx context.Context, h http.Header, id int64) (*metadata.ChangeFreezeWindow, errors.CCErrorCoder)
+	SearchChangeFreezeWindows(ctx context.Context, h http.Header, option metadata.SearchChangeFreezeWindowOption) (*metadata.SearchChangeFreezeWindowResult, errors.CCErrorCoder)
+}
+
+func NewChangeFreezeClientInterface(client rest.ClientInterface) ChangeFreezeClientInterface {
+	return &changeFreeze{client: client}
+}
+
+type changeFreeze struct {
+	client rest.ClientInterface
+}
@@ -0,0 +1,144 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package changefreeze
+
+import (
+	"context"
+	"net/http"
+
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/metadata"
+)
+
+func (c *changeFreeze) CreateChangeFreezeWindow(ctx context.Context, h http.Header, window metadata.ChangeFreezeWindow) (*metadata.ChangeFreezeWindow, errors.CCErrorCoder) {
+	ret := struct {
+		metadata.BaseResp `json:",inline"`
+		Data              *metadata.ChangeFreezeWindow `json:"data"`
+	}{}
+	subPath := "/create/change_freeze/window"
+
+	err := c.client.Post().
+		WithContext(ctx).
+		Body(window).
+		SubResourcef(subPath).
+		WithHeaders(h).
+		Do().
+		Into(&ret)
+
+	if err != nil {
+		blog.Errorf("CreateChangeFreezeWindow failed, http request failed, err: %+v", err)
+		return nil, errors.CCHttpError
+	}
+	if ret.CCError() != nil {
+		return nil, ret.CCError()
+	}
+
+	return ret.Data, nil
+}
+
+func (c *changeFreeze) UpdateChangeFreezeWindow(ctx context.Context, h http.Header, id int64, window metadata.ChangeFreezeWindow) (*metadata.ChangeFreezeWindow, errors.CCErrorCoder) {
+	ret := struct {
+		metadata.BaseResp `json:",inline"`
+		Data              *metadata.ChangeFreezeWindow `json:"data"`
+	}{}
+	subPath := "/update/change_freeze/window/%d"
+
+	err := c.client.Put().
+		WithContext(ctx).
+		Body(window).
+		SubResourcef(subPath, id).
+		WithHeaders(h).
+		Do().
+		Into(&ret)
+
+	if err != nil {
+		blog.Errorf("UpdateChangeFreezeWindow failed, http request failed, err: %+v", err)
+		return nil, errors.CCHttpError
+	}
+	if ret.CCError() != nil {
+		return nil, ret.CCError()
+	}
+
+	return ret.Data, nil
+}
+
+func (c *changeFreeze) DeleteChangeFreezeWindow(ctx context.Context, h http.Header, id int64) errors.CCErrorCoder {
+	ret := metadata.BaseResp{}
+	subPath := "/delete/change_freeze/window/%d"
+
+	err := c.client.Delete().
+		WithContext(ctx).
+		SubResourcef(subPath, id).
+		WithHeaders(h).
+		Do().
+		Into(&ret)
+
+	if err != nil {
+		blog.Errorf("DeleteChangeFreezeWindow failed, http request failed, err: %+v", err)
+		return errors.CCHttpError
+	}
+
+	return ret.CCError()
+}
+
+func (c *changeFreeze) GetChangeFreezeWindow(ctx context.Context, h http.Header, id int64) (*metadata.ChangeFreezeWindow, errors.CCErrorCoder) {
+	ret := struct {
+		metadata.BaseResp `json:",inline"`
+		Data              *metadata.ChangeFreezeWindow `json:"data"`
+	}{}
+	subPath := "/find/change_freeze/window/%d"
+
+	err := c.client.Get().
+		WithContext(ctx).
+		SubResourcef(subPath, id).
+		WithHeaders(h).
+		Do().
+		Into(&ret)
+
+	if err != nil {
+		blog.Errorf("GetChangeFreezeWindow failed, http request failed, err: %+v", err)
+		return nil, errors.CCHttpError
+	}
+	if ret.CCError() != nil {
+		return nil, ret.CCError()
+	}
+
+	return ret.Data, nil
+}
+
+func (c *changeFreeze) SearchChangeFreezeWindows(ctx context.Context, h http.Header, option metadata.SearchChangeFreezeWindowOption) (*metadata.SearchChangeFreezeWindowResult, errors.CCErrorCoder) {
+	ret := struct {
+		metadata.BaseResp `json:",inline"`
+		Data              *metadata.SearchChangeFreezeWindowResult `json:"data"`
+	}{}
+	subPath := "/findmany/change_freeze/window"
+
+	err := c.client.Post().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef(subPath).
+		WithHeaders(h).
+		Do().
+		Into(&ret)
+
+	if err != nil {
+		blog.Errorf("SearchChangeFreezeWindows failed, http request failed, err: %+v", err)
+		return nil, errors.CCHttpError
+	}
+	if ret.CCError() != nil {
+		return nil, ret.CCError()
+	}
+
+	return ret.Data, nil
+}
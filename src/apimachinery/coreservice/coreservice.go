@@ -15,9 +15,12 @@ package coreservice
 import (
 	"fmt"
 
+	"configcenter/src/apimachinery/coreservice/admission"
+	"configcenter/src/apimachinery/coreservice/approval"
 	"configcenter/src/apimachinery/coreservice/association"
 	"configcenter/src/apimachinery/coreservice/auditlog"
 	"configcenter/src/apimachinery/coreservice/auth"
+	"configcenter/src/apimachinery/coreservice/changefreeze"
 	"configcenter/src/apimachinery/coreservice/cloud"
 	"configcenter/src/apimachinery/coreservice/count"
 	"configcenter/src/apimachinery/coreservice/common"
@@ -59,6 +62,9 @@ type CoreServiceClientInterface interface {
 	Cloud() cloud.CloudInterface
 	Auth() auth.AuthClientInterface
 	Common() common.CommonInterface
+	Admission() admission.AdmissionClientInterface
+	Approval() approval.ApprovalClientInterface
+	ChangeFreeze() changefreeze.ChangeFreezeClientInterface
 	Event() event.EventClientInterface
 }
 
@@ -152,4 +158,16 @@ func (c *coreService) Common() common.CommonInterface {
 
 func (c *coreService) Event() event.EventClientInterface {
 	return event.NewEventClientInterface(c.restCli)
-}
\ No newline at end of file
+}
+
+func (c *coreService) Admission() admission.AdmissionClientInterface {
+	return admission.NewAdmissionClientInterface(c.restCli)
+}
+
+func (c *coreService) Approval() approval.ApprovalClientInterface {
+	return approval.NewApprovalClientInterface(c.restCli)
+}
+
+func (c *coreService) ChangeFreeze() changefreeze.ChangeFreezeClientInterface {
+	return changefreeze.NewChangeFreezeClientInterface(c.restCli)
+}
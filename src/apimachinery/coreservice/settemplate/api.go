@@ -355,3 +355,77 @@ func (p *setTemplate) ModifySetTemplateSyncStatus(ctx context.Context, header ht
 
 	return ret.CCError()
 }
+
+func (p *setTemplate) UpdateSetTemplateSyncPolicy(ctx context.Context, header http.Header, bizID int64, setTemplateID int64, option metadata.UpdateSetTemplateSyncPolicyOption) (metadata.SetTemplateSyncPolicy, errors.CCErrorCoder) {
+	ret := struct {
+		metadata.BaseResp `json:",inline"`
+		Data              metadata.SetTemplateSyncPolicy `json:"data"`
+	}{}
+	subPath := "/update/topo/set_template_sync_policy/%d/bk_biz_id/%d"
+
+	err := p.client.Put().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef(subPath, setTemplateID, bizID).
+		WithHeaders(header).
+		Do().
+		Into(&ret)
+
+	if err != nil {
+		blog.Errorf("UpdateSetTemplateSyncPolicy failed, http request failed, err: %+v", err)
+		return ret.Data, errors.CCHttpError
+	}
+	if ret.Result == false || ret.Code != 0 {
+		return ret.Data, errors.NewCCError(ret.Code, ret.ErrMsg)
+	}
+
+	return ret.Data, nil
+}
+
+func (p *setTemplate) ListSetTemplateSyncPolicy(ctx context.Context, header http.Header, option metadata.ListSetTemplateSyncPolicyOption) (metadata.MultipleSetTemplateSyncPolicy, errors.CCErrorCoder) {
+	ret := struct {
+		metadata.BaseResp `json:",inline"`
+		Data              metadata.MultipleSetTemplateSyncPolicy `json:"data"`
+	}{}
+	subPath := "/findmany/topo/set_template_sync_policy"
+
+	err := p.client.Post().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef(subPath).
+		WithHeaders(header).
+		Do().
+		Into(&ret)
+
+	if err != nil {
+		blog.Errorf("ListSetTemplateSyncPolicy failed, http request failed, err: %+v", err)
+		return ret.Data, errors.CCHttpError
+	}
+	if ret.Result == false || ret.Code != 0 {
+		return ret.Data, errors.NewCCError(ret.Code, ret.ErrMsg)
+	}
+
+	return ret.Data, nil
+}
+
+func (p *setTemplate) UpdateSetTemplateSyncResult(ctx context.Context, header http.Header, bizID int64, setTemplateID int64, option metadata.UpdateSetTemplateSyncResultOption) errors.CCErrorCoder {
+	ret := struct {
+		metadata.BaseResp
+	}{}
+	subPath := "/update/topo/set_template_sync_result/%d/bk_biz_id/%d"
+
+	err := p.client.Put().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef(subPath, setTemplateID, bizID).
+		WithHeaders(header).
+		Do().
+		Into(&ret)
+
+	if err != nil {
+		blog.Errorf("UpdateSetTemplateSyncResult failed, http request failed, err: %+v", err)
+		return errors.CCHttpError
+	}
+
+	return ret.CCError()
+}
@@ -35,6 +35,9 @@ type SetTemplateInterface interface {
 	DeleteSetTemplateSyncStatus(ctx context.Context, header http.Header, bizID int64, setIDs []int64) errors.CCErrorCoder
 	ListSetTemplateSyncHistory(ctx context.Context, header http.Header, bizID int64, option metadata.ListSetTemplateSyncStatusOption) (metadata.MultipleSetTemplateSyncStatus, errors.CCErrorCoder)
 	ModifySetTemplateSyncStatus(ctx context.Context, header http.Header, setID int64, syncStatus metadata.SyncStatus) errors.CCErrorCoder
+	UpdateSetTemplateSyncPolicy(ctx context.Context, header http.Header, bizID int64, setTemplateID int64, option metadata.UpdateSetTemplateSyncPolicyOption) (metadata.SetTemplateSyncPolicy, errors.CCErrorCoder)
+	ListSetTemplateSyncPolicy(ctx context.Context, header http.Header, option metadata.ListSetTemplateSyncPolicyOption) (metadata.MultipleSetTemplateSyncPolicy, errors.CCErrorCoder)
+	UpdateSetTemplateSyncResult(ctx context.Context, header http.Header, bizID int64, setTemplateID int64, option metadata.UpdateSetTemplateSyncResultOption) errors.CCErrorCoder
 }
 
 func NewSetTemplateInterfaceClient(client rest.ClientInterface) SetTemplateInterface {
@@ -658,3 +658,18 @@ func (hs *hostServer) FindCloudAreaHostCount(ctx context.Context, header http.He
 		Into(resp)
 	return
 }
+
+// HostInventory get the ansible dynamic inventory or the ssh config of the hosts in the businesses
+func (hs *hostServer) HostInventory(ctx context.Context, header http.Header, option *metadata.HostInventoryOption) (resp *metadata.HostInventoryResponse, err error) {
+	resp = new(metadata.HostInventoryResponse)
+	subPath := "/findmany/hosts/inventory"
+
+	err = hs.client.Post().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef(subPath).
+		WithHeaders(header).
+		Do().
+		Into(resp)
+	return
+}
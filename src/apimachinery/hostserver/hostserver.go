@@ -24,6 +24,9 @@ import (
 )
 
 type HostServerClientInterface interface {
+	// Client returns the raw client of host server, it's used to send the approved requests again
+	Client() rest.ClientInterface
+
 	DeleteHostBatch(ctx context.Context, h http.Header, dat interface{}) (resp *metadata.Response, err error)
 	GetHostInstanceProperties(ctx context.Context, ownerID string, hostID string, h http.Header) (resp *metadata.HostInstancePropertiesResult, err error)
 	HostSnapInfo(ctx context.Context, hostID string, h http.Header, dat interface{}) (resp *metadata.HostSnapResult, err error)
@@ -77,6 +80,7 @@ type HostServerClientInterface interface {
 	SearchCloudArea(ctx context.Context, h http.Header, params map[string]interface{}) (resp *metadata.SearchResp, err error)
 	DeleteCloudArea(ctx context.Context, h http.Header, cloudID int64) (resp *metadata.Response, err error)
 	FindCloudAreaHostCount(ctx context.Context, header http.Header, option metadata.CloudAreaHostCount) (resp *metadata.CloudAreaHostCountResult, err error)
+	HostInventory(ctx context.Context, header http.Header, option *metadata.HostInventoryOption) (resp *metadata.HostInventoryResponse, err error)
 }
 
 func NewHostServerClientInterface(c *util.Capability, version string) HostServerClientInterface {
@@ -89,3 +93,7 @@ func NewHostServerClientInterface(c *util.Capability, version string) HostServer
 type hostServer struct {
 	client rest.ClientInterface
 }
+
+func (hs *hostServer) Client() rest.ClientInterface {
+	return hs.client
+}
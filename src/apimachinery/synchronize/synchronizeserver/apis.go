@@ -17,10 +17,12 @@ import (
 
 	"configcenter/src/apimachinery/rest"
 	"configcenter/src/common/metadata"
+	"configcenter/src/common/watch"
 )
 
 type SynchronizeClientInterface interface {
 	Find(ctx context.Context, h http.Header, input *metadata.SynchronizeFindInfoParameter) (resp *metadata.ResponseInstData, err error)
+	Watch(ctx context.Context, h http.Header, opts *watch.WatchEventOptions) (resp *metadata.WatchEventResponse, err error)
 }
 
 func NewSychronizeClientInterface(client rest.ClientInterface) SynchronizeClientInterface {
@@ -17,6 +17,7 @@ import (
 
 	//"configcenter/src/apimachinery/rest"
 	"configcenter/src/common/metadata"
+	"configcenter/src/common/watch"
 )
 
 func (s *synchronize) Find(ctx context.Context, h http.Header, input *metadata.SynchronizeFindInfoParameter) (resp *metadata.ResponseInstData, err error) {
@@ -33,3 +34,19 @@ func (s *synchronize) Find(ctx context.Context, h http.Header, input *metadata.S
 
 	return
 }
+
+// Watch watch the resource events of the synchronize source cmdb
+func (s *synchronize) Watch(ctx context.Context, h http.Header, opts *watch.WatchEventOptions) (resp *metadata.WatchEventResponse, err error) {
+	resp = new(metadata.WatchEventResponse)
+	subPath := "/watch/resource/%s"
+
+	err = s.client.Post().
+		WithContext(ctx).
+		Body(opts).
+		SubResourcef(subPath, opts.Resource).
+		WithHeaders(h).
+		Do().
+		Into(resp)
+
+	return
+}
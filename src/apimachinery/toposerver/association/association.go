@@ -163,6 +163,20 @@ func (asst *Association) SearchAssociationRelatedInst(ctx context.Context, h htt
 
 	return
 }
+func (asst *Association) SearchInstAssociationGraph(ctx context.Context, h http.Header, request *metadata.SearchInstAssociationGraphRequest) (resp *metadata.SearchInstAssociationGraphResult, err error) {
+	resp = new(metadata.SearchInstAssociationGraphResult)
+	subPath := "/find/instassociation/graph"
+
+	err = asst.client.Post().
+		WithContext(ctx).
+		Body(request).
+		SubResourcef(subPath).
+		WithHeaders(h).
+		Do().
+		Into(resp)
+
+	return
+}
 func (asst *Association) CreateInst(ctx context.Context, h http.Header, request *metadata.CreateAssociationInstRequest) (resp *metadata.CreateAssociationInstResult, err error) {
 	resp = new(metadata.CreateAssociationInstResult)
 	subPath := "/inst/association/action/create"
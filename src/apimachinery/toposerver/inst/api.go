@@ -32,11 +32,14 @@ type InstanceInterface interface {
 	GetAppBasicInfo(ctx context.Context, h http.Header, bizID int64) (resp *metadata.AppBasicInfoResult, err error)
 	GetDefaultApp(ctx context.Context, ownerID string, h http.Header) (resp *metadata.SearchInstResult, err error)
 	CreateDefaultApp(ctx context.Context, ownerID string, h http.Header, data map[string]interface{}) (resp *metadata.CreateInstResult, err error)
+	CloneBizTopo(ctx context.Context, h http.Header, bizID int64, input *metadata.CloneBizTopoRequest) (resp *metadata.CloneBizTopoTaskResponse, err error)
+	GetCloneBizTopoTask(ctx context.Context, h http.Header, bizID int64) (resp *metadata.CloneBizTopoTaskResponse, err error)
 	SearchAuditDict(ctx context.Context, h http.Header) (resp *metadata.Response, err error)
 	SearchAuditList(ctx context.Context, h http.Header, input *metadata.AuditQueryInput) (*metadata.Response, error)
 	SearchAuditDetail(ctx context.Context, h http.Header, input *metadata.AuditDetailQueryInput) (*metadata.Response, error)
 	GetInternalModule(ctx context.Context, ownerID, appID string, h http.Header) (resp *metadata.SearchInnterAppTopoResult, err error)
 	SearchBriefBizTopo(ctx context.Context, h http.Header, bizID int64, input map[string]interface{}) (resp *metadata.SearchBriefBizTopoResult, err error)
+	DiffBizTopo(ctx context.Context, h http.Header, bizID int64, input *metadata.TopoDiffRequest) (resp *metadata.TopoDiffResponse, err error)
 	CreateInst(ctx context.Context, ownerID string, objID string, h http.Header, dat interface{}) (resp *metadata.CreateInstResult, err error)
 	DeleteInst(ctx context.Context, ownerID string, objID string, instID int64, h http.Header) (resp *metadata.Response, err error)
 	UpdateInst(ctx context.Context, ownerID string, objID string, instID int64, h http.Header, dat map[string]interface{}) (resp *metadata.Response, err error)
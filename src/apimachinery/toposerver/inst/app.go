@@ -125,3 +125,29 @@ func (t *instanceClient) CreateDefaultApp(ctx context.Context, ownerID string, h
 		Into(resp)
 	return
 }
+
+func (t *instanceClient) CloneBizTopo(ctx context.Context, h http.Header, bizID int64, input *metadata.CloneBizTopoRequest) (resp *metadata.CloneBizTopoTaskResponse, err error) {
+	resp = new(metadata.CloneBizTopoTaskResponse)
+	subPath := "/createmany/topo/clone/biz/%d"
+	err = t.client.Post().
+		WithContext(ctx).
+		Body(input).
+		SubResourcef(subPath, bizID).
+		WithHeaders(h).
+		Do().
+		Into(resp)
+	return
+}
+
+func (t *instanceClient) GetCloneBizTopoTask(ctx context.Context, h http.Header, bizID int64) (resp *metadata.CloneBizTopoTaskResponse, err error) {
+	resp = new(metadata.CloneBizTopoTaskResponse)
+	subPath := "/find/topo/clone/biz/%d/task"
+	err = t.client.Post().
+		WithContext(ctx).
+		Body(nil).
+		SubResourcef(subPath, bizID).
+		WithHeaders(h).
+		Do().
+		Into(resp)
+	return
+}
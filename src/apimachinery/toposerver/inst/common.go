@@ -117,3 +117,17 @@ func (t *instanceClient) SearchBriefBizTopo(ctx context.Context, h http.Header,
 		Into(resp)
 	return
 }
+
+func (t *instanceClient) DiffBizTopo(ctx context.Context, h http.Header, bizID int64, input *metadata.TopoDiffRequest) (resp *metadata.TopoDiffResponse, err error) {
+	resp = new(metadata.TopoDiffResponse)
+	subPath := "/find/topo/diff/biz/%d"
+
+	err = t.client.Post().
+		WithContext(ctx).
+		Body(input).
+		SubResourcef(subPath, bizID).
+		WithHeaders(h).
+		Do().
+		Into(resp)
+	return
+}
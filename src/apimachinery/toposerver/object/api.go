@@ -55,6 +55,9 @@ type ObjectInterface interface {
 	SearchObjectUnique(ctx context.Context, objID string, h http.Header) (resp *metadata.Response, err error)
 	UpdateObjectUnique(ctx context.Context, objID string, h http.Header, uniqueID uint64, data *metadata.UpdateUniqueRequest) (resp *metadata.Response, err error)
 	DeleteObjectUnique(ctx context.Context, objID string, h http.Header, uniqueID uint64) (resp *metadata.Response, err error)
+	ExportModelSchema(ctx context.Context, h http.Header, option *metadata.ExportModelSchemaOption) (resp *metadata.ExportModelSchemaResult, err error)
+	PlanModelSchema(ctx context.Context, h http.Header, option *metadata.ModelSchemaOption) (resp *metadata.ModelSchemaPlanResult, err error)
+	ApplyModelSchema(ctx context.Context, h http.Header, option *metadata.ModelSchemaOption) (resp *metadata.ModelSchemaPlanResult, err error)
 }
 
 func NewObjectInterface(client rest.ClientInterface) ObjectInterface {
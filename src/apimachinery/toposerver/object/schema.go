@@ -0,0 +1,62 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package object
+
+import (
+	"context"
+	"net/http"
+
+	"configcenter/src/common/metadata"
+)
+
+func (t *object) ExportModelSchema(ctx context.Context, h http.Header, option *metadata.ExportModelSchemaOption) (resp *metadata.ExportModelSchemaResult, err error) {
+	resp = new(metadata.ExportModelSchemaResult)
+	subPath := "/find/model/schema/export"
+
+	err = t.client.Post().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef(subPath).
+		WithHeaders(h).
+		Do().
+		Into(resp)
+	return
+}
+
+func (t *object) PlanModelSchema(ctx context.Context, h http.Header, option *metadata.ModelSchemaOption) (resp *metadata.ModelSchemaPlanResult, err error) {
+	resp = new(metadata.ModelSchemaPlanResult)
+	subPath := "/find/model/schema/plan"
+
+	err = t.client.Post().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef(subPath).
+		WithHeaders(h).
+		Do().
+		Into(resp)
+	return
+}
+
+func (t *object) ApplyModelSchema(ctx context.Context, h http.Header, option *metadata.ModelSchemaOption) (resp *metadata.ModelSchemaPlanResult, err error) {
+	resp = new(metadata.ModelSchemaPlanResult)
+	subPath := "/update/model/schema/apply"
+
+	err = t.client.Post().
+		WithContext(ctx).
+		Body(option).
+		SubResourcef(subPath).
+		WithHeaders(h).
+		Do().
+		Into(resp)
+	return
+}
@@ -25,6 +25,8 @@ import (
 )
 
 type TopoServerClientInterface interface {
+	// Client returns the raw client of topo server, it's used to send the approved requests again
+	Client() rest.ClientInterface
 	Instance() inst.InstanceInterface
 	Object() object.ObjectInterface
 	Association() association.AssociationInterface
@@ -43,6 +45,10 @@ type topoServer struct {
 	restCli rest.ClientInterface
 }
 
+func (t *topoServer) Client() rest.ClientInterface {
+	return t.restCli
+}
+
 func (t *topoServer) Instance() inst.InstanceInterface {
 	return inst.NewInstanceClient(t.restCli)
 }
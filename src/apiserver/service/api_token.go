@@ -0,0 +1,520 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"configcenter/src/ac/meta"
+	"configcenter/src/ac/parser"
+	"configcenter/src/common"
+	cc "configcenter/src/common/backbone/configcenter"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+
+	"github.com/emicklei/go-restful"
+)
+
+const (
+	// apiTokenCacheTTL 令牌校验结果在apiserver内存中的缓存时间, 吊销令牌最多延迟这么久生效
+	apiTokenCacheTTL = 30 * time.Second
+	// apiTokenCacheMaxSize 缓存的最大条目数, 超过后清空, 避免大量无效令牌撑爆内存
+	apiTokenCacheMaxSize = 10000
+	// apiTokenTouchInterval 记录令牌最近使用时间的最小间隔
+	apiTokenTouchInterval = time.Minute
+)
+
+type cachedAPIToken struct {
+	// token is nil when the token does not exist
+	token   *metadata.APIToken
+	scopes  []meta.APITokenScope
+	fetched time.Time
+}
+
+// apiTokenCache caches the api tokens by their hash, so that the requests do not hit coreservice every time.
+type apiTokenCache struct {
+	lock    sync.Mutex
+	entries map[string]*cachedAPIToken
+	touched map[int64]time.Time
+}
+
+func newAPITokenCache() *apiTokenCache {
+	return &apiTokenCache{
+		entries: make(map[string]*cachedAPIToken),
+		touched: make(map[int64]time.Time),
+	}
+}
+
+func (c *apiTokenCache) get(hash string, now time.Time) (*cachedAPIToken, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	entry, exists := c.entries[hash]
+	if !exists || now.Sub(entry.fetched) > apiTokenCacheTTL {
+		return nil, false
+	}
+	return entry, true
+}
+
+func (c *apiTokenCache) set(hash string, entry *cachedAPIToken) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if len(c.entries) >= apiTokenCacheMaxSize {
+		c.entries = make(map[string]*cachedAPIToken)
+	}
+	c.entries[hash] = entry
+}
+
+// invalidate removes the token from the cache, used when the token is revoked.
+func (c *apiTokenCache) invalidate(id int64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for hash, entry := range c.entries {
+		if entry.token != nil && entry.token.ID == id {
+			delete(c.entries, hash)
+		}
+	}
+	delete(c.touched, id)
+}
+
+// shouldTouch checks if the last used time of the token needs to be recorded.
+func (c *apiTokenCache) shouldTouch(id int64, now time.Time) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if last, exists := c.touched[id]; exists && now.Sub(last) < apiTokenTouchInterval {
+		return false
+	}
+	if len(c.touched) >= apiTokenCacheMaxSize {
+		c.touched = make(map[int64]time.Time)
+	}
+	c.touched[id] = now
+	return true
+}
+
+// hashAPIToken returns the hex sha256 digest of the token, only the digest is stored.
+func hashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func generateAPIToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return metadata.APITokenPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// bearerAPIToken gets the api token from the Authorization header, the bearer tokens that are not
+// issued by cmdb, such as the ones of the api gateway, are ignored.
+func bearerAPIToken(header http.Header) string {
+	authorization := strings.TrimSpace(header.Get("Authorization"))
+	if len(authorization) < 7 || !strings.EqualFold(authorization[:7], "Bearer ") {
+		return ""
+	}
+	token := strings.TrimSpace(authorization[7:])
+	if !strings.HasPrefix(token, metadata.APITokenPrefix) {
+		return ""
+	}
+	return token
+}
+
+// trustedProxies are the networks of the proxies in front of apiserver, the X-Forwarded-For header
+// is only used when the request comes from one of them.
+type trustedProxies []*net.IPNet
+
+// loadTrustedProxies loads the trusted proxies from configuration apiServer.trustedProxies, the items
+// are ips or cidrs, the invalid items are ignored.
+func loadTrustedProxies() trustedProxies {
+	if !cc.IsExist("apiServer.trustedProxies") {
+		return nil
+	}
+
+	items := make([]string, 0)
+	if err := cc.UnmarshalKey("apiServer.trustedProxies", &items); err != nil {
+		blog.Errorf("parse apiServer.trustedProxies config failed, err: %v", err)
+		return nil
+	}
+
+	proxies := make(trustedProxies, 0, len(items))
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		if !strings.Contains(item, "/") {
+			if ip := net.ParseIP(item); ip != nil && ip.To4() != nil {
+				item += "/32"
+			} else {
+				item += "/128"
+			}
+		}
+
+		_, network, err := net.ParseCIDR(item)
+		if err != nil {
+			blog.Errorf("trusted proxy %s is invalid, skip it, err: %v", item, err)
+			continue
+		}
+		proxies = append(proxies, network)
+	}
+	return proxies
+}
+
+func (p trustedProxies) contains(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, network := range p {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// remoteIP returns the client ip of the request, it's the connection address unless the connection
+// comes from a trusted proxy.
+func (p trustedProxies) remoteIP(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+	if !p.contains(host) {
+		return host
+	}
+
+	// the right most address that is not a trusted proxy is the client, the left ones can be forged
+	forwarded := strings.Split(req.Header.Get("X-Forwarded-For"), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		ip := strings.TrimSpace(forwarded[i])
+		if net.ParseIP(ip) == nil {
+			break
+		}
+		if !p.contains(ip) {
+			return ip
+		}
+	}
+	return host
+}
+
+// resolveAPIToken gets the api token by the plain token, returns nil if the token does not exist.
+func (s *service) resolveAPIToken(ctx context.Context, rid string, token string) (*cachedAPIToken, error) {
+	hash := hashAPIToken(token)
+	now := time.Now()
+	if entry, exists := s.apiTokens.get(hash, now); exists {
+		return entry, nil
+	}
+
+	header := util.BuildHeader(common.CCSystemOperatorUserName, common.BKSuperOwnerID)
+	header.Set(common.BKHTTPCCRequestID, rid)
+	option := metadata.ListAPITokenOption{
+		TokenHash: hash,
+		Page:      metadata.BasePage{Limit: 1},
+	}
+	result, err := s.clientSet.CoreService().Auth().ListAPITokens(ctx, header, option)
+	if err != nil {
+		blog.Errorf("get api token by hash failed, err: %v, rid: %s", err, rid)
+		return nil, err
+	}
+
+	entry := &cachedAPIToken{fetched: now}
+	if len(result.Info) > 0 {
+		entry.token = &result.Info[0]
+		for _, scope := range entry.token.Scopes {
+			parsed, err := meta.ParseAPITokenScope(scope)
+			if err != nil {
+				// the scopes are validated when created, skip the scopes that are no longer supported
+				blog.Warnf("api token %d has invalid scope %s, err: %v, rid: %s", entry.token.ID, scope, err, rid)
+				continue
+			}
+			entry.scopes = append(entry.scopes, parsed)
+		}
+	}
+	s.apiTokens.set(hash, entry)
+	return entry, nil
+}
+
+// apiTokenFilter authenticates the requests with api tokens, the user and supplier account headers are derived
+// from the token, and the resources of the request must be in the scopes of the token.
+func (s *service) apiTokenFilter(errFunc func() errors.CCErrorIf) func(req *restful.Request,
+	resp *restful.Response, fchain *restful.FilterChain) {
+
+	return func(req *restful.Request, resp *restful.Response, fchain *restful.FilterChain) {
+		header := req.Request.Header
+		// the api token header can only be set by apiserver
+		header.Del(common.BKHTTPAPITokenID)
+		// the approved requests are only executed by topo server, they can not be sent by the users
+		header.Del(common.BKHTTPApprovalRequestID)
+
+		token := bearerAPIToken(header)
+		if token == "" {
+			fchain.ProcessFilter(req, resp)
+			return
+		}
+
+		rid := util.GetHTTPCCRequestID(header)
+		if rid == "" {
+			rid = util.GenerateRID()
+			header.Set(common.BKHTTPCCRequestID, rid)
+			resp.Header().Set(common.BKHTTPCCRequestID, rid)
+		}
+		defErr := errFunc().CreateDefaultCCErrorIf(util.GetLanguage(header))
+		writeErr := func(status int, code int) {
+			resp.WriteHeaderAndJson(status, metadata.BaseResp{
+				Code:   code,
+				ErrMsg: defErr.Error(code).Error(),
+				Result: false,
+			}, restful.MIME_JSON)
+		}
+
+		ctx := context.WithValue(req.Request.Context(), common.ContextRequestIDField, rid)
+		entry, err := s.resolveAPIToken(ctx, rid, token)
+		if err != nil {
+			writeErr(http.StatusInternalServerError, common.CCErrCommHTTPDoRequestFailed)
+			return
+		}
+		if entry.token == nil || !entry.token.IsValid(time.Now()) {
+			blog.Errorf("request %s %s with invalid api token, caller: %s, rid: %s", req.Request.Method,
+				req.Request.URL.Path, req.Request.RemoteAddr, rid)
+			writeErr(http.StatusUnauthorized, common.CCErrAPITokenInvalid)
+			return
+		}
+		apiToken := entry.token
+
+		// api tokens can not mint or revoke tokens, otherwise a leaked token can escalate itself
+		if parser.IsAPITokenManageRequest(req.Request) {
+			writeErr(http.StatusForbidden, common.CCErrAPITokenManageForbidden)
+			return
+		}
+
+		header.Del("Authorization")
+		header.Del(common.BKHTTPOwner)
+		header.Set(common.BKHTTPHeaderUser, apiToken.User)
+		header.Set(common.BKHTTPOwnerID, apiToken.SupplierAccount)
+		header.Set(common.BKHTTPAPITokenID, strconv.FormatInt(apiToken.ID, 10))
+
+		attribute, err := parser.ParseAttribute(req, s.engine)
+		if err != nil {
+			blog.Errorf("parse auth attribute for %s %s with api token %d failed, err: %v, rid: %s",
+				req.Request.Method, req.Request.URL.Path, apiToken.ID, err, rid)
+			writeErr(http.StatusForbidden, common.CCErrCommParseAuthAttributeFailed)
+			return
+		}
+		if resource, ok := parser.CheckAPITokenScopes(req.Request, attribute, entry.scopes); !ok {
+			blog.Errorf("api token %d has no scope of resource %s action %s, scopes: %v, rid: %s", apiToken.ID,
+				resource.Type, resource.Action, apiToken.Scopes, rid)
+			writeErr(http.StatusForbidden, common.CCErrAPITokenScopeDenied)
+			return
+		}
+
+		blog.Infof("request %s %s with api token %d(%s) of user %s, caller: %s, rid: %s", req.Request.Method,
+			req.Request.URL.Path, apiToken.ID, apiToken.Name, apiToken.User, req.Request.RemoteAddr, rid)
+
+		now := time.Now()
+		if s.apiTokens.shouldTouch(apiToken.ID, now) {
+			go s.touchAPIToken(rid, apiToken.ID, metadata.UpdateAPITokenLastUsedOption{
+				LastUsedTime: now,
+				LastUsedIP:   s.trustedProxies.remoteIP(req.Request),
+			})
+		}
+
+		fchain.ProcessFilter(req, resp)
+	}
+}
+
+func (s *service) touchAPIToken(rid string, id int64, option metadata.UpdateAPITokenLastUsedOption) {
+	header := util.BuildHeader(common.CCSystemOperatorUserName, common.BKSuperOwnerID)
+	header.Set(common.BKHTTPCCRequestID, rid)
+	ctx := context.WithValue(context.Background(), common.ContextRequestIDField, rid)
+	if err := s.clientSet.CoreService().Auth().UpdateAPITokenLastUsed(ctx, header, id, option); err != nil {
+		blog.Errorf("update api token %d last used info failed, err: %v, rid: %s", id, err, rid)
+	}
+}
+
+func apiTokenAuditData(token *metadata.APIToken) map[string]interface{} {
+	return map[string]interface{}{
+		common.BKFieldID:      token.ID,
+		common.BKFieldName:    token.Name,
+		"kind":                token.Kind,
+		"bk_username":         token.User,
+		"hint":                token.Hint,
+		"scopes":              token.Scopes,
+		"expire_time":         token.ExpireTime,
+		"revoked":             token.Revoked,
+		common.CreatorField:   token.Creator,
+		common.BKOwnerIDField: token.SupplierAccount,
+	}
+}
+
+func (s *service) saveAPITokenAudit(ctx context.Context, header http.Header, rid string, token *metadata.APIToken,
+	action metadata.ActionType) {
+
+	detail := &metadata.BasicContent{}
+	switch action {
+	case metadata.AuditCreate:
+		detail.CurData = apiTokenAuditData(token)
+	default:
+		detail.PreData = apiTokenAuditData(token)
+		detail.UpdateFields = map[string]interface{}{"revoked": true}
+	}
+	audit := metadata.AuditLog{
+		AuditType:       metadata.APITokenType,
+		ResourceType:    metadata.APITokenRes,
+		Action:          action,
+		ResourceID:      token.ID,
+		ResourceName:    token.Name,
+		OperationDetail: &metadata.BasicOpDetail{Details: detail},
+	}
+	if _, err := s.clientSet.CoreService().Audit().SaveAuditLog(ctx, header, audit); err != nil {
+		blog.Errorf("save api token %d audit log failed, err: %v, rid: %s", token.ID, err, rid)
+	}
+}
+
+// CreateAPIToken create a personal or service account api token, the plain token is only returned this time.
+func (s *service) CreateAPIToken(req *restful.Request, resp *restful.Response) {
+	header := req.Request.Header
+	rid := util.GetHTTPCCRequestID(header)
+	defErr := s.engine.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(header))
+	ctx := context.WithValue(req.Request.Context(), common.ContextRequestIDField, rid)
+
+	option := metadata.CreateAPITokenOption{}
+	if err := json.NewDecoder(req.Request.Body).Decode(&option); err != nil {
+		blog.Errorf("create api token, but decode body failed, err: %v, rid: %s", err, rid)
+		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Error(common.CCErrCommJSONUnmarshalFailed)})
+		return
+	}
+	if key, err := option.Validate(); err != nil {
+		blog.Errorf("create api token, but option is invalid, key: %s, err: %v, rid: %s", key, err, rid)
+		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Errorf(common.CCErrCommParamsInvalid, key)})
+		return
+	}
+	for _, scope := range option.Scopes {
+		if _, err := meta.ParseAPITokenScope(scope); err != nil {
+			blog.Errorf("create api token, but scope %s is invalid, err: %v, rid: %s", scope, err, rid)
+			resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Errorf(common.CCErrCommParamsInvalid, "scopes")})
+			return
+		}
+	}
+
+	plain, err := generateAPIToken()
+	if err != nil {
+		blog.Errorf("create api token, but generate token failed, err: %v, rid: %s", err, rid)
+		resp.WriteError(http.StatusInternalServerError, &metadata.RespError{Msg: defErr.Error(common.CCErrCommInternalServerError)})
+		return
+	}
+
+	user := util.GetUser(header)
+	if option.Kind == metadata.APITokenKindService {
+		user = option.ServiceAccount
+	}
+	now := time.Now()
+	token := metadata.APIToken{
+		Name:        option.Name,
+		Description: option.Description,
+		Kind:        option.Kind,
+		User:        user,
+		Hint:        plain[len(plain)-4:],
+		Scopes:      option.Scopes,
+		ExpireTime:  now.AddDate(0, 0, option.ExpireDays),
+	}
+	saveOption := metadata.SaveAPITokenOption{
+		Token:     token,
+		TokenHash: hashAPIToken(plain),
+	}
+	created, ccErr := s.clientSet.CoreService().Auth().CreateAPIToken(ctx, header, saveOption)
+	if ccErr != nil {
+		blog.Errorf("create api token %s failed, err: %v, rid: %s", option.Name, ccErr, rid)
+		resp.WriteError(http.StatusInternalServerError, &metadata.RespError{Msg: ccErr})
+		return
+	}
+
+	s.saveAPITokenAudit(ctx, header, rid, created, metadata.AuditCreate)
+	resp.WriteEntity(metadata.NewSuccessResp(metadata.CreatedAPIToken{APIToken: *created, Token: plain}))
+}
+
+// ListAPITokens list the api tokens created by the user
+func (s *service) ListAPITokens(req *restful.Request, resp *restful.Response) {
+	header := req.Request.Header
+	rid := util.GetHTTPCCRequestID(header)
+	defErr := s.engine.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(header))
+	ctx := context.WithValue(req.Request.Context(), common.ContextRequestIDField, rid)
+
+	option := metadata.ListAPITokenOption{}
+	if err := json.NewDecoder(req.Request.Body).Decode(&option); err != nil {
+		blog.Errorf("list api tokens, but decode body failed, err: %v, rid: %s", err, rid)
+		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Error(common.CCErrCommJSONUnmarshalFailed)})
+		return
+	}
+	option.Creator = util.GetUser(header)
+	option.TokenHash = ""
+
+	result, err := s.clientSet.CoreService().Auth().ListAPITokens(ctx, header, option)
+	if err != nil {
+		blog.Errorf("list api tokens failed, option: %+v, err: %v, rid: %s", option, err, rid)
+		resp.WriteError(http.StatusInternalServerError, &metadata.RespError{Msg: err})
+		return
+	}
+	resp.WriteEntity(metadata.NewSuccessResp(result))
+}
+
+// RevokeAPIToken revoke an api token created by the user, a revoked token can not be used anymore.
+func (s *service) RevokeAPIToken(req *restful.Request, resp *restful.Response) {
+	header := req.Request.Header
+	rid := util.GetHTTPCCRequestID(header)
+	defErr := s.engine.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(header))
+	ctx := context.WithValue(req.Request.Context(), common.ContextRequestIDField, rid)
+
+	id, err := strconv.ParseInt(req.PathParameter("id"), 10, 64)
+	if err != nil || id <= 0 {
+		blog.Errorf("revoke api token, but id %s is invalid, rid: %s", req.PathParameter("id"), rid)
+		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Errorf(common.CCErrCommParamsInvalid, "id")})
+		return
+	}
+
+	option := metadata.ListAPITokenOption{
+		IDs:     []int64{id},
+		Creator: util.GetUser(header),
+		Page:    metadata.BasePage{Limit: 1},
+	}
+	result, ccErr := s.clientSet.CoreService().Auth().ListAPITokens(ctx, header, option)
+	if ccErr != nil {
+		blog.Errorf("revoke api token %d, but get token failed, err: %v, rid: %s", id, ccErr, rid)
+		resp.WriteError(http.StatusInternalServerError, &metadata.RespError{Msg: ccErr})
+		return
+	}
+	if len(result.Info) == 0 {
+		blog.Errorf("revoke api token %d, but token is not found or not created by the user, rid: %s", id, rid)
+		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Errorf(common.CCErrCommParamsInvalid, "id")})
+		return
+	}
+
+	if ccErr := s.clientSet.CoreService().Auth().RevokeAPIToken(ctx, header, id); ccErr != nil {
+		blog.Errorf("revoke api token %d failed, err: %v, rid: %s", id, ccErr, rid)
+		resp.WriteError(http.StatusInternalServerError, &metadata.RespError{Msg: ccErr})
+		return
+	}
+	s.apiTokens.invalidate(id)
+
+	s.saveAPITokenAudit(ctx, header, rid, &result.Info[0], metadata.AuditUpdate)
+	resp.WriteEntity(metadata.NewSuccessResp(nil))
+}
@@ -0,0 +1,76 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"net"
+	"net/http"
+	"testing"
+)
+
+func TestTrustedProxiesRemoteIP(t *testing.T) {
+	_, network, _ := net.ParseCIDR("10.0.0.0/24")
+	proxies := trustedProxies{network}
+
+	cases := []struct {
+		name       string
+		proxies    trustedProxies
+		remoteAddr string
+		forwarded  string
+		expect     string
+	}{
+		{
+			name:       "no trusted proxy ignores forwarded header",
+			remoteAddr: "192.168.1.1:5000",
+			forwarded:  "1.1.1.1",
+			expect:     "192.168.1.1",
+		},
+		{
+			name:       "untrusted connection ignores forwarded header",
+			proxies:    proxies,
+			remoteAddr: "192.168.1.1:5000",
+			forwarded:  "1.1.1.1",
+			expect:     "192.168.1.1",
+		},
+		{
+			name:       "trusted proxy uses the right most untrusted address",
+			proxies:    proxies,
+			remoteAddr: "10.0.0.2:5000",
+			forwarded:  "1.1.1.1, 2.2.2.2, 10.0.0.3",
+			expect:     "2.2.2.2",
+		},
+		{
+			name:       "trusted proxy without forwarded header",
+			proxies:    proxies,
+			remoteAddr: "10.0.0.2:5000",
+			expect:     "10.0.0.2",
+		},
+		{
+			name:       "trusted proxy with invalid forwarded address",
+			proxies:    proxies,
+			remoteAddr: "10.0.0.2:5000",
+			forwarded:  "1.1.1.1, unknown",
+			expect:     "10.0.0.2",
+		},
+	}
+
+	for _, c := range cases {
+		req := &http.Request{RemoteAddr: c.remoteAddr, Header: make(http.Header)}
+		if c.forwarded != "" {
+			req.Header.Set("X-Forwarded-For", c.forwarded)
+		}
+		if got := c.proxies.remoteIP(req); got != c.expect {
+			t.Errorf("%s: got remote ip %s, expect %s", c.name, got, c.expect)
+		}
+	}
+}
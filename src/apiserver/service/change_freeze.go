@@ -0,0 +1,198 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"configcenter/src/ac/meta"
+	"configcenter/src/ac/parser"
+	"configcenter/src/common"
+	"configcenter/src/common/auth"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/changefreeze"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+
+	"github.com/emicklei/go-restful"
+)
+
+// changeFreezeCacheTTL 变更冻结窗口在apiserver内存中的缓存时间, 窗口的修改最多延迟这么久生效
+const changeFreezeCacheTTL = 30 * time.Second
+
+// changeFreezeCache caches the enabled change freeze windows of all the supplier accounts, so that the write
+// requests do not hit coreservice every time.
+type changeFreezeCache struct {
+	lock    sync.Mutex
+	windows []metadata.ChangeFreezeWindow
+	fetched time.Time
+}
+
+// activeChangeFreezeWindows returns the windows of the supplier account which are active now
+func (s *service) activeChangeFreezeWindows(ctx context.Context, rid, ownerID string, now time.Time) (
+	[]metadata.ChangeFreezeWindow, error) {
+
+	cache := s.changeFreeze
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+
+	if now.Sub(cache.fetched) > changeFreezeCacheTTL {
+		header := util.BuildHeader(common.CCSystemOperatorUserName, common.BKSuperOwnerID)
+		header.Set(common.BKHTTPCCRequestID, rid)
+		cond := mapstr.MapStr{"enabled": true}
+		windows, err := changefreeze.SearchWindows(ctx, header, s.clientSet.CoreService(), cond)
+		if err != nil {
+			blog.Errorf("search enabled change freeze windows failed, err: %v, rid: %s", err, rid)
+			return nil, err
+		}
+		cache.windows = windows
+		cache.fetched = now
+	}
+
+	windows := make([]metadata.ChangeFreezeWindow, 0)
+	for _, window := range cache.windows {
+		if window.OwnerID == ownerID && window.Schedule.IsActive(now) {
+			windows = append(windows, window)
+		}
+	}
+	return windows, nil
+}
+
+// canOverrideChangeFreeze checks if the user has the permission to make changes in an active change freeze window
+func (s *service) canOverrideChangeFreeze(ctx context.Context, header http.Header) (bool, error) {
+	if !auth.EnableAuthorize() {
+		return true, nil
+	}
+
+	user := meta.UserInfo{UserName: util.GetUser(header), SupplierAccount: util.GetOwnerID(header)}
+	decisions, err := s.authorizer.AuthorizeBatch(ctx, header, user, parser.ChangeFreezeOverrideAttribute)
+	if err != nil {
+		return false, err
+	}
+	return len(decisions) > 0 && decisions[0].Authorized, nil
+}
+
+// changeFreezeFilter rejects the changes of the hosts, topology, service instances and processes in the active
+// change freeze windows, unless the user or app is exempted by the window, or the user overrides the windows with
+// a reason in the override header, which needs the permission of the global settings and is audited.
+func (s *service) changeFreezeFilter(errFunc func() errors.CCErrorIf) func(req *restful.Request,
+	resp *restful.Response, fchain *restful.FilterChain) {
+
+	return func(req *restful.Request, resp *restful.Response, fchain *restful.FilterChain) {
+		header := req.Request.Header
+		reason := strings.TrimSpace(header.Get(common.BKHTTPChangeFreezeOverride))
+		// the override is checked here, the scene servers do not need it
+		header.Del(common.BKHTTPChangeFreezeOverride)
+		if req.Request.Method == http.MethodGet {
+			fchain.ProcessFilter(req, resp)
+			return
+		}
+
+		rid := util.GetHTTPCCRequestID(header)
+		ctx := context.WithValue(req.Request.Context(), common.ContextRequestIDField, rid)
+		defErr := errFunc().CreateDefaultCCErrorIf(util.GetLanguage(header))
+		writeErr := func(err errors.CCErrorCoder) {
+			resp.WriteAsJson(metadata.BaseResp{
+				Code:   err.GetCode(),
+				ErrMsg: err.Error(),
+				Result: false,
+			})
+		}
+
+		windows, err := s.activeChangeFreezeWindows(ctx, rid, util.GetOwnerID(header), time.Now())
+		if err != nil {
+			writeErr(defErr.CCError(common.CCErrCommHTTPDoRequestFailed))
+			return
+		}
+		if len(windows) == 0 {
+			fchain.ProcessFilter(req, resp)
+			return
+		}
+
+		var resources []parser.FrozenResource
+		attribute, err := parser.ParseAttribute(req, s.engine)
+		if err != nil {
+			// the request may still change the frozen resources, they are worked out from the url instead
+			blog.Warnf("parse auth attribute for %s %s failed, check change freeze by the url, err: %v, rid: %s",
+				req.Request.Method, req.Request.URL.Path, err, rid)
+			resources = parser.ChangeFreezeResourcesByPath(req.Request)
+		} else {
+			resources = parser.ChangeFreezeResources(req.Request, attribute)
+		}
+
+		user := util.GetUser(header)
+		frozen, frozenResource := changefreeze.Frozen(windows, time.Now(), user,
+			header.Get(common.BKHTTPRequestAppCode), resources)
+		if len(frozen) == 0 {
+			fchain.ProcessFilter(req, resp)
+			return
+		}
+
+		if len(reason) == 0 {
+			blog.Errorf("%s %s by %s is rejected by change freeze window %d, resource: %+v, rid: %s",
+				req.Request.Method, req.Request.URL.Path, user, frozen[0].ID, frozenResource, rid)
+			writeErr(defErr.CCErrorf(common.CCErrCommChangeFrozen, frozen[0].Name, frozenResource.Resource))
+			return
+		}
+
+		allowed, err := s.canOverrideChangeFreeze(ctx, header)
+		if err != nil {
+			blog.Errorf("check change freeze override permission of %s failed, err: %v, rid: %s", user, err, rid)
+			writeErr(defErr.CCError(common.CCErrCommCheckAuthorizeFailed))
+			return
+		}
+		if !allowed {
+			blog.Errorf("%s has no permission to override change freeze window %d, rid: %s", user, frozen[0].ID, rid)
+			writeErr(defErr.CCErrorf(common.CCErrCommChangeFreezeOverrideDenied, frozen[0].Name))
+			return
+		}
+
+		blog.Warnf("%s %s by %s overrides change freeze windows, reason: %s, rid: %s", req.Request.Method,
+			req.Request.URL.Path, user, reason, rid)
+		for index := range frozen {
+			s.saveChangeFreezeOverrideAudit(ctx, header, rid, &frozen[index], req.Request, reason)
+		}
+		fchain.ProcessFilter(req, resp)
+	}
+}
+
+func (s *service) saveChangeFreezeOverrideAudit(ctx context.Context, header http.Header, rid string,
+	window *metadata.ChangeFreezeWindow, req *http.Request, reason string) {
+
+	audit := metadata.AuditLog{
+		AuditType:    metadata.ChangeFreezeType,
+		ResourceType: metadata.ChangeFreezeRes,
+		Action:       metadata.AuditOverride,
+		BusinessID:   window.BizID,
+		ResourceID:   window.ID,
+		ResourceName: window.Name,
+		OperationDetail: &metadata.BasicOpDetail{
+			Details: &metadata.BasicContent{
+				CurData: map[string]interface{}{
+					"method": req.Method,
+					"path":   req.URL.Path,
+					"reason": reason,
+				},
+			},
+		},
+	}
+	if _, err := s.clientSet.CoreService().Audit().SaveAuditLog(ctx, header, audit); err != nil {
+		blog.Errorf("save change freeze window %d override audit log failed, err: %v, rid: %s", window.ID, err, rid)
+	}
+}
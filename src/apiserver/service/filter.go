@@ -79,7 +79,25 @@ func (s *service) URLFilterChan(req *restful.Request, resp *restful.Response, ch
 		}
 	}()
 
-	servers := make([]string, 0)
+	servers, err := s.getServers(kind)
+	if err != nil {
+		return
+	}
+
+	if strings.HasPrefix(servers[0], "https://") {
+		req.Request.URL.Host = servers[0][8:]
+		req.Request.URL.Scheme = "https"
+	} else {
+		req.Request.URL.Host = servers[0][7:]
+		req.Request.URL.Scheme = "http"
+	}
+
+	chain.ProcessFilter(req, resp)
+}
+
+// getServers get the addresses of the server which the request type is proxied to
+func (s *service) getServers(kind RequestType) (servers []string, err error) {
+	servers = make([]string, 0)
 	switch kind {
 	case TopoType:
 		servers, err = s.discovery.TopoServer().GetServers()
@@ -113,20 +131,7 @@ func (s *service) URLFilterChan(req *restful.Request, resp *restful.Response, ch
 			servers, err = s.discovery.Server(name).GetServers()
 		}
 	}
-
-	if err != nil {
-		return
-	}
-
-	if strings.HasPrefix(servers[0], "https://") {
-		req.Request.URL.Host = servers[0][8:]
-		req.Request.URL.Scheme = "https"
-	} else {
-		req.Request.URL.Host = servers[0][7:]
-		req.Request.URL.Scheme = "http"
-	}
-
-	chain.ProcessFilter(req, resp)
+	return servers, err
 }
 
 func (s *service) authFilter(errFunc func() errors.CCErrorIf) func(req *restful.Request, resp *restful.Response, fchain *restful.FilterChain) {
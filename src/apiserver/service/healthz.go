@@ -131,6 +131,7 @@ func (s *service) RootWebService() *restful.WebService {
 
 	ws.Route(ws.GET("/healthz").To(s.healthz))
 	ws.Route(ws.GET("/version").To(s.Version))
+	ws.Route(ws.GET("/openapi.json").To(s.OpenAPIDocument))
 
 	return ws
 }
@@ -0,0 +1,172 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/openapi"
+	"configcenter/src/common/util"
+	"configcenter/src/common/version"
+
+	"github.com/emicklei/go-restful"
+)
+
+// openAPICacheTTL is the time to cache the aggregated document, the routes only change when the servers upgrade.
+const openAPICacheTTL = 5 * time.Minute
+
+// openAPIServers are the servers whose apis are proxied by api server
+var openAPIServers = []RequestType{TopoType, HostType, ProcType, EventType, DataCollectType, OperationType,
+	TaskType, AdminType, CloudType}
+
+// publicPathAliases are the server path prefixes which are exposed by api server with another prefix
+var publicPathAliases = []struct {
+	server string
+	public string
+}{
+	{server: "/topo/v3/app/", public: rootPath + "/biz/"},
+	{server: "/topo/v3/objectattr", public: rootPath + "/object/attr"},
+	{server: "/event/v3/", public: rootPath + "/event/"},
+	{server: "/process/v3/", public: rootPath + "/proc/"},
+	{server: "/collector/v3/", public: rootPath + "/collector/"},
+	{server: "/migrate/v3/", public: rootPath + "/admin/"},
+}
+
+type openAPICache struct {
+	lock    sync.Mutex
+	doc     *openapi.Document
+	fetched time.Time
+}
+
+// OpenAPIDocument returns the OpenAPI 3 document of the apis proxied by api server, which is aggregated from
+// the routes of the servers, the server which can not be reached is not included.
+func (s *service) OpenAPIDocument(req *restful.Request, resp *restful.Response) {
+	rid := util.GetHTTPCCRequestID(req.Request.Header)
+
+	s.openAPI.lock.Lock()
+	defer s.openAPI.lock.Unlock()
+
+	if s.openAPI.doc == nil || time.Since(s.openAPI.fetched) > openAPICacheTTL {
+		doc := s.aggregateOpenAPIDocument(req.Request.Header, rid)
+		if err := openapi.Validate(doc); err != nil {
+			blog.Errorf("aggregate openapi document, but the document is invalid, err: %v, rid: %s", err, rid)
+			if rerr := resp.WriteError(http.StatusInternalServerError, &metadata.RespError{
+				Msg:     err,
+				ErrCode: common.CCErrCommInternalServerError,
+			}); rerr != nil {
+				blog.Errorf("response openapi document failed, err: %v, rid: %s", rerr, rid)
+			}
+			return
+		}
+		s.openAPI.doc = doc
+		s.openAPI.fetched = time.Now()
+	}
+
+	if err := resp.WriteAsJson(s.openAPI.doc); err != nil {
+		blog.Errorf("response openapi document failed, err: %v, rid: %s", err, rid)
+	}
+}
+
+func (s *service) aggregateOpenAPIDocument(header http.Header, rid string) *openapi.Document {
+	doc := openapi.NewDocument("BlueKing CMDB API", version.CCVersion)
+	doc.Servers = []openapi.Server{{URL: "/"}}
+
+	for _, kind := range openAPIServers {
+		serverDoc, err := s.fetchOpenAPIDocument(kind, header)
+		if err != nil {
+			blog.Errorf("get %s server openapi document failed, skip it, err: %v, rid: %s", kind, err, rid)
+			continue
+		}
+
+		err = doc.Merge(serverDoc, func(serverPath string) (string, bool) {
+			return publicPath(kind, serverPath)
+		})
+		if err != nil {
+			blog.Errorf("merge %s server openapi document failed, err: %v, rid: %s", kind, err, rid)
+		}
+	}
+	return doc
+}
+
+func (s *service) fetchOpenAPIDocument(kind RequestType, header http.Header) (*openapi.Document, error) {
+	servers, err := s.getServers(kind)
+	if err != nil {
+		return nil, err
+	}
+	if len(servers) == 0 {
+		return nil, fmt.Errorf("no %s server", kind)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, servers[0]+"/openapi", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(common.BKHTTPCCRequestID, header.Get(common.BKHTTPCCRequestID))
+
+	response, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("response status %d", response.StatusCode)
+	}
+
+	doc := new(openapi.Document)
+	if err := json.NewDecoder(response.Body).Decode(doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// publicPath returns the api server path of the server's route path, the route path is like /topo/v3/xxx,
+// and the api server path is /api/v3/xxx except the aliases. the path is verified by the url filter so that
+// the document is consistent with the proxy, returns false if the route is not proxied by api server.
+func publicPath(kind RequestType, serverPath string) (string, bool) {
+	segments := strings.SplitN(serverPath, "/", 4)
+	if len(segments) != 4 {
+		return "", false
+	}
+
+	candidates := []string{rootPath + "/" + segments[3]}
+	for _, alias := range publicPathAliases {
+		if strings.HasPrefix(serverPath, alias.server) {
+			candidates = append(candidates, alias.public+strings.TrimPrefix(serverPath, alias.server))
+		}
+	}
+
+	for _, candidate := range candidates {
+		req := restful.NewRequest(&http.Request{
+			Method:     http.MethodPost,
+			URL:        &url.URL{Path: candidate},
+			RequestURI: candidate,
+			Header:     make(http.Header),
+		})
+
+		matched, err := URLPath(candidate).FilterChain(req)
+		if err == nil && matched == kind && req.Request.URL.Path == serverPath {
+			return candidate, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,44 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"testing"
+)
+
+func TestPublicPath(t *testing.T) {
+	tests := []struct {
+		kind       RequestType
+		serverPath string
+		publicPath string
+		proxied    bool
+	}{
+		{TopoType, "/topo/v3/app/search/{owner_id}", "/api/v3/biz/search/{owner_id}", true},
+		{TopoType, "/topo/v3/objectattr/search", "/api/v3/objectattr/search", true},
+		{TopoType, "/topo/v3/find/topo/tree/brief/biz/{bk_biz_id}", "/api/v3/find/topo/tree/brief/biz/{bk_biz_id}", true},
+		{HostType, "/host/v3/hosts/app/{appid}/list_hosts", "/api/v3/hosts/app/{appid}/list_hosts", true},
+		{EventType, "/event/v3/notify/rule/search", "/api/v3/event/notify/rule/search", true},
+		{ProcType, "/process/v3/findmany/proc/service_category", "/api/v3/findmany/proc/service_category", true},
+		// the path is proxied to topo server, not host server.
+		{HostType, "/host/v3/find/topo/tree", "", false},
+		{TopoType, "/healthz", "", false},
+	}
+
+	for _, test := range tests {
+		path, proxied := publicPath(test.kind, test.serverPath)
+		if proxied != test.proxied || path != test.publicPath {
+			t.Errorf("%s server path %s, expect %s(%v), got %s(%v)", test.kind, test.serverPath, test.publicPath,
+				test.proxied, path, proxied)
+		}
+	}
+}
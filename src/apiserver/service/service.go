@@ -39,13 +39,17 @@ func NewService() Service {
 }
 
 type service struct {
-	engine     *backbone.Engine
-	client     HTTPClient
-	discovery  discovery.DiscoveryInterface
-	clientSet  apimachinery.ClientSetInterface
-	authorizer ac.AuthorizeInterface
-	cache      redis.Client
-	limiter    *Limiter
+	engine         *backbone.Engine
+	client         HTTPClient
+	discovery      discovery.DiscoveryInterface
+	clientSet      apimachinery.ClientSetInterface
+	authorizer     ac.AuthorizeInterface
+	cache          redis.Client
+	limiter        *Limiter
+	apiTokens      *apiTokenCache
+	openAPI        *openAPICache
+	changeFreeze   *changeFreezeCache
+	trustedProxies trustedProxies
 }
 
 func (s *service) SetConfig(engine *backbone.Engine, httpClient HTTPClient, discovery discovery.DiscoveryInterface,
@@ -57,6 +61,10 @@ func (s *service) SetConfig(engine *backbone.Engine, httpClient HTTPClient, disc
 	s.cache = cache
 	s.limiter = limiter
 	s.authorizer = iam.NewAuthorizer(clientSet)
+	s.apiTokens = newAPITokenCache()
+	s.openAPI = new(openAPICache)
+	s.changeFreeze = new(changeFreezeCache)
+	s.trustedProxies = loadTrustedProxies()
 }
 
 func (s *service) WebServices() []*restful.WebService {
@@ -67,9 +75,12 @@ func (s *service) WebServices() []*restful.WebService {
 	ws := &restful.WebService{}
 	ws.Path(rootPath)
 	ws.Filter(s.engine.Metric().RestfulMiddleWare)
+	// api token filter derives the user and supplier account headers, so it must go before the global filter
+	ws.Filter(s.apiTokenFilter(getErrFun))
 	ws.Filter(rdapi.AllGlobalFilter(getErrFun))
 	ws.Filter(rdapi.RequestLogFilter())
 	ws.Filter(s.LimiterFilter())
+	ws.Filter(s.changeFreezeFilter(getErrFun))
 	ws.Produces(restful.MIME_JSON)
 	if auth.EnableAuthorize() {
 		ws.Filter(s.authFilter(getErrFun))
@@ -77,6 +88,9 @@ func (s *service) WebServices() []*restful.WebService {
 	ws.Route(ws.POST("/auth/verify").To(s.AuthVerify))
 	ws.Route(ws.GET("/auth/business_list").To(s.GetAnyAuthorizedAppList))
 	ws.Route(ws.POST("/auth/skip_url").To(s.GetUserNoAuthSkipURL))
+	ws.Route(ws.POST("/create/api_token").To(s.CreateAPIToken))
+	ws.Route(ws.POST("/findmany/api_token").To(s.ListAPITokens))
+	ws.Route(ws.PUT("/update/api_token/{id}/revoke").To(s.RevokeAPIToken))
 	ws.Route(ws.GET("{.*}").Filter(s.URLFilterChan).To(s.Get))
 	ws.Route(ws.POST("{.*}").Filter(s.URLFilterChan).To(s.Post))
 	ws.Route(ws.PUT("{.*}").Filter(s.URLFilterChan).To(s.Put))
@@ -0,0 +1,211 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package approval guards the sensitive operations with the approval policies, the operation which matches an
+// enabled policy is saved as a pending approval request, and executed with the identity of the requester by
+// topo server after it's approved.
+package approval
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"configcenter/src/apimachinery/coreservice"
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+)
+
+// PreviewFunc returns what the operation is going to do, it's only called when the operation needs approval
+type PreviewFunc func() (interface{}, error)
+
+// Gate checks the sensitive operations of a scene server against the approval policies
+type Gate struct {
+	clientSet coreservice.CoreServiceClientInterface
+	server    metadata.ApprovalServer
+	// rootPath is the root path of the web service, the request path is saved relative to it
+	rootPath string
+}
+
+// NewGate create an approval gate of the scene server
+func NewGate(clientSet coreservice.CoreServiceClientInterface, server metadata.ApprovalServer, rootPath string) *Gate {
+	return &Gate{
+		clientSet: clientSet,
+		server:    server,
+		rootPath:  strings.TrimRight(rootPath, "/"),
+	}
+}
+
+// Check checks if the operation can be executed now. If the operation matches an enabled approval policy, a pending
+// approval request is created and returned with the CCErrCommApprovalRequired error. The handler must return
+// without doing anything when false is returned, the response is already written.
+// body is the decoded body of the request, it's sent again when the request is approved.
+func (g *Gate) Check(ctx *rest.Contexts, operation metadata.ApprovalOperation, bizID int64, body interface{},
+	preview PreviewFunc) bool {
+
+	kit := ctx.Kit
+	if requestID := kit.Header.Get(common.BKHTTPApprovalRequestID); len(requestID) != 0 {
+		if err := g.checkApprovedRequest(kit, requestID, operation); err != nil {
+			ctx.RespAutoError(err)
+			return false
+		}
+		return true
+	}
+
+	policy, ccErr := g.matchPolicy(kit, operation, bizID)
+	if ccErr != nil {
+		ctx.RespAutoError(ccErr)
+		return false
+	}
+	if policy == nil {
+		return true
+	}
+
+	request := metadata.ApprovalRequest{
+		PolicyID:  policy.ID,
+		Operation: operation,
+		BizID:     bizID,
+		Approvers: policy.Approvers,
+		Request: metadata.ApprovalOriginRequest{
+			Server:   g.server,
+			Method:   ctx.Request.Request.Method,
+			Path:     strings.TrimPrefix(ctx.Request.Request.URL.Path, g.rootPath),
+			Language: util.GetLanguage(kit.Header),
+		},
+		ExpireTime: time.Now().Add(policy.GetExpireDuration()),
+	}
+	if body != nil {
+		var err error
+		if request.Request.Body, err = json.Marshal(body); err != nil {
+			blog.Errorf("marshal %s request body failed, err: %v, rid: %s", operation, err, kit.Rid)
+			ctx.RespAutoError(kit.CCError.CCError(common.CCErrCommJSONMarshalFailed))
+			return false
+		}
+	}
+	if preview != nil {
+		data, err := preview()
+		if err != nil {
+			blog.Errorf("generate %s preview failed, err: %v, rid: %s", operation, err, kit.Rid)
+			ctx.RespAutoError(err)
+			return false
+		}
+		if request.Preview, err = json.Marshal(data); err != nil {
+			blog.Errorf("marshal %s preview failed, err: %v, rid: %s", operation, err, kit.Rid)
+			ctx.RespAutoError(kit.CCError.CCError(common.CCErrCommJSONMarshalFailed))
+			return false
+		}
+	}
+
+	created, ccErr := g.clientSet.Approval().CreateApprovalRequest(kit.Ctx, kit.Header, request)
+	if ccErr != nil {
+		blog.Errorf("create %s approval request failed, policy: %d, err: %v, rid: %s", operation, policy.ID, ccErr,
+			kit.Rid)
+		ctx.RespAutoError(ccErr)
+		return false
+	}
+	SaveRequestAudit(kit.Ctx, kit.Header, kit.Rid, g.clientSet, created, metadata.AuditCreate)
+
+	blog.Infof("%s needs approval, approval request %d is created by policy %d, rid: %s", operation, created.ID,
+		policy.ID, kit.Rid)
+	ctx.RespEntityWithError(created, kit.CCError.CCErrorf(common.CCErrCommApprovalRequired, operation, created.ID))
+	return false
+}
+
+// checkApprovedRequest checks the request is sent by topo server to execute the approved operation
+func (g *Gate) checkApprovedRequest(kit *rest.Kit, requestID string, operation metadata.ApprovalOperation) error {
+	id, err := strconv.ParseInt(requestID, 10, 64)
+	if err != nil {
+		blog.Errorf("approval request id %s is invalid, rid: %s", requestID, kit.Rid)
+		return kit.CCError.CCErrorf(common.CCErrCommApprovalRequestInvalid, requestID)
+	}
+
+	request, ccErr := g.clientSet.Approval().GetApprovalRequest(kit.Ctx, kit.Header, id)
+	if ccErr != nil {
+		blog.Errorf("get approval request %d failed, err: %v, rid: %s", id, ccErr, kit.Rid)
+		return ccErr
+	}
+	if request.Status != metadata.ApprovalStatusExecuting || request.Operation != operation ||
+		request.Requester != kit.User || request.Request.Server != g.server {
+		blog.Errorf("approval request %d can not execute %s, status: %s, operation: %s, requester: %s, user: %s, "+
+			"rid: %s", id, operation, request.Status, request.Operation, request.Requester, kit.User, kit.Rid)
+		return kit.CCError.CCErrorf(common.CCErrCommApprovalRequestInvalid, requestID)
+	}
+	return nil
+}
+
+// matchPolicy find the enabled policy of the operation, the policy of the business takes precedence over the
+// policy of all businesses
+func (g *Gate) matchPolicy(kit *rest.Kit, operation metadata.ApprovalOperation, bizID int64) (
+	*metadata.ApprovalPolicy, errors.CCErrorCoder) {
+
+	option := metadata.SearchApprovalPolicyOption{
+		Condition: map[string]interface{}{
+			"operation":         operation,
+			"enabled":           true,
+			common.BKAppIDField: map[string]interface{}{common.BKDBIN: []int64{0, bizID}},
+		},
+		Page: metadata.BasePage{Limit: 1, Sort: "-" + common.BKAppIDField},
+	}
+	result, err := g.clientSet.Approval().SearchApprovalPolicies(kit.Ctx, kit.Header, option)
+	if err != nil {
+		blog.Errorf("search %s approval policies failed, biz: %d, err: %v, rid: %s", operation, bizID, err, kit.Rid)
+		return nil, err
+	}
+	if len(result.Info) == 0 {
+		return nil, nil
+	}
+	return &result.Info[0], nil
+}
+
+// SaveRequestAudit save the audit log of the approval request
+func SaveRequestAudit(ctx context.Context, header http.Header, rid string,
+	clientSet coreservice.CoreServiceClientInterface, request *metadata.ApprovalRequest, action metadata.ActionType) {
+
+	data := map[string]interface{}{
+		common.BKFieldID:      request.ID,
+		"policy_id":           request.PolicyID,
+		"operation":           request.Operation,
+		common.BKAppIDField:   request.BizID,
+		"status":              request.Status,
+		"requester":           request.Requester,
+		"approvers":           request.Approvers,
+		"approver":            request.Approver,
+		"comment":             request.Comment,
+		"method":              request.Request.Method,
+		"path":                request.Request.Path,
+		"expire_time":         request.ExpireTime,
+		common.BKOwnerIDField: request.OwnerID,
+	}
+	detail := &metadata.BasicContent{CurData: data}
+	if action != metadata.AuditCreate {
+		detail = &metadata.BasicContent{PreData: data, UpdateFields: map[string]interface{}{"status": request.Status}}
+	}
+	audit := metadata.AuditLog{
+		AuditType:       metadata.ApprovalType,
+		ResourceType:    metadata.ApprovalRequestRes,
+		Action:          action,
+		BusinessID:      request.BizID,
+		ResourceID:      request.ID,
+		ResourceName:    string(request.Operation),
+		OperationDetail: &metadata.BasicOpDetail{Details: detail},
+	}
+	if _, err := clientSet.Audit().SaveAuditLog(ctx, header, audit); err != nil {
+		blog.Errorf("save approval request %d audit log failed, err: %v, rid: %s", request.ID, err, rid)
+	}
+}
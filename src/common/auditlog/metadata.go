@@ -13,6 +13,7 @@
 package auditlog
 
 import (
+	"configcenter/src/common"
 	"configcenter/src/common/http/rest"
 	"configcenter/src/common/metadata"
 )
@@ -26,10 +27,19 @@ type generateAuditCommonParameter struct {
 }
 
 func NewGenerateAuditCommonParameter(kit *rest.Kit, action metadata.ActionType) *generateAuditCommonParameter {
-	return &generateAuditCommonParameter{
+	parameter := &generateAuditCommonParameter{
 		kit:    kit,
 		action: action,
 	}
+	// the requests authenticated by api tokens are marked by apiserver
+	if kit.Header.Get(common.BKHTTPAPITokenID) != "" {
+		parameter.operateFrom = metadata.FromAPIToken
+	}
+	// the approved requests are executed by topo server with the identity of the requester
+	if kit.Header.Get(common.BKHTTPApprovalRequestID) != "" {
+		parameter.operateFrom = metadata.FromApproval
+	}
+	return parameter
 }
 
 func (a *generateAuditCommonParameter) WithOperateFrom(operateFrom metadata.OperateFromType) *generateAuditCommonParameter {
@@ -439,6 +439,22 @@ func Bool(key string) (bool, error) {
 	return false, err.New("config not found")
 }
 
+// UnmarshalKey decode the configuration information of the key into the value, the value should be a pointer.
+func UnmarshalKey(key string, val interface{}) error {
+	confLock.RLock()
+	defer confLock.RUnlock()
+	if migrateParser != nil && migrateParser.isSet(key) {
+		return migrateParser.unmarshalKey(key, val)
+	}
+	if commonParser != nil && commonParser.isSet(key) {
+		return commonParser.unmarshalKey(key, val)
+	}
+	if extraParser != nil && extraParser.isSet(key) {
+		return extraParser.unmarshalKey(key, val)
+	}
+	return err.New("config not found")
+}
+
 func IsExist(key string) bool {
 	confLock.RLock()
 	defer confLock.RUnlock()
@@ -516,6 +532,10 @@ func (vp *viperParser) getBool(path string) bool {
 	return vp.parser.GetBool(path)
 }
 
+func (vp *viperParser) unmarshalKey(path string, val interface{}) error {
+	return vp.parser.UnmarshalKey(path, val)
+}
+
 func (vp *viperParser) isSet(path string) bool {
 	return vp.parser.IsSet(path)
 }
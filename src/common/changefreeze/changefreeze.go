@@ -0,0 +1,105 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package changefreeze checks the changes against the change freeze windows. The requests sent through api server
+// are checked by its change freeze filter, the changes made by the servers themselves, such as the approved
+// operations, the automatic synchronizations and the background tasks, are checked with this package.
+package changefreeze
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"configcenter/src/ac/parser"
+	"configcenter/src/apimachinery/coreservice"
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+)
+
+// SearchWindows returns all the change freeze windows that match the condition, the windows are read page by page
+func SearchWindows(ctx context.Context, header http.Header, clientSet coreservice.CoreServiceClientInterface,
+	cond mapstr.MapStr) ([]metadata.ChangeFreezeWindow, error) {
+
+	windows := make([]metadata.ChangeFreezeWindow, 0)
+	option := metadata.SearchChangeFreezeWindowOption{
+		Condition: cond,
+		Page:      metadata.BasePage{Limit: common.BKMaxPageSize, Sort: "id"},
+	}
+	for {
+		result, err := clientSet.ChangeFreeze().SearchChangeFreezeWindows(ctx, header, option)
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, result.Info...)
+		if len(result.Info) < option.Page.Limit {
+			return windows, nil
+		}
+		option.Page.Start += option.Page.Limit
+	}
+}
+
+// Frozen returns the windows which freeze the changes of the resources by the user or the app at the time, and the
+// first frozen resource, the windows are not frozen if the user or the app is exempted.
+func Frozen(windows []metadata.ChangeFreezeWindow, now time.Time, user, appCode string,
+	resources []parser.FrozenResource) ([]metadata.ChangeFreezeWindow, parser.FrozenResource) {
+
+	frozen := make([]metadata.ChangeFreezeWindow, 0)
+	var frozenResource parser.FrozenResource
+	for _, window := range windows {
+		if !window.Schedule.IsActive(now) || window.IsExempt(user, appCode) {
+			continue
+		}
+		for _, resource := range resources {
+			if window.Covers(resource.BizID, resource.Resource) {
+				if len(frozen) == 0 {
+					frozenResource = resource
+				}
+				frozen = append(frozen, window)
+				break
+			}
+		}
+	}
+	return frozen, frozenResource
+}
+
+// Check returns the CCErrCommChangeFrozen error if the changes of the resources made by the user of the kit are
+// frozen by the enabled windows of the supplier account which are active now.
+func Check(kit *rest.Kit, clientSet coreservice.CoreServiceClientInterface,
+	resources ...parser.FrozenResource) errors.CCErrorCoder {
+
+	if len(resources) == 0 {
+		return nil
+	}
+
+	cond := mapstr.MapStr{
+		"enabled":                true,
+		common.BkSupplierAccount: kit.SupplierAccount,
+	}
+	windows, err := SearchWindows(kit.Ctx, kit.Header, clientSet, cond)
+	if err != nil {
+		blog.Errorf("search enabled change freeze windows failed, err: %v, rid: %s", err, kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommHTTPDoRequestFailed)
+	}
+
+	frozen, resource := Frozen(windows, time.Now(), kit.User, kit.Header.Get(common.BKHTTPRequestAppCode), resources)
+	if len(frozen) == 0 {
+		return nil
+	}
+	blog.Errorf("changes of %+v by %s are rejected by change freeze window %d, rid: %s", resource, kit.User,
+		frozen[0].ID, kit.Rid)
+	return kit.CCError.CCErrorf(common.CCErrCommChangeFrozen, frozen[0].Name, resource.Resource)
+}
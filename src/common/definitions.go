@@ -488,7 +488,8 @@ const (
 	BKSetTemplateIDField      = "set_template_id"
 	BKSetTemplateVersionField = "set_template_version"
 
-	HostApplyRuleIDField = "host_apply_rule_id"
+	HostApplyRuleIDField       = "host_apply_rule_id"
+	HostApplyRulePriorityField = "priority"
 
 	BKParentIDField = "bk_parent_id"
 	BKRootIDField   = "bk_root_id"
@@ -926,6 +927,14 @@ const (
 	BKHTTPSecretsEnv     = "BK-Secrets-Env"
 	// BKHTTPReadReference  query db use secondary node
 	BKHTTPReadReference = "Cc_Read_Preference"
+	// BKHTTPAPITokenID the id of the api token that authenticated the request, it's set by apiserver
+	BKHTTPAPITokenID = "Cc_Api_Token_Id"
+	// BKHTTPApprovalRequestID the id of the approved request which is executed, it's set when the approval request
+	// is executed by topo server
+	BKHTTPApprovalRequestID = "Cc_Approval_Request_Id"
+	// BKHTTPChangeFreezeOverride the reason to make changes in an active change freeze window, the user needs the
+	// permission of the global settings to override the window
+	BKHTTPChangeFreezeOverride = "Cc_Change_Freeze_Override"
 )
 
 type ReadPreferenceMode string
@@ -1046,6 +1055,8 @@ const (
 	BKBluekingLoginPluginVersion   = "blueking"
 	BKOpenSourceLoginPluginVersion = "opensource"
 	BKSkipLoginPluginVersion       = "skip-login"
+	BKLDAPLoginPluginVersion       = "ldap"
+	BKOIDCLoginPluginVersion       = "oidc"
 
 	HTTPCookieBKToken = "bk_token"
 
@@ -1199,6 +1210,7 @@ const (
 	OptionOther          = "其他"
 	TimerPattern         = "^[\\d]+\\:[\\d]+$"
 	SyncSetTaskName      = "sync-settemplate2set"
+	CloneBizTopoTaskName = "clone-biz-topo"
 
 	BKHostState = "bk_state"
 )
@@ -226,6 +226,27 @@ const (
 	// CCErrArrayLengthWrong the length of the array is wrong
 	CCErrArrayLengthWrong = 1199089
 
+	// CCErrCommRestrictedFieldNoPermission no permission to edit restricted fields
+	CCErrCommRestrictedFieldNoPermission = 1199090
+
+	// CCErrCommApprovalRequired the operation needs to be approved, an approval request is created
+	CCErrCommApprovalRequired = 1199091
+
+	// CCErrCommApprovalRequestInvalid the approval request can not be used to execute the operation
+	CCErrCommApprovalRequestInvalid = 1199092
+
+	// CCErrCommApprovalRequestNotPending the approval request is not waiting for approval
+	CCErrCommApprovalRequestNotPending = 1199093
+
+	// CCErrCommNotApprover the user is not the approver of the approval request
+	CCErrCommNotApprover = 1199094
+
+	// CCErrCommChangeFrozen the changes of the resource are forbidden by an active change freeze window
+	CCErrCommChangeFrozen = 1199095
+
+	// CCErrCommChangeFreezeOverrideDenied no permission to make changes in an active change freeze window
+	CCErrCommChangeFreezeOverrideDenied = 1199096
+
 	// too many requests
 	CCErrTooManyRequestErr = 1199997
 
@@ -239,6 +260,12 @@ const (
 	CCErrAPIGetAuthorizedAppListFromAuthFailed = 1100001
 	CCErrAPIGetUserResourceAuthStatusFailed    = 1100002
 	CCErrAPINoObjectInstancesIsFound           = 1100003
+	// CCErrAPITokenInvalid the api token is invalid, expired or revoked
+	CCErrAPITokenInvalid = 1100004
+	// CCErrAPITokenScopeDenied the request is out of the api token scopes
+	CCErrAPITokenScopeDenied = 1100005
+	// CCErrAPITokenManageForbidden api tokens can not be managed with an api token
+	CCErrAPITokenManageForbidden = 1100006
 
 	// toposerver 1101XXX
 	// CCErrTopoInstCreateFailed unable to create the instance
@@ -468,6 +495,9 @@ const (
 	CCErrorTopoOperateReourceDirFailNotExist       = 1101103
 	CCErrorTopoResourceDirIdleModuleCanNotRemove   = 1101104
 	CCErrorTopoResourceDirUsedInCloudSync          = 1101105
+	CCErrorTopoCloneTargetBizNotEmpty              = 1101106
+	CCErrorTopoCloneBizTaskIsRunning               = 1101107
+	CCErrorTopoDiffAuditLogExceedLimit             = 1101108
 
 	CCErrorModelNotFound = 1101102
 	// object controller 1102XXX
@@ -509,6 +539,8 @@ const (
 	CCErrEventSubscribeTelnetFailed = 1103005
 	// CCErrEventOperateSuccessBUtSentEventFailed failed to sent event
 	CCErrEventPushEventFailed = 1103006
+	// CCErrEventWatchCursorNotExist the watch cursor is expired or not exist, need to watch from a start time
+	CCErrEventWatchCursorNotExist = 1103007
 
 	// host 1104XXX
 	CCErrHostModuleRelationAddFailed = 1104000
@@ -700,6 +732,9 @@ const (
 	CCErrWebNoUsernamePasswd            = 1111015
 	CCErrWebUserinfoFormatWrong         = 1111016
 	CCErrWebUnknownLoginVersion         = 1111017
+	CCErrWebLDAPConfigInvalid           = 1111018
+	CCErrWebLDAPSearchUserFailed        = 1111019
+	CCErrWebOIDCLoginFailed             = 1111020
 
 	// datacollection 1112xxx
 	CCErrCollectNetDeviceCreateFail            = 1112000
@@ -783,6 +818,10 @@ const (
 
 	// CCERrrCoreServiceUniqueRuleExist 模型唯一校验规则已经存在
 	CCERrrCoreServiceSameUniqueCheckRuleExist = 1113050
+	// CCErrCoreServiceAdmissionDenied 准入webhook[%s]拒绝了请求: %s
+	CCErrCoreServiceAdmissionDenied = 1113060
+	// CCErrCoreServiceAdmissionWebhookFailed 调用准入webhook[%s]失败: %s
+	CCErrCoreServiceAdmissionWebhookFailed = 1113061
 	// CCErrCoreServiceResourceDirectoryNotExistErr 资源池目录不存在
 	CCErrCoreServiceResourceDirectoryNotExistErr = 1113033
 	// CCErrCoreServiceHostNotUnderAnyResourceDirectory 主机不在任意资源池目录下
@@ -74,6 +74,27 @@ func (c *Contexts) RespEntity(data interface{}) {
 	c.writeAsJson(metadata.NewSuccessResp(data))
 }
 
+// RespRawEntity response the data as json without the common response wrapper, it's used by the apis that
+// are consumed by the third-party systems in their own formats.
+func (c *Contexts) RespRawEntity(data interface{}) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		blog.ErrorfDepthf(1, "marshal json response failed, err: %v, rid: %s", err, c.Kit.Rid)
+		c.RespAutoError(c.Kit.CCError.Error(common.CCErrCommJSONMarshalFailed))
+		return
+	}
+
+	c.resp.Header().Set("Content-Type", "application/json")
+	c.resp.Header().Add(common.BKHTTPCCRequestID, c.Kit.Rid)
+	if c.respStatusCode != 0 {
+		c.resp.WriteHeader(c.respStatusCode)
+	}
+	if _, err := c.resp.Write(body); err != nil {
+		blog.ErrorfDepthf(1, "response http request failed, err: %v, rid: %s", err, c.Kit.Rid)
+		return
+	}
+}
+
 // RespString response the data format to a json string.
 // the data is a string, and do not need marshal, can return directly.
 func (c *Contexts) RespString(data string) {
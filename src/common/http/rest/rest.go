@@ -16,6 +16,9 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"reflect"
+	"runtime"
+	"strings"
 
 	"configcenter/src/common"
 	"configcenter/src/common/errors"
@@ -29,8 +32,16 @@ type Action struct {
 	Verb    string
 	Path    string
 	Handler func(contexts *Contexts)
+	// Request and Response are the optional samples of the request body and the response data,
+	// which are used to generate the openapi document.
+	Request  interface{}
+	Response interface{}
 }
 
+// EmptyBody is the request sample of the POST and PUT actions which do not read the request body,
+// all their parameters are in the path.
+type EmptyBody struct{}
+
 type RestfulConfig struct {
 	RootPath string
 }
@@ -77,19 +88,29 @@ func (r *RestUtility) AddHandler(action Action) {
 func (r *RestUtility) AddToRestfulWebService(ws *restful.WebService) {
 
 	for _, action := range r.actions {
+		var builder *restful.RouteBuilder
 		switch action.Verb {
 		case http.MethodPost:
-			ws.Route(ws.POST(action.Path).To(r.wrapperAction(action)))
+			builder = ws.POST(action.Path)
 		case http.MethodDelete:
-			ws.Route(ws.DELETE(action.Path).To(r.wrapperAction(action)))
+			builder = ws.DELETE(action.Path)
 		case http.MethodPut:
-			ws.Route(ws.PUT(action.Path).To(r.wrapperAction(action)))
+			builder = ws.PUT(action.Path)
 		case http.MethodGet:
-			ws.Route(ws.GET(action.Path).To(r.wrapperAction(action)))
+			builder = ws.GET(action.Path)
 		default:
 			panic(fmt.Sprintf("rest utility add handler to webservice, but got unsupport verb: %s .", action.Verb))
 		}
 
+		// the handler is wrapped, so the operation name is set with the handler's name instead of the wrapper's.
+		builder.To(r.wrapperAction(action)).Operation(handlerName(action.Handler))
+		if action.Request != nil {
+			builder.Reads(action.Request)
+		}
+		if action.Response != nil {
+			builder.Writes(action.Response)
+		}
+		ws.Route(builder)
 	}
 	return
 }
@@ -129,3 +150,10 @@ func (r *RestUtility) wrapperAction(action Action) func(req *restful.Request, re
 		action.Handler(restContexts)
 	}
 }
+
+// handlerName returns the method name of the handler, such as SearchBusiness.
+func handlerName(handler func(contexts *Contexts)) string {
+	name := runtime.FuncForPC(reflect.ValueOf(handler).Pointer()).Name()
+	name = strings.TrimSuffix(name, "-fm")
+	return name[strings.LastIndex(name, ".")+1:]
+}
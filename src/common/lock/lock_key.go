@@ -26,6 +26,12 @@ const (
 
 	// CheckSetTemplateSyncFormat  检测集群模板同步的状态
 	CheckSetTemplateSyncFormat = "topo:settemplate:sync:status:check:%d"
+
+	// CloneBizTopoFormat 克隆业务拓扑任务的执行锁, 避免任务重试时与未结束的执行同时进行
+	CloneBizTopoFormat = "topo:clone:biz:topo:%s"
+
+	// SetTemplateAutoSyncFormat 集群模板自动同步的执行锁, 模板变更与维护窗口触发的同步共用, 避免同一模板同时同步
+	SetTemplateAutoSyncFormat = "topo:settemplate:auto:sync:%d"
 )
 
 // StrFormat  build  lock key format
@@ -0,0 +1,275 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"net/url"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/mapstr"
+)
+
+// AdmissionOperation is the write operation which is reviewed by the admission webhooks
+type AdmissionOperation string
+
+const (
+	AdmissionOperationCreate AdmissionOperation = "create"
+	AdmissionOperationUpdate AdmissionOperation = "update"
+	AdmissionOperationDelete AdmissionOperation = "delete"
+	// AdmissionOperationTransfer transfer hosts to modules, the obj_id of the webhook must be host.
+	AdmissionOperationTransfer AdmissionOperation = "transfer"
+)
+
+// AdmissionWebhookType is the type of the admission webhook
+type AdmissionWebhookType string
+
+const (
+	// AdmissionWebhookValidating can only allow or reject the request
+	AdmissionWebhookValidating AdmissionWebhookType = "validating"
+	// AdmissionWebhookMutating can also return a patch to modify the object, it's only supported by
+	// create and update operation, mutating webhooks are called before validating webhooks.
+	AdmissionWebhookMutating AdmissionWebhookType = "mutating"
+)
+
+// AdmissionFailurePolicy decides what to do when the webhook can not be called or returns an invalid response
+type AdmissionFailurePolicy string
+
+const (
+	// AdmissionFailurePolicyIgnore ignore the failed webhook and go on, which is fail-open
+	AdmissionFailurePolicyIgnore AdmissionFailurePolicy = "ignore"
+	// AdmissionFailurePolicyFail reject the request, which is fail-closed
+	AdmissionFailurePolicyFail AdmissionFailurePolicy = "fail"
+)
+
+const (
+	// AdmissionWebhookDefaultTimeout is the default timeout seconds of calling a webhook
+	AdmissionWebhookDefaultTimeout = 3
+	// AdmissionWebhookMaxTimeout is the max timeout seconds of calling a webhook, the writes are blocked
+	// until the webhook returns, so it should be short.
+	AdmissionWebhookMaxTimeout = 30
+)
+
+// AdmissionWebhook is called with the proposed object when the object of the model is written
+type AdmissionWebhook struct {
+	ID   int64  `json:"id" bson:"id"`
+	Name string `json:"name" bson:"name"`
+	// ObjID is the model of the objects to review, such as host, biz, set, module or custom object id
+	ObjID      string               `json:"bk_obj_id" bson:"bk_obj_id"`
+	Operations []AdmissionOperation `json:"operations" bson:"operations"`
+	Type       AdmissionWebhookType `json:"type" bson:"type"`
+	// URL is the http or https url to post the admission review to
+	URL string `json:"url" bson:"url"`
+	// TimeoutSeconds is the timeout of calling the webhook, 0 means the default timeout
+	TimeoutSeconds int64                  `json:"timeout_seconds" bson:"timeout_seconds"`
+	FailurePolicy  AdmissionFailurePolicy `json:"failure_policy" bson:"failure_policy"`
+	Enabled        bool                   `json:"enabled" bson:"enabled"`
+	Creator        string                 `json:"creator" bson:"creator"`
+	Modifier       string                 `json:"modifier" bson:"modifier"`
+	CreateTime     time.Time              `json:"create_time" bson:"create_time"`
+	LastTime       time.Time              `json:"last_time" bson:"last_time"`
+	OwnerID        string                 `json:"bk_supplier_account" bson:"bk_supplier_account"`
+}
+
+// GetTimeout returns the timeout of calling the webhook
+func (w *AdmissionWebhook) GetTimeout() time.Duration {
+	if w.TimeoutSeconds <= 0 {
+		return AdmissionWebhookDefaultTimeout * time.Second
+	}
+	return time.Duration(w.TimeoutSeconds) * time.Second
+}
+
+// Match checks if the webhook should review the operation of the model
+func (w *AdmissionWebhook) Match(objID string, operation AdmissionOperation) bool {
+	if !w.Enabled || w.ObjID != objID {
+		return false
+	}
+	for _, op := range w.Operations {
+		if op == operation {
+			return true
+		}
+	}
+	return false
+}
+
+func invalidAdmissionWebhookField(field string) errors.RawErrorInfo {
+	return errors.RawErrorInfo{
+		ErrCode: common.CCErrCommParamsIsInvalid,
+		Args:    []interface{}{field},
+	}
+}
+
+// Validate validate the admission webhook
+func (w *AdmissionWebhook) Validate() errors.RawErrorInfo {
+	if len(w.Name) == 0 {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsNeedSet,
+			Args:    []interface{}{"name"},
+		}
+	}
+	if len(w.ObjID) == 0 {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsNeedSet,
+			Args:    []interface{}{common.BKObjIDField},
+		}
+	}
+
+	if len(w.Operations) == 0 {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsNeedSet,
+			Args:    []interface{}{"operations"},
+		}
+	}
+	for _, op := range w.Operations {
+		switch op {
+		case AdmissionOperationCreate, AdmissionOperationUpdate:
+		case AdmissionOperationDelete:
+			if w.Type == AdmissionWebhookMutating {
+				return invalidAdmissionWebhookField("operations")
+			}
+		case AdmissionOperationTransfer:
+			if w.Type == AdmissionWebhookMutating || w.ObjID != common.BKInnerObjIDHost {
+				return invalidAdmissionWebhookField("operations")
+			}
+		default:
+			return invalidAdmissionWebhookField("operations")
+		}
+	}
+
+	if w.Type != AdmissionWebhookValidating && w.Type != AdmissionWebhookMutating {
+		return invalidAdmissionWebhookField("type")
+	}
+
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+		return invalidAdmissionWebhookField("url")
+	}
+
+	if w.TimeoutSeconds < 0 || w.TimeoutSeconds > AdmissionWebhookMaxTimeout {
+		return invalidAdmissionWebhookField("timeout_seconds")
+	}
+
+	if w.FailurePolicy != AdmissionFailurePolicyIgnore && w.FailurePolicy != AdmissionFailurePolicyFail {
+		return invalidAdmissionWebhookField("failure_policy")
+	}
+	return errors.RawErrorInfo{}
+}
+
+// AdmissionReviewRequest is the proposed write which is posted to the admission webhook
+type AdmissionReviewRequest struct {
+	// UID is the request id of the write
+	UID             string             `json:"uid"`
+	ObjID           string             `json:"bk_obj_id"`
+	Operation       AdmissionOperation `json:"operation"`
+	User            string             `json:"user"`
+	SupplierAccount string             `json:"bk_supplier_account"`
+	// Object is the object to create, the data to update, or the transfer option for transfer operation
+	Object mapstr.MapStr `json:"object,omitempty"`
+	// OldObjects are the objects to update or delete
+	OldObjects []mapstr.MapStr `json:"old_objects,omitempty"`
+}
+
+// AdmissionReviewResponse is the decision of the admission webhook
+type AdmissionReviewResponse struct {
+	Allowed bool `json:"allowed"`
+	// Message is the reason to reject the request, which is returned to the user
+	Message string `json:"message"`
+	// Patch are the fields to set to the object, only used by mutating webhooks
+	Patch mapstr.MapStr `json:"patch"`
+}
+
+// AdmissionReview is the body of the request and response of the admission webhook
+type AdmissionReview struct {
+	Request  *AdmissionReviewRequest  `json:"request,omitempty"`
+	Response *AdmissionReviewResponse `json:"response,omitempty"`
+}
+
+// AdmissionDecision is the decision of a webhook call
+type AdmissionDecision string
+
+const (
+	AdmissionDecisionAllowed AdmissionDecision = "allowed"
+	AdmissionDecisionDenied  AdmissionDecision = "denied"
+	// AdmissionDecisionFailed the webhook is failed and the failure policy is used
+	AdmissionDecisionFailed AdmissionDecision = "failed"
+)
+
+// AdmissionWebhookLog records the decision of an admission webhook call
+type AdmissionWebhookLog struct {
+	ID          int64              `json:"id" bson:"id"`
+	WebhookID   int64              `json:"webhook_id" bson:"webhook_id"`
+	WebhookName string             `json:"webhook_name" bson:"webhook_name"`
+	ObjID       string             `json:"bk_obj_id" bson:"bk_obj_id"`
+	Operation   AdmissionOperation `json:"operation" bson:"operation"`
+	RequestID   string             `json:"rid" bson:"rid"`
+	User        string             `json:"user" bson:"user"`
+	Decision    AdmissionDecision  `json:"decision" bson:"decision"`
+	// Patched the object is modified by the mutating webhook
+	Patched bool `json:"patched" bson:"patched"`
+	// Message is the reject message of the webhook, or the error of the failed call
+	Message string `json:"message" bson:"message"`
+	// FailurePolicy is the policy used when the webhook is failed
+	FailurePolicy AdmissionFailurePolicy `json:"failure_policy,omitempty" bson:"failure_policy,omitempty"`
+	// Duration is the milliseconds of the webhook call
+	Duration   int64     `json:"duration" bson:"duration"`
+	CreateTime time.Time `json:"create_time" bson:"create_time"`
+	OwnerID    string    `json:"bk_supplier_account" bson:"bk_supplier_account"`
+}
+
+// SearchAdmissionWebhookOption search admission webhooks
+type SearchAdmissionWebhookOption struct {
+	Condition mapstr.MapStr `json:"condition"`
+	Page      BasePage      `json:"page"`
+}
+
+// SearchAdmissionWebhookResult search admission webhooks result
+type SearchAdmissionWebhookResult struct {
+	Count int64              `json:"count"`
+	Info  []AdmissionWebhook `json:"info"`
+}
+
+// SearchAdmissionWebhookLogOption search the decision logs, the newest logs are returned first by default
+type SearchAdmissionWebhookLogOption struct {
+	WebhookID int64             `json:"webhook_id"`
+	ObjID     string            `json:"bk_obj_id"`
+	Decision  AdmissionDecision `json:"decision"`
+	RequestID string            `json:"rid"`
+	StartTime *time.Time        `json:"start_time"`
+	EndTime   *time.Time        `json:"end_time"`
+	Page      BasePage          `json:"page"`
+}
+
+// SearchAdmissionWebhookLogResult search the decision logs result
+type SearchAdmissionWebhookLogResult struct {
+	Count int64                 `json:"count"`
+	Info  []AdmissionWebhookLog `json:"info"`
+}
+
+// AdmissionWebhookResultData is the response of the admission webhook apis
+type AdmissionWebhookResultData struct {
+	BaseResp `json:",inline"`
+	Data     AdmissionWebhook `json:"data"`
+}
+
+// SearchAdmissionWebhookResp is the response of searching admission webhooks
+type SearchAdmissionWebhookResp struct {
+	BaseResp `json:",inline"`
+	Data     SearchAdmissionWebhookResult `json:"data"`
+}
+
+// SearchAdmissionWebhookLogResp is the response of searching decision logs
+type SearchAdmissionWebhookLogResp struct {
+	BaseResp `json:",inline"`
+	Data     SearchAdmissionWebhookLogResult `json:"data"`
+}
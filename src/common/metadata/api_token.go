@@ -0,0 +1,199 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"configcenter/src/common"
+)
+
+// APITokenKind API令牌的类型
+type APITokenKind string
+
+const (
+	// APITokenKindPersonal 个人令牌, 以创建者本人的身份访问
+	APITokenKindPersonal APITokenKind = "personal"
+	// APITokenKindService 服务账号令牌, 以服务账号的身份访问, 供脚本和第三方系统使用
+	APITokenKindService APITokenKind = "service"
+)
+
+const (
+	// APITokenPrefix 令牌明文的前缀, 便于识别和扫描泄露的令牌
+	APITokenPrefix = "cmdb_"
+	// APITokenDefaultExpireDays 令牌默认有效天数
+	APITokenDefaultExpireDays = 90
+	// APITokenMaxExpireDays 令牌最长有效天数
+	APITokenMaxExpireDays = 365
+	// APITokenMaxScopes 每个令牌最多的权限范围数量
+	APITokenMaxScopes = 50
+	// ServiceAccountPrefix 服务账号名的保留前缀, 带有该前缀的用户不能登录, 保证服务账号不会与真实用户同名
+	ServiceAccountPrefix = "svc_"
+)
+
+// serviceAccountRegexp 服务账号名的格式
+var serviceAccountRegexp = regexp.MustCompile(`^svc_[a-zA-Z0-9][a-zA-Z0-9_.-]{0,59}$`)
+
+// IsServiceAccount 用户名是否使用了服务账号的保留前缀
+func IsServiceAccount(user string) bool {
+	return strings.HasPrefix(user, ServiceAccountPrefix)
+}
+
+// APIToken 用于程序化访问的API令牌, 只保存令牌明文的sha256摘要, 明文仅在创建时返回一次
+type APIToken struct {
+	ID          int64        `json:"id" bson:"id"`
+	Name        string       `json:"name" bson:"name"`
+	Description string       `json:"description" bson:"description"`
+	Kind        APITokenKind `json:"kind" bson:"kind"`
+	// User 使用令牌访问时的用户, 个人令牌为创建者, 服务账号令牌为服务账号名
+	User      string `json:"bk_username" bson:"bk_username"`
+	TokenHash string `json:"-" bson:"token_hash"`
+	// Hint 令牌明文的末4位, 用于辨认令牌
+	Hint string `json:"hint" bson:"hint"`
+	// Scopes 令牌的权限范围, 格式为 资源类型:read 或 资源类型:write, 资源类型为*时表示所有资源
+	Scopes       []string   `json:"scopes" bson:"scopes"`
+	ExpireTime   time.Time  `json:"expire_time" bson:"expire_time"`
+	Revoked      bool       `json:"revoked" bson:"revoked"`
+	RevokeTime   *time.Time `json:"revoke_time,omitempty" bson:"revoke_time"`
+	LastUsedTime *time.Time `json:"last_used_time,omitempty" bson:"last_used_time"`
+	LastUsedIP   string     `json:"last_used_ip" bson:"last_used_ip"`
+
+	// 通用字段
+	Creator         string    `json:"creator" bson:"creator"`
+	CreateTime      time.Time `json:"create_time" bson:"create_time"`
+	LastTime        time.Time `json:"last_time" bson:"last_time"`
+	SupplierAccount string    `json:"bk_supplier_account" bson:"bk_supplier_account"`
+}
+
+// IsValid 令牌未被吊销且未过期
+func (t *APIToken) IsValid(now time.Time) bool {
+	return !t.Revoked && now.Before(t.ExpireTime)
+}
+
+// CreateAPITokenOption 创建API令牌的参数
+type CreateAPITokenOption struct {
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	Kind        APITokenKind `json:"kind"`
+	// ServiceAccount 服务账号名, 仅服务账号令牌需要
+	ServiceAccount string   `json:"service_account"`
+	Scopes         []string `json:"scopes"`
+	// ExpireDays 有效天数, 默认90天, 最长365天
+	ExpireDays int `json:"expire_days"`
+}
+
+// Validate validate the option and set the default values, returns the invalid field name and reason.
+func (o *CreateAPITokenOption) Validate() (string, error) {
+	if len(o.Name) == 0 || len(o.Name) > common.NameFieldMaxLength {
+		return "name", fmt.Errorf("name length should be in range 1~%d", common.NameFieldMaxLength)
+	}
+
+	if len(o.Kind) == 0 {
+		o.Kind = APITokenKindPersonal
+	}
+	switch o.Kind {
+	case APITokenKindPersonal:
+		if len(o.ServiceAccount) != 0 {
+			return "service_account", errors.New("personal token can not have service account")
+		}
+	case APITokenKindService:
+		if len(o.ServiceAccount) == 0 {
+			return "service_account", errors.New("service account is not set")
+		}
+		if !serviceAccountRegexp.MatchString(o.ServiceAccount) {
+			return "service_account", fmt.Errorf("service account must match %s", serviceAccountRegexp.String())
+		}
+	default:
+		return "kind", fmt.Errorf("unsupported token kind %s", o.Kind)
+	}
+
+	if len(o.Scopes) == 0 || len(o.Scopes) > APITokenMaxScopes {
+		return "scopes", fmt.Errorf("scopes count should be in range 1~%d", APITokenMaxScopes)
+	}
+
+	if o.ExpireDays == 0 {
+		o.ExpireDays = APITokenDefaultExpireDays
+	}
+	if o.ExpireDays < 0 || o.ExpireDays > APITokenMaxExpireDays {
+		return "expire_days", fmt.Errorf("expire days should be in range 1~%d", APITokenMaxExpireDays)
+	}
+	return "", nil
+}
+
+// SaveAPITokenOption 保存API令牌, 令牌摘要不会在接口中返回, 单独传递给coreservice
+type SaveAPITokenOption struct {
+	Token     APIToken `json:"token"`
+	TokenHash string   `json:"token_hash"`
+}
+
+// CreatedAPIToken 创建的API令牌, Token为令牌明文, 只返回这一次
+type CreatedAPIToken struct {
+	APIToken `json:",inline"`
+	Token    string `json:"token"`
+}
+
+type CreateAPITokenResult struct {
+	BaseResp `json:",inline"`
+	Data     CreatedAPIToken `json:"data"`
+}
+
+// ListAPITokenOption 查询API令牌的参数
+type ListAPITokenOption struct {
+	IDs     []int64      `json:"ids"`
+	Kind    APITokenKind `json:"kind"`
+	Creator string       `json:"creator"`
+	User    string       `json:"bk_username"`
+	// TokenHash 按令牌明文的摘要查询, 仅用于apiserver校验令牌
+	TokenHash string   `json:"token_hash"`
+	Page      BasePage `json:"page"`
+}
+
+func (o ListAPITokenOption) ToFilter() map[string]interface{} {
+	filter := make(map[string]interface{})
+	if len(o.IDs) != 0 {
+		filter[common.BKFieldID] = map[string]interface{}{common.BKDBIN: o.IDs}
+	}
+	if len(o.Kind) != 0 {
+		filter["kind"] = o.Kind
+	}
+	if len(o.Creator) != 0 {
+		filter[common.CreatorField] = o.Creator
+	}
+	if len(o.User) != 0 {
+		filter["bk_username"] = o.User
+	}
+	if len(o.TokenHash) != 0 {
+		filter["token_hash"] = o.TokenHash
+	}
+	return filter
+}
+
+type MultipleAPIToken struct {
+	Count int64      `json:"count"`
+	Info  []APIToken `json:"info"`
+}
+
+type ListAPITokenResult struct {
+	BaseResp `json:",inline"`
+	Data     MultipleAPIToken `json:"data"`
+}
+
+// UpdateAPITokenLastUsedOption 记录令牌最近一次使用的信息
+type UpdateAPITokenLastUsedOption struct {
+	LastUsedTime time.Time `json:"last_used_time"`
+	LastUsedIP   string    `json:"last_used_ip"`
+}
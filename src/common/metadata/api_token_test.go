@@ -0,0 +1,48 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"testing"
+)
+
+func TestCreateAPITokenOptionServiceAccount(t *testing.T) {
+	cases := []struct {
+		account string
+		valid   bool
+	}{
+		{account: "svc_deploy", valid: true},
+		{account: "svc_node.exporter-1", valid: true},
+		{account: "", valid: false},
+		{account: "admin", valid: false},
+		{account: "svc_", valid: false},
+		{account: "svc_-deploy", valid: false},
+		{account: "deploy_svc_", valid: false},
+	}
+
+	for _, c := range cases {
+		option := CreateAPITokenOption{
+			Name:           "deploy",
+			Kind:           APITokenKindService,
+			ServiceAccount: c.account,
+			Scopes:         []string{"host:read"},
+		}
+		key, err := option.Validate()
+		if c.valid && err != nil {
+			t.Errorf("service account %s should be valid, but got err: %v", c.account, err)
+		}
+		if !c.valid && (err == nil || key != "service_account") {
+			t.Errorf("service account %s should be invalid, but got key: %s, err: %v", c.account, key, err)
+		}
+	}
+}
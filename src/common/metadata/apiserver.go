@@ -46,6 +46,11 @@ type ObjectAttrResult struct {
 	Data     []Attribute `json:"data"`
 }
 
+type RestrictedFieldResult struct {
+	BaseResp `json:",inline"`
+	Data     []string `json:"data"`
+}
+
 type ObjectAttrGroupResult struct {
 	BaseResp `json:",inline"`
 	Data     []AttributeGroup `json:"data"`
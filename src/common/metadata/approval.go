@@ -0,0 +1,323 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/mapstr"
+)
+
+// ApprovalOperation is the sensitive operation which can be guarded by an approval policy
+type ApprovalOperation string
+
+const (
+	ApprovalOperationDeleteBusiness ApprovalOperation = "delete_business"
+	ApprovalOperationDeleteModel    ApprovalOperation = "delete_model"
+	ApprovalOperationDeleteSet      ApprovalOperation = "delete_set"
+	// ApprovalOperationTransferHostAcrossBiz transfer hosts from one business to another
+	ApprovalOperationTransferHostAcrossBiz ApprovalOperation = "transfer_host_across_biz"
+	// ApprovalOperationMoveHostToResourcePool transfer hosts from the business to the resource pool
+	ApprovalOperationMoveHostToResourcePool ApprovalOperation = "move_host_to_resource_pool"
+	// ApprovalOperationTransferHostWithAutoClear transfer hosts in the business and clear the service instances
+	ApprovalOperationTransferHostWithAutoClear ApprovalOperation = "transfer_host_with_auto_clear_service_instance"
+	ApprovalOperationRunHostApplyPlan          ApprovalOperation = "run_host_apply_plan"
+)
+
+// IsValid checks if the operation can be guarded by an approval policy
+func (o ApprovalOperation) IsValid() bool {
+	switch o {
+	case ApprovalOperationDeleteBusiness, ApprovalOperationDeleteModel, ApprovalOperationDeleteSet,
+		ApprovalOperationTransferHostAcrossBiz, ApprovalOperationMoveHostToResourcePool,
+		ApprovalOperationTransferHostWithAutoClear, ApprovalOperationRunHostApplyPlan:
+		return true
+	}
+	return false
+}
+
+const (
+	// ApprovalDefaultExpireSeconds is the default seconds a pending request waits for approval, which is 3 days
+	ApprovalDefaultExpireSeconds = 3 * 24 * 60 * 60
+	// ApprovalMaxExpireSeconds is the max seconds a pending request waits for approval, which is 30 days
+	ApprovalMaxExpireSeconds = 30 * 24 * 60 * 60
+)
+
+// ApprovalPolicy requires the operation to be approved by one of the approvers before it's executed
+type ApprovalPolicy struct {
+	ID        int64             `json:"id" bson:"id"`
+	Name      string            `json:"name" bson:"name"`
+	Operation ApprovalOperation `json:"operation" bson:"operation"`
+	// BizID is the business the policy applies to, 0 means all the businesses
+	BizID     int64    `json:"bk_biz_id" bson:"bk_biz_id"`
+	Approvers []string `json:"approvers" bson:"approvers"`
+	// ExpireSeconds is how long the request waits for approval before it's expired, 0 means the default value
+	ExpireSeconds int64     `json:"expire_seconds" bson:"expire_seconds"`
+	Enabled       bool      `json:"enabled" bson:"enabled"`
+	Creator       string    `json:"creator" bson:"creator"`
+	Modifier      string    `json:"modifier" bson:"modifier"`
+	CreateTime    time.Time `json:"create_time" bson:"create_time"`
+	LastTime      time.Time `json:"last_time" bson:"last_time"`
+	OwnerID       string    `json:"bk_supplier_account" bson:"bk_supplier_account"`
+}
+
+// GetExpireDuration returns how long the request waits for approval
+func (p *ApprovalPolicy) GetExpireDuration() time.Duration {
+	if p.ExpireSeconds <= 0 {
+		return ApprovalDefaultExpireSeconds * time.Second
+	}
+	return time.Duration(p.ExpireSeconds) * time.Second
+}
+
+// Validate validate the approval policy
+func (p *ApprovalPolicy) Validate() errors.RawErrorInfo {
+	if len(p.Name) == 0 {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsNeedSet,
+			Args:    []interface{}{"name"},
+		}
+	}
+	if !p.Operation.IsValid() {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsIsInvalid,
+			Args:    []interface{}{"operation"},
+		}
+	}
+	// models are not in any business
+	if p.BizID < 0 || (p.BizID != 0 && p.Operation == ApprovalOperationDeleteModel) {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsIsInvalid,
+			Args:    []interface{}{common.BKAppIDField},
+		}
+	}
+	if len(p.Approvers) == 0 {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsNeedSet,
+			Args:    []interface{}{"approvers"},
+		}
+	}
+	for _, approver := range p.Approvers {
+		if len(approver) == 0 {
+			return errors.RawErrorInfo{
+				ErrCode: common.CCErrCommParamsIsInvalid,
+				Args:    []interface{}{"approvers"},
+			}
+		}
+	}
+	if p.ExpireSeconds < 0 || p.ExpireSeconds > ApprovalMaxExpireSeconds {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsIsInvalid,
+			Args:    []interface{}{"expire_seconds"},
+		}
+	}
+	return errors.RawErrorInfo{}
+}
+
+// ApprovalStatus is the status of the approval request
+type ApprovalStatus string
+
+const (
+	// ApprovalStatusPending the request is waiting for approval
+	ApprovalStatusPending  ApprovalStatus = "pending"
+	ApprovalStatusRejected ApprovalStatus = "rejected"
+	// ApprovalStatusExecuting the request is approved and the operation is being executed
+	ApprovalStatusExecuting ApprovalStatus = "executing"
+	ApprovalStatusExecuted  ApprovalStatus = "executed"
+	// ApprovalStatusFailed the request is approved, but the operation is failed
+	ApprovalStatusFailed ApprovalStatus = "failed"
+	// ApprovalStatusExpired the request is not approved or rejected before the expire time
+	ApprovalStatusExpired ApprovalStatus = "expired"
+)
+
+// IsValid checks if the status is one of the statuses of the approval request
+func (s ApprovalStatus) IsValid() bool {
+	switch s {
+	case ApprovalStatusPending, ApprovalStatusRejected, ApprovalStatusExecuting, ApprovalStatusExecuted,
+		ApprovalStatusFailed, ApprovalStatusExpired:
+		return true
+	}
+	return false
+}
+
+// ApprovalServer is the scene server which executes the approved operation
+type ApprovalServer string
+
+const (
+	ApprovalServerTopo ApprovalServer = "topo"
+	ApprovalServerHost ApprovalServer = "host"
+)
+
+// ApprovalOriginRequest is the request of the operation, it's sent again with the requester's identity when approved
+type ApprovalOriginRequest struct {
+	Server ApprovalServer `json:"server" bson:"server"`
+	Method string         `json:"method" bson:"method"`
+	// Path is the path of the request relative to the root path of the server
+	Path string `json:"path" bson:"path"`
+	// Body is the json body of the request
+	Body json.RawMessage `json:"body,omitempty" bson:"body"`
+	// Language is the language of the requester, the errors of the execution are returned in it
+	Language string `json:"language" bson:"language"`
+}
+
+// approvalPublicRootPath is the root path of the apis in api server
+const approvalPublicRootPath = "/api/v3"
+
+// PublicPath returns the path of the request in api server, which is used to parse the permissions of the request,
+// the topo server paths of the business and the model attributes are exposed by api server with other prefixes.
+func (r ApprovalOriginRequest) PublicPath() string {
+	if r.Server == ApprovalServerTopo {
+		switch {
+		case strings.HasPrefix(r.Path, "/app/"):
+			return approvalPublicRootPath + "/biz/" + strings.TrimPrefix(r.Path, "/app/")
+		case strings.HasPrefix(r.Path, "/objectattr"):
+			return approvalPublicRootPath + "/object/attr" + strings.TrimPrefix(r.Path, "/objectattr")
+		}
+	}
+	return approvalPublicRootPath + r.Path
+}
+
+// ApprovalRequest is the pending operation which waits for approval
+type ApprovalRequest struct {
+	ID        int64             `json:"id" bson:"id"`
+	PolicyID  int64             `json:"policy_id" bson:"policy_id"`
+	Operation ApprovalOperation `json:"operation" bson:"operation"`
+	BizID     int64             `json:"bk_biz_id" bson:"bk_biz_id"`
+	Status    ApprovalStatus    `json:"status" bson:"status"`
+	Requester string            `json:"requester" bson:"requester"`
+	// Approvers are copied from the policy when the request is created
+	Approvers []string              `json:"approvers" bson:"approvers"`
+	Request   ApprovalOriginRequest `json:"request" bson:"request"`
+	// Preview is what the operation is going to do, such as the transfer plans of the hosts
+	Preview json.RawMessage `json:"preview,omitempty" bson:"preview"`
+	// Approver is the user who approved or rejected the request
+	Approver string `json:"approver" bson:"approver"`
+	Comment  string `json:"comment" bson:"comment"`
+	// Result is the response data of the executed operation, or the error message of the failed operation
+	Result     json.RawMessage `json:"result,omitempty" bson:"result"`
+	CreateTime time.Time       `json:"create_time" bson:"create_time"`
+	ExpireTime time.Time       `json:"expire_time" bson:"expire_time"`
+	LastTime   time.Time       `json:"last_time" bson:"last_time"`
+	OwnerID    string          `json:"bk_supplier_account" bson:"bk_supplier_account"`
+}
+
+// IsApprover checks if the user can approve or reject the request
+func (r *ApprovalRequest) IsApprover(user string) bool {
+	for _, approver := range r.Approvers {
+		if approver == user {
+			return true
+		}
+	}
+	return false
+}
+
+// UpdateApprovalStatusOption changes the status of the approval request, the request is updated only when it's
+// in the from status, so that the request can not be approved and rejected at the same time.
+type UpdateApprovalStatusOption struct {
+	From     ApprovalStatus  `json:"from"`
+	To       ApprovalStatus  `json:"to"`
+	Approver string          `json:"approver"`
+	Comment  string          `json:"comment"`
+	Result   json.RawMessage `json:"result,omitempty"`
+}
+
+// ApprovalDecisionOption is the decision of the approver
+type ApprovalDecisionOption struct {
+	Comment string `json:"comment"`
+}
+
+// SearchApprovalPolicyOption search approval policies
+type SearchApprovalPolicyOption struct {
+	Condition mapstr.MapStr `json:"condition"`
+	Page      BasePage      `json:"page"`
+}
+
+// SearchApprovalPolicyResult search approval policies result
+type SearchApprovalPolicyResult struct {
+	Count int64            `json:"count"`
+	Info  []ApprovalPolicy `json:"info"`
+}
+
+// SearchApprovalRequestOption search approval requests, the newest requests are returned first by default
+type SearchApprovalRequestOption struct {
+	Condition mapstr.MapStr `json:"condition"`
+	Page      BasePage      `json:"page"`
+}
+
+// SearchUserApprovalRequestOption search the approval requests which are requested by the user or waiting for the
+// user, only the typed filters are accepted, the empty filters are ignored.
+type SearchUserApprovalRequestOption struct {
+	Status    ApprovalStatus    `json:"status"`
+	Operation ApprovalOperation `json:"operation"`
+	BizID     int64             `json:"bk_biz_id"`
+	Requester string            `json:"requester"`
+	Page      BasePage          `json:"page"`
+}
+
+// Validate validate the filters of the search option
+func (o *SearchUserApprovalRequestOption) Validate() errors.RawErrorInfo {
+	if len(o.Status) != 0 && !o.Status.IsValid() {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsIsInvalid,
+			Args:    []interface{}{"status"},
+		}
+	}
+	if len(o.Operation) != 0 && !o.Operation.IsValid() {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsIsInvalid,
+			Args:    []interface{}{"operation"},
+		}
+	}
+	if o.BizID < 0 {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsIsInvalid,
+			Args:    []interface{}{common.BKAppIDField},
+		}
+	}
+	return errors.RawErrorInfo{}
+}
+
+// ToSearchOption converts the filters to the search option of the requests which are requested by the user or
+// waiting for the user
+func (o *SearchUserApprovalRequestOption) ToSearchOption(user string) SearchApprovalRequestOption {
+	cond := mapstr.MapStr{
+		common.BKDBOR: []map[string]interface{}{
+			{"requester": user},
+			{"approvers": user},
+		},
+	}
+	if len(o.Status) != 0 {
+		cond["status"] = o.Status
+	}
+	if len(o.Operation) != 0 {
+		cond["operation"] = o.Operation
+	}
+	if o.BizID != 0 {
+		cond[common.BKAppIDField] = o.BizID
+	}
+	if len(o.Requester) != 0 {
+		cond["requester"] = o.Requester
+	}
+	return SearchApprovalRequestOption{
+		Condition: cond,
+		Page:      o.Page,
+	}
+}
+
+// SearchApprovalRequestResult search approval requests result
+type SearchApprovalRequestResult struct {
+	Count int64             `json:"count"`
+	Info  []ApprovalRequest `json:"info"`
+}
@@ -0,0 +1,112 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"testing"
+	"time"
+
+	"configcenter/src/common"
+)
+
+func TestApprovalPolicyValidate(t *testing.T) {
+	valid := ApprovalPolicy{
+		Name:      "delete business",
+		Operation: ApprovalOperationDeleteBusiness,
+		Approvers: []string{"admin"},
+	}
+	tests := []struct {
+		name    string
+		modify  func(p *ApprovalPolicy)
+		errCode int
+	}{
+		{"valid", func(p *ApprovalPolicy) {}, 0},
+		{"no name", func(p *ApprovalPolicy) { p.Name = "" }, common.CCErrCommParamsNeedSet},
+		{"invalid operation", func(p *ApprovalPolicy) { p.Operation = "delete_host" }, common.CCErrCommParamsIsInvalid},
+		{"negative biz", func(p *ApprovalPolicy) { p.BizID = -1 }, common.CCErrCommParamsIsInvalid},
+		{"model policy of biz", func(p *ApprovalPolicy) {
+			p.Operation = ApprovalOperationDeleteModel
+			p.BizID = 2
+		}, common.CCErrCommParamsIsInvalid},
+		{"no approvers", func(p *ApprovalPolicy) { p.Approvers = nil }, common.CCErrCommParamsNeedSet},
+		{"empty approver", func(p *ApprovalPolicy) { p.Approvers = []string{""} }, common.CCErrCommParamsIsInvalid},
+		{"expire too long", func(p *ApprovalPolicy) { p.ExpireSeconds = ApprovalMaxExpireSeconds + 1 },
+			common.CCErrCommParamsIsInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := valid
+			policy.Approvers = append([]string{}, valid.Approvers...)
+			tt.modify(&policy)
+			if got := policy.Validate(); got.ErrCode != tt.errCode {
+				t.Errorf("Validate() = %d, want %d", got.ErrCode, tt.errCode)
+			}
+		})
+	}
+}
+
+func TestApprovalPolicyGetExpireDuration(t *testing.T) {
+	policy := ApprovalPolicy{}
+	if got := policy.GetExpireDuration(); got != ApprovalDefaultExpireSeconds*time.Second {
+		t.Errorf("GetExpireDuration() = %v, want default", got)
+	}
+	policy.ExpireSeconds = 60
+	if got := policy.GetExpireDuration(); got != time.Minute {
+		t.Errorf("GetExpireDuration() = %v, want %v", got, time.Minute)
+	}
+}
+
+func TestApprovalOriginRequestPublicPath(t *testing.T) {
+	cases := []struct {
+		request  ApprovalOriginRequest
+		expected string
+	}{
+		{ApprovalOriginRequest{Server: ApprovalServerTopo, Path: "/app/0/2"}, "/api/v3/biz/0/2"},
+		{ApprovalOriginRequest{Server: ApprovalServerTopo, Path: "/set/2/3"}, "/api/v3/set/2/3"},
+		{ApprovalOriginRequest{Server: ApprovalServerTopo, Path: "/objectattr/1"}, "/api/v3/object/attr/1"},
+		{ApprovalOriginRequest{Server: ApprovalServerHost, Path: "/hosts/modules/across/biz"},
+			"/api/v3/hosts/modules/across/biz"},
+	}
+	for _, c := range cases {
+		if path := c.request.PublicPath(); path != c.expected {
+			t.Errorf("public path of %s should be %s, got %s", c.request.Path, c.expected, path)
+		}
+	}
+}
+
+func TestSearchUserApprovalRequestOption(t *testing.T) {
+	invalid := []SearchUserApprovalRequestOption{
+		{Status: "approved"},
+		{Operation: "delete_host"},
+		{BizID: -1},
+	}
+	for _, option := range invalid {
+		if rawErr := option.Validate(); rawErr.ErrCode != common.CCErrCommParamsIsInvalid {
+			t.Errorf("option %+v should be invalid, got %d", option, rawErr.ErrCode)
+		}
+	}
+
+	option := SearchUserApprovalRequestOption{Status: ApprovalStatusPending, BizID: 2, Requester: "user1"}
+	if rawErr := option.Validate(); rawErr.ErrCode != 0 {
+		t.Fatalf("option %+v should be valid, got %d", option, rawErr.ErrCode)
+	}
+	cond := option.ToSearchOption("admin").Condition
+	if len(cond) != 4 || cond["status"] != ApprovalStatusPending || cond[common.BKAppIDField] != int64(2) ||
+		cond["requester"] != "user1" {
+		t.Errorf("unexpected condition %v", cond)
+	}
+	userCond, ok := cond[common.BKDBOR].([]map[string]interface{})
+	if !ok || len(userCond) != 2 || userCond[0]["requester"] != "admin" || userCond[1]["approvers"] != "admin" {
+		t.Errorf("the requests should be limited to the user, got %v", cond[common.BKDBOR])
+	}
+}
@@ -14,6 +14,7 @@ package metadata
 
 import (
 	"configcenter/src/common/mapstr"
+	"configcenter/src/common/querybuilder"
 )
 
 const (
@@ -34,6 +35,10 @@ const (
 	// AssociationFieldAssociationId auto incr id
 	AssociationFieldAssociationId   = "id"
 	AssociationFieldAssociationKind = "bk_asst_id"
+	// AssociationFieldAliasName the alias name of the association
+	AssociationFieldAliasName = "bk_obj_asst_name"
+	AssociationFieldMapping   = "mapping"
+	AssociationFieldOnDelete  = "on_delete"
 )
 
 type SearchAssociationTypeRequest struct {
@@ -106,6 +111,8 @@ type SearchAssociationRelatedInstRequestCond struct {
 
 type SearchAssociationInstRequest struct {
 	Condition mapstr.MapStr `json:"condition"` // construct condition mapstr by condition.Condition
+	// Filter 结构化的过滤条件, 与condition取交集
+	Filter *querybuilder.QueryFilter `json:"filter,omitempty"`
 }
 
 type SearchAssociationRelatedInstRequest struct {
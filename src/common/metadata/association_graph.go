@@ -0,0 +1,129 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"errors"
+	"fmt"
+
+	"configcenter/src/common"
+)
+
+const (
+	// InstGraphDirectionOut 沿关联的源实例->目标实例方向遍历
+	InstGraphDirectionOut = "out"
+	// InstGraphDirectionIn 沿关联的目标实例->源实例方向遍历
+	InstGraphDirectionIn = "in"
+	// InstGraphDirectionBoth 双向遍历
+	InstGraphDirectionBoth = "both"
+
+	InstGraphDefaultDepth     = 2
+	InstGraphMaxDepth         = 6
+	InstGraphDefaultFanOut    = 100
+	InstGraphMaxFanOut        = 500
+	InstGraphMaxNodes         = 5000
+	InstGraphDefaultPageLimit = 200
+)
+
+// SearchInstAssociationGraphRequest multi-hop instance association graph query
+type SearchInstAssociationGraphRequest struct {
+	// ObjectID and InstID is the start instance of the traversal
+	ObjectID string `json:"bk_obj_id"`
+	InstID   int64  `json:"bk_inst_id"`
+	// Depth the max hops from the start instance
+	Depth int `json:"depth"`
+	// Direction out, in or both, default is both
+	Direction string `json:"direction"`
+	// AssociationKindIDs only traverse the association of these kinds if set, such as "connect", "run"
+	AssociationKindIDs []string `json:"bk_asst_ids"`
+	// ObjectIDs only traverse into the instances of these objects if set
+	ObjectIDs []string `json:"bk_obj_ids"`
+	// MaxFanOut the max associations expanded for one instance, the others are dropped and the node is marked as truncated
+	MaxFanOut int `json:"max_fan_out"`
+	// Page paging the nodes sorted by depth, only start and limit is used
+	Page BasePage `json:"page"`
+}
+
+// Validate validate the request and set the default values
+func (r *SearchInstAssociationGraphRequest) Validate() (string, error) {
+	if r.ObjectID == "" {
+		return common.BKObjIDField, errors.New("start object can not be empty")
+	}
+	if r.InstID <= 0 {
+		return common.BKInstIDField, errors.New("start instance id must be positive")
+	}
+
+	if r.Depth == 0 {
+		r.Depth = InstGraphDefaultDepth
+	}
+	if r.Depth < 0 || r.Depth > InstGraphMaxDepth {
+		return "depth", fmt.Errorf("depth must between 1 and %d", InstGraphMaxDepth)
+	}
+
+	switch r.Direction {
+	case "":
+		r.Direction = InstGraphDirectionBoth
+	case InstGraphDirectionOut, InstGraphDirectionIn, InstGraphDirectionBoth:
+	default:
+		return "direction", fmt.Errorf("direction must be one of %s, %s, %s", InstGraphDirectionOut,
+			InstGraphDirectionIn, InstGraphDirectionBoth)
+	}
+
+	if r.MaxFanOut == 0 {
+		r.MaxFanOut = InstGraphDefaultFanOut
+	}
+	if r.MaxFanOut < 0 || r.MaxFanOut > InstGraphMaxFanOut {
+		return "max_fan_out", fmt.Errorf("max_fan_out must between 1 and %d", InstGraphMaxFanOut)
+	}
+
+	if r.Page.Limit == 0 {
+		r.Page.Limit = InstGraphDefaultPageLimit
+	}
+	if r.Page.Limit < 0 || r.Page.Limit > common.BKMaxPageSize {
+		return "page.limit", fmt.Errorf("limit must between 1 and %d", common.BKMaxPageSize)
+	}
+	if r.Page.Start < 0 {
+		return "page.start", errors.New("start can not be negative")
+	}
+	return "", nil
+}
+
+// InstGraphNode an instance in the association graph
+type InstGraphNode struct {
+	ObjectID string `json:"bk_obj_id"`
+	InstID   int64  `json:"bk_inst_id"`
+	InstName string `json:"bk_inst_name"`
+	// Depth the hops from the start instance
+	Depth int `json:"depth"`
+	// Path the instance association ids of the shortest path from the start instance to this instance
+	Path []int64 `json:"path"`
+	// Truncated the associations of this instance exceed max fan out, some of them are not expanded
+	Truncated bool `json:"truncated"`
+}
+
+// InstAssociationGraph the result of the instance association graph query
+type InstAssociationGraph struct {
+	// Count the total nodes found, including the start instance
+	Count int `json:"count"`
+	// Nodes the nodes of this page
+	Nodes []InstGraphNode `json:"nodes"`
+	// Edges the associations between the nodes of this page and the associations on their paths
+	Edges []InstAsst `json:"edges"`
+	// Truncated whether the traversal is stopped by fan out or node limit, the result is incomplete
+	Truncated bool `json:"truncated"`
+}
+
+type SearchInstAssociationGraphResult struct {
+	BaseResp `json:",inline"`
+	Data     InstAssociationGraph `json:"data"`
+}
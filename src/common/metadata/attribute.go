@@ -47,6 +47,7 @@ const (
 	AttributeFieldIsOnly          = "isonly"
 	AttributeFieldIsSystem        = "bk_issystem"
 	AttributeFieldIsAPI           = "bk_isapi"
+	AttributeFieldIsRestricted    = "bk_isrestricted"
 	AttributeFieldPropertyType    = "bk_property_type"
 	AttributeFieldOption          = "option"
 	AttributeFieldDescription     = "description"
@@ -75,6 +76,7 @@ type Attribute struct {
 	IsOnly            bool        `field:"isonly" json:"isonly" bson:"isonly"`
 	IsSystem          bool        `field:"bk_issystem" json:"bk_issystem" bson:"bk_issystem"`
 	IsAPI             bool        `field:"bk_isapi" json:"bk_isapi" bson:"bk_isapi"`
+	IsRestricted      bool        `field:"bk_isrestricted" json:"bk_isrestricted" bson:"bk_isrestricted"`
 	PropertyType      string      `field:"bk_property_type" json:"bk_property_type" bson:"bk_property_type"`
 	Option            interface{} `field:"option" json:"option" bson:"option"`
 	Description       string      `field:"description" json:"description" bson:"description"`
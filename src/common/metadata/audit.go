@@ -23,10 +23,14 @@ import (
 
 type AuditQueryResult struct {
 	BaseResp `json:",inline"`
-	Data     struct {
-		Count int64      `json:"count"`
-		Info  []AuditLog `json:"info"`
-	} `json:"data"`
+	Data     AuditQueryData `json:"data"`
+}
+
+type AuditQueryData struct {
+	Count int64      `json:"count"`
+	Info  []AuditLog `json:"info"`
+	// NextCursor 游标分页时下一页的cursor, 为空表示没有更多数据
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 type CreateAuditLogParam struct {
@@ -53,6 +57,13 @@ func (input *AuditQueryInput) Validate() errors.RawErrorInfo {
 		}
 	}
 
+	if input.Page.IsCursorPage() && input.Page.Start != 0 {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsInvalid,
+			Args:    []interface{}{"start"},
+		}
+	}
+
 	return errors.RawErrorInfo{}
 }
 
@@ -108,6 +119,22 @@ type AuditLog struct {
 	ResourceName string `json:"resource_name" bson:"resource_name"`
 }
 
+// CursorValues returns the sortable fields of audit log, used to generate the page cursor
+func (auditLog *AuditLog) CursorValues() map[string]interface{} {
+	return map[string]interface{}{
+		common.BKFieldID:            auditLog.ID,
+		common.BKAuditTypeField:     string(auditLog.AuditType),
+		common.BKUser:               auditLog.User,
+		common.BKResourceTypeField:  string(auditLog.ResourceType),
+		common.BKActionField:        string(auditLog.Action),
+		common.BKOperateFromField:   string(auditLog.OperateFrom),
+		common.BKOperationTimeField: auditLog.OperationTime,
+		common.BKAppIDField:         auditLog.BusinessID,
+		common.BKResourceIDField:    auditLog.ResourceID,
+		common.BKResourceNameField:  auditLog.ResourceName,
+	}
+}
+
 type bsonAuditLog struct {
 	ID              int64           `json:"id" bson:"id"`
 	AuditType       AuditType       `json:"audit_type" bson:"audit_type"`
@@ -430,6 +457,15 @@ const (
 
 	// DynamicGroupType is dynamic grouping audit type.
 	DynamicGroupType AuditType = "dynamic_grouping"
+
+	// APITokenType represent the api token operation audit.
+	APITokenType AuditType = "api_token"
+
+	// ApprovalType represent the approval request operation audit.
+	ApprovalType AuditType = "approval"
+
+	// ChangeFreezeType represent the change freeze window operation audit.
+	ChangeFreezeType AuditType = "change_freeze"
 )
 
 type ResourceType string
@@ -467,6 +503,9 @@ const (
 	CloudAreaRes       ResourceType = "cloud_area"
 	CloudAccountRes    ResourceType = "cloud_account"
 	CloudSyncTaskRes   ResourceType = "cloud_sync_task"
+	APITokenRes        ResourceType = "api_token"
+	ApprovalRequestRes ResourceType = "approval_request"
+	ChangeFreezeRes    ResourceType = "change_freeze_window"
 
 	// host related operation type
 	HostRes ResourceType = "host"
@@ -487,6 +526,10 @@ const (
 	FromSynchronizer OperateFromType = "synchronizer"
 	// FromCloudSync means this audit is created by cloud sync.
 	FromCloudSync OperateFromType = "cloud_sync"
+	// FromAPIToken means this audit is created by a request authenticated with an api token.
+	FromAPIToken OperateFromType = "api_token"
+	// FromApproval means this audit is created by an approved request which is executed for the requester.
+	FromApproval OperateFromType = "approval"
 )
 
 // ActionType defines all the user's operation type
@@ -513,6 +556,14 @@ const (
 	AuditPause ActionType = "stop"
 	// resume using an object
 	AuditResume ActionType = "resume"
+	// approve a request
+	AuditApprove ActionType = "approve"
+	// reject a request
+	AuditReject ActionType = "reject"
+	// a request is expired
+	AuditExpire ActionType = "expire"
+	// make changes in an active change freeze window
+	AuditOverride ActionType = "override"
 )
 
 func GetAuditTypeByObjID(objID string, isMainline bool) AuditType {
@@ -572,7 +623,8 @@ func GetAuditTypesByCategory(category string) []AuditType {
 	case "host":
 		return []AuditType{HostType}
 	case "other":
-		return []AuditType{ModelType, AssociationKindType, EventPushType, DynamicGroupType}
+		return []AuditType{ModelType, AssociationKindType, EventPushType, DynamicGroupType, APITokenType, ApprovalType,
+			ChangeFreezeType}
 	}
 	return []AuditType{}
 }
@@ -740,6 +792,34 @@ var auditDict = []resourceTypeInfo{
 			actionInfoMap[AuditDelete],
 		},
 	},
+	{
+		ID:   APITokenRes,
+		Name: "API令牌",
+		Operations: []actionTypeInfo{
+			actionInfoMap[AuditCreate],
+			actionInfoMap[AuditUpdate],
+		},
+	},
+	{
+		ID:   ApprovalRequestRes,
+		Name: "审批单",
+		Operations: []actionTypeInfo{
+			actionInfoMap[AuditCreate],
+			actionInfoMap[AuditApprove],
+			actionInfoMap[AuditReject],
+			actionInfoMap[AuditExpire],
+		},
+	},
+	{
+		ID:   ChangeFreezeRes,
+		Name: "变更冻结窗口",
+		Operations: []actionTypeInfo{
+			actionInfoMap[AuditCreate],
+			actionInfoMap[AuditUpdate],
+			actionInfoMap[AuditDelete],
+			actionInfoMap[AuditOverride],
+		},
+	},
 }
 
 var actionInfoMap = map[ActionType]actionTypeInfo{
@@ -753,6 +833,10 @@ var actionInfoMap = map[ActionType]actionTypeInfo{
 	AuditRecover:            {ID: AuditRecover, Name: "恢复"},
 	AuditPause:              {ID: AuditPause, Name: "停用"},
 	AuditResume:             {ID: AuditResume, Name: "启用"},
+	AuditApprove:            {ID: AuditApprove, Name: "审批通过"},
+	AuditReject:             {ID: AuditReject, Name: "审批拒绝"},
+	AuditExpire:             {ID: AuditExpire, Name: "审批过期"},
+	AuditOverride:           {ID: AuditOverride, Name: "冻结期强制变更"},
 }
 
 type resourceTypeInfo struct {
@@ -0,0 +1,278 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/util"
+)
+
+// CloneBizTopoOption 克隆业务拓扑时的可选内容, 主线拓扑与模板绑定总是会被克隆, 主机永远不会被克隆
+type CloneBizTopoOption struct {
+	// WithAttributes 是否同时克隆自定义层级、集群、模块的属性值
+	WithAttributes bool `json:"with_attributes" mapstructure:"with_attributes"`
+	// WithDynamicGroups 是否同时克隆动态分组
+	WithDynamicGroups bool `json:"with_dynamic_groups" mapstructure:"with_dynamic_groups"`
+	// WithHostApplyRules 是否同时克隆主机属性自动应用规则
+	WithHostApplyRules bool `json:"with_host_apply_rules" mapstructure:"with_host_apply_rules"`
+}
+
+// CloneBizTopoRequest 克隆业务拓扑请求, TargetBizID 与 Business 二选一:
+// TargetBizID 为已存在的空业务, Business 为新建业务的属性
+type CloneBizTopoRequest struct {
+	TargetBizID        int64         `json:"bk_target_biz_id" mapstructure:"bk_target_biz_id"`
+	Business           mapstr.MapStr `json:"business" mapstructure:"business"`
+	CloneBizTopoOption `json:",inline" mapstructure:",squash"`
+}
+
+// Validate validate clone business topology request
+func (r *CloneBizTopoRequest) Validate() (string, error) {
+	if r.TargetBizID < 0 {
+		return "bk_target_biz_id", errors.New("bk_target_biz_id should be positive")
+	}
+	if r.TargetBizID == 0 && len(r.Business) == 0 {
+		return "business", errors.New("one of bk_target_biz_id and business must be set")
+	}
+	if r.TargetBizID != 0 && len(r.Business) != 0 {
+		return "business", errors.New("bk_target_biz_id and business can not be set at the same time")
+	}
+	return "", nil
+}
+
+// CloneBizTopoTask task_server 中克隆业务拓扑子任务的数据
+type CloneBizTopoTask struct {
+	// CloneID 克隆任务的唯一标识, 用于记录各阶段的执行进度, 任务重试时从未完成的阶段继续执行
+	CloneID  string             `json:"clone_id" mapstructure:"clone_id"`
+	SrcBizID int64              `json:"bk_src_biz_id" mapstructure:"bk_src_biz_id"`
+	BizID    int64              `json:"bk_biz_id" mapstructure:"bk_biz_id"`
+	Option   CloneBizTopoOption `json:"option" mapstructure:"option"`
+}
+
+// CloneBizTopoProgress 克隆任务的执行进度, 每个阶段提交后记录, 任务重试时跳过已完成的阶段
+type CloneBizTopoProgress struct {
+	FinishedStages []string            `json:"finished_stages"`
+	Result         *CloneBizTopoResult `json:"result"`
+	// Unregistered 已提交但还未注册到iam的资源
+	Unregistered []IamInstanceWithCreator `json:"unregistered"`
+}
+
+// IsFinished check if the stage is finished
+func (p *CloneBizTopoProgress) IsFinished(stage string) bool {
+	for _, finished := range p.FinishedStages {
+		if finished == stage {
+			return true
+		}
+	}
+	return false
+}
+
+// CloneBizTopoResult 克隆结果, 记录源业务中各资源 ID 与目标业务中新 ID 的对应关系
+type CloneBizTopoResult struct {
+	SrcBizID int64 `json:"bk_src_biz_id"`
+	BizID    int64 `json:"bk_biz_id"`
+	// Instances 主线实例(自定义层级、集群、模块) bk_obj_id -> 旧实例ID -> 新实例ID
+	Instances         map[string]map[int64]int64 `json:"instances"`
+	ServiceCategories map[int64]int64            `json:"service_categories"`
+	ServiceTemplates  map[int64]int64            `json:"service_templates"`
+	ProcessTemplates  map[int64]int64            `json:"process_templates"`
+	SetTemplates      map[int64]int64            `json:"set_templates"`
+	HostApplyRules    map[int64]int64            `json:"host_apply_rules"`
+	DynamicGroups     map[string]string          `json:"dynamic_groups"`
+}
+
+// NewCloneBizTopoResult create an empty clone result, the business itself is mapped in Instances
+func NewCloneBizTopoResult(srcBizID, bizID int64) *CloneBizTopoResult {
+	result := &CloneBizTopoResult{
+		SrcBizID:          srcBizID,
+		BizID:             bizID,
+		Instances:         make(map[string]map[int64]int64),
+		ServiceCategories: make(map[int64]int64),
+		ServiceTemplates:  make(map[int64]int64),
+		ProcessTemplates:  make(map[int64]int64),
+		SetTemplates:      make(map[int64]int64),
+		HostApplyRules:    make(map[int64]int64),
+		DynamicGroups:     make(map[string]string),
+	}
+	result.AddInst(common.BKInnerObjIDApp, srcBizID, bizID)
+	return result
+}
+
+// AddInst record the new id of a cloned mainline instance
+func (r *CloneBizTopoResult) AddInst(objID string, oldID, newID int64) {
+	if _, ok := r.Instances[objID]; !ok {
+		r.Instances[objID] = make(map[int64]int64)
+	}
+	r.Instances[objID][oldID] = newID
+}
+
+// InstID return the new id of a cloned mainline instance
+func (r *CloneBizTopoResult) InstID(objID string, oldID int64) (int64, bool) {
+	newID, ok := r.Instances[objID][oldID]
+	return newID, ok
+}
+
+// Clone 复制克隆结果, 阶段失败回滚时丢弃该阶段在复制的结果上记录的映射
+func (r *CloneBizTopoResult) Clone() (*CloneBizTopoResult, error) {
+	result := new(CloneBizTopoResult)
+	if err := convertCloneBizTopoData(r, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// cloneDynamicGroupFields 动态分组条件中引用了拓扑资源 ID 的字段
+var cloneDynamicGroupFields = map[string]func(r *CloneBizTopoResult, id int64) (int64, bool){
+	common.BKSetIDField: func(r *CloneBizTopoResult, id int64) (int64, bool) {
+		return r.InstID(common.BKInnerObjIDSet, id)
+	},
+	common.BKModuleIDField: func(r *CloneBizTopoResult, id int64) (int64, bool) {
+		return r.InstID(common.BKInnerObjIDModule, id)
+	},
+	common.BKServiceTemplateIDField: func(r *CloneBizTopoResult, id int64) (int64, bool) {
+		newID, ok := r.ServiceTemplates[id]
+		return newID, ok
+	},
+	common.BKSetTemplateIDField: func(r *CloneBizTopoResult, id int64) (int64, bool) {
+		newID, ok := r.SetTemplates[id]
+		return newID, ok
+	},
+}
+
+// CloneDynamicGroupInfo 将动态分组条件中引用的集群、模块、模板 ID 替换为克隆后的新 ID
+func (r *CloneBizTopoResult) CloneDynamicGroupInfo(info DynamicGroupInfo) (DynamicGroupInfo, error) {
+	result := DynamicGroupInfo{Condition: make([]DynamicGroupInfoCondition, 0, len(info.Condition))}
+	for _, infoCond := range info.Condition {
+		conditions := make([]DynamicGroupCondition, 0, len(infoCond.Condition))
+		for _, cond := range infoCond.Condition {
+			mapFunc, ok := cloneDynamicGroupFields[cond.Field]
+			if !ok {
+				conditions = append(conditions, cond)
+				continue
+			}
+
+			switch value := cond.Value.(type) {
+			case []interface{}:
+				values := make([]interface{}, 0, len(value))
+				for _, item := range value {
+					newID, err := r.cloneDynamicGroupValue(mapFunc, cond.Field, item)
+					if err != nil {
+						return result, err
+					}
+					values = append(values, newID)
+				}
+				cond.Value = values
+			default:
+				newID, err := r.cloneDynamicGroupValue(mapFunc, cond.Field, value)
+				if err != nil {
+					return result, err
+				}
+				cond.Value = newID
+			}
+			conditions = append(conditions, cond)
+		}
+		result.Condition = append(result.Condition, DynamicGroupInfoCondition{
+			ObjID:     infoCond.ObjID,
+			Condition: conditions,
+		})
+	}
+	return result, nil
+}
+
+func (r *CloneBizTopoResult) cloneDynamicGroupValue(mapFunc func(r *CloneBizTopoResult, id int64) (int64, bool),
+	field string, value interface{}) (int64, error) {
+
+	id, err := util.GetInt64ByInterface(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s value %v, err: %v", field, value, err)
+	}
+	newID, ok := mapFunc(r, id)
+	if !ok {
+		return 0, fmt.Errorf("%s %d is not cloned", field, id)
+	}
+	return newID, nil
+}
+
+// CloneBizTopoTaskResult 克隆任务的执行状态, 任务成功后 Result 为新旧 ID 映射, 失败时为已完成阶段的部分映射
+type CloneBizTopoTaskResult struct {
+	TaskID     string              `json:"task_id"`
+	SrcBizID   int64               `json:"bk_src_biz_id"`
+	BizID      int64               `json:"bk_biz_id"`
+	Status     APITaskStatus       `json:"status"`
+	Result     *CloneBizTopoResult `json:"result,omitempty"`
+	ErrMsg     string              `json:"bk_error_msg,omitempty"`
+	CreateTime time.Time           `json:"create_time"`
+	LastTime   time.Time           `json:"last_time"`
+}
+
+// NewCloneBizTopoTaskResult 由 task_server 中的任务详情解析克隆任务的执行状态与结果
+func NewCloneBizTopoTaskResult(detail APITaskDetail) (*CloneBizTopoTaskResult, error) {
+	result := &CloneBizTopoTaskResult{
+		TaskID:     detail.TaskID,
+		Status:     detail.Status,
+		CreateTime: detail.CreateTime,
+		LastTime:   detail.LastTime,
+	}
+	// 克隆任务只有一个子任务
+	if len(detail.Detail) == 0 {
+		return result, nil
+	}
+	subTask := detail.Detail[0]
+
+	task := CloneBizTopoTask{}
+	if err := convertCloneBizTopoData(subTask.Data, &task); err != nil {
+		return nil, fmt.Errorf("parse clone task data failed, err: %v", err)
+	}
+	result.SrcBizID = task.SrcBizID
+	result.BizID = task.BizID
+
+	if subTask.Response == nil {
+		return result, nil
+	}
+	if !subTask.Response.Result {
+		result.ErrMsg = subTask.Response.ErrMsg
+	}
+	// 任务失败时 Data 为已完成阶段的新旧 ID 映射
+	if subTask.Response.Data != nil {
+		result.Result = &CloneBizTopoResult{}
+		if err := convertCloneBizTopoData(subTask.Response.Data, result.Result); err != nil {
+			return nil, fmt.Errorf("parse clone task response failed, err: %v", err)
+		}
+	}
+	return result, nil
+}
+
+// convertCloneBizTopoData task_server 中存储的数据为通用的map, 通过json转换为具体的结构
+func convertCloneBizTopoData(data interface{}, result interface{}) error {
+	js, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(js, result)
+}
+
+// CloneBizTopoTaskResponse clone business topology task response
+type CloneBizTopoTaskResponse struct {
+	BaseResp `json:",inline"`
+	Data     CloneBizTopoTaskResult `json:"data"`
+}
+
+// GetCloneBizTopoIndex 返回task_server中克隆任务的检索值(flag), 以目标业务为维度
+func GetCloneBizTopoIndex(bizID int64) string {
+	return fmt.Sprintf("clone_biz_topo:%d", bizID)
+}
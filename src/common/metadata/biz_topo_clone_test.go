@@ -0,0 +1,151 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"encoding/json"
+	"testing"
+
+	"configcenter/src/common"
+	"configcenter/src/common/mapstr"
+)
+
+func TestCloneBizTopoRequestValidate(t *testing.T) {
+	cases := []struct {
+		req   CloneBizTopoRequest
+		valid bool
+	}{
+		{req: CloneBizTopoRequest{TargetBizID: 3}, valid: true},
+		{req: CloneBizTopoRequest{Business: mapstr.MapStr{"bk_biz_name": "biz"}}, valid: true},
+		{req: CloneBizTopoRequest{}, valid: false},
+		{req: CloneBizTopoRequest{TargetBizID: -1}, valid: false},
+		{req: CloneBizTopoRequest{TargetBizID: 3, Business: mapstr.MapStr{"bk_biz_name": "biz"}}, valid: false},
+	}
+	for idx, c := range cases {
+		if _, err := c.req.Validate(); (err == nil) != c.valid {
+			t.Fatalf("case %d expect valid %v, got err: %v", idx, c.valid, err)
+		}
+	}
+}
+
+func TestCloneDynamicGroupInfo(t *testing.T) {
+	result := NewCloneBizTopoResult(2, 3)
+	result.AddInst(common.BKInnerObjIDSet, 10, 110)
+	result.AddInst(common.BKInnerObjIDModule, 20, 120)
+	result.AddInst(common.BKInnerObjIDModule, 21, 121)
+	result.ServiceTemplates[5] = 105
+
+	info := DynamicGroupInfo{Condition: []DynamicGroupInfoCondition{
+		{
+			ObjID: common.BKInnerObjIDSet,
+			Condition: []DynamicGroupCondition{
+				{Field: common.BKSetIDField, Operator: DynamicGroupOperatorEQ, Value: float64(10)},
+				{Field: common.BKSetNameField, Operator: DynamicGroupOperatorEQ, Value: "set"},
+			},
+		},
+		{
+			ObjID: common.BKInnerObjIDModule,
+			Condition: []DynamicGroupCondition{
+				{Field: common.BKModuleIDField, Operator: DynamicGroupOperatorIN, Value: []interface{}{float64(20), json.Number("21")}},
+				{Field: common.BKServiceTemplateIDField, Operator: DynamicGroupOperatorIN, Value: []interface{}{5}},
+			},
+		},
+	}}
+
+	cloned, err := result.CloneDynamicGroupInfo(info)
+	if err != nil {
+		t.Fatalf("clone dynamic group info failed, err: %v", err)
+	}
+	setCond := cloned.Condition[0].Condition
+	if setCond[0].Value != int64(110) || setCond[1].Value != "set" {
+		t.Fatalf("unexpected set condition: %+v", setCond)
+	}
+	moduleCond := cloned.Condition[1].Condition
+	moduleIDs := moduleCond[0].Value.([]interface{})
+	if len(moduleIDs) != 2 || moduleIDs[0] != int64(120) || moduleIDs[1] != int64(121) {
+		t.Fatalf("unexpected module condition: %+v", moduleCond[0])
+	}
+	if moduleCond[1].Value.([]interface{})[0] != int64(105) {
+		t.Fatalf("unexpected service template condition: %+v", moduleCond[1])
+	}
+	// 原分组条件不应被修改
+	if info.Condition[0].Condition[0].Value != float64(10) {
+		t.Fatalf("source condition should not be changed: %+v", info.Condition[0].Condition[0])
+	}
+
+	info.Condition[0].Condition[0].Value = float64(11)
+	if _, err := result.CloneDynamicGroupInfo(info); err == nil {
+		t.Fatalf("clone dynamic group info referring to uncloned set should fail")
+	}
+}
+
+func TestNewCloneBizTopoTaskResult(t *testing.T) {
+	cloneResult := NewCloneBizTopoResult(2, 3)
+	cloneResult.AddInst(common.BKInnerObjIDSet, 10, 110)
+	cloneResult.SetTemplates[7] = 8
+
+	// task_server 存储的数据均为通用的map
+	toMap := func(v interface{}) interface{} {
+		js, _ := json.Marshal(v)
+		var data interface{}
+		_ = json.Unmarshal(js, &data)
+		return data
+	}
+	detail := APITaskDetail{
+		TaskID: "task",
+		Status: APITaskStatusSuccess,
+		Detail: []APISubTaskDetail{{
+			Data:     toMap(CloneBizTopoTask{SrcBizID: 2, BizID: 3}),
+			Status:   APITaskStatusSuccess,
+			Response: &Response{BaseResp: BaseResp{Result: true}, Data: toMap(cloneResult)},
+		}},
+	}
+
+	result, err := NewCloneBizTopoTaskResult(detail)
+	if err != nil {
+		t.Fatalf("parse clone task failed, err: %v", err)
+	}
+	if result.TaskID != "task" || result.SrcBizID != 2 || result.BizID != 3 || result.Result == nil {
+		t.Fatalf("unexpected clone task result: %+v", result)
+	}
+	if id, ok := result.Result.InstID(common.BKInnerObjIDApp, 2); !ok || id != 3 {
+		t.Fatalf("business should be mapped, got %d", id)
+	}
+	if id, ok := result.Result.InstID(common.BKInnerObjIDSet, 10); !ok || id != 110 {
+		t.Fatalf("set should be mapped, got %d", id)
+	}
+	if result.Result.SetTemplates[7] != 8 {
+		t.Fatalf("set template should be mapped: %+v", result.Result.SetTemplates)
+	}
+
+	detail.Status = APITAskStatusFail
+	detail.Detail[0].Status = APITAskStatusFail
+	detail.Detail[0].Response = &Response{BaseResp: BaseResp{Result: false, ErrMsg: "failed"}}
+	result, err = NewCloneBizTopoTaskResult(detail)
+	if err != nil {
+		t.Fatalf("parse clone task failed, err: %v", err)
+	}
+	if result.Result != nil || result.ErrMsg != "failed" {
+		t.Fatalf("unexpected failed clone task result: %+v", result)
+	}
+
+	// the partial mapping of the finished stages is reported when failed
+	detail.Detail[0].Response.Data = toMap(cloneResult)
+	result, err = NewCloneBizTopoTaskResult(detail)
+	if err != nil {
+		t.Fatalf("parse clone task failed, err: %v", err)
+	}
+	if result.Result == nil || result.Result.SetTemplates[7] != 8 || result.ErrMsg != "failed" {
+		t.Fatalf("unexpected partial clone task result: %+v", result)
+	}
+}
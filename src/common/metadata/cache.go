@@ -32,6 +32,58 @@ type ListWithIDOption struct {
 	Fields []string `json:"fields"`
 }
 
+// SearchInstWithUniqueKeyOption find a model instance with the values of a unique rule of the model.
+type SearchInstWithUniqueKeyOption struct {
+	// Condition is the property id and value map of a unique rule, the fields must be same as the rule's fields.
+	Condition map[string]interface{} `json:"condition"`
+	// only return these fields in instance.
+	Fields []string `json:"fields"`
+}
+
+// CheckInstCacheConsistencyOption check the model instance cache with the data in mongodb
+type CheckInstCacheConsistencyOption struct {
+	// Repair the inconsistent cache with the data in mongodb
+	Repair bool `json:"repair"`
+}
+
+// InstCacheConsistencyResult is the result of model instance cache consistency check
+type InstCacheConsistencyResult struct {
+	ObjID string `json:"bk_obj_id"`
+	// Total is the count of instances in mongodb
+	Total int64 `json:"total"`
+	// Consistent is the count of cached instances which are same as mongodb
+	Consistent int64 `json:"consistent"`
+	// Missing is the count of instances which are not cached, except the ones over the size limits
+	Missing int64 `json:"missing"`
+	// Inconsistent is the count of cached instances which are different from mongodb
+	Inconsistent int64 `json:"inconsistent"`
+	// Redundant is the count of cached instances which are not exist in mongodb
+	Redundant int64 `json:"redundant"`
+	// Uncached is the count of instances which are not cached because of the size limits
+	Uncached int64 `json:"uncached"`
+	Repaired bool  `json:"repaired"`
+}
+
+// ListServiceInstanceWithHostOption list the service instances and processes on the hosts in cache.
+type ListServiceInstanceWithHostOption struct {
+	// BizID only return the service instances of this business, 0 means all businesses.
+	BizID int64 `json:"bk_biz_id"`
+	// length range is [1,500]
+	HostIDs []int64 `json:"bk_host_ids"`
+}
+
+// HostServiceInstanceCache is the service instances and processes on a host in cache.
+type HostServiceInstanceCache struct {
+	HostID           int64                  `json:"bk_host_id"`
+	ServiceInstances []ServiceInstanceCache `json:"service_instances"`
+}
+
+// ServiceInstanceCache is the service instance with it's processes, the process property contains the bind info.
+type ServiceInstanceCache struct {
+	ServiceInstance `json:",inline"`
+	Processes       []ProcessInstance `json:"processes"`
+}
+
 type DeleteArchive struct {
 	Oid    string      `json:"oid" bson:"oid"`
 	Detail interface{} `json:"detail" bson:"detail"`
@@ -0,0 +1,270 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/mapstr"
+)
+
+// ChangeFreezeResource is the kind of resources whose changes are forbidden in a freeze window
+type ChangeFreezeResource string
+
+const (
+	ChangeFreezeHost ChangeFreezeResource = "host"
+	// ChangeFreezeTopology the business, sets, modules, mainline instances and set templates
+	ChangeFreezeTopology        ChangeFreezeResource = "topology"
+	ChangeFreezeServiceInstance ChangeFreezeResource = "service_instance"
+	ChangeFreezeProcess         ChangeFreezeResource = "process"
+)
+
+// ChangeFreezeAnyBizID is the business id of the changed resources whose business can not be told from the request,
+// such as the hosts updated in batch by their ids, these changes are frozen by the windows of all the businesses
+const ChangeFreezeAnyBizID int64 = -1
+
+// IsValid checks if the resource can be frozen
+func (r ChangeFreezeResource) IsValid() bool {
+	switch r {
+	case ChangeFreezeHost, ChangeFreezeTopology, ChangeFreezeServiceInstance, ChangeFreezeProcess:
+		return true
+	}
+	return false
+}
+
+// ChangeFreezeWindow forbids the changes of the resources in the business during the schedule
+type ChangeFreezeWindow struct {
+	ID          int64  `json:"id" bson:"id"`
+	Name        string `json:"name" bson:"name"`
+	Description string `json:"description" bson:"description"`
+	// BizID is the business to freeze, 0 means all the businesses
+	BizID int64 `json:"bk_biz_id" bson:"bk_biz_id"`
+	// Resources are the kinds of resources to freeze, empty means all the kinds
+	Resources []ChangeFreezeResource `json:"resources" bson:"resources"`
+	Schedule  ChangeFreezeSchedule   `json:"schedule" bson:"schedule"`
+	// ExemptUsers and ExemptApps can still make changes in the window, the apps are matched by the app code header
+	ExemptUsers []string  `json:"exempt_users" bson:"exempt_users"`
+	ExemptApps  []string  `json:"exempt_apps" bson:"exempt_apps"`
+	Enabled     bool      `json:"enabled" bson:"enabled"`
+	Creator     string    `json:"creator" bson:"creator"`
+	Modifier    string    `json:"modifier" bson:"modifier"`
+	CreateTime  time.Time `json:"create_time" bson:"create_time"`
+	LastTime    time.Time `json:"last_time" bson:"last_time"`
+	OwnerID     string    `json:"bk_supplier_account" bson:"bk_supplier_account"`
+}
+
+// ChangeFreezeSchedule is the time when the window is active. The window is active all the time between the start
+// and end time, or only in the weekly periods between them if the periods are set.
+type ChangeFreezeSchedule struct {
+	StartTime time.Time            `json:"start_time" bson:"start_time"`
+	EndTime   time.Time            `json:"end_time" bson:"end_time"`
+	Periods   []ChangeFreezePeriod `json:"periods" bson:"periods"`
+	// TimeZone is the IANA time zone of the periods, such as Asia/Shanghai, empty means the local time zone
+	TimeZone string `json:"time_zone" bson:"time_zone"`
+}
+
+// ChangeFreezePeriod is a daily period in the weekdays, the period crosses midnight when the end is not after the
+// begin, such as 22:00 to 06:00, and the part after midnight belongs to the weekday it begins.
+type ChangeFreezePeriod struct {
+	// Weekdays are the days the period begins, 0 is Sunday, empty means every day
+	Weekdays []time.Weekday `json:"weekdays" bson:"weekdays"`
+	// Begin and End are in the format of HH:MM
+	Begin string `json:"begin" bson:"begin"`
+	End   string `json:"end" bson:"end"`
+}
+
+// parseMinute parses HH:MM to the minutes of the day
+func parseMinute(clock string) (int, bool) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, false
+	}
+	return t.Hour()*60 + t.Minute(), true
+}
+
+func (p *ChangeFreezePeriod) hasWeekday(day time.Weekday) bool {
+	if len(p.Weekdays) == 0 {
+		return true
+	}
+	for _, weekday := range p.Weekdays {
+		if weekday == day {
+			return true
+		}
+	}
+	return false
+}
+
+// contains checks if the local time is in the period
+func (p *ChangeFreezePeriod) contains(t time.Time) bool {
+	begin, ok := parseMinute(p.Begin)
+	if !ok {
+		return false
+	}
+	end, ok := parseMinute(p.End)
+	if !ok {
+		return false
+	}
+
+	minute := t.Hour()*60 + t.Minute()
+	if begin < end {
+		return p.hasWeekday(t.Weekday()) && minute >= begin && minute < end
+	}
+	if minute >= begin {
+		return p.hasWeekday(t.Weekday())
+	}
+	return minute < end && p.hasWeekday(t.AddDate(0, 0, -1).Weekday())
+}
+
+// IsActive checks if the time is in the schedule
+func (s *ChangeFreezeSchedule) IsActive(now time.Time) bool {
+	if now.Before(s.StartTime) || !now.Before(s.EndTime) {
+		return false
+	}
+	if len(s.Periods) == 0 {
+		return true
+	}
+
+	loc := time.Local
+	if len(s.TimeZone) != 0 {
+		var err error
+		if loc, err = time.LoadLocation(s.TimeZone); err != nil {
+			// the time zone is validated when saved, freeze the whole time in case it's no longer available
+			return true
+		}
+	}
+	local := now.In(loc)
+	for _, period := range s.Periods {
+		if period.contains(local) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate validate the schedule
+func (s *ChangeFreezeSchedule) Validate() errors.RawErrorInfo {
+	if s.StartTime.IsZero() || s.EndTime.IsZero() || !s.StartTime.Before(s.EndTime) {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsIsInvalid,
+			Args:    []interface{}{"schedule.end_time"},
+		}
+	}
+	if len(s.TimeZone) != 0 {
+		if _, err := time.LoadLocation(s.TimeZone); err != nil {
+			return errors.RawErrorInfo{
+				ErrCode: common.CCErrCommParamsIsInvalid,
+				Args:    []interface{}{"schedule.time_zone"},
+			}
+		}
+	}
+	for _, period := range s.Periods {
+		begin, ok := parseMinute(period.Begin)
+		if !ok {
+			return errors.RawErrorInfo{
+				ErrCode: common.CCErrCommParamsIsInvalid,
+				Args:    []interface{}{"schedule.periods.begin"},
+			}
+		}
+		end, ok := parseMinute(period.End)
+		if !ok || begin == end {
+			return errors.RawErrorInfo{
+				ErrCode: common.CCErrCommParamsIsInvalid,
+				Args:    []interface{}{"schedule.periods.end"},
+			}
+		}
+		for _, weekday := range period.Weekdays {
+			if weekday < time.Sunday || weekday > time.Saturday {
+				return errors.RawErrorInfo{
+					ErrCode: common.CCErrCommParamsIsInvalid,
+					Args:    []interface{}{"schedule.periods.weekdays"},
+				}
+			}
+		}
+	}
+	return errors.RawErrorInfo{}
+}
+
+// Validate validate the change freeze window
+func (w *ChangeFreezeWindow) Validate() errors.RawErrorInfo {
+	if len(w.Name) == 0 {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsNeedSet,
+			Args:    []interface{}{"name"},
+		}
+	}
+	if w.BizID < 0 {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsIsInvalid,
+			Args:    []interface{}{common.BKAppIDField},
+		}
+	}
+	for _, resource := range w.Resources {
+		if !resource.IsValid() {
+			return errors.RawErrorInfo{
+				ErrCode: common.CCErrCommParamsIsInvalid,
+				Args:    []interface{}{"resources"},
+			}
+		}
+	}
+	return w.Schedule.Validate()
+}
+
+// Covers checks if the changes of the resource in the business are frozen by the window, bizID 0 means the
+// resource is not in any business, such as the hosts in the resource pool, which are only frozen by global windows,
+// and ChangeFreezeAnyBizID means the business is unknown, which is frozen by all the windows
+func (w *ChangeFreezeWindow) Covers(bizID int64, resource ChangeFreezeResource) bool {
+	if w.BizID != 0 && bizID != ChangeFreezeAnyBizID && w.BizID != bizID {
+		return false
+	}
+	if len(w.Resources) == 0 {
+		return true
+	}
+	for _, r := range w.Resources {
+		if r == resource {
+			return true
+		}
+	}
+	return false
+}
+
+// IsExempt checks if the user or the app can make changes in the window
+func (w *ChangeFreezeWindow) IsExempt(user, appCode string) bool {
+	for _, exempt := range w.ExemptUsers {
+		if exempt == user {
+			return true
+		}
+	}
+	if len(appCode) == 0 {
+		return false
+	}
+	for _, exempt := range w.ExemptApps {
+		if exempt == appCode {
+			return true
+		}
+	}
+	return false
+}
+
+// SearchChangeFreezeWindowOption search change freeze windows
+type SearchChangeFreezeWindowOption struct {
+	Condition mapstr.MapStr `json:"condition"`
+	Page      BasePage      `json:"page"`
+}
+
+// SearchChangeFreezeWindowResult search change freeze windows result
+type SearchChangeFreezeWindowResult struct {
+	Count int64                `json:"count"`
+	Info  []ChangeFreezeWindow `json:"info"`
+}
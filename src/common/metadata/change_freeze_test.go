@@ -0,0 +1,119 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"testing"
+	"time"
+)
+
+func TestChangeFreezeScheduleIsActive(t *testing.T) {
+	schedule := ChangeFreezeSchedule{
+		StartTime: time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC),
+		EndTime:   time.Date(2020, 12, 31, 0, 0, 0, 0, time.UTC),
+	}
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2020, 12, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	if !schedule.IsActive(at(7, 12, 0)) {
+		t.Errorf("the whole time between start and end should be active")
+	}
+	if schedule.IsActive(at(31, 0, 0)) || schedule.IsActive(at(1, 0, 0).Add(-time.Minute)) {
+		t.Errorf("the time out of start and end should not be active")
+	}
+
+	// 2020-12-04 is Friday, freeze from Friday 22:00 to Saturday 06:00
+	schedule.TimeZone = "UTC"
+	schedule.Periods = []ChangeFreezePeriod{{Weekdays: []time.Weekday{time.Friday}, Begin: "22:00", End: "06:00"}}
+	cases := []struct {
+		time   time.Time
+		active bool
+	}{
+		{at(4, 21, 59), false},
+		{at(4, 22, 0), true},
+		{at(5, 5, 59), true},
+		{at(5, 6, 0), false},
+		{at(5, 22, 30), false},
+		{at(6, 1, 0), false},
+		// the time zone of the time does not matter
+		{at(4, 23, 0).In(time.FixedZone("UTC+8", 8*60*60)), true},
+	}
+	for _, c := range cases {
+		if got := schedule.IsActive(c.time); got != c.active {
+			t.Errorf("IsActive(%s) = %v, want %v", c.time, got, c.active)
+		}
+	}
+}
+
+func TestChangeFreezeWindowCovers(t *testing.T) {
+	global := ChangeFreezeWindow{}
+	if !global.Covers(0, ChangeFreezeHost) || !global.Covers(3, ChangeFreezeProcess) {
+		t.Errorf("global window without resources should cover all the resources")
+	}
+
+	window := ChangeFreezeWindow{BizID: 3, Resources: []ChangeFreezeResource{ChangeFreezeTopology}}
+	if !window.Covers(3, ChangeFreezeTopology) {
+		t.Errorf("window should cover the topology of its business")
+	}
+	if window.Covers(4, ChangeFreezeTopology) || window.Covers(0, ChangeFreezeTopology) {
+		t.Errorf("window should not cover the other businesses")
+	}
+	if window.Covers(3, ChangeFreezeHost) {
+		t.Errorf("window should not cover the resources that are not frozen")
+	}
+	if !window.Covers(ChangeFreezeAnyBizID, ChangeFreezeTopology) || window.Covers(ChangeFreezeAnyBizID,
+		ChangeFreezeHost) {
+		t.Errorf("window should cover the frozen resources whose business is unknown")
+	}
+
+	window.ExemptUsers = []string{"admin"}
+	window.ExemptApps = []string{"bk_sops"}
+	if !window.IsExempt("admin", "") || !window.IsExempt("user", "bk_sops") || window.IsExempt("user", "") {
+		t.Errorf("IsExempt does not match the exempt users and apps")
+	}
+}
+
+func TestChangeFreezeWindowValidate(t *testing.T) {
+	window := ChangeFreezeWindow{
+		Name: "release freeze",
+		Schedule: ChangeFreezeSchedule{
+			StartTime: time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC),
+			EndTime:   time.Date(2020, 12, 31, 0, 0, 0, 0, time.UTC),
+			Periods:   []ChangeFreezePeriod{{Begin: "22:00", End: "06:00"}},
+		},
+	}
+	if rawErr := window.Validate(); rawErr.ErrCode != 0 {
+		t.Errorf("valid window is rejected, err: %+v", rawErr)
+	}
+
+	invalid := []func(w *ChangeFreezeWindow){
+		func(w *ChangeFreezeWindow) { w.Name = "" },
+		func(w *ChangeFreezeWindow) { w.BizID = -1 },
+		func(w *ChangeFreezeWindow) { w.Resources = []ChangeFreezeResource{"model"} },
+		func(w *ChangeFreezeWindow) { w.Schedule.EndTime = w.Schedule.StartTime },
+		func(w *ChangeFreezeWindow) { w.Schedule.TimeZone = "Not/Exist" },
+		func(w *ChangeFreezeWindow) { w.Schedule.Periods = []ChangeFreezePeriod{{Begin: "25:00", End: "06:00"}} },
+		func(w *ChangeFreezeWindow) { w.Schedule.Periods = []ChangeFreezePeriod{{Begin: "06:00", End: "06:00"}} },
+		func(w *ChangeFreezeWindow) {
+			w.Schedule.Periods = []ChangeFreezePeriod{{Weekdays: []time.Weekday{7}, Begin: "06:00", End: "08:00"}}
+		},
+	}
+	for index, modify := range invalid {
+		w := window
+		modify(&w)
+		if rawErr := w.Validate(); rawErr.ErrCode == 0 {
+			t.Errorf("invalid window %d is accepted", index)
+		}
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"configcenter/src/common"
 	"configcenter/src/common/errors"
 	"configcenter/src/common/mapstr"
+	"configcenter/src/common/querybuilder"
 	"configcenter/src/common/util"
 
 	"github.com/coccyx/timeparser"
@@ -105,6 +106,8 @@ type ResponseInstData struct {
 type InstDataInfo struct {
 	Count int             `json:"count"`
 	Info  []mapstr.MapStr `json:"info"`
+	// NextCursor 游标分页时下一页的cursor, 为空表示没有更多数据
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 type ResponseDataMapStr struct {
@@ -119,6 +122,10 @@ type QueryInput struct {
 	Limit          int                    `json:"limit,omitempty"`
 	Sort           string                 `json:"sort,omitempty"`
 	DisableCounter bool                   `json:"disable_counter,omitempty"`
+	// Filter 结构化的过滤条件, 会根据模型字段校验后与condition取交集
+	Filter *querybuilder.QueryFilter `json:"filter,omitempty"`
+	// Cursor 游标分页的续传令牌, 首页传"*", 使用时start需为0
+	Cursor string `json:"cursor,omitempty"`
 }
 
 // ConvTime cc_type key
@@ -259,6 +266,21 @@ type ListHostWithoutAppResponse struct {
 	Data     ListHostResult `json:"data"`
 }
 
+// SearchModuleWithRelationOption search the modules of the business by the sets and the service templates
+type SearchModuleWithRelationOption struct {
+	BkSetIdS             []int64  `json:"bk_set_ids"`
+	BkServiceTemplateIds []int64  `json:"bk_service_template_ids"`
+	Fields               []string `json:"fields"`
+	Page                 BasePage `json:"page"`
+}
+
+// ListModulesByServiceTemplateOption list the modules created by the service template
+type ListModulesByServiceTemplateOption struct {
+	Page    *BasePage `field:"page" json:"page" mapstructure:"page"`
+	Keyword string    `field:"keyword" json:"keyword" mapstructure:"keyword"`
+	Modules []int64   `field:"bk_module_ids" json:"bk_module_ids" mapstructure:"bk_module_ids"`
+}
+
 type SearchInstBatchOption struct {
 	IDs    []int64  `json:"bk_ids"`
 	Fields []string `json:"fields"`
@@ -0,0 +1,322 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CursorFirstPage 游标分页首页的cursor取值
+const CursorFirstPage = "*"
+
+const (
+	cursorTypeNull   = "null"
+	cursorTypeInt    = "int"
+	cursorTypeFloat  = "float"
+	cursorTypeString = "string"
+	cursorTypeBool   = "bool"
+	cursorTypeTime   = "time"
+)
+
+// pageCursor is the decoded content of a continuation token, it records the sort key
+// and the sort value and id of the last record in previous page.
+type pageCursor struct {
+	Sort  string `json:"s"`
+	Type  string `json:"t"`
+	Value string `json:"v,omitempty"`
+	ID    int64  `json:"i"`
+}
+
+// CursorQuery is the keyset condition and the stable sort derived from page cursor
+type CursorQuery struct {
+	// Condition should be combined with the origin query condition by $and, it is nil on the first page
+	Condition map[string]interface{}
+	// Sort is the stable sort used to query db, always ends with the id field
+	Sort string
+
+	field   string
+	idField string
+}
+
+// MergeCondition combine the origin condition with the keyset condition
+func (q *CursorQuery) MergeCondition(cond map[string]interface{}) map[string]interface{} {
+	if q.Condition == nil {
+		return cond
+	}
+	if len(cond) == 0 {
+		return q.Condition
+	}
+	return map[string]interface{}{"$and": []interface{}{cond, q.Condition}}
+}
+
+// Fields make sure the sort field and id field are returned, they are needed to generate next cursor
+func (q *CursorQuery) Fields(fields []string) []string {
+	if len(fields) == 0 {
+		return fields
+	}
+	hasField, hasID := q.field == "", false
+	for _, f := range fields {
+		if f == q.field {
+			hasField = true
+		}
+		if f == q.idField {
+			hasID = true
+		}
+	}
+	result := append(make([]string, 0, len(fields)+2), fields...)
+	if !hasField {
+		result = append(result, q.field)
+	}
+	if !hasID {
+		result = append(result, q.idField)
+	}
+	return result
+}
+
+// IsCursorPage whether this page use cursor(search-after) paging
+func (page BasePage) IsCursorPage() bool {
+	return page.Cursor != ""
+}
+
+// parseCursorSort parse page sort to at most one sort field besides the id field, the id field
+// is used as the tie breaker, so that the order is stable.
+func (page BasePage) parseCursorSort(idField string) (field string, desc bool, err error) {
+	if page.Sort == "" {
+		return "", false, nil
+	}
+
+	items := strings.Split(page.Sort, ",")
+	if len(items) > 2 {
+		return "", false, errors.New("cursor paging supports at most one sort field besides id")
+	}
+
+	idDesc := false
+	hasID := false
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		isDesc := false
+		if strings.HasPrefix(item, "-") {
+			isDesc = true
+			item = item[1:]
+		}
+		if kv := strings.Split(item, ":"); len(kv) > 1 {
+			item = kv[0]
+			isDesc = strings.TrimSpace(kv[1]) == "-1"
+		}
+		if item == "" {
+			return "", false, errors.New("invalid sort")
+		}
+
+		if item == idField {
+			hasID, idDesc = true, isDesc
+			continue
+		}
+		if field != "" {
+			return "", false, errors.New("cursor paging supports at most one sort field besides id")
+		}
+		field, desc = item, isDesc
+	}
+
+	if field == "" {
+		return "", idDesc, nil
+	}
+	if hasID && idDesc != desc {
+		return "", false, errors.New("sort field and id must be sorted in the same direction")
+	}
+	return field, desc, nil
+}
+
+func cursorSortString(field, idField string, desc bool) string {
+	direction := ""
+	if desc {
+		direction = "-"
+	}
+	if field == "" {
+		return direction + idField
+	}
+	return direction + field + "," + direction + idField
+}
+
+// ParseCursor parse page cursor to the keyset condition and the stable sort.
+func (page BasePage) ParseCursor(idField string) (*CursorQuery, error) {
+	field, desc, err := page.parseCursorSort(idField)
+	if err != nil {
+		return nil, err
+	}
+	sort := cursorSortString(field, idField, desc)
+	result := &CursorQuery{Sort: sort, field: field, idField: idField}
+	if page.Cursor == CursorFirstPage {
+		return result, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(page.Cursor)
+	if err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+	c := new(pageCursor)
+	if err := json.Unmarshal(raw, c); err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+	if c.Sort != sort {
+		return nil, errors.New("cursor does not match the sort of this request")
+	}
+
+	idOp, valueOp := "$gt", "$gt"
+	if desc {
+		idOp, valueOp = "$lt", "$lt"
+	}
+
+	if field == "" {
+		result.Condition = map[string]interface{}{idField: map[string]interface{}{idOp: c.ID}}
+		return result, nil
+	}
+
+	if c.Type == cursorTypeNull {
+		// null值在升序时排在最前, 在降序时排在最后
+		sameValue := map[string]interface{}{field: nil, idField: map[string]interface{}{idOp: c.ID}}
+		if desc {
+			result.Condition = sameValue
+			return result, nil
+		}
+		result.Condition = map[string]interface{}{
+			"$or": []interface{}{sameValue, map[string]interface{}{field: map[string]interface{}{"$ne": nil}}},
+		}
+		return result, nil
+	}
+
+	value, err := c.value()
+	if err != nil {
+		return nil, err
+	}
+	or := []interface{}{
+		map[string]interface{}{field: map[string]interface{}{valueOp: value}},
+		map[string]interface{}{field: value, idField: map[string]interface{}{idOp: c.ID}},
+	}
+	if desc {
+		or = append(or, map[string]interface{}{field: nil})
+	}
+	result.Condition = map[string]interface{}{"$or": or}
+	return result, nil
+}
+
+func (c *pageCursor) value() (interface{}, error) {
+	var value interface{}
+	var err error
+	switch c.Type {
+	case cursorTypeInt:
+		value, err = strconv.ParseInt(c.Value, 10, 64)
+	case cursorTypeFloat:
+		value, err = strconv.ParseFloat(c.Value, 64)
+	case cursorTypeString:
+		value = c.Value
+	case cursorTypeBool:
+		value, err = strconv.ParseBool(c.Value)
+	case cursorTypeTime:
+		var t time.Time
+		t, err = time.Parse(time.RFC3339Nano, c.Value)
+		value = t
+	default:
+		err = fmt.Errorf("unsupported cursor type %s", c.Type)
+	}
+	if err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+	return value, nil
+}
+
+// NextCursor generate the continuation token from the last record of this page,
+// returns empty string when there is no more data.
+func (page BasePage) NextCursor(idField string, count int, last map[string]interface{}) (string, error) {
+	if !page.IsCursorPage() || count == 0 || count < page.Limit || last == nil {
+		return "", nil
+	}
+
+	field, desc, err := page.parseCursorSort(idField)
+	if err != nil {
+		return "", err
+	}
+
+	id, err := cursorInt64(last[idField])
+	if err != nil {
+		return "", fmt.Errorf("invalid %s of the last record, err: %v", idField, err)
+	}
+	c := pageCursor{Sort: cursorSortString(field, idField, desc), ID: id}
+	if field == "" {
+		c.Type = cursorTypeInt
+	} else if c.Type, c.Value, err = encodeCursorValue(last[field]); err != nil {
+		return "", fmt.Errorf("sort field %s can not be used by cursor paging, err: %v", field, err)
+	}
+
+	raw, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func cursorInt64(val interface{}) (int64, error) {
+	switch v := val.(type) {
+	case int:
+		return int64(v), nil
+	case int32:
+		return int64(v), nil
+	case int64:
+		return v, nil
+	case uint64:
+		return int64(v), nil
+	case float64:
+		return int64(v), nil
+	case json.Number:
+		return v.Int64()
+	default:
+		return 0, fmt.Errorf("%v is not a number", val)
+	}
+}
+
+func encodeCursorValue(val interface{}) (string, string, error) {
+	switch v := val.(type) {
+	case nil:
+		return cursorTypeNull, "", nil
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return cursorTypeInt, fmt.Sprint(v), nil
+	case float32:
+		return cursorTypeFloat, strconv.FormatFloat(float64(v), 'g', -1, 32), nil
+	case float64:
+		return cursorTypeFloat, strconv.FormatFloat(v, 'g', -1, 64), nil
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return cursorTypeInt, v.String(), nil
+		}
+		return cursorTypeFloat, v.String(), nil
+	case string:
+		return cursorTypeString, v, nil
+	case bool:
+		return cursorTypeBool, strconv.FormatBool(v), nil
+	case time.Time:
+		return cursorTypeTime, v.UTC().Format(time.RFC3339Nano), nil
+	case Time:
+		return cursorTypeTime, v.UTC().Format(time.RFC3339Nano), nil
+	case primitive.DateTime:
+		return cursorTypeTime, time.Unix(0, int64(v)*int64(time.Millisecond)).UTC().Format(time.RFC3339Nano), nil
+	default:
+		return "", "", fmt.Errorf("unsupported value type %T", val)
+	}
+}
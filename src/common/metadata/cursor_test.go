@@ -0,0 +1,79 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCursorPage(t *testing.T) {
+	page := BasePage{Sort: "-create_time", Limit: 2, Cursor: CursorFirstPage}
+	first, err := page.ParseCursor("bk_inst_id")
+	if err != nil {
+		t.Fatalf("parse first page cursor failed, err: %v", err)
+	}
+	if first.Condition != nil || first.Sort != "-create_time,-bk_inst_id" {
+		t.Fatalf("unexpected first page query: %+v", first)
+	}
+
+	createTime := time.Date(2020, 12, 1, 10, 0, 0, 0, time.UTC)
+	last := map[string]interface{}{"bk_inst_id": int64(8), "create_time": createTime}
+	next, err := page.NextCursor("bk_inst_id", 2, last)
+	if err != nil || next == "" {
+		t.Fatalf("generate next cursor failed, cursor: %s, err: %v", next, err)
+	}
+
+	page.Cursor = next
+	query, err := page.ParseCursor("bk_inst_id")
+	if err != nil {
+		t.Fatalf("parse cursor failed, err: %v", err)
+	}
+	want := map[string]interface{}{"$or": []interface{}{
+		map[string]interface{}{"create_time": map[string]interface{}{"$lt": createTime}},
+		map[string]interface{}{"create_time": createTime, "bk_inst_id": map[string]interface{}{"$lt": int64(8)}},
+		map[string]interface{}{"create_time": nil},
+	}}
+	if !reflect.DeepEqual(query.Condition, want) {
+		t.Fatalf("unexpected cursor condition: %v, want: %v", query.Condition, want)
+	}
+
+	// the last page has no next cursor
+	if next, _ := page.NextCursor("bk_inst_id", 1, last); next != "" {
+		t.Fatalf("last page should not have next cursor, got: %s", next)
+	}
+
+	// cursor can not be used with another sort
+	page.Sort = "bk_inst_name"
+	if _, err := page.ParseCursor("bk_inst_id"); err == nil {
+		t.Fatalf("cursor with mismatched sort should be rejected")
+	}
+
+	page.Sort = "a,b"
+	if _, err := page.ParseCursor("bk_inst_id"); err == nil {
+		t.Fatalf("multiple sort fields besides id should be rejected")
+	}
+}
+
+func TestCursorPageValidate(t *testing.T) {
+	if _, err := (BasePage{Limit: 10, Start: 10, Cursor: CursorFirstPage}).Validate(false); err == nil {
+		t.Fatalf("start with cursor should be rejected")
+	}
+	if _, err := (BasePage{Limit: 0, Cursor: CursorFirstPage}).Validate(false); err == nil {
+		t.Fatalf("cursor page without limit should be rejected")
+	}
+	if _, err := (BasePage{Limit: 10, Cursor: CursorFirstPage}).Validate(false); err != nil {
+		t.Fatalf("valid cursor page is rejected, err: %v", err)
+	}
+}
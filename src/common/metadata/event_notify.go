@@ -0,0 +1,306 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/mapstr"
+)
+
+// NotifyChannelType is the channel to deliver the notification message
+type NotifyChannelType string
+
+const (
+	// NotifyChannelEmail send mail by the smtp server configured in event server
+	NotifyChannelEmail NotifyChannelType = "email"
+	// NotifyChannelWebhook post the message and the event to the url in json
+	NotifyChannelWebhook NotifyChannelType = "webhook"
+	// NotifyChannelWeCom post a text message to the WeCom group robot webhook
+	NotifyChannelWeCom NotifyChannelType = "wecom"
+	// NotifyChannelSlack post a text message to the slack incoming webhook
+	NotifyChannelSlack NotifyChannelType = "slack"
+)
+
+// notify rule predicate operators
+const (
+	NotifyOperatorEqual    = "equal"
+	NotifyOperatorNotEqual = "not_equal"
+	NotifyOperatorIn       = "in"
+	NotifyOperatorNotIn    = "not_in"
+	NotifyOperatorContains = "contains"
+	// NotifyOperatorChanged the field is in the update fields of the update event
+	NotifyOperatorChanged = "changed"
+)
+
+// NotifyDeliveryStatus is the result of a notification delivery
+type NotifyDeliveryStatus string
+
+const (
+	NotifyDeliverySuccess NotifyDeliveryStatus = "success"
+	NotifyDeliveryFailed  NotifyDeliveryStatus = "failed"
+	// NotifyDeliveryLimited the notification is dropped by the rate limit of the rule, only the first
+	// dropped notification in each rate limit window is recorded.
+	NotifyDeliveryLimited NotifyDeliveryStatus = "limited"
+)
+
+// NotifyPredicate is a condition on the event data, the data is the current data of the resource,
+// or the data before deleted for the delete event.
+type NotifyPredicate struct {
+	Field    string      `json:"field" bson:"field"`
+	Operator string      `json:"operator" bson:"operator"`
+	Value    interface{} `json:"value" bson:"value"`
+}
+
+// NotifyChannel is where the notification is delivered
+type NotifyChannel struct {
+	Type NotifyChannelType `json:"type" bson:"type"`
+	// Receivers are the mail addresses for email channel, or the users to mention for chat channels
+	Receivers []string `json:"receivers" bson:"receivers"`
+	// ReceiverFields are the fields of the event data whose values are added to receivers,
+	// such as operator of host, multiple values in a field are separated by comma.
+	ReceiverFields []string `json:"receiver_fields" bson:"receiver_fields"`
+	// URL is the webhook url for webhook, wecom and slack channel
+	URL string `json:"url" bson:"url"`
+}
+
+// NotifyTemplate is the go text/template of the notification, empty means the default template
+type NotifyTemplate struct {
+	Title   string `json:"title" bson:"title"`
+	Content string `json:"content" bson:"content"`
+}
+
+// NotifyRateLimit limits the notifications of a rule in a time window
+type NotifyRateLimit struct {
+	// Count is the max number of notifications in a window, 0 means no limit
+	Count int64 `json:"count" bson:"count"`
+	// IntervalSeconds is the length of the window, default is 60 seconds
+	IntervalSeconds int64 `json:"interval_seconds" bson:"interval_seconds"`
+}
+
+// NotifyRule sends notification to the channel when the event of the resource matches the rule
+type NotifyRule struct {
+	ID   int64  `json:"id" bson:"id"`
+	Name string `json:"name" bson:"name"`
+	// Resource is the obj_type of the event, such as host, set, module, biz, moduletransfer or custom object id
+	Resource string `json:"resource" bson:"resource"`
+	// Actions are the event actions of create, update and delete, empty means all actions
+	Actions []string `json:"actions" bson:"actions"`
+	// BizID is the business scope of the rule, 0 means all businesses
+	BizID      int64             `json:"bk_biz_id" bson:"bk_biz_id"`
+	Predicates []NotifyPredicate `json:"predicates" bson:"predicates"`
+	Template   NotifyTemplate    `json:"template" bson:"template"`
+	Channel    NotifyChannel     `json:"channel" bson:"channel"`
+	RateLimit  NotifyRateLimit   `json:"rate_limit" bson:"rate_limit"`
+	Enabled    bool              `json:"enabled" bson:"enabled"`
+	Creator    string            `json:"creator" bson:"creator"`
+	Modifier   string            `json:"modifier" bson:"modifier"`
+	CreateTime time.Time         `json:"create_time" bson:"create_time"`
+	LastTime   time.Time         `json:"last_time" bson:"last_time"`
+	OwnerID    string            `json:"bk_supplier_account" bson:"bk_supplier_account"`
+}
+
+// GetRateLimitInterval returns the rate limit window of the rule
+func (r *NotifyRule) GetRateLimitInterval() time.Duration {
+	if r.RateLimit.IntervalSeconds <= 0 {
+		return time.Minute
+	}
+	return time.Duration(r.RateLimit.IntervalSeconds) * time.Second
+}
+
+func invalidNotifyRuleField(field string) errors.RawErrorInfo {
+	return errors.RawErrorInfo{
+		ErrCode: common.CCErrCommParamsIsInvalid,
+		Args:    []interface{}{field},
+	}
+}
+
+// Validate validate the notify rule, the templates are validated by event server with the template functions
+func (r *NotifyRule) Validate() errors.RawErrorInfo {
+	if len(r.Name) == 0 {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsNeedSet,
+			Args:    []interface{}{"name"},
+		}
+	}
+	if len(r.Resource) == 0 {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsNeedSet,
+			Args:    []interface{}{"resource"},
+		}
+	}
+	for _, action := range r.Actions {
+		if action != EventActionCreate && action != EventActionUpdate && action != EventActionDelete {
+			return invalidNotifyRuleField("actions")
+		}
+	}
+	if r.BizID < 0 {
+		return invalidNotifyRuleField(common.BKAppIDField)
+	}
+
+	for _, predicate := range r.Predicates {
+		if len(predicate.Field) == 0 {
+			return invalidNotifyRuleField("predicates.field")
+		}
+		switch predicate.Operator {
+		case NotifyOperatorEqual, NotifyOperatorNotEqual, NotifyOperatorContains, NotifyOperatorChanged:
+		case NotifyOperatorIn, NotifyOperatorNotIn:
+			if _, ok := predicate.Value.([]interface{}); !ok {
+				return invalidNotifyRuleField("predicates.value")
+			}
+		default:
+			return invalidNotifyRuleField("predicates.operator")
+		}
+	}
+
+	switch r.Channel.Type {
+	case NotifyChannelEmail:
+		if len(r.Channel.Receivers) == 0 && len(r.Channel.ReceiverFields) == 0 {
+			return errors.RawErrorInfo{
+				ErrCode: common.CCErrCommParamsNeedSet,
+				Args:    []interface{}{"channel.receivers"},
+			}
+		}
+	case NotifyChannelWebhook, NotifyChannelWeCom, NotifyChannelSlack:
+		u, err := url.Parse(r.Channel.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+			return invalidNotifyRuleField("channel.url")
+		}
+	default:
+		return invalidNotifyRuleField("channel.type")
+	}
+
+	if r.RateLimit.Count < 0 || r.RateLimit.IntervalSeconds < 0 {
+		return invalidNotifyRuleField("rate_limit")
+	}
+	return errors.RawErrorInfo{}
+}
+
+// redactedSecret replaces the secrets in the responses
+const redactedSecret = "***"
+
+// urlRegexp matches the urls in the error messages
+var urlRegexp = regexp.MustCompile(`https?://[^\s"'<>]+`)
+
+// RedactURL masks the secrets of the webhook url, only the scheme, host and the first path element are
+// reserved, the user info, the rest path elements and the query values are masked.
+func RedactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || len(u.Host) == 0 {
+		return redactedSecret
+	}
+
+	redacted := url.URL{Scheme: u.Scheme, Host: u.Host}
+	if u.User != nil {
+		redacted.User = url.User(redactedSecret)
+	}
+
+	elements := strings.SplitN(strings.TrimPrefix(u.Path, "/"), "/", 2)
+	if len(elements[0]) != 0 {
+		redacted.Path = "/" + elements[0]
+	}
+	if len(elements) > 1 {
+		redacted.Path += "/" + redactedSecret
+	}
+
+	if len(u.RawQuery) != 0 {
+		query := u.Query()
+		for key := range query {
+			query.Set(key, redactedSecret)
+		}
+		redacted.RawQuery = query.Encode()
+	}
+	// keep the masks readable instead of escaped
+	return strings.Replace(redacted.String(), url.QueryEscape(redactedSecret), redactedSecret, -1)
+}
+
+// RedactURLs masks the secrets of all the urls in the text, such as the error message of a delivery.
+func RedactURLs(text string) string {
+	return urlRegexp.ReplaceAllStringFunc(text, RedactURL)
+}
+
+// Redact masks the secrets of the notify rule before it's returned.
+func (r *NotifyRule) Redact() {
+	if len(r.Channel.URL) != 0 {
+		r.Channel.URL = RedactURL(r.Channel.URL)
+	}
+}
+
+// NotifyDeliveryLog records a delivery of the notify rule
+type NotifyDeliveryLog struct {
+	ID        int64                `json:"id" bson:"id"`
+	RuleID    int64                `json:"rule_id" bson:"rule_id"`
+	RuleName  string               `json:"rule_name" bson:"rule_name"`
+	EventID   int64                `json:"event_id" bson:"event_id"`
+	Resource  string               `json:"resource" bson:"resource"`
+	Action    string               `json:"action" bson:"action"`
+	Channel   NotifyChannelType    `json:"channel" bson:"channel"`
+	Receivers []string             `json:"receivers" bson:"receivers"`
+	Title     string               `json:"title" bson:"title"`
+	Content   string               `json:"content" bson:"content"`
+	Status    NotifyDeliveryStatus `json:"status" bson:"status"`
+	// Message is the error message of the failed delivery
+	Message    string    `json:"message" bson:"message"`
+	CreateTime time.Time `json:"create_time" bson:"create_time"`
+	OwnerID    string    `json:"bk_supplier_account" bson:"bk_supplier_account"`
+}
+
+// SearchNotifyRuleOption search notify rules
+type SearchNotifyRuleOption struct {
+	Condition mapstr.MapStr `json:"condition"`
+	Page      BasePage      `json:"page"`
+}
+
+// SearchNotifyRuleResult search notify rules result
+type SearchNotifyRuleResult struct {
+	Count int64        `json:"count"`
+	Info  []NotifyRule `json:"info"`
+}
+
+// SearchNotifyDeliveryLogOption search the delivery logs, the newest logs are returned first by default
+type SearchNotifyDeliveryLogOption struct {
+	RuleID    int64                `json:"rule_id"`
+	Status    NotifyDeliveryStatus `json:"status"`
+	StartTime *time.Time           `json:"start_time"`
+	EndTime   *time.Time           `json:"end_time"`
+	Page      BasePage             `json:"page"`
+}
+
+// SearchNotifyDeliveryLogResult search the delivery logs result
+type SearchNotifyDeliveryLogResult struct {
+	Count int64               `json:"count"`
+	Info  []NotifyDeliveryLog `json:"info"`
+}
+
+// TestNotifyRuleOption match and render the rule with the sample event, and deliver it if not dry run,
+// the rule does not need to be saved, so that it can be tested before created.
+type TestNotifyRuleOption struct {
+	Rule   NotifyRule `json:"rule"`
+	Event  EventInst  `json:"event"`
+	DryRun bool       `json:"dry_run"`
+}
+
+// TestNotifyRuleResult is the result of testing notify rule
+type TestNotifyRuleResult struct {
+	Matched   bool     `json:"matched"`
+	Title     string   `json:"title"`
+	Content   string   `json:"content"`
+	Receivers []string `json:"receivers"`
+	// Error is the error message of the delivery
+	Error string `json:"error,omitempty"`
+}
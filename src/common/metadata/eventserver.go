@@ -20,6 +20,7 @@ import (
 	"strings"
 	"time"
 
+	"configcenter/src/common/mapstr"
 	"configcenter/src/common/watch"
 )
 
@@ -247,3 +248,25 @@ func ParseCursorTypeFromEventType(eventType string) watch.CursorType {
 		return watch.ObjectBase
 	}
 }
+
+// WatchEventResult is the decodable result of the watch event api, the detail of the event is
+// the resource data with the watched fields.
+type WatchEventResult struct {
+	// Watched events or not
+	Watched bool              `json:"bk_watched"`
+	Events  []*WatchEventItem `json:"bk_events"`
+}
+
+// WatchEventItem is an event of the watch event api result
+type WatchEventItem struct {
+	Cursor    string           `json:"bk_cursor"`
+	Resource  watch.CursorType `json:"bk_resource"`
+	EventType watch.EventType  `json:"bk_event_type"`
+	Detail    mapstr.MapStr    `json:"bk_detail"`
+}
+
+// WatchEventResponse is the response of the watch event api
+type WatchEventResponse struct {
+	BaseResp `json:",inline"`
+	Data     WatchEventResult `json:"data"`
+}
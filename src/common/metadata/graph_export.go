@@ -0,0 +1,255 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	// GraphFormatDOT Graphviz DOT language
+	GraphFormatDOT = "dot"
+	// GraphFormatGraphML GraphML xml format, can be imported by Gephi, yEd etc.
+	GraphFormatGraphML = "graphml"
+	// GraphFormatJSON json node/edge format
+	GraphFormatJSON = "json"
+)
+
+// GraphNode node of the exported graph
+type GraphNode struct {
+	ID         string            `json:"id"`
+	Label      string            `json:"label"`
+	Type       string            `json:"type"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// GraphEdge edge of the exported graph
+type GraphEdge struct {
+	ID         string            `json:"id"`
+	Source     string            `json:"source"`
+	Target     string            `json:"target"`
+	Label      string            `json:"label"`
+	Type       string            `json:"type"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// Graph the exported graph, it's a directed graph
+type Graph struct {
+	Name  string      `json:"name"`
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+// GraphExportResult the content of dot and graphml format, or the graph of json format
+type GraphExportResult struct {
+	Format  string `json:"format"`
+	Content string `json:"content,omitempty"`
+	Graph   *Graph `json:"graph,omitempty"`
+}
+
+// ValidateGraphFormat validate the export format, json is used by default
+func ValidateGraphFormat(format string) (string, error) {
+	switch format {
+	case "":
+		return GraphFormatJSON, nil
+	case GraphFormatDOT, GraphFormatGraphML, GraphFormatJSON:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unsupported graph format %s, must be one of %s, %s, %s", format, GraphFormatDOT,
+			GraphFormatGraphML, GraphFormatJSON)
+	}
+}
+
+// Export encode the graph by the format
+func (g *Graph) Export(format string) (*GraphExportResult, error) {
+	result := &GraphExportResult{Format: format}
+	switch format {
+	case GraphFormatJSON:
+		result.Graph = g
+	case GraphFormatDOT:
+		result.Content = g.DOT()
+	case GraphFormatGraphML:
+		content, err := g.GraphML()
+		if err != nil {
+			return nil, err
+		}
+		result.Content = content
+	default:
+		return nil, fmt.Errorf("unsupported graph format %s", format)
+	}
+	return result, nil
+}
+
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", "")
+
+func dotQuote(s string) string {
+	return `"` + dotEscaper.Replace(s) + `"`
+}
+
+func dotAttributes(label, typ string, attributes map[string]string) string {
+	items := []string{"label=" + dotQuote(label), "type=" + dotQuote(typ)}
+	for _, key := range sortedGraphAttributeKeys(attributes) {
+		items = append(items, dotQuote(key)+"="+dotQuote(attributes[key]))
+	}
+	return "[" + strings.Join(items, ", ") + "]"
+}
+
+// DOT encode the graph in Graphviz DOT language
+func (g *Graph) DOT() string {
+	buf := bytes.Buffer{}
+	buf.WriteString("digraph " + dotQuote(g.Name) + " {\n")
+	for _, node := range g.Nodes {
+		buf.WriteString("  " + dotQuote(node.ID) + " " + dotAttributes(node.Label, node.Type, node.Attributes) + ";\n")
+	}
+	for _, edge := range g.Edges {
+		buf.WriteString("  " + dotQuote(edge.Source) + " -> " + dotQuote(edge.Target) + " " +
+			dotAttributes(edge.Label, edge.Type, edge.Attributes) + ";\n")
+	}
+	buf.WriteString("}\n")
+	return buf.String()
+}
+
+type graphMLDocument struct {
+	XMLName xml.Name       `xml:"graphml"`
+	XMLNS   string         `xml:"xmlns,attr"`
+	Keys    []graphMLKey   `xml:"key"`
+	Graph   graphMLGraphEl `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID       string `xml:"id,attr"`
+	For      string `xml:"for,attr"`
+	AttrName string `xml:"attr.name,attr"`
+	AttrType string `xml:"attr.type,attr"`
+}
+
+type graphMLGraphEl struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	ID     string        `xml:"id,attr"`
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+// GraphML encode the graph in GraphML format
+func (g *Graph) GraphML() (string, error) {
+	nodeAttrs, edgeAttrs := make(map[string]string), make(map[string]string)
+	for _, node := range g.Nodes {
+		for key := range node.Attributes {
+			nodeAttrs[key] = ""
+		}
+	}
+	for _, edge := range g.Edges {
+		for key := range edge.Attributes {
+			edgeAttrs[key] = ""
+		}
+	}
+
+	doc := graphMLDocument{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{ID: "n_label", For: "node", AttrName: "label", AttrType: "string"},
+			{ID: "n_type", For: "node", AttrName: "type", AttrType: "string"},
+			{ID: "e_label", For: "edge", AttrName: "label", AttrType: "string"},
+			{ID: "e_type", For: "edge", AttrName: "type", AttrType: "string"},
+		},
+		Graph: graphMLGraphEl{ID: g.Name, EdgeDefault: "directed"},
+	}
+	nodeKeys, edgeKeys := sortedGraphAttributeKeys(nodeAttrs), sortedGraphAttributeKeys(edgeAttrs)
+	for _, key := range nodeKeys {
+		doc.Keys = append(doc.Keys, graphMLKey{ID: "n_" + key, For: "node", AttrName: key, AttrType: "string"})
+	}
+	for _, key := range edgeKeys {
+		doc.Keys = append(doc.Keys, graphMLKey{ID: "e_" + key, For: "edge", AttrName: key, AttrType: "string"})
+	}
+
+	for _, node := range g.Nodes {
+		el := graphMLNode{ID: node.ID, Data: []graphMLData{{"n_label", node.Label}, {"n_type", node.Type}}}
+		for _, key := range nodeKeys {
+			if value, exist := node.Attributes[key]; exist {
+				el.Data = append(el.Data, graphMLData{"n_" + key, value})
+			}
+		}
+		doc.Graph.Nodes = append(doc.Graph.Nodes, el)
+	}
+	for _, edge := range g.Edges {
+		el := graphMLEdge{ID: edge.ID, Source: edge.Source, Target: edge.Target,
+			Data: []graphMLData{{"e_label", edge.Label}, {"e_type", edge.Type}}}
+		for _, key := range edgeKeys {
+			if value, exist := edge.Attributes[key]; exist {
+				el.Data = append(el.Data, graphMLData{"e_" + key, value})
+			}
+		}
+		doc.Graph.Edges = append(doc.Graph.Edges, el)
+	}
+
+	content, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return xml.Header + string(content) + "\n", nil
+}
+
+func sortedGraphAttributeKeys(attributes map[string]string) []string {
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ExportModelGraphRequest export the model association graph
+type ExportModelGraphRequest struct {
+	Format string `json:"format"`
+	// ClassificationIDs only export the objects of these classifications if set
+	ClassificationIDs []string `json:"bk_classification_ids"`
+	// AssociationKindIDs only export the associations of these kinds if set, bk_mainline is the mainline topology
+	AssociationKindIDs []string `json:"bk_asst_ids"`
+}
+
+// ExportBizTopoGraphRequest export the mainline instance topology of a business
+type ExportBizTopoGraphRequest struct {
+	Format string `json:"format"`
+}
+
+// ExportInstGraphRequest export the instance association subgraph around an instance
+type ExportInstGraphRequest struct {
+	Format                            string `json:"format"`
+	SearchInstAssociationGraphRequest `json:",inline"`
+}
+
+type GraphExportResponse struct {
+	BaseResp `json:",inline"`
+	Data     GraphExportResult `json:"data"`
+}
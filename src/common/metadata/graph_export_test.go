@@ -0,0 +1,72 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func testGraph() *Graph {
+	return &Graph{
+		Name: "model",
+		Nodes: []GraphNode{
+			{ID: "host", Label: `主机 "host"`, Type: "object", Attributes: map[string]string{"ispre": "true"}},
+			{ID: "switch", Label: "switch", Type: "object"},
+		},
+		Edges: []GraphEdge{
+			{ID: "switch_connect_host", Source: "switch", Target: "host", Label: "connect", Type: "connect"},
+		},
+	}
+}
+
+func TestGraphDOT(t *testing.T) {
+	dot := testGraph().DOT()
+	for _, want := range []string{
+		`digraph "model" {`,
+		`"host" [label="主机 \"host\"", type="object", "ispre"="true"];`,
+		`"switch" -> "host" [label="connect", type="connect"];`,
+	} {
+		if !strings.Contains(dot, want) {
+			t.Fatalf("dot content does not contain %s:\n%s", want, dot)
+		}
+	}
+}
+
+func TestGraphML(t *testing.T) {
+	content, err := testGraph().GraphML()
+	if err != nil {
+		t.Fatalf("encode graphml failed, err: %v", err)
+	}
+
+	doc := new(graphMLDocument)
+	if err := xml.Unmarshal([]byte(content), doc); err != nil {
+		t.Fatalf("graphml is not valid xml, err: %v", err)
+	}
+	if len(doc.Graph.Nodes) != 2 || len(doc.Graph.Edges) != 1 || len(doc.Keys) != 5 {
+		t.Fatalf("unexpected graphml: %s", content)
+	}
+	if doc.Graph.Edges[0].Source != "switch" || doc.Graph.Edges[0].Target != "host" {
+		t.Fatalf("unexpected graphml edge: %+v", doc.Graph.Edges[0])
+	}
+}
+
+func TestGraphExportFormat(t *testing.T) {
+	if format, err := ValidateGraphFormat(""); err != nil || format != GraphFormatJSON {
+		t.Fatalf("json should be the default format, got: %s, err: %v", format, err)
+	}
+	if _, err := ValidateGraphFormat("svg"); err == nil {
+		t.Fatalf("unsupported format should be rejected")
+	}
+}
@@ -44,6 +44,11 @@ type TopoGraphics struct {
 	Assts           []GraphAsst            `json:"assts,omitempty"`
 }
 
+// UpdateTopoGraphicsOption update the positions of the nodes in the topo graphics
+type UpdateTopoGraphicsOption struct {
+	Data []TopoGraphics `json:"data" field:"data"`
+}
+
 type UpdateTopoGraphicsInput struct {
 	Origin []TopoGraphics `field:"origin" json:"origin" bson:"origin"`
 }
@@ -25,6 +25,7 @@ const (
 	GroupFieldSupplierAccount = "bk_supplier_account"
 	GroupFieldIsDefault       = "bk_isdefault"
 	GroupFieldIsPre           = "ispre"
+	GroupFieldIsCollapse      = "is_collapse"
 )
 
 // PropertyGroupObjectAtt uset to update or delete the property group object attribute
@@ -40,6 +41,12 @@ type PropertyGroupObjectAtt struct {
 	} `json:"data"`
 }
 
+// UpdatePropertyGroupObjectAttOption move the attributes of the model to the property groups
+type UpdatePropertyGroupObjectAttOption struct {
+	Data       []PropertyGroupObjectAtt `json:"data" field:"json"`
+	ModelBizID int64                    `json:"bk_biz_id"`
+}
+
 // Group group metadata definition
 type Group struct {
 	BizID      int64  `field:"bk_biz_id" json:"bk_biz_id" bson:"bk_biz_id"`
@@ -17,6 +17,7 @@ import (
 	"strings"
 	"time"
 
+	"configcenter/src/common"
 	"configcenter/src/common/errors"
 	"configcenter/src/common/json"
 	"configcenter/src/common/querybuilder"
@@ -33,6 +34,11 @@ type HostApplyRule struct {
 	ID       int64 `field:"id" json:"id" bson:"id" mapstructure:"id"`
 	BizID    int64 `field:"bk_biz_id" json:"bk_biz_id" bson:"bk_biz_id" mapstructure:"bk_biz_id"`
 	ModuleID int64 `field:"bk_module_id" json:"bk_module_id" bson:"bk_module_id" mapstructure:"bk_module_id"`
+	// ObjID, InstID 规则绑定的拓扑节点, 可以是业务、自定义层级、集群或模块, 绑定到模块时 InstID 与 ModuleID 相同
+	ObjID  string `field:"bk_obj_id" json:"bk_obj_id" bson:"bk_obj_id" mapstructure:"bk_obj_id"`
+	InstID int64  `field:"bk_inst_id" json:"bk_inst_id" bson:"bk_inst_id" mapstructure:"bk_inst_id"`
+	// Priority 规则优先级, 值越大优先级越高, 优先级相同时离模块越近的拓扑节点上的规则生效
+	Priority int64 `field:"priority" json:"priority" bson:"priority" mapstructure:"priority"`
 	// `id` field of table: `cc_AsstDes`, not the same with bk_property_id
 	AttributeID   int64       `field:"bk_attribute_id" json:"bk_attribute_id" bson:"bk_attribute_id" mapstructure:"bk_attribute_id"`
 	PropertyValue interface{} `field:"bk_property_value" json:"bk_property_value" bson:"bk_property_value" mapstructure:"bk_property_value"`
@@ -46,28 +52,103 @@ type HostApplyRule struct {
 }
 
 func (h *HostApplyRule) Validate() (string, error) {
+	h.ObjID, h.InstID = NewHostApplyScope(h.ObjID, h.InstID, h.ModuleID).Values()
+	if h.ObjID == common.BKInnerObjIDModule {
+		h.ModuleID = h.InstID
+	} else {
+		h.ModuleID = 0
+	}
+	if h.InstID <= 0 {
+		return common.BKInstIDField, fmt.Errorf("invalid topo node instance id: %d", h.InstID)
+	}
+	if h.Priority < 0 {
+		return common.HostApplyRulePriorityField, fmt.Errorf("priority should not be negative")
+	}
 	return "", nil
 }
 
+// Scope returns the topo node the rule is bound to, rules created before scope is supported are bound to module
+func (h HostApplyRule) Scope() HostApplyScope {
+	return NewHostApplyScope(h.ObjID, h.InstID, h.ModuleID)
+}
+
+// HostApplyScope 主机属性自动应用规则可以绑定的拓扑节点
+type HostApplyScope struct {
+	ObjID  string `field:"bk_obj_id" json:"bk_obj_id" bson:"bk_obj_id" mapstructure:"bk_obj_id"`
+	InstID int64  `field:"bk_inst_id" json:"bk_inst_id" bson:"bk_inst_id" mapstructure:"bk_inst_id"`
+}
+
+// NewHostApplyScope returns the scope of the rule, moduleID is used when objID is not specified.
+func NewHostApplyScope(objID string, instID int64, moduleID int64) HostApplyScope {
+	if len(objID) == 0 {
+		return HostApplyScope{ObjID: common.BKInnerObjIDModule, InstID: moduleID}
+	}
+	if objID == common.BKInnerObjIDModule && instID == 0 {
+		return HostApplyScope{ObjID: common.BKInnerObjIDModule, InstID: moduleID}
+	}
+	return HostApplyScope{ObjID: objID, InstID: instID}
+}
+
+func (s HostApplyScope) Values() (string, int64) {
+	return s.ObjID, s.InstID
+}
+
+// HostApplyConflictPolicy 主机属于多个模块且各模块上生效的规则值不同时的冲突处理方式
+type HostApplyConflictPolicy string
+
+const (
+	// HostApplyConflictPolicyManual 冲突需要通过 conflict_resolvers 手动指定, 未指定时字段不更新, 默认方式
+	HostApplyConflictPolicyManual HostApplyConflictPolicy = "manual"
+	// HostApplyConflictPolicyPriority 按规则优先级、拓扑节点距离、模块ID的顺序选出生效的规则
+	HostApplyConflictPolicyPriority HostApplyConflictPolicy = "priority"
+)
+
+// HostApplyReason 说明计划中某个字段的值是如何得到的
+type HostApplyReason string
+
+const (
+	// HostApplyReasonRule 主机所在模块上生效的规则值一致
+	HostApplyReasonRule HostApplyReason = "rule"
+	// HostApplyReasonResolver 规则冲突, 使用了用户指定的值
+	HostApplyReasonResolver HostApplyReason = "conflict_resolver"
+	// HostApplyReasonPriority 规则冲突, 按冲突处理策略选出了优先级最高的规则
+	HostApplyReasonPriority HostApplyReason = "priority"
+)
+
 type CreateHostApplyRuleOption struct {
 	AttributeID   int64       `field:"bk_attribute_id" json:"bk_attribute_id" bson:"bk_attribute_id" mapstructure:"bk_attribute_id"`
 	ModuleID      int64       `field:"bk_module_id" json:"bk_module_id" bson:"bk_module_id" mapstructure:"bk_module_id"`
 	PropertyValue interface{} `field:"bk_property_value" json:"bk_property_value" bson:"bk_property_value" mapstructure:"bk_property_value"`
+	ObjID         string      `field:"bk_obj_id" json:"bk_obj_id" bson:"bk_obj_id" mapstructure:"bk_obj_id"`
+	InstID        int64       `field:"bk_inst_id" json:"bk_inst_id" bson:"bk_inst_id" mapstructure:"bk_inst_id"`
+	Priority      int64       `field:"priority" json:"priority" bson:"priority" mapstructure:"priority"`
+}
+
+func (o CreateHostApplyRuleOption) Scope() HostApplyScope {
+	return NewHostApplyScope(o.ObjID, o.InstID, o.ModuleID)
 }
 
 type UpdateHostApplyRuleOption struct {
 	PropertyValue interface{} `field:"bk_property_value" json:"bk_property_value" bson:"bk_property_value" mapstructure:"bk_property_value"`
+	// Priority 为空时不修改规则优先级
+	Priority *int64 `field:"priority" json:"priority,omitempty" bson:"priority" mapstructure:"priority"`
 }
 
 type MultipleHostApplyRuleResult struct {
 	Count int64           `json:"count" mapstructure:"count"`
 	Info  []HostApplyRule `json:"info" mapstructure:"info"`
+	// ModuleScopes 模块到业务的拓扑路径(含模块自身), 仅在 with_inherited 为true时返回
+	ModuleScopes map[int64][]HostApplyScope `json:"module_scopes,omitempty" mapstructure:"module_scopes"`
 }
 
 type ListHostApplyRuleOption struct {
-	ModuleIDs    []int64  `field:"bk_module_ids" json:"bk_module_ids" bson:"bk_module_ids" mapstructure:"bk_module_ids"`
-	AttributeIDs []int64  `field:"bk_attribute_ids" json:"bk_attribute_ids" bson:"bk_attribute_ids" mapstructure:"bk_attribute_ids"`
-	Page         BasePage `field:"page" json:"page" bson:"page" mapstructure:"page"`
+	ModuleIDs []int64 `field:"bk_module_ids" json:"bk_module_ids" bson:"bk_module_ids" mapstructure:"bk_module_ids"`
+	// Scopes 按规则绑定的拓扑节点查询, 与 ModuleIDs 同时指定时返回两者的并集
+	Scopes []HostApplyScope `field:"scopes" json:"scopes" bson:"scopes" mapstructure:"scopes"`
+	// WithInherited 为true时同时返回 ModuleIDs 所在集群、自定义层级和业务上的规则
+	WithInherited bool     `field:"with_inherited" json:"with_inherited" bson:"with_inherited" mapstructure:"with_inherited"`
+	AttributeIDs  []int64  `field:"bk_attribute_ids" json:"bk_attribute_ids" bson:"bk_attribute_ids" mapstructure:"bk_attribute_ids"`
+	Page          BasePage `field:"page" json:"page" bson:"page" mapstructure:"page"`
 }
 
 type ListHostRelatedApplyRuleOption struct {
@@ -91,6 +172,13 @@ type CreateOrUpdateApplyRuleOption struct {
 	AttributeID   int64       `field:"bk_attribute_id" json:"bk_attribute_id" bson:"bk_attribute_id" mapstructure:"bk_attribute_id"`
 	ModuleID      int64       `field:"bk_module_id" json:"bk_module_id" bson:"bk_module_id" mapstructure:"bk_module_id"`
 	PropertyValue interface{} `field:"bk_property_value" json:"bk_property_value" bson:"bk_property_value" mapstructure:"bk_property_value"`
+	ObjID         string      `field:"bk_obj_id" json:"bk_obj_id" bson:"bk_obj_id" mapstructure:"bk_obj_id"`
+	InstID        int64       `field:"bk_inst_id" json:"bk_inst_id" bson:"bk_inst_id" mapstructure:"bk_inst_id"`
+	Priority      int64       `field:"priority" json:"priority" bson:"priority" mapstructure:"priority"`
+}
+
+func (o CreateOrUpdateApplyRuleOption) Scope() HostApplyScope {
+	return NewHostApplyScope(o.ObjID, o.InstID, o.ModuleID)
 }
 
 type BatchCreateOrUpdateHostApplyRuleResult struct {
@@ -124,10 +212,13 @@ type Host2Modules struct {
 // - Rules: 主机属性应用规则，由于上述case2的存在，其中 ID 可能为0
 // - HostModules: 主机所有模块信息，case3的存在，导致不能直接从db中查询主机所属模块
 // - ConflictResolvers: 可选参数，用于表示主机属性应用出现冲突时，如何设置应用值，如果未设置则冲突的字段不会被更新
+// - ConflictPolicy: 可选参数，未通过 ConflictResolvers 解决的冲突的处理方式
+// Rules 可以绑定在模块及其上层的集群、自定义层级和业务上，每个模块按拓扑路径继承上层节点的规则
 type HostApplyPlanOption struct {
 	Rules             []HostApplyRule             `field:"host_apply_rules" json:"host_apply_rules" bson:"host_apply_rules" mapstructure:"host_apply_rules"`
 	HostModules       []Host2Modules              `field:"host_modules" json:"host_modules" bson:"host_modules" mapstructure:"host_modules"`
 	ConflictResolvers []HostApplyConflictResolver `field:"conflict_resolvers" json:"conflict_resolvers" bson:"conflict_resolvers" mapstructure:"conflict_resolvers"`
+	ConflictPolicy    HostApplyConflictPolicy     `field:"conflict_policy" json:"conflict_policy" bson:"conflict_policy" mapstructure:"conflict_policy"`
 }
 
 type HostApplyConflictField struct {
@@ -145,6 +236,10 @@ type HostApplyUpdateField struct {
 	AttributeID   int64       `field:"bk_attribute_id" json:"bk_attribute_id" bson:"bk_attribute_id" mapstructure:"bk_attribute_id"`
 	PropertyID    string      `field:"bk_property_id" json:"bk_property_id" mapstructure:"bk_property_id"`
 	PropertyValue interface{} `field:"bk_property_value" json:"bk_property_value" mapstructure:"bk_property_value"`
+
+	// Rule 产生该字段值的规则, 值来自 conflict_resolvers 时为空
+	Rule   *HostApplyRule  `field:"host_apply_rule" json:"host_apply_rule,omitempty" mapstructure:"host_apply_rule"`
+	Reason HostApplyReason `field:"reason" json:"reason" mapstructure:"reason"`
 }
 
 type OneHostApplyPlan struct {
@@ -196,7 +291,8 @@ type HostApplyPlanRequest struct {
 	ConflictResolvers []HostApplyConflictResolver `field:"conflict_resolvers" json:"conflict_resolvers" bson:"conflict_resolvers" mapstructure:"conflict_resolvers"`
 	ModuleIDs         []int64                     `field:"bk_module_ids" json:"bk_module_ids" bson:"bk_module_ids" mapstructure:"bk_module_ids"`
 	// optional, if set, only hostID in HostIDs will be used
-	HostIDs []int64 `field:"bk_host_ids" json:"bk_host_ids" bson:"bk_host_ids" mapstructure:"bk_host_ids"`
+	HostIDs        []int64                 `field:"bk_host_ids" json:"bk_host_ids" bson:"bk_host_ids" mapstructure:"bk_host_ids"`
+	ConflictPolicy HostApplyConflictPolicy `field:"conflict_policy" json:"conflict_policy" bson:"conflict_policy" mapstructure:"conflict_policy"`
 }
 
 type HostApplyResult struct {
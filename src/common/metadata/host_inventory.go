@@ -0,0 +1,275 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"configcenter/src/common"
+	"configcenter/src/common/errors"
+)
+
+const (
+	// HostInventoryAnsible is the ansible json dynamic inventory format
+	HostInventoryAnsible = "ansible"
+	// HostInventorySSHConfig is the openssh client config format
+	HostInventorySSHConfig = "ssh_config"
+
+	// HostInventoryMaxBiz is the max number of businesses in an inventory
+	HostInventoryMaxBiz = 20
+	// HostInventoryMaxVars is the max number of host attributes used as host vars
+	HostInventoryMaxVars = 50
+)
+
+// HostInventoryOption is the option to generate the inventory of the hosts in the businesses, the hosts in any of the
+// sets, modules and the modules of the service templates are selected, empty means all.
+type HostInventoryOption struct {
+	BizIDs             []int64 `json:"bk_biz_ids"`
+	SetIDs             []int64 `json:"bk_set_ids"`
+	ModuleIDs          []int64 `json:"bk_module_ids"`
+	ServiceTemplateIDs []int64 `json:"service_template_ids"`
+	// HostVars are the host attributes added to the host vars
+	HostVars []string `json:"host_vars"`
+	// Format is ansible or ssh_config, default is ansible
+	Format string `json:"format"`
+	// SSHUser and SSHPort are set to the User and Port of each host in the ssh config if they are not empty
+	SSHUser string `json:"ssh_user"`
+	SSHPort int64  `json:"ssh_port"`
+}
+
+// Validate validate the host inventory option
+func (o *HostInventoryOption) Validate() errors.RawErrorInfo {
+	if len(o.BizIDs) == 0 {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsNeedSet,
+			Args:    []interface{}{"bk_biz_ids"},
+		}
+	}
+	if len(o.BizIDs) > HostInventoryMaxBiz {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommXXExceedLimit,
+			Args:    []interface{}{"bk_biz_ids", HostInventoryMaxBiz},
+		}
+	}
+	for _, bizID := range o.BizIDs {
+		if bizID <= 0 {
+			return errors.RawErrorInfo{
+				ErrCode: common.CCErrCommParamsIsInvalid,
+				Args:    []interface{}{"bk_biz_ids"},
+			}
+		}
+	}
+	if len(o.HostVars) > HostInventoryMaxVars {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommXXExceedLimit,
+			Args:    []interface{}{"host_vars", HostInventoryMaxVars},
+		}
+	}
+
+	switch o.Format {
+	case "":
+		o.Format = HostInventoryAnsible
+	case HostInventoryAnsible, HostInventorySSHConfig:
+	default:
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsIsInvalid,
+			Args:    []interface{}{"format"},
+		}
+	}
+
+	if o.SSHPort < 0 || o.SSHPort > 65535 {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsIsInvalid,
+			Args:    []interface{}{"ssh_port"},
+		}
+	}
+	if strings.ContainsAny(o.SSHUser, " \t\r\n\"") {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsIsInvalid,
+			Args:    []interface{}{"ssh_user"},
+		}
+	}
+	return errors.RawErrorInfo{}
+}
+
+// HostInventoryResult is the inventory in the format of the option, only one of the fields is set
+type HostInventoryResult struct {
+	Ansible   *AnsibleInventory `json:"ansible,omitempty"`
+	SSHConfig string            `json:"ssh_config,omitempty"`
+}
+
+// HostInventoryResponse is the response of the host inventory
+type HostInventoryResponse struct {
+	BaseResp `json:",inline"`
+	Data     HostInventoryResult `json:"data"`
+}
+
+// InventoryHost is a host in the inventory
+type InventoryHost struct {
+	HostID  int64
+	CloudID int64
+	// IP is the address to connect the host, which is the first inner ip of the host
+	IP string
+	// Groups are the names of the groups that the host belongs to directly, such as the modules
+	Groups []string
+	Vars   map[string]interface{}
+}
+
+// InventoryHostNames returns the names of the hosts in the inventory. The name is the ip of the host, the hosts in
+// different cloud areas can have the same inner ip, so their names are suffixed with the cloud id to tell them apart.
+func InventoryHostNames(hosts []InventoryHost) map[int64]string {
+	cloudIDs := make(map[string]map[int64]bool)
+	for _, host := range hosts {
+		if cloudIDs[host.IP] == nil {
+			cloudIDs[host.IP] = make(map[int64]bool)
+		}
+		cloudIDs[host.IP][host.CloudID] = true
+	}
+
+	names := make(map[int64]string)
+	for _, host := range hosts {
+		if len(cloudIDs[host.IP]) == 1 {
+			names[host.HostID] = host.IP
+			continue
+		}
+		names[host.HostID] = fmt.Sprintf("%s_cloud_%d", host.IP, host.CloudID)
+	}
+	return names
+}
+
+// AnsibleGroup is a group in the ansible inventory
+type AnsibleGroup struct {
+	Hosts    []string               `json:"hosts,omitempty"`
+	Children []string               `json:"children,omitempty"`
+	Vars     map[string]interface{} `json:"vars,omitempty"`
+}
+
+// AnsibleInventory is the ansible json dynamic inventory, the groups are at the top level and the host vars are in
+// the _meta field, so that ansible does not need to call the inventory for each host.
+type AnsibleInventory struct {
+	Groups   map[string]*AnsibleGroup
+	HostVars map[string]map[string]interface{}
+}
+
+type ansibleInventoryMeta struct {
+	HostVars map[string]map[string]interface{} `json:"hostvars"`
+}
+
+// MarshalJSON marshals the inventory in the format of the ansible dynamic inventory
+func (i AnsibleInventory) MarshalJSON() ([]byte, error) {
+	data := make(map[string]interface{}, len(i.Groups)+1)
+	for name, group := range i.Groups {
+		data[name] = group
+	}
+	hostVars := i.HostVars
+	if hostVars == nil {
+		hostVars = make(map[string]map[string]interface{})
+	}
+	data["_meta"] = ansibleInventoryMeta{HostVars: hostVars}
+	return json.Marshal(data)
+}
+
+// UnmarshalJSON unmarshals the inventory in the format of the ansible dynamic inventory
+func (i *AnsibleInventory) UnmarshalJSON(data []byte) error {
+	raw := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	i.Groups = make(map[string]*AnsibleGroup, len(raw))
+	i.HostVars = make(map[string]map[string]interface{})
+	for name, value := range raw {
+		if name == "_meta" {
+			inventoryMeta := new(ansibleInventoryMeta)
+			if err := json.Unmarshal(value, inventoryMeta); err != nil {
+				return err
+			}
+			if inventoryMeta.HostVars != nil {
+				i.HostVars = inventoryMeta.HostVars
+			}
+			continue
+		}
+
+		group := new(AnsibleGroup)
+		if err := json.Unmarshal(value, group); err != nil {
+			return err
+		}
+		i.Groups[name] = group
+	}
+	return nil
+}
+
+// NewAnsibleInventory new an ansible inventory with the groups and the hosts, the host is added to the hosts of its
+// groups, and the host vars are set with ansible_host
+func NewAnsibleInventory(groups map[string]*AnsibleGroup, hosts []InventoryHost) *AnsibleInventory {
+	inventory := &AnsibleInventory{
+		Groups:   groups,
+		HostVars: make(map[string]map[string]interface{}, len(hosts)),
+	}
+
+	names := InventoryHostNames(hosts)
+	for _, host := range hosts {
+		name := names[host.HostID]
+		vars := make(map[string]interface{}, len(host.Vars)+1)
+		for key, value := range host.Vars {
+			vars[key] = value
+		}
+		vars["ansible_host"] = host.IP
+		inventory.HostVars[name] = vars
+
+		for _, groupName := range host.Groups {
+			group, exists := inventory.Groups[groupName]
+			if !exists {
+				group = new(AnsibleGroup)
+				inventory.Groups[groupName] = group
+			}
+			group.Hosts = append(group.Hosts, name)
+		}
+	}
+
+	for _, group := range inventory.Groups {
+		sort.Strings(group.Hosts)
+		sort.Strings(group.Children)
+	}
+	return inventory
+}
+
+// NewSSHConfig generates the openssh client config of the hosts, the host alias is the same as the host name in
+// the ansible inventory, so the hosts in different cloud areas with the same inner ip have different aliases.
+func NewSSHConfig(hosts []InventoryHost, user string, port int64) string {
+	names := InventoryHostNames(hosts)
+	sorted := make([]InventoryHost, len(hosts))
+	copy(sorted, hosts)
+	sort.Slice(sorted, func(i, j int) bool { return names[sorted[i].HostID] < names[sorted[j].HostID] })
+
+	builder := strings.Builder{}
+	for idx, host := range sorted {
+		if idx != 0 {
+			builder.WriteString("\n")
+		}
+		builder.WriteString(fmt.Sprintf("# %s: %d, %s: %d\n", common.BKHostIDField, host.HostID, common.BKCloudIDField,
+			host.CloudID))
+		builder.WriteString(fmt.Sprintf("Host %s\n", names[host.HostID]))
+		builder.WriteString(fmt.Sprintf("    HostName %s\n", host.IP))
+		if len(user) != 0 {
+			builder.WriteString(fmt.Sprintf("    User %s\n", user))
+		}
+		if port != 0 {
+			builder.WriteString(fmt.Sprintf("    Port %d\n", port))
+		}
+	}
+	return builder.String()
+}
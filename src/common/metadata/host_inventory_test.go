@@ -0,0 +1,115 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"configcenter/src/common"
+)
+
+func TestInventoryHostNames(t *testing.T) {
+	hosts := []InventoryHost{
+		{HostID: 1, CloudID: 0, IP: "10.0.0.1"},
+		{HostID: 2, CloudID: 1, IP: "10.0.0.1"},
+		{HostID: 3, CloudID: 1, IP: "10.0.0.2"},
+	}
+	expected := map[int64]string{1: "10.0.0.1_cloud_0", 2: "10.0.0.1_cloud_1", 3: "10.0.0.2"}
+	if names := InventoryHostNames(hosts); !reflect.DeepEqual(names, expected) {
+		t.Errorf("host names should be %v, got %v", expected, names)
+	}
+}
+
+func TestAnsibleInventory(t *testing.T) {
+	groups := map[string]*AnsibleGroup{
+		"biz_2": {Children: []string{"set_3"}, Vars: map[string]interface{}{common.BKAppNameField: "demo"}},
+		"set_3": {Children: []string{"module_5", "module_4"}},
+	}
+	hosts := []InventoryHost{
+		{HostID: 1, IP: "10.0.0.2", Groups: []string{"module_4"}, Vars: map[string]interface{}{"bk_os_type": "1"}},
+		{HostID: 2, IP: "10.0.0.1", Groups: []string{"module_4", "module_5"}},
+	}
+	inventory := NewAnsibleInventory(groups, hosts)
+
+	data, err := json.Marshal(inventory)
+	if err != nil {
+		t.Fatalf("marshal inventory failed, err: %v", err)
+	}
+	raw := make(map[string]interface{})
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("unmarshal inventory failed, err: %v", err)
+	}
+	if _, exists := raw["_meta"]; !exists {
+		t.Fatalf("the inventory should have the _meta field, got %s", data)
+	}
+
+	decoded := new(AnsibleInventory)
+	if err := json.Unmarshal(data, decoded); err != nil {
+		t.Fatalf("unmarshal inventory failed, err: %v", err)
+	}
+	if hosts := decoded.Groups["module_4"].Hosts; !reflect.DeepEqual(hosts, []string{"10.0.0.1", "10.0.0.2"}) {
+		t.Errorf("the hosts of module_4 should be sorted, got %v", hosts)
+	}
+	if children := decoded.Groups["set_3"].Children; !reflect.DeepEqual(children, []string{"module_4", "module_5"}) {
+		t.Errorf("the children of set_3 should be sorted, got %v", children)
+	}
+	expectedVars := map[string]interface{}{"bk_os_type": "1", "ansible_host": "10.0.0.2"}
+	if vars := decoded.HostVars["10.0.0.2"]; !reflect.DeepEqual(vars, expectedVars) {
+		t.Errorf("host vars should be %v, got %v", expectedVars, vars)
+	}
+}
+
+func TestNewSSHConfig(t *testing.T) {
+	hosts := []InventoryHost{
+		{HostID: 2, CloudID: 1, IP: "10.0.0.1"},
+		{HostID: 1, CloudID: 0, IP: "10.0.0.1"},
+	}
+	expected := "# bk_host_id: 1, bk_cloud_id: 0\n" +
+		"Host 10.0.0.1_cloud_0\n" +
+		"    HostName 10.0.0.1\n" +
+		"    User root\n" +
+		"\n" +
+		"# bk_host_id: 2, bk_cloud_id: 1\n" +
+		"Host 10.0.0.1_cloud_1\n" +
+		"    HostName 10.0.0.1\n" +
+		"    User root\n"
+	if config := NewSSHConfig(hosts, "root", 0); config != expected {
+		t.Errorf("ssh config should be:\n%s\ngot:\n%s", expected, config)
+	}
+}
+
+func TestHostInventoryOptionValidate(t *testing.T) {
+	cases := []struct {
+		option HostInventoryOption
+		code   int
+	}{
+		{HostInventoryOption{BizIDs: []int64{2}}, 0},
+		{HostInventoryOption{}, common.CCErrCommParamsNeedSet},
+		{HostInventoryOption{BizIDs: []int64{-1}}, common.CCErrCommParamsIsInvalid},
+		{HostInventoryOption{BizIDs: []int64{2}, Format: "yaml"}, common.CCErrCommParamsIsInvalid},
+		{HostInventoryOption{BizIDs: []int64{2}, SSHUser: "root\nHost *"}, common.CCErrCommParamsIsInvalid},
+	}
+	for idx, c := range cases {
+		if rawErr := c.option.Validate(); rawErr.ErrCode != c.code {
+			t.Errorf("case %d should return error code %d, got %d", idx, c.code, rawErr.ErrCode)
+		}
+	}
+
+	option := HostInventoryOption{BizIDs: []int64{2}}
+	option.Validate()
+	if option.Format != HostInventoryAnsible {
+		t.Errorf("the default format should be ansible, got %s", option.Format)
+	}
+}
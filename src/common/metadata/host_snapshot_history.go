@@ -0,0 +1,121 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/errors"
+)
+
+// HostSnapshotHistory is one record of the host snapshot time series, only the snapshot
+// fields which are synchronized to the host are saved, and a new record is only saved when
+// these fields changed compared with the previous record.
+type HostSnapshotHistory struct {
+	HostID int64 `json:"bk_host_id" bson:"bk_host_id"`
+	// Source is the message source of the collector which reports the snapshot
+	Source   string                 `json:"source" bson:"source"`
+	Snapshot map[string]interface{} `json:"snapshot" bson:"snapshot"`
+	// ChangedFields is the snapshot fields changed compared with the previous record,
+	// it is empty for the first record of the host.
+	ChangedFields   []string  `json:"changed_fields" bson:"changed_fields"`
+	SupplierAccount string    `json:"bk_supplier_account" bson:"bk_supplier_account"`
+	CreateTime      time.Time `json:"create_time" bson:"create_time"`
+}
+
+// SearchHostSnapshotHistoryOption search a host's snapshot history in a time range
+type SearchHostSnapshotHistoryOption struct {
+	HostID int64 `json:"bk_host_id"`
+	// Source only returns records reported by the collector of this source, empty means all sources.
+	Source    string     `json:"source"`
+	StartTime *time.Time `json:"start_time"`
+	EndTime   *time.Time `json:"end_time"`
+	// Fields only returns records whose changed fields contains one of these fields, used to
+	// query the hardware or os change history of the host, empty means all records.
+	Fields []string `json:"fields"`
+	Page   BasePage `json:"page"`
+}
+
+func (o *SearchHostSnapshotHistoryOption) Validate() (rawError errors.RawErrorInfo) {
+	if o.HostID <= 0 {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsIsInvalid,
+			Args:    []interface{}{common.BKHostIDField},
+		}
+	}
+
+	if o.StartTime != nil && o.EndTime != nil && o.StartTime.After(*o.EndTime) {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsIsInvalid,
+			Args:    []interface{}{"start_time"},
+		}
+	}
+
+	if o.Page.IsIllegal() {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommPageLimitIsExceeded,
+		}
+	}
+
+	return errors.RawErrorInfo{}
+}
+
+type HostSnapshotHistoryResult struct {
+	Count int64                 `json:"count"`
+	Info  []HostSnapshotHistory `json:"info"`
+}
+
+// HostSnapshotDriftField is a manually maintained host field whose value reported by the
+// collector disagrees with the value in cmdb.
+type HostSnapshotDriftField struct {
+	PropertyID    string      `json:"bk_property_id" bson:"bk_property_id"`
+	ReportedValue interface{} `json:"reported_value" bson:"reported_value"`
+	CMDBValue     interface{} `json:"cmdb_value" bson:"cmdb_value"`
+}
+
+// HostSnapshotDrift records the drift of a host, it is removed when the drift disappears.
+type HostSnapshotDrift struct {
+	HostID          int64                    `json:"bk_host_id" bson:"bk_host_id"`
+	InnerIP         string                   `json:"bk_host_innerip" bson:"bk_host_innerip"`
+	CloudID         int64                    `json:"bk_cloud_id" bson:"bk_cloud_id"`
+	Fields          []HostSnapshotDriftField `json:"fields" bson:"fields"`
+	SupplierAccount string                   `json:"bk_supplier_account" bson:"bk_supplier_account"`
+	// CreateTime is the time when the drift is detected first.
+	CreateTime time.Time `json:"create_time" bson:"create_time"`
+	// LastTime is the time when the drift fields changed last.
+	LastTime time.Time `json:"last_time" bson:"last_time"`
+}
+
+// SearchHostSnapshotDriftOption search the drift report, empty host ids means all hosts
+type SearchHostSnapshotDriftOption struct {
+	HostIDs []int64 `json:"bk_host_ids"`
+	// PropertyID only returns hosts whose drift fields contains this field
+	PropertyID string   `json:"bk_property_id"`
+	Page       BasePage `json:"page"`
+}
+
+func (o *SearchHostSnapshotDriftOption) Validate() (rawError errors.RawErrorInfo) {
+	if o.Page.IsIllegal() {
+		return errors.RawErrorInfo{
+			ErrCode: common.CCErrCommPageLimitIsExceeded,
+		}
+	}
+
+	return errors.RawErrorInfo{}
+}
+
+type HostSnapshotDriftResult struct {
+	Count int64               `json:"count"`
+	Info  []HostSnapshotDrift `json:"info"`
+}
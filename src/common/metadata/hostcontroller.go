@@ -79,6 +79,8 @@ type GetHistoryResult struct {
 type HostInfo struct {
 	Count int             `json:"count"`
 	Info  []mapstr.MapStr `json:"info"`
+	// NextCursor 游标分页时下一页的cursor, 为空表示没有更多数据
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 type GetHostsResult struct {
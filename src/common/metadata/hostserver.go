@@ -127,6 +127,8 @@ type HostCommonSearch struct {
 	Condition []SearchCondition `json:"condition"`
 	Page      BasePage          `json:"page"`
 	Pattern   string            `json:"pattern,omitempty"`
+	// HostPropertyFilter 主机属性的结构化过滤条件, 与condition中的主机条件取交集
+	HostPropertyFilter *querybuilder.QueryFilter `json:"host_property_filter,omitempty"`
 }
 
 type SetCommonSearch struct {
@@ -450,6 +452,8 @@ type SearchHost struct {
 type ListHostResult struct {
 	Count int                      `json:"count"`
 	Info  []map[string]interface{} `json:"info"`
+	// NextCursor 游标分页时下一页的cursor, 为空表示没有更多数据
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 type HostTopoResult struct {
@@ -584,6 +588,18 @@ type CloudAreaHostCountElem struct {
 	HostCount int64 `json:"host_count"`
 }
 
+// CreateManyCloudAreaOption create many cloud areas
+type CreateManyCloudAreaOption struct {
+	Data []mapstr.MapStr `json:"data"`
+}
+
+// UpdateCloudAreaOption update the cloud area, the empty fields are not updated
+type UpdateCloudAreaOption struct {
+	CloudName   string `json:"bk_cloud_name"`
+	CloudVendor string `json:"bk_cloud_vendor"`
+	Region      string `json:"bk_region"`
+}
+
 type CreateManyCloudAreaResult struct {
 	BaseResp `json:",inline"`
 	Data     []CreateManyCloudAreaElem `json:"data"`
@@ -648,6 +664,13 @@ type AddToModuleInfo struct {
 	ServiceTemplate *ServiceTemplateDetail `field:"service_template" json:"service_template"`
 }
 
+// HostTransferResult the result of transferring one host with auto clearing service instances
+type HostTransferResult struct {
+	HostID  int64  `json:"bk_host_id"`
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
 type HostTransferPreview struct {
 	HostID              int64                  `field:"bk_host_id" json:"bk_host_id"`
 	FinalModules        []int64                `field:"final_modules" json:"final_modules"`
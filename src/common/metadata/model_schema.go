@@ -0,0 +1,306 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"configcenter/src/common"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/mapstr"
+)
+
+// ModelSchema is the declarative definition of the model, including the classifications, objects
+// with their attribute groups, attributes and uniques, and the associations between objects.
+// it's diffed with the live model to generate a plan, and the plan is applied to make them the same.
+type ModelSchema struct {
+	Classifications []SchemaClassification `json:"classifications"`
+	Objects         []SchemaObject         `json:"objects"`
+	Associations    []SchemaAssociation    `json:"associations"`
+}
+
+// SchemaClassification is the classification definition in the model schema.
+type SchemaClassification struct {
+	ClassificationID   string `json:"bk_classification_id"`
+	ClassificationName string `json:"bk_classification_name"`
+	ClassificationIcon string `json:"bk_classification_icon,omitempty"`
+}
+
+// SchemaObject is the object definition in the model schema. the attribute groups, attributes
+// and uniques not declared are left as they are, unless the schema is applied with prune.
+type SchemaObject struct {
+	ObjectID    string                 `json:"bk_obj_id"`
+	ObjectName  string                 `json:"bk_obj_name"`
+	ObjCls      string                 `json:"bk_classification_id"`
+	ObjIcon     string                 `json:"bk_obj_icon,omitempty"`
+	Description string                 `json:"description,omitempty"`
+	Groups      []SchemaAttributeGroup `json:"groups,omitempty"`
+	Attributes  []SchemaAttribute      `json:"attributes,omitempty"`
+	Uniques     []SchemaUnique         `json:"uniques,omitempty"`
+}
+
+// SchemaAttributeGroup is the attribute group definition of an object in the model schema.
+type SchemaAttributeGroup struct {
+	GroupID    string `json:"bk_group_id"`
+	GroupName  string `json:"bk_group_name"`
+	GroupIndex int64  `json:"bk_group_index"`
+	IsCollapse bool   `json:"is_collapse,omitempty"`
+}
+
+// SchemaAttribute is the attribute definition of an object in the model schema,
+// PropertyGroup is the default group of the object if not set.
+type SchemaAttribute struct {
+	PropertyID    string      `json:"bk_property_id"`
+	PropertyName  string      `json:"bk_property_name"`
+	PropertyType  string      `json:"bk_property_type"`
+	PropertyGroup string      `json:"bk_property_group,omitempty"`
+	IsEditable    bool        `json:"editable"`
+	IsRequired    bool        `json:"isrequired"`
+	Option        interface{} `json:"option,omitempty"`
+	Unit          string      `json:"unit,omitempty"`
+	Placeholder   string      `json:"placeholder,omitempty"`
+	Description   string      `json:"description,omitempty"`
+}
+
+// SchemaUnique is the unique definition of an object in the model schema, Keys are the property ids.
+type SchemaUnique struct {
+	Keys      []string `json:"keys"`
+	MustCheck bool     `json:"must_check"`
+}
+
+// SchemaAssociation is the association definition between objects in the model schema,
+// mainline associations are managed by the topology model, they can not be declared in the schema.
+type SchemaAssociation struct {
+	ObjectID             string                    `json:"bk_obj_id"`
+	AsstKindID           string                    `json:"bk_asst_id"`
+	AsstObjID            string                    `json:"bk_asst_obj_id"`
+	AssociationAliasName string                    `json:"bk_obj_asst_name,omitempty"`
+	Mapping              AssociationMapping        `json:"mapping"`
+	OnDelete             AssociationOnDeleteAction `json:"on_delete,omitempty"`
+}
+
+// AssociationName returns the unique id of the association, it's generated with the same rule as model association.
+func (s SchemaAssociation) AssociationName() string {
+	return fmt.Sprintf("%s_%s_%s", s.ObjectID, s.AsstKindID, s.AsstObjID)
+}
+
+// Validate checks the schema itself, the references to the live model are checked when planning.
+func (s *ModelSchema) Validate() (rawError errors.RawErrorInfo) {
+	classifications := make(map[string]struct{})
+	for _, cls := range s.Classifications {
+		if len(cls.ClassificationID) == 0 || len(cls.ClassificationName) == 0 {
+			return errors.RawErrorInfo{
+				ErrCode: common.CCErrCommParamsNeedSet,
+				Args:    []interface{}{"classifications." + common.BKClassificationIDField},
+			}
+		}
+		if _, exist := classifications[cls.ClassificationID]; exist {
+			return errors.RawErrorInfo{
+				ErrCode: common.CCErrCommDuplicateItem,
+				Args:    []interface{}{cls.ClassificationID},
+			}
+		}
+		classifications[cls.ClassificationID] = struct{}{}
+	}
+
+	objects := make(map[string]struct{})
+	for _, obj := range s.Objects {
+		if len(obj.ObjectID) == 0 || len(obj.ObjectName) == 0 || len(obj.ObjCls) == 0 {
+			return errors.RawErrorInfo{
+				ErrCode: common.CCErrCommParamsNeedSet,
+				Args:    []interface{}{fmt.Sprintf("objects[%s]", obj.ObjectID)},
+			}
+		}
+		if _, exist := objects[obj.ObjectID]; exist {
+			return errors.RawErrorInfo{
+				ErrCode: common.CCErrCommDuplicateItem,
+				Args:    []interface{}{obj.ObjectID},
+			}
+		}
+		objects[obj.ObjectID] = struct{}{}
+
+		if rawErr := obj.validate(); rawErr.ErrCode != 0 {
+			return rawErr
+		}
+	}
+
+	associations := make(map[string]struct{})
+	for _, asst := range s.Associations {
+		if len(asst.ObjectID) == 0 || len(asst.AsstKindID) == 0 || len(asst.AsstObjID) == 0 {
+			return errors.RawErrorInfo{
+				ErrCode: common.CCErrCommParamsNeedSet,
+				Args:    []interface{}{"associations." + common.AssociationObjAsstIDField},
+			}
+		}
+		if asst.AsstKindID == common.AssociationKindMainline {
+			return errors.RawErrorInfo{
+				ErrCode: common.CCErrCommParamsInvalid,
+				Args:    []interface{}{asst.AssociationName()},
+			}
+		}
+		switch asst.Mapping {
+		case OneToOneMapping, OneToManyMapping, ManyToManyMapping:
+		default:
+			return errors.RawErrorInfo{
+				ErrCode: common.CCErrCommParamsInvalid,
+				Args:    []interface{}{asst.AssociationName() + ".mapping"},
+			}
+		}
+		if _, exist := associations[asst.AssociationName()]; exist {
+			return errors.RawErrorInfo{
+				ErrCode: common.CCErrCommDuplicateItem,
+				Args:    []interface{}{asst.AssociationName()},
+			}
+		}
+		associations[asst.AssociationName()] = struct{}{}
+	}
+
+	return errors.RawErrorInfo{}
+}
+
+func (o *SchemaObject) validate() (rawError errors.RawErrorInfo) {
+	groups := make(map[string]struct{})
+	for _, group := range o.Groups {
+		if len(group.GroupID) == 0 || len(group.GroupName) == 0 {
+			return errors.RawErrorInfo{
+				ErrCode: common.CCErrCommParamsNeedSet,
+				Args:    []interface{}{fmt.Sprintf("objects[%s].groups", o.ObjectID)},
+			}
+		}
+		if _, exist := groups[group.GroupID]; exist {
+			return errors.RawErrorInfo{
+				ErrCode: common.CCErrCommDuplicateItem,
+				Args:    []interface{}{o.ObjectID + "." + group.GroupID},
+			}
+		}
+		groups[group.GroupID] = struct{}{}
+	}
+
+	attributes := make(map[string]struct{})
+	for _, attr := range o.Attributes {
+		if len(attr.PropertyID) == 0 || len(attr.PropertyName) == 0 || len(attr.PropertyType) == 0 {
+			return errors.RawErrorInfo{
+				ErrCode: common.CCErrCommParamsNeedSet,
+				Args:    []interface{}{fmt.Sprintf("objects[%s].attributes[%s]", o.ObjectID, attr.PropertyID)},
+			}
+		}
+		if _, exist := attributes[attr.PropertyID]; exist {
+			return errors.RawErrorInfo{
+				ErrCode: common.CCErrCommDuplicateItem,
+				Args:    []interface{}{o.ObjectID + "." + attr.PropertyID},
+			}
+		}
+		attributes[attr.PropertyID] = struct{}{}
+	}
+
+	uniques := make(map[string]struct{})
+	for _, unique := range o.Uniques {
+		if len(unique.Keys) == 0 {
+			return errors.RawErrorInfo{
+				ErrCode: common.CCErrCommParamsNeedSet,
+				Args:    []interface{}{fmt.Sprintf("objects[%s].uniques.keys", o.ObjectID)},
+			}
+		}
+		key := SchemaUniqueID(unique.Keys)
+		if _, exist := uniques[key]; exist {
+			return errors.RawErrorInfo{
+				ErrCode: common.CCErrCommDuplicateItem,
+				Args:    []interface{}{o.ObjectID + "." + key},
+			}
+		}
+		uniques[key] = struct{}{}
+	}
+
+	return errors.RawErrorInfo{}
+}
+
+// SchemaUniqueID returns the id of a unique in the schema, it's the sorted property ids joined by comma.
+func SchemaUniqueID(keys []string) string {
+	sorted := make([]string, len(keys))
+	copy(sorted, keys)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ",")
+}
+
+// ExportModelSchemaOption exports the live model as a schema.
+type ExportModelSchemaOption struct {
+	// ObjectIDs are the objects to export, empty means all the objects except the hidden ones.
+	ObjectIDs []string `json:"bk_obj_ids"`
+}
+
+// ModelSchemaOption plans or applies a model schema.
+type ModelSchemaOption struct {
+	Schema ModelSchema `json:"schema"`
+	// Prune deletes the attribute groups, attributes, uniques of the declared objects and the
+	// associations between the declared objects that are not in the schema, preset ones are never deleted.
+	Prune bool `json:"prune"`
+}
+
+type SchemaChangeAction string
+
+const (
+	SchemaChangeCreate SchemaChangeAction = "create"
+	SchemaChangeUpdate SchemaChangeAction = "update"
+	SchemaChangeDelete SchemaChangeAction = "delete"
+)
+
+type SchemaResourceKind string
+
+const (
+	SchemaKindClassification SchemaResourceKind = "classification"
+	SchemaKindObject         SchemaResourceKind = "object"
+	SchemaKindAttributeGroup SchemaResourceKind = "attribute_group"
+	SchemaKindAttribute      SchemaResourceKind = "attribute"
+	SchemaKindUnique         SchemaResourceKind = "unique"
+	SchemaKindAssociation    SchemaResourceKind = "association"
+)
+
+// SchemaChange is one change needed to make the live model the same as the schema.
+type SchemaChange struct {
+	Action SchemaChangeAction `json:"action"`
+	Kind   SchemaResourceKind `json:"kind"`
+	// ObjectID is the object that the group, attribute or unique belongs to.
+	ObjectID string `json:"bk_obj_id,omitempty"`
+	// ID is the identifier of the resource in the schema, for unique it's the sorted property ids.
+	ID string `json:"id"`
+	// TargetID is the id of the live resource to be updated or deleted.
+	TargetID int64 `json:"target_id,omitempty"`
+	// Fields are the fields to be updated.
+	Fields []string `json:"fields,omitempty"`
+	// Data is the desired data of the created or updated resource.
+	Data mapstr.MapStr `json:"data,omitempty"`
+}
+
+func (c SchemaChange) String() string {
+	if len(c.ObjectID) != 0 && c.Kind != SchemaKindObject {
+		return fmt.Sprintf("%s %s %s.%s", c.Action, c.Kind, c.ObjectID, c.ID)
+	}
+	return fmt.Sprintf("%s %s %s", c.Action, c.Kind, c.ID)
+}
+
+// ModelSchemaPlan is the changes to apply in dependency order.
+type ModelSchemaPlan struct {
+	Changes []SchemaChange `json:"changes"`
+}
+
+type ExportModelSchemaResult struct {
+	BaseResp `json:",inline"`
+	Data     ModelSchema `json:"data"`
+}
+
+type ModelSchemaPlanResult struct {
+	BaseResp `json:",inline"`
+	Data     ModelSchemaPlan `json:"data"`
+}
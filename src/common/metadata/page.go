@@ -13,6 +13,7 @@
 package metadata
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 
@@ -32,6 +33,8 @@ type BasePage struct {
 	Sort  string `json:"sort,omitempty" mapstructure:"sort"`
 	Limit int    `json:"limit,omitempty" mapstructure:"limit"`
 	Start int    `json:"start" mapstructure:"start"`
+	// Cursor 游标分页(search-after)的续传令牌, 首页传"*", 后续传上一页返回的next_cursor
+	Cursor string `json:"cursor,omitempty" mapstructure:"cursor"`
 }
 
 func (page BasePage) Validate(allowNoLimit bool) (string, error) {
@@ -40,6 +43,14 @@ func (page BasePage) Validate(allowNoLimit bool) (string, error) {
 			return "limit", fmt.Errorf("exceed max page size: %d", common.BKMaxPageSize)
 		}
 	}
+	if page.IsCursorPage() {
+		if page.Start != 0 {
+			return "start", errors.New("start must be 0 when cursor is set")
+		}
+		if page.Limit <= 0 || page.Limit > common.BKMaxPageSize {
+			return "limit", fmt.Errorf("limit must between 1 and %d when cursor is set", common.BKMaxPageSize)
+		}
+	}
 	return "", nil
 }
 
@@ -64,6 +75,9 @@ func ParsePage(origin interface{}) BasePage {
 	if sort, ok := page["sort"]; ok && sort != nil {
 		result.Sort = fmt.Sprint(sort)
 	}
+	if cursor, ok := page["cursor"]; ok && cursor != nil {
+		result.Cursor = fmt.Sprint(cursor)
+	}
 	if start, ok := page["start"]; ok {
 		result.Start, _ = strconv.Atoi(fmt.Sprint(start))
 	}
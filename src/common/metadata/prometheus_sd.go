@@ -0,0 +1,291 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"encoding/json"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"configcenter/src/common"
+	"configcenter/src/common/errors"
+)
+
+const (
+	// PrometheusSDHostMode emits a target for each host with the port in the option
+	PrometheusSDHostMode = "host"
+	// PrometheusSDProcessMode emits a target for each bind info of the processes on the hosts
+	PrometheusSDProcessMode = "process"
+
+	// PrometheusSDLabelPrefix is the prefix of the labels, prometheus keeps the labels with __meta_ prefix only
+	// in the relabeling phase, so the users can choose the labels to keep with relabel_configs.
+	PrometheusSDLabelPrefix = "__meta_cmdb_"
+	// PrometheusSDMaxLabels is the max number of host attributes that can be used as labels
+	PrometheusSDMaxLabels = 20
+)
+
+// PrometheusSDOption is the option of the prometheus http service discovery, it's parsed from the url query
+// because prometheus only sends GET requests without body to the http_sd_configs url. The list values are
+// comma separated, such as bk_module_ids=1,2,3, and the key can also be repeated.
+type PrometheusSDOption struct {
+	BizID int64
+	// Mode is host or process, default is host
+	Mode               string
+	SetIDs             []int64
+	ModuleIDs          []int64
+	ServiceTemplateIDs []int64
+	// ProcessNames filters the processes by bk_process_name in process mode
+	ProcessNames []string
+	// Port is the port of the targets in host mode, it filters the bind info port in process mode
+	Port int64
+	// Labels are the host attributes added to the labels of the targets
+	Labels []string
+}
+
+func splitPrometheusSDQuery(query url.Values, key string) []string {
+	values := make([]string, 0)
+	for _, value := range query[key] {
+		for _, item := range strings.Split(value, ",") {
+			item = strings.TrimSpace(item)
+			if len(item) != 0 {
+				values = append(values, item)
+			}
+		}
+	}
+	return values
+}
+
+func parsePrometheusSDIDs(query url.Values, key string) ([]int64, errors.RawErrorInfo) {
+	values := splitPrometheusSDQuery(query, key)
+	ids := make([]int64, len(values))
+	for idx, value := range values {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || id <= 0 {
+			return nil, errors.RawErrorInfo{
+				ErrCode: common.CCErrCommParamsIsInvalid,
+				Args:    []interface{}{key},
+			}
+		}
+		ids[idx] = id
+	}
+	return ids, errors.RawErrorInfo{}
+}
+
+// ParsePrometheusSDOption parses and validates the prometheus http service discovery option of the business
+func ParsePrometheusSDOption(bizID int64, query url.Values) (*PrometheusSDOption, errors.RawErrorInfo) {
+	if bizID <= 0 {
+		return nil, errors.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsIsInvalid,
+			Args:    []interface{}{common.BKAppIDField},
+		}
+	}
+
+	opt := &PrometheusSDOption{
+		BizID:        bizID,
+		Mode:         query.Get("mode"),
+		ProcessNames: splitPrometheusSDQuery(query, "bk_process_names"),
+		Labels:       splitPrometheusSDQuery(query, "labels"),
+	}
+
+	var rawErr errors.RawErrorInfo
+	if opt.SetIDs, rawErr = parsePrometheusSDIDs(query, "bk_set_ids"); rawErr.ErrCode != 0 {
+		return nil, rawErr
+	}
+	if opt.ModuleIDs, rawErr = parsePrometheusSDIDs(query, "bk_module_ids"); rawErr.ErrCode != 0 {
+		return nil, rawErr
+	}
+	if opt.ServiceTemplateIDs, rawErr = parsePrometheusSDIDs(query, "service_template_ids"); rawErr.ErrCode != 0 {
+		return nil, rawErr
+	}
+
+	if port := query.Get("port"); len(port) != 0 {
+		var err error
+		opt.Port, err = strconv.ParseInt(port, 10, 64)
+		if err != nil || opt.Port <= 0 || opt.Port > 65535 {
+			return nil, errors.RawErrorInfo{
+				ErrCode: common.CCErrCommParamsIsInvalid,
+				Args:    []interface{}{"port"},
+			}
+		}
+	}
+
+	switch opt.Mode {
+	case "":
+		opt.Mode = PrometheusSDHostMode
+		fallthrough
+	case PrometheusSDHostMode:
+		if opt.Port == 0 {
+			return nil, errors.RawErrorInfo{
+				ErrCode: common.CCErrCommParamsNeedSet,
+				Args:    []interface{}{"port"},
+			}
+		}
+		if len(opt.ProcessNames) != 0 {
+			return nil, errors.RawErrorInfo{
+				ErrCode: common.CCErrCommParamsIsInvalid,
+				Args:    []interface{}{"bk_process_names"},
+			}
+		}
+	case PrometheusSDProcessMode:
+	default:
+		return nil, errors.RawErrorInfo{
+			ErrCode: common.CCErrCommParamsIsInvalid,
+			Args:    []interface{}{"mode"},
+		}
+	}
+
+	if len(opt.Labels) > PrometheusSDMaxLabels {
+		return nil, errors.RawErrorInfo{
+			ErrCode: common.CCErrCommXXExceedLimit,
+			Args:    []interface{}{"labels", PrometheusSDMaxLabels},
+		}
+	}
+	return opt, errors.RawErrorInfo{}
+}
+
+// MatchProcessName checks if the process is selected by the process names
+func (o *PrometheusSDOption) MatchProcessName(name string) bool {
+	if len(o.ProcessNames) == 0 {
+		return true
+	}
+	for _, processName := range o.ProcessNames {
+		if processName == name {
+			return true
+		}
+	}
+	return false
+}
+
+// PrometheusTargetGroup is a target group in the response of the prometheus http service discovery
+type PrometheusTargetGroup struct {
+	Targets []string          `json:"targets"`
+	Labels  map[string]string `json:"labels"`
+}
+
+// NewPrometheusTargetGroup new a target group with the targets
+func NewPrometheusTargetGroup(targets ...string) *PrometheusTargetGroup {
+	return &PrometheusTargetGroup{
+		Targets: targets,
+		Labels:  make(map[string]string),
+	}
+}
+
+// SetLabel sets the label with the prefix, the characters that are not allowed in the prometheus label name are
+// replaced by underscores
+func (g *PrometheusTargetGroup) SetLabel(name string, value interface{}) {
+	g.Labels[PrometheusSDLabelPrefix+PrometheusLabelName(name)] = PrometheusLabelValue(value)
+}
+
+// PrometheusLabelName converts the name to a valid prometheus label name, which matches [a-zA-Z_][a-zA-Z0-9_]*
+func PrometheusLabelName(name string) string {
+	label := []byte(name)
+	for idx, char := range label {
+		switch {
+		case char == '_', char >= 'a' && char <= 'z', char >= 'A' && char <= 'Z':
+		case char >= '0' && char <= '9' && idx != 0:
+		default:
+			label[idx] = '_'
+		}
+	}
+	return string(label)
+}
+
+// PrometheusLabelValue converts the attribute value to the label value
+func PrometheusLabelValue(value interface{}) string {
+	switch val := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case json.Number:
+		return val.String()
+	case bool:
+		return strconv.FormatBool(val)
+	}
+	js, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+	return string(js)
+}
+
+// PrometheusJoinLabelValues joins the values with the commas around them, such as ",a,b,", so that the values can be
+// matched by the regex like .*,a,.* in the relabeling, which is the same as the tags of the consul service discovery
+func PrometheusJoinLabelValues(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return "," + strings.Join(values, ",") + ","
+}
+
+// PrometheusHostIP returns the first inner ip of the host, which is used as the address of the targets on the host
+func PrometheusHostIP(innerIP interface{}) string {
+	ip, ok := innerIP.(string)
+	if !ok {
+		return ""
+	}
+	return strings.TrimSpace(strings.Split(ip, ",")[0])
+}
+
+// PrometheusBindTarget is a target from the bind info of a process
+type PrometheusBindTarget struct {
+	Address string
+	Port    string
+}
+
+// PrometheusBindTargets returns the scrapable targets in the process bind info. The disabled, udp, port range and
+// loopback bind info are skipped, and the unspecified ip, such as 0.0.0.0, is replaced by the host ip. The port
+// filters the bind info if it is not 0.
+func PrometheusBindTargets(bindInfo []ProcBindInfo, hostIP string, port int64) []PrometheusBindTarget {
+	targets := make([]PrometheusBindTarget, 0)
+	for _, info := range bindInfo {
+		if info.Std == nil || info.Std.Port == nil || info.Std.Enable != nil && !*info.Std.Enable {
+			continue
+		}
+		if info.Std.Protocol != nil && ProtocolType(*info.Std.Protocol) == ProtocolTypeUDP {
+			continue
+		}
+
+		bindPort, err := strconv.ParseInt(strings.TrimSpace(*info.Std.Port), 10, 64)
+		if err != nil || bindPort <= 0 || bindPort > 65535 {
+			continue
+		}
+		if port != 0 && bindPort != port {
+			continue
+		}
+
+		ip := ""
+		if info.Std.IP != nil {
+			ip = strings.TrimSpace(*info.Std.IP)
+		}
+		if parsed := net.ParseIP(ip); parsed == nil || parsed.IsUnspecified() {
+			ip = hostIP
+		} else if parsed.IsLoopback() {
+			continue
+		}
+		if len(ip) == 0 {
+			continue
+		}
+
+		portStr := strconv.FormatInt(bindPort, 10)
+		targets = append(targets, PrometheusBindTarget{
+			Address: net.JoinHostPort(ip, portStr),
+			Port:    portStr,
+		})
+	}
+	return targets
+}
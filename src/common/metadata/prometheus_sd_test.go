@@ -0,0 +1,107 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"configcenter/src/common"
+)
+
+func TestParsePrometheusSDOption(t *testing.T) {
+	query, _ := url.ParseQuery("port=9100&bk_module_ids=1,2&bk_module_ids=3&labels=bk_os_type,,bk_state")
+	opt, rawErr := ParsePrometheusSDOption(2, query)
+	if rawErr.ErrCode != 0 {
+		t.Fatalf("parse option failed, err: %v", rawErr)
+	}
+	if opt.Mode != PrometheusSDHostMode || opt.Port != 9100 {
+		t.Errorf("the default mode should be host, got mode %s and port %d", opt.Mode, opt.Port)
+	}
+	if !reflect.DeepEqual(opt.ModuleIDs, []int64{1, 2, 3}) {
+		t.Errorf("module ids should be [1 2 3], got %v", opt.ModuleIDs)
+	}
+	if !reflect.DeepEqual(opt.Labels, []string{"bk_os_type", "bk_state"}) {
+		t.Errorf("labels should be [bk_os_type bk_state], got %v", opt.Labels)
+	}
+
+	cases := []struct {
+		query string
+		code  int
+	}{
+		{"mode=process&bk_process_names=nginx", 0},
+		{"", common.CCErrCommParamsNeedSet},
+		{"mode=host&port=9100&bk_process_names=nginx", common.CCErrCommParamsIsInvalid},
+		{"port=70000", common.CCErrCommParamsIsInvalid},
+		{"mode=container", common.CCErrCommParamsIsInvalid},
+		{"port=9100&bk_set_ids=a", common.CCErrCommParamsIsInvalid},
+	}
+	for _, c := range cases {
+		query, _ := url.ParseQuery(c.query)
+		if _, rawErr := ParsePrometheusSDOption(2, query); rawErr.ErrCode != c.code {
+			t.Errorf("parse %q should return error code %d, got %d", c.query, c.code, rawErr.ErrCode)
+		}
+	}
+}
+
+func TestPrometheusLabel(t *testing.T) {
+	group := NewPrometheusTargetGroup("127.0.0.1:9100")
+	group.SetLabel("host_bk-os.type", "linux")
+	group.SetLabel("1st", 1.0)
+	group.SetLabel(common.BKCloudIDField, json.Number("3"))
+	group.SetLabel("tags", []string{"a"})
+	group.SetLabel("empty", nil)
+
+	expected := map[string]string{
+		"__meta_cmdb_host_bk_os_type": "linux",
+		"__meta_cmdb__st":             "1",
+		"__meta_cmdb_bk_cloud_id":     "3",
+		"__meta_cmdb_tags":            `["a"]`,
+		"__meta_cmdb_empty":           "",
+	}
+	if !reflect.DeepEqual(group.Labels, expected) {
+		t.Errorf("labels should be %v, got %v", expected, group.Labels)
+	}
+
+	if joined := PrometheusJoinLabelValues([]string{"a", "b"}); joined != ",a,b," {
+		t.Errorf("joined values should be ,a,b, got %s", joined)
+	}
+}
+
+func TestPrometheusBindTargets(t *testing.T) {
+	bindInfo := make([]ProcBindInfo, 0)
+	raw := `[
+		{"ip": "0.0.0.0", "port": "80", "protocol": "1", "enable": true},
+		{"ip": "10.0.0.2", "port": "443", "protocol": "1", "enable": true},
+		{"ip": "127.0.0.1", "port": "8080", "protocol": "1", "enable": true},
+		{"ip": "10.0.0.2", "port": "53", "protocol": "2", "enable": true},
+		{"ip": "10.0.0.2", "port": "8000-8010", "protocol": "1", "enable": true},
+		{"ip": "10.0.0.2", "port": "9000", "protocol": "1", "enable": false}
+	]`
+	if err := json.Unmarshal([]byte(raw), &bindInfo); err != nil {
+		t.Fatalf("unmarshal bind info failed, err: %v", err)
+	}
+
+	targets := PrometheusBindTargets(bindInfo, "10.0.0.1", 0)
+	expected := []PrometheusBindTarget{{Address: "10.0.0.1:80", Port: "80"}, {Address: "10.0.0.2:443", Port: "443"}}
+	if !reflect.DeepEqual(targets, expected) {
+		t.Errorf("targets should be %v, got %v", expected, targets)
+	}
+
+	targets = PrometheusBindTargets(bindInfo, "10.0.0.1", 443)
+	if len(targets) != 1 || targets[0].Address != "10.0.0.2:443" {
+		t.Errorf("only the bind info with port 443 should be returned, got %v", targets)
+	}
+}
@@ -17,6 +17,7 @@ import (
 
 	"configcenter/src/common"
 	"configcenter/src/common/mapstr"
+	"configcenter/src/common/querybuilder"
 )
 
 // Deprecated: SearchLimit sub condition
@@ -37,6 +38,8 @@ type QueryCondition struct {
 	Page           BasePage      `json:"page"`
 	Condition      mapstr.MapStr `json:"condition"`
 	DisableCounter bool          `json:"disable_counter"`
+	// Filter 结构化的过滤条件, 会根据模型字段校验后与condition取交集
+	Filter *querybuilder.QueryFilter `json:"filter,omitempty"`
 }
 
 // IsIllegal  limit is illegal, if limit = 0; change to default page size
@@ -54,6 +57,8 @@ func (qc *QueryCondition) IsIllegal() bool {
 type QueryResult struct {
 	Count uint64          `json:"count"`
 	Info  []mapstr.MapStr `json:"info"`
+	// NextCursor 游标分页时下一页的cursor, 为空表示没有更多数据
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 type QueryConditionResult ResponseInstData
@@ -0,0 +1,246 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"fmt"
+	"strings"
+
+	"configcenter/src/common"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/querybuilder"
+)
+
+// InstFilterFieldTypes returns the types of the fields that can be used in the filter of the object instances,
+// which are the model attributes and the builtin fields that are not attributes.
+func InstFilterFieldTypes(objID string, attributes []Attribute) querybuilder.FieldTypeFunc {
+	fieldTypes := map[string]string{
+		common.GetInstIDField(objID): common.FieldTypeInt,
+		common.CreateTimeField:       common.FieldTypeTime,
+		common.LastTimeField:         common.FieldTypeTime,
+		common.BKOwnerIDField:        common.FieldTypeSingleChar,
+	}
+	switch objID {
+	case common.BKInnerObjIDSet:
+		fieldTypes[common.BKAppIDField] = common.FieldTypeInt
+		fieldTypes[common.BKParentIDField] = common.FieldTypeInt
+	case common.BKInnerObjIDModule:
+		fieldTypes[common.BKAppIDField] = common.FieldTypeInt
+		fieldTypes[common.BKSetIDField] = common.FieldTypeInt
+		fieldTypes[common.BKParentIDField] = common.FieldTypeInt
+	case common.BKInnerObjIDHost:
+		fieldTypes[common.BKCloudIDField] = common.FieldTypeInt
+	case common.BKInnerObjIDApp, common.BKInnerObjIDProc, common.BKInnerObjIDPlat:
+	default:
+		fieldTypes[common.BKObjIDField] = common.FieldTypeSingleChar
+	}
+
+	for _, attribute := range attributes {
+		if _, exists := fieldTypes[attribute.PropertyID]; exists {
+			continue
+		}
+		fieldTypes[attribute.PropertyID] = attribute.PropertyType
+	}
+	return querybuilder.FieldTypeFuncFromMap(fieldTypes)
+}
+
+// InstAsstFilterFieldTypes returns the types of the fields that can be used in the filter of instance associations
+func InstAsstFilterFieldTypes() querybuilder.FieldTypeFunc {
+	return querybuilder.FieldTypeFuncFromMap(map[string]string{
+		common.BKFieldID:                 common.FieldTypeInt,
+		common.BKInstIDField:             common.FieldTypeInt,
+		common.BKObjIDField:              common.FieldTypeSingleChar,
+		common.BKAsstInstIDField:         common.FieldTypeInt,
+		common.BKAsstObjIDField:          common.FieldTypeSingleChar,
+		common.AssociationKindIDField:    common.FieldTypeSingleChar,
+		common.AssociationObjAsstIDField: common.FieldTypeSingleChar,
+		common.BKAppIDField:              common.FieldTypeInt,
+		common.BKOwnerIDField:            common.FieldTypeSingleChar,
+	})
+}
+
+// MergeQueryFilter validates the filter with the field types and merges it into the condition with AND, the values of
+// the string operators in the filter are matched literally. returns the invalid key and error if the filter is invalid.
+func MergeQueryFilter(cond mapstr.MapStr, filter *querybuilder.QueryFilter,
+	typeOf querybuilder.FieldTypeFunc) (mapstr.MapStr, string, error) {
+
+	if cond == nil {
+		cond = mapstr.New()
+	}
+	if filter == nil || filter.Rule == nil {
+		return cond, "", nil
+	}
+
+	if key, err := filter.ValidateWithFields(typeOf); err != nil {
+		return nil, "filter." + key, err
+	}
+	mgoFilter, key, err := filter.ToLiteralMgo()
+	if err != nil {
+		return nil, "filter." + key, err
+	}
+
+	if len(cond) == 0 {
+		return mgoFilter, "", nil
+	}
+	return mapstr.MapStr{common.BKDBAND: []map[string]interface{}{cond, mgoFilter}}, "", nil
+}
+
+// scriptQueryOperators are the mongo operators that run scripts or evaluate expressions, they are never allowed in
+// the query conditions.
+var scriptQueryOperators = map[string]bool{
+	"$where":       true,
+	"$function":    true,
+	"$accumulator": true,
+	"$expr":        true,
+}
+
+// MaxUserRegexLength is the max length of the regular expression in the query conditions of the users
+const MaxUserRegexLength = 128
+
+// ValidateQueryOperators checks the raw query condition does not contain the operators that run scripts, it's used by
+// the internal apis whose conditions are built by the servers, the conditions of the users are checked by
+// ValidateUserQueryOperators.
+func ValidateQueryOperators(cond interface{}) error {
+	return validateQueryOperators(cond, false)
+}
+
+// ValidateUserQueryOperators checks the raw query condition from the users, the operators that run scripts are not
+// allowed, and the regular expressions are bounded by ValidateUserRegex, $options is not allowed.
+func ValidateUserQueryOperators(cond interface{}) error {
+	return validateQueryOperators(cond, true)
+}
+
+// ValidateSearchConditionOperators checks the operators and values of the host search conditions from the users, the
+// operators that run scripts are not allowed, and the regular expressions are bounded like ValidateUserQueryOperators.
+func ValidateSearchConditionOperators(conds []SearchCondition) error {
+	for _, cond := range conds {
+		for _, item := range cond.Condition {
+			if scriptQueryOperators[item.Operator] || item.Operator == common.BKDBOPTIONS {
+				return fmt.Errorf("operator %s is not allowed in query condition", item.Operator)
+			}
+			if item.Operator == common.BKDBLIKE {
+				if err := ValidateUserRegex(item.Value); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := validateQueryOperators(item.Value, true); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateUserRegex checks the regular expression from the users is a string anchored at the beginning by "^" and not
+// longer than MaxUserRegexLength, so that it can use the index and the backtracking of it is bounded.
+func ValidateUserRegex(value interface{}) error {
+	pattern, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("value of operator %s should be a string", common.BKDBLIKE)
+	}
+	if len(pattern) > MaxUserRegexLength {
+		return fmt.Errorf("value of operator %s exceeds max length %d", common.BKDBLIKE, MaxUserRegexLength)
+	}
+	if !strings.HasPrefix(pattern, "^") {
+		return fmt.Errorf("value of operator %s should be anchored by ^", common.BKDBLIKE)
+	}
+	return nil
+}
+
+// validateQueryOperators checks the operators of the condition recursively, the regular expressions are bounded if
+// the condition is from the users.
+func validateQueryOperators(cond interface{}, fromUser bool) error {
+	switch value := cond.(type) {
+	case mapstr.MapStr:
+		return validateQueryOperators(map[string]interface{}(value), fromUser)
+	case map[string]interface{}:
+		for key, item := range value {
+			if scriptQueryOperators[key] || (fromUser && key == common.BKDBOPTIONS) {
+				return fmt.Errorf("operator %s is not allowed in query condition", key)
+			}
+			if fromUser && key == common.BKDBLIKE {
+				if err := ValidateUserRegex(item); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := validateQueryOperators(item, fromUser); err != nil {
+				return err
+			}
+		}
+	case []mapstr.MapStr:
+		for _, item := range value {
+			if err := validateQueryOperators(item, fromUser); err != nil {
+				return err
+			}
+		}
+	case []map[string]interface{}:
+		for _, item := range value {
+			if err := validateQueryOperators(item, fromUser); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, item := range value {
+			if err := validateQueryOperators(item, fromUser); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// QueryConditionFields returns the top level fields used in the raw query condition, including the fields in the
+// logical operators like $and, $or and $nor, the sub fields like "a.b" are returned as their top level field "a".
+func QueryConditionFields(cond interface{}) []string {
+	fields := make([]string, 0)
+	switch value := cond.(type) {
+	case mapstr.MapStr:
+		return QueryConditionFields(map[string]interface{}(value))
+	case map[string]interface{}:
+		for key, item := range value {
+			if strings.HasPrefix(key, "$") {
+				fields = append(fields, QueryConditionFields(item)...)
+				continue
+			}
+			fields = append(fields, strings.SplitN(key, ".", 2)[0])
+		}
+	case []mapstr.MapStr:
+		for _, item := range value {
+			fields = append(fields, QueryConditionFields(item)...)
+		}
+	case []map[string]interface{}:
+		for _, item := range value {
+			fields = append(fields, QueryConditionFields(item)...)
+		}
+	case []interface{}:
+		for _, item := range value {
+			fields = append(fields, QueryConditionFields(item)...)
+		}
+	}
+	return fields
+}
+
+// SortFields returns the fields used in the sort of the page, like "-a,b"
+func SortFields(sort string) []string {
+	fields := make([]string, 0)
+	for _, item := range strings.Split(sort, ",") {
+		field := strings.TrimSpace(strings.SplitN(item, ":", 2)[0])
+		field = strings.TrimLeft(field, "+-")
+		if len(field) > 0 {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
@@ -0,0 +1,115 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"configcenter/src/common/mapstr"
+)
+
+func TestValidateQueryOperators(t *testing.T) {
+	regexCond := mapstr.MapStr{"$or": []interface{}{
+		mapstr.MapStr{"bk_inst_name": mapstr.MapStr{"$regex": "a.*"}},
+	}}
+	if err := ValidateQueryOperators(regexCond); err != nil {
+		t.Errorf("regex in condition should be allowed, err: %v", err)
+	}
+
+	for _, operator := range []string{"$where", "$function", "$accumulator", "$expr"} {
+		cond := mapstr.MapStr{"$and": []mapstr.MapStr{{operator: "sleep(1000)"}}}
+		if err := ValidateQueryOperators(cond); err == nil {
+			t.Errorf("script operator %s should be rejected", operator)
+		}
+	}
+
+	if err := ValidateQueryOperators(mapstr.MapStr{"bk_inst_id": mapstr.MapStr{"$in": []int64{1}}}); err != nil {
+		t.Errorf("normal condition should be allowed, err: %v", err)
+	}
+}
+
+func TestValidateUserQueryOperators(t *testing.T) {
+	anchored := mapstr.MapStr{"$or": []interface{}{
+		mapstr.MapStr{"bk_inst_name": mapstr.MapStr{"$regex": "^a.*"}},
+	}}
+	if err := ValidateUserQueryOperators(anchored); err != nil {
+		t.Errorf("anchored regex in condition should be allowed, err: %v", err)
+	}
+
+	invalid := []mapstr.MapStr{
+		{"bk_inst_name": mapstr.MapStr{"$regex": "a.*"}},
+		{"bk_inst_name": mapstr.MapStr{"$regex": "^" + strings.Repeat("(a+)+", MaxUserRegexLength)}},
+		{"bk_inst_name": mapstr.MapStr{"$regex": mapstr.MapStr{"$regex": "^a"}}},
+		{"bk_inst_name": mapstr.MapStr{"$regex": "^a", "$options": "s"}},
+		{"$and": []mapstr.MapStr{{"$where": "sleep(1000)"}}},
+	}
+	for _, cond := range invalid {
+		if err := ValidateUserQueryOperators(cond); err == nil {
+			t.Errorf("condition %v should be rejected", cond)
+		}
+	}
+}
+
+func TestValidateSearchConditionOperators(t *testing.T) {
+	conds := []SearchCondition{{ObjectID: "host", Condition: []ConditionItem{
+		{Field: "bk_host_name", Operator: "$eq", Value: "a"},
+	}}}
+	if err := ValidateSearchConditionOperators(conds); err != nil {
+		t.Errorf("normal condition should be allowed, err: %v", err)
+	}
+
+	conds[0].Condition = append(conds[0].Condition, ConditionItem{Field: "bk_host_name", Operator: "$regex", Value: "^a"})
+	if err := ValidateSearchConditionOperators(conds); err != nil {
+		t.Errorf("anchored regex operator should be allowed, err: %v", err)
+	}
+
+	conds[0].Condition = []ConditionItem{{Field: "bk_host_name", Operator: "$regex", Value: "(a+)+$"}}
+	if err := ValidateSearchConditionOperators(conds); err == nil {
+		t.Errorf("regex operator which is not anchored should be rejected")
+	}
+
+	conds[0].Condition = []ConditionItem{{Field: "bk_host_name", Operator: "$where", Value: "true"}}
+	if err := ValidateSearchConditionOperators(conds); err == nil {
+		t.Errorf("script operator should be rejected")
+	}
+
+	conds[0].Condition = []ConditionItem{{Field: "bk_host_name", Operator: "$eq",
+		Value: map[string]interface{}{"$where": "true"}}}
+	if err := ValidateSearchConditionOperators(conds); err == nil {
+		t.Errorf("script operator in value should be rejected")
+	}
+}
+
+func TestQueryConditionFields(t *testing.T) {
+	cond := mapstr.MapStr{
+		"bk_inst_name": "a",
+		"cost.amount":  mapstr.MapStr{"$gt": 1},
+		"$or": []interface{}{
+			mapstr.MapStr{"contract": "b"},
+			map[string]interface{}{"$and": []mapstr.MapStr{{"owner": "c"}}},
+		},
+	}
+	fields := QueryConditionFields(cond)
+	sort.Strings(fields)
+	expected := []string{"bk_inst_name", "contract", "cost", "owner"}
+	if !reflect.DeepEqual(fields, expected) {
+		t.Errorf("expected fields %v, got %v", expected, fields)
+	}
+
+	if fields := SortFields("-cost, bk_inst_id:1,"); !reflect.DeepEqual(fields, []string{"cost", "bk_inst_id"}) {
+		t.Errorf("unexpected sort fields %v", fields)
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/coccyx/timeparser"
 
 	"configcenter/src/common"
+	"configcenter/src/common/querybuilder"
 	"configcenter/src/common/util"
 )
 
@@ -32,6 +33,10 @@ type ObjQueryInput struct {
 	Limit          int         `json:"limit"`
 	Sort           string      `json:"sort"`
 	DisableCounter bool        `json:"disable_counter"`
+	// Filter 结构化的过滤条件, 会根据模型字段校验后与condition取交集
+	Filter *querybuilder.QueryFilter `json:"filter,omitempty"`
+	// Cursor 游标分页的续传令牌, 首页传"*", 使用时start需为0
+	Cursor string `json:"cursor,omitempty"`
 }
 
 // ConvTime 将查询条件中字段包含cc_type key ，子节点变为time.Time
@@ -345,8 +345,9 @@ type ReadModelAssociationResult struct {
 type ReadInstAssociationResult struct {
 	BaseResp
 	Data struct {
-		Count uint64     `json:"count"`
-		Info  []InstAsst `json:"info"`
+		Count      uint64     `json:"count"`
+		Info       []InstAsst `json:"info"`
+		NextCursor string     `json:"next_cursor,omitempty"`
 	}
 }
 
@@ -16,3 +16,16 @@ type SetOptionResult struct {
 	BaseResp `json:",inline"`
 	Data     SetDataResult `json:"data"`
 }
+
+// BatchCreateSetRequest batch create sets in a business, the supplier account is shared by the sets without it
+type BatchCreateSetRequest struct {
+	BkSupplierAccount string                   `json:"bk_supplier_account"`
+	Sets              []map[string]interface{} `json:"sets"`
+}
+
+// OneSetCreateResult the result of one set in the batch create sets request
+type OneSetCreateResult struct {
+	Index    int         `json:"index"`
+	Data     interface{} `json:"data"`
+	ErrorMsg string      `json:"error_message"`
+}
@@ -84,3 +84,10 @@ type SetTemplateSyncStatus struct {
 func GetSetTemplateSyncIndex(setID int64) string {
 	return fmt.Sprintf("set_template_sync:%d", setID)
 }
+
+// ServiceTemplateWithModuleInfo the service template of the set template with its modules and host count
+type ServiceTemplateWithModuleInfo struct {
+	ServiceTemplate ServiceTemplate `json:"service_template"`
+	HostCount       int             `json:"host_count"`
+	Modules         []ModuleInst    `json:"modules"`
+}
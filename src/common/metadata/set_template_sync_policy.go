@@ -0,0 +1,254 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"configcenter/src/common"
+
+	"github.com/robfig/cron"
+)
+
+// SetTemplateSyncMode 集群模板同步到集群实例的方式
+type SetTemplateSyncMode string
+
+const (
+	// SetTemplateSyncModeManual 由用户手动触发同步, 默认方式
+	SetTemplateSyncModeManual SetTemplateSyncMode = "manual"
+	// SetTemplateSyncModeAuto 集群模板变更后自动同步
+	SetTemplateSyncModeAuto SetTemplateSyncMode = "auto"
+	// SetTemplateSyncModeWindow 在维护窗口(cron表达式)内定时同步
+	SetTemplateSyncModeWindow SetTemplateSyncMode = "window"
+)
+
+const (
+	// SetTemplateSyncDefaultBatchSize 每批次同步的集群数量默认值
+	SetTemplateSyncDefaultBatchSize = 20
+	// SetTemplateSyncMaxBatchSize 每批次同步的集群数量最大值
+	SetTemplateSyncMaxBatchSize = 200
+
+	// SetTemplateSyncWindowDuration 维护窗口从cron表达式的时间点开始持续的时长, 只在窗口内开始同步
+	SetTemplateSyncWindowDuration = time.Hour
+	// SetTemplateSyncRetryBackoff 同步失败后在维护窗口内重试的初始间隔, 每次连续失败翻倍
+	SetTemplateSyncRetryBackoff = time.Minute
+	// SetTemplateSyncMaxRetryBackoff 同步失败后重试间隔的最大值
+	SetTemplateSyncMaxRetryBackoff = 30 * time.Minute
+)
+
+// SetTemplateSyncPolicy 集群模板的自动同步策略, 每个集群模板最多一条
+type SetTemplateSyncPolicy struct {
+	BizID         int64               `field:"bk_biz_id" json:"bk_biz_id" bson:"bk_biz_id" mapstructure:"bk_biz_id"`
+	SetTemplateID int64               `field:"set_template_id" json:"set_template_id" bson:"set_template_id" mapstructure:"set_template_id"`
+	Mode          SetTemplateSyncMode `field:"mode" json:"mode" bson:"mode" mapstructure:"mode"`
+	// Cron 维护窗口的cron表达式(标准5段格式), 仅在 window 模式下生效
+	Cron string `field:"cron" json:"cron" bson:"cron" mapstructure:"cron"`
+	// KeepModulesWithHost 为true时, 自动同步不会删除仍然包含主机的模块
+	KeepModulesWithHost bool `field:"keep_modules_with_host" json:"keep_modules_with_host" bson:"keep_modules_with_host" mapstructure:"keep_modules_with_host"`
+	// BatchSize 每批次提交到task_server的集群数量, 上一批完成后才会提交下一批
+	BatchSize int64 `field:"batch_size" json:"batch_size" bson:"batch_size" mapstructure:"batch_size"`
+	// NotifyURL 同步完成后, 以POST方式推送同步摘要的回调地址, 为空时不推送
+	NotifyURL string `field:"notify_url" json:"notify_url" bson:"notify_url" mapstructure:"notify_url"`
+
+	LastSyncTime *time.Time              `field:"last_sync_time" json:"last_sync_time,omitempty" bson:"last_sync_time" mapstructure:"last_sync_time"`
+	LastSummary  *SetTemplateSyncSummary `field:"last_summary" json:"last_summary,omitempty" bson:"last_summary" mapstructure:"last_summary"`
+	// LastFailTime 最近一次自动同步失败的时间, FailCount 为连续失败的次数, 同步成功后清零
+	LastFailTime *time.Time `field:"last_fail_time" json:"last_fail_time,omitempty" bson:"last_fail_time" mapstructure:"last_fail_time"`
+	FailCount    int64      `field:"fail_count" json:"fail_count" bson:"fail_count" mapstructure:"fail_count"`
+	LastError    string     `field:"last_error" json:"last_error,omitempty" bson:"last_error" mapstructure:"last_error"`
+
+	// 通用字段
+	Creator         string    `field:"creator" json:"creator" bson:"creator" mapstructure:"creator"`
+	Modifier        string    `field:"modifier" json:"modifier" bson:"modifier" mapstructure:"modifier"`
+	CreateTime      time.Time `field:"create_time" json:"create_time" bson:"create_time" mapstructure:"create_time"`
+	LastTime        time.Time `field:"last_time" json:"last_time" bson:"last_time" mapstructure:"last_time"`
+	SupplierAccount string    `field:"bk_supplier_account" json:"bk_supplier_account" bson:"bk_supplier_account" mapstructure:"bk_supplier_account"`
+}
+
+// Validate validate policy fields, returns the invalid field name and reason.
+func (p *SetTemplateSyncPolicy) Validate() (string, error) {
+	switch p.Mode {
+	case SetTemplateSyncModeManual, SetTemplateSyncModeAuto:
+	case SetTemplateSyncModeWindow:
+		if _, err := cron.ParseStandard(p.Cron); err != nil {
+			return "cron", err
+		}
+	default:
+		return "mode", fmt.Errorf("unsupported sync mode: %s", p.Mode)
+	}
+
+	if p.BatchSize == 0 {
+		p.BatchSize = SetTemplateSyncDefaultBatchSize
+	}
+	if p.BatchSize < 0 || p.BatchSize > SetTemplateSyncMaxBatchSize {
+		return "batch_size", fmt.Errorf("batch size should be in range (0, %d]", SetTemplateSyncMaxBatchSize)
+	}
+
+	if len(p.NotifyURL) != 0 {
+		u, err := url.Parse(p.NotifyURL)
+		if err != nil {
+			return "notify_url", err
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return "notify_url", fmt.Errorf("unsupported scheme: %s", u.Scheme)
+		}
+	}
+	return "", nil
+}
+
+// NextSyncTime returns the first time the maintenance window opens after the last sync,
+// the second return value is false if the policy is not scheduled by a maintenance window.
+func (p *SetTemplateSyncPolicy) NextSyncTime() (time.Time, bool) {
+	if p.Mode != SetTemplateSyncModeWindow {
+		return time.Time{}, false
+	}
+	schedule, err := cron.ParseStandard(p.Cron)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	// a window policy never synced starts counting from the time it was configured
+	from := p.LastTime
+	if p.LastSyncTime != nil && p.LastSyncTime.After(from) {
+		from = *p.LastSyncTime
+	}
+	return schedule.Next(from), true
+}
+
+// InWindow checks if the maintenance window of the policy is open at now, the window opens at the time of the cron
+// expression and lasts for SetTemplateSyncWindowDuration.
+func (p *SetTemplateSyncPolicy) InWindow(now time.Time) bool {
+	if p.Mode != SetTemplateSyncModeWindow {
+		return false
+	}
+	schedule, err := cron.ParseStandard(p.Cron)
+	if err != nil {
+		return false
+	}
+	return !schedule.Next(now.Add(-SetTemplateSyncWindowDuration)).After(now)
+}
+
+// RetryBackoff returns the time to wait after the last failure before the next try, it doubles with each
+// consecutive failure and is limited by SetTemplateSyncMaxRetryBackoff.
+func (p *SetTemplateSyncPolicy) RetryBackoff() time.Duration {
+	if p.FailCount <= 0 {
+		return 0
+	}
+	backoff := SetTemplateSyncRetryBackoff
+	for i := int64(1); i < p.FailCount && backoff < SetTemplateSyncMaxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > SetTemplateSyncMaxRetryBackoff {
+		backoff = SetTemplateSyncMaxRetryBackoff
+	}
+	return backoff
+}
+
+// IsDue checks if the maintenance window policy should be synchronized at now, which means the window opened after
+// the last successful sync, it is still open, and the backoff after the last failure has passed.
+func (p *SetTemplateSyncPolicy) IsDue(now time.Time) bool {
+	next, ok := p.NextSyncTime()
+	if !ok || next.After(now) {
+		return false
+	}
+	if !p.InWindow(now) {
+		return false
+	}
+	if p.FailCount > 0 && p.LastFailTime != nil && p.LastFailTime.Add(p.RetryBackoff()).After(now) {
+		return false
+	}
+	return true
+}
+
+// UpdateSetTemplateSyncPolicyOption 设置集群模板的同步策略
+type UpdateSetTemplateSyncPolicyOption struct {
+	Mode                SetTemplateSyncMode `json:"mode"`
+	Cron                string              `json:"cron"`
+	KeepModulesWithHost bool                `json:"keep_modules_with_host"`
+	BatchSize           int64               `json:"batch_size"`
+	NotifyURL           string              `json:"notify_url"`
+}
+
+// ListSetTemplateSyncPolicyOption 查询集群模板同步策略, 业务ID为0时查询所有业务
+type ListSetTemplateSyncPolicyOption struct {
+	BizID          int64                 `json:"bk_biz_id"`
+	SetTemplateIDs []int64               `json:"set_template_ids"`
+	Modes          []SetTemplateSyncMode `json:"modes"`
+	Page           BasePage              `json:"page"`
+}
+
+func (option ListSetTemplateSyncPolicyOption) ToFilter() map[string]interface{} {
+	filter := make(map[string]interface{})
+	if option.BizID != 0 {
+		filter[common.BKAppIDField] = option.BizID
+	}
+	if option.SetTemplateIDs != nil {
+		filter[common.BKSetTemplateIDField] = map[string]interface{}{
+			common.BKDBIN: option.SetTemplateIDs,
+		}
+	}
+	if option.Modes != nil {
+		filter["mode"] = map[string]interface{}{
+			common.BKDBIN: option.Modes,
+		}
+	}
+	return filter
+}
+
+type MultipleSetTemplateSyncPolicy struct {
+	Count int64                   `json:"count"`
+	Info  []SetTemplateSyncPolicy `json:"info"`
+}
+
+// UpdateSetTemplateSyncResultOption 记录一次自动同步的结果, Error 不为空时表示同步失败,
+// 此时只记录失败时间, 失败次数与原因, 不更新最近同步时间, 以便在维护窗口内退避重试
+type UpdateSetTemplateSyncResultOption struct {
+	SyncTime  time.Time              `json:"sync_time"`
+	Summary   SetTemplateSyncSummary `json:"summary"`
+	Error     string                 `json:"error"`
+	FailCount int64                  `json:"fail_count"`
+}
+
+// SetTemplateSyncTrigger 触发自动同步的原因
+type SetTemplateSyncTrigger string
+
+const (
+	SetTemplateSyncTriggerTemplateChanged SetTemplateSyncTrigger = "template_changed"
+	SetTemplateSyncTriggerWindow          SetTemplateSyncTrigger = "maintenance_window"
+)
+
+// SetTemplateSyncSummary 一次自动同步中每个集群所做的变更的摘要, 用于通知
+type SetTemplateSyncSummary struct {
+	BizID              int64                  `json:"bk_biz_id" bson:"bk_biz_id"`
+	SetTemplateID      int64                  `json:"set_template_id" bson:"set_template_id"`
+	SetTemplateVersion int64                  `json:"set_template_version" bson:"set_template_version"`
+	Trigger            SetTemplateSyncTrigger `json:"trigger" bson:"trigger"`
+	StartTime          time.Time              `json:"start_time" bson:"start_time"`
+	EndTime            time.Time              `json:"end_time" bson:"end_time"`
+	Sets               []SetSyncSummary       `json:"sets" bson:"sets"`
+}
+
+type SetSyncSummary struct {
+	SetID   int64      `json:"bk_set_id" bson:"bk_set_id"`
+	SetName string     `json:"bk_set_name" bson:"bk_set_name"`
+	Status  SyncStatus `json:"status" bson:"status"`
+	TaskID  string     `json:"task_id" bson:"task_id"`
+	// Changes 已提交同步的模块变更
+	Changes []SetModuleDiff `json:"changes" bson:"changes"`
+	// Skipped 因保护策略(如模块下仍有主机)而跳过的模块变更
+	Skipped []SetModuleDiff `json:"skipped" bson:"skipped"`
+	// Message 同步失败或跳过整个集群的原因
+	Message string `json:"message,omitempty" bson:"message"`
+}
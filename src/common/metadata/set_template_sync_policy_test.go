@@ -0,0 +1,95 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSetTemplateSyncPolicyInWindow(t *testing.T) {
+	// the window opens at 02:00 every day
+	policy := SetTemplateSyncPolicy{Mode: SetTemplateSyncModeWindow, Cron: "0 2 * * *"}
+	day := time.Date(2020, 12, 1, 0, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		now  time.Time
+		want bool
+	}{
+		{now: day.Add(time.Hour), want: false},
+		{now: day.Add(2 * time.Hour), want: true},
+		{now: day.Add(2*time.Hour + 30*time.Minute), want: true},
+		{now: day.Add(3*time.Hour + time.Minute), want: false},
+	}
+	for _, tt := range tests {
+		if got := policy.InWindow(tt.now); got != tt.want {
+			t.Errorf("InWindow(%s) = %v, want %v", tt.now, got, tt.want)
+		}
+	}
+
+	auto := SetTemplateSyncPolicy{Mode: SetTemplateSyncModeAuto}
+	if auto.InWindow(day.Add(2 * time.Hour)) {
+		t.Errorf("policy not in window mode should never be in window")
+	}
+}
+
+func TestSetTemplateSyncPolicyIsDue(t *testing.T) {
+	day := time.Date(2020, 12, 1, 0, 0, 0, 0, time.Local)
+	inWindow := day.Add(2*time.Hour + 10*time.Minute)
+	policy := SetTemplateSyncPolicy{Mode: SetTemplateSyncModeWindow, Cron: "0 2 * * *", LastTime: day}
+
+	if !policy.IsDue(inWindow) {
+		t.Errorf("policy should be due in the window")
+	}
+	// the window was missed, wait for the next one instead of syncing out of the window
+	if policy.IsDue(day.Add(5 * time.Hour)) {
+		t.Errorf("policy should not be due after the window is closed")
+	}
+
+	synced := day.Add(2*time.Hour + 5*time.Minute)
+	policy.LastSyncTime = &synced
+	if policy.IsDue(inWindow) {
+		t.Errorf("policy synchronized in the window should not be due again")
+	}
+
+	// failed one minute ago, the first retry waits for one minute
+	policy.LastSyncTime = nil
+	failed := inWindow.Add(-30 * time.Second)
+	policy.LastFailTime = &failed
+	policy.FailCount = 1
+	if policy.IsDue(inWindow) {
+		t.Errorf("policy should not be retried before the backoff passes")
+	}
+	if !policy.IsDue(inWindow.Add(time.Minute)) {
+		t.Errorf("policy should be retried after the backoff passes")
+	}
+}
+
+func TestSetTemplateSyncPolicyRetryBackoff(t *testing.T) {
+	tests := []struct {
+		failCount int64
+		want      time.Duration
+	}{
+		{failCount: 0, want: 0},
+		{failCount: 1, want: time.Minute},
+		{failCount: 2, want: 2 * time.Minute},
+		{failCount: 4, want: 8 * time.Minute},
+		{failCount: 100, want: SetTemplateSyncMaxRetryBackoff},
+	}
+	for _, tt := range tests {
+		policy := SetTemplateSyncPolicy{FailCount: tt.failCount}
+		if got := policy.RetryBackoff(); got != tt.want {
+			t.Errorf("RetryBackoff with %d failures = %s, want %s", tt.failCount, got, tt.want)
+		}
+	}
+}
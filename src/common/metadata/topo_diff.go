@@ -0,0 +1,102 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metadata
+
+import (
+	"errors"
+	"time"
+
+	"configcenter/src/common"
+)
+
+// TopoDiffRequest 业务拓扑差异比较请求, TargetBizID 与 Time 二选一:
+// TargetBizID 与另一个业务的当前拓扑比较, Time 与本业务在该时刻的拓扑比较, 历史拓扑由审计记录回放得到
+type TopoDiffRequest struct {
+	TargetBizID int64 `json:"target_biz_id"`
+	// Time 格式为 2006-01-02 15:04:05, 服务器本地时间
+	Time string `json:"time"`
+}
+
+// Validate validate topology diff request
+func (r *TopoDiffRequest) Validate() (string, error) {
+	if r.TargetBizID < 0 {
+		return "target_biz_id", errors.New("target_biz_id should be positive")
+	}
+	if r.TargetBizID == 0 && r.Time == "" {
+		return "target_biz_id", errors.New("one of target_biz_id and time must be set")
+	}
+	if r.TargetBizID != 0 && r.Time != "" {
+		return "time", errors.New("target_biz_id and time can not be set at the same time")
+	}
+	if r.Time != "" {
+		t, err := r.ParseTime()
+		if err != nil {
+			return "time", err
+		}
+		if t.After(time.Now()) {
+			return "time", errors.New("time can not be later than now")
+		}
+	}
+	return "", nil
+}
+
+// ParseTime parse the time to compare with
+func (r *TopoDiffRequest) ParseTime() (time.Time, error) {
+	return time.ParseInLocation(common.TimeTransferModel, r.Time, time.Local)
+}
+
+// TopoDiffNode 参与比较的主线拓扑节点, Path 为从业务下一层到该节点的实例名称
+type TopoDiffNode struct {
+	ObjectID            string   `json:"bk_obj_id"`
+	InstID              int64    `json:"bk_inst_id"`
+	InstName            string   `json:"bk_inst_name"`
+	Path                []string `json:"path"`
+	Default             int      `json:"default"`
+	HostCount           int64    `json:"host_count"`
+	ServiceTemplateID   int64    `json:"service_template_id,omitempty"`
+	ServiceTemplateName string   `json:"service_template_name,omitempty"`
+	SetTemplateID       int64    `json:"set_template_id,omitempty"`
+	SetTemplateName     string   `json:"set_template_name,omitempty"`
+}
+
+// TopoNodeChange 两侧都存在但内容不同的节点, Fields 为发生变化的字段
+type TopoNodeChange struct {
+	Fields []string     `json:"fields"`
+	Base   TopoDiffNode `json:"base"`
+	Target TopoDiffNode `json:"target"`
+}
+
+// TopoDiffSide 比较的一侧, Time 为空表示当前拓扑
+type TopoDiffSide struct {
+	BizID int64  `json:"bk_biz_id"`
+	Time  string `json:"time,omitempty"`
+}
+
+// TopoDiffResult 拓扑差异, Added 为仅存在于 Target 中的节点, Removed 为仅存在于 Base 中的节点.
+// 业务间比较时 Base 为当前业务, 节点按层级与名称路径匹配, 模板按名称比较;
+// 与历史时刻比较时 Base 为历史拓扑, Target 为当前拓扑, 节点按实例ID匹配
+type TopoDiffResult struct {
+	Base    TopoDiffSide     `json:"base"`
+	Target  TopoDiffSide     `json:"target"`
+	Added   []TopoDiffNode   `json:"added"`
+	Removed []TopoDiffNode   `json:"removed"`
+	Changed []TopoNodeChange `json:"changed"`
+	// ReplayedAuditCount 重建历史拓扑时回放的审计记录数
+	ReplayedAuditCount int64 `json:"replayed_audit_count,omitempty"`
+}
+
+// TopoDiffResponse topology diff response
+type TopoDiffResponse struct {
+	BaseResp `json:",inline"`
+	Data     TopoDiffResult `json:"data"`
+}
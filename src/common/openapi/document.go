@@ -0,0 +1,291 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+
+	"github.com/emicklei/go-restful"
+)
+
+// MIMEJSON is the content type of all the cmdb apis
+const MIMEJSON = "application/json"
+
+// Description describes how the document is generated
+const Description = "this document is generated from the registered routes, the request bodies and the response " +
+	"data are described by the go types the apis decode and respond."
+
+// NewDocument create an empty document
+func NewDocument(title, version string) *Document {
+	return &Document{
+		OpenAPI: Version,
+		Info: Info{
+			Title:       title,
+			Description: Description,
+			Version:     version,
+		},
+		Paths: make(map[string]*PathItem),
+		Components: Components{
+			Schemas: make(map[string]*Schema),
+		},
+		types: make(map[string]reflect.Type),
+	}
+}
+
+// pathParamRegexp matches the path parameter of go-restful, which may have a regexp like {name:*}
+var pathParamRegexp = regexp.MustCompile(`\{([^{}:]+)(:[^{}]*)?\}`)
+
+// NormalizePath removes the regexp of the path parameters and the duplicate slashes of the go-restful route path,
+// returns the OpenAPI path template and the path parameter names.
+func NormalizePath(routePath string) (string, []string) {
+	for strings.Contains(routePath, "//") {
+		routePath = strings.Replace(routePath, "//", "/", -1)
+	}
+	if len(routePath) > 1 {
+		routePath = strings.TrimSuffix(routePath, "/")
+	}
+
+	params := make([]string, 0)
+	normalized := pathParamRegexp.ReplaceAllStringFunc(routePath, func(param string) string {
+		name := strings.TrimSpace(pathParamRegexp.FindStringSubmatch(param)[1])
+		params = append(params, name)
+		return "{" + name + "}"
+	})
+	return normalized, params
+}
+
+// AddRoutes add the routes to the document with the tag, which is usually the name of the server.
+// the request and response schemas are generated from the samples of Reads and Writes of the route.
+func (d *Document) AddRoutes(tag string, routes []restful.Route) error {
+	for _, route := range routes {
+		routePath, params := NormalizePath(route.Path)
+
+		item, exist := d.Paths[routePath]
+		if !exist {
+			item = new(PathItem)
+		}
+
+		operation := item.operation(route.Method)
+		if operation == nil {
+			return fmt.Errorf("route %s %s has unsupported method", route.Method, route.Path)
+		}
+		if *operation != nil {
+			return fmt.Errorf("route %s %s is duplicated", route.Method, route.Path)
+		}
+
+		*operation = d.newOperation(tag, route, params)
+		d.Paths[routePath] = item
+	}
+
+	d.AddTag(tag)
+	return nil
+}
+
+// AddTag add the tag to the document if not exists
+func (d *Document) AddTag(tag string) {
+	for _, t := range d.Tags {
+		if t.Name == tag {
+			return
+		}
+	}
+	d.Tags = append(d.Tags, Tag{Name: tag})
+}
+
+func (d *Document) newOperation(tag string, route restful.Route, params []string) *Operation {
+	operation := &Operation{
+		Tags:        []string{tag},
+		Summary:     route.Doc,
+		Description: route.Notes,
+		OperationID: d.uniqueOperationID(tag + "." + route.Operation),
+		Parameters:  make([]*Parameter, 0),
+		Responses: map[string]*Response{
+			"200": {
+				Description: "the result is false with bk_error_code and bk_error_msg if failed",
+				Content: map[string]*MediaType{
+					MIMEJSON: {Schema: d.responseSchema(route.WriteSample)},
+				},
+			},
+		},
+		Deprecated: route.Deprecated,
+	}
+	if len(operation.Summary) == 0 {
+		operation.Summary = route.Operation
+	}
+
+	for _, name := range params {
+		operation.Parameters = append(operation.Parameters, &Parameter{
+			Name:     name,
+			In:       InPath,
+			Required: true,
+			Schema:   &Schema{Type: "string"},
+		})
+	}
+
+	for _, param := range route.ParameterDocs {
+		data := param.Data()
+		var in string
+		switch data.Kind {
+		case restful.QueryParameterKind:
+			in = InQuery
+		case restful.HeaderParameterKind:
+			in = InHeader
+		default:
+			// path parameters are parsed from the path, body is generated with the read sample.
+			continue
+		}
+
+		schema := &Schema{Type: data.DataType}
+		if len(schema.Type) == 0 {
+			schema.Type = "string"
+		}
+		operation.Parameters = append(operation.Parameters, &Parameter{
+			Name:        data.Name,
+			In:          in,
+			Description: data.Description,
+			Required:    data.Required,
+			Schema:      schema,
+		})
+	}
+
+	switch {
+	case isEmptyBody(route.ReadSample):
+		// all the parameters of the route are in the path, it does not read the request body.
+	case route.ReadSample != nil:
+		operation.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]*MediaType{MIMEJSON: {Schema: d.SchemaOf(route.ReadSample)}},
+		}
+	case route.Method == http.MethodPost || route.Method == http.MethodPut:
+		// the request of the route is not described, it can be any json object, the routes of the topo and host
+		// servers are required to describe their requests by the tests.
+		operation.RequestBody = &RequestBody{
+			Content: map[string]*MediaType{MIMEJSON: {Schema: &Schema{Type: "object"}}},
+		}
+	}
+
+	return operation
+}
+
+// isEmptyBody checks if the request sample means the route does not read the request body
+func isEmptyBody(sample interface{}) bool {
+	_, ok := sample.(rest.EmptyBody)
+	return ok
+}
+
+// responseSchema returns the schema of the response, which is the data wrapped by the base response.
+func (d *Document) responseSchema(sample interface{}) *Schema {
+	return &Schema{
+		AllOf: []*Schema{
+			d.SchemaOf(metadata.BaseResp{}),
+			{
+				Type:       "object",
+				Properties: map[string]*Schema{"data": d.SchemaOf(sample)},
+			},
+		},
+	}
+}
+
+// uniqueOperationID returns the operation id, a same handler may be used by several routes,
+// so the index is added to the duplicate operation id.
+func (d *Document) uniqueOperationID(id string) string {
+	ids := make(map[string]bool)
+	for _, item := range d.Paths {
+		for _, operation := range item.Operations() {
+			ids[operation.OperationID] = true
+		}
+	}
+
+	unique := id
+	for idx := 2; ids[unique]; idx++ {
+		unique = fmt.Sprintf("%s%d", id, idx)
+	}
+	return unique
+}
+
+// Merge add the paths and components of the source document to this document, the path of the source document
+// is converted by the rewrite function, and the path is skipped if it returns false. the conflicted operations
+// are skipped and returned with error, the components with same name are defined by the same go type, so the
+// existing component is used.
+func (d *Document) Merge(src *Document, rewrite func(path string) (string, bool)) error {
+	conflicts := make([]string, 0)
+	for srcPath, srcItem := range src.Paths {
+		dstPath, ok := rewrite(srcPath)
+		if !ok {
+			continue
+		}
+
+		item, exist := d.Paths[dstPath]
+		if !exist {
+			item = new(PathItem)
+		}
+		for method, srcOperation := range srcItem.Operations() {
+			operation := item.operation(method)
+			if *operation != nil {
+				conflicts = append(conflicts, method+" "+dstPath)
+				continue
+			}
+			*operation = srcOperation
+		}
+		d.Paths[dstPath] = item
+	}
+
+	for name, schema := range src.Components.Schemas {
+		if _, exist := d.Components.Schemas[name]; !exist {
+			d.Components.Schemas[name] = schema
+		}
+	}
+
+	for _, tag := range src.Tags {
+		d.AddTag(tag.Name)
+	}
+
+	if len(conflicts) != 0 {
+		return fmt.Errorf("conflict operations: %s", strings.Join(conflicts, ", "))
+	}
+	return nil
+}
+
+// ContainerDocument generates the document of the routes in the container, the web services without root path
+// such as the healthz service are skipped.
+func ContainerDocument(container *restful.Container, tag, version string) (*Document, error) {
+	doc := NewDocument(tag, version)
+	for _, ws := range container.RegisteredWebServices() {
+		if len(strings.Trim(ws.RootPath(), "/")) == 0 {
+			continue
+		}
+
+		if err := doc.AddRoutes(tag, ws.Routes()); err != nil {
+			return nil, err
+		}
+	}
+	return doc, nil
+}
+
+// RouteFunction returns the route function which responses the document of the routes in the container
+func RouteFunction(container *restful.Container, tag, version string) restful.RouteFunction {
+	return func(req *restful.Request, resp *restful.Response) {
+		doc, err := ContainerDocument(container, tag, version)
+		if err != nil {
+			resp.WriteError(http.StatusInternalServerError, err)
+			return
+		}
+		resp.WriteAsJson(doc)
+	}
+}
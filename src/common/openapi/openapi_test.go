@@ -0,0 +1,162 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openapi
+
+import (
+	"strings"
+	"testing"
+
+	"configcenter/src/common/metadata"
+
+	"github.com/emicklei/go-restful"
+)
+
+type testNode struct {
+	metadata.BasePage `json:",inline"`
+	Name              string      `json:"name"`
+	Children          []*testNode `json:"children"`
+	Ignored           string      `json:"-"`
+	CreateTime        metadata.Time
+	internal          string
+}
+
+func testHandler(req *restful.Request, resp *restful.Response) {}
+
+func TestAddRoutes(t *testing.T) {
+	ws := new(restful.WebService).Path("/topo/v3/")
+	ws.Route(ws.POST("/find/node/{bk_biz_id}").To(testHandler).Reads(testNode{}).Writes([]testNode{}))
+	ws.Route(ws.GET("/find/node/{bk_biz_id}/{name:*}").To(testHandler).
+		Param(ws.QueryParameter("limit", "page limit").DataType("integer")))
+	ws.Route(ws.DELETE("/delete/node").To(testHandler))
+
+	doc := NewDocument("test", "v3")
+	if err := doc.AddRoutes("topo", ws.Routes()); err != nil {
+		t.Fatalf("add routes failed, err: %v", err)
+	}
+	if err := Validate(doc); err != nil {
+		t.Fatalf("validate document failed, err: %v", err)
+	}
+
+	post := doc.Paths["/topo/v3/find/node/{bk_biz_id}"].Post
+	if post == nil || post.OperationID != "topo.testHandler" {
+		t.Fatalf("invalid post operation: %#v", post)
+	}
+	if ref := post.RequestBody.Content[MIMEJSON].Schema.Ref; ref != ComponentRefPrefix+"openapi.testNode" {
+		t.Fatalf("invalid request body schema ref: %s", ref)
+	}
+
+	get := doc.Paths["/topo/v3/find/node/{bk_biz_id}/{name}"].Get
+	if get == nil || get.OperationID != "topo.testHandler2" {
+		t.Fatalf("invalid get operation: %#v", get)
+	}
+	if len(get.Parameters) != 3 || get.Parameters[2].In != InQuery || get.Parameters[2].Schema.Type != "integer" {
+		t.Fatalf("invalid get parameters: %#v", get.Parameters)
+	}
+	if get.RequestBody != nil {
+		t.Fatalf("get operation should not have request body")
+	}
+
+	node := doc.Components.Schemas["openapi.testNode"]
+	for _, property := range []string{"start", "limit", "sort", "cursor", "name", "children", "CreateTime"} {
+		if _, exist := node.Properties[property]; !exist {
+			t.Fatalf("property %s of test node is not generated, properties: %v", property, node.Properties)
+		}
+	}
+	if len(node.Properties) != 7 {
+		t.Fatalf("test node should have 7 properties, got %d", len(node.Properties))
+	}
+	if ref := node.Properties["children"].Items.Ref; ref != ComponentRefPrefix+"openapi.testNode" {
+		t.Fatalf("recursive children should refer to test node, got %s", ref)
+	}
+	if node.Properties["CreateTime"].Type != "string" {
+		t.Fatalf("time should be string, got %s", node.Properties["CreateTime"].Type)
+	}
+}
+
+func TestAddDuplicateRoutes(t *testing.T) {
+	ws := new(restful.WebService).Path("/topo/v3")
+	ws.Route(ws.POST("/find/node").To(testHandler))
+	ws.Route(ws.POST("/find//node/").To(testHandler))
+
+	if err := NewDocument("test", "v3").AddRoutes("topo", ws.Routes()); err == nil {
+		t.Fatalf("duplicate routes should be failed")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	doc := NewDocument("test", "v3")
+	doc.Paths["/find/{id}"] = &PathItem{
+		Post: &Operation{
+			OperationID: "find",
+			Parameters:  []*Parameter{{Name: "name", In: InPath, Required: true, Schema: &Schema{Type: "string"}}},
+			Responses: map[string]*Response{"200": {
+				Description: "success",
+				Content:     map[string]*MediaType{MIMEJSON: {Schema: &Schema{Ref: ComponentRefPrefix + "not_exist"}}},
+			}},
+		},
+	}
+	doc.Paths["/find/{name}"] = &PathItem{
+		Get: &Operation{OperationID: "find", Responses: map[string]*Response{"200": {Description: "success"}}},
+	}
+
+	err := Validate(doc)
+	if err == nil {
+		t.Fatalf("invalid document should be failed")
+	}
+
+	for _, problem := range []string{
+		"POST /find/{id} path parameter id is not declared",
+		"POST /find/{id} path parameter name is not in the path",
+		"refers to undefined schema",
+		"path /find/{name} is identical with /find/{id}",
+		"GET /find/{name} has the same operation id find",
+		"GET /find/{name} path parameter name is not declared",
+	} {
+		if !strings.Contains(err.Error(), problem) {
+			t.Errorf("problem %q is not found in %v", problem, err)
+		}
+	}
+}
+
+func TestMerge(t *testing.T) {
+	ws := new(restful.WebService).Path("/host/v3")
+	ws.Route(ws.POST("/hosts/search").To(testHandler).Reads(testNode{}))
+	ws.Route(ws.POST("/internal/search").To(testHandler))
+
+	src := NewDocument("host", "v3")
+	if err := src.AddRoutes("host", ws.Routes()); err != nil {
+		t.Fatalf("add routes failed, err: %v", err)
+	}
+
+	rewrite := func(path string) (string, bool) {
+		if strings.HasPrefix(path, "/host/v3/internal") {
+			return "", false
+		}
+		return strings.Replace(path, "/host/v3", "/api/v3", 1), true
+	}
+
+	doc := NewDocument("cmdb", "v3")
+	if err := doc.Merge(src, rewrite); err != nil {
+		t.Fatalf("merge document failed, err: %v", err)
+	}
+	if len(doc.Paths) != 1 || doc.Paths["/api/v3/hosts/search"] == nil {
+		t.Fatalf("invalid merged paths: %v", doc.Paths)
+	}
+	if err := Validate(doc); err != nil {
+		t.Fatalf("validate merged document failed, err: %v", err)
+	}
+
+	if err := doc.Merge(src, rewrite); err == nil {
+		t.Fatalf("merge the conflict document should be failed")
+	}
+}
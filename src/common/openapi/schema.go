@@ -0,0 +1,179 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"path"
+	"reflect"
+	"strings"
+	"time"
+
+	"configcenter/src/common/metadata"
+)
+
+// ComponentRefPrefix is the prefix of the $ref to the component schemas
+const ComponentRefPrefix = "#/components/schemas/"
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	ccTimeType        = reflect.TypeOf(metadata.Time{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// SchemaOf returns the schema of the sample, the named structs are added to the components of the document
+// and referenced with $ref, so that the same struct is defined only once.
+func (d *Document) SchemaOf(sample interface{}) *Schema {
+	if sample == nil {
+		return new(Schema)
+	}
+	return d.schemaOfType(reflect.TypeOf(sample))
+}
+
+func (d *Document) schemaOfType(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == ccTimeType:
+		return &Schema{Type: "string", Description: "time with format 2006-01-02 15:04:05"}
+	case implements(t, textMarshalerType):
+		return &Schema{Type: "string"}
+	case implements(t, jsonMarshalerType):
+		// the json format is decided by the type itself, can be any value.
+		return new(Schema)
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: d.schemaOfType(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: d.schemaOfType(t.Elem())}
+	case reflect.Struct:
+		if len(t.Name()) == 0 {
+			return d.structSchema(t)
+		}
+		return &Schema{Ref: ComponentRefPrefix + d.addComponent(t)}
+	default:
+		// interface and the types can not be encoded to json, such as chan and func.
+		return new(Schema)
+	}
+}
+
+func implements(t, iface reflect.Type) bool {
+	return t.Implements(iface) || reflect.PtrTo(t).Implements(iface)
+}
+
+// addComponent add the struct to the component schemas if not exists, returns the component name.
+func (d *Document) addComponent(t reflect.Type) string {
+	name := componentName(t, 0)
+	for idx := 2; ; idx++ {
+		exist, ok := d.types[name]
+		if !ok {
+			break
+		}
+		if exist == t {
+			return name
+		}
+		// the structs with same name in different packages.
+		name = componentName(t, idx)
+	}
+
+	// register the component before generating the properties, so that the recursive struct can refer to itself.
+	d.types[name] = t
+	schema := new(Schema)
+	d.Components.Schemas[name] = schema
+	*schema = *d.structSchema(t)
+	return name
+}
+
+func componentName(t reflect.Type, idx int) string {
+	name := path.Base(t.PkgPath()) + "." + t.Name()
+	if idx > 0 {
+		name = fmt.Sprintf("%s%d", name, idx)
+	}
+	return name
+}
+
+func (d *Document) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	d.addFields(schema, t)
+	return schema
+}
+
+// addFields add the fields of the struct to the properties as the json encoding does, the embedded structs
+// without json name and the inline fields are flattened.
+func (d *Document) addFields(schema *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		opts := strings.Split(tag, ",")
+		name := opts[0]
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+
+		isStruct := fieldType.Kind() == reflect.Struct && !implements(fieldType, jsonMarshalerType) &&
+			!implements(fieldType, textMarshalerType)
+		if len(name) == 0 && isStruct && (field.Anonymous || hasOption(opts[1:], "inline")) {
+			d.addFields(schema, fieldType)
+			continue
+		}
+
+		// unexported field is not encoded.
+		if len(field.PkgPath) != 0 {
+			continue
+		}
+
+		if len(name) == 0 {
+			name = field.Name
+		}
+		schema.Properties[name] = d.schemaOfType(field.Type)
+	}
+}
+
+func hasOption(opts []string, option string) bool {
+	for _, opt := range opts {
+		if opt == option {
+			return true
+		}
+	}
+	return false
+}
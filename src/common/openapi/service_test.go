@@ -0,0 +1,111 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openapi_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"configcenter/src/common/backbone"
+	"configcenter/src/common/openapi"
+	"configcenter/src/common/types"
+	cloudsvc "configcenter/src/scene_server/cloud_server/service"
+	eventsvc "configcenter/src/scene_server/event_server/service"
+	hostsvc "configcenter/src/scene_server/host_server/service"
+	operationsvc "configcenter/src/scene_server/operation_server/service"
+	procsvc "configcenter/src/scene_server/proc_server/service"
+	tasksvc "configcenter/src/scene_server/task_server/service"
+	toposvc "configcenter/src/scene_server/topo_server/service"
+
+	"github.com/emicklei/go-restful"
+)
+
+// TestServiceDocuments make sure the routes of the servers can generate valid openapi documents
+func TestServiceDocuments(t *testing.T) {
+	cases := []struct {
+		tag       string
+		container func() *restful.Container
+		// requireRequest requires all the POST and PUT routes to describe their requests
+		requireRequest bool
+	}{
+		{
+			tag:            types.CC_MODULE_TOPO,
+			container:      (&toposvc.Service{Engine: new(backbone.Engine)}).WebService,
+			requireRequest: true,
+		},
+		{
+			tag:            types.CC_MODULE_HOST,
+			container:      (&hostsvc.Service{Engine: new(backbone.Engine)}).WebService,
+			requireRequest: true,
+		},
+		{
+			tag:            types.CC_MODULE_EVENTSERVER,
+			container:      eventsvc.NewService(context.Background(), new(backbone.Engine)).WebService,
+			requireRequest: true,
+		},
+		{
+			tag:            types.CC_MODULE_PROC,
+			container:      (&procsvc.ProcServer{Engine: new(backbone.Engine)}).WebService,
+			requireRequest: true,
+		},
+		{
+			tag: types.CC_MODULE_CLOUD,
+			container: func() *restful.Container {
+				s := cloudsvc.NewService(context.Background())
+				s.Engine = new(backbone.Engine)
+				return s.WebService()
+			},
+			requireRequest: true,
+		},
+		{
+			tag:            types.CC_MODULE_OPERATION,
+			container:      (&operationsvc.OperationServer{Engine: new(backbone.Engine)}).WebService,
+			requireRequest: true,
+		},
+		{
+			tag:            types.CC_MODULE_TASK,
+			container:      (&tasksvc.Service{Engine: new(backbone.Engine)}).WebService,
+			requireRequest: true,
+		},
+	}
+
+	for _, c := range cases {
+		container := c.container()
+		if c.requireRequest {
+			for _, ws := range container.RegisteredWebServices() {
+				for _, route := range ws.Routes() {
+					if route.Method != http.MethodPost && route.Method != http.MethodPut {
+						continue
+					}
+					if route.ReadSample == nil {
+						t.Errorf("%s route %s %s has no request schema", c.tag, route.Method, route.Path)
+					}
+				}
+			}
+		}
+
+		doc, err := openapi.ContainerDocument(container, c.tag, "test")
+		if err != nil {
+			t.Errorf("generate %s openapi document failed, err: %v", c.tag, err)
+			continue
+		}
+		if len(doc.Paths) == 0 {
+			t.Errorf("%s openapi document has no paths", c.tag)
+			continue
+		}
+		if err := openapi.Validate(doc); err != nil {
+			t.Errorf("validate %s openapi document failed, err: %v", c.tag, err)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package openapi
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var componentNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9.\-_]+$`)
+
+// Validate checks the document with the rules of OpenAPI 3.0 used by cmdb, returns all the problems found.
+func Validate(doc *Document) error {
+	v := &validator{doc: doc, visited: make(map[*Schema]bool)}
+	v.validate()
+	if len(v.problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("invalid openapi document: %s", strings.Join(v.problems, "; "))
+}
+
+type validator struct {
+	doc      *Document
+	problems []string
+	visited  map[*Schema]bool
+}
+
+func (v *validator) addProblem(format string, args ...interface{}) {
+	v.problems = append(v.problems, fmt.Sprintf(format, args...))
+}
+
+func (v *validator) validate() {
+	if !strings.HasPrefix(v.doc.OpenAPI, "3.0.") {
+		v.addProblem("unsupported openapi version %q", v.doc.OpenAPI)
+	}
+	if len(v.doc.Info.Title) == 0 || len(v.doc.Info.Version) == 0 {
+		v.addProblem("info title and version must be set")
+	}
+
+	// validate in a stable sequence, so that the problems are in the same order.
+	paths := make([]string, 0, len(v.doc.Paths))
+	for p := range v.doc.Paths {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	operationIDs := make(map[string]string)
+	templates := make(map[string]string)
+	for _, p := range paths {
+		if !strings.HasPrefix(p, "/") {
+			v.addProblem("path %s must start with /", p)
+		}
+
+		// the paths with same template but different parameter names are identical.
+		template := pathParamRegexp.ReplaceAllString(p, "{}")
+		if exist, ok := templates[template]; ok {
+			v.addProblem("path %s is identical with %s", p, exist)
+		}
+		templates[template] = p
+
+		_, params := NormalizePath(p)
+		operations := v.doc.Paths[p].Operations()
+		if len(operations) == 0 {
+			v.addProblem("path %s has no operation", p)
+		}
+
+		methods := make([]string, 0, len(operations))
+		for method := range operations {
+			methods = append(methods, method)
+		}
+		sort.Strings(methods)
+
+		for _, method := range methods {
+			operation := operations[method]
+			name := method + " " + p
+
+			if len(operation.OperationID) == 0 {
+				v.addProblem("%s has no operation id", name)
+			} else if exist, ok := operationIDs[operation.OperationID]; ok {
+				v.addProblem("%s has the same operation id %s with %s", name, operation.OperationID, exist)
+			} else {
+				operationIDs[operation.OperationID] = name
+			}
+
+			v.validateParameters(name, params, operation.Parameters)
+
+			if operation.RequestBody != nil {
+				v.validateContent(name+" request body", operation.RequestBody.Content)
+			}
+
+			if len(operation.Responses) == 0 {
+				v.addProblem("%s has no response", name)
+			}
+			for code, response := range operation.Responses {
+				if len(response.Description) == 0 {
+					v.addProblem("%s response %s has no description", name, code)
+				}
+				v.validateContent(name+" response "+code, response.Content)
+			}
+		}
+	}
+
+	for name, schema := range v.doc.Components.Schemas {
+		if !componentNameRegexp.MatchString(name) {
+			v.addProblem("invalid component name %s", name)
+		}
+		v.validateSchema("component "+name, schema)
+	}
+}
+
+func (v *validator) validateParameters(name string, pathParams []string, parameters []*Parameter) {
+	declared := make(map[string]bool)
+	for _, param := range parameters {
+		if len(param.Name) == 0 {
+			v.addProblem("%s has parameter without name", name)
+			continue
+		}
+
+		key := param.In + ":" + param.Name
+		if declared[key] {
+			v.addProblem("%s has duplicate %s parameter %s", name, param.In, param.Name)
+		}
+		declared[key] = true
+
+		switch param.In {
+		case InPath:
+			if !param.Required {
+				v.addProblem("%s path parameter %s must be required", name, param.Name)
+			}
+		case InQuery, InHeader:
+		default:
+			v.addProblem("%s parameter %s has invalid location %s", name, param.Name, param.In)
+		}
+
+		if param.Schema == nil {
+			v.addProblem("%s parameter %s has no schema", name, param.Name)
+			continue
+		}
+		v.validateSchema(name+" parameter "+param.Name, param.Schema)
+	}
+
+	for _, param := range pathParams {
+		if !declared[InPath+":"+param] {
+			v.addProblem("%s path parameter %s is not declared", name, param)
+		}
+		delete(declared, InPath+":"+param)
+	}
+
+	for key := range declared {
+		if strings.HasPrefix(key, InPath+":") {
+			v.addProblem("%s path parameter %s is not in the path", name, strings.TrimPrefix(key, InPath+":"))
+		}
+	}
+}
+
+func (v *validator) validateContent(name string, content map[string]*MediaType) {
+	for mime, media := range content {
+		if media == nil || media.Schema == nil {
+			v.addProblem("%s %s has no schema", name, mime)
+			continue
+		}
+		v.validateSchema(name, media.Schema)
+	}
+}
+
+func (v *validator) validateSchema(name string, schema *Schema) {
+	if schema == nil || v.visited[schema] {
+		return
+	}
+	v.visited[schema] = true
+
+	if len(schema.Ref) != 0 {
+		component := strings.TrimPrefix(schema.Ref, ComponentRefPrefix)
+		if _, exist := v.doc.Components.Schemas[component]; !exist || component == schema.Ref {
+			v.addProblem("%s refers to undefined schema %s", name, schema.Ref)
+		}
+		return
+	}
+
+	switch schema.Type {
+	case "", "object", "string", "integer", "number", "boolean":
+	case "array":
+		if schema.Items == nil {
+			v.addProblem("%s is an array without items", name)
+		}
+	default:
+		v.addProblem("%s has invalid type %s", name, schema.Type)
+	}
+
+	v.validateSchema(name, schema.Items)
+	v.validateSchema(name, schema.AdditionalProperties)
+	for _, sub := range schema.AllOf {
+		v.validateSchema(name, sub)
+	}
+	for property, sub := range schema.Properties {
+		v.validateSchema(name+"."+property, sub)
+	}
+}
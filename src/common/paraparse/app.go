@@ -20,6 +20,7 @@ import (
 	"configcenter/src/common"
 	"configcenter/src/common/mapstr"
 	"configcenter/src/common/metadata"
+	"configcenter/src/common/querybuilder"
 )
 
 // common search struct
@@ -27,6 +28,8 @@ type SearchParams struct {
 	Condition map[string]interface{} `json:"condition"`
 	Page      map[string]interface{} `json:"page,omitempty"`
 	Fields    []string               `json:"fields,omitempty"`
+	// Filter 结构化的过滤条件, 会根据模型字段校验后与condition取交集
+	Filter *querybuilder.QueryFilter `json:"filter,omitempty"`
 }
 
 func ParseCommonParams(input []metadata.ConditionItem, output map[string]interface{}) error {
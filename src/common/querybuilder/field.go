@@ -0,0 +1,161 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package querybuilder
+
+import (
+	"fmt"
+	"reflect"
+
+	"configcenter/src/common"
+)
+
+// FieldTypeFunc returns the property type of the field, such as common.FieldTypeInt,
+// returns false if the field can not be used in the filter.
+type FieldTypeFunc func(field string) (propertyType string, exists bool)
+
+// FieldTypeFuncFromMap build a FieldTypeFunc from the field to property type map
+func FieldTypeFuncFromMap(fieldTypes map[string]string) FieldTypeFunc {
+	return func(field string) (string, bool) {
+		propertyType, exists := fieldTypes[field]
+		return propertyType, exists
+	}
+}
+
+var (
+	// 所有类型的字段都支持的操作符
+	commonOperators = []Operator{OperatorExist, OperatorNotExist, OperatorIsNull, OperatorIsNotNull}
+
+	numericOperators = []Operator{OperatorEqual, OperatorNotEqual, OperatorIn, OperatorNotIn, OperatorLess,
+		OperatorLessOrEqual, OperatorGreater, OperatorGreaterOrEqual}
+
+	stringOperators = []Operator{OperatorEqual, OperatorNotEqual, OperatorIn, OperatorNotIn, OperatorBeginsWith,
+		OperatorNotBeginsWith, OperatorContains, OperatorNotContains, OperatorsEndsWith, OperatorNotEndsWith}
+
+	datetimeOperators = []Operator{OperatorDatetimeLess, OperatorDatetimeLessOrEqual, OperatorDatetimeGreater,
+		OperatorDatetimeGreaterOrEqual}
+
+	boolOperators = []Operator{OperatorEqual, OperatorNotEqual}
+
+	arrayOperators = []Operator{OperatorEqual, OperatorNotEqual, OperatorIn, OperatorNotIn, OperatorIsEmpty,
+		OperatorIsNotEmpty}
+)
+
+// fieldTypeRule is the operators and the value type that a property type supports
+type fieldTypeRule struct {
+	operators []Operator
+	// valueType is the type of the value or the elements of the value for in/not_in operators
+	valueType string
+}
+
+var fieldTypeRules = map[string]fieldTypeRule{
+	common.FieldTypeInt:          {operators: numericOperators, valueType: TypeNumeric},
+	common.FieldTypeFloat:        {operators: numericOperators, valueType: TypeNumeric},
+	common.FieldTypeSingleChar:   {operators: stringOperators, valueType: TypeString},
+	common.FieldTypeLongChar:     {operators: stringOperators, valueType: TypeString},
+	common.FieldTypeEnum:         {operators: stringOperators, valueType: TypeString},
+	common.FieldTypeList:         {operators: stringOperators, valueType: TypeString},
+	common.FieldTypeUser:         {operators: stringOperators, valueType: TypeString},
+	common.FieldTypeTimeZone:     {operators: stringOperators, valueType: TypeString},
+	common.FieldTypeDate:         {operators: stringOperators, valueType: TypeString},
+	common.FieldTypeTime:         {operators: datetimeOperators, valueType: TypeString},
+	common.FieldTypeBool:         {operators: boolOperators, valueType: TypeBoolean},
+	common.FieldTypeOrganization: {operators: arrayOperators, valueType: TypeNumeric},
+}
+
+// ValidateFields validates the fields of the rule exist, and the operators and values match the field types.
+func ValidateFields(rule Rule, typeOf FieldTypeFunc) (string, error) {
+	switch r := rule.(type) {
+	case AtomRule:
+		return r.validateFieldType(typeOf)
+	case CombinedRule:
+		for idx, child := range r.Rules {
+			if key, err := ValidateFields(child, typeOf); err != nil {
+				return fmt.Sprintf("rules[%d].%s", idx, key), err
+			}
+		}
+		return "", nil
+	default:
+		return "", fmt.Errorf("unexpected rule type: %v", reflect.TypeOf(rule))
+	}
+}
+
+// RuleFields returns all the fields used in the rule.
+func RuleFields(rule Rule) []string {
+	switch r := rule.(type) {
+	case AtomRule:
+		return []string{r.Field}
+	case CombinedRule:
+		fields := make([]string, 0)
+		for _, child := range r.Rules {
+			fields = append(fields, RuleFields(child)...)
+		}
+		return fields
+	default:
+		return nil
+	}
+}
+
+func (r AtomRule) validateFieldType(typeOf FieldTypeFunc) (string, error) {
+	propertyType, exists := typeOf(r.Field)
+	if !exists {
+		return "field", fmt.Errorf("field %s can not be used in the filter", r.Field)
+	}
+
+	for _, op := range commonOperators {
+		if r.Operator == op {
+			return "", nil
+		}
+	}
+
+	typeRule, ok := fieldTypeRules[propertyType]
+	if !ok {
+		return "operator", fmt.Errorf("field %s of type %s only supports existence check", r.Field, propertyType)
+	}
+
+	supported := false
+	for _, op := range typeRule.operators {
+		if r.Operator == op {
+			supported = true
+			break
+		}
+	}
+	if !supported {
+		return "operator", fmt.Errorf("operator %s is not supported by field %s of type %s", r.Operator, r.Field,
+			propertyType)
+	}
+
+	switch r.Operator {
+	case OperatorIsEmpty, OperatorIsNotEmpty:
+		return "", nil
+	case OperatorIn, OperatorNotIn:
+		if r.Value == nil {
+			return "", nil
+		}
+		v := reflect.ValueOf(r.Value)
+		if v.Kind() != reflect.Array && v.Kind() != reflect.Slice {
+			return "value", fmt.Errorf("value of operator %s should be an array", r.Operator)
+		}
+		for i := 0; i < v.Len(); i++ {
+			if t := getType(v.Index(i).Interface()); t != typeRule.valueType {
+				return "value", fmt.Errorf("value type %s does not match field %s of type %s", t, r.Field,
+					propertyType)
+			}
+		}
+		return "", nil
+	default:
+		if t := getType(r.Value); t != typeRule.valueType {
+			return "value", fmt.Errorf("value type %s does not match field %s of type %s", t, r.Field, propertyType)
+		}
+		return "", nil
+	}
+}
@@ -0,0 +1,95 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package querybuilder_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"configcenter/src/common"
+	"configcenter/src/common/querybuilder"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testFieldTypes = querybuilder.FieldTypeFuncFromMap(map[string]string{
+	"bk_inst_id":   common.FieldTypeInt,
+	"bk_inst_name": common.FieldTypeSingleChar,
+	"enabled":      common.FieldTypeBool,
+	"last_time":    common.FieldTypeTime,
+	"operator":     common.FieldTypeOrganization,
+	"detail":       common.FieldTypeTable,
+})
+
+func TestValidateWithFields(t *testing.T) {
+	valid := []string{
+		`{"condition":"AND","rules":[{"field":"bk_inst_id","operator":"in","value":[1,2]},{"field":"bk_inst_name","operator":"contains","value":"a.b"}]}`,
+		`{"condition":"OR","rules":[{"field":"enabled","operator":"equal","value":true},{"field":"last_time","operator":"datetime_less","value":"2020-11-30T10:00:00Z"}]}`,
+		`{"condition":"AND","rules":[{"field":"operator","operator":"is_empty"},{"field":"detail","operator":"not_exist"}]}`,
+		`{"condition":"AND","rules":[{"field":"bk_inst_name","operator":"is_null"},{"condition":"OR","rules":[{"field":"bk_inst_id","operator":"greater","value":1}]}]}`,
+	}
+	for idx, data := range valid {
+		filter := querybuilder.QueryFilter{}
+		assert.Nil(t, json.Unmarshal([]byte(data), &filter), "case %d", idx)
+		key, err := filter.ValidateWithFields(testFieldTypes)
+		assert.Nil(t, err, "case %d", idx)
+		assert.Empty(t, key, "case %d", idx)
+	}
+
+	invalid := map[string]string{
+		`{"condition":"AND","rules":[{"field":"not_exist","operator":"equal","value":1}]}`:                      "rules[0].field",
+		`{"condition":"AND","rules":[{"field":"bk_inst_id","operator":"contains","value":"1"}]}`:                "rules[0].operator",
+		`{"condition":"AND","rules":[{"field":"bk_inst_id","operator":"in","value":[1,"2"]}]}`:                  "rules[0].value",
+		`{"condition":"AND","rules":[{"field":"bk_inst_name","operator":"equal","value":1}]}`:                   "rules[0].value",
+		`{"condition":"AND","rules":[{"field":"last_time","operator":"equal","value":"2020-11-30T10:00:00Z"}]}`: "rules[0].operator",
+		`{"condition":"AND","rules":[{"field":"detail","operator":"equal","value":"x"}]}`:                       "rules[0].operator",
+	}
+	for data, expectKey := range invalid {
+		filter := querybuilder.QueryFilter{}
+		assert.Nil(t, json.Unmarshal([]byte(data), &filter), data)
+		key, err := filter.ValidateWithFields(testFieldTypes)
+		assert.NotNil(t, err, data)
+		assert.Equal(t, expectKey, key, data)
+	}
+}
+
+func TestStringOperatorMatchLiterally(t *testing.T) {
+	rule := querybuilder.AtomRule{Field: "bk_inst_name", Operator: querybuilder.OperatorContains, Value: "a.b*"}
+	filter, _, err := (&querybuilder.QueryFilter{Rule: rule}).ToLiteralMgo()
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{common.BKDBLIKE: `a\.b\*`}, filter["bk_inst_name"])
+
+	// the existing callers of ToMgo still match the values as regular expressions
+	filter, _, err = rule.ToMgo()
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{common.BKDBLIKE: "a.b*"}, filter["bk_inst_name"])
+
+	rule.Operator = querybuilder.OperatorNotBeginsWith
+	filter, _, err = (&querybuilder.QueryFilter{Rule: rule}).ToLiteralMgo()
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{common.BKDBNot: map[string]interface{}{common.BKDBLIKE: `^a\.b\*`}},
+		filter["bk_inst_name"])
+
+	rule.Operator = querybuilder.OperatorsEndsWith
+	rule.Value = "(a+)+"
+	combined := querybuilder.CombinedRule{Condition: querybuilder.ConditionAnd, Rules: []querybuilder.Rule{rule}}
+	filter, _, err = (&querybuilder.QueryFilter{Rule: combined}).ToLiteralMgo()
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{common.BKDBAND: []map[string]interface{}{
+		{"bk_inst_name": map[string]interface{}{common.BKDBLIKE: `\(a\+\)\+$`}},
+	}}, filter)
+
+	rule.Value = 1
+	_, _, err = (&querybuilder.QueryFilter{Rule: rule}).ToLiteralMgo()
+	assert.NotNil(t, err)
+}
@@ -87,6 +87,29 @@ func (qf *QueryFilter) Validate() (string, error) {
 	return qf.Rule.Validate()
 }
 
+// ValidateWithFields validates the filter, and checks the fields against their property types
+func (qf *QueryFilter) ValidateWithFields(typeOf FieldTypeFunc) (string, error) {
+	if key, err := qf.Validate(); err != nil {
+		return key, err
+	}
+	if qf.Rule == nil {
+		return "", nil
+	}
+	if qf.GetDeep() > MaxDeep {
+		return "rules", fmt.Errorf("exceed max query condition deepth: %d", MaxDeep)
+	}
+	return ValidateFields(qf.Rule, typeOf)
+}
+
+// ToLiteralMgo generate mongo filter from the filter like ToMgo, but the values of the string operators are matched
+// literally instead of as regular expressions.
+func (qf *QueryFilter) ToLiteralMgo() (map[string]interface{}, string, error) {
+	if qf.Rule == nil {
+		return nil, "", fmt.Errorf("query filter is empty")
+	}
+	return ruleToMgo(qf.Rule, true)
+}
+
 func (qf *QueryFilter) MarshalJSON() ([]byte, error) {
 	if qf.Rule != nil {
 		return json.Marshal(qf.Rule)
@@ -109,10 +109,10 @@ var SupportOperators = map[Operator]bool{
 	OperatorGreater:        true,
 	OperatorGreaterOrEqual: true,
 
-	OperatorDatetimeLess:           false,
-	OperatorDatetimeLessOrEqual:    false,
-	OperatorDatetimeGreater:        false,
-	OperatorDatetimeGreaterOrEqual: false,
+	OperatorDatetimeLess:           true,
+	OperatorDatetimeLessOrEqual:    true,
+	OperatorDatetimeGreater:        true,
+	OperatorDatetimeGreaterOrEqual: true,
 
 	OperatorBeginsWith:    true,
 	OperatorNotBeginsWith: true,
@@ -121,14 +121,14 @@ var SupportOperators = map[Operator]bool{
 	OperatorsEndsWith:     true,
 	OperatorNotEndsWith:   true,
 
-	OperatorIsEmpty:    false,
-	OperatorIsNotEmpty: false,
+	OperatorIsEmpty:    true,
+	OperatorIsNotEmpty: true,
 
-	OperatorIsNull:    false,
-	OperatorIsNotNull: false,
+	OperatorIsNull:    true,
+	OperatorIsNotNull: true,
 
 	OperatorExist:    true,
-	OperatorNotExist: false,
+	OperatorNotExist: true,
 }
 
 func (op Operator) Validate() error {
@@ -205,9 +205,18 @@ func (r AtomRule) validateValue() error {
 
 // ToMgo generate mongo filter from rule
 func (r AtomRule) ToMgo() (mgoFiler map[string]interface{}, key string, err error) {
+	return r.toMgo(false)
+}
+
+// toMgo generate mongo filter from rule, the values of the string operators are regular expressions unless literal
+// is set, then they are matched literally.
+func (r AtomRule) toMgo(literal bool) (mgoFiler map[string]interface{}, key string, err error) {
 	if key, err := r.Validate(); err != nil {
 		return nil, key, fmt.Errorf("validate failed, key: %s, err: %s", key, err)
 	}
+	if literal && isStringOperator(r.Operator) {
+		return r.literalToMgo()
+	}
 
 	filter := make(map[string]interface{})
 	switch r.Operator {
@@ -331,6 +340,39 @@ func (r AtomRule) ToMgo() (mgoFiler map[string]interface{}, key string, err erro
 	return filter, "", nil
 }
 
+// literalToMgo generate mongo filter from the rule of string operator, the special characters of regular expression in
+// the value are escaped, so that the value is matched literally.
+func (r AtomRule) literalToMgo() (mgoFiler map[string]interface{}, key string, err error) {
+	value, ok := r.Value.(string)
+	if !ok {
+		return nil, "value", fmt.Errorf("value of operator %s should be a string", r.Operator)
+	}
+
+	pattern := regexp.QuoteMeta(value)
+	switch r.Operator {
+	case OperatorBeginsWith, OperatorNotBeginsWith:
+		pattern = "^" + pattern
+	case OperatorsEndsWith, OperatorNotEndsWith:
+		pattern += "$"
+	}
+	like := map[string]interface{}{common.BKDBLIKE: pattern}
+	switch r.Operator {
+	case OperatorNotBeginsWith, OperatorNotContains, OperatorNotEndsWith:
+		return map[string]interface{}{r.Field: map[string]interface{}{common.BKDBNot: like}}, "", nil
+	default:
+		return map[string]interface{}{r.Field: like}, "", nil
+	}
+}
+
+func isStringOperator(op Operator) bool {
+	switch op {
+	case OperatorBeginsWith, OperatorNotBeginsWith, OperatorContains, OperatorNotContains, OperatorsEndsWith,
+		OperatorNotEndsWith:
+		return true
+	}
+	return false
+}
+
 // *************** define query ************************
 type CombinedRule struct {
 	Condition Condition `json:"condition"`
@@ -369,6 +411,10 @@ func (r CombinedRule) Validate() (string, error) {
 }
 
 func (r CombinedRule) ToMgo() (mgoFilter map[string]interface{}, key string, err error) {
+	return r.toMgo(false)
+}
+
+func (r CombinedRule) toMgo(literal bool) (mgoFilter map[string]interface{}, key string, err error) {
 	if err := r.Condition.Validate(); err != nil {
 		return nil, "condition", err
 	}
@@ -377,7 +423,7 @@ func (r CombinedRule) ToMgo() (mgoFilter map[string]interface{}, key string, err
 	}
 	filters := make([]map[string]interface{}, 0)
 	for idx, rule := range r.Rules {
-		filter, key, err := rule.ToMgo()
+		filter, key, err := ruleToMgo(rule, literal)
 		if err != nil {
 			return nil, fmt.Sprintf("rules[%d].%s", idx, key), err
 		}
@@ -393,6 +439,19 @@ func (r CombinedRule) ToMgo() (mgoFilter map[string]interface{}, key string, err
 	return mgoFilter, "", nil
 }
 
+// ruleToMgo generate mongo filter from the rule, the rules other than atom and combined rules are always converted
+// by their own ToMgo
+func ruleToMgo(rule Rule, literal bool) (map[string]interface{}, string, error) {
+	switch r := rule.(type) {
+	case AtomRule:
+		return r.toMgo(literal)
+	case CombinedRule:
+		return r.toMgo(literal)
+	default:
+		return rule.ToMgo()
+	}
+}
+
 func (r CombinedRule) Match(matcher Matcher) bool {
 	if len(r.Rules) == 0 {
 		return true
@@ -99,10 +99,31 @@ const (
 	BKTableNameAPITask                    = "cc_APITask"
 	BKTableNameSetTemplateSyncStatus      = "cc_SetTemplateSyncStatus"
 	BKTableNameSetTemplateSyncHistory     = "cc_SetTemplateSyncHistory"
+	BKTableNameSetTemplateSyncPolicy      = "cc_SetTemplateSyncPolicy"
 
 	// rule for host property auto apply
 	BKTableNameHostApplyRule = "cc_HostApplyRule"
 
+	// host snapshot history and drift tables
+	BKTableNameHostSnapshotHistory = "cc_HostSnapshotHistory"
+	BKTableNameHostSnapshotDrift   = "cc_HostSnapshotDrift"
+
+	// api tokens for programmatic access
+	BKTableNameAPIToken = "cc_APIToken"
+
+	// event notification rules and their delivery logs
+	BKTableNameNotifyRule        = "cc_NotifyRule"
+	BKTableNameNotifyDeliveryLog = "cc_NotifyDeliveryLog"
+
+	// admission webhooks and their decision logs
+	BKTableNameAdmissionWebhook    = "cc_AdmissionWebhook"
+	BKTableNameAdmissionWebhookLog = "cc_AdmissionWebhookLog"
+
+	// approval policies of the sensitive operations and the approval requests
+	BKTableNameApprovalPolicy     = "cc_ApprovalPolicy"
+	BKTableNameApprovalRequest    = "cc_ApprovalRequest"
+	BKTableNameChangeFreezeWindow = "cc_ChangeFreezeWindow"
+
 	// cloud sync tables
 	BKTableNameCloudSyncTask    = "cc_CloudSyncTask"
 	BKTableNameCloudAccount     = "cc_CloudAccount"
@@ -163,9 +184,20 @@ var AllTables = []string{
 	BKTableNameAPITask,
 	BKTableNameSetTemplateSyncStatus,
 	BKTableNameSetTemplateSyncHistory,
+	BKTableNameSetTemplateSyncPolicy,
 	BKTableNameCloudSyncTask,
 	BKTableNameCloudAccount,
 	BKTableNameCloudSyncHistory,
+	BKTableNameHostSnapshotHistory,
+	BKTableNameHostSnapshotDrift,
+	BKTableNameAPIToken,
+	BKTableNameNotifyRule,
+	BKTableNameNotifyDeliveryLog,
+	BKTableNameAdmissionWebhook,
+	BKTableNameAdmissionWebhookLog,
+	BKTableNameApprovalPolicy,
+	BKTableNameApprovalRequest,
+	BKTableNameChangeFreezeWindow,
 }
 
 // GetInstTableName returns inst data table name
@@ -61,6 +61,7 @@ const (
 	ObjectBase              CursorType = "object_instance"
 	Process                 CursorType = "process"
 	ProcessInstanceRelation CursorType = "process_instance_relation"
+	HostSnapshotDrift       CursorType = "host_snapshot_drift"
 )
 
 func (ct CursorType) ToInt() int {
@@ -85,6 +86,8 @@ func (ct CursorType) ToInt() int {
 		return 9
 	case ProcessInstanceRelation:
 		return 10
+	case HostSnapshotDrift:
+		return 11
 	default:
 		return -1
 	}
@@ -112,6 +115,8 @@ func (ct *CursorType) ParseInt(typ int) {
 		*ct = Process
 	case 10:
 		*ct = ProcessInstanceRelation
+	case 11:
+		*ct = HostSnapshotDrift
 	default:
 		*ct = UnknownType
 	}
@@ -119,7 +124,8 @@ func (ct *CursorType) ParseInt(typ int) {
 
 // ListCursorTypes returns all support CursorTypes.
 func ListCursorTypes() []CursorType {
-	return []CursorType{Host, ModuleHostRelation, Biz, Set, Module, SetTemplate, ObjectBase, Process, ProcessInstanceRelation}
+	return []CursorType{Host, ModuleHostRelation, Biz, Set, Module, SetTemplate, ObjectBase, Process, ProcessInstanceRelation,
+		HostSnapshotDrift}
 }
 
 // ListEventCallbackCursorTypes returns all support CursorTypes for event callback.
@@ -266,6 +272,8 @@ func GetEventCursor(coll string, e *types.Event) (string, error) {
 		curType = Process
 	case common.BKTableNameProcessInstanceRelation:
 		curType = ProcessInstanceRelation
+	case common.BKTableNameHostSnapshotDrift:
+		curType = HostSnapshotDrift
 	default:
 		blog.Errorf("unsupported cursor type collection: %s, oid: %s", e.Oid)
 		return "", fmt.Errorf("unsupported cursor type collection: %s", coll)
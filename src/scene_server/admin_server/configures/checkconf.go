@@ -169,6 +169,18 @@ func (cc *ConfCenter) isDatacollectionConfigOK(v *viper.Viper, fileName string)
 			return err
 		}
 	}
+
+	if v.IsSet("datacollection.hostsnap.history.maxRecords") {
+		if err := cc.isConfigNotIntVal("datacollection.hostsnap.history.maxRecords", fileName, v); err != nil {
+			return err
+		}
+	}
+
+	if v.IsSet("datacollection.hostsnap.history.retentionDays") {
+		if err := cc.isConfigNotIntVal("datacollection.hostsnap.history.retentionDays", fileName, v); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
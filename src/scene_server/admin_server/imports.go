@@ -110,4 +110,12 @@ import (
 	_ "configcenter/src/scene_server/admin_server/upgrader/y3.9.202011021415"
 	_ "configcenter/src/scene_server/admin_server/upgrader/y3.9.202011171550"
 	_ "configcenter/src/scene_server/admin_server/upgrader/y3.9.202011192014"
+	_ "configcenter/src/scene_server/admin_server/upgrader/y3.9.202011251530"
+	_ "configcenter/src/scene_server/admin_server/upgrader/y3.9.202011261030"
+	_ "configcenter/src/scene_server/admin_server/upgrader/y3.9.202011271100"
+	_ "configcenter/src/scene_server/admin_server/upgrader/y3.9.202011301000"
+	_ "configcenter/src/scene_server/admin_server/upgrader/y3.9.202012011000"
+	_ "configcenter/src/scene_server/admin_server/upgrader/y3.9.202012021000"
+	_ "configcenter/src/scene_server/admin_server/upgrader/y3.9.202012031000"
+	_ "configcenter/src/scene_server/admin_server/upgrader/y3.9.202012041000"
 )
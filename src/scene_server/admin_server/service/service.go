@@ -21,8 +21,10 @@ import (
 	"configcenter/src/common/errors"
 	"configcenter/src/common/metadata"
 	"configcenter/src/common/metric"
+	"configcenter/src/common/openapi"
 	"configcenter/src/common/rdapi"
 	"configcenter/src/common/types"
+	"configcenter/src/common/version"
 	"configcenter/src/scene_server/admin_server/app/options"
 	"configcenter/src/storage/dal"
 	"configcenter/src/storage/dal/redis"
@@ -87,6 +89,7 @@ func (s *Service) WebService() *restful.Container {
 
 	healthzAPI := new(restful.WebService).Produces(restful.MIME_JSON)
 	healthzAPI.Route(healthzAPI.GET("/healthz").To(s.Healthz))
+	healthzAPI.Route(healthzAPI.GET("/openapi").To(openapi.RouteFunction(container, types.CC_MODULE_MIGRATE, version.CCVersion)))
 	container.Add(healthzAPI)
 
 	return container
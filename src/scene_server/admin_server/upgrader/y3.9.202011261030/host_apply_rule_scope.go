@@ -0,0 +1,121 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package y3_9_202011261030
+
+import (
+	"context"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/util"
+	"configcenter/src/scene_server/admin_server/upgrader"
+	"configcenter/src/storage/dal"
+	"configcenter/src/storage/dal/types"
+)
+
+// addHostApplyRuleScope 主机属性自动应用规则支持绑定到业务、自定义层级和集群,
+// 已有的规则均绑定在模块上, 补充规则绑定的拓扑节点及优先级字段, 并修改唯一索引
+func addHostApplyRuleScope(ctx context.Context, db dal.RDB, conf *upgrader.Config) error {
+	tableName := common.BKTableNameHostApplyRule
+
+	filter := map[string]interface{}{
+		common.BKObjIDField: map[string]interface{}{
+			common.BKDBExists: false,
+		},
+	}
+	moduleIDs, err := db.Table(tableName).Distinct(ctx, common.BKModuleIDField, filter)
+	if err != nil {
+		blog.Errorf("distinct %s of table %s failed, err: %v", common.BKModuleIDField, tableName, err)
+		return err
+	}
+
+	for _, item := range moduleIDs {
+		moduleID, err := util.GetInt64ByInterface(item)
+		if err != nil {
+			blog.Errorf("parse module id %v failed, err: %v", item, err)
+			return err
+		}
+
+		moduleFilter := map[string]interface{}{
+			common.BKModuleIDField: moduleID,
+			common.BKObjIDField: map[string]interface{}{
+				common.BKDBExists: false,
+			},
+		}
+		doc := map[string]interface{}{
+			common.BKObjIDField:               common.BKInnerObjIDModule,
+			common.BKInstIDField:              moduleID,
+			common.HostApplyRulePriorityField: 0,
+		}
+		if err := db.Table(tableName).Update(ctx, moduleFilter, doc); err != nil {
+			blog.Errorf("update host apply rule scope failed, filter: %+v, err: %v", moduleFilter, err)
+			return err
+		}
+	}
+
+	// rules not bound to module have zero bk_module_id, so the unique index should use bound topo node
+	oldIndexName := "idx_unique_bizID_moduleID_attrID"
+	newIndex := types.Index{
+		Keys: map[string]int32{
+			common.BKAppIDField:       1,
+			common.BKObjIDField:       1,
+			common.BKInstIDField:      1,
+			common.BKAttributeIDField: 1,
+		},
+		Unique:     true,
+		Background: true,
+		Name:       "idx_unique_bizID_objID_instID_attrID",
+	}
+
+	indexes, err := db.Table(tableName).Indexes(ctx)
+	if err != nil {
+		blog.Errorf("get indexes of table %s failed, err: %v", tableName, err)
+		return err
+	}
+
+	createNewIndex := true
+	for _, index := range indexes {
+		switch index.Name {
+		case oldIndexName:
+			if err := db.Table(tableName).DropIndex(ctx, oldIndexName); err != nil {
+				blog.Errorf("drop index %s of table %s failed, err: %v", oldIndexName, tableName, err)
+				return err
+			}
+		case newIndex.Name:
+			createNewIndex = false
+		}
+	}
+
+	if createNewIndex {
+		if err := db.Table(tableName).CreateIndex(ctx, newIndex); err != nil {
+			blog.Errorf("create index %s of table %s failed, err: %v", newIndex.Name, tableName, err)
+			return err
+		}
+	}
+
+	moduleIndex := types.Index{
+		Keys:       map[string]int32{common.BKModuleIDField: 1},
+		Background: true,
+		Name:       "idx_moduleID",
+	}
+	for _, index := range indexes {
+		if index.Name == moduleIndex.Name {
+			return nil
+		}
+	}
+	if err := db.Table(tableName).CreateIndex(ctx, moduleIndex); err != nil {
+		blog.Errorf("create index %s of table %s failed, err: %v", moduleIndex.Name, tableName, err)
+		return err
+	}
+	return nil
+}
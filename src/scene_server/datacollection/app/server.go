@@ -405,14 +405,19 @@ func (c *DataCollection) runCollectPorters() {
 	}
 	blog.Info("DataCollection| get default appid id success[%s]", c.defaultAppID)
 
+	// host snapshot analyzer also handles the snapshots reported by http.
+	snapAnalyzer := hostsnap.NewHostSnap(c.ctx, c.redisCli, c.db, c.engine, c.authManager)
+	c.service.SetHostSnap(snapAnalyzer)
+
 	// create and add new porters.
 	if c.snapCli != nil {
 		topic := c.snapMessageTopic(c.defaultAppID)
-		analyzer := hostsnap.NewHostSnap(c.ctx, c.redisCli, c.db, c.engine, c.authManager)
 
-		porter := collections.NewSimplePorter(snapPorterName, c.engine, c.hash, analyzer, c.snapCli, topic, c.registry)
+		porter := collections.NewSimplePorter(snapPorterName, c.engine, c.hash, snapAnalyzer, c.snapCli, topic, c.registry)
 		c.porterManager.AddPorter(porter)
 		blog.Info("DataCollection| create hostsnap analyzer with target porter[%s] on topic[%s] success", snapPorterName, topic)
+
+		c.runSnapSourcePorters(snapAnalyzer)
 	}
 
 	if c.disCli != nil {
@@ -434,6 +439,32 @@ func (c *DataCollection) runCollectPorters() {
 	}
 }
 
+// runSnapSourcePorters runs porters for the non-gse snapshot collectors which publish messages to snap redis.
+func (c *DataCollection) runSnapSourcePorters(snapAnalyzer *hostsnap.HostSnap) {
+	sources, err := hostsnap.LoadSourceConfigs()
+	if err != nil {
+		blog.Errorf("DataCollection| load hostsnap source configs failed, err: %v", err)
+		return
+	}
+
+	for _, source := range sources {
+		if len(source.Channel) == 0 {
+			continue
+		}
+
+		analyzer, err := snapAnalyzer.NewSourceAnalyzer(source.Source)
+		if err != nil {
+			blog.Errorf("DataCollection| create hostsnap analyzer of source[%s] failed, err: %v", source.Source, err)
+			continue
+		}
+
+		name := snapPorterName + "_" + source.Source
+		porter := collections.NewSimplePorter(name, c.engine, c.hash, analyzer, c.snapCli, []string{source.Channel}, c.registry)
+		c.porterManager.AddPorter(porter)
+		blog.Info("DataCollection| create hostsnap analyzer with target porter[%s] on topic[%s] success", name, source.Channel)
+	}
+}
+
 // Run runs a new datacollection server.
 func (c *DataCollection) Run() error {
 	// init configs.
@@ -0,0 +1,83 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package hostsnap
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// fingerprintTTL is the time that the fingerprint of a host is kept, the snapshot of the host is recorded again after
+// it expires, and the fingerprints of the hosts that no longer report snapshot, such as the deleted hosts, are removed.
+const fingerprintTTL = time.Hour
+
+func newFingerprints(ctx context.Context) *fingerprints {
+	f := &fingerprints{
+		pool: make(map[string]fingerprint),
+		ttl:  fingerprintTTL,
+	}
+	go f.gc(ctx)
+	return f
+}
+
+// fingerprints records the fingerprint of the snapshot and host data when the snapshot history of the host is
+// recorded last time, so that the db is not queried for every snapshot message.
+type fingerprints struct {
+	lock sync.Mutex
+	// key: source:host_id
+	pool map[string]fingerprint
+	ttl  time.Duration
+}
+
+type fingerprint struct {
+	value  uint64
+	expire time.Time
+}
+
+// Match checks if the fingerprint of the key is the same with the value and not expired.
+func (f *fingerprints) Match(key string, value uint64) bool {
+	f.lock.Lock()
+	last, exist := f.pool[key]
+	f.lock.Unlock()
+
+	return exist && last.value == value && time.Now().Before(last.expire)
+}
+
+// Set records the fingerprint of the key.
+func (f *fingerprints) Set(key string, value uint64) {
+	f.lock.Lock()
+	f.pool[key] = fingerprint{value: value, expire: time.Now().Add(f.ttl)}
+	f.lock.Unlock()
+}
+
+func (f *fingerprints) gc(ctx context.Context) {
+	ticker := time.NewTicker(f.ttl)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		now := time.Now()
+		f.lock.Lock()
+		for key, last := range f.pool {
+			if now.After(last.expire) {
+				delete(f.pool, key)
+			}
+		}
+		f.lock.Unlock()
+	}
+}
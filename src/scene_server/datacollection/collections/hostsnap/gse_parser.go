@@ -0,0 +1,48 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hostsnap
+
+import (
+	"github.com/tidwall/gjson"
+)
+
+// gseParser parses the snapshot reported by gse bkmonitorbeat.
+type gseParser struct{}
+
+func (p *gseParser) Source() string {
+	return GseSource
+}
+
+func (p *gseParser) Fields() []string {
+	return compareFields
+}
+
+func (p *gseParser) Decode(msg string) gjson.Result {
+	// the snapshot may be wrapped in the data field of the message
+	if !gjson.Get(msg, "cloudid").Exists() {
+		return gjson.Parse(gjson.Get(msg, "data").String())
+	}
+	return gjson.Parse(msg)
+}
+
+func (p *gseParser) ParseHost(val *gjson.Result) (int64, []string) {
+	return val.Get("cloudid").Int(), getIPS(val)
+}
+
+func (p *gseParser) ParseSetter(val *gjson.Result, innerIP, outerIP string) (map[string]interface{}, string) {
+	return parseSetter(val, innerIP, outerIP)
+}
+
+func (p *gseParser) ParseSnapshot(val *gjson.Result) (*string, error) {
+	return ParseHostSnap(val)
+}
@@ -0,0 +1,356 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hostsnap
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"time"
+
+	"configcenter/src/common"
+	cc "configcenter/src/common/backbone/configcenter"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+
+	"github.com/tidwall/gjson"
+)
+
+const (
+	// defaultHistoryMaxRecords is the default max number of snapshot history records of one host
+	defaultHistoryMaxRecords = 100
+	// minHistoryMaxRecords is the minimum value of max number of snapshot history records of one host
+	minHistoryMaxRecords = 1
+	// defaultHistoryRetentionDays is the default days that snapshot history records are kept
+	defaultHistoryRetentionDays = 30
+	// minHistoryRetentionDays is the minimum days that snapshot history records are kept
+	minHistoryRetentionDays = 1
+	// historyCleanInterval is the interval to clean the expired snapshot history of all hosts
+	historyCleanInterval = time.Hour
+)
+
+// getManualFields 获取人工维护的主机字段, 这些字段不会被快照数据更新, 只会检查快照上报的值是否与主机上的值一致
+func getManualFields() []string {
+	config, err := cc.String("datacollection.hostsnap.manualFields")
+	if err != nil || len(config) == 0 {
+		return make([]string, 0)
+	}
+
+	configured := make(map[string]struct{})
+	for _, field := range strings.Split(config, ",") {
+		configured[strings.TrimSpace(field)] = struct{}{}
+	}
+
+	// keep the same order with compareFields, and drop fields that is not collected
+	fields := make([]string, 0)
+	for _, field := range compareFields {
+		if _, ok := configured[field]; ok {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// diffSnapshotFields returns the fields whose value in src is different from the value in toCompare,
+// the numeric fields bk_cpu, bk_cpu_mhz, bk_disk, bk_mem tolerate changes less than changeRangePercent.
+func diffSnapshotFields(src, toCompare string, fields []string, changeRangePercent int) []string {
+	changed := make([]string, 0)
+	srcElements := gjson.GetMany(src, fields...)
+	compareElements := gjson.GetMany(toCompare, fields...)
+	for idx, field := range fields {
+		// compare these value with string directly to avoid empty value or null value.
+		if srcElements[idx].String() == compareElements[idx].String() {
+			continue
+		}
+
+		// tolerate bk_cpu, bk_cpu_mhz, bk_disk, bk_mem changes less than the set value
+		if field == "bk_cpu" || field == "bk_cpu_mhz" || field == "bk_disk" || field == "bk_mem" {
+			val := compareElements[idx].Float() * (float64(changeRangePercent) / 100.0)
+			diff := srcElements[idx].Float() - compareElements[idx].Float()
+			if -val < diff && diff < val {
+				continue
+			}
+		}
+		changed = append(changed, field)
+	}
+	return changed
+}
+
+// detectSnapshotDrift returns the manually maintained fields whose reported value disagree with the
+// value of the host, the fields that are not maintained in cmdb or not reported are skipped.
+func detectSnapshotDrift(raw, host string, fields []string, changeRangePercent int) []metadata.HostSnapshotDriftField {
+	drifts := make([]metadata.HostSnapshotDriftField, 0)
+	for _, field := range diffSnapshotFields(raw, host, fields, changeRangePercent) {
+		reported := gjson.Get(raw, field)
+		recorded := gjson.Get(host, field)
+		if len(reported.String()) == 0 || len(recorded.String()) == 0 {
+			continue
+		}
+
+		drifts = append(drifts, metadata.HostSnapshotDriftField{
+			PropertyID:    field,
+			ReportedValue: reported.Value(),
+			CMDBValue:     recorded.Value(),
+		})
+	}
+	return drifts
+}
+
+// isSameDriftFields check if the drift fields are the same, values are compared in string format
+// because the numeric values' types may be changed after saved in db.
+func isSameDriftFields(a, b []metadata.HostSnapshotDriftField) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for idx := range a {
+		if a[idx].PropertyID != b[idx].PropertyID ||
+			fmt.Sprint(a[idx].ReportedValue) != fmt.Sprint(b[idx].ReportedValue) ||
+			fmt.Sprint(a[idx].CMDBValue) != fmt.Sprint(b[idx].CMDBValue) {
+			return false
+		}
+	}
+	return true
+}
+
+func snapshotFingerprint(raw, host string) uint64 {
+	hash := fnv.New64a()
+	hash.Write([]byte(raw))
+	hash.Write([]byte(host))
+	return hash.Sum64()
+}
+
+// recordSnapshot saves the snapshot history of the host and checks if the manually maintained fields of the
+// host drift, it is skipped when neither the snapshot nor the host changed since the last time it is recorded.
+func (h *HostSnap) recordSnapshot(kit *rest.Kit, source string, fields []string, hostID int64, innerIP string,
+	cloudID int64, host string, setter map[string]interface{}, raw string) {
+
+	key := fmt.Sprintf("%s:%d", source, hostID)
+	fingerprint := snapshotFingerprint(raw, host)
+	if h.fingerprints.Match(key, fingerprint) {
+		return
+	}
+
+	changeRangePercent := getLimitConfig("datacollection.hostsnap.changeRangePercent", defaultChangeRangePercent, minChangeRangePercent)
+
+	if err := h.saveSnapshotHistory(kit, source, fields, hostID, setter, raw, changeRangePercent); err != nil {
+		blog.Errorf("save host %d snapshot history failed, source: %s, err: %v, rid: %s", hostID, source, err, kit.Rid)
+		return
+	}
+
+	// only check the manually maintained fields reported by the collector
+	driftFields := make([]string, 0)
+	for _, field := range h.manualFields {
+		if _, ok := setter[field]; ok {
+			driftFields = append(driftFields, field)
+		}
+	}
+
+	if err := h.checkSnapshotDrift(kit, driftFields, hostID, innerIP, cloudID, host, raw, changeRangePercent); err != nil {
+		blog.Errorf("check host %d snapshot drift failed, source: %s, err: %v, rid: %s", hostID, source, err, kit.Rid)
+		return
+	}
+
+	h.fingerprints.Set(key, fingerprint)
+}
+
+// saveSnapshotHistory saves a new history record when the snapshot changed compared with the last record,
+// and removes the records that exceed the max record number or retention days.
+func (h *HostSnap) saveSnapshotHistory(kit *rest.Kit, source string, fields []string, hostID int64,
+	setter map[string]interface{}, raw string, changeRangePercent int) error {
+
+	filter := map[string]interface{}{
+		common.BKHostIDField: hostID,
+		"source":             source,
+	}
+
+	last := make([]metadata.HostSnapshotHistory, 0)
+	err := h.db.Table(common.BKTableNameHostSnapshotHistory).Find(filter).Sort("-"+common.CreateTimeField).
+		Limit(1).All(kit.Ctx, &last)
+	if err != nil {
+		blog.Errorf("get host %d last snapshot history failed, err: %v, rid: %s", hostID, err, kit.Rid)
+		return err
+	}
+
+	changedFields := make([]string, 0)
+	if len(last) > 0 {
+		lastRaw, err := json.Marshal(last[0].Snapshot)
+		if err != nil {
+			blog.Errorf("marshal host %d last snapshot history failed, err: %v, rid: %s", hostID, err, kit.Rid)
+			return err
+		}
+
+		changedFields = diffSnapshotFields(raw, string(lastRaw), fields, changeRangePercent)
+		if len(changedFields) == 0 {
+			return nil
+		}
+	}
+
+	history := metadata.HostSnapshotHistory{
+		HostID:          hostID,
+		Source:          source,
+		Snapshot:        setter,
+		ChangedFields:   changedFields,
+		SupplierAccount: kit.SupplierAccount,
+		CreateTime:      time.Now(),
+	}
+	if err := h.db.Table(common.BKTableNameHostSnapshotHistory).Insert(kit.Ctx, history); err != nil {
+		blog.Errorf("save host %d snapshot history failed, err: %v, rid: %s", hostID, err, kit.Rid)
+		return err
+	}
+
+	blog.V(4).Infof("host %d snapshot changed fields: %v, save history success, rid: %s", hostID, changedFields, kit.Rid)
+
+	return h.pruneSnapshotHistory(kit, hostID)
+}
+
+// pruneSnapshotHistory removes the host's snapshot history records that exceed the max record number or retention days
+func (h *HostSnap) pruneSnapshotHistory(kit *rest.Kit, hostID int64) error {
+	maxRecords := getLimitConfig("datacollection.hostsnap.history.maxRecords", defaultHistoryMaxRecords,
+		minHistoryMaxRecords)
+	retentionDays := getLimitConfig("datacollection.hostsnap.history.retentionDays", defaultHistoryRetentionDays,
+		minHistoryRetentionDays)
+	expireTime := time.Now().AddDate(0, 0, -retentionDays)
+
+	filter := map[string]interface{}{
+		common.BKHostIDField: hostID,
+	}
+
+	// get the oldest record that need to be kept, records older than it exceed the max record number
+	oldest := make([]metadata.HostSnapshotHistory, 0)
+	err := h.db.Table(common.BKTableNameHostSnapshotHistory).Find(filter).Fields(common.CreateTimeField).
+		Sort("-"+common.CreateTimeField).Start(uint64(maxRecords-1)).Limit(1).All(kit.Ctx, &oldest)
+	if err != nil {
+		blog.Errorf("get host %d oldest snapshot history failed, err: %v, rid: %s", hostID, err, kit.Rid)
+		return err
+	}
+
+	if len(oldest) > 0 && oldest[0].CreateTime.After(expireTime) {
+		expireTime = oldest[0].CreateTime
+	}
+
+	filter[common.CreateTimeField] = map[string]interface{}{
+		common.BKDBLT: expireTime,
+	}
+	if err := h.db.Table(common.BKTableNameHostSnapshotHistory).Delete(kit.Ctx, filter); err != nil {
+		blog.Errorf("delete host %d expired snapshot history failed, err: %v, rid: %s", hostID, err, kit.Rid)
+		return err
+	}
+	return nil
+}
+
+// cleanExpiredSnapshotHistory loop to remove snapshot history that exceed the retention days, so that the
+// history of the hosts that no longer report snapshot can also be cleaned.
+func (h *HostSnap) cleanExpiredSnapshotHistory() {
+	ticker := time.NewTicker(historyCleanInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-h.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if !h.Engine.ServiceManageInterface.IsMaster() {
+			continue
+		}
+
+		retentionDays := getLimitConfig("datacollection.hostsnap.history.retentionDays", defaultHistoryRetentionDays,
+			minHistoryRetentionDays)
+		filter := map[string]interface{}{
+			common.CreateTimeField: map[string]interface{}{
+				common.BKDBLT: time.Now().AddDate(0, 0, -retentionDays),
+			},
+		}
+
+		_, rid := newHeaderWithRid()
+		if err := h.db.Table(common.BKTableNameHostSnapshotHistory).Delete(h.ctx, filter); err != nil {
+			blog.Errorf("clean expired host snapshot history failed, err: %v, rid: %s", err, rid)
+			continue
+		}
+		blog.V(4).Infof("clean expired host snapshot history success, rid: %s", rid)
+	}
+}
+
+// checkSnapshotDrift saves the drift of the manually maintained fields of the host, and removes the drift record
+// when the drift disappears. the record changes generate the host snapshot drift watch events.
+func (h *HostSnap) checkSnapshotDrift(kit *rest.Kit, manualFields []string, hostID int64, innerIP string,
+	cloudID int64, host, raw string, changeRangePercent int) error {
+
+	if len(manualFields) == 0 {
+		return nil
+	}
+
+	filter := map[string]interface{}{
+		common.BKHostIDField: hostID,
+	}
+
+	existing := make([]metadata.HostSnapshotDrift, 0)
+	if err := h.db.Table(common.BKTableNameHostSnapshotDrift).Find(filter).All(kit.Ctx, &existing); err != nil {
+		blog.Errorf("get host %d snapshot drift failed, err: %v, rid: %s", hostID, err, kit.Rid)
+		return err
+	}
+
+	fields := detectSnapshotDrift(raw, host, manualFields, changeRangePercent)
+	if len(fields) == 0 {
+		if len(existing) == 0 {
+			return nil
+		}
+
+		if err := h.db.Table(common.BKTableNameHostSnapshotDrift).Delete(kit.Ctx, filter); err != nil {
+			blog.Errorf("delete host %d snapshot drift failed, err: %v, rid: %s", hostID, err, kit.Rid)
+			return err
+		}
+		blog.Infof("host %d snapshot drift disappeared, rid: %s", hostID, kit.Rid)
+		return nil
+	}
+
+	now := time.Now()
+	if len(existing) == 0 {
+		drift := metadata.HostSnapshotDrift{
+			HostID:          hostID,
+			InnerIP:         innerIP,
+			CloudID:         cloudID,
+			Fields:          fields,
+			SupplierAccount: kit.SupplierAccount,
+			CreateTime:      now,
+			LastTime:        now,
+		}
+		if err := h.db.Table(common.BKTableNameHostSnapshotDrift).Insert(kit.Ctx, drift); err != nil {
+			blog.Errorf("save host %d snapshot drift failed, err: %v, rid: %s", hostID, err, kit.Rid)
+			return err
+		}
+		blog.Infof("host %d snapshot drift detected, fields: %+v, rid: %s", hostID, fields, kit.Rid)
+		return nil
+	}
+
+	if isSameDriftFields(existing[0].Fields, fields) {
+		return nil
+	}
+
+	doc := map[string]interface{}{
+		common.BKHostInnerIPField: innerIP,
+		common.BKCloudIDField:     cloudID,
+		"fields":                  fields,
+		common.LastTimeField:      now,
+	}
+	if err := h.db.Table(common.BKTableNameHostSnapshotDrift).Update(kit.Ctx, filter, doc); err != nil {
+		blog.Errorf("update host %d snapshot drift failed, err: %v, rid: %s", hostID, err, kit.Rid)
+		return err
+	}
+	blog.Infof("host %d snapshot drift changed, fields: %+v, rid: %s", hostID, fields, kit.Rid)
+	return nil
+}
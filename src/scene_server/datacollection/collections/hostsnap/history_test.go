@@ -0,0 +1,82 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hostsnap
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"configcenter/src/common/metadata"
+)
+
+func TestDiffSnapshotFields(t *testing.T) {
+	src := `{"bk_cpu":8,"bk_mem":16000,"bk_os_name":"linux centos","bk_host_name":"host-a"}`
+	toCompare := `{"bk_cpu":4,"bk_mem":16500,"bk_os_name":"linux centos","bk_host_name":"host-b"}`
+	fields := []string{"bk_cpu", "bk_mem", "bk_os_name", "bk_host_name"}
+
+	changed := diffSnapshotFields(src, toCompare, fields, 10)
+	// bk_mem changes less than 10% is tolerated
+	if !reflect.DeepEqual(changed, []string{"bk_cpu", "bk_host_name"}) {
+		t.Fatalf("unexpected changed fields: %v", changed)
+	}
+
+	if changed := diffSnapshotFields(src, src, fields, 10); len(changed) != 0 {
+		t.Fatalf("same snapshot should have no changed fields, but got: %v", changed)
+	}
+}
+
+func TestDetectSnapshotDrift(t *testing.T) {
+	raw := `{"bk_cpu":8,"bk_os_name":"linux centos","bk_host_name":"host-a","bk_mac":""}`
+	host := `{"bk_host_id":1,"bk_cpu":8,"bk_os_name":"","bk_host_name":"db-master","bk_mac":"52:54:00:19:2e:e8"}`
+	fields := []string{"bk_cpu", "bk_os_name", "bk_host_name", "bk_mac"}
+
+	// bk_os_name is not maintained in cmdb and bk_mac is not reported, they are not drift
+	drifts := detectSnapshotDrift(raw, host, fields, 10)
+	expected := []metadata.HostSnapshotDriftField{{
+		PropertyID:    "bk_host_name",
+		ReportedValue: "host-a",
+		CMDBValue:     "db-master",
+	}}
+	if !reflect.DeepEqual(drifts, expected) {
+		t.Fatalf("unexpected drift fields: %+v", drifts)
+	}
+
+	if !isSameDriftFields(drifts, expected) {
+		t.Fatalf("drift fields should be the same")
+	}
+
+	// numeric values may be decoded from db with another type
+	if !isSameDriftFields([]metadata.HostSnapshotDriftField{{PropertyID: "bk_cpu", ReportedValue: int32(8), CMDBValue: 4}},
+		[]metadata.HostSnapshotDriftField{{PropertyID: "bk_cpu", ReportedValue: int64(8), CMDBValue: float64(4)}}) {
+		t.Fatalf("drift fields with different numeric types should be the same")
+	}
+}
+
+func TestFingerprints(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	f := newFingerprints(ctx)
+
+	f.Set("gse:1", 100)
+	if !f.Match("gse:1", 100) || f.Match("gse:1", 101) || f.Match("gse:2", 100) {
+		t.Fatalf("unexpected fingerprint match result")
+	}
+
+	// the expired fingerprint does not match, the snapshot is recorded again
+	f.pool["gse:1"] = fingerprint{value: 100, expire: time.Now().Add(-time.Second)}
+	if f.Match("gse:1", 100) {
+		t.Fatalf("expired fingerprint should not match")
+	}
+}
@@ -70,6 +70,10 @@ type HostSnap struct {
 	ctx    context.Context
 	db     dal.RDB
 	window *Window
+	// manualFields 人工维护的主机字段, 不会被快照数据更新, 用于检测配置漂移
+	manualFields []string
+	// fingerprints 记录每台主机最近一次记录快照历史时的快照和主机数据的指纹, 避免每条快照消息都查询db
+	fingerprints *fingerprints
 }
 
 func NewHostSnap(ctx context.Context, redisCli redis.Client, db dal.RDB, engine *backbone.Engine, authManager *extensions.AuthManager) *HostSnap {
@@ -84,6 +88,14 @@ func NewHostSnap(ctx context.Context, redisCli redis.Client, db dal.RDB, engine
 		filter:      newFilter(),
 		window:      newWindow(),
 	}
+	h.fingerprints = newFingerprints(ctx)
+
+	h.manualFields = getManualFields()
+	for _, field := range h.manualFields {
+		ignoreCompareField[field] = struct{}{}
+	}
+
+	go h.cleanExpiredSnapshotHistory()
 	return h
 }
 
@@ -137,24 +149,26 @@ func getLimitConfig(config string, defaultValue, minValue int) int {
 	return limit
 }
 
+// Analyze analyzes the snapshot message reported by gse.
 func (h *HostSnap) Analyze(msg *string) error {
+	return h.AnalyzeWithSource(GseSource, msg)
+}
+
+// AnalyzeWithSource analyzes the snapshot message reported by the collector of the source.
+func (h *HostSnap) AnalyzeWithSource(source string, msg *string) error {
 	if msg == nil {
 		return fmt.Errorf("message nil")
 	}
 
-	var data string
-
-	if !gjson.Get(*msg, "cloudid").Exists() {
-		data = gjson.Get(*msg, "data").String()
-	} else {
-		data = *msg
+	parser, exist := GetParser(source)
+	if !exist {
+		return fmt.Errorf("snapshot parser of source %s not exist", source)
 	}
 
 	header, rid := newHeaderWithRid()
 
-	val := gjson.Parse(data)
-	cloudID := val.Get("cloudid").Int()
-	ips := getIPS(&val)
+	val := parser.Decode(*msg)
+	cloudID, ips := parser.ParseHost(&val)
 	host, err := h.getHostByVal(header, cloudID, ips, &val)
 	if err != nil {
 		blog.Errorf("get host detail with ips: %v failed, err: %v, rid: %s", ips, err, rid)
@@ -182,7 +196,7 @@ func (h *HostSnap) Analyze(msg *string) error {
 	outerIP := elements[2].String()
 
 	// save host snapshot in redis
-	h.saveHostsnap(header, &val, hostID)
+	h.saveHostsnap(header, parser, &val, hostID)
 
 	// window restriction on request
 	if !h.window.canPassWindow() {
@@ -192,9 +206,30 @@ func (h *HostSnap) Analyze(msg *string) error {
 		}
 		return nil
 	}
-	setter, raw := parseSetter(&val, innerIP, outerIP)
+
+	kit := &rest.Kit{
+		Rid:             rid,
+		Header:          header,
+		Ctx:             h.ctx,
+		CCError:         h.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(header)),
+		User:            common.CCSystemCollectorUserName,
+		SupplierAccount: common.BKDefaultOwnerID,
+	}
+
+	setter, raw := parser.ParseSetter(&val, innerIP, outerIP)
+	// only the fields reported by the collector are compared
+	fields := make([]string, 0, len(setter))
+	for _, field := range parser.Fields() {
+		if _, ok := setter[field]; ok {
+			fields = append(fields, field)
+		}
+	}
+
+	// save snapshot history and check the drift of manually maintained fields
+	h.recordSnapshot(kit, source, fields, hostID, innerIP, cloudID, host, setter, raw)
+
 	// no need to update
-	if !needToUpdate(raw, host) {
+	if !needToUpdate(raw, host, fields) {
 		return nil
 	}
 
@@ -210,14 +245,6 @@ func (h *HostSnap) Analyze(msg *string) error {
 
 	// get audit interface of host.
 	audit := auditlog.NewHostAudit(h.CoreAPI.CoreService())
-	kit := &rest.Kit{
-		Rid:             rid,
-		Header:          header,
-		Ctx:             h.ctx,
-		CCError:         h.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(header)),
-		User:            common.CCSystemCollectorUserName,
-		SupplierAccount: common.BKDefaultOwnerID,
-	}
 
 	// generate audit log for update host.
 	generateAuditParameter := auditlog.NewGenerateAuditCommonParameter(kit, metadata.AuditUpdate).
@@ -263,31 +290,18 @@ func (h *HostSnap) Analyze(msg *string) error {
 	return nil
 }
 
-func needToUpdate(src, toCompare string) bool {
+func needToUpdate(src, toCompare string, fields []string) bool {
 	// get data fluctuation limit
 	changeRangePercent := getLimitConfig("datacollection.hostsnap.changeRangePercent", defaultChangeRangePercent, minChangeRangePercent)
-	srcElements := gjson.GetMany(src, compareFields...)
-	compareElements := gjson.GetMany(toCompare, compareFields...)
-	for idx, field := range compareFields {
+	toCompareFields := make([]string, 0, len(fields))
+	for _, field := range fields {
 		if _, ok := ignoreCompareField[field]; ok {
 			// 忽略变更对比的字段直接过滤掉
 			continue
 		}
-		// compare these value with string directly to avoid empty value or null value.
-		if srcElements[idx].String() != compareElements[idx].String() {
-			compareField := compareFields[idx]
-			// tolerate bk_cpu, bk_cpu_mhz, bk_disk, bk_mem changes less than the set value
-			if compareField == "bk_cpu" || compareField == "bk_cpu_mhz" || compareField == "bk_disk" || compareField == "bk_mem" {
-				val := compareElements[idx].Float() * (float64(changeRangePercent)/100.0)
-				diff := srcElements[idx].Float() - compareElements[idx].Float()
-				if -val < diff && diff < val {
-					continue
-				}
-			}
-			return true
-		}
+		toCompareFields = append(toCompareFields, field)
 	}
-	return false
+	return len(diffSnapshotFields(src, toCompare, toCompareFields, changeRangePercent)) > 0
 }
 
 func parseSetter(val *gjson.Result, innerIP, outerIP string) (map[string]interface{}, string) {
@@ -531,16 +545,35 @@ func getIPS(val *gjson.Result) []string {
 	return append(ipv4, ipv6...)
 }
 
+// appendValidIP appends the ip without mask if it's a valid ipv4 address and not a loopback address
+func appendValidIP(ips []string, addr string) []string {
+	ip := strings.Split(strings.TrimSpace(addr), "/")[0]
+	if strings.HasPrefix(ip, "127.0.0.") || net.ParseIP(ip) == nil {
+		return ips
+	}
+
+	if strings.Contains(ip, ":") {
+		// not support ipv6 for now.
+		return ips
+	}
+	return append(ips, ip)
+}
+
 // saveHostsnap save host snapshot in redis
-func (h *HostSnap) saveHostsnap(header http.Header, hostData *gjson.Result, hostID int64) error {
+func (h *HostSnap) saveHostsnap(header http.Header, parser SnapshotParser, hostData *gjson.Result, hostID int64) error {
 	rid := util.GetHTTPCCRequestID(header)
 
-	snapshot, err := ParseHostSnap(hostData)
+	snapshot, err := parser.ParseSnapshot(hostData)
 	if err != nil {
 		blog.Errorf("saveHostsnap failed, ParseHostSnap err: %v, hostID:%v, rid:%s", err, hostID, rid)
 		return err
 	}
 
+	if snapshot == nil {
+		// the source does not support realtime snapshot
+		return nil
+	}
+
 	key := common.RedisSnapKeyPrefix + strconv.FormatInt(hostID, 10)
 	if err := h.redisCli.Set(context.Background(), key, *snapshot, time.Minute*10).Err(); err != nil {
 		blog.Errorf("saveHostsnap failed, set key: %s to redis err: %v, rid: %s", key, err, rid)
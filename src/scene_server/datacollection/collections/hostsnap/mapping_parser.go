@@ -0,0 +1,321 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hostsnap
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"configcenter/src/common"
+
+	"github.com/tidwall/gjson"
+)
+
+const (
+	defaultCloudIDPath = "cloudid"
+	defaultIPPath      = "ip"
+)
+
+// sourceRegexp limits the source name, it's used as the porter name and in the report url.
+var sourceRegexp = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]{0,31}$`)
+
+// SourceConfig is the snapshot parser config of a non-gse collector.
+type SourceConfig struct {
+	// Source is the name of the message source.
+	Source string `json:"source" mapstructure:"source"`
+	// Channel is the snap redis channel that the collector publish snapshot to,
+	// empty means the collector only reports snapshot by http.
+	Channel string `json:"channel" mapstructure:"channel"`
+	// CloudIDPath is the path of cloud id in the payload, default is cloudid.
+	CloudIDPath string `json:"cloud_id_path" mapstructure:"cloudIDPath"`
+	// IPPaths are the paths of host ips in the payload, default is ip.
+	IPPaths []string `json:"ip_paths" mapstructure:"ipPaths"`
+	// Fields are the mappings from payload to host attributes.
+	Fields []FieldMapping `json:"fields" mapstructure:"fields"`
+	// Reporters are the service accounts whose api tokens can report the snapshot of the source by http,
+	// empty means the source can not be reported by http.
+	Reporters []string `json:"reporters" mapstructure:"reporters"`
+}
+
+// merge returns the preset config with the channel and the host paths of the configured one.
+func (s SourceConfig) merge(config SourceConfig) SourceConfig {
+	merged := s
+	merged.Channel = config.Channel
+	merged.Reporters = config.Reporters
+	if len(config.CloudIDPath) != 0 {
+		merged.CloudIDPath = config.CloudIDPath
+	}
+	if len(config.IPPaths) != 0 {
+		merged.IPPaths = config.IPPaths
+	}
+	return merged
+}
+
+// FieldMapping maps a value in the collector payload to a host attribute.
+type FieldMapping struct {
+	// PropertyID is the host attribute, it must be one of the snapshot fields.
+	PropertyID string `json:"bk_property_id" mapstructure:"bk_property_id"`
+	// Path is the gjson path of the value in the payload.
+	Path string `json:"path" mapstructure:"path"`
+	// Transforms are the names of transforms applied to the value in order.
+	Transforms []string `json:"transforms" mapstructure:"transforms"`
+}
+
+type transformFunc func(val interface{}) interface{}
+
+// transforms converts the collected value to the format of host attribute
+var transforms = map[string]transformFunc{
+	"trim": func(val interface{}) interface{} {
+		return strings.TrimSpace(toString(val))
+	},
+	"lower": func(val interface{}) interface{} {
+		return strings.ToLower(toString(val))
+	},
+	"int": func(val interface{}) interface{} {
+		return int64(toFloat(val))
+	},
+	"first": func(val interface{}) interface{} {
+		if arr, ok := val.([]interface{}); ok {
+			if len(arr) == 0 {
+				return nil
+			}
+			return arr[0]
+		}
+		return val
+	},
+	"count": func(val interface{}) interface{} {
+		switch v := val.(type) {
+		case []interface{}:
+			return int64(len(v))
+		case nil:
+			return int64(0)
+		default:
+			return int64(1)
+		}
+	},
+	"sum": func(val interface{}) interface{} {
+		arr, ok := val.([]interface{})
+		if !ok {
+			return toFloat(val)
+		}
+		var sum float64
+		for _, item := range arr {
+			sum += toFloat(item)
+		}
+		return sum
+	},
+	"hz_to_mhz": func(val interface{}) interface{} {
+		return int64(toFloat(val) / 1e6)
+	},
+	"kb_to_mb": func(val interface{}) interface{} {
+		return uint64(toFloat(val)) >> 10
+	},
+	"bytes_to_mb": func(val interface{}) interface{} {
+		return uint64(toFloat(val)) >> 10 >> 10
+	},
+	"bytes_to_gb": func(val interface{}) interface{} {
+		return uint64(toFloat(val)) >> 10 >> 10 >> 10
+	},
+	"os_type": func(val interface{}) interface{} {
+		osType := strings.ToLower(strings.TrimSpace(toString(val)))
+		switch {
+		case strings.Contains(osType, "windows"):
+			return common.HostOSTypeEnumWindows
+		case osType == "aix":
+			return common.HostOSTypeEnumAIX
+		case osType == "linux":
+			return common.HostOSTypeEnumLinux
+		}
+		// linux distributions reported as platform
+		for _, distribution := range linuxDistributions {
+			if osType == distribution {
+				return common.HostOSTypeEnumLinux
+			}
+		}
+		return strings.TrimSpace(toString(val))
+	},
+	"os_bit": func(val interface{}) interface{} {
+		arch := strings.ToLower(strings.TrimSpace(toString(val)))
+		switch arch {
+		case "x86_64", "amd64", "aarch64", "arm64", "ppc64", "ppc64le", "s390x", "64-bit":
+			return "64-bit"
+		case "i386", "i686", "x86", "arm", "armv7l", "32-bit":
+			return "32-bit"
+		}
+		return arch
+	},
+}
+
+var linuxDistributions = []string{"centos", "rhel", "redhat", "ubuntu", "debian", "fedora", "suse", "opensuse",
+	"sles", "amzn", "arch", "gentoo", "tlinux", "tencentos", "ol", "rocky", "almalinux"}
+
+func toString(val interface{}) string {
+	switch v := val.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// toFloat converts the value to float, collectors like osquery report numbers as strings.
+func toFloat(val interface{}) float64 {
+	switch v := val.(type) {
+	case float64:
+		return v
+	case int64:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case bool:
+		if v {
+			return 1
+		}
+		return 0
+	default:
+		f, _ := strconv.ParseFloat(strings.TrimSpace(toString(v)), 64)
+		return f
+	}
+}
+
+// mappingParser parses the snapshot of non-gse collectors with configured field mappings.
+type mappingParser struct {
+	config SourceConfig
+	fields []string
+}
+
+// NewMappingParser creates a snapshot parser with field mappings.
+func NewMappingParser(config SourceConfig) (SnapshotParser, error) {
+	config.Source = strings.TrimSpace(config.Source)
+	if len(config.Source) == 0 {
+		return nil, errors.New("source can not be empty")
+	}
+
+	if config.Source == GseSource {
+		return nil, fmt.Errorf("source %s is reserved", GseSource)
+	}
+
+	if !sourceRegexp.MatchString(config.Source) {
+		return nil, fmt.Errorf("source %s is invalid, must match %s", config.Source, sourceRegexp.String())
+	}
+
+	if len(config.CloudIDPath) == 0 {
+		config.CloudIDPath = defaultCloudIDPath
+	}
+
+	if len(config.IPPaths) == 0 {
+		config.IPPaths = []string{defaultIPPath}
+	}
+
+	if len(config.Fields) == 0 {
+		return nil, errors.New("fields can not be empty")
+	}
+
+	snapshotFields := make(map[string]struct{}, len(compareFields))
+	for _, field := range compareFields {
+		snapshotFields[field] = struct{}{}
+	}
+
+	fields := make([]string, 0, len(config.Fields))
+	mapped := make(map[string]struct{}, len(config.Fields))
+	for _, mapping := range config.Fields {
+		if _, ok := snapshotFields[mapping.PropertyID]; !ok {
+			return nil, fmt.Errorf("field %s is not a host snapshot field", mapping.PropertyID)
+		}
+
+		if _, ok := mapped[mapping.PropertyID]; ok {
+			return nil, fmt.Errorf("field %s is mapped more than once", mapping.PropertyID)
+		}
+		mapped[mapping.PropertyID] = struct{}{}
+
+		if len(mapping.Path) == 0 {
+			return nil, fmt.Errorf("path of field %s can not be empty", mapping.PropertyID)
+		}
+
+		for _, name := range mapping.Transforms {
+			if _, ok := transforms[name]; !ok {
+				return nil, fmt.Errorf("transform %s of field %s is not supported", name, mapping.PropertyID)
+			}
+		}
+		fields = append(fields, mapping.PropertyID)
+	}
+
+	return &mappingParser{config: config, fields: fields}, nil
+}
+
+func (p *mappingParser) Source() string {
+	return p.config.Source
+}
+
+func (p *mappingParser) Fields() []string {
+	return p.fields
+}
+
+func (p *mappingParser) Decode(msg string) gjson.Result {
+	return gjson.Parse(msg)
+}
+
+func (p *mappingParser) ParseHost(val *gjson.Result) (int64, []string) {
+	ips := make([]string, 0)
+	for _, path := range p.config.IPPaths {
+		result := val.Get(path)
+		if result.IsArray() {
+			for _, item := range result.Array() {
+				ips = appendValidIP(ips, item.String())
+			}
+			continue
+		}
+		ips = appendValidIP(ips, result.String())
+	}
+	return int64(toFloat(val.Get(p.config.CloudIDPath).Value())), ips
+}
+
+func (p *mappingParser) ParseSetter(val *gjson.Result, innerIP, outerIP string) (map[string]interface{}, string) {
+	setter := make(map[string]interface{}, len(p.config.Fields))
+	for _, mapping := range p.config.Fields {
+		result := val.Get(mapping.Path)
+		if !result.Exists() {
+			// the collector does not report this field, do not compare or update it.
+			continue
+		}
+
+		value := result.Value()
+		for _, name := range mapping.Transforms {
+			value = transforms[name](value)
+		}
+		if value == nil {
+			value = ""
+		}
+		setter[mapping.PropertyID] = value
+	}
+
+	raw, err := json.Marshal(setter)
+	if err != nil {
+		// the setter only contains basic types, should not happen.
+		return setter, "{}"
+	}
+	return setter, string(raw)
+}
+
+// ParseSnapshot non-gse snapshot has no realtime status, do not save it.
+func (p *mappingParser) ParseSnapshot(val *gjson.Result) (*string, error) {
+	return nil, nil
+}
@@ -0,0 +1,233 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hostsnap
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	cc "configcenter/src/common/backbone/configcenter"
+	"configcenter/src/common/blog"
+
+	"github.com/tidwall/gjson"
+)
+
+// GseSource is the message source of gse bkmonitorbeat snapshot, it's the default source.
+const GseSource = "gse"
+
+// SnapshotParser parses the snapshot message reported by one kind of collector.
+type SnapshotParser interface {
+	// Source returns the message source that the parser handles.
+	Source() string
+
+	// Fields returns the host attributes parsed from the snapshot, only these fields are compared and updated.
+	Fields() []string
+
+	// Decode decodes the message into the snapshot payload.
+	Decode(msg string) gjson.Result
+
+	// ParseHost returns the cloud id and ips used to find the host that the snapshot belongs to.
+	ParseHost(val *gjson.Result) (int64, []string)
+
+	// ParseSetter parses the host attributes to be updated, and the raw json of these attributes used to compare.
+	ParseSetter(val *gjson.Result, innerIP, outerIP string) (map[string]interface{}, string)
+
+	// ParseSnapshot parses the realtime snapshot saved in redis for host snapshot api, returns nil if not supported.
+	ParseSnapshot(val *gjson.Result) (*string, error)
+}
+
+var (
+	parserLock sync.RWMutex
+	parsers    = make(map[string]SnapshotParser)
+	// reporters are the service accounts that can report the snapshot of the source by http
+	reporters = make(map[string]map[string]struct{})
+)
+
+// RegisterParser registers a snapshot parser, the parser with the same source is replaced.
+func RegisterParser(parser SnapshotParser) error {
+	if parser == nil || len(parser.Source()) == 0 {
+		return errors.New("snapshot parser source can not be empty")
+	}
+
+	parserLock.Lock()
+	defer parserLock.Unlock()
+	parsers[parser.Source()] = parser
+	return nil
+}
+
+// GetParser returns the snapshot parser of the message source.
+func GetParser(source string) (SnapshotParser, bool) {
+	parserLock.RLock()
+	defer parserLock.RUnlock()
+	parser, exist := parsers[source]
+	return parser, exist
+}
+
+// CanReport checks if the user can report the snapshot of the source by http, only the reporters configured
+// in the source can report, the gse source is never reported by http.
+func CanReport(source, user string) bool {
+	if source == GseSource || len(user) == 0 {
+		return false
+	}
+
+	parserLock.RLock()
+	defer parserLock.RUnlock()
+	_, exist := reporters[source][user]
+	return exist
+}
+
+// setReporters replaces the reporters of the configured sources.
+func setReporters(configs []SourceConfig) {
+	all := make(map[string]map[string]struct{})
+	for _, config := range configs {
+		users := make(map[string]struct{})
+		for _, user := range config.Reporters {
+			if user = strings.TrimSpace(user); len(user) != 0 {
+				users[user] = struct{}{}
+			}
+		}
+		all[config.Source] = users
+	}
+
+	parserLock.Lock()
+	defer parserLock.Unlock()
+	reporters = all
+}
+
+// ListParserSources returns the message sources of all the registered snapshot parsers.
+func ListParserSources() []string {
+	parserLock.RLock()
+	defer parserLock.RUnlock()
+	sources := make([]string, 0, len(parsers))
+	for source := range parsers {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+	return sources
+}
+
+func init() {
+	if err := RegisterParser(new(gseParser)); err != nil {
+		panic(err)
+	}
+
+	for _, preset := range presetSources {
+		parser, err := NewMappingParser(preset)
+		if err != nil {
+			panic(fmt.Sprintf("invalid preset snapshot parser %s, err: %v", preset.Source, err))
+		}
+		if err := RegisterParser(parser); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// LoadSourceConfigs loads the snapshot parsers of the non-gse collectors from configuration
+// datacollection.hostsnap.sources, the configured source with the same name of the preset one
+// overrides the preset parser. the preset sources that are not configured have no reporters, so
+// they can not be reported by http. returns the configs of all the mapping sources.
+func LoadSourceConfigs() ([]SourceConfig, error) {
+	configs := make([]SourceConfig, 0)
+	if cc.IsExist("datacollection.hostsnap.sources") {
+		if err := cc.UnmarshalKey("datacollection.hostsnap.sources", &configs); err != nil {
+			blog.Errorf("parse host snapshot sources config failed, err: %v", err)
+			return nil, err
+		}
+	}
+
+	sources := make([]SourceConfig, 0)
+	configured := make(map[string]struct{})
+	for _, config := range configs {
+		if config.Source == GseSource {
+			return nil, fmt.Errorf("snapshot source %s is reserved", GseSource)
+		}
+
+		if _, exist := configured[config.Source]; exist {
+			return nil, fmt.Errorf("snapshot source %s is duplicated", config.Source)
+		}
+
+		// use the preset field mappings if the source only configured the channel
+		if len(config.Fields) == 0 {
+			for _, preset := range presetSources {
+				if preset.Source == config.Source {
+					config = preset.merge(config)
+					break
+				}
+			}
+		}
+
+		parser, err := NewMappingParser(config)
+		if err != nil {
+			blog.Errorf("invalid host snapshot source config %+v, err: %v", config, err)
+			return nil, err
+		}
+
+		if err := RegisterParser(parser); err != nil {
+			return nil, err
+		}
+		configured[config.Source] = struct{}{}
+		sources = append(sources, config)
+	}
+	setReporters(sources)
+
+	for _, preset := range presetSources {
+		if _, exist := configured[preset.Source]; !exist {
+			sources = append(sources, preset)
+		}
+	}
+	return sources, nil
+}
+
+// SourceAnalyzer is the collections.Analyzer of the snapshot messages reported by a non-gse collector.
+type SourceAnalyzer struct {
+	snap   *HostSnap
+	parser SnapshotParser
+}
+
+// NewSourceAnalyzer returns the analyzer of the source, the parser of the source must be registered.
+func (h *HostSnap) NewSourceAnalyzer(source string) (*SourceAnalyzer, error) {
+	parser, exist := GetParser(source)
+	if !exist {
+		return nil, fmt.Errorf("snapshot parser of source %s not exist", source)
+	}
+	return &SourceAnalyzer{snap: h, parser: parser}, nil
+}
+
+// Analyze analyzes the snapshot message of the source.
+func (a *SourceAnalyzer) Analyze(msg *string) error {
+	return a.snap.AnalyzeWithSource(a.parser.Source(), msg)
+}
+
+// Hash returns a hash value of the host.
+func (a *SourceAnalyzer) Hash(cloudid, ip string) (string, error) {
+	return a.snap.Hash(cloudid, ip)
+}
+
+// HashMessage returns a hash value of the host that the message belongs to.
+func (a *SourceAnalyzer) HashMessage(msg string) (string, error) {
+	val := a.parser.Decode(msg)
+	cloudID, ips := a.parser.ParseHost(&val)
+	if len(ips) == 0 {
+		return "", fmt.Errorf("can't make hash from invalid message format, ip empty")
+	}
+	return a.snap.Hash(strconv.FormatInt(cloudID, 10), ips[0])
+}
+
+// Mock returns an empty message, the mapping sources have no mock message.
+func (a *SourceAnalyzer) Mock() string {
+	return "{}"
+}
@@ -0,0 +1,136 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hostsnap
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// parserFixture is the snapshot payload of a source and the expected parse result.
+type parserFixture struct {
+	Source  string                 `json:"source"`
+	Payload json.RawMessage        `json:"payload"`
+	CloudID int64                  `json:"cloud_id"`
+	IPs     []string               `json:"ips"`
+	Setter  map[string]interface{} `json:"setter"`
+}
+
+func TestSnapshotParsers(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "*.json"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no parser fixtures found, err: %v", err)
+	}
+
+	for _, file := range files {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatalf("read fixture %s failed, err: %v", file, err)
+		}
+
+		fixture := new(parserFixture)
+		if err := json.Unmarshal(content, fixture); err != nil {
+			t.Fatalf("decode fixture %s failed, err: %v", file, err)
+		}
+
+		parser, exist := GetParser(fixture.Source)
+		if !exist {
+			t.Fatalf("parser of source %s in fixture %s not registered", fixture.Source, file)
+		}
+
+		val := parser.Decode(string(fixture.Payload))
+		cloudID, ips := parser.ParseHost(&val)
+		if cloudID != fixture.CloudID || !reflect.DeepEqual(ips, fixture.IPs) {
+			t.Errorf("fixture %s parse host got cloud id %d, ips %v, expect %d, %v", file, cloudID, ips,
+				fixture.CloudID, fixture.IPs)
+		}
+
+		_, raw := parser.ParseSetter(&val, strings.Join(ips, ","), "")
+		setter := make(map[string]interface{})
+		if err := json.Unmarshal([]byte(raw), &setter); err != nil {
+			t.Fatalf("fixture %s got invalid setter %s, err: %v", file, raw, err)
+		}
+
+		for _, field := range parser.Fields() {
+			if !reflect.DeepEqual(setter[field], fixture.Setter[field]) {
+				t.Errorf("fixture %s field %s got %#v, expect %#v", file, field, setter[field], fixture.Setter[field])
+			}
+		}
+	}
+}
+
+func TestNewMappingParser(t *testing.T) {
+	fields := []FieldMapping{{PropertyID: "bk_host_name", Path: "hostname"}}
+	invalid := map[string]SourceConfig{
+		"empty source":          {Fields: fields},
+		"reserved source":       {Source: GseSource, Fields: fields},
+		"invalid source":        {Source: "my/source", Fields: fields},
+		"empty fields":          {Source: "custom"},
+		"not snapshot field":    {Source: "custom", Fields: []FieldMapping{{PropertyID: "bk_comment", Path: "comment"}}},
+		"empty path":            {Source: "custom", Fields: []FieldMapping{{PropertyID: "bk_host_name"}}},
+		"unsupported transform": {Source: "custom", Fields: []FieldMapping{{PropertyID: "bk_host_name", Path: "hostname", Transforms: []string{"upper"}}}},
+		"duplicate field":       {Source: "custom", Fields: append(fields, fields...)},
+	}
+	for name, config := range invalid {
+		if _, err := NewMappingParser(config); err == nil {
+			t.Errorf("%s config should be invalid", name)
+		}
+	}
+
+	parser, err := NewMappingParser(SourceConfig{Source: "custom", Fields: fields})
+	if err != nil {
+		t.Fatalf("new mapping parser failed, err: %v", err)
+	}
+
+	val := parser.Decode(`{"cloudid":3,"ip":"10.0.0.1","hostname":" web-01 "}`)
+	cloudID, ips := parser.ParseHost(&val)
+	if cloudID != 3 || !reflect.DeepEqual(ips, []string{"10.0.0.1"}) {
+		t.Errorf("default host paths got cloud id %d, ips %v", cloudID, ips)
+	}
+
+	// the fields not reported should not be in the setter
+	val = parser.Decode(`{"cloudid":3,"ip":"10.0.0.1"}`)
+	if setter, raw := parser.ParseSetter(&val, "10.0.0.1", ""); len(setter) != 0 || raw != "{}" {
+		t.Errorf("empty payload got setter %s", raw)
+	}
+}
+
+func TestCanReport(t *testing.T) {
+	defer setReporters(nil)
+	setReporters([]SourceConfig{
+		{Source: "node_exporter", Reporters: []string{" svc_node "}},
+		{Source: "osquery"},
+	})
+
+	cases := []struct {
+		source string
+		user   string
+		expect bool
+	}{
+		{source: "node_exporter", user: "svc_node", expect: true},
+		{source: "node_exporter", user: "admin", expect: false},
+		{source: "node_exporter", user: "", expect: false},
+		{source: "osquery", user: "svc_node", expect: false},
+		{source: GseSource, user: "svc_node", expect: false},
+		{source: "custom", user: "svc_node", expect: false},
+	}
+	for _, c := range cases {
+		if got := CanReport(c.source, c.user); got != c.expect {
+			t.Errorf("source %s user %s can report: %v, expect %v", c.source, c.user, got, c.expect)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hostsnap
+
+import (
+	"configcenter/src/common"
+)
+
+// presetSources are the builtin snapshot parsers of the commonly used collectors, their payload
+// layouts are shown by the fixtures in testdata. they can be overridden by configuration.
+var presetSources = []SourceConfig{nodeExporterSource, osquerySource}
+
+// nodeExporterSource parses the node_exporter metrics converted to json, each metric is an array of
+// samples with labels and value, like: {"metrics":{"node_uname_info":[{"labels":{...},"value":1}]}}
+var nodeExporterSource = SourceConfig{
+	Source:      "node_exporter",
+	CloudIDPath: defaultCloudIDPath,
+	IPPaths:     []string{defaultIPPath},
+	Fields: []FieldMapping{
+		{PropertyID: "bk_host_name", Path: "metrics.node_uname_info.0.labels.nodename", Transforms: []string{"trim"}},
+		{PropertyID: "bk_os_type", Path: "metrics.node_uname_info.0.labels.sysname", Transforms: []string{"os_type"}},
+		{PropertyID: "bk_os_name", Path: "metrics.node_os_info.0.labels.pretty_name", Transforms: []string{"trim"}},
+		{PropertyID: "bk_os_version", Path: "metrics.node_os_info.0.labels.version_id", Transforms: []string{"trim"}},
+		{PropertyID: "bk_os_bit", Path: "metrics.node_uname_info.0.labels.machine", Transforms: []string{"os_bit"}},
+		{PropertyID: "bk_cpu", Path: "metrics.node_cpu_info.#", Transforms: []string{"int"}},
+		{PropertyID: "bk_cpu_module", Path: "metrics.node_cpu_info.0.labels.model_name", Transforms: []string{"trim"}},
+		{PropertyID: "bk_cpu_mhz", Path: "metrics.node_cpu_frequency_max_hertz.0.value", Transforms: []string{"hz_to_mhz"}},
+		{PropertyID: "bk_mem", Path: "metrics.node_memory_MemTotal_bytes.0.value", Transforms: []string{"bytes_to_mb"}},
+		{PropertyID: "bk_disk", Path: "metrics.node_filesystem_size_bytes.#.value", Transforms: []string{"sum", "bytes_to_gb"}},
+	},
+}
+
+// osquerySource parses the results of osquery tables, each table is an array of rows whose values
+// are strings, like: {"tables":{"system_info":[{"hostname":"..."}],"os_version":[{"platform":"..."}]}}
+var osquerySource = SourceConfig{
+	Source:      "osquery",
+	CloudIDPath: defaultCloudIDPath,
+	IPPaths:     []string{defaultIPPath, "tables.interface_addresses.#.address"},
+	Fields: []FieldMapping{
+		{PropertyID: "bk_host_name", Path: "tables.system_info.0.hostname", Transforms: []string{"trim"}},
+		{PropertyID: "bk_cpu", Path: "tables.system_info.0.cpu_logical_cores", Transforms: []string{"int"}},
+		{PropertyID: "bk_cpu_module", Path: "tables.system_info.0.cpu_brand", Transforms: []string{"trim"}},
+		{PropertyID: "bk_mem", Path: "tables.system_info.0.physical_memory", Transforms: []string{"bytes_to_mb"}},
+		{PropertyID: "bk_os_type", Path: "tables.os_version.0.platform", Transforms: []string{"os_type"}},
+		{PropertyID: "bk_os_name", Path: "tables.os_version.0.name", Transforms: []string{"trim"}},
+		{PropertyID: "bk_os_version", Path: "tables.os_version.0.version", Transforms: []string{"trim"}},
+		{PropertyID: "bk_os_bit", Path: "tables.os_version.0.arch", Transforms: []string{"os_bit"}},
+		{PropertyID: common.HostFieldDockerServerVersion, Path: "tables.docker_version.0.version", Transforms: []string{"trim"}},
+	},
+}
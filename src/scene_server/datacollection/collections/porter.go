@@ -255,6 +255,15 @@ func (p *SimplePorter) analyzeLoop() {
 }
 
 // collectLoop keeps subscribe redis topic and collecting messages from collectors.
+// hashMessage returns the sharding hash key of the message, analyzers that know
+// their own message format could implement MessageHasher to extract the host keys.
+func (p *SimplePorter) hashMessage(payload string) (string, error) {
+	if hasher, ok := p.analyzer.(MessageHasher); ok {
+		return hasher.HashMessage(payload)
+	}
+	return p.analyzer.Hash(gjson.Get(payload, "cloudid").String(), gjson.Get(payload, "ip").String())
+}
+
 func (p *SimplePorter) collectLoop() error {
 	for {
 		// subscribe target topics and handle message base on the redis pubsun channel.
@@ -288,7 +297,7 @@ func (p *SimplePorter) collectLoop() error {
 			}
 
 			// message data sharding hashring check.
-			hashKey, err := p.analyzer.Hash(gjson.Get(newMsg.Payload, "cloudid").String(), gjson.Get(newMsg.Payload, "ip").String())
+			hashKey, err := p.hashMessage(newMsg.Payload)
 			if err != nil {
 				blog.Errorf("SimplePorter[%s]| calculates message hash key failed, %+v", p.name, err)
 
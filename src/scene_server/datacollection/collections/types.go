@@ -24,6 +24,13 @@ type Analyzer interface {
 	Mock() string
 }
 
+// MessageHasher is an optional interface of Analyzer, porters would use it to calculate
+// the sharding hash key when the message is not in the default {"cloudid","ip"} format.
+type MessageHasher interface {
+	// HashMessage returns a hash value of the whole message.
+	HashMessage(message string) (string, error)
+}
+
 // Porter is common porter interface. It handles
 // message from collectors base on Analyzer.
 type Porter interface {
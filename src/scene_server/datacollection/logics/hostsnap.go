@@ -0,0 +1,121 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package logics
+
+import (
+	"net/http"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+)
+
+// SearchHostSnapshotHistory search the snapshot history of a host, the newest records are returned first by default
+func (lgc *Logics) SearchHostSnapshotHistory(header http.Header, option metadata.SearchHostSnapshotHistoryOption) (
+	*metadata.HostSnapshotHistoryResult, error) {
+
+	rid := util.GetHTTPCCRequestID(header)
+
+	filter := map[string]interface{}{
+		common.BKHostIDField: option.HostID,
+	}
+
+	if len(option.Source) > 0 {
+		filter["source"] = option.Source
+	}
+
+	timeCond := make(map[string]interface{})
+	if option.StartTime != nil {
+		timeCond[common.BKDBGTE] = *option.StartTime
+	}
+	if option.EndTime != nil {
+		timeCond[common.BKDBLTE] = *option.EndTime
+	}
+	if len(timeCond) > 0 {
+		filter[common.CreateTimeField] = timeCond
+	}
+
+	if len(option.Fields) > 0 {
+		filter["changed_fields"] = map[string]interface{}{
+			common.BKDBIN: option.Fields,
+		}
+	}
+	filter = util.SetQueryOwner(filter, util.GetOwnerID(header))
+
+	count, err := lgc.db.Table(common.BKTableNameHostSnapshotHistory).Find(filter).Count(lgc.ctx)
+	if err != nil {
+		blog.Errorf("count host snapshot history failed, filter: %+v, err: %v, rid: %s", filter, err, rid)
+		return nil, err
+	}
+
+	sort := option.Page.Sort
+	if len(sort) == 0 {
+		sort = "-" + common.CreateTimeField
+	}
+
+	histories := make([]metadata.HostSnapshotHistory, 0)
+	err = lgc.db.Table(common.BKTableNameHostSnapshotHistory).Find(filter).Sort(sort).Start(uint64(option.Page.Start)).
+		Limit(uint64(option.Page.Limit)).All(lgc.ctx, &histories)
+	if err != nil {
+		blog.Errorf("search host snapshot history failed, filter: %+v, err: %v, rid: %s", filter, err, rid)
+		return nil, err
+	}
+
+	return &metadata.HostSnapshotHistoryResult{
+		Count: int64(count),
+		Info:  histories,
+	}, nil
+}
+
+// SearchHostSnapshotDrift search the hosts whose manually maintained fields disagree with the reported snapshot
+func (lgc *Logics) SearchHostSnapshotDrift(header http.Header, option metadata.SearchHostSnapshotDriftOption) (
+	*metadata.HostSnapshotDriftResult, error) {
+
+	rid := util.GetHTTPCCRequestID(header)
+
+	filter := make(map[string]interface{})
+	if len(option.HostIDs) > 0 {
+		filter[common.BKHostIDField] = map[string]interface{}{
+			common.BKDBIN: option.HostIDs,
+		}
+	}
+	if len(option.PropertyID) > 0 {
+		filter["fields."+common.BKPropertyIDField] = option.PropertyID
+	}
+	filter = util.SetQueryOwner(filter, util.GetOwnerID(header))
+
+	count, err := lgc.db.Table(common.BKTableNameHostSnapshotDrift).Find(filter).Count(lgc.ctx)
+	if err != nil {
+		blog.Errorf("count host snapshot drift failed, filter: %+v, err: %v, rid: %s", filter, err, rid)
+		return nil, err
+	}
+
+	sort := option.Page.Sort
+	if len(sort) == 0 {
+		sort = "-" + common.LastTimeField
+	}
+
+	drifts := make([]metadata.HostSnapshotDrift, 0)
+	err = lgc.db.Table(common.BKTableNameHostSnapshotDrift).Find(filter).Sort(sort).Start(uint64(option.Page.Start)).
+		Limit(uint64(option.Page.Limit)).All(lgc.ctx, &drifts)
+	if err != nil {
+		blog.Errorf("search host snapshot drift failed, filter: %+v, err: %v, rid: %s", filter, err, rid)
+		return nil, err
+	}
+
+	return &metadata.HostSnapshotDriftResult{
+		Count: int64(count),
+		Info:  drifts,
+	}, nil
+}
@@ -0,0 +1,141 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/scene_server/datacollection/collections/hostsnap"
+
+	"github.com/emicklei/go-restful"
+)
+
+// SearchHostSnapshotHistory search the hardware and os change history of a host reported by snapshot
+func (s *Service) SearchHostSnapshotHistory(req *restful.Request, resp *restful.Response) {
+	pHeader := req.Request.Header
+	rid := util.GetHTTPCCRequestID(pHeader)
+	defErr := s.engine.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(pHeader))
+
+	option := metadata.SearchHostSnapshotHistoryOption{}
+	if err := json.NewDecoder(req.Request.Body).Decode(&option); err != nil {
+		blog.Errorf("search host snapshot history, but decode body failed, err: %v, rid: %s", err, rid)
+		_ = resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Error(common.CCErrCommJSONUnmarshalFailed)})
+		return
+	}
+
+	if rawErr := option.Validate(); rawErr.ErrCode != 0 {
+		blog.Errorf("search host snapshot history, but option is invalid, option: %+v, rid: %s", option, rid)
+		_ = resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: rawErr.ToCCError(defErr)})
+		return
+	}
+
+	result, err := s.logics.SearchHostSnapshotHistory(pHeader, option)
+	if err != nil {
+		blog.Errorf("search host snapshot history failed, option: %+v, err: %v, rid: %s", option, err, rid)
+		_ = resp.WriteError(http.StatusInternalServerError, &metadata.RespError{Msg: defErr.Error(common.CCErrCommDBSelectFailed)})
+		return
+	}
+
+	_ = resp.WriteEntity(metadata.NewSuccessResp(result))
+}
+
+// SearchHostSnapshotDrift search the report of hosts whose manually maintained fields disagree with the snapshot
+func (s *Service) SearchHostSnapshotDrift(req *restful.Request, resp *restful.Response) {
+	pHeader := req.Request.Header
+	rid := util.GetHTTPCCRequestID(pHeader)
+	defErr := s.engine.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(pHeader))
+
+	option := metadata.SearchHostSnapshotDriftOption{}
+	if err := json.NewDecoder(req.Request.Body).Decode(&option); err != nil {
+		blog.Errorf("search host snapshot drift, but decode body failed, err: %v, rid: %s", err, rid)
+		_ = resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Error(common.CCErrCommJSONUnmarshalFailed)})
+		return
+	}
+
+	if rawErr := option.Validate(); rawErr.ErrCode != 0 {
+		blog.Errorf("search host snapshot drift, but option is invalid, option: %+v, rid: %s", option, rid)
+		_ = resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: rawErr.ToCCError(defErr)})
+		return
+	}
+
+	result, err := s.logics.SearchHostSnapshotDrift(pHeader, option)
+	if err != nil {
+		blog.Errorf("search host snapshot drift failed, option: %+v, err: %v, rid: %s", option, err, rid)
+		_ = resp.WriteError(http.StatusInternalServerError, &metadata.RespError{Msg: defErr.Error(common.CCErrCommDBSelectFailed)})
+		return
+	}
+
+	_ = resp.WriteEntity(metadata.NewSuccessResp(result))
+}
+
+// maxReportSnapshotSize is the max body size of the snapshot reported by http
+const maxReportSnapshotSize = 4 << 20
+
+// ReportHostSnapshot receives the snapshot reported by http from the collector of the source,
+// the snapshot is parsed by the parser of the source and updated to host synchronously.
+// the request must be sent with the api token of a reporter service account configured in the source.
+func (s *Service) ReportHostSnapshot(req *restful.Request, resp *restful.Response) {
+	pHeader := req.Request.Header
+	rid := util.GetHTTPCCRequestID(pHeader)
+	defErr := s.engine.CCErr.CreateDefaultCCErrorIf(util.GetLanguage(pHeader))
+
+	source := req.PathParameter("source")
+	if source == hostsnap.GseSource {
+		blog.Errorf("report host snapshot, but source %s is reserved, rid: %s", source, rid)
+		_ = resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Errorf(common.CCErrCommParamsIsInvalid, "source")})
+		return
+	}
+
+	if _, exist := hostsnap.GetParser(source); !exist {
+		blog.Errorf("report host snapshot, but source %s has no parser, rid: %s", source, rid)
+		_ = resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Errorf(common.CCErrCommParamsIsInvalid, "source")})
+		return
+	}
+
+	// the api token id header is only set by apiserver after the token is authenticated
+	user := util.GetUser(pHeader)
+	if len(pHeader.Get(common.BKHTTPAPITokenID)) == 0 || !hostsnap.CanReport(source, user) {
+		blog.Errorf("user %s is not the reporter of host snapshot source %s, token: %s, rid: %s", user, source,
+			pHeader.Get(common.BKHTTPAPITokenID), rid)
+		_ = resp.WriteError(http.StatusForbidden, &metadata.RespError{Msg: defErr.Error(common.CCErrCommAuthNotHavePermission)})
+		return
+	}
+
+	if s.hostSnap == nil {
+		blog.Errorf("report host snapshot, but host snapshot analyzer is not ready, rid: %s", rid)
+		_ = resp.WriteError(http.StatusInternalServerError, &metadata.RespError{Msg: defErr.Error(common.CCErrCommInternalServerError)})
+		return
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(resp.ResponseWriter, req.Request.Body, maxReportSnapshotSize))
+	if err != nil {
+		blog.Errorf("report host snapshot, but read body failed, err: %v, rid: %s", err, rid)
+		_ = resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Error(common.CCErrCommHTTPReadBodyFailed)})
+		return
+	}
+
+	msg := string(body)
+	if err := s.hostSnap.AnalyzeWithSource(source, &msg); err != nil {
+		blog.Errorf("report host snapshot of source %s failed, err: %v, rid: %s", source, err, rid)
+		_ = resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: defErr.Errorf(common.CCErrCommParamsIsInvalid, err.Error())})
+		return
+	}
+
+	_ = resp.WriteEntity(metadata.NewSuccessResp(nil))
+}
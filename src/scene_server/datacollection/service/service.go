@@ -21,8 +21,11 @@ import (
 	"configcenter/src/common/errors"
 	"configcenter/src/common/metadata"
 	"configcenter/src/common/metric"
+	"configcenter/src/common/openapi"
 	"configcenter/src/common/rdapi"
 	"configcenter/src/common/types"
+	"configcenter/src/common/version"
+	"configcenter/src/scene_server/datacollection/collections/hostsnap"
 	"configcenter/src/scene_server/datacollection/logics"
 	"configcenter/src/storage/dal"
 	"configcenter/src/storage/dal/redis"
@@ -42,6 +45,8 @@ type Service struct {
 	disCli  redis.Client
 	netCli  redis.Client
 
+	hostSnap *hostsnap.HostSnap
+
 	logics *logics.Logics
 }
 
@@ -70,6 +75,11 @@ func (s *Service) SetSnapCli(db redis.Client) {
 	s.snapCli = db
 }
 
+// SetHostSnap setups the host snapshot analyzer used by snapshot report api.
+func (s *Service) SetHostSnap(snap *hostsnap.HostSnap) {
+	s.hostSnap = snap
+}
+
 // SetDiscoverCli setups discover redis.
 func (s *Service) SetDiscoverCli(db redis.Client) {
 	s.disCli = db
@@ -112,10 +122,15 @@ func (s *Service) WebService() *restful.Container {
 	api.Route(api.POST("/netcollect/collector/action/update").To(s.UpdateCollector))
 	api.Route(api.POST("/netcollect/collector/action/discover").To(s.DiscoverNetDevice))
 
+	api.Route(api.POST("/hostsnap/history/action/search").To(s.SearchHostSnapshotHistory))
+	api.Route(api.POST("/hostsnap/drift/action/search").To(s.SearchHostSnapshotDrift))
+	api.Route(api.POST("/hostsnap/source/{source}/action/report").To(s.ReportHostSnapshot))
+
 	container.Add(api)
 
 	healthzAPI := new(restful.WebService).Produces(restful.MIME_JSON)
 	healthzAPI.Route(healthzAPI.GET("/healthz").To(s.Healthz))
+	healthzAPI.Route(healthzAPI.GET("/openapi").To(openapi.RouteFunction(container, types.CC_MODULE_DATACOLLECTION, version.CCVersion)))
 	container.Add(healthzAPI)
 
 	return container
@@ -15,6 +15,7 @@ package options
 import (
 	"configcenter/src/common/auth"
 	"configcenter/src/common/core/cc/config"
+	"configcenter/src/scene_server/event_server/notification"
 	"configcenter/src/storage/dal/mongo"
 	"configcenter/src/storage/dal/redis"
 
@@ -50,4 +51,7 @@ type Config struct {
 
 	// Redis is cc redis configs.
 	Redis redis.Config
+
+	// SMTP is smtp server configs of the email notification channel.
+	SMTP notification.SMTPConfig
 }
@@ -19,6 +19,7 @@ import (
 	"sync"
 	"time"
 
+	"configcenter/src/ac/extensions"
 	"configcenter/src/ac/iam"
 	"configcenter/src/common/backbone"
 	cc "configcenter/src/common/backbone/configcenter"
@@ -28,6 +29,7 @@ import (
 	"configcenter/src/scene_server/event_server/app/options"
 	"configcenter/src/scene_server/event_server/distribution"
 	"configcenter/src/scene_server/event_server/identifier"
+	"configcenter/src/scene_server/event_server/notification"
 	svc "configcenter/src/scene_server/event_server/service"
 	"configcenter/src/storage/dal"
 	"configcenter/src/storage/dal/mongo/local"
@@ -74,6 +76,9 @@ type EventServer struct {
 
 	// distributor handles all events distribution.
 	distributor *distribution.Distributor
+
+	// notifier sends change notifications of the events matching the notify rules.
+	notifier *notification.Notifier
 }
 
 // NewEventServer creates a new EventServer object.
@@ -144,7 +149,12 @@ func (es *EventServer) OnHostConfigUpdate(prev, curr cc.ProcessConfig) {
 			blog.Infof("on host config update event: \n%s", data)
 		}
 
-		// TODO: add your configs updates here.
+		// smtp server of the email notification channel.
+		es.config.SMTP.Host, _ = cc.String("eventServer.notification.smtp.host")
+		es.config.SMTP.Port, _ = cc.Int("eventServer.notification.smtp.port")
+		es.config.SMTP.User, _ = cc.String("eventServer.notification.smtp.user")
+		es.config.SMTP.Password, _ = cc.String("eventServer.notification.smtp.password")
+		es.config.SMTP.From, _ = cc.String("eventServer.notification.smtp.from")
 	}
 
 }
@@ -207,6 +217,8 @@ func (es *EventServer) initModules() error {
 
 	// initialize auth authorizer
 	es.service.SetAuthorizer(iam.NewAuthorizer(es.engine.CoreAPI))
+	authManager := extensions.NewAuthManager(es.engine.CoreAPI)
+	es.service.SetAuthManager(authManager)
 
 	// init subscription stream watcher.
 	subWatcher, err := reflector.NewReflector(es.config.MongoDB.GetMongoConf())
@@ -232,6 +244,14 @@ func (es *EventServer) initModules() error {
 	es.eventHandler.SetDistributer(distributor)
 	blog.Infof("init modules, create event handler success")
 
+	// init change notification notifier.
+	notifier := notification.NewNotifier(es.ctx, es.db, es.config.SMTP)
+	notifier.SetAuthManager(authManager)
+	es.notifier = notifier
+	es.eventHandler.SetNotifier(notifier)
+	es.service.SetNotifier(notifier)
+	blog.Infof("init modules, create notifier success")
+
 	return nil
 }
 
@@ -252,6 +272,9 @@ func (es *EventServer) Run() error {
 	// run identifier handler.
 	go es.identifierHandler.Run()
 
+	// run change notification notifier.
+	es.notifier.Start()
+
 	// run main logic comm distributer.
 	if err := es.distributor.Start(es.eventHandler); err != nil {
 		return fmt.Errorf("distributer start failed, %+v", err)
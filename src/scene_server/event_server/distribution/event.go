@@ -26,6 +26,7 @@ import (
 	ccjson "configcenter/src/common/json"
 	"configcenter/src/common/metadata"
 	"configcenter/src/common/watch"
+	"configcenter/src/scene_server/event_server/notification"
 	etypes "configcenter/src/scene_server/event_server/types"
 	"configcenter/src/storage/dal/redis"
 	"configcenter/src/storage/stream/types"
@@ -80,6 +81,9 @@ type EventHandler struct {
 	// distributer handles all events distribution.
 	distributer *Distributor
 
+	// notifier sends notifications of the events matching the notify rules.
+	notifier *notification.Notifier
+
 	// metrics.
 	// eventHandleTotal is event handle total stat.
 	eventHandleTotal *prometheus.CounterVec
@@ -160,6 +164,11 @@ func (h *EventHandler) SetDistributer(distributer *Distributor) {
 	h.distributer = distributer
 }
 
+// SetNotifier setups notifier to event handler.
+func (h *EventHandler) SetNotifier(notifier *notification.Notifier) {
+	h.notifier = notifier
+}
+
 // Handle handles events distributed by distributer, add events to real handle queue to
 // handle host identifier infos. Handler would find all relate subscribers and send event message
 // to target subscribers by callback.
@@ -357,6 +366,11 @@ func (h *EventHandler) handleEvent(event *metadata.EventInst) error {
 	blog.Infof("handle event inst, %+v", event)
 	defer blog.Infof("handle event inst done, %+v", event)
 
+	// send notifications of the event asynchronously.
+	if h.notifier != nil {
+		h.notifier.Notify(event)
+	}
+
 	// find all subscribers on this event type.
 	subscribers := h.distributer.FindSubscribers(event.GetType())
 
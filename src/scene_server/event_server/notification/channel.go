@@ -0,0 +1,207 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"configcenter/src/common/metadata"
+)
+
+const httpSendTimeout = 10 * time.Second
+
+// Message is the rendered notification of a rule
+type Message struct {
+	RuleID    int64
+	RuleName  string
+	Title     string
+	Content   string
+	Receivers []string
+	Event     *metadata.EventInst
+}
+
+func errUnsupportedChannel(channelType metadata.NotifyChannelType) error {
+	return fmt.Errorf("unsupported channel type %s", channelType)
+}
+
+// Sender delivers the message to a type of channel
+type Sender interface {
+	Send(ctx context.Context, channel metadata.NotifyChannel, msg *Message) error
+}
+
+// SMTPConfig is the smtp server used by the email channel
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	User     string
+	Password string
+	From     string
+}
+
+type emailSender struct {
+	config   SMTPConfig
+	sendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewEmailSender create the email channel sender with the smtp server
+func NewEmailSender(config SMTPConfig) Sender {
+	return &emailSender{config: config, sendMail: smtp.SendMail}
+}
+
+// Send send the message by smtp to the receivers
+func (e *emailSender) Send(_ context.Context, _ metadata.NotifyChannel, msg *Message) error {
+	if len(e.config.Host) == 0 {
+		return errors.New("smtp server is not configured")
+	}
+	if len(msg.Receivers) == 0 {
+		return errors.New("no receivers")
+	}
+
+	port := e.config.Port
+	if port == 0 {
+		port = 25
+	}
+	from := e.config.From
+	if len(from) == 0 {
+		from = e.config.User
+	}
+
+	var auth smtp.Auth
+	if len(e.config.User) > 0 {
+		auth = smtp.PlainAuth("", e.config.User, e.config.Password, e.config.Host)
+	}
+
+	buf := new(bytes.Buffer)
+	buf.WriteString("From: " + from + "\r\n")
+	buf.WriteString("To: " + strings.Join(msg.Receivers, ",") + "\r\n")
+	buf.WriteString("Subject: " + mime.BEncoding.Encode("UTF-8", msg.Title) + "\r\n")
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	buf.WriteString(msg.Content)
+
+	addr := net.JoinHostPort(e.config.Host, strconv.Itoa(port))
+	return e.sendMail(addr, auth, from, msg.Receivers, buf.Bytes())
+}
+
+type httpSender struct {
+	client *http.Client
+	body   func(msg *Message) interface{}
+	// check checks the response body for the channels returning errors with 200 status code
+	check func(body []byte) error
+}
+
+// NewWebhookSender create the generic webhook sender which post the message and the event in json
+func NewWebhookSender() Sender {
+	return &httpSender{
+		client: &http.Client{Timeout: httpSendTimeout},
+		body: func(msg *Message) interface{} {
+			return map[string]interface{}{
+				"rule_id":   msg.RuleID,
+				"rule_name": msg.RuleName,
+				"title":     msg.Title,
+				"content":   msg.Content,
+				"receivers": msg.Receivers,
+				"event":     msg.Event,
+			}
+		},
+	}
+}
+
+// NewWeComSender create the WeCom group robot sender, the receivers are mentioned in the message
+func NewWeComSender() Sender {
+	return &httpSender{
+		client: &http.Client{Timeout: httpSendTimeout},
+		body: func(msg *Message) interface{} {
+			return map[string]interface{}{
+				"msgtype": "text",
+				"text": map[string]interface{}{
+					"content":        msg.Title + "\n" + msg.Content,
+					"mentioned_list": msg.Receivers,
+				},
+			}
+		},
+		check: func(body []byte) error {
+			result := struct {
+				ErrCode int    `json:"errcode"`
+				ErrMsg  string `json:"errmsg"`
+			}{}
+			if err := json.Unmarshal(body, &result); err != nil {
+				return fmt.Errorf("decode response failed, %v", err)
+			}
+			if result.ErrCode != 0 {
+				return fmt.Errorf("errcode: %d, errmsg: %s", result.ErrCode, result.ErrMsg)
+			}
+			return nil
+		},
+	}
+}
+
+// NewSlackSender create the slack incoming webhook sender
+func NewSlackSender() Sender {
+	return &httpSender{
+		client: &http.Client{Timeout: httpSendTimeout},
+		body: func(msg *Message) interface{} {
+			text := "*" + msg.Title + "*\n" + msg.Content
+			for _, receiver := range msg.Receivers {
+				text += " @" + receiver
+			}
+			return map[string]interface{}{"text": text}
+		},
+	}
+}
+
+// Send post the message to the channel url
+func (h *httpSender) Send(ctx context.Context, channel metadata.NotifyChannel, msg *Message) error {
+	data, err := json.Marshal(h.body(msg))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, channel.URL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("http status %d, response: %s", resp.StatusCode, string(body))
+	}
+	if h.check != nil {
+		return h.check(body)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package notification
+
+import (
+	"sync"
+	"time"
+
+	"configcenter/src/common/metadata"
+)
+
+// rateLimiter is a fixed window rate limiter of the notify rules
+type rateLimiter struct {
+	lock    sync.Mutex
+	windows map[int64]*limitWindow
+}
+
+type limitWindow struct {
+	start   time.Time
+	count   int64
+	dropped int64
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{windows: make(map[int64]*limitWindow)}
+}
+
+// allow checks whether the rule can send a notification at now,
+// firstDropped is true for the first dropped notification of the window.
+func (r *rateLimiter) allow(rule *metadata.NotifyRule, now time.Time) (allowed bool, firstDropped bool) {
+	if rule.RateLimit.Count <= 0 {
+		return true, false
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	window, exist := r.windows[rule.ID]
+	if !exist || now.Sub(window.start) >= rule.GetRateLimitInterval() {
+		window = &limitWindow{start: now}
+		r.windows[rule.ID] = window
+	}
+
+	if window.count < rule.RateLimit.Count {
+		window.count++
+		return true, false
+	}
+	window.dropped++
+	return false, window.dropped == 1
+}
+
+// prune removes the windows of the rules which are deleted or have expired windows
+func (r *rateLimiter) prune(rules []metadata.NotifyRule, now time.Time) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	intervals := make(map[int64]time.Duration)
+	for idx := range rules {
+		intervals[rules[idx].ID] = rules[idx].GetRateLimitInterval()
+	}
+	for id, window := range r.windows {
+		interval, exist := intervals[id]
+		if !exist || now.Sub(window.start) >= interval {
+			delete(r.windows, id)
+		}
+	}
+}
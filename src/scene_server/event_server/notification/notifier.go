@@ -0,0 +1,390 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package notification
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"configcenter/src/ac/extensions"
+	"configcenter/src/ac/meta"
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/storage/dal"
+)
+
+const (
+	// defaultRulesReloadInterval is the interval to reload the enabled notify rules from db.
+	defaultRulesReloadInterval = 30 * time.Second
+
+	// defaultQueueSize is the max number of events waiting to be notified, new events are dropped when the queue is full.
+	defaultQueueSize = 10000
+
+	// defaultSendTimeout is the timeout of delivering a notification.
+	defaultSendTimeout = 15 * time.Second
+)
+
+// Notifier matches the events with the notify rules, and delivers the rendered notifications to the channels.
+type Notifier struct {
+	ctx context.Context
+	db  dal.RDB
+
+	// rules is the enabled notify rules cache.
+	rules   []metadata.NotifyRule
+	rulesMu sync.RWMutex
+
+	senders map[metadata.NotifyChannelType]Sender
+	limiter *rateLimiter
+	queue   chan *metadata.EventInst
+
+	// authManager checks the restricted fields that the rule creators can view, nil means auth is disabled.
+	authManager *extensions.AuthManager
+	restricted  *restrictedFieldsCache
+
+	// loadRules, bizOfHost and saveLogs access the db, they are replaced in tests.
+	loadRules func(ctx context.Context) ([]metadata.NotifyRule, error)
+	bizOfHost func(ctx context.Context, hostID int64) (int64, error)
+	saveLogs  func(ctx context.Context, logs []metadata.NotifyDeliveryLog) error
+	// noPermissionFields returns the restricted fields of the object that the rule creator can not view,
+	// it's replaced in tests.
+	noPermissionFields func(ctx context.Context, rule *metadata.NotifyRule, objID string) ([]string, error)
+	now                func() time.Time
+}
+
+// NewNotifier creates a new Notifier with the default channel senders.
+func NewNotifier(ctx context.Context, db dal.RDB, smtpConfig SMTPConfig) *Notifier {
+	n := &Notifier{
+		ctx:        ctx,
+		db:         db,
+		limiter:    newRateLimiter(),
+		restricted: newRestrictedFieldsCache(),
+		queue:      make(chan *metadata.EventInst, defaultQueueSize),
+		senders: map[metadata.NotifyChannelType]Sender{
+			metadata.NotifyChannelEmail:   NewEmailSender(smtpConfig),
+			metadata.NotifyChannelWebhook: NewWebhookSender(),
+			metadata.NotifyChannelWeCom:   NewWeComSender(),
+			metadata.NotifyChannelSlack:   NewSlackSender(),
+		},
+		now: time.Now,
+	}
+	n.loadRules = n.loadRulesFromDB
+	n.bizOfHost = n.bizOfHostFromDB
+	n.saveLogs = n.saveLogsToDB
+	n.noPermissionFields = n.noPermissionFieldsByAuth
+	return n
+}
+
+// SetAuthManager setups the auth manager that checks the restricted fields the rule creators can view.
+func (n *Notifier) SetAuthManager(authManager *extensions.AuthManager) {
+	n.authManager = authManager
+}
+
+// SetSender replaces the sender of the channel type.
+func (n *Notifier) SetSender(channelType metadata.NotifyChannelType, sender Sender) {
+	n.senders[channelType] = sender
+}
+
+// Start starts reloading the rules and handling the events.
+func (n *Notifier) Start() {
+	n.LoadRules()
+	go n.reloadRules()
+	go n.run()
+}
+
+// LoadRules reloads the enabled rules from db, it's called when the rules are changed to take effect immediately.
+func (n *Notifier) LoadRules() {
+	rules, err := n.loadRules(n.ctx)
+	if err != nil {
+		blog.Errorf("load notify rules failed, err: %v", err)
+		return
+	}
+
+	n.rulesMu.Lock()
+	n.rules = rules
+	n.rulesMu.Unlock()
+
+	n.limiter.prune(rules, n.now())
+}
+
+func (n *Notifier) reloadRules() {
+	ticker := time.NewTicker(defaultRulesReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-n.ctx.Done():
+			return
+		case <-ticker.C:
+			n.LoadRules()
+		}
+	}
+}
+
+// Notify adds the event to the notify queue without blocking the event distribution.
+func (n *Notifier) Notify(event *metadata.EventInst) {
+	n.rulesMu.RLock()
+	ruleCount := len(n.rules)
+	n.rulesMu.RUnlock()
+
+	if ruleCount == 0 {
+		return
+	}
+
+	select {
+	case n.queue <- event:
+	default:
+		blog.Errorf("notify queue is full, drop event %d", event.ID)
+	}
+}
+
+func (n *Notifier) run() {
+	for {
+		select {
+		case <-n.ctx.Done():
+			return
+		case event := <-n.queue:
+			n.handle(event)
+		}
+	}
+}
+
+// handle matches the event with all enabled rules, and delivers the notifications of the matched rules.
+func (n *Notifier) handle(event *metadata.EventInst) {
+	n.rulesMu.RLock()
+	rules := n.rules
+	n.rulesMu.RUnlock()
+
+	data := eventData(event)
+	bizID := n.eventBizID(event, data)
+
+	logs := make([]metadata.NotifyDeliveryLog, 0)
+	for idx := range rules {
+		rule := &rules[idx]
+		if rule.Resource != event.ObjType {
+			continue
+		}
+
+		ruleEvent, ruleData, err := n.viewableEvent(rule, event, data)
+		if err != nil {
+			blog.Errorf("get restricted fields of rule %d for event %d failed, err: %v", rule.ID, event.ID, err)
+			continue
+		}
+		if !matchRule(rule, ruleEvent, ruleData, bizID) {
+			continue
+		}
+
+		allowed, firstDropped := n.limiter.allow(rule, n.now())
+		if !allowed {
+			if firstDropped {
+				log := n.newLog(rule, event, nil)
+				log.Status = metadata.NotifyDeliveryLimited
+				logs = append(logs, log)
+			}
+			continue
+		}
+
+		logs = append(logs, n.deliver(rule, ruleEvent, ruleData, bizID))
+	}
+
+	if len(logs) == 0 {
+		return
+	}
+	if err := n.saveLogs(n.ctx, logs); err != nil {
+		blog.Errorf("save notify delivery logs failed, event: %d, err: %v", event.ID, err)
+	}
+}
+
+// deliver renders and sends the notification of the rule, returns the delivery log.
+func (n *Notifier) deliver(rule *metadata.NotifyRule, event *metadata.EventInst, data map[string]interface{},
+	bizID int64) metadata.NotifyDeliveryLog {
+
+	msg, err := render(rule, event, data, bizID)
+	log := n.newLog(rule, event, msg)
+	if err == nil {
+		err = n.send(rule.Channel, msg)
+	}
+
+	if err != nil {
+		blog.Errorf("deliver notification of rule %d for event %d failed, err: %v", rule.ID, event.ID, err)
+		log.Status = metadata.NotifyDeliveryFailed
+		log.Message = metadata.RedactURLs(err.Error())
+		return log
+	}
+	log.Status = metadata.NotifyDeliverySuccess
+	return log
+}
+
+func (n *Notifier) send(channel metadata.NotifyChannel, msg *Message) error {
+	sender, exist := n.senders[channel.Type]
+	if !exist {
+		return errUnsupportedChannel(channel.Type)
+	}
+
+	ctx, cancel := context.WithTimeout(n.ctx, defaultSendTimeout)
+	defer cancel()
+	return sender.Send(ctx, channel, msg)
+}
+
+func (n *Notifier) newLog(rule *metadata.NotifyRule, event *metadata.EventInst,
+	msg *Message) metadata.NotifyDeliveryLog {
+
+	log := metadata.NotifyDeliveryLog{
+		RuleID:     rule.ID,
+		RuleName:   rule.Name,
+		EventID:    event.ID,
+		Resource:   event.ObjType,
+		Action:     event.Action,
+		Channel:    rule.Channel.Type,
+		Receivers:  make([]string, 0),
+		CreateTime: n.now(),
+		OwnerID:    rule.OwnerID,
+	}
+	if msg != nil {
+		log.Receivers = msg.Receivers
+		log.Title = msg.Title
+		log.Content = msg.Content
+	}
+	return log
+}
+
+// Test matches and renders the rule with the event, and sends the notification if not dry run,
+// the rate limit is not applied and no delivery log is recorded.
+func (n *Notifier) Test(rule *metadata.NotifyRule, event *metadata.EventInst,
+	dryRun bool) *metadata.TestNotifyRuleResult {
+
+	data := eventData(event)
+	bizID := n.eventBizID(event, data)
+
+	result := &metadata.TestNotifyRuleResult{Receivers: make([]string, 0)}
+	if rule.Resource != event.ObjType {
+		return result
+	}
+
+	event, data, err := n.viewableEvent(rule, event, data)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Matched = matchRule(rule, event, data, bizID)
+	if !result.Matched {
+		return result
+	}
+
+	msg, err := render(rule, event, data, bizID)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Title = msg.Title
+	result.Content = msg.Content
+	result.Receivers = msg.Receivers
+
+	if dryRun {
+		return result
+	}
+	if err := n.send(rule.Channel, msg); err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// viewableEvent returns the event and its data without the restricted fields that the rule creator can not view,
+// so that the fields are neither matched, rendered nor sent to the channels.
+func (n *Notifier) viewableEvent(rule *metadata.NotifyRule, event *metadata.EventInst,
+	data map[string]interface{}) (*metadata.EventInst, map[string]interface{}, error) {
+
+	key := restrictedFieldsKey(rule, event.ObjType)
+	fields, exist := n.restricted.get(key, n.now())
+	if !exist {
+		var err error
+		fields, err = n.noPermissionFields(n.ctx, rule, event.ObjType)
+		if err != nil {
+			return nil, nil, err
+		}
+		n.restricted.set(key, fields, n.now())
+	}
+
+	event, data = stripRestrictedFields(event, data, fields)
+	return event, data, nil
+}
+
+func (n *Notifier) noPermissionFieldsByAuth(ctx context.Context, rule *metadata.NotifyRule, objID string) (
+	[]string, error) {
+
+	if n.authManager == nil || !n.authManager.Enabled() {
+		return nil, nil
+	}
+
+	header := util.BuildHeader(rule.Creator, rule.OwnerID)
+	noPermission, err := n.authManager.GetNoPermissionRestrictedFields(ctx, header, meta.ViewRestrictedField, objID)
+	if err != nil {
+		return nil, err
+	}
+	return noPermission[objID], nil
+}
+
+// eventBizID returns the business of the event resource, host is related to business by module host relation.
+func (n *Notifier) eventBizID(event *metadata.EventInst, data map[string]interface{}) int64 {
+	if event.ObjType != common.BKInnerObjIDHost {
+		bizID, _ := util.GetInt64ByInterface(data[common.BKAppIDField])
+		return bizID
+	}
+
+	hostID, err := util.GetInt64ByInterface(data[common.BKHostIDField])
+	if err != nil || hostID == 0 {
+		return 0
+	}
+	bizID, err := n.bizOfHost(n.ctx, hostID)
+	if err != nil {
+		blog.Errorf("get business of host %d failed, err: %v", hostID, err)
+		return 0
+	}
+	return bizID
+}
+
+func (n *Notifier) loadRulesFromDB(ctx context.Context) ([]metadata.NotifyRule, error) {
+	rules := make([]metadata.NotifyRule, 0)
+	filter := map[string]interface{}{"enabled": true}
+	if err := n.db.Table(common.BKTableNameNotifyRule).Find(filter).All(ctx, &rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (n *Notifier) bizOfHostFromDB(ctx context.Context, hostID int64) (int64, error) {
+	relations := make([]metadata.ModuleHost, 0)
+	filter := map[string]interface{}{common.BKHostIDField: hostID}
+	err := n.db.Table(common.BKTableNameModuleHostConfig).Find(filter).Fields(common.BKAppIDField).
+		Limit(1).All(ctx, &relations)
+	if err != nil {
+		return 0, err
+	}
+	if len(relations) == 0 {
+		return 0, nil
+	}
+	return relations[0].AppID, nil
+}
+
+func (n *Notifier) saveLogsToDB(ctx context.Context, logs []metadata.NotifyDeliveryLog) error {
+	for idx := range logs {
+		id, err := n.db.NextSequence(ctx, common.BKTableNameNotifyDeliveryLog)
+		if err != nil {
+			return err
+		}
+		logs[idx].ID = int64(id)
+	}
+	return n.db.Table(common.BKTableNameNotifyDeliveryLog).Insert(ctx, logs)
+}
@@ -0,0 +1,321 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+)
+
+func newTestEvent() *metadata.EventInst {
+	return &metadata.EventInst{
+		ID:           1,
+		Action:       metadata.EventActionUpdate,
+		ObjType:      "host",
+		ActionTime:   metadata.Time{Time: time.Date(2020, 12, 1, 10, 0, 0, 0, time.Local)},
+		UpdateFields: []string{"bk_host_name"},
+		Data: []metadata.EventData{{CurData: map[string]interface{}{
+			"bk_host_id":          float64(3),
+			"bk_host_innerip":     "127.0.0.1",
+			"bk_host_name":        "db-01",
+			"operator":            "admin,user1",
+			"bk_supplier_account": "0",
+		}}},
+	}
+}
+
+func newTestNotifier(t *testing.T, rules []metadata.NotifyRule) (*Notifier, *[]metadata.NotifyDeliveryLog) {
+	logs := make([]metadata.NotifyDeliveryLog, 0)
+	n := NewNotifier(context.Background(), nil, SMTPConfig{})
+	n.loadRules = func(ctx context.Context) ([]metadata.NotifyRule, error) {
+		return rules, nil
+	}
+	n.bizOfHost = func(ctx context.Context, hostID int64) (int64, error) {
+		if hostID != 3 {
+			t.Errorf("unexpected host id %d", hostID)
+		}
+		return 2, nil
+	}
+	n.saveLogs = func(ctx context.Context, l []metadata.NotifyDeliveryLog) error {
+		logs = append(logs, l...)
+		return nil
+	}
+	n.LoadRules()
+	return n, &logs
+}
+
+func TestMatchRule(t *testing.T) {
+	event := newTestEvent()
+	data := eventData(event)
+
+	tests := []struct {
+		name  string
+		rule  metadata.NotifyRule
+		bizID int64
+		match bool
+	}{
+		{"resource", metadata.NotifyRule{Resource: "host"}, 0, true},
+		{"other resource", metadata.NotifyRule{Resource: "set"}, 0, false},
+		{"action", metadata.NotifyRule{Resource: "host", Actions: []string{"create"}}, 0, false},
+		{"biz", metadata.NotifyRule{Resource: "host", BizID: 2}, 2, true},
+		{"other biz", metadata.NotifyRule{Resource: "host", BizID: 2}, 5, false},
+		{"owner", metadata.NotifyRule{Resource: "host", OwnerID: "1"}, 0, false},
+		{"equal", metadata.NotifyRule{Resource: "host", Predicates: []metadata.NotifyPredicate{
+			{Field: "bk_host_id", Operator: metadata.NotifyOperatorEqual, Value: 3}}}, 0, true},
+		{"not equal", metadata.NotifyRule{Resource: "host", Predicates: []metadata.NotifyPredicate{
+			{Field: "bk_host_name", Operator: metadata.NotifyOperatorNotEqual, Value: "db-01"}}}, 0, false},
+		{"in", metadata.NotifyRule{Resource: "host", Predicates: []metadata.NotifyPredicate{
+			{Field: "bk_host_innerip", Operator: metadata.NotifyOperatorIn,
+				Value: []interface{}{"127.0.0.2", "127.0.0.1"}}}}, 0, true},
+		{"not in", metadata.NotifyRule{Resource: "host", Predicates: []metadata.NotifyPredicate{
+			{Field: "bk_host_innerip", Operator: metadata.NotifyOperatorNotIn,
+				Value: []interface{}{"127.0.0.1"}}}}, 0, false},
+		{"contains", metadata.NotifyRule{Resource: "host", Predicates: []metadata.NotifyPredicate{
+			{Field: "bk_host_name", Operator: metadata.NotifyOperatorContains, Value: "db"}}}, 0, true},
+		{"changed", metadata.NotifyRule{Resource: "host", Predicates: []metadata.NotifyPredicate{
+			{Field: "bk_host_name", Operator: metadata.NotifyOperatorChanged}}}, 0, true},
+		{"not changed", metadata.NotifyRule{Resource: "host", Predicates: []metadata.NotifyPredicate{
+			{Field: "operator", Operator: metadata.NotifyOperatorChanged}}}, 0, false},
+	}
+
+	for _, test := range tests {
+		if match := matchRule(&test.rule, event, data, test.bizID); match != test.match {
+			t.Errorf("test %s, expect match %v, got %v", test.name, test.match, match)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	event := newTestEvent()
+	rule := &metadata.NotifyRule{
+		ID:   1,
+		Name: "host changed",
+		Template: metadata.NotifyTemplate{
+			Title:   `{{.Data.bk_host_innerip}} {{.Action}}`,
+			Content: `biz {{.BizID}}: {{join .UpdateFields ","}}`,
+		},
+		Channel: metadata.NotifyChannel{
+			Receivers:      []string{"admin"},
+			ReceiverFields: []string{"operator"},
+		},
+	}
+
+	msg, err := render(rule, event, eventData(event), 2)
+	if err != nil {
+		t.Fatalf("render failed, err: %v", err)
+	}
+	if msg.Title != "127.0.0.1 update" || msg.Content != "biz 2: bk_host_name" {
+		t.Errorf("unexpected message, title: %s, content: %s", msg.Title, msg.Content)
+	}
+	if strings.Join(msg.Receivers, ",") != "admin,user1" {
+		t.Errorf("unexpected receivers %v", msg.Receivers)
+	}
+
+	if err := ValidateTemplate(metadata.NotifyTemplate{Title: "{{.Data"}); err == nil {
+		t.Errorf("invalid template should be rejected")
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	rule := &metadata.NotifyRule{ID: 1, RateLimit: metadata.NotifyRateLimit{Count: 2, IntervalSeconds: 60}}
+	limiter := newRateLimiter()
+	now := time.Now()
+
+	expects := []struct{ allowed, firstDropped bool }{{true, false}, {true, false}, {false, true}, {false, false}}
+	for idx, expect := range expects {
+		allowed, firstDropped := limiter.allow(rule, now)
+		if allowed != expect.allowed || firstDropped != expect.firstDropped {
+			t.Errorf("call %d, expect %v, got %v %v", idx, expect, allowed, firstDropped)
+		}
+	}
+
+	if allowed, _ := limiter.allow(rule, now.Add(time.Minute)); !allowed {
+		t.Errorf("should be allowed in the next window")
+	}
+}
+
+func TestNotifyWebhook(t *testing.T) {
+	bodies := make(chan map[string]interface{}, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := make(map[string]interface{})
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		bodies <- body
+		if r.URL.Path == "/wecom" {
+			w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+		}
+	}))
+	defer server.Close()
+
+	rules := []metadata.NotifyRule{
+		{ID: 1, Name: "webhook", Resource: "host", BizID: 2, RateLimit: metadata.NotifyRateLimit{Count: 1},
+			Channel: metadata.NotifyChannel{Type: metadata.NotifyChannelWebhook, URL: server.URL + "/webhook"}},
+		{ID: 2, Name: "wecom", Resource: "host",
+			Channel: metadata.NotifyChannel{Type: metadata.NotifyChannelWeCom, URL: server.URL + "/wecom",
+				ReceiverFields: []string{"operator"}}},
+		{ID: 3, Name: "unmatched", Resource: "set",
+			Channel: metadata.NotifyChannel{Type: metadata.NotifyChannelSlack, URL: server.URL + "/slack"}},
+	}
+	n, logs := newTestNotifier(t, rules)
+
+	n.handle(newTestEvent())
+	n.handle(newTestEvent())
+	n.handle(newTestEvent())
+
+	statuses := make(map[int64][]metadata.NotifyDeliveryStatus)
+	for _, log := range *logs {
+		statuses[log.RuleID] = append(statuses[log.RuleID], log.Status)
+	}
+	if len(statuses[1]) != 2 || statuses[1][0] != metadata.NotifyDeliverySuccess ||
+		statuses[1][1] != metadata.NotifyDeliveryLimited {
+		t.Errorf("unexpected webhook delivery statuses %v", statuses[1])
+	}
+	if len(statuses[2]) != 3 || len(statuses[3]) != 0 {
+		t.Errorf("unexpected delivery statuses %v", statuses)
+	}
+	if len(bodies) != 4 {
+		t.Fatalf("expect 4 requests, got %d", len(bodies))
+	}
+
+	webhook := <-bodies
+	if webhook["title"] != "[CMDB] webhook" || webhook["event"] == nil {
+		t.Errorf("unexpected webhook body %v", webhook)
+	}
+	wecom := <-bodies
+	text, _ := wecom["text"].(map[string]interface{})
+	if wecom["msgtype"] != "text" || len(text["mentioned_list"].([]interface{})) != 2 {
+		t.Errorf("unexpected wecom body %v", wecom)
+	}
+}
+
+func TestNotifyEmail(t *testing.T) {
+	var sentTo []string
+	var sentMsg string
+	sender := &emailSender{
+		config: SMTPConfig{Host: "127.0.0.1", From: "cmdb@example.com"},
+		sendMail: func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+			if addr != "127.0.0.1:25" || from != "cmdb@example.com" || a != nil {
+				t.Errorf("unexpected smtp params, addr: %s, from: %s", addr, from)
+			}
+			sentTo, sentMsg = to, string(msg)
+			return nil
+		},
+	}
+
+	rule := metadata.NotifyRule{ID: 1, Name: "email", Resource: "host",
+		Channel: metadata.NotifyChannel{Type: metadata.NotifyChannelEmail, Receivers: []string{"ops@example.com"}}}
+	n, _ := newTestNotifier(t, nil)
+	n.SetSender(metadata.NotifyChannelEmail, sender)
+
+	result := n.Test(&rule, newTestEvent(), true)
+	if !result.Matched || len(result.Error) != 0 || sentTo != nil {
+		t.Fatalf("unexpected dry run result %+v", result)
+	}
+
+	result = n.Test(&rule, newTestEvent(), false)
+	if !result.Matched || len(result.Error) != 0 {
+		t.Fatalf("unexpected test result %+v", result)
+	}
+	if strings.Join(sentTo, ",") != "ops@example.com" || !strings.Contains(sentMsg, "Subject: [CMDB] email") ||
+		!strings.Contains(sentMsg, "update") {
+		t.Errorf("unexpected mail, to: %v, msg: %s", sentTo, sentMsg)
+	}
+}
+
+func TestRestrictedFields(t *testing.T) {
+	bodies := make(chan map[string]interface{}, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := make(map[string]interface{})
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		bodies <- body
+	}))
+	defer server.Close()
+
+	rules := []metadata.NotifyRule{
+		{ID: 1, Name: "webhook", Resource: "host", Creator: "user1",
+			Template: metadata.NotifyTemplate{Content: `{{.Data.bk_host_innerip}}|{{json .Data}}`},
+			Channel:  metadata.NotifyChannel{Type: metadata.NotifyChannelWebhook, URL: server.URL}},
+		{ID: 2, Name: "predicate", Resource: "host", Creator: "user1",
+			Predicates: []metadata.NotifyPredicate{
+				{Field: "bk_host_innerip", Operator: metadata.NotifyOperatorEqual, Value: "127.0.0.1"}},
+			Channel: metadata.NotifyChannel{Type: metadata.NotifyChannelWebhook, URL: server.URL}},
+	}
+	n, logs := newTestNotifier(t, rules)
+	calls := 0
+	n.noPermissionFields = func(ctx context.Context, rule *metadata.NotifyRule, objID string) ([]string, error) {
+		calls++
+		if rule.Creator != "user1" || objID != "host" {
+			t.Errorf("unexpected restricted fields request, creator: %s, objID: %s", rule.Creator, objID)
+		}
+		return []string{"bk_host_innerip"}, nil
+	}
+
+	event := newTestEvent()
+	n.handle(event)
+	n.handle(event)
+
+	if calls != 1 {
+		t.Errorf("restricted fields should be cached, got %d calls", calls)
+	}
+	if len(*logs) != 2 || len(bodies) != 2 {
+		t.Fatalf("only the rule without restricted predicate should be delivered, logs: %+v", *logs)
+	}
+	for _, log := range *logs {
+		if log.RuleID != 1 || strings.Contains(log.Content, "127.0.0.1") {
+			t.Errorf("unexpected delivery log %+v", log)
+		}
+	}
+	body := <-bodies
+	if js, _ := json.Marshal(body); strings.Contains(string(js), "127.0.0.1") {
+		t.Errorf("restricted field is sent to webhook, body: %s", js)
+	}
+	if eventData(event)["bk_host_innerip"] != "127.0.0.1" {
+		t.Errorf("the original event should not be changed")
+	}
+}
+
+func TestReferencedFields(t *testing.T) {
+	rule := &metadata.NotifyRule{
+		Predicates: []metadata.NotifyPredicate{{Field: "bk_host_name", Operator: metadata.NotifyOperatorChanged}},
+		Template: metadata.NotifyTemplate{
+			Title:   `{{.Data.bk_host_innerip}} {{index .Data "bk_comment"}}`,
+			Content: `{{with .Data}}{{.bk_asset_id}}{{end}}{{range $k, $v := .Event.Data}}{{$v.CurData.bk_sn}}{{end}}`,
+		},
+		Channel: metadata.NotifyChannel{ReceiverFields: []string{"operator"}},
+	}
+
+	fields, err := ReferencedFields(rule)
+	if err != nil {
+		t.Fatalf("get referenced fields failed, err: %v", err)
+	}
+	for _, field := range []string{"bk_host_name", "operator", "bk_host_innerip", "bk_comment", "bk_asset_id",
+		"bk_sn"} {
+		if !util.InStrArr(fields, field) {
+			t.Errorf("field %s is not referenced, fields: %v", field, fields)
+		}
+	}
+}
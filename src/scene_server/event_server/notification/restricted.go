@@ -0,0 +1,193 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package notification
+
+import (
+	"sync"
+	"text/template"
+	"text/template/parse"
+	"time"
+
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+)
+
+// restrictedFieldsCacheTTL is the time that the restricted fields the rule creators can not view are cached,
+// so that the permission is not checked for every event.
+const restrictedFieldsCacheTTL = time.Minute
+
+// restrictedFieldsCache caches the restricted fields that the users can not view by the user and object.
+type restrictedFieldsCache struct {
+	lock  sync.Mutex
+	items map[string]restrictedFieldsItem
+}
+
+type restrictedFieldsItem struct {
+	fields []string
+	expire time.Time
+}
+
+func newRestrictedFieldsCache() *restrictedFieldsCache {
+	return &restrictedFieldsCache{items: make(map[string]restrictedFieldsItem)}
+}
+
+func restrictedFieldsKey(rule *metadata.NotifyRule, objID string) string {
+	return rule.OwnerID + ":" + rule.Creator + ":" + objID
+}
+
+func (c *restrictedFieldsCache) get(key string, now time.Time) ([]string, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	item, exist := c.items[key]
+	if !exist || now.After(item.expire) {
+		return nil, false
+	}
+	return item.fields, true
+}
+
+// set caches the fields of the key, and removes the expired items.
+func (c *restrictedFieldsCache) set(key string, fields []string, now time.Time) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for k, item := range c.items {
+		if now.After(item.expire) {
+			delete(c.items, k)
+		}
+	}
+	c.items[key] = restrictedFieldsItem{fields: fields, expire: now.Add(restrictedFieldsCacheTTL)}
+}
+
+// stripRestrictedFields returns the copies of the event and its data without the restricted fields,
+// the original event is shared by all rules so it's not changed.
+func stripRestrictedFields(event *metadata.EventInst, data map[string]interface{}, fields []string) (
+	*metadata.EventInst, map[string]interface{}) {
+
+	if len(fields) == 0 {
+		return event, data
+	}
+
+	stripped := *event
+	stripped.Data = make([]metadata.EventData, len(event.Data))
+	for idx, item := range event.Data {
+		if item.CurData != nil {
+			stripped.Data[idx].CurData = stripMap(toMap(item.CurData), fields)
+		}
+		if item.PreData != nil {
+			stripped.Data[idx].PreData = stripMap(toMap(item.PreData), fields)
+		}
+	}
+	stripped.UpdateFields = stripFields(event.UpdateFields, fields)
+	stripped.DeletedFields = stripFields(event.DeletedFields, fields)
+
+	return &stripped, stripMap(data, fields)
+}
+
+func stripMap(data map[string]interface{}, fields []string) map[string]interface{} {
+	result := make(map[string]interface{}, len(data))
+	for key, value := range data {
+		if !util.InStrArr(fields, key) {
+			result[key] = value
+		}
+	}
+	return result
+}
+
+func stripFields(values []string, fields []string) []string {
+	if values == nil {
+		return nil
+	}
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if !util.InStrArr(fields, value) {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// ReferencedFields returns the fields that the rule may read from the event data, including the predicate
+// fields, the receiver fields and the names used in the templates. the templates can access the data in many
+// ways, so all field names and strings in them are returned.
+func ReferencedFields(rule *metadata.NotifyRule) ([]string, error) {
+	fields := make([]string, 0)
+	for _, predicate := range rule.Predicates {
+		fields = append(fields, predicate.Field)
+	}
+	fields = append(fields, rule.Channel.ReceiverFields...)
+
+	for name, text := range map[string]string{"title": rule.Template.Title, "content": rule.Template.Content} {
+		tpl, err := template.New(name).Funcs(templateFuncs).Parse(text)
+		if err != nil {
+			return nil, err
+		}
+		if tpl.Tree != nil {
+			fields = append(fields, templateNames(tpl.Tree.Root)...)
+		}
+	}
+	return util.StrArrayUnique(fields), nil
+}
+
+// templateNames returns the field names and strings used in the template node and its children.
+func templateNames(node parse.Node) []string {
+	names := make([]string, 0)
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return names
+		}
+		for _, child := range n.Nodes {
+			names = append(names, templateNames(child)...)
+		}
+	case *parse.ActionNode:
+		names = append(names, templateNames(n.Pipe)...)
+	case *parse.IfNode:
+		names = append(names, branchNames(&n.BranchNode)...)
+	case *parse.RangeNode:
+		names = append(names, branchNames(&n.BranchNode)...)
+	case *parse.WithNode:
+		names = append(names, branchNames(&n.BranchNode)...)
+	case *parse.TemplateNode:
+		names = append(names, templateNames(n.Pipe)...)
+	case *parse.PipeNode:
+		if n == nil {
+			return names
+		}
+		for _, cmd := range n.Cmds {
+			names = append(names, templateNames(cmd)...)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			names = append(names, templateNames(arg)...)
+		}
+	case *parse.FieldNode:
+		names = append(names, n.Ident...)
+	case *parse.ChainNode:
+		names = append(names, templateNames(n.Node)...)
+		names = append(names, n.Field...)
+	case *parse.VariableNode:
+		if len(n.Ident) > 1 {
+			names = append(names, n.Ident[1:]...)
+		}
+	case *parse.StringNode:
+		names = append(names, n.Text)
+	}
+	return names
+}
+
+func branchNames(n *parse.BranchNode) []string {
+	names := templateNames(n.Pipe)
+	names = append(names, templateNames(n.List)...)
+	return append(names, templateNames(n.ElseList)...)
+}
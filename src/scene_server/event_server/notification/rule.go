@@ -0,0 +1,242 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package notification
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"configcenter/src/common"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+)
+
+const (
+	defaultTitleTemplate   = `[CMDB] {{.Rule.Name}}`
+	defaultContentTemplate = `{{.ObjType}} {{.Action}} at {{.Time}}` +
+		`{{if .UpdateFields}}, updated fields: {{join .UpdateFields ", "}}{{end}}
+{{json .Data}}`
+)
+
+var templateFuncs = template.FuncMap{
+	"json": func(v interface{}) string {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(data)
+	},
+	"join": strings.Join,
+}
+
+// templateData is the data used to render the notify templates
+type templateData struct {
+	Rule         *metadata.NotifyRule
+	Event        *metadata.EventInst
+	ObjType      string
+	Action       string
+	Time         string
+	BizID        int64
+	Data         map[string]interface{}
+	UpdateFields []string
+}
+
+// ValidateTemplate validate the title and content template of notify rule
+func ValidateTemplate(tpl metadata.NotifyTemplate) error {
+	if _, err := template.New("title").Funcs(templateFuncs).Parse(tpl.Title); err != nil {
+		return fmt.Errorf("invalid title template, %v", err)
+	}
+	if _, err := template.New("content").Funcs(templateFuncs).Parse(tpl.Content); err != nil {
+		return fmt.Errorf("invalid content template, %v", err)
+	}
+	return nil
+}
+
+func renderTemplate(name, text, defaultText string, data *templateData) (string, error) {
+	if len(text) == 0 {
+		text = defaultText
+	}
+	tpl, err := template.New(name).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return "", err
+	}
+	buf := new(bytes.Buffer)
+	if err := tpl.Execute(buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// render renders the notify message of the rule with the event
+func render(rule *metadata.NotifyRule, event *metadata.EventInst, data map[string]interface{}, bizID int64) (
+	*Message, error) {
+
+	tplData := &templateData{
+		Rule:         rule,
+		Event:        event,
+		ObjType:      event.ObjType,
+		Action:       event.Action,
+		Time:         event.ActionTime.Format(common.TimeTransferModel),
+		BizID:        bizID,
+		Data:         data,
+		UpdateFields: event.UpdateFields,
+	}
+	title, err := renderTemplate("title", rule.Template.Title, defaultTitleTemplate, tplData)
+	if err != nil {
+		return nil, fmt.Errorf("render title failed, %v", err)
+	}
+	content, err := renderTemplate("content", rule.Template.Content, defaultContentTemplate, tplData)
+	if err != nil {
+		return nil, fmt.Errorf("render content failed, %v", err)
+	}
+
+	return &Message{
+		RuleID:    rule.ID,
+		RuleName:  rule.Name,
+		Title:     strings.TrimSpace(title),
+		Content:   content,
+		Receivers: receivers(rule.Channel, data),
+		Event:     event,
+	}, nil
+}
+
+// receivers returns the configured receivers and the receivers from the event data without duplicates
+func receivers(channel metadata.NotifyChannel, data map[string]interface{}) []string {
+	result := make([]string, 0)
+	exists := make(map[string]bool)
+	add := func(receiver string) {
+		receiver = strings.TrimSpace(receiver)
+		if len(receiver) == 0 || exists[receiver] {
+			return
+		}
+		exists[receiver] = true
+		result = append(result, receiver)
+	}
+
+	for _, receiver := range channel.Receivers {
+		add(receiver)
+	}
+	for _, field := range channel.ReceiverFields {
+		value, exist := data[field]
+		if !exist || value == nil {
+			continue
+		}
+		for _, receiver := range strings.Split(util.GetStrByInterface(value), ",") {
+			add(receiver)
+		}
+	}
+	return result
+}
+
+// eventData returns the resource data of the event, the data before deleted is used for the delete event
+func eventData(event *metadata.EventInst) map[string]interface{} {
+	if len(event.Data) == 0 {
+		return make(map[string]interface{})
+	}
+
+	raw := event.Data[0].CurData
+	if raw == nil {
+		raw = event.Data[0].PreData
+	}
+	return toMap(raw)
+}
+
+// toMap converts the event resource data to map
+func toMap(raw interface{}) map[string]interface{} {
+	if data, ok := raw.(map[string]interface{}); ok {
+		return data
+	}
+
+	// the data is json string before the event is decoded from the event queue
+	data := make(map[string]interface{})
+	bytes, err := json.Marshal(raw)
+	if err != nil {
+		return data
+	}
+	if str := ""; json.Unmarshal(bytes, &str) == nil {
+		bytes = []byte(str)
+	}
+	_ = json.Unmarshal(bytes, &data)
+	return data
+}
+
+// matchRule checks whether the event matches the resource, action, business scope and predicates of the rule,
+// bizID is the business of the event resource, 0 means unknown.
+func matchRule(rule *metadata.NotifyRule, event *metadata.EventInst, data map[string]interface{}, bizID int64) bool {
+	if rule.Resource != event.ObjType {
+		return false
+	}
+	if len(rule.Actions) > 0 && !util.InStrArr(rule.Actions, event.Action) {
+		return false
+	}
+	if owner, exist := data[common.BKOwnerIDField]; exist && len(rule.OwnerID) > 0 &&
+		util.GetStrByInterface(owner) != rule.OwnerID {
+		return false
+	}
+	if rule.BizID != 0 && rule.BizID != bizID {
+		return false
+	}
+
+	for _, predicate := range rule.Predicates {
+		if !matchPredicate(predicate, event, data) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchPredicate(predicate metadata.NotifyPredicate, event *metadata.EventInst, data map[string]interface{}) bool {
+	if predicate.Operator == metadata.NotifyOperatorChanged {
+		return event.Action == metadata.EventActionUpdate && util.InStrArr(event.UpdateFields, predicate.Field)
+	}
+
+	value, exist := data[predicate.Field]
+	switch predicate.Operator {
+	case metadata.NotifyOperatorEqual:
+		return exist && valueEqual(value, predicate.Value)
+	case metadata.NotifyOperatorNotEqual:
+		return !exist || !valueEqual(value, predicate.Value)
+	case metadata.NotifyOperatorIn, metadata.NotifyOperatorNotIn:
+		in := false
+		values, _ := predicate.Value.([]interface{})
+		for _, item := range values {
+			if exist && valueEqual(value, item) {
+				in = true
+				break
+			}
+		}
+		return in == (predicate.Operator == metadata.NotifyOperatorIn)
+	case metadata.NotifyOperatorContains:
+		return exist && strings.Contains(util.GetStrByInterface(value), util.GetStrByInterface(predicate.Value))
+	default:
+		return false
+	}
+}
+
+// valueEqual compare the numbers by value since the numbers decoded from json are float64,
+// and compare other values by their string format.
+func valueEqual(a, b interface{}) bool {
+	_, aIsStr := a.(string)
+	_, bIsStr := b.(string)
+	if !aIsStr && !bIsStr {
+		af, aErr := util.GetFloat64ByInterface(a)
+		bf, bErr := util.GetFloat64ByInterface(b)
+		if aErr == nil && bErr == nil {
+			return af == bf
+		}
+	}
+	return util.GetStrByInterface(a) == util.GetStrByInterface(b)
+}
@@ -0,0 +1,481 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"configcenter/src/ac"
+	"configcenter/src/ac/meta"
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/scene_server/event_server/notification"
+)
+
+// validateNotifyRule validates the notify rule and its templates.
+func (s *Service) validateNotifyRule(ctx *rest.Contexts, rule *metadata.NotifyRule) bool {
+	if rawErr := rule.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return false
+	}
+	if err := notification.ValidateTemplate(rule.Template); err != nil {
+		blog.Errorf("invalid notify rule template, err: %v, rid: %s", err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, "template"))
+		return false
+	}
+	if err := s.checkNotifyRuleRestrictedFields(ctx, rule); err != nil {
+		ctx.RespAutoError(err)
+		return false
+	}
+	return true
+}
+
+// checkNotifyRuleRestrictedFields checks that the predicates, receiver fields and templates of the rule do not
+// reference the restricted fields that the user has no permission to view. the fields are also removed from
+// the events before they are notified, this makes the user know that the rule can not use them.
+func (s *Service) checkNotifyRuleRestrictedFields(ctx *rest.Contexts, rule *metadata.NotifyRule) error {
+	if s.authManager == nil || !s.authManager.Enabled() {
+		return nil
+	}
+
+	fields, err := notification.ReferencedFields(rule)
+	if err != nil {
+		blog.Errorf("get notify rule referenced fields failed, err: %v, rid: %s", err, ctx.Kit.Rid)
+		return ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, "template")
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+
+	noPermission, err := s.authManager.GetNoPermissionRestrictedFields(ctx.Kit.Ctx, ctx.Kit.Header,
+		meta.ViewRestrictedField, rule.Resource)
+	if err != nil {
+		blog.Errorf("get no permission restricted fields failed, objID: %s, err: %v, rid: %s", rule.Resource, err,
+			ctx.Kit.Rid)
+		return ctx.Kit.CCError.CCError(common.CCErrCommAuthorizeFailed)
+	}
+
+	denied := make([]string, 0)
+	for _, field := range noPermission[rule.Resource] {
+		if util.InStrArr(fields, field) {
+			denied = append(denied, field)
+		}
+	}
+	if len(denied) > 0 {
+		blog.Errorf("notify rule references restricted fields %v of object %s, rid: %s", denied, rule.Resource,
+			ctx.Kit.Rid)
+		return ctx.Kit.CCError.CCErrorf(common.CCErrCommRestrictedFieldNoPermission, strings.Join(denied, ","))
+	}
+	return nil
+}
+
+// reloadNotifyRules makes the changed rules take effect immediately.
+func (s *Service) reloadNotifyRules() {
+	if s.notifier != nil {
+		s.notifier.LoadRules()
+	}
+}
+
+// the notify rules are owned by their creators and scoped to their businesses. a user can read the rules
+// created by the user or of the businesses the user can view, and write the rules created by the user or
+// of the businesses the user can edit. the rules of all businesses can only be written by their creators.
+
+// authorizeNotifyRule checks if the user can read or write the notify rule.
+func (s *Service) authorizeNotifyRule(ctx *rest.Contexts, rule *metadata.NotifyRule, action meta.Action) error {
+	if s.authManager == nil || !s.authManager.Enabled() || rule.Creator == ctx.Kit.User {
+		return nil
+	}
+	if rule.BizID == 0 {
+		return ctx.Kit.CCError.CCError(common.CCErrCommAuthNotHavePermission)
+	}
+	return s.authorizeNotifyRuleBiz(ctx, rule.BizID, action)
+}
+
+// authorizeNotifyRuleBiz checks if the user has the permission of the business that the rule is scoped to,
+// the rules of all businesses need no business permission.
+func (s *Service) authorizeNotifyRuleBiz(ctx *rest.Contexts, bizID int64, action meta.Action) error {
+	if s.authManager == nil || !s.authManager.Enabled() || bizID == 0 {
+		return nil
+	}
+
+	err := s.authManager.AuthorizeByBusinessID(ctx.Kit.Ctx, ctx.Kit.Header, action, bizID)
+	if err == nil {
+		return nil
+	}
+	if err == ac.NoAuthorizeError {
+		return ctx.Kit.CCError.CCError(common.CCErrCommAuthNotHavePermission)
+	}
+	blog.Errorf("authorize business %d action %s of notify rule failed, err: %v, rid: %s", bizID, action, err,
+		ctx.Kit.Rid)
+	return ctx.Kit.CCError.CCError(common.CCErrCommCheckAuthorizeFailed)
+}
+
+// readableNotifyRuleFilter returns the filter of the notify rules that the user can read,
+// returns nil if authorization is not enabled.
+func (s *Service) readableNotifyRuleFilter(ctx *rest.Contexts) (map[string]interface{}, error) {
+	if s.authManager == nil || !s.authManager.Enabled() {
+		return nil, nil
+	}
+
+	authInput := meta.ListAuthorizedResourcesParam{
+		UserName:     ctx.Kit.User,
+		ResourceType: meta.Business,
+		Action:       meta.ViewBusinessResource,
+	}
+	authorizedResources, err := s.authorizer.ListAuthorizedResources(ctx.Kit.Ctx, ctx.Kit.Header, authInput)
+	if err != nil {
+		blog.Errorf("list authorized businesses of notify rules failed, err: %v, rid: %s", err, ctx.Kit.Rid)
+		return nil, ctx.Kit.CCError.CCError(common.CCErrCommCheckAuthorizeFailed)
+	}
+
+	bizIDs := make([]int64, 0)
+	for _, resourceID := range authorizedResources {
+		bizID, err := strconv.ParseInt(resourceID, 10, 64)
+		if err != nil {
+			blog.Errorf("parse business id %s failed, err: %v, rid: %s", resourceID, err, ctx.Kit.Rid)
+			return nil, ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsNeedInt, common.BKAppIDField)
+		}
+		if bizID != 0 {
+			bizIDs = append(bizIDs, bizID)
+		}
+	}
+
+	return map[string]interface{}{
+		common.BKDBOR: []map[string]interface{}{
+			{common.CreatorField: ctx.Kit.User},
+			{common.BKAppIDField: map[string]interface{}{common.BKDBIN: bizIDs}},
+		},
+	}, nil
+}
+
+// getNotifyRule gets the notify rule by id, returns nil if the rule does not exist.
+func (s *Service) getNotifyRule(ctx *rest.Contexts, id int64) (*metadata.NotifyRule, error) {
+	filter := util.SetQueryOwner(map[string]interface{}{"id": id}, ctx.Kit.SupplierAccount)
+	rule := new(metadata.NotifyRule)
+	if err := s.db.Table(common.BKTableNameNotifyRule).Find(filter).One(ctx.Kit.Ctx, rule); err != nil {
+		if s.db.IsNotFoundError(err) {
+			return nil, nil
+		}
+		blog.Errorf("get notify rule %d failed, err: %v, rid: %s", id, err, ctx.Kit.Rid)
+		return nil, ctx.Kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+	return rule, nil
+}
+
+// CreateNotifyRule creates a new notify rule.
+func (s *Service) CreateNotifyRule(ctx *rest.Contexts) {
+	rule := new(metadata.NotifyRule)
+	if err := ctx.DecodeInto(rule); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	if !s.validateNotifyRule(ctx, rule) {
+		return
+	}
+	if err := s.authorizeNotifyRuleBiz(ctx, rule.BizID, meta.ViewBusinessResource); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	id, err := s.db.NextSequence(ctx.Kit.Ctx, common.BKTableNameNotifyRule)
+	if err != nil {
+		blog.Errorf("get notify rule id failed, err: %v, rid: %s", err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBInsertFailed))
+		return
+	}
+
+	now := time.Now()
+	rule.ID = int64(id)
+	rule.Creator = ctx.Kit.User
+	rule.Modifier = ctx.Kit.User
+	rule.CreateTime = now
+	rule.LastTime = now
+	rule.OwnerID = ctx.Kit.SupplierAccount
+
+	if err := s.db.Table(common.BKTableNameNotifyRule).Insert(ctx.Kit.Ctx, rule); err != nil {
+		blog.Errorf("create notify rule failed, rule: %+v, err: %v, rid: %s", rule, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBInsertFailed))
+		return
+	}
+	s.reloadNotifyRules()
+
+	ctx.RespEntity(metadata.RspID{ID: rule.ID})
+}
+
+// UpdateNotifyRule updates the notify rule, the whole rule is replaced except the creation info.
+func (s *Service) UpdateNotifyRule(ctx *rest.Contexts) {
+	id, err := strconv.ParseInt(ctx.Request.PathParameter("id"), 10, 64)
+	if err != nil || id <= 0 {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, "id"))
+		return
+	}
+
+	rule := new(metadata.NotifyRule)
+	if err := ctx.DecodeInto(rule); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	if !s.validateNotifyRule(ctx, rule) {
+		return
+	}
+
+	existRule, err := s.getNotifyRule(ctx, id)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	if existRule == nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommNotFound))
+		return
+	}
+
+	// the user must be able to write the rule both before and after it's updated
+	if err := s.authorizeNotifyRule(ctx, existRule, meta.Update); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	updated := &metadata.NotifyRule{Creator: existRule.Creator, BizID: rule.BizID}
+	if err := s.authorizeNotifyRule(ctx, updated, meta.Update); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	if err := s.authorizeNotifyRuleBiz(ctx, rule.BizID, meta.ViewBusinessResource); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	// the url is redacted in the search result, keep the saved one if it's sent back unchanged
+	if rule.Channel.URL == metadata.RedactURL(existRule.Channel.URL) {
+		rule.Channel.URL = existRule.Channel.URL
+	}
+
+	rule.ID = existRule.ID
+	rule.Creator = existRule.Creator
+	rule.CreateTime = existRule.CreateTime
+	rule.OwnerID = existRule.OwnerID
+	rule.Modifier = ctx.Kit.User
+	rule.LastTime = time.Now()
+
+	filter := util.SetQueryOwner(map[string]interface{}{"id": id}, ctx.Kit.SupplierAccount)
+	if err := s.db.Table(common.BKTableNameNotifyRule).Update(ctx.Kit.Ctx, filter, rule); err != nil {
+		blog.Errorf("update notify rule %d failed, err: %v, rid: %s", id, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBUpdateFailed))
+		return
+	}
+	s.reloadNotifyRules()
+
+	ctx.RespEntity(nil)
+}
+
+// DeleteNotifyRule deletes the notify rule, the delivery logs of the rule are reserved.
+func (s *Service) DeleteNotifyRule(ctx *rest.Contexts) {
+	id, err := strconv.ParseInt(ctx.Request.PathParameter("id"), 10, 64)
+	if err != nil || id <= 0 {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, "id"))
+		return
+	}
+
+	rule, err := s.getNotifyRule(ctx, id)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	if rule == nil {
+		ctx.RespEntity(nil)
+		return
+	}
+	if err := s.authorizeNotifyRule(ctx, rule, meta.Delete); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	filter := util.SetQueryOwner(map[string]interface{}{"id": id}, ctx.Kit.SupplierAccount)
+	if err := s.db.Table(common.BKTableNameNotifyRule).Delete(ctx.Kit.Ctx, filter); err != nil {
+		blog.Errorf("delete notify rule %d failed, err: %v, rid: %s", id, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBDeleteFailed))
+		return
+	}
+	s.reloadNotifyRules()
+
+	ctx.RespEntity(nil)
+}
+
+// SearchNotifyRules searches notify rules.
+func (s *Service) SearchNotifyRules(ctx *rest.Contexts) {
+	option := new(metadata.SearchNotifyRuleOption)
+	if err := ctx.DecodeInto(option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	if !s.validateNotifyPage(ctx, &option.Page, "id") {
+		return
+	}
+
+	filter := util.SetQueryOwner(option.Condition, ctx.Kit.SupplierAccount)
+	readable, err := s.readableNotifyRuleFilter(ctx)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	if readable != nil {
+		filter = map[string]interface{}{common.BKDBAND: []map[string]interface{}{filter, readable}}
+	}
+
+	count, err := s.db.Table(common.BKTableNameNotifyRule).Find(filter).Count(ctx.Kit.Ctx)
+	if err != nil {
+		blog.Errorf("count notify rules failed, filter: %+v, err: %v, rid: %s", filter, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBSelectFailed))
+		return
+	}
+
+	rules := make([]metadata.NotifyRule, 0)
+	err = s.db.Table(common.BKTableNameNotifyRule).Find(filter).Sort(option.Page.Sort).
+		Start(uint64(option.Page.Start)).Limit(uint64(option.Page.Limit)).All(ctx.Kit.Ctx, &rules)
+	if err != nil {
+		blog.Errorf("search notify rules failed, filter: %+v, err: %v, rid: %s", filter, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBSelectFailed))
+		return
+	}
+
+	for idx := range rules {
+		rules[idx].Redact()
+	}
+
+	ctx.RespEntity(metadata.SearchNotifyRuleResult{Count: int64(count), Info: rules})
+}
+
+// SearchNotifyDeliveryLogs searches the delivery logs of the notify rules.
+func (s *Service) SearchNotifyDeliveryLogs(ctx *rest.Contexts) {
+	option := new(metadata.SearchNotifyDeliveryLogOption)
+	if err := ctx.DecodeInto(option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	if !s.validateNotifyPage(ctx, &option.Page, "-id") {
+		return
+	}
+
+	filter := make(map[string]interface{})
+	if option.RuleID != 0 {
+		filter["rule_id"] = option.RuleID
+	}
+	if len(option.Status) != 0 {
+		filter["status"] = option.Status
+	}
+	timeFilter := make(map[string]interface{})
+	if option.StartTime != nil {
+		timeFilter[common.BKDBGTE] = *option.StartTime
+	}
+	if option.EndTime != nil {
+		timeFilter[common.BKDBLTE] = *option.EndTime
+	}
+	if len(timeFilter) != 0 {
+		filter[common.CreateTimeField] = timeFilter
+	}
+	filter = util.SetQueryOwner(filter, ctx.Kit.SupplierAccount)
+
+	// only the logs of the readable rules can be found
+	readable, err := s.readableNotifyRuleFilter(ctx)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	if readable != nil {
+		ruleFilter := util.SetQueryOwner(readable, ctx.Kit.SupplierAccount)
+		ruleIDs, err := s.db.Table(common.BKTableNameNotifyRule).Distinct(ctx.Kit.Ctx, "id", ruleFilter)
+		if err != nil {
+			blog.Errorf("get readable notify rule ids failed, err: %v, rid: %s", err, ctx.Kit.Rid)
+			ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBSelectFailed))
+			return
+		}
+		filter = map[string]interface{}{
+			common.BKDBAND: []map[string]interface{}{filter, {"rule_id": map[string]interface{}{common.BKDBIN: ruleIDs}}},
+		}
+	}
+
+	count, err := s.db.Table(common.BKTableNameNotifyDeliveryLog).Find(filter).Count(ctx.Kit.Ctx)
+	if err != nil {
+		blog.Errorf("count notify delivery logs failed, filter: %+v, err: %v, rid: %s", filter, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBSelectFailed))
+		return
+	}
+
+	logs := make([]metadata.NotifyDeliveryLog, 0)
+	err = s.db.Table(common.BKTableNameNotifyDeliveryLog).Find(filter).Sort(option.Page.Sort).
+		Start(uint64(option.Page.Start)).Limit(uint64(option.Page.Limit)).All(ctx.Kit.Ctx, &logs)
+	if err != nil {
+		blog.Errorf("search notify delivery logs failed, filter: %+v, err: %v, rid: %s", filter, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommDBSelectFailed))
+		return
+	}
+
+	for idx := range logs {
+		logs[idx].Message = metadata.RedactURLs(logs[idx].Message)
+	}
+
+	ctx.RespEntity(metadata.SearchNotifyDeliveryLogResult{Count: int64(count), Info: logs})
+}
+
+// validateNotifyPage validates the page and sets the default limit and sort.
+func (s *Service) validateNotifyPage(ctx *rest.Contexts, page *metadata.BasePage, defaultSort string) bool {
+	if page.IsCursorPage() {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, "page.cursor"))
+		return false
+	}
+	if page.Limit > common.BKMaxPageSize {
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommPageLimitIsExceeded))
+		return false
+	}
+	if page.Limit <= 0 {
+		page.Limit = common.BKMaxPageSize
+	}
+	if len(page.Sort) == 0 {
+		page.Sort = defaultSort
+	}
+	return true
+}
+
+// TestNotifyRule matches and renders the rule with the sample event, and sends the notification if not dry run.
+func (s *Service) TestNotifyRule(ctx *rest.Contexts) {
+	option := new(metadata.TestNotifyRuleOption)
+	if err := ctx.DecodeInto(option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	if !s.validateNotifyRule(ctx, &option.Rule) {
+		return
+	}
+	if err := s.authorizeNotifyRuleBiz(ctx, option.Rule.BizID, meta.ViewBusinessResource); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	if s.notifier == nil {
+		blog.Errorf("notifier is not initialized, rid: %s", ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommNotFound))
+		return
+	}
+
+	if len(option.Rule.OwnerID) == 0 {
+		option.Rule.OwnerID = ctx.Kit.SupplierAccount
+	}
+	// the notification is rendered with the fields that the user can view
+	option.Rule.Creator = ctx.Kit.User
+	result := s.notifier.Test(&option.Rule, &option.Event, option.DryRun)
+	result.Error = metadata.RedactURLs(result.Error)
+	ctx.RespEntity(result)
+}
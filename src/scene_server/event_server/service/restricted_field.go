@@ -0,0 +1,102 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"encoding/json"
+
+	"configcenter/src/ac/extensions"
+	"configcenter/src/ac/meta"
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/watch"
+
+	"github.com/tidwall/gjson"
+)
+
+// resourceObjectMap is the mapping of the watch resource to the object whose attributes the event detail holds
+var resourceObjectMap = map[watch.CursorType]string{
+	watch.Host:   common.BKInnerObjIDHost,
+	watch.Biz:    common.BKInnerObjIDApp,
+	watch.Set:    common.BKInnerObjIDSet,
+	watch.Module: common.BKInnerObjIDModule,
+}
+
+// filterEventsRestrictedFields remove the restricted fields that user has no permission to view from event details
+func (s *Service) filterEventsRestrictedFields(ctx *rest.Contexts, rsc watch.CursorType,
+	events []*watch.WatchEventDetail) error {
+
+	if s.authManager == nil || !s.authManager.Enabled() {
+		return nil
+	}
+
+	// group the json details of events by the object they belong to
+	eventObjMap := make(map[string][]*watch.WatchEventDetail)
+	for _, event := range events {
+		if event == nil {
+			continue
+		}
+		detail, ok := event.Detail.(watch.JsonString)
+		if !ok || len(detail) == 0 {
+			continue
+		}
+
+		objID, exist := resourceObjectMap[rsc]
+		if rsc == watch.ObjectBase {
+			objID, exist = gjson.Get(string(detail), common.BKObjIDField).String(), true
+		}
+		if !exist || len(objID) == 0 {
+			continue
+		}
+		eventObjMap[objID] = append(eventObjMap[objID], event)
+	}
+
+	if len(eventObjMap) == 0 {
+		return nil
+	}
+
+	objIDs := make([]string, 0, len(eventObjMap))
+	for objID := range eventObjMap {
+		objIDs = append(objIDs, objID)
+	}
+	noPermission, err := s.authManager.GetNoPermissionRestrictedFields(ctx.Kit.Ctx, ctx.Kit.Header,
+		meta.ViewRestrictedField, objIDs...)
+	if err != nil {
+		blog.Errorf("get no permission restricted fields failed, objIDs: %v, err: %v, rid: %s", objIDs, err, ctx.Kit.Rid)
+		return err
+	}
+
+	for objID, fields := range noPermission {
+		if len(fields) == 0 {
+			continue
+		}
+		for _, event := range eventObjMap[objID] {
+			detail := mapstr.New()
+			if err := json.Unmarshal([]byte(event.Detail.(watch.JsonString)), &detail); err != nil {
+				blog.Errorf("unmarshal event detail failed, cursor: %s, err: %v, rid: %s", event.Cursor, err, ctx.Kit.Rid)
+				return err
+			}
+			extensions.RemoveRestrictedFields(fields, detail)
+
+			js, err := json.Marshal(detail)
+			if err != nil {
+				blog.Errorf("marshal event detail failed, cursor: %s, err: %v, rid: %s", event.Cursor, err, ctx.Kit.Rid)
+				return err
+			}
+			event.Detail = watch.JsonString(js)
+		}
+	}
+	return nil
+}
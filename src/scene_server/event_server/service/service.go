@@ -17,15 +17,21 @@ import (
 	"net/http"
 
 	"configcenter/src/ac"
+	"configcenter/src/ac/extensions"
 	"configcenter/src/common"
 	"configcenter/src/common/backbone"
 	"configcenter/src/common/errors"
 	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
 	"configcenter/src/common/metadata"
 	"configcenter/src/common/metric"
+	"configcenter/src/common/openapi"
 	"configcenter/src/common/rdapi"
 	"configcenter/src/common/types"
+	"configcenter/src/common/version"
+	"configcenter/src/common/watch"
 	"configcenter/src/scene_server/event_server/distribution"
+	"configcenter/src/scene_server/event_server/notification"
 	"configcenter/src/storage/dal"
 	"configcenter/src/storage/dal/redis"
 
@@ -46,7 +52,13 @@ type Service struct {
 	// distributer is event subscription distributer.
 	distributer *distribution.Distributor
 
+	// notifier is change notification notifier.
+	notifier *notification.Notifier
+
 	authorizer ac.AuthorizeInterface
+
+	// authManager is used to filter the restricted fields of the watched events.
+	authManager *extensions.AuthManager
 }
 
 // NewService creates a new Service object.
@@ -68,11 +80,21 @@ func (s *Service) SetAuthorizer(authorizer ac.AuthorizeInterface) {
 	s.authorizer = authorizer
 }
 
+// SetAuthManager setups auth manager.
+func (s *Service) SetAuthManager(authManager *extensions.AuthManager) {
+	s.authManager = authManager
+}
+
 // SetDistributer setups event subscription distributer.
 func (s *Service) SetDistributer(distributer *distribution.Distributor) {
 	s.distributer = distributer
 }
 
+// SetNotifier setups change notification notifier.
+func (s *Service) SetNotifier(notifier *notification.Notifier) {
+	s.notifier = notifier
+}
+
 // WebService setups a new restful web service.
 func (s *Service) WebService() *restful.Container {
 	container := restful.NewContainer()
@@ -93,6 +115,7 @@ func (s *Service) WebService() *restful.Container {
 
 	healthzAPI := new(restful.WebService).Produces(restful.MIME_JSON)
 	healthzAPI.Route(healthzAPI.GET("/healthz").To(s.Healthz))
+	healthzAPI.Route(healthzAPI.GET("/openapi").To(openapi.RouteFunction(container, types.CC_MODULE_EVENTSERVER, version.CCVersion)))
 	container.Add(healthzAPI)
 
 	return container
@@ -105,13 +128,32 @@ func (s *Service) initService(web *restful.WebService) {
 		Language: s.engine.Language,
 	})
 
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/subscribe/search/{ownerID}/{appID}", Handler: s.ListSubscriptions})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/subscribe/{ownerID}/{appID}", Handler: s.Subscribe})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/subscribe/search/{ownerID}/{appID}", Handler: s.ListSubscriptions,
+		Request: metadata.ParamSubscriptionSearch{}, Response: metadata.RspSubscriptionSearch{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/subscribe/{ownerID}/{appID}", Handler: s.Subscribe,
+		Request: metadata.Subscription{}, Response: mapstr.MapStr{}})
 	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/subscribe/{ownerID}/{appID}/{subscribeID}", Handler: s.UnSubscribe})
-	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/subscribe/{ownerID}/{appID}/{subscribeID}", Handler: s.UpdateSubscription})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/subscribe/ping", Handler: s.Ping})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/subscribe/telnet", Handler: s.Telnet})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/watch/resource/{resource}", Handler: s.WatchEvent})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/subscribe/{ownerID}/{appID}/{subscribeID}", Handler: s.UpdateSubscription,
+		Request: metadata.Subscription{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/subscribe/ping", Handler: s.Ping,
+		Request: metadata.ParamSubscriptionTestCallback{}, Response: metadata.RspSubscriptionTestCallback{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/subscribe/telnet", Handler: s.Telnet,
+		Request: metadata.ParamSubscriptionTelnet{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/watch/resource/{resource}", Handler: s.WatchEvent,
+		Request: watch.WatchEventOptions{}, Response: watch.WatchResp{}})
+
+	// change notification rules.
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/notify/rule", Handler: s.CreateNotifyRule,
+		Request: metadata.NotifyRule{}, Response: metadata.RspID{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/notify/rule/{id}", Handler: s.UpdateNotifyRule,
+		Request: metadata.NotifyRule{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/notify/rule/{id}", Handler: s.DeleteNotifyRule})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/notify/rule/search", Handler: s.SearchNotifyRules,
+		Request: metadata.SearchNotifyRuleOption{}, Response: metadata.SearchNotifyRuleResult{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/notify/rule/test", Handler: s.TestNotifyRule,
+		Request: metadata.TestNotifyRuleOption{}, Response: metadata.TestNotifyRuleResult{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/notify/log/search", Handler: s.SearchNotifyDeliveryLogs,
+		Request: metadata.SearchNotifyDeliveryLogOption{}, Response: metadata.SearchNotifyDeliveryLogResult{}})
 
 	utility.AddToRestfulWebService(web)
 
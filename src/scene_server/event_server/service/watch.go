@@ -58,10 +58,20 @@ func (s *Service) WatchEvent(ctx *rest.Contexts) {
 		if err != nil {
 			blog.Errorf("watch event with cursor failed, cursor: %s, err: %v, rid: %s", options.Cursor, err, ctx.Kit.Rid)
 			time.Sleep(500 * time.Millisecond)
+			if err == ewatcher.StartCursorNotExistError {
+				// the cursor is expired, the user need to watch from a start time or from now.
+				ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrEventWatchCursorNotExist))
+				return
+			}
 			ctx.RespAutoError(err)
 			return
 		}
 
+		if err := s.filterEventsRestrictedFields(ctx, options.Resource, events); err != nil {
+			ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommAuthorizeFailed))
+			return
+		}
+
 		// if not events is hit, then we return user's cursor, so that they can watch with this cursor again.
 		ctx.RespEntity(s.generateResp(options.Cursor, options.Resource, events))
 		return
@@ -77,6 +87,11 @@ func (s *Service) WatchEvent(ctx *rest.Contexts) {
 			return
 		}
 
+		if err := s.filterEventsRestrictedFields(ctx, options.Resource, events); err != nil {
+			ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommAuthorizeFailed))
+			return
+		}
+
 		ctx.RespEntity(s.generateResp("", options.Resource, events))
 		return
 	}
@@ -90,7 +105,13 @@ func (s *Service) WatchEvent(ctx *rest.Contexts) {
 		return
 	}
 
-	ctx.RespEntity(s.generateResp("", options.Resource, []*watch.WatchEventDetail{events}))
+	eventDetails := []*watch.WatchEventDetail{events}
+	if err := s.filterEventsRestrictedFields(ctx, options.Resource, eventDetails); err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommAuthorizeFailed))
+		return
+	}
+
+	ctx.RespEntity(s.generateResp("", options.Resource, eventDetails))
 }
 
 func (s *Service) generateResp(startCursor string, rsc watch.CursorType, events []*watch.WatchEventDetail) *watch.WatchResp {
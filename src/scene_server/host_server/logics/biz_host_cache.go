@@ -0,0 +1,251 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package logics
+
+import (
+	"sort"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/json"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+)
+
+// cacheBatchSize is the max length of the ids in a cache list request
+const cacheBatchSize = 500
+
+// relationPageSize is the page size of reading the host module relations from db
+const relationPageSize = common.BKMaxRecordsAtOnce
+
+// BizHostTopo is the hosts in a business with their sets and modules, the business, sets, modules and hosts are read
+// from cache, only the host module relations are read from db.
+type BizHostTopo struct {
+	BizID   int64
+	BizName string
+	// HostIDs are sorted so that the outputs built from the topology are stable
+	HostIDs   []int64
+	Relations map[int64][]metadata.ModuleHost
+	Sets      map[int64]metadata.SetInst
+	Modules   map[int64]metadata.ModuleInst
+	Hosts     map[int64]mapstr.MapStr
+}
+
+// BizHostTopoOption select the hosts of the business by topology, the hosts in any of the sets, modules and the
+// modules created by any of the service templates are selected, empty means all
+type BizHostTopoOption struct {
+	BizID              int64
+	SetIDs             []int64
+	ModuleIDs          []int64
+	ServiceTemplateIDs []int64
+	// HostFields are the host fields to return besides the host id, inner ip and cloud id
+	HostFields []string
+}
+
+// GetBizHostTopoFromCache get the hosts of the business with their topology, it's used by the apis that are
+// polled frequently by other systems, such as the service discovery and the inventory.
+func (lgc *Logics) GetBizHostTopoFromCache(kit *rest.Kit, opt *BizHostTopoOption) (*BizHostTopo, errors.CCError) {
+	bizStr, err := lgc.CoreAPI.CacheService().Cache().Topology().SearchBusiness(kit.Ctx, kit.Header, opt.BizID)
+	if err != nil {
+		blog.Errorf("get business %d from cache failed, err: %v, rid: %s", opt.BizID, err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommHTTPDoRequestFailed)
+	}
+	biz := new(metadata.BizBasicInfo)
+	if err := json.Unmarshal([]byte(bizStr), biz); err != nil {
+		blog.Errorf("unmarshal business %s failed, err: %v, rid: %s", bizStr, err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommJSONUnmarshalFailed)
+	}
+
+	relations, ccErr := lgc.listBizHostModuleRelations(kit, opt)
+	if ccErr != nil {
+		return nil, ccErr
+	}
+
+	moduleIDs := make([]int64, 0)
+	setIDs := make([]int64, 0)
+	for _, relation := range relations {
+		moduleIDs = append(moduleIDs, relation.ModuleID)
+		setIDs = append(setIDs, relation.SetID)
+	}
+
+	modules, ccErr := lgc.listModulesFromCache(kit, util.IntArrayUnique(moduleIDs))
+	if ccErr != nil {
+		return nil, ccErr
+	}
+	if len(opt.ServiceTemplateIDs) != 0 {
+		for id, module := range modules {
+			if !util.InArray(module.ServiceTemplateID, opt.ServiceTemplateIDs) {
+				delete(modules, id)
+			}
+		}
+	}
+
+	topo := &BizHostTopo{
+		BizID:     opt.BizID,
+		BizName:   biz.BizName,
+		HostIDs:   make([]int64, 0),
+		Relations: make(map[int64][]metadata.ModuleHost),
+		Modules:   modules,
+		Hosts:     make(map[int64]mapstr.MapStr),
+	}
+	for _, relation := range relations {
+		if _, exists := modules[relation.ModuleID]; !exists {
+			continue
+		}
+		if _, exists := topo.Relations[relation.HostID]; !exists {
+			topo.HostIDs = append(topo.HostIDs, relation.HostID)
+		}
+		topo.Relations[relation.HostID] = append(topo.Relations[relation.HostID], relation)
+	}
+	sort.Slice(topo.HostIDs, func(i, j int) bool { return topo.HostIDs[i] < topo.HostIDs[j] })
+
+	if topo.Sets, ccErr = lgc.listSetsFromCache(kit, util.IntArrayUnique(setIDs)); ccErr != nil {
+		return nil, ccErr
+	}
+
+	fields := append([]string{common.BKHostIDField, common.BKHostInnerIPField, common.BKCloudIDField},
+		opt.HostFields...)
+	for start := 0; start < len(topo.HostIDs); start += cacheBatchSize {
+		end := start + cacheBatchSize
+		if end > len(topo.HostIDs) {
+			end = len(topo.HostIDs)
+		}
+		hostOpt := &metadata.ListWithIDOption{IDs: topo.HostIDs[start:end], Fields: fields}
+		hostStr, err := lgc.CoreAPI.CacheService().Cache().Host().ListHostWithHostID(kit.Ctx, kit.Header, hostOpt)
+		if err != nil {
+			blog.Errorf("list hosts from cache failed, ids: %v, err: %v, rid: %s", hostOpt.IDs, err, kit.Rid)
+			return nil, kit.CCError.CCError(common.CCErrCommHTTPDoRequestFailed)
+		}
+		hosts := make([]mapstr.MapStr, 0)
+		if err := json.Unmarshal([]byte(hostStr), &hosts); err != nil {
+			blog.Errorf("unmarshal hosts %s failed, err: %v, rid: %s", hostStr, err, kit.Rid)
+			return nil, kit.CCError.CCError(common.CCErrCommJSONUnmarshalFailed)
+		}
+		for _, host := range hosts {
+			hostID, err := util.GetInt64ByInterface(host[common.BKHostIDField])
+			if err != nil {
+				blog.Errorf("parse host id failed, host: %v, err: %v, rid: %s", host, err, kit.Rid)
+				return nil, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKHostIDField)
+			}
+			topo.Hosts[hostID] = host
+		}
+	}
+
+	// the host attributes are exposed to other systems, so the restricted fields are removed like the host search
+	hosts := make([]mapstr.MapStr, 0, len(topo.Hosts))
+	for _, host := range topo.Hosts {
+		hosts = append(hosts, host)
+	}
+	if err := lgc.AuthManager.FilterRestrictedFields(kit.Ctx, kit.Header, common.BKInnerObjIDHost, hosts...); err != nil {
+		blog.Errorf("filter host restricted fields failed, err: %v, rid: %s", err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommAuthorizeFailed)
+	}
+
+	return topo, nil
+}
+
+// listBizHostModuleRelations read the host module relations of the business in the sets and modules page by page,
+// so that a large business does not read all the relations in one request.
+func (lgc *Logics) listBizHostModuleRelations(kit *rest.Kit, opt *BizHostTopoOption) ([]metadata.ModuleHost,
+	errors.CCError) {
+
+	relationOpt := &metadata.HostModuleRelationRequest{
+		ApplicationID: opt.BizID,
+		SetIDArr:      opt.SetIDs,
+		ModuleIDArr:   opt.ModuleIDs,
+		Page: metadata.BasePage{
+			Limit: relationPageSize,
+			Sort:  common.BKHostIDField + "," + common.BKModuleIDField,
+		},
+		Fields: []string{common.BKHostIDField, common.BKSetIDField, common.BKModuleIDField},
+	}
+
+	relations := make([]metadata.ModuleHost, 0)
+	for {
+		relationRes, err := lgc.CoreAPI.CoreService().Host().GetHostModuleRelation(kit.Ctx, kit.Header, relationOpt)
+		if err != nil {
+			blog.Errorf("get host module relation failed, opt: %#v, err: %v, rid: %s", relationOpt, err, kit.Rid)
+			return nil, kit.CCError.CCError(common.CCErrCommHTTPDoRequestFailed)
+		}
+		if !relationRes.Result {
+			blog.Errorf("get host module relation failed, opt: %#v, err: %s, rid: %s", relationOpt,
+				relationRes.ErrMsg, kit.Rid)
+			return nil, kit.CCError.New(relationRes.Code, relationRes.ErrMsg)
+		}
+
+		relations = append(relations, relationRes.Data.Info...)
+		if len(relationRes.Data.Info) < relationPageSize {
+			return relations, nil
+		}
+		relationOpt.Page.Start += relationPageSize
+	}
+}
+
+func (lgc *Logics) listModulesFromCache(kit *rest.Kit, ids []int64) (map[int64]metadata.ModuleInst, errors.CCError) {
+	modules := make(map[int64]metadata.ModuleInst)
+	for start := 0; start < len(ids); start += cacheBatchSize {
+		end := start + cacheBatchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		opt := &metadata.ListWithIDOption{
+			IDs:    ids[start:end],
+			Fields: []string{common.BKModuleIDField, common.BKModuleNameField, common.BKServiceTemplateIDField},
+		}
+		moduleStr, err := lgc.CoreAPI.CacheService().Cache().Topology().ListModules(kit.Ctx, kit.Header, opt)
+		if err != nil {
+			blog.Errorf("list modules from cache failed, ids: %v, err: %v, rid: %s", opt.IDs, err, kit.Rid)
+			return nil, kit.CCError.CCError(common.CCErrCommHTTPDoRequestFailed)
+		}
+		moduleArr := make([]metadata.ModuleInst, 0)
+		if err := json.Unmarshal([]byte(moduleStr), &moduleArr); err != nil {
+			blog.Errorf("unmarshal modules %s failed, err: %v, rid: %s", moduleStr, err, kit.Rid)
+			return nil, kit.CCError.CCError(common.CCErrCommJSONUnmarshalFailed)
+		}
+		for _, module := range moduleArr {
+			modules[module.ModuleID] = module
+		}
+	}
+	return modules, nil
+}
+
+func (lgc *Logics) listSetsFromCache(kit *rest.Kit, ids []int64) (map[int64]metadata.SetInst, errors.CCError) {
+	sets := make(map[int64]metadata.SetInst)
+	for start := 0; start < len(ids); start += cacheBatchSize {
+		end := start + cacheBatchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		opt := &metadata.ListWithIDOption{
+			IDs:    ids[start:end],
+			Fields: []string{common.BKSetIDField, common.BKSetNameField},
+		}
+		setStr, err := lgc.CoreAPI.CacheService().Cache().Topology().ListSets(kit.Ctx, kit.Header, opt)
+		if err != nil {
+			blog.Errorf("list sets from cache failed, ids: %v, err: %v, rid: %s", opt.IDs, err, kit.Rid)
+			return nil, kit.CCError.CCError(common.CCErrCommHTTPDoRequestFailed)
+		}
+		setArr := make([]metadata.SetInst, 0)
+		if err := json.Unmarshal([]byte(setStr), &setArr); err != nil {
+			blog.Errorf("unmarshal sets %s failed, err: %v, rid: %s", setStr, err, kit.Rid)
+			return nil, kit.CCError.CCError(common.CCErrCommJSONUnmarshalFailed)
+		}
+		for _, set := range setArr {
+			sets[set.SetID] = set
+		}
+	}
+	return sets, nil
+}
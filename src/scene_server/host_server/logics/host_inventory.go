@@ -0,0 +1,138 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package logics
+
+import (
+	"fmt"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+)
+
+// HostInventory generates the inventory of the hosts in the businesses, the groups are named by the ids since the
+// names of the sets and modules are not unique, such as biz_2, set_10, module_20 and service_template_5, and the
+// names are in the group vars.
+func (lgc *Logics) HostInventory(kit *rest.Kit, opt *metadata.HostInventoryOption) (*metadata.HostInventoryResult,
+	errors.CCError) {
+
+	groups := make(map[string]*metadata.AnsibleGroup)
+	hosts := make([]metadata.InventoryHost, 0)
+
+	for _, bizID := range util.IntArrayUnique(opt.BizIDs) {
+		topo, err := lgc.GetBizHostTopoFromCache(kit, &BizHostTopoOption{
+			BizID:              bizID,
+			SetIDs:             opt.SetIDs,
+			ModuleIDs:          opt.ModuleIDs,
+			ServiceTemplateIDs: opt.ServiceTemplateIDs,
+			HostFields:         opt.HostVars,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		bizGroup := &metadata.AnsibleGroup{
+			Vars: map[string]interface{}{common.BKAppIDField: bizID, common.BKAppNameField: topo.BizName},
+		}
+		groups[fmt.Sprintf("biz_%d", bizID)] = bizGroup
+
+		for _, hostID := range topo.HostIDs {
+			host, exists := topo.Hosts[hostID]
+			if !exists {
+				continue
+			}
+			ip := metadata.PrometheusHostIP(host[common.BKHostInnerIPField])
+			if len(ip) == 0 {
+				blog.Warnf("host %d has no inner ip, skip it in the inventory, rid: %s", hostID, kit.Rid)
+				continue
+			}
+			cloudID, err := util.GetInt64ByInterface(host[common.BKCloudIDField])
+			if err != nil {
+				blog.Errorf("parse cloud id failed, host: %v, err: %v, rid: %s", host, err, kit.Rid)
+				return nil, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKCloudIDField)
+			}
+
+			inventoryHost := metadata.InventoryHost{
+				HostID:  hostID,
+				CloudID: cloudID,
+				IP:      ip,
+				Groups:  make([]string, 0),
+				Vars: map[string]interface{}{
+					common.BKHostIDField:      hostID,
+					common.BKCloudIDField:     cloudID,
+					common.BKHostInnerIPField: host[common.BKHostInnerIPField],
+					common.BKAppIDField:       bizID,
+				},
+			}
+			for _, field := range opt.HostVars {
+				if value, exists := host[field]; exists {
+					inventoryHost.Vars[field] = value
+				}
+			}
+
+			for _, relation := range topo.Relations[hostID] {
+				module, exists := topo.Modules[relation.ModuleID]
+				if !exists {
+					continue
+				}
+				setName := fmt.Sprintf("set_%d", relation.SetID)
+				moduleName := fmt.Sprintf("module_%d", module.ModuleID)
+
+				if _, exists := groups[setName]; !exists {
+					groups[setName] = &metadata.AnsibleGroup{
+						Vars: map[string]interface{}{
+							common.BKSetIDField:   relation.SetID,
+							common.BKSetNameField: topo.Sets[relation.SetID].SetName,
+							common.BKAppIDField:   bizID,
+						},
+					}
+					bizGroup.Children = append(bizGroup.Children, setName)
+				}
+				if _, exists := groups[moduleName]; !exists {
+					groups[moduleName] = &metadata.AnsibleGroup{
+						Vars: map[string]interface{}{
+							common.BKModuleIDField:          module.ModuleID,
+							common.BKModuleNameField:        module.ModuleName,
+							common.BKSetIDField:             relation.SetID,
+							common.BKServiceTemplateIDField: module.ServiceTemplateID,
+						},
+					}
+					groups[setName].Children = append(groups[setName].Children, moduleName)
+				}
+				inventoryHost.Groups = append(inventoryHost.Groups, moduleName)
+
+				if module.ServiceTemplateID == 0 {
+					continue
+				}
+				templateName := fmt.Sprintf("service_template_%d", module.ServiceTemplateID)
+				if _, exists := groups[templateName]; !exists {
+					groups[templateName] = &metadata.AnsibleGroup{
+						Vars: map[string]interface{}{common.BKServiceTemplateIDField: module.ServiceTemplateID},
+					}
+				}
+				if !util.InArray(templateName, inventoryHost.Groups) {
+					inventoryHost.Groups = append(inventoryHost.Groups, templateName)
+				}
+			}
+			hosts = append(hosts, inventoryHost)
+		}
+	}
+
+	if opt.Format == metadata.HostInventorySSHConfig {
+		return &metadata.HostInventoryResult{SSHConfig: metadata.NewSSHConfig(hosts, opt.SSHUser, opt.SSHPort)}, nil
+	}
+	return &metadata.HostInventoryResult{Ansible: metadata.NewAnsibleInventory(groups, hosts)}, nil
+}
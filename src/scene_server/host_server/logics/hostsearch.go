@@ -685,6 +685,7 @@ func (sh *searchHost) searchByHostConds() errors.CCError {
 		Limit:     sh.hostSearchParam.Page.Limit,
 		Sort:      sh.hostSearchParam.Page.Sort,
 		Fields:    strings.Join(sh.conds.hostCond.Fields, ","),
+		Filter:    sh.hostSearchParam.HostPropertyFilter,
 	}
 	sh.conds.hostCond.Fields = nil
 	sh.hostSearchParam = nil
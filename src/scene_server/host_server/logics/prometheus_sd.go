@@ -0,0 +1,176 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package logics
+
+import (
+	"net"
+	"strconv"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/json"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+)
+
+// prometheusSDProcess is the process attributes used by the prometheus service discovery
+type prometheusSDProcess struct {
+	ProcessID   int64                   `json:"bk_process_id"`
+	ProcessName string                  `json:"bk_process_name"`
+	FuncName    string                  `json:"bk_func_name"`
+	BindInfo    []metadata.ProcBindInfo `json:"bind_info"`
+}
+
+// PrometheusSDTargets generates the prometheus http service discovery targets of the hosts or the processes
+// in the business
+func (lgc *Logics) PrometheusSDTargets(kit *rest.Kit, opt *metadata.PrometheusSDOption) (
+	[]*metadata.PrometheusTargetGroup, errors.CCError) {
+
+	topo, err := lgc.GetBizHostTopoFromCache(kit, &BizHostTopoOption{
+		BizID:              opt.BizID,
+		SetIDs:             opt.SetIDs,
+		ModuleIDs:          opt.ModuleIDs,
+		ServiceTemplateIDs: opt.ServiceTemplateIDs,
+		HostFields:         opt.Labels,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make([]*metadata.PrometheusTargetGroup, 0)
+	if opt.Mode == metadata.PrometheusSDHostMode {
+		port := strconv.FormatInt(opt.Port, 10)
+		for _, hostID := range topo.HostIDs {
+			host, exists := topo.Hosts[hostID]
+			if !exists {
+				continue
+			}
+			ip := metadata.PrometheusHostIP(host[common.BKHostInnerIPField])
+			if len(ip) == 0 {
+				continue
+			}
+			group := metadata.NewPrometheusTargetGroup(net.JoinHostPort(ip, port))
+			setPrometheusHostLabels(group, topo, host, opt.Labels, topo.Relations[hostID])
+			groups = append(groups, group)
+		}
+		return groups, nil
+	}
+
+	for start := 0; start < len(topo.HostIDs); start += cacheBatchSize {
+		end := start + cacheBatchSize
+		if end > len(topo.HostIDs) {
+			end = len(topo.HostIDs)
+		}
+		instOpt := &metadata.ListServiceInstanceWithHostOption{BizID: opt.BizID, HostIDs: topo.HostIDs[start:end]}
+		instStr, err := lgc.CoreAPI.CacheService().Cache().Process().ListServiceInstancesWithHostIDs(kit.Ctx,
+			kit.Header, instOpt)
+		if err != nil {
+			blog.Errorf("list service instances from cache failed, opt: %#v, err: %v, rid: %s", instOpt, err, kit.Rid)
+			return nil, kit.CCError.CCError(common.CCErrCommHTTPDoRequestFailed)
+		}
+		hostInstances := make([]metadata.HostServiceInstanceCache, 0)
+		if err := json.Unmarshal([]byte(instStr), &hostInstances); err != nil {
+			blog.Errorf("unmarshal service instances %s failed, err: %v, rid: %s", instStr, err, kit.Rid)
+			return nil, kit.CCError.CCError(common.CCErrCommJSONUnmarshalFailed)
+		}
+
+		for _, hostInstance := range hostInstances {
+			host, exists := topo.Hosts[hostInstance.HostID]
+			if !exists {
+				continue
+			}
+			hostIP := metadata.PrometheusHostIP(host[common.BKHostInnerIPField])
+
+			for _, instance := range hostInstance.ServiceInstances {
+				// the service instances in the modules that are not selected are skipped
+				if _, exists := topo.Modules[instance.ModuleID]; !exists {
+					continue
+				}
+				relations := make([]metadata.ModuleHost, 0, 1)
+				for _, relation := range topo.Relations[instance.HostID] {
+					if relation.ModuleID == instance.ModuleID {
+						relations = append(relations, relation)
+					}
+				}
+
+				for _, processInst := range instance.Processes {
+					process := new(prometheusSDProcess)
+					if err := mapstr.DecodeFromMapStr(process, processInst.Property); err != nil {
+						blog.Errorf("decode process %v failed, err: %v, rid: %s", processInst.Property, err, kit.Rid)
+						return nil, kit.CCError.CCError(common.CCErrCommJSONUnmarshalFailed)
+					}
+					if !opt.MatchProcessName(process.ProcessName) {
+						continue
+					}
+
+					for _, target := range metadata.PrometheusBindTargets(process.BindInfo, hostIP, opt.Port) {
+						group := metadata.NewPrometheusTargetGroup(target.Address)
+						setPrometheusHostLabels(group, topo, host, opt.Labels, relations)
+						group.SetLabel("service_instance_id", instance.ID)
+						group.SetLabel("service_instance_name", instance.Name)
+						group.SetLabel(common.BKProcessIDField, process.ProcessID)
+						group.SetLabel(common.BKProcessNameField, process.ProcessName)
+						group.SetLabel(common.BKFuncName, process.FuncName)
+						group.SetLabel("port", target.Port)
+						groups = append(groups, group)
+					}
+				}
+			}
+		}
+	}
+	return groups, nil
+}
+
+// setPrometheusHostLabels sets the labels of the business, topology and the host attributes, the names and ids of
+// the sets, modules and service templates are joined by commas since a host can be in multiple modules
+func setPrometheusHostLabels(group *metadata.PrometheusTargetGroup, topo *BizHostTopo, host mapstr.MapStr,
+	labels []string, relations []metadata.ModuleHost) {
+
+	group.SetLabel(common.BKAppIDField, topo.BizID)
+	group.SetLabel(common.BKAppNameField, topo.BizName)
+	group.SetLabel(common.BKHostIDField, host[common.BKHostIDField])
+	group.SetLabel(common.BKHostInnerIPField, host[common.BKHostInnerIPField])
+	group.SetLabel(common.BKCloudIDField, host[common.BKCloudIDField])
+
+	setIDs, setNames := make([]string, 0), make([]string, 0)
+	moduleIDs, moduleNames, templateIDs := make([]string, 0), make([]string, 0), make([]string, 0)
+	existSets, existTemplates := make(map[int64]bool), make(map[int64]bool)
+	for _, relation := range relations {
+		module, exists := topo.Modules[relation.ModuleID]
+		if !exists {
+			continue
+		}
+		moduleIDs = append(moduleIDs, strconv.FormatInt(module.ModuleID, 10))
+		moduleNames = append(moduleNames, module.ModuleName)
+		if module.ServiceTemplateID != 0 && !existTemplates[module.ServiceTemplateID] {
+			existTemplates[module.ServiceTemplateID] = true
+			templateIDs = append(templateIDs, strconv.FormatInt(module.ServiceTemplateID, 10))
+		}
+		if !existSets[relation.SetID] {
+			existSets[relation.SetID] = true
+			setIDs = append(setIDs, strconv.FormatInt(relation.SetID, 10))
+			setNames = append(setNames, topo.Sets[relation.SetID].SetName)
+		}
+	}
+	group.SetLabel(common.BKSetIDField, metadata.PrometheusJoinLabelValues(setIDs))
+	group.SetLabel(common.BKSetNameField, metadata.PrometheusJoinLabelValues(setNames))
+	group.SetLabel(common.BKModuleIDField, metadata.PrometheusJoinLabelValues(moduleIDs))
+	group.SetLabel(common.BKModuleNameField, metadata.PrometheusJoinLabelValues(moduleNames))
+	group.SetLabel(common.BKServiceTemplateIDField, metadata.PrometheusJoinLabelValues(templateIDs))
+
+	for _, field := range labels {
+		group.SetLabel("host_"+field, host[field])
+	}
+}
@@ -0,0 +1,50 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"configcenter/src/common"
+	"configcenter/src/common/approval"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+)
+
+// approvalGate returns the approval gate of the host server, the approved operations are sent to "/host/v3"
+func (s *Service) approvalGate() *approval.Gate {
+	return approval.NewGate(s.Engine.CoreAPI.CoreService(), metadata.ApprovalServerHost, "/host/v3")
+}
+
+// approvalHostRelationPreview previews the current topology of the hosts to be transferred
+func (s *Service) approvalHostRelationPreview(kit *rest.Kit, bizID int64, hostIDs []int64) approval.PreviewFunc {
+	return func() (interface{}, error) {
+		option := &metadata.HostModuleRelationRequest{
+			ApplicationID: bizID,
+			HostIDArr:     hostIDs,
+			Page:          metadata.BasePage{Limit: common.BKNoLimit},
+			Fields:        []string{common.BKHostIDField, common.BKSetIDField, common.BKModuleIDField},
+		}
+		result, err := s.Engine.CoreAPI.CoreService().Host().GetHostModuleRelation(kit.Ctx, kit.Header, option)
+		if err != nil {
+			blog.Errorf("get host module relation failed, biz: %d, hosts: %v, err: %v, rid: %s", bizID, hostIDs, err,
+				kit.Rid)
+			return nil, kit.CCError.CCError(common.CCErrCommHTTPDoRequestFailed)
+		}
+		if !result.Result {
+			blog.Errorf("get host module relation failed, biz: %d, hosts: %v, err: %s, rid: %s", bizID, hostIDs,
+				result.ErrMsg, kit.Rid)
+			return nil, kit.CCError.New(result.Code, result.ErrMsg)
+		}
+		return result.Data.Info, nil
+	}
+}
@@ -39,6 +39,12 @@ func (s *Service) FindManyCloudArea(ctx *rest.Contexts) {
 		return
 	}
 
+	if err := metadata.ValidateUserQueryOperators(input.Condition); err != nil {
+		blog.Errorf("FindManyCloudArea failed, condition is invalid, err: %v, rid: %s", err, rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "condition"))
+		return
+	}
+
 	// set default limit
 	if input.Page.Limit == 0 {
 		input.Page.Limit = common.BKMaxPageSize
@@ -104,9 +110,7 @@ func (s *Service) FindManyCloudArea(ctx *rest.Contexts) {
 // CreatePlatBatch create plat instance in batch
 func (s *Service) CreatePlatBatch(ctx *rest.Contexts) {
 
-	input := struct {
-		Data []mapstr.MapStr `json:"data"`
-	}{}
+	input := metadata.CreateManyCloudAreaOption{}
 
 	if err := ctx.DecodeInto(&input); nil != err {
 		ctx.RespAutoError(err)
@@ -402,11 +406,7 @@ func (s *Service) UpdatePlat(ctx *rest.Contexts) {
 	}
 
 	// decode request body
-	input := struct {
-		CloudName   string `json:"bk_cloud_name"`
-		CloudVendor string `json:"bk_cloud_vendor"`
-		Region      string `json:"bk_region"`
-	}{}
+	input := metadata.UpdateCloudAreaOption{}
 
 	if err := ctx.DecodeInto(&input); err != nil {
 		ctx.RespAutoError(err)
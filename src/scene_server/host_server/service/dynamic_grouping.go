@@ -479,6 +479,11 @@ func (s *Service) ExecuteDynamicGroup(ctx *rest.Contexts) {
 
 	// execute dynamic group with target object type.
 	if targetDynamicGroup.ObjID == common.BKInnerObjIDHost {
+		if err := s.checkHostRestrictedFieldsSearch(ctx, hostSearchConditionFields(searchConditions, nil)...); err != nil {
+			ctx.RespAutoError(err)
+			return
+		}
+
 		// build host search conditions.
 		searchHostCondition := meta.HostCommonSearch{AppID: bizIDInt64, Condition: searchConditions, Page: searchPage}
 
@@ -492,6 +497,11 @@ func (s *Service) ExecuteDynamicGroup(ctx *rest.Contexts) {
 			return
 		}
 
+		if err := s.filterHostRestrictedFields(ctx, data.Info...); err != nil {
+			ctx.RespAutoError(err)
+			return
+		}
+
 		ctx.RespEntity(meta.InstDataInfo{
 			Count: data.Count,
 			Info:  data.Info,
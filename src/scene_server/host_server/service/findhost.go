@@ -49,6 +49,11 @@ func (s *Service) FindModuleHost(ctx *rest.Contexts) {
 		return
 	}
 
+	if err := s.filterSearchHostRestrictedFields(ctx, host); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
 	ctx.RespEntity(*host)
 }
 
@@ -292,6 +297,10 @@ func (s *Service) findDistinctHostInfo(ctx *rest.Contexts, distinctHostCond *met
 		return nil, err
 	}
 
+	if err := s.filterHostRestrictedFields(ctx, hostInfo...); err != nil {
+		return nil, err
+	}
+
 	return &meta.SearchHostResult{
 		BaseResp: meta.SuccessBaseResp,
 		Data: &meta.SearchHost{
@@ -454,6 +463,12 @@ func (s *Service) ListResourcePoolHosts(ctx *rest.Contexts) {
 		return
 	}
 
+	if err := s.checkHostRestrictedFieldsSearch(ctx, hostSearchConditionFields(nil,
+		parameter.HostPropertyFilter)...); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
 	filter := &meta.QueryCondition{
 		Fields: []string{common.BKAppIDField, common.BKAppNameField},
 		Page: meta.BasePage{
@@ -516,6 +531,10 @@ func (s *Service) ListResourcePoolHosts(ctx *rest.Contexts) {
 		ctx.RespAutoError(ccErr)
 		return
 	}
+	if ccErr := s.filterListHostRestrictedFields(ctx, hostResult); ccErr != nil {
+		ctx.RespAutoError(ccErr)
+		return
+	}
 	ctx.RespEntity(hostResult)
 }
 
@@ -531,6 +550,12 @@ func (s *Service) ListBizHosts(ctx *rest.Contexts) {
 		return
 	}
 
+	if err := s.checkHostRestrictedFieldsSearch(ctx, hostSearchConditionFields(nil,
+		parameter.HostPropertyFilter)...); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
 	if key, err := parameter.Validate(); err != nil {
 		blog.ErrorJSON("ListBizHosts failed, Validate failed,parameter:%s, err: %s, rid:%s", parameter, err, ctx.Kit.Rid)
 		ccErr := defErr.CCErrorf(common.CCErrCommParamsInvalid, key)
@@ -555,6 +580,10 @@ func (s *Service) ListBizHosts(ctx *rest.Contexts) {
 		ctx.RespAutoError(ccErr)
 		return
 	}
+	if ccErr := s.filterListHostRestrictedFields(ctx, hostResult); ccErr != nil {
+		ctx.RespAutoError(ccErr)
+		return
+	}
 	ctx.RespEntity(hostResult)
 }
 
@@ -653,6 +682,12 @@ func (s *Service) ListHostsWithNoBiz(ctx *rest.Contexts) {
 		return
 	}
 
+	if err := s.checkHostRestrictedFieldsSearch(ctx, hostSearchConditionFields(nil,
+		parameter.HostPropertyFilter)...); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
 	if key, err := parameter.Validate(); err != nil {
 		blog.ErrorJSON("ListHostsWithNoBiz failed, decode body failed,parameter:%s, err: %#v, rid:%s", parameter, err, ctx.Kit.Rid)
 		ccErr := defErr.CCErrorf(common.CCErrCommParamsInvalid, key)
@@ -674,6 +709,10 @@ func (s *Service) ListHostsWithNoBiz(ctx *rest.Contexts) {
 		ctx.RespAutoError(defErr.Error(common.CCErrHostGetFail))
 		return
 	}
+	if ccErr := s.filterListHostRestrictedFields(ctx, host); ccErr != nil {
+		ctx.RespAutoError(ccErr)
+		return
+	}
 	ctx.RespEntity(host)
 
 }
@@ -690,6 +729,12 @@ func (s *Service) ListBizHostsTopo(ctx *rest.Contexts) {
 		return
 	}
 
+	if err := s.checkHostRestrictedFieldsSearch(ctx, hostSearchConditionFields(nil,
+		parameter.HostPropertyFilter)...); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
 	if key, err := parameter.Validate(); err != nil {
 		blog.ErrorJSON("ListHostByTopoNode failed, Validate failed,parameter:%s, err: %s, rid:%s", parameter, err, ctx.Kit.Rid)
 		ccErr := defErr.CCErrorf(common.CCErrCommParamsInvalid, key)
@@ -726,6 +771,10 @@ func (s *Service) ListBizHostsTopo(ctx *rest.Contexts) {
 		ctx.RespAutoError(defErr.Error(common.CCErrHostGetFail))
 		return
 	}
+	if ccErr := s.filterListHostRestrictedFields(ctx, hosts); ccErr != nil {
+		ctx.RespAutoError(ccErr)
+		return
+	}
 
 	if len(hosts.Info) == 0 {
 		ctx.RespEntity(hosts)
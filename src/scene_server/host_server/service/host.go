@@ -278,6 +278,11 @@ func (s *Service) GetHostInstanceProperties(ctx *rest.Contexts) {
 		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommAuthorizeFailed))
 		return
 	}
+	if err := s.filterHostRestrictedFields(ctx, details); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
 	attribute, err := s.Logic.GetHostAttributes(ctx.Kit, nil)
 	if err != nil {
 		blog.Errorf("get host attribute fields failed, err: %v,rid:%s", err, ctx.Kit.Rid)
@@ -290,6 +295,10 @@ func (s *Service) GetHostInstanceProperties(ctx *rest.Contexts) {
 		if attr.PropertyID == common.BKChildStr {
 			continue
 		}
+		// the restricted fields that user has no permission to view are removed from the details
+		if _, exist := details[attr.PropertyID]; attr.IsRestricted && !exist {
+			continue
+		}
 		result = append(result, meta.HostInstanceProperties{
 			PropertyID:    attr.PropertyID,
 			PropertyName:  attr.PropertyName,
@@ -347,6 +356,11 @@ func (s *Service) HostSnapInfo(ctx *rest.Contexts) {
 		return
 	}
 
+	if err := s.filterHostRestrictedFields(ctx, snap); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
 	ctx.RespEntity(snap)
 
 }
@@ -391,11 +405,11 @@ func (s *Service) HostSnapInfoBatch(ctx *rest.Contexts) {
 		return
 	}
 
-	ret := make([]map[string]interface{}, 0)
+	ret := make([]mapstr.MapStr, 0)
 	for hostID, snapData := range result.Data {
 		if snapData == "" {
 			blog.Infof("snapData is empty, hostID:%v, rid:%s", hostID, ctx.Kit.Rid)
-			ret = append(ret, map[string]interface{}{"bk_host_id": hostID})
+			ret = append(ret, mapstr.MapStr{"bk_host_id": hostID})
 			continue
 		}
 		var snap map[string]interface{}
@@ -405,7 +419,7 @@ func (s *Service) HostSnapInfoBatch(ctx *rest.Contexts) {
 			ctx.RespAutoError(err)
 			return
 		}
-		snapFields := make(map[string]interface{})
+		snapFields := make(mapstr.MapStr)
 		for _, field := range option.Fields {
 			if _, ok := snap[field]; ok {
 				snapFields[field] = snap[field]
@@ -415,6 +429,11 @@ func (s *Service) HostSnapInfoBatch(ctx *rest.Contexts) {
 		ret = append(ret, snapFields)
 	}
 
+	if err := s.filterHostRestrictedFields(ctx, ret...); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
 	ctx.RespEntity(ret)
 
 }
@@ -578,6 +597,18 @@ func (s *Service) SearchHost(ctx *rest.Contexts) {
 		return
 	}
 
+	if err := meta.ValidateSearchConditionOperators(body.Condition); err != nil {
+		blog.Errorf("search host condition is invalid, err: %v, rid: %s", err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "condition"))
+		return
+	}
+
+	if err := s.checkHostRestrictedFieldsSearch(ctx, hostSearchConditionFields(body.Condition,
+		body.HostPropertyFilter)...); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
 	ctx.SetReadPreference(common.SecondaryPreferredMode)
 	host, err := s.Logic.SearchHost(ctx.Kit, body, false)
 	if err != nil {
@@ -594,6 +625,11 @@ func (s *Service) SearchHost(ctx *rest.Contexts) {
 		return
 	}
 
+	if err := s.filterSearchHostRestrictedFields(ctx, host); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
 	ctx.RespEntity(*host)
 
 }
@@ -606,6 +642,18 @@ func (s *Service) SearchHostWithAsstDetail(ctx *rest.Contexts) {
 		return
 	}
 
+	if err := meta.ValidateSearchConditionOperators(body.Condition); err != nil {
+		blog.Errorf("search host condition is invalid, err: %v, rid: %s", err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "condition"))
+		return
+	}
+
+	if err := s.checkHostRestrictedFieldsSearch(ctx, hostSearchConditionFields(body.Condition,
+		body.HostPropertyFilter)...); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
 	host, err := s.Logic.SearchHost(ctx.Kit, body, true)
 	if err != nil {
 		blog.Errorf("search host failed, err: %v,input:%+v,rid:%s", err, body, ctx.Kit.Rid)
@@ -621,6 +669,11 @@ func (s *Service) SearchHostWithAsstDetail(ctx *rest.Contexts) {
 		return
 	}
 
+	if err := s.filterSearchHostRestrictedFields(ctx, host); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
 	ctx.RespEntity(*host)
 }
 
@@ -714,6 +767,11 @@ func (s *Service) UpdateHostBatch(ctx *rest.Contexts) {
 	data.Remove(common.BKHostIDField)
 	data.Remove(common.BKCloudIDField)
 
+	if err := s.checkHostRestrictedFieldsEdit(ctx, data); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
 	// check authorization
 	hostIDArr := make([]int64, 0)
 	for _, id := range strings.Split(hostIDStr, ",") {
@@ -867,8 +925,15 @@ func (s *Service) UpdateHostPropertyBatch(ctx *rest.Contexts) {
 
 	// check authorization
 	hostIDArr := make([]int64, 0)
+	properties := make([]mapstr.MapStr, 0, len(parameter.Update))
 	for _, update := range parameter.Update {
 		hostIDArr = append(hostIDArr, update.HostID)
+		properties = append(properties, update.Properties)
+	}
+
+	if err := s.checkHostRestrictedFieldsEdit(ctx, properties...); err != nil {
+		ctx.RespAutoError(err)
+		return
 	}
 	// auth: check authorization
 	if err := s.AuthManager.AuthorizeByHostsIDs(ctx.Kit.Ctx, ctx.Kit.Header, authmeta.Update, hostIDArr...); err != nil {
@@ -1319,6 +1384,18 @@ func (s *Service) CloneHostProperty(ctx *rest.Contexts) {
 		return
 	}
 
+	// the restricted fields of the source host are cloned to the destination host as well
+	srcHosts, ccErr := s.Logic.GetHostInfoByConds(ctx.Kit, map[string]interface{}{common.BKHostIDField: srcHostID})
+	if ccErr != nil {
+		blog.Errorf("get source host %d failed, err: %v, rid: %s", srcHostID, ccErr, ctx.Kit.Rid)
+		ctx.RespAutoError(ccErr)
+		return
+	}
+	if err := s.checkHostRestrictedFieldsEdit(ctx, srcHosts...); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
 	txnErr := s.Engine.CoreAPI.CoreService().Txn().AutoRunTxn(ctx.Kit.Ctx, s.EnableTxn, ctx.Kit.Header, func() error {
 		err = s.Logic.CloneHostProperty(ctx.Kit, input.AppID, srcHostID, dstHostID)
 		if nil != err {
@@ -1404,6 +1481,15 @@ func (s *Service) UpdateImportHosts(ctx *rest.Contexts) {
 		return
 	}
 
+	properties := make([]mapstr.MapStr, 0, len(hosts))
+	for _, hostInfo := range hosts {
+		properties = append(properties, hostInfo)
+	}
+	if err := s.checkHostRestrictedFieldsEdit(ctx, properties...); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
 	// audit interface of host audit log.
 	audit := auditlog.NewHostAudit(s.CoreAPI.CoreService())
 	auditContexts := make([]meta.AuditLog, 0)
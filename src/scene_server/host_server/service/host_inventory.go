@@ -0,0 +1,46 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+)
+
+// HostInventory returns the ansible dynamic inventory or the ssh config of the hosts in the businesses
+func (s *Service) HostInventory(ctx *rest.Contexts) {
+	opt := new(metadata.HostInventoryOption)
+	if err := ctx.DecodeInto(opt); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	if rawErr := opt.Validate(); rawErr.ErrCode != 0 {
+		blog.Errorf("host inventory option is invalid, opt: %#v, err: %v, rid: %s", opt, rawErr, ctx.Kit.Rid)
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	if err := s.checkHostRestrictedFieldsSearch(ctx, opt.HostVars...); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	result, err := s.Logic.HostInventory(ctx.Kit, opt)
+	if err != nil {
+		blog.Errorf("generate host inventory failed, opt: %#v, err: %v, rid: %s", opt, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(result)
+}
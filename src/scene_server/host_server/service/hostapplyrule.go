@@ -42,6 +42,11 @@ func (s *Service) CreateHostApplyRule(ctx *rest.Contexts) {
 		return
 	}
 
+	if err := s.checkHostApplyRuleRestrictedFields(ctx, option.AttributeID); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
 	var rule metadata.HostApplyRule
 	txnErr := s.Engine.CoreAPI.CoreService().Txn().AutoRunTxn(ctx.Kit.Ctx, s.EnableTxn, ctx.Kit.Header, func() error {
 		var err error
@@ -85,6 +90,18 @@ func (s *Service) UpdateHostApplyRule(ctx *rest.Contexts) {
 		return
 	}
 
+	originRule, err := s.CoreAPI.CoreService().HostApplyRule().GetHostApplyRule(ctx.Kit.Ctx, ctx.Kit.Header, bizID, ruleID)
+	if err != nil {
+		blog.Errorf("UpdateHostApplyRule failed, get host apply rule %d failed, err: %v, rid: %s", ruleID, err, rid)
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if err := s.checkHostApplyRuleRestrictedFields(ctx, originRule.AttributeID); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
 	var rule metadata.HostApplyRule
 	txnErr := s.Engine.CoreAPI.CoreService().Txn().AutoRunTxn(ctx.Kit.Ctx, s.EnableTxn, ctx.Kit.Header, func() error {
 		var err error
@@ -187,8 +204,8 @@ func (s *Service) ListHostApplyRule(ctx *rest.Contexts) {
 		return
 	}
 
-	if len(option.ModuleIDs) == 0 {
-		blog.Errorf("ListHostApplyRule failed, parameter bk_module_ids empty, rid:%s", err, rid)
+	if len(option.ModuleIDs) == 0 && len(option.Scopes) == 0 {
+		blog.Errorf("ListHostApplyRule failed, parameter bk_module_ids empty, rid:%s", rid)
 		ctx.RespAutoError(ctx.Kit.CCError.Errorf(common.CCErrCommParamsInvalid, "bk_module_ids"))
 		return
 	}
@@ -219,6 +236,15 @@ func (s *Service) BatchCreateOrUpdateHostApplyRule(ctx *rest.Contexts) {
 		return
 	}
 
+	attributeIDs := make([]int64, 0, len(option.Rules))
+	for _, rule := range option.Rules {
+		attributeIDs = append(attributeIDs, rule.AttributeID)
+	}
+	if err := s.checkHostApplyRuleRestrictedFields(ctx, attributeIDs...); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
 	var batchResult metadata.BatchCreateOrUpdateHostApplyRuleResult
 	txnErr := s.Engine.CoreAPI.CoreService().Txn().AutoRunTxn(ctx.Kit.Ctx, s.EnableTxn, ctx.Kit.Header, func() error {
 		var err error
@@ -337,8 +363,16 @@ func (s *Service) generateApplyPlan(ctx *rest.Contexts, bizID int64, planRequest
 		})
 	}
 
+	switch planRequest.ConflictPolicy {
+	case "", metadata.HostApplyConflictPolicyManual, metadata.HostApplyConflictPolicyPriority:
+	default:
+		return planResult, ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "conflict_policy")
+	}
+
+	// rules on the set, custom levels and business of the modules are inherited by the modules
 	ruleOption := metadata.ListHostApplyRuleOption{
-		ModuleIDs: moduleIDs,
+		ModuleIDs:     moduleIDs,
+		WithInherited: true,
 		Page: metadata.BasePage{
 			Limit: common.BKNoLimit,
 		},
@@ -353,16 +387,25 @@ func (s *Service) generateApplyPlan(ctx *rest.Contexts, bizID int64, planRequest
 	if len(planRequest.AdditionalRules) > 0 {
 	OuterLoop:
 		for _, item := range planRequest.AdditionalRules {
+			scope := item.Scope()
 			for index, rule := range rules.Info {
-				if item.ModuleID == rule.ModuleID && item.AttributeID == rule.AttributeID {
+				if scope == rule.Scope() && item.AttributeID == rule.AttributeID {
 					rules.Info[index].PropertyValue = item.PropertyValue
+					rules.Info[index].Priority = item.Priority
 					continue OuterLoop
 				}
 			}
+			moduleID := int64(0)
+			if scope.ObjID == common.BKInnerObjIDModule {
+				moduleID = scope.InstID
+			}
 			rules.Info = append(rules.Info, metadata.HostApplyRule{
 				ID:              0,
 				BizID:           bizID,
-				ModuleID:        item.ModuleID,
+				ModuleID:        moduleID,
+				ObjID:           scope.ObjID,
+				InstID:          scope.InstID,
+				Priority:        item.Priority,
 				AttributeID:     item.AttributeID,
 				PropertyValue:   item.PropertyValue,
 				Creator:         ctx.Kit.User,
@@ -396,6 +439,7 @@ func (s *Service) generateApplyPlan(ctx *rest.Contexts, bizID int64, planRequest
 		Rules:             finalRules,
 		HostModules:       hostModules,
 		ConflictResolvers: planRequest.ConflictResolvers,
+		ConflictPolicy:    planRequest.ConflictPolicy,
 	}
 
 	planResult, ccErr = s.CoreAPI.CoreService().HostApplyRule().GenerateApplyPlan(ctx.Kit.Ctx, ctx.Kit.Header, bizID, planOption)
@@ -430,7 +474,14 @@ func (s *Service) RunHostApplyRule(ctx *rest.Contexts) {
 		ctx.RespAutoError(err)
 		return
 	}
-	
+
+	preview := func() (interface{}, error) {
+		return planResult, nil
+	}
+	if !s.approvalGate().Check(ctx, metadata.ApprovalOperationRunHostApplyPlan, bizID, planRequest, preview) {
+		return
+	}
+
 	txnErr := s.Engine.CoreAPI.CoreService().Txn().AutoRunTxn(ctx.Kit.Ctx, s.EnableTxn, ctx.Kit.Header, func() error {
 		// enable host apply on module
 		moduleUpdateOption := &metadata.UpdateOption{
@@ -456,9 +507,13 @@ func (s *Service) RunHostApplyRule(ctx *rest.Contexts) {
 		// save rules to database
 		rulesOption := make([]metadata.CreateOrUpdateApplyRuleOption, 0)
 		for _, rule := range planResult.Rules {
+			scope := rule.Scope()
 			rulesOption = append(rulesOption, metadata.CreateOrUpdateApplyRuleOption{
 				AttributeID:   rule.AttributeID,
 				ModuleID:      rule.ModuleID,
+				ObjID:         scope.ObjID,
+				InstID:        scope.InstID,
+				Priority:      rule.Priority,
 				PropertyValue: rule.PropertyValue,
 			})
 		}
@@ -637,7 +692,8 @@ func (s *Service) listHostRelatedApplyRule(ctx *rest.Contexts, bizID int64, opti
 	}
 
 	ruleOption := metadata.ListHostApplyRuleOption{
-		ModuleIDs: validModuleIDs,
+		ModuleIDs:     validModuleIDs,
+		WithInherited: true,
 		Page: metadata.BasePage{
 			Limit: common.BKNoLimit,
 		},
@@ -647,13 +703,22 @@ func (s *Service) listHostRelatedApplyRule(ctx *rest.Contexts, bizID int64, opti
 		blog.ErrorJSON("listHostRelatedApplyRule failed, ListHostApplyRule failed, bizID: %s, option: %s, err: %s, rid: %s", bizID, option, ccErr.Error(), rid)
 		return nil, ccErr
 	}
-	// moduleID -> []hostApplyRule
-	moduleRules := make(map[int64][]metadata.HostApplyRule)
+	// topo node -> []hostApplyRule
+	scopeRules := make(map[metadata.HostApplyScope][]metadata.HostApplyRule)
 	for _, item := range ruleResult.Info {
-		if _, exist := moduleRules[item.ModuleID]; exist == false {
-			moduleRules[item.ModuleID] = make([]metadata.HostApplyRule, 0)
+		scope := item.Scope()
+		scopeRules[scope] = append(scopeRules[scope], item)
+	}
+	// moduleID -> []hostApplyRule, including rules inherited from upper topo nodes
+	moduleRules := make(map[int64][]metadata.HostApplyRule)
+	for _, moduleID := range validModuleIDs {
+		path, exist := ruleResult.ModuleScopes[moduleID]
+		if !exist {
+			path = []metadata.HostApplyScope{{ObjID: common.BKInnerObjIDModule, InstID: moduleID}}
+		}
+		for _, scope := range path {
+			moduleRules[moduleID] = append(moduleRules[moduleID], scopeRules[scope]...)
 		}
-		moduleRules[item.ModuleID] = append(moduleRules[item.ModuleID], item)
 	}
 
 	// hostID -> []moduleIDs
@@ -666,10 +731,15 @@ func (s *Service) listHostRelatedApplyRule(ctx *rest.Contexts, bizID int64, opti
 		if exist == false {
 			continue
 		}
+		// rules inherited from the same topo node are returned only once
+		ruleIDs := make(map[int64]bool)
 		for _, moduleID := range moduleIDs {
-			rules, exist := moduleRules[moduleID]
-			if exist == true {
-				result[hostID] = append(result[hostID], rules...)
+			for _, rule := range moduleRules[moduleID] {
+				if ruleIDs[rule.ID] {
+					continue
+				}
+				ruleIDs[rule.ID] = true
+				result[hostID] = append(result[hostID], rule)
 			}
 		}
 	}
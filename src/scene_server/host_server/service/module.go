@@ -103,6 +103,11 @@ func (s *Service) MoveHostToResourcePool(ctx *rest.Contexts) {
 		return
 	}
 
+	if !s.approvalGate().Check(ctx, metadata.ApprovalOperationMoveHostToResourcePool, conf.ApplicationID, conf,
+		s.approvalHostRelationPreview(ctx.Kit, conf.ApplicationID, conf.HostIDs)) {
+		return
+	}
+
 	var exceptionArr []metadata.ExceptionResult
 	txnErr := s.Engine.CoreAPI.CoreService().Txn().AutoRunTxn(ctx.Kit.Ctx, s.EnableTxn, ctx.Kit.Header, func() error {
 		var err error
@@ -193,6 +198,11 @@ func (s *Service) TransferHostAcrossBusiness(ctx *rest.Contexts) {
 		return
 	}
 
+	if !s.approvalGate().Check(ctx, metadata.ApprovalOperationTransferHostAcrossBiz, data.SrcAppID, data,
+		s.approvalHostRelationPreview(ctx.Kit, data.SrcAppID, data.HostID)) {
+		return
+	}
+
 	txnErr := s.Engine.CoreAPI.CoreService().Txn().AutoRunTxn(ctx.Kit.Ctx, s.EnableTxn, ctx.Kit.Header, func() error {
 		err := s.Logic.TransferHostAcrossBusiness(ctx.Kit, data.SrcAppID, data.DstAppID, data.HostID, data.DstModuleID)
 		if err != nil {
@@ -0,0 +1,55 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"strconv"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+)
+
+// PrometheusServiceDiscovery returns the targets of the hosts or the processes in the business in the format of the
+// prometheus http_sd_configs, the options are in the url query since prometheus only sends GET requests, such as:
+// /findmany/hosts/prometheus_sd/biz/2?mode=process&bk_module_ids=10,11&bk_process_names=nginx&labels=bk_os_type
+func (s *Service) PrometheusServiceDiscovery(ctx *rest.Contexts) {
+	bizID, err := strconv.ParseInt(ctx.Request.PathParameter(common.BKAppIDField), 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, common.BKAppIDField))
+		return
+	}
+
+	opt, rawErr := metadata.ParsePrometheusSDOption(bizID, ctx.Request.Request.URL.Query())
+	if rawErr.ErrCode != 0 {
+		blog.Errorf("parse prometheus service discovery option failed, query: %s, err: %v, rid: %s",
+			ctx.Request.Request.URL.RawQuery, rawErr, ctx.Kit.Rid)
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+
+	if err := s.checkHostRestrictedFieldsSearch(ctx, opt.Labels...); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	groups, ccErr := s.Logic.PrometheusSDTargets(ctx.Kit, opt)
+	if ccErr != nil {
+		blog.Errorf("get prometheus service discovery targets failed, opt: %#v, err: %v, rid: %s", opt, ccErr,
+			ctx.Kit.Rid)
+		ctx.RespAutoError(ccErr)
+		return
+	}
+	ctx.RespRawEntity(groups)
+}
@@ -0,0 +1,154 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"strings"
+
+	authmeta "configcenter/src/ac/meta"
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	meta "configcenter/src/common/metadata"
+	"configcenter/src/common/querybuilder"
+	"configcenter/src/common/util"
+)
+
+// filterHostRestrictedFields remove the host restricted fields that user has no permission to view
+func (s *Service) filterHostRestrictedFields(ctx *rest.Contexts, hosts ...mapstr.MapStr) errors.CCErrorCoder {
+	err := s.AuthManager.FilterRestrictedFields(ctx.Kit.Ctx, ctx.Kit.Header, common.BKInnerObjIDHost, hosts...)
+	if err != nil {
+		blog.Errorf("filter host restricted fields failed, err: %v, rid: %s", err, ctx.Kit.Rid)
+		return ctx.Kit.CCError.CCError(common.CCErrCommAuthorizeFailed)
+	}
+	return nil
+}
+
+// filterSearchHostRestrictedFields remove the host restricted fields from the host search result
+func (s *Service) filterSearchHostRestrictedFields(ctx *rest.Contexts, result *meta.SearchHost) errors.CCErrorCoder {
+	hosts := make([]mapstr.MapStr, 0, len(result.Info))
+	for _, item := range result.Info {
+		host, ok := item[common.BKInnerObjIDHost].(mapstr.MapStr)
+		if !ok {
+			continue
+		}
+		hosts = append(hosts, host)
+	}
+	return s.filterHostRestrictedFields(ctx, hosts...)
+}
+
+// filterListHostRestrictedFields remove the host restricted fields from the host list result
+func (s *Service) filterListHostRestrictedFields(ctx *rest.Contexts, result *meta.ListHostResult) errors.CCErrorCoder {
+	hosts := make([]mapstr.MapStr, 0, len(result.Info))
+	for _, host := range result.Info {
+		hosts = append(hosts, host)
+	}
+	return s.filterHostRestrictedFields(ctx, hosts...)
+}
+
+// checkHostRestrictedFieldsEdit check if user has the permission to edit the host restricted fields in data
+func (s *Service) checkHostRestrictedFieldsEdit(ctx *rest.Contexts, data ...mapstr.MapStr) errors.CCErrorCoder {
+	denied, err := s.AuthManager.CheckRestrictedFieldsEdit(ctx.Kit.Ctx, ctx.Kit.Header, common.BKInnerObjIDHost,
+		data...)
+	if err != nil {
+		blog.Errorf("check host restricted fields edit failed, err: %v, rid: %s", err, ctx.Kit.Rid)
+		return ctx.Kit.CCError.CCError(common.CCErrCommAuthorizeFailed)
+	}
+	if len(denied) > 0 {
+		blog.Errorf("no permission to edit host restricted fields %v, rid: %s", denied, ctx.Kit.Rid)
+		return ctx.Kit.CCError.CCErrorf(common.CCErrCommRestrictedFieldNoPermission, strings.Join(denied, ","))
+	}
+	return nil
+}
+
+// checkHostApplyRuleRestrictedFields check if user has the permission to edit the host restricted fields that
+// the host apply rules of the attributes set to the hosts
+func (s *Service) checkHostApplyRuleRestrictedFields(ctx *rest.Contexts, attributeIDs ...int64) errors.CCErrorCoder {
+	if !s.AuthManager.Enabled() || len(attributeIDs) == 0 {
+		return nil
+	}
+
+	query := &meta.QueryCondition{
+		Condition: mapstr.MapStr{
+			common.BKFieldID:    mapstr.MapStr{common.BKDBIN: util.IntArrayUnique(attributeIDs)},
+			common.BKObjIDField: common.BKInnerObjIDHost,
+		},
+		Fields: []string{common.BKPropertyIDField},
+		Page:   meta.BasePage{Limit: common.BKNoLimit},
+	}
+	attrResp, err := s.CoreAPI.CoreService().Model().ReadModelAttrByCondition(ctx.Kit.Ctx, ctx.Kit.Header, query)
+	if err != nil {
+		blog.Errorf("get host apply rule attributes %v failed, err: %v, rid: %s", attributeIDs, err, ctx.Kit.Rid)
+		return ctx.Kit.CCError.CCError(common.CCErrCommHTTPDoRequestFailed)
+	}
+	if err := attrResp.CCError(); err != nil {
+		blog.Errorf("get host apply rule attributes %v failed, err: %v, rid: %s", attributeIDs, err, ctx.Kit.Rid)
+		return err
+	}
+
+	data := mapstr.New()
+	for _, attr := range attrResp.Data.Info {
+		data[attr.PropertyID] = nil
+	}
+	return s.checkHostRestrictedFieldsEdit(ctx, data)
+}
+
+// checkHostRestrictedFieldsSearch check if user has the permission to view the host restricted fields that are used
+// in the search conditions or exported by the request, so that their values can not be probed by searching
+func (s *Service) checkHostRestrictedFieldsSearch(ctx *rest.Contexts, fields ...string) errors.CCErrorCoder {
+	if !s.AuthManager.Enabled() || len(fields) == 0 {
+		return nil
+	}
+
+	noPermission, err := s.AuthManager.GetNoPermissionRestrictedFields(ctx.Kit.Ctx, ctx.Kit.Header,
+		authmeta.ViewRestrictedField, common.BKInnerObjIDHost)
+	if err != nil {
+		blog.Errorf("get no permission host restricted fields failed, err: %v, rid: %s", err, ctx.Kit.Rid)
+		return ctx.Kit.CCError.CCError(common.CCErrCommAuthorizeFailed)
+	}
+
+	fieldMap := make(map[string]struct{}, len(fields))
+	for _, field := range fields {
+		fieldMap[field] = struct{}{}
+	}
+	denied := make([]string, 0)
+	for _, field := range noPermission[common.BKInnerObjIDHost] {
+		if _, exist := fieldMap[field]; exist {
+			denied = append(denied, field)
+		}
+	}
+	if len(denied) > 0 {
+		blog.Errorf("no permission to search by host restricted fields %v, rid: %s", denied, ctx.Kit.Rid)
+		return ctx.Kit.CCError.CCErrorf(common.CCErrCommRestrictedFieldNoPermission, strings.Join(denied, ","))
+	}
+	return nil
+}
+
+// hostSearchConditionFields returns the host fields used in the search conditions and the host property filter
+func hostSearchConditionFields(conds []meta.SearchCondition, filter *querybuilder.QueryFilter) []string {
+	fields := make([]string, 0)
+	for _, cond := range conds {
+		if cond.ObjectID != common.BKInnerObjIDHost {
+			continue
+		}
+		for _, item := range cond.Condition {
+			fields = append(fields, item.Field)
+		}
+	}
+	if filter != nil {
+		fields = append(fields, querybuilder.RuleFields(filter.Rule)...)
+	}
+	return fields
+}
@@ -0,0 +1,56 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"configcenter/src/common"
+	meta "configcenter/src/common/metadata"
+	"configcenter/src/common/querybuilder"
+)
+
+func TestHostSearchConditionFields(t *testing.T) {
+	conds := []meta.SearchCondition{
+		{
+			ObjectID:  common.BKInnerObjIDHost,
+			Condition: []meta.ConditionItem{{Field: "bk_host_innerip"}, {Field: "bk_asset_id"}},
+		},
+		{
+			ObjectID:  common.BKInnerObjIDModule,
+			Condition: []meta.ConditionItem{{Field: common.BKModuleIDField}},
+		},
+	}
+	filter := &querybuilder.QueryFilter{
+		Rule: querybuilder.CombinedRule{
+			Condition: querybuilder.ConditionAnd,
+			Rules: []querybuilder.Rule{
+				querybuilder.AtomRule{Field: "bk_os_type"},
+				querybuilder.CombinedRule{
+					Condition: querybuilder.ConditionOr,
+					Rules:     []querybuilder.Rule{querybuilder.AtomRule{Field: "bk_sn"}},
+				},
+			},
+		},
+	}
+
+	expected := []string{"bk_host_innerip", "bk_asset_id", "bk_os_type", "bk_sn"}
+	if fields := hostSearchConditionFields(conds, filter); !reflect.DeepEqual(fields, expected) {
+		t.Fatalf("expect fields %v, got %v", expected, fields)
+	}
+
+	if fields := hostSearchConditionFields(nil, nil); len(fields) != 0 {
+		t.Fatalf("expect no fields, got %v", fields)
+	}
+}
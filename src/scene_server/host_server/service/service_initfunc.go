@@ -4,6 +4,8 @@ import (
 	"net/http"
 
 	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
 
 	"github.com/emicklei/go-restful"
 )
@@ -31,13 +33,19 @@ func (s *Service) initCloudarea(web *restful.WebService) {
 		Language: s.Engine.Language,
 	})
 
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/cloudarea", Handler: s.FindManyCloudArea})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/createmany/cloudarea", Handler: s.CreatePlatBatch})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/create/cloudarea", Handler: s.CreatePlat})
-	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/cloudarea/{bk_cloud_id}", Handler: s.UpdatePlat})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/cloudarea", Handler: s.FindManyCloudArea,
+		Request: metadata.CloudAreaSearchParam{}, Response: mapstr.MapStr{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/createmany/cloudarea", Handler: s.CreatePlatBatch,
+		Request: metadata.CreateManyCloudAreaOption{}, Response: []metadata.CreateManyCloudAreaElem{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/create/cloudarea", Handler: s.CreatePlat,
+		Request: mapstr.MapStr{}, Response: metadata.CreateOneDataResult{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/cloudarea/{bk_cloud_id}", Handler: s.UpdatePlat,
+		Request: metadata.UpdateCloudAreaOption{}})
 	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/delete/cloudarea/{bk_cloud_id}", Handler: s.DeletePlat})
-	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/updatemany/hosts/cloudarea_field", Handler: s.UpdateHostCloudAreaField})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/cloudarea/hostcount", Handler: s.FindCloudAreaHostCount})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/updatemany/hosts/cloudarea_field", Handler: s.UpdateHostCloudAreaField,
+		Request: metadata.UpdateHostCloudAreaFieldOption{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/cloudarea/hostcount", Handler: s.FindCloudAreaHostCount,
+		Request: metadata.CloudAreaHostCount{}, Response: []metadata.CloudAreaHostCountElem{}})
 
 	utility.AddToRestfulWebService(web)
 
@@ -50,11 +58,15 @@ func (s *Service) initFavourite(web *restful.WebService) {
 		Language: s.Engine.Language,
 	})
 
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/hosts/favorites/search", Handler: s.ListHostFavourites})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/hosts/favorites", Handler: s.AddHostFavourite})
-	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/hosts/favorites/{id}", Handler: s.UpdateHostFavouriteByID})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/hosts/favorites/search", Handler: s.ListHostFavourites,
+		Request: metadata.QueryInput{}, Response: metadata.FavoriteResult{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/hosts/favorites", Handler: s.AddHostFavourite,
+		Request: metadata.FavouriteParms{}, Response: metadata.ID{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/hosts/favorites/{id}", Handler: s.UpdateHostFavouriteByID,
+		Request: mapstr.MapStr{}})
 	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/hosts/favorites/{id}", Handler: s.DeleteHostFavouriteByID})
-	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/hosts/favorites/{id}/incr", Handler: s.IncrHostFavouritesCount})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/hosts/favorites/{id}/incr", Handler: s.IncrHostFavouritesCount,
+		Request: rest.EmptyBody{}, Response: mapstr.MapStr{}})
 
 	utility.AddToRestfulWebService(web)
 
@@ -67,16 +79,30 @@ func (s *Service) initFindhost(web *restful.WebService) {
 		Language: s.Engine.Language,
 	})
 
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/modulehost", Handler: s.FindModuleHost})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/module_relation/bk_biz_id/{bk_biz_id}", Handler: s.FindModuleHostRelation})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/hosts/by_service_templates/biz/{bk_biz_id}", Handler: s.FindHostsByServiceTemplates})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/hosts/by_set_templates/biz/{bk_biz_id}", Handler: s.FindHostsBySetTemplates})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/hosts/list_resource_pool_hosts", Handler: s.ListResourcePoolHosts})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/hosts/app/{appid}/list_hosts", Handler: s.ListBizHosts})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/hosts/list_hosts_without_app", Handler: s.ListHostsWithNoBiz})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/hosts/app/{bk_biz_id}/list_hosts_topo", Handler: s.ListBizHostsTopo})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/host/count_by_topo_node/bk_biz_id/{bk_biz_id}", Handler: s.CountTopoNodeHosts})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/hosts/by_topo/biz/{bk_biz_id}", Handler: s.FindHostsByTopo})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/modulehost", Handler: s.FindModuleHost,
+		Request: metadata.HostModuleFind{}, Response: metadata.SearchHost{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/module_relation/bk_biz_id/{bk_biz_id}", Handler: s.FindModuleHostRelation,
+		Request: metadata.FindModuleHostRelationParameter{}, Response: metadata.FindModuleHostRelationResult{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/hosts/by_service_templates/biz/{bk_biz_id}", Handler: s.FindHostsByServiceTemplates,
+		Request: metadata.FindHostsBySrvTplOpt{}, Response: metadata.SearchHost{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/hosts/by_set_templates/biz/{bk_biz_id}", Handler: s.FindHostsBySetTemplates,
+		Request: metadata.FindHostsBySetTplOpt{}, Response: metadata.SearchHost{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/hosts/list_resource_pool_hosts", Handler: s.ListResourcePoolHosts,
+		Request: metadata.ListHostsParameter{}, Response: metadata.ListHostResult{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/hosts/app/{appid}/list_hosts", Handler: s.ListBizHosts,
+		Request: metadata.ListHostsParameter{}, Response: metadata.ListHostResult{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/hosts/list_hosts_without_app", Handler: s.ListHostsWithNoBiz,
+		Request: metadata.ListHostsWithNoBizParameter{}, Response: metadata.ListHostResult{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/hosts/app/{bk_biz_id}/list_hosts_topo", Handler: s.ListBizHostsTopo,
+		Request: metadata.ListHostsWithNoBizParameter{}, Response: metadata.HostTopoResult{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/host/count_by_topo_node/bk_biz_id/{bk_biz_id}", Handler: s.CountTopoNodeHosts,
+		Request: metadata.CountTopoNodeHostsOption{}, Response: []metadata.TopoNodeHostCount{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/hosts/by_topo/biz/{bk_biz_id}", Handler: s.FindHostsByTopo,
+		Request: metadata.FindHostsByTopoOpt{}, Response: metadata.SearchHost{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodGet, Path: "/findmany/hosts/prometheus_sd/biz/{bk_biz_id}",
+		Handler: s.PrometheusServiceDiscovery})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/hosts/inventory", Handler: s.HostInventory,
+		Request: metadata.HostInventoryOption{}, Response: metadata.HostInventoryResult{}})
 
 	utility.AddToRestfulWebService(web)
 
@@ -90,20 +116,32 @@ func (s *Service) initHost(web *restful.WebService) {
 	})
 
 	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/hosts/batch", Handler: s.DeleteHostBatchFromResourcePool})
-	utility.AddHandler(rest.Action{Verb: http.MethodGet, Path: "/hosts/{bk_supplier_account}/{bk_host_id}", Handler: s.GetHostInstanceProperties})
-	utility.AddHandler(rest.Action{Verb: http.MethodGet, Path: "/hosts/snapshot/{bk_host_id}", Handler: s.HostSnapInfo})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/hosts/snapshot/batch", Handler: s.HostSnapInfoBatch})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/hosts/add", Handler: s.AddHost})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/hosts/add/resource", Handler: s.AddHostToResourcePool})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/hosts/search", Handler: s.SearchHost})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/hosts/search/asstdetail", Handler: s.SearchHostWithAsstDetail})
-	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/hosts/batch", Handler: s.UpdateHostBatch})
-	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/hosts/property/batch", Handler: s.UpdateHostPropertyBatch})
+	utility.AddHandler(rest.Action{Verb: http.MethodGet, Path: "/hosts/{bk_supplier_account}/{bk_host_id}", Handler: s.GetHostInstanceProperties,
+		Response: []metadata.HostInstanceProperties{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodGet, Path: "/hosts/snapshot/{bk_host_id}", Handler: s.HostSnapInfo,
+		Response: mapstr.MapStr{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/hosts/snapshot/batch", Handler: s.HostSnapInfoBatch,
+		Request: metadata.HostSnapBatchOption{}, Response: []mapstr.MapStr{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/hosts/add", Handler: s.AddHost,
+		Request: metadata.HostList{}, Response: mapstr.MapStr{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/hosts/add/resource", Handler: s.AddHostToResourcePool,
+		Request: metadata.AddHostToResourcePoolHostList{}, Response: metadata.AddHostToResourcePoolResult{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/hosts/search", Handler: s.SearchHost,
+		Request: metadata.HostCommonSearch{}, Response: metadata.SearchHost{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/hosts/search/asstdetail", Handler: s.SearchHostWithAsstDetail,
+		Request: metadata.HostCommonSearch{}, Response: metadata.SearchHost{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/hosts/batch", Handler: s.UpdateHostBatch,
+		Request: mapstr.MapStr{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/hosts/property/batch", Handler: s.UpdateHostPropertyBatch,
+		Request: metadata.UpdateHostPropertyBatchParameter{}})
 	// TODO: Deprecated, delete this api, used in framework
 	// utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/hosts/sync/new/host", Handler: s.NewHostSyncAppTopo})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/hosts/modules/idle/set", Handler: s.MoveSetHost2IdleModule})
-	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/hosts/property/clone", Handler: s.CloneHostProperty})
-	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/hosts/update", Handler: s.UpdateImportHosts})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/hosts/modules/idle/set", Handler: s.MoveSetHost2IdleModule,
+		Request: metadata.SetHostConfigParams{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/hosts/property/clone", Handler: s.CloneHostProperty,
+		Request: metadata.CloneHostPropertyParams{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/hosts/update", Handler: s.UpdateImportHosts,
+		Request: metadata.HostList{}, Response: mapstr.MapStr{}})
 
 	utility.AddToRestfulWebService(web)
 
@@ -117,15 +155,23 @@ func (s *Service) initHostapplyrule(web *restful.WebService) {
 	})
 
 	// 主机属性自动应用
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/create/host_apply_rule/bk_biz_id/{bk_biz_id}", Handler: s.CreateHostApplyRule})
-	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/host_apply_rule/{host_apply_rule_id}/bk_biz_id/{bk_biz_id}", Handler: s.UpdateHostApplyRule})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/create/host_apply_rule/bk_biz_id/{bk_biz_id}", Handler: s.CreateHostApplyRule,
+		Request: metadata.CreateHostApplyRuleOption{}, Response: metadata.HostApplyRule{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/host_apply_rule/{host_apply_rule_id}/bk_biz_id/{bk_biz_id}", Handler: s.UpdateHostApplyRule,
+		Request: metadata.UpdateHostApplyRuleOption{}, Response: metadata.HostApplyRule{}})
 	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/deletemany/host_apply_rule/bk_biz_id/{bk_biz_id}", Handler: s.DeleteHostApplyRule})
-	utility.AddHandler(rest.Action{Verb: http.MethodGet, Path: "/find/host_apply_rule/{host_apply_rule_id}/bk_biz_id/{bk_biz_id}/", Handler: s.GetHostApplyRule})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/host_apply_rule/bk_biz_id/{bk_biz_id}", Handler: s.ListHostApplyRule})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/createmany/host_apply_rule/bk_biz_id/{bk_biz_id}/batch_create_or_update", Handler: s.BatchCreateOrUpdateHostApplyRule})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/createmany/host_apply_plan/bk_biz_id/{bk_biz_id}/preview", Handler: s.GenerateApplyPlan})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/updatemany/host_apply_plan/bk_biz_id/{bk_biz_id}/run", Handler: s.RunHostApplyRule})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/host_apply_rule/bk_biz_id/{bk_biz_id}/host_related_rules", Handler: s.ListHostRelatedApplyRule})
+	utility.AddHandler(rest.Action{Verb: http.MethodGet, Path: "/find/host_apply_rule/{host_apply_rule_id}/bk_biz_id/{bk_biz_id}/", Handler: s.GetHostApplyRule,
+		Response: metadata.HostApplyRule{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/host_apply_rule/bk_biz_id/{bk_biz_id}", Handler: s.ListHostApplyRule,
+		Request: metadata.ListHostApplyRuleOption{}, Response: metadata.MultipleHostApplyRuleResult{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/createmany/host_apply_rule/bk_biz_id/{bk_biz_id}/batch_create_or_update", Handler: s.BatchCreateOrUpdateHostApplyRule,
+		Request: metadata.BatchCreateOrUpdateApplyRuleOption{}, Response: metadata.BatchCreateOrUpdateHostApplyRuleResult{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/createmany/host_apply_plan/bk_biz_id/{bk_biz_id}/preview", Handler: s.GenerateApplyPlan,
+		Request: metadata.HostApplyPlanRequest{}, Response: metadata.HostApplyPlanResult{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/updatemany/host_apply_plan/bk_biz_id/{bk_biz_id}/run", Handler: s.RunHostApplyRule,
+		Request: metadata.HostApplyPlanRequest{}, Response: []metadata.HostApplyResult{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/host_apply_rule/bk_biz_id/{bk_biz_id}/host_related_rules", Handler: s.ListHostRelatedApplyRule,
+		Request: metadata.ListHostRelatedApplyRuleOption{}, Response: map[int64][]metadata.HostApplyRule{}})
 
 	utility.AddToRestfulWebService(web)
 }
@@ -137,9 +183,11 @@ func (s *Service) initHostlock(web *restful.WebService) {
 		Language: s.Engine.Language,
 	})
 
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/host/lock", Handler: s.LockHost})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/host/lock", Handler: s.LockHost,
+		Request: metadata.HostLockRequest{}})
 	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/host/lock", Handler: s.UnlockHost})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/host/lock/search", Handler: s.QueryHostLock})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/host/lock/search", Handler: s.QueryHostLock,
+		Request: metadata.QueryHostLockRequest{}, Response: map[int64]bool{}})
 
 	utility.AddToRestfulWebService(web)
 
@@ -152,21 +200,31 @@ func (s *Service) initModule(web *restful.WebService) {
 		Language: s.Engine.Language,
 	})
 
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/hosts/modules", Handler: s.TransferHostModule})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/hosts/modules/idle", Handler: s.MoveHost2IdleModule})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/hosts/modules/fault", Handler: s.MoveHost2FaultModule})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/hosts/modules/recycle", Handler: s.MoveHost2RecycleModule})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/hosts/modules/resource", Handler: s.MoveHostToResourcePool})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/hosts/modules/resource/idle", Handler: s.AssignHostToApp})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/hosts/modules", Handler: s.TransferHostModule,
+		Request: metadata.HostsModuleRelation{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/hosts/modules/idle", Handler: s.MoveHost2IdleModule,
+		Request: metadata.DefaultModuleHostConfigParams{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/hosts/modules/fault", Handler: s.MoveHost2FaultModule,
+		Request: metadata.DefaultModuleHostConfigParams{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/hosts/modules/recycle", Handler: s.MoveHost2RecycleModule,
+		Request: metadata.DefaultModuleHostConfigParams{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/hosts/modules/resource", Handler: s.MoveHostToResourcePool,
+		Request: metadata.DefaultModuleHostConfigParams{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/hosts/modules/resource/idle", Handler: s.AssignHostToApp,
+		Request: metadata.DefaultModuleHostConfigParams{}})
 	// get host module relation in app
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/hosts/modules/read", Handler: s.GetHostModuleRelation})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/hosts/modules/read", Handler: s.GetHostModuleRelation,
+		Request: metadata.HostModuleRelationParameter{}, Response: []metadata.ModuleHost{}})
 	// transfer host to other business
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/hosts/modules/across/biz", Handler: s.TransferHostAcrossBusiness})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/hosts/modules/across/biz", Handler: s.TransferHostAcrossBusiness,
+		Request: metadata.TransferHostAcrossBusinessParameter{}})
 	// TODO: Deprecated, delete this api. delete host from business, used for framework
 	//utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/hosts/module/biz/delete", Handler: s.DeleteHostFromBusiness})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/host/topo/relation/read", Handler: s.GetAppHostTopoRelation})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/host/topo/relation/read", Handler: s.GetAppHostTopoRelation,
+		Request: metadata.HostModuleRelationRequest{}, Response: metadata.HostConfigData{}})
 	// 主机在资源池目录之间转移
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/host/transfer/resource/directory", Handler: s.TransferHostResourceDirectory})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/host/transfer/resource/directory", Handler: s.TransferHostResourceDirectory,
+		Request: metadata.TransferHostResourceDirectory{}})
 
 	utility.AddToRestfulWebService(web)
 
@@ -179,8 +237,10 @@ func (s *Service) initSpecial(web *restful.WebService) {
 		Language: s.Engine.Language,
 	})
 
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/host/install/bk", Handler: s.BKSystemInstall})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/system/config/user_config/blueking_modify", Handler: s.FindSystemUserConfigBKSwitch})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/host/install/bk", Handler: s.BKSystemInstall,
+		Request: metadata.BkSystemInstallRequest{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/system/config/user_config/blueking_modify", Handler: s.FindSystemUserConfigBKSwitch,
+		Request: rest.EmptyBody{}})
 
 	utility.AddToRestfulWebService(web)
 
@@ -193,8 +253,10 @@ func (s *Service) initTransfer(web *restful.WebService) {
 		Language: s.Engine.Language,
 	})
 
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/host/transfer_with_auto_clear_service_instance/bk_biz_id/{bk_biz_id}/", Handler: s.TransferHostWithAutoClearServiceInstance})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/host/transfer_with_auto_clear_service_instance/bk_biz_id/{bk_biz_id}/preview/", Handler: s.TransferHostWithAutoClearServiceInstancePreview})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/host/transfer_with_auto_clear_service_instance/bk_biz_id/{bk_biz_id}/", Handler: s.TransferHostWithAutoClearServiceInstance,
+		Request: metadata.TransferHostWithAutoClearServiceInstanceOption{}, Response: []metadata.HostTransferResult{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/host/transfer_with_auto_clear_service_instance/bk_biz_id/{bk_biz_id}/preview/", Handler: s.TransferHostWithAutoClearServiceInstancePreview,
+		Request: metadata.TransferHostWithAutoClearServiceInstanceOption{}, Response: []metadata.HostTransferPreview{}})
 
 	utility.AddToRestfulWebService(web)
 }
@@ -208,9 +270,11 @@ func (s *Service) initDynamicGroup(web *restful.WebService) {
 
 	// create new dynamic group.
 	utility.AddHandler(rest.Action{
-		Verb:    http.MethodPost,
-		Path:    "/dynamicgroup",
-		Handler: s.CreateDynamicGroup,
+		Verb:     http.MethodPost,
+		Path:     "/dynamicgroup",
+		Handler:  s.CreateDynamicGroup,
+		Request:  metadata.DynamicGroup{},
+		Response: metadata.ID{},
 	})
 
 	// update dynamic group.
@@ -218,13 +282,15 @@ func (s *Service) initDynamicGroup(web *restful.WebService) {
 		Verb:    http.MethodPut,
 		Path:    "/dynamicgroup/{bk_biz_id}/{id}",
 		Handler: s.UpdateDynamicGroup,
+		Request: mapstr.MapStr{},
 	})
 
 	// query target dynamic group.
 	utility.AddHandler(rest.Action{
-		Verb:    http.MethodGet,
-		Path:    "/dynamicgroup/{bk_biz_id}/{id}",
-		Handler: s.GetDynamicGroup,
+		Verb:     http.MethodGet,
+		Path:     "/dynamicgroup/{bk_biz_id}/{id}",
+		Handler:  s.GetDynamicGroup,
+		Response: metadata.DynamicGroup{},
 	})
 
 	// delete target dynamic group.
@@ -236,16 +302,20 @@ func (s *Service) initDynamicGroup(web *restful.WebService) {
 
 	// search(list) dynamic groups.
 	utility.AddHandler(rest.Action{
-		Verb:    http.MethodPost,
-		Path:    "/dynamicgroup/search/{bk_biz_id}",
-		Handler: s.SearchDynamicGroup,
+		Verb:     http.MethodPost,
+		Path:     "/dynamicgroup/search/{bk_biz_id}",
+		Handler:  s.SearchDynamicGroup,
+		Request:  metadata.QueryCondition{},
+		Response: metadata.DynamicGroupBatch{},
 	})
 
 	// execute dynamic group and get target resources.
 	utility.AddHandler(rest.Action{
-		Verb:    http.MethodPost,
-		Path:    "/dynamicgroup/data/{bk_biz_id}/{id}",
-		Handler: s.ExecuteDynamicGroup,
+		Verb:     http.MethodPost,
+		Path:     "/dynamicgroup/data/{bk_biz_id}/{id}",
+		Handler:  s.ExecuteDynamicGroup,
+		Request:  metadata.QueryCondition{},
+		Response: metadata.InstDataInfo{},
 	})
 
 	utility.AddToRestfulWebService(web)
@@ -258,10 +328,14 @@ func (s *Service) initUsercustom(web *restful.WebService) {
 		Language: s.Engine.Language,
 	})
 
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/usercustom", Handler: s.SaveUserCustom})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/usercustom/user/search", Handler: s.GetUserCustom})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/usercustom/default/model", Handler: s.GetModelDefaultCustom})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/usercustom/default/model/{obj_id}", Handler: s.SaveModelDefaultCustom})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/usercustom", Handler: s.SaveUserCustom,
+		Request: mapstr.MapStr{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/usercustom/user/search", Handler: s.GetUserCustom,
+		Request: rest.EmptyBody{}, Response: mapstr.MapStr{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/usercustom/default/model", Handler: s.GetModelDefaultCustom,
+		Request: rest.EmptyBody{}, Response: mapstr.MapStr{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/usercustom/default/model/{obj_id}", Handler: s.SaveModelDefaultCustom,
+		Request: mapstr.MapStr{}})
 
 	utility.AddToRestfulWebService(web)
 
@@ -68,7 +68,7 @@ func (s *Service) TransferHostWithAutoClearServiceInstance(ctx *rest.Contexts) {
 	bizIDStr := ctx.Request.PathParameter(common.BKAppIDField)
 	bizID, err := strconv.ParseInt(bizIDStr, 10, 64)
 	if err != nil {
-		blog.V(7).Infof("parse bizID from url failed, bizID: %s, err: %+v, rid: %s", bizIDStr, ctx.Kit.Rid)
+		blog.V(7).Infof("parse bizID from url failed, bizID: %s, err: %+v, rid: %s", bizIDStr, err, ctx.Kit.Rid)
 		err := ctx.Kit.CCError.Errorf(common.CCErrCommParamsNeedInt, common.BKAppIDField)
 		ctx.RespAutoError(err)
 		return
@@ -93,12 +93,14 @@ func (s *Service) TransferHostWithAutoClearServiceInstance(ctx *rest.Contexts) {
 		return
 	}
 
-	type HostTransferResult struct {
-		HostID  int64  `json:"bk_host_id"`
-		Code    int    `json:"code"`
-		Message string `json:"message"`
+	preview := func() (interface{}, error) {
+		return transferPlans, nil
+	}
+	if !s.approvalGate().Check(ctx, metadata.ApprovalOperationTransferHostWithAutoClear, bizID, option, preview) {
+		return
 	}
-	transferResult := make([]HostTransferResult, 0)
+
+	transferResult := make([]metadata.HostTransferResult, 0)
 	txnErr := s.Engine.CoreAPI.CoreService().Txn().AutoRunTxn(ctx.Kit.Ctx, s.EnableTxn, ctx.Kit.Header, func() error {
 
 		// get service instance modules
@@ -592,7 +594,8 @@ func (s *Service) generateTransferPlans(ctx *rest.Contexts, bizID int64, withHos
 		finalModuleIDs = append(finalModuleIDs, item.FinalModules...)
 	}
 	ruleOption := metadata.ListHostApplyRuleOption{
-		ModuleIDs: finalModuleIDs,
+		ModuleIDs:     finalModuleIDs,
+		WithInherited: true,
 		Page: metadata.BasePage{
 			Limit: common.BKNoLimit,
 		},
@@ -867,7 +870,7 @@ func (s *Service) TransferHostWithAutoClearServiceInstancePreview(ctx *rest.Cont
 	bizIDStr := ctx.Request.PathParameter(common.BKAppIDField)
 	bizID, err := strconv.ParseInt(bizIDStr, 10, 64)
 	if err != nil {
-		blog.V(7).Infof("parse bizID from url failed, bizID: %s, err: %+v, rid: %s", bizIDStr, ctx.Kit.Rid)
+		blog.V(7).Infof("parse bizID from url failed, bizID: %s, err: %+v, rid: %s", bizIDStr, err, ctx.Kit.Rid)
 		err := ctx.Kit.CCError.Errorf(common.CCErrCommParamsNeedInt, common.BKAppIDField)
 		ctx.RespAutoError(err)
 		return
@@ -360,7 +360,8 @@ func (ps *ProcServer) createServiceInstancesPreview(ctx *rest.Contexts, input me
 
 	// generate host apply plans
 	ruleOption := metadata.ListHostApplyRuleOption{
-		ModuleIDs: append(preModuleIDs, input.ModuleID),
+		ModuleIDs:     append(preModuleIDs, input.ModuleID),
+		WithInherited: true,
 		Page: metadata.BasePage{
 			Limit: common.BKNoLimit,
 		},
@@ -1672,7 +1673,8 @@ func (ps *ProcServer) DeleteServiceInstancePreview(ctx *rest.Contexts) {
 		finalModules = append(finalModules, item.ModuleIDs...)
 	}
 	listRuleOption := metadata.ListHostApplyRuleOption{
-		ModuleIDs: finalModules,
+		ModuleIDs:     finalModules,
+		WithInherited: true,
 		Page: metadata.BasePage{
 			Limit: common.BKNoLimit,
 		},
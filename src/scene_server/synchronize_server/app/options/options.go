@@ -108,4 +108,9 @@ type ConfigItem struct {
 
 	// EnableInstFilter  是否开启实例数据根据同步身份过滤
 	EnableInstFilter bool
+
+	// Incremental 是否开启增量同步，开启后通过监听数据源cmdb的资源事件同步实例和主机模块关系的变更，
+	// 监听游标保存在redis中，游标不存在或过期时先进行一次全量同步。开启后定时同步不再全量同步，只同步没有监听游标的
+	// 模型和云区域，且不清理数据。实例关联和进程实例关系不在同步范围内，全量同步和增量同步均不会同步
+	Incremental bool
 }
@@ -28,6 +28,7 @@ import (
 	//"configcenter/src/storage/dal/redis"
 	synchronizeClient "configcenter/src/apimachinery/synchronize"
 	synchronizeUtil "configcenter/src/apimachinery/synchronize/util"
+	"configcenter/src/storage/dal/redis"
 )
 
 func Run(ctx context.Context, cancel context.CancelFunc, op *options.ServerOption) error {
@@ -64,6 +65,22 @@ func Run(ctx context.Context, cancel context.CancelFunc, op *options.ServerOptio
 	}
 	service.Engine = engine
 	service.Config = synchronSrv.Config
+	// incremental synchronize save the watch cursors in redis
+	for _, item := range synchronSrv.Config.ConifgItemArray {
+		if !item.Incremental {
+			continue
+		}
+		redisConf, err := engine.WithRedis()
+		if err != nil {
+			return fmt.Errorf("get redis config failed, err: %v", err)
+		}
+		cache, err := redis.NewFromConfig(redisConf)
+		if err != nil {
+			return fmt.Errorf("connect redis failed, err: %v", err)
+		}
+		service.CacheDB = cache
+		break
+	}
 	synchronSrv.Service = service
 	synchronizeClientInst, err := synchronizeClient.NewSynchronize(engine.ApiMachineryConfig(), synchronSrv.synchronizeClientConfig)
 	if err != nil {
@@ -115,6 +132,7 @@ func (s *SynchronizeServer) onSynchronizeServerConfigUpdate(previous, current cc
 		objectIDs, _ := cc.String("synchronizeServer." + name + ".ObjectID")
 		ignoreModelAttr, _ := cc.String("synchronizeServer." + name + ".IgnoreModelAttribute")
 		strEnableInstFilter, _ := cc.String("synchronizeServer." + name + ".EnableInstFilter")
+		strIncremental, _ := cc.String("synchronizeServer." + name + ".Incremental")
 
 		configItem.AppNames = SplitFilter(appNames, ",")
 		if syncResource == "1" {
@@ -139,6 +157,9 @@ func (s *SynchronizeServer) onSynchronizeServerConfigUpdate(previous, current cc
 		if strEnableInstFilter == "1" {
 			configItem.EnableInstFilter = true
 		}
+		if strIncremental == "1" {
+			configItem.Incremental = true
+		}
 
 		configInfo.ConifgItemArray = append(configInfo.ConifgItemArray, configItem)
 		if targetHost != "" {
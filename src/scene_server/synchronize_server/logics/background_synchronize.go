@@ -35,6 +35,7 @@ type synchronizeItemInterface interface {
 	synchronizeInstanceTask(ctx context.Context) (errorInfoArr []metadata.ExceptionResult, err errors.CCError)
 	synchronizeModelTask(ctx context.Context) ([]metadata.ExceptionResult, errors.CCError)
 	synchronizeAssociationTask(ctx context.Context) ([]metadata.ExceptionResult, errors.CCError)
+	synchronizeUnwatchedInstanceTask(ctx context.Context) ([]metadata.ExceptionResult, errors.CCError)
 	synchronizeItemClearData(ctx context.Context) (map[string][]metadata.ExceptionResult, errors.CCError)
 }
 
@@ -168,6 +169,26 @@ func (s *synchronizeItem) synchronizeInstanceTask(ctx context.Context) (errorInf
 	return
 }
 
+// synchronizeUnwatchedInstanceTask synchronize the instances which have no watch cursor, they are not synchronized
+// by the incremental synchronize, must be called after synchronizeModelTask.
+func (s *synchronizeItem) synchronizeUnwatchedInstanceTask(ctx context.Context) ([]metadata.ExceptionResult, errors.CCError) {
+	if _, ok := s.objIDMap[common.BKInnerObjIDPlat]; !ok {
+		return nil, nil
+	}
+
+	inst := s.lgc.NewFetchInst(s.config, s.baseCondition)
+	if err := inst.Pretreatment(); err != nil {
+		blog.Errorf("instance Pretreatment error. err:%s, rid:%s", err.Error(), s.lgc.rid)
+		return nil, err
+	}
+	errorInfoArr, err := s.synchronizeInstance(ctx, common.BKInnerObjIDPlat, inst)
+	if err != nil {
+		blog.Errorf("synchronizeUnwatchedInstanceTask synchronize %s error,err:%s,rid:%s", common.BKInnerObjIDPlat, err.Error(), s.lgc.rid)
+		return nil, s.lgc.ccErr.Error(common.CCErrCommHTTPDoRequestFailed)
+	}
+	return errorInfoArr, nil
+}
+
 func (s *synchronizeItem) synchronizeInstance(ctx context.Context, objID string, inst *FetchInst) ([]metadata.ExceptionResult, error) {
 	var start int64 = 0
 	limit := int64(defaultLimit)
@@ -0,0 +1,746 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package logics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/metrics"
+	"configcenter/src/common/watch"
+	"configcenter/src/scene_server/synchronize_server/app/options"
+	"configcenter/src/scene_server/synchronize_server/logics/exception/file"
+	"configcenter/src/storage/dal/redis"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// incrementalCursorKeyPrefix is the redis key prefix of the watch cursors, the key is prefix + name:resource
+	incrementalCursorKeyPrefix = common.BKCacheKeyV3Prefix + "synchronize:incremental:cursor:"
+
+	incrementalRetryInterval       = 5 * time.Second
+	incrementalMasterCheckInterval = 10 * time.Second
+)
+
+// errWatchCursorExpired the watch cursor is expired in the source cmdb, need full synchronize
+var errWatchCursorExpired = errors.New("watch cursor expired")
+
+// incrementalResources are the watched resources of incremental synchronize, models and cloud areas have no watch
+// cursor, they are synchronized by the timed synchronize.
+var incrementalResources = []watch.CursorType{
+	watch.Biz,
+	watch.Set,
+	watch.Module,
+	watch.Host,
+	watch.ModuleHostRelation,
+	watch.Process,
+	watch.ObjectBase,
+}
+
+// incrementalWatchFields only the id is watched, the latest instance is fetched from the source cmdb,
+// the host relation has no id, the relation itself is synchronized.
+var incrementalWatchFields = map[watch.CursorType][]string{
+	watch.Biz:                {common.BKAppIDField},
+	watch.Set:                {common.BKSetIDField},
+	watch.Module:             {common.BKModuleIDField},
+	watch.Host:               {common.BKHostIDField},
+	watch.Process:            {common.BKProcessIDField},
+	watch.ObjectBase:         {common.BKInstIDField, common.BKObjIDField},
+	watch.ModuleHostRelation: {common.BKAppIDField, common.BKSetIDField, common.BKModuleIDField, common.BKHostIDField, common.BKOwnerIDField},
+}
+
+// incrementalSource is the synchronize source cmdb
+type incrementalSource interface {
+	// Watch watch the resource events, returns errWatchCursorExpired when the cursor is expired
+	Watch(ctx context.Context, opts *watch.WatchEventOptions) (*metadata.WatchEventResult, error)
+	// FetchByID fetch the instances which match the synchronize condition
+	FetchByID(ctx context.Context, objID string, appIDArr []int64, instIDArr []int64) ([]mapstr.MapStr, error)
+	// FetchAppID fetch the id of the businesses to synchronize
+	FetchAppID(ctx context.Context) ([]int64, error)
+}
+
+// incrementalTarget is current cmdb which the changes are applied to
+type incrementalTarget interface {
+	Synchronize(ctx context.Context, input *metadata.SynchronizeParameter) ([]metadata.ExceptionResult, error)
+}
+
+// cursorStore persists the watch cursors
+type cursorStore interface {
+	GetCursor(ctx context.Context, key string) (string, error)
+	SetCursor(ctx context.Context, key string, cursor string) error
+}
+
+// incrementalSynchronize synchronize the changes of a config item by watching the events of the source cmdb
+type incrementalSynchronize struct {
+	config  *options.ConfigItem
+	source  incrementalSource
+	target  incrementalTarget
+	store   cursorStore
+	metrics *incrementalMetrics
+
+	// fullSync synchronize all data of the config item
+	fullSync  func(ctx context.Context)
+	isMaster  func() bool
+	exception func(ctx context.Context, resource watch.CursorType, exceptions []metadata.ExceptionResult)
+	now       func() time.Time
+
+	appIDLock sync.RWMutex
+	appIDArr  []int64
+
+	// lastResync is the start time of last full synchronize, full synchronize requested before it is skipped,
+	// resyncLock is shared with the timed synchronize of the config item, so they do not run at the same time.
+	resyncLock *sync.Mutex
+	lastResync time.Time
+}
+
+var (
+	resyncLocksLock sync.Mutex
+	// resyncLocks are the synchronize locks of the incremental config items, the key is the config item name
+	resyncLocks = make(map[string]*sync.Mutex)
+)
+
+// getResyncLock returns the lock of the config item, the full synchronize and the timed synchronize of the
+// incremental config item are serialized by it.
+func getResyncLock(name string) *sync.Mutex {
+	resyncLocksLock.Lock()
+	defer resyncLocksLock.Unlock()
+
+	lock, exists := resyncLocks[name]
+	if !exists {
+		lock = new(sync.Mutex)
+		resyncLocks[name] = lock
+	}
+	return lock
+}
+
+// resourceWatchState is the watch state of a resource
+type resourceWatchState struct {
+	loaded bool
+	cursor string
+	// startFrom is used when there is no cursor after full synchronize
+	startFrom int64
+}
+
+// TriggerIncrementalSynchronize start incremental synchronize of the config items enabled incremental mode
+func (lgc *Logics) TriggerIncrementalSynchronize(ctx context.Context, config *options.Config) {
+	if config == nil {
+		return
+	}
+	lgc = lgc.NewFromHeader(copyHeader(lgc.header))
+	for _, item := range config.ConifgItemArray {
+		if !item.Incremental {
+			continue
+		}
+		if lgc.cache == nil {
+			blog.Errorf("incremental synchronize %s, but redis is not configured, rid: %s", item.Name, lgc.rid)
+			continue
+		}
+		blog.Infof("start incremental synchronize %s, rid: %s", item.Name, lgc.rid)
+		lgc.newIncrementalSynchronize(item).run(ctx)
+	}
+}
+
+func (lgc *Logics) newIncrementalSynchronize(config *options.ConfigItem) *incrementalSynchronize {
+	return &incrementalSynchronize{
+		config:  config,
+		source:  &syncSource{lgc: lgc, config: config},
+		target:  &syncTarget{lgc: lgc},
+		store:   &redisCursorStore{cache: lgc.cache},
+		metrics: getIncrementalMetrics(),
+		fullSync: func(ctx context.Context) {
+			lgc.SynchronizeItem(ctx, config)
+		},
+		isMaster:   lgc.Engine.ServiceManageInterface.IsMaster,
+		exception:  writeIncrementalException(config),
+		now:        time.Now,
+		resyncLock: getResyncLock(config.Name),
+	}
+}
+
+func (s *incrementalSynchronize) run(ctx context.Context) {
+	for _, resource := range incrementalResources {
+		go s.watchResource(ctx, resource)
+	}
+}
+
+func (s *incrementalSynchronize) watchResource(ctx context.Context, resource watch.CursorType) {
+	state := new(resourceWatchState)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		if !s.isMaster() {
+			// reload the cursor when become master again, it may be changed by other node
+			state.loaded = false
+			time.Sleep(incrementalMasterCheckInterval)
+			continue
+		}
+
+		if err := s.watchOnce(ctx, resource, state); err != nil {
+			blog.Errorf("incremental synchronize %s resource %s failed, err: %v", s.config.Name, resource, err)
+			s.metrics.errors.WithLabelValues(s.config.Name, string(resource)).Inc()
+			time.Sleep(incrementalRetryInterval)
+		}
+	}
+}
+
+// watchOnce watch a round of events of the resource and apply them to current cmdb, the cursor is saved
+// after the events are applied, so the events will be watched again if failed.
+func (s *incrementalSynchronize) watchOnce(ctx context.Context, resource watch.CursorType,
+	state *resourceWatchState) error {
+
+	key := incrementalCursorKeyPrefix + s.config.Name + ":" + string(resource)
+	if !state.loaded {
+		cursor, err := s.store.GetCursor(ctx, key)
+		if err != nil {
+			return fmt.Errorf("get cursor failed, err: %v", err)
+		}
+		state.cursor = cursor
+		if len(cursor) == 0 {
+			// never synchronized incrementally, synchronize all data and watch from the start of it
+			state.startFrom = s.resync(ctx, time.Time{}).Unix()
+		}
+		state.loaded = true
+	}
+
+	opts := &watch.WatchEventOptions{
+		Resource: resource,
+		Fields:   incrementalWatchFields[resource],
+		Cursor:   state.cursor,
+	}
+	if len(state.cursor) == 0 {
+		opts.StartFrom = state.startFrom
+	}
+
+	result, err := s.source.Watch(ctx, opts)
+	if err == errWatchCursorExpired {
+		blog.Warnf("incremental synchronize %s resource %s cursor %s expired, synchronize all data",
+			s.config.Name, resource, state.cursor)
+		state.cursor = ""
+		state.startFrom = s.resync(ctx, s.now()).Unix()
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("watch failed, err: %v", err)
+	}
+
+	if len(result.Events) == 0 {
+		return nil
+	}
+	last := result.Events[len(result.Events)-1]
+
+	if !result.Watched {
+		// no events, all the changes are synchronized
+		s.metrics.lag.WithLabelValues(s.config.Name, string(resource)).Set(0)
+		if len(last.Cursor) != 0 && last.Cursor != watch.NoEventCursor && last.Cursor != state.cursor {
+			return s.saveCursor(ctx, key, state, last.Cursor)
+		}
+		return nil
+	}
+
+	if err := s.apply(ctx, resource, result.Events); err != nil {
+		return err
+	}
+
+	cursor := new(watch.Cursor)
+	if err := cursor.Decode(last.Cursor); err == nil {
+		eventTime := time.Unix(int64(cursor.ClusterTime.Sec), int64(cursor.ClusterTime.Nano))
+		s.metrics.lastEventTime.WithLabelValues(s.config.Name, string(resource)).Set(float64(eventTime.Unix()))
+		s.metrics.lag.WithLabelValues(s.config.Name, string(resource)).Set(s.now().Sub(eventTime).Seconds())
+	}
+	return s.saveCursor(ctx, key, state, last.Cursor)
+}
+
+func (s *incrementalSynchronize) saveCursor(ctx context.Context, key string, state *resourceWatchState,
+	cursor string) error {
+
+	if err := s.store.SetCursor(ctx, key, cursor); err != nil {
+		return fmt.Errorf("save cursor failed, err: %v", err)
+	}
+	state.cursor = cursor
+	return nil
+}
+
+// resync synchronize all data if no full synchronize started after requestedAt, returns the start time of it.
+func (s *incrementalSynchronize) resync(ctx context.Context, requestedAt time.Time) time.Time {
+	s.resyncLock.Lock()
+	defer s.resyncLock.Unlock()
+
+	if !s.lastResync.IsZero() && !s.lastResync.Before(requestedAt) {
+		return s.lastResync
+	}
+
+	start := s.now()
+	s.fullSync(ctx)
+	s.lastResync = start
+	s.metrics.resync.WithLabelValues(s.config.Name).Inc()
+	s.refreshAppID(ctx)
+	return start
+}
+
+func (s *incrementalSynchronize) refreshAppID(ctx context.Context) {
+	appIDArr, err := s.source.FetchAppID(ctx)
+	if err != nil {
+		blog.Errorf("incremental synchronize %s fetch business failed, err: %v", s.config.Name, err)
+		return
+	}
+	s.appIDLock.Lock()
+	s.appIDArr = appIDArr
+	s.appIDLock.Unlock()
+}
+
+func (s *incrementalSynchronize) getAppID() []int64 {
+	s.appIDLock.RLock()
+	defer s.appIDLock.RUnlock()
+	return s.appIDArr
+}
+
+// objectSynchronized checks whether the instances of the object are synchronized by the config
+func (s *incrementalSynchronize) objectSynchronized(objID string) bool {
+	if len(s.config.ObjectIDArr) == 0 {
+		return true
+	}
+	for _, id := range s.config.ObjectIDArr {
+		if id == objID {
+			return s.config.WhiteList
+		}
+	}
+	return !s.config.WhiteList
+}
+
+func (s *incrementalSynchronize) apply(ctx context.Context, resource watch.CursorType,
+	events []*metadata.WatchEventItem) error {
+
+	if resource == watch.ModuleHostRelation {
+		return s.applyHostRelation(ctx, events)
+	}
+	return s.applyInstance(ctx, resource, events)
+}
+
+// instanceChanges are the changed instances of an object in a round of events
+type instanceChanges struct {
+	objID   string
+	upsert  []int64
+	deleted []int64
+}
+
+func removeID(ids []int64, id int64) []int64 {
+	for idx := range ids {
+		if ids[idx] == id {
+			return append(ids[:idx], ids[idx+1:]...)
+		}
+	}
+	return ids
+}
+
+// applyInstance the created and updated instances are fetched from the source cmdb with the synchronize condition,
+// so the latest data is synchronized, and the instances not match the condition any more are deleted.
+func (s *incrementalSynchronize) applyInstance(ctx context.Context, resource watch.CursorType,
+	events []*metadata.WatchEventItem) error {
+
+	changesMap := make(map[string]*instanceChanges)
+	objIDs := make([]string, 0)
+	for _, event := range events {
+		if event.Detail == nil {
+			continue
+		}
+
+		objID := string(resource)
+		if resource == watch.ObjectBase {
+			objID, _ = event.Detail.String(common.BKObjIDField)
+		}
+		if len(objID) == 0 || !s.objectSynchronized(objID) {
+			continue
+		}
+
+		id, err := event.Detail.Int64(common.GetInstIDField(objID))
+		if err != nil {
+			blog.Errorf("incremental synchronize %s, get id of %s event failed, detail: %v, err: %v",
+				s.config.Name, objID, event.Detail, err)
+			continue
+		}
+		s.metrics.events.WithLabelValues(s.config.Name, string(resource), string(event.EventType)).Inc()
+
+		changes, exists := changesMap[objID]
+		if !exists {
+			changes = &instanceChanges{objID: objID}
+			changesMap[objID] = changes
+			objIDs = append(objIDs, objID)
+		}
+		changes.upsert = removeID(changes.upsert, id)
+		changes.deleted = removeID(changes.deleted, id)
+		if event.EventType == watch.Delete {
+			changes.deleted = append(changes.deleted, id)
+		} else {
+			changes.upsert = append(changes.upsert, id)
+		}
+	}
+
+	exceptions := make([]metadata.ExceptionResult, 0)
+	for _, objID := range objIDs {
+		changes := changesMap[objID]
+		for start := 0; start < len(changes.upsert); start += defaultLimit {
+			end := start + defaultLimit
+			if end > len(changes.upsert) {
+				end = len(changes.upsert)
+			}
+			instIDArr := changes.upsert[start:end]
+
+			infos, err := s.source.FetchByID(ctx, objID, s.getAppID(), instIDArr)
+			if err != nil {
+				return fmt.Errorf("fetch %s instances failed, err: %v", objID, err)
+			}
+
+			idField := common.GetInstIDField(objID)
+			items := make([]*metadata.SynchronizeItem, 0)
+			found := make(map[int64]bool)
+			for _, info := range infos {
+				id, err := info.Int64(idField)
+				if err != nil {
+					continue
+				}
+				found[id] = true
+				items = append(items, &metadata.SynchronizeItem{ID: id, Info: info})
+			}
+			// the instance is deleted or not match the synchronize condition any more
+			for _, id := range instIDArr {
+				if !found[id] {
+					changes.deleted = append(changes.deleted, id)
+				}
+			}
+
+			errs, err := s.synchronize(ctx, metadata.SynchronizeOperateTypeRepalce,
+				metadata.SynchronizeOperateDataTypeInstance, objID, items)
+			if err != nil {
+				return err
+			}
+			exceptions = append(exceptions, errs...)
+		}
+
+		if len(changes.deleted) > 0 {
+			idField := common.GetInstIDField(objID)
+			items := make([]*metadata.SynchronizeItem, len(changes.deleted))
+			for idx, id := range changes.deleted {
+				items[idx] = &metadata.SynchronizeItem{ID: id, Info: mapstr.MapStr{idField: id}}
+			}
+			errs, err := s.synchronize(ctx, metadata.SynchronizeOperateTypeDelete,
+				metadata.SynchronizeOperateDataTypeInstance, objID, items)
+			if err != nil {
+				return err
+			}
+			exceptions = append(exceptions, errs...)
+		}
+
+		if objID == common.BKInnerObjIDApp {
+			s.refreshAppID(ctx)
+		}
+	}
+
+	if len(exceptions) > 0 {
+		s.exception(ctx, resource, exceptions)
+	}
+	return nil
+}
+
+// applyHostRelation applies the host relation events in order, the consecutive events with the same type are
+// applied together.
+func (s *incrementalSynchronize) applyHostRelation(ctx context.Context, events []*metadata.WatchEventItem) error {
+	appIDMap := make(map[int64]bool)
+	for _, appID := range s.getAppID() {
+		appIDMap[appID] = true
+	}
+
+	exceptions := make([]metadata.ExceptionResult, 0)
+	items := make([]*metadata.SynchronizeItem, 0)
+	var operateType metadata.SynchronizeOperateType
+	flush := func() error {
+		if len(items) == 0 {
+			return nil
+		}
+		errs, err := s.synchronize(ctx, operateType, metadata.SynchronizeOperateDataTypeAssociation,
+			common.SynchronizeAssociationTypeModelHost, items)
+		if err != nil {
+			return err
+		}
+		exceptions = append(exceptions, errs...)
+		items = make([]*metadata.SynchronizeItem, 0)
+		return nil
+	}
+
+	for _, event := range events {
+		if event.Detail == nil {
+			continue
+		}
+		appID, err := event.Detail.Int64(common.BKAppIDField)
+		if err != nil || (len(appIDMap) > 0 && !appIDMap[appID]) {
+			continue
+		}
+		s.metrics.events.WithLabelValues(s.config.Name, string(watch.ModuleHostRelation), string(event.EventType)).Inc()
+
+		eventOperateType := metadata.SynchronizeOperateTypeRepalce
+		if event.EventType == watch.Delete {
+			eventOperateType = metadata.SynchronizeOperateTypeDelete
+		}
+		if eventOperateType != operateType {
+			if err := flush(); err != nil {
+				return err
+			}
+			operateType = eventOperateType
+		}
+		items = append(items, &metadata.SynchronizeItem{Info: event.Detail})
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+
+	if len(exceptions) > 0 {
+		s.exception(ctx, watch.ModuleHostRelation, exceptions)
+	}
+	return nil
+}
+
+func (s *incrementalSynchronize) synchronize(ctx context.Context, operateType metadata.SynchronizeOperateType,
+	dataType metadata.SynchronizeOperateDataType, dataClassify string, items []*metadata.SynchronizeItem) (
+	[]metadata.ExceptionResult, error) {
+
+	if len(items) == 0 {
+		return nil, nil
+	}
+	input := &metadata.SynchronizeParameter{
+		OperateType:     operateType,
+		OperateDataType: dataType,
+		DataClassify:    dataClassify,
+		InfoArray:       items,
+		Version:         getVersion(),
+		SynchronizeFlag: s.config.SynchronizeFlag,
+	}
+	return s.target.Synchronize(ctx, input)
+}
+
+// syncSource fetch data from the source cmdb by the synchronize server of it
+type syncSource struct {
+	lgc    *Logics
+	config *options.ConfigItem
+}
+
+// Watch watch the resource events of the source cmdb
+func (s *syncSource) Watch(ctx context.Context, opts *watch.WatchEventOptions) (*metadata.WatchEventResult, error) {
+	result, err := s.lgc.synchronizeSrv.SynchronizeSrv(s.config.Name).Watch(ctx, s.lgc.header, opts)
+	if err != nil {
+		blog.Errorf("watch %s http do error. err:%s,opts:%#v,rid:%s", s.config.Name, err.Error(), opts, s.lgc.rid)
+		return nil, s.lgc.ccErr.Error(common.CCErrCommHTTPDoRequestFailed)
+	}
+	if !result.Result {
+		if result.Code == common.CCErrEventWatchCursorNotExist {
+			return nil, errWatchCursorExpired
+		}
+		blog.Errorf("watch %s http reply error. err code:%d,err msg:%s,opts:%#v,rid:%s", s.config.Name, result.Code,
+			result.ErrMsg, opts, s.lgc.rid)
+		return nil, s.lgc.ccErr.New(result.Code, result.ErrMsg)
+	}
+	return &result.Data, nil
+}
+
+// FetchByID fetch the instances which match the synchronize condition
+func (s *syncSource) FetchByID(ctx context.Context, objID string, appIDArr []int64, instIDArr []int64) (
+	[]mapstr.MapStr, error) {
+
+	inst := s.lgc.NewFetchInst(s.config, mapstr.New())
+	if err := inst.Pretreatment(); err != nil {
+		return nil, err
+	}
+	inst.SetAppIDArr(appIDArr)
+
+	info, err := inst.FetchByID(ctx, objID, instIDArr)
+	if err != nil {
+		return nil, err
+	}
+	if info == nil {
+		return nil, nil
+	}
+	return info.Info, nil
+}
+
+// FetchAppID fetch the id of the businesses to synchronize
+func (s *syncSource) FetchAppID(ctx context.Context) ([]int64, error) {
+	inst := s.lgc.NewFetchInst(s.config, mapstr.New())
+	if err := inst.Pretreatment(); err != nil {
+		return nil, err
+	}
+
+	appIDArr := make([]int64, 0)
+	for start := int64(0); ; start += defaultLimit {
+		info, err := inst.Fetch(ctx, common.BKInnerObjIDApp, start, defaultLimit)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range info.Info {
+			appID, err := item.Int64(common.BKAppIDField)
+			if err != nil {
+				continue
+			}
+			appIDArr = append(appIDArr, appID)
+		}
+		if start+defaultLimit >= int64(info.Count) {
+			break
+		}
+	}
+	return appIDArr, nil
+}
+
+// syncTarget applies the changes to current cmdb
+type syncTarget struct {
+	lgc *Logics
+}
+
+// Synchronize save the synchronize data to current cmdb
+func (s *syncTarget) Synchronize(ctx context.Context, input *metadata.SynchronizeParameter) (
+	[]metadata.ExceptionResult, error) {
+
+	var result *metadata.SynchronizeResult
+	var err error
+	switch input.OperateDataType {
+	case metadata.SynchronizeOperateDataTypeAssociation:
+		result, err = s.lgc.CoreAPI.CoreService().Synchronize().SynchronizeAssociation(ctx, s.lgc.header, input)
+	default:
+		result, err = s.lgc.CoreAPI.CoreService().Synchronize().SynchronizeInstance(ctx, s.lgc.header, input)
+	}
+	if err != nil {
+		blog.Errorf("incremental synchronize http do error, error: %s,DataSign: %s,DataType: %d,rid:%s", err.Error(),
+			input.DataClassify, input.OperateDataType, s.lgc.rid)
+		return nil, s.lgc.ccErr.Error(common.CCErrCommHTTPDoRequestFailed)
+	}
+	if result.Result {
+		return nil, nil
+	}
+	if len(result.Data.Exceptions) > 0 {
+		return result.Data.Exceptions, nil
+	}
+	return []metadata.ExceptionResult{{
+		Code:    int64(result.Code),
+		Message: result.ErrMsg,
+		Data:    input.InfoArray,
+	}}, nil
+}
+
+// redisCursorStore save the watch cursors in redis
+type redisCursorStore struct {
+	cache redis.Client
+}
+
+// GetCursor get the cursor, returns empty cursor if not exist
+func (r *redisCursorStore) GetCursor(ctx context.Context, key string) (string, error) {
+	cursor, err := r.cache.Get(ctx, key).Result()
+	if err != nil && !redis.IsNilErr(err) {
+		return "", err
+	}
+	return cursor, nil
+}
+
+// SetCursor save the cursor without expiration, the expired cursor is detected by the source cmdb
+func (r *redisCursorStore) SetCursor(ctx context.Context, key string, cursor string) error {
+	return r.cache.Set(ctx, key, cursor, 0).Err()
+}
+
+func writeIncrementalException(config *options.ConfigItem) func(ctx context.Context, resource watch.CursorType,
+	exceptions []metadata.ExceptionResult) {
+
+	return func(ctx context.Context, resource watch.CursorType, exceptions []metadata.ExceptionResult) {
+		version := getVersion()
+		exceptionFile, err := file.NewException(config.SynchronizeFlag, version, config.ExceptionFileCount)
+		if err != nil {
+			blog.Errorf("incremental synchronize exception error, config:%#v,err:%s,version:%d", config, err.Error(), version)
+			return
+		}
+		defer exceptionFile.Destruct()
+
+		exceptionType := "incremental " + string(resource)
+		if err := exceptionFile.WriteStringln("synchronize " + exceptionType + " exception start"); err != nil {
+			return
+		}
+		if err := exceptionFile.Write(ctx, exceptions); err != nil {
+			return
+		}
+		exceptionFile.WriteStringln("synchronize " + exceptionType + " exception end")
+	}
+}
+
+// incrementalMetrics is the metrics of incremental synchronize, the labels are config item name and resource
+type incrementalMetrics struct {
+	// lag is the duration between the last synchronized event occurs and it's synchronized, 0 when no events
+	lag *prometheus.GaugeVec
+	// lastEventTime is the unix time of the last synchronized event
+	lastEventTime *prometheus.GaugeVec
+	// events is the count of synchronized events
+	events *prometheus.CounterVec
+	// errors is the count of failed watch rounds
+	errors *prometheus.CounterVec
+	// resync is the count of full synchronize caused by missing or expired cursor
+	resync *prometheus.CounterVec
+}
+
+var (
+	incMetrics     *incrementalMetrics
+	incMetricsOnce sync.Once
+)
+
+func getIncrementalMetrics() *incrementalMetrics {
+	incMetricsOnce.Do(func() {
+		labels := []string{"name", "resource"}
+		m := new(incrementalMetrics)
+		m.lag = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: "synchronize",
+			Name:      "incremental_lag_seconds",
+			Help:      "the lag(seconds) between the last synchronized event occurs and it's synchronized",
+		}, labels)
+		m.lastEventTime = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: "synchronize",
+			Name:      "incremental_last_event_unix_time_seconds",
+			Help:      "the unix time seconds of the last synchronized event",
+		}, labels)
+		m.events = prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: "synchronize",
+			Name:      "incremental_total_event_count",
+			Help:      "the total count of synchronized events",
+		}, append(labels, "event_type"))
+		m.errors = prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: "synchronize",
+			Name:      "incremental_total_error_count",
+			Help:      "the total count of failed incremental synchronize rounds",
+		}, labels)
+		m.resync = prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: "synchronize",
+			Name:      "incremental_total_resync_count",
+			Help:      "the total count of full synchronize caused by missing or expired watch cursor",
+		}, []string{"name"})
+		metrics.Register().MustRegister(m.lag, m.lastEventTime, m.events, m.errors, m.resync)
+		incMetrics = m
+	})
+	return incMetrics
+}
@@ -0,0 +1,255 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package logics
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/watch"
+	"configcenter/src/scene_server/synchronize_server/app/options"
+)
+
+type fakeSource struct {
+	results  []*metadata.WatchEventResult
+	errs     []error
+	opts     []*watch.WatchEventOptions
+	insts    map[int64]mapstr.MapStr
+	fetchIDs [][]int64
+}
+
+func (f *fakeSource) Watch(ctx context.Context, opts *watch.WatchEventOptions) (*metadata.WatchEventResult, error) {
+	f.opts = append(f.opts, opts)
+	result, err := f.results[0], f.errs[0]
+	f.results, f.errs = f.results[1:], f.errs[1:]
+	return result, err
+}
+
+func (f *fakeSource) FetchByID(ctx context.Context, objID string, appIDArr []int64, instIDArr []int64) (
+	[]mapstr.MapStr, error) {
+
+	f.fetchIDs = append(f.fetchIDs, instIDArr)
+	infos := make([]mapstr.MapStr, 0)
+	for _, id := range instIDArr {
+		if inst, ok := f.insts[id]; ok {
+			infos = append(infos, inst)
+		}
+	}
+	return infos, nil
+}
+
+func (f *fakeSource) FetchAppID(ctx context.Context) ([]int64, error) {
+	return []int64{1}, nil
+}
+
+type fakeTarget struct {
+	inputs []*metadata.SynchronizeParameter
+}
+
+func (f *fakeTarget) Synchronize(ctx context.Context, input *metadata.SynchronizeParameter) (
+	[]metadata.ExceptionResult, error) {
+
+	f.inputs = append(f.inputs, input)
+	return nil, nil
+}
+
+type memoryCursorStore map[string]string
+
+func (m memoryCursorStore) GetCursor(ctx context.Context, key string) (string, error) {
+	return m[key], nil
+}
+
+func (m memoryCursorStore) SetCursor(ctx context.Context, key string, cursor string) error {
+	m[key] = cursor
+	return nil
+}
+
+func newTestIncrementalSynchronize(source *fakeSource, target *fakeTarget, store memoryCursorStore,
+	fullSync *int) *incrementalSynchronize {
+
+	now := time.Unix(1600000000, 0)
+	return &incrementalSynchronize{
+		config:  &options.ConfigItem{Name: "test", SynchronizeFlag: "test"},
+		source:  source,
+		target:  target,
+		store:   store,
+		metrics: getIncrementalMetrics(),
+		fullSync: func(ctx context.Context) {
+			*fullSync++
+		},
+		isMaster:  func() bool { return true },
+		exception: func(ctx context.Context, resource watch.CursorType, exceptions []metadata.ExceptionResult) {},
+		now: func() time.Time {
+			now = now.Add(time.Second)
+			return now
+		},
+		resyncLock: new(sync.Mutex),
+	}
+}
+
+func TestIncrementalSynchronizeApplyInstance(t *testing.T) {
+	source := &fakeSource{
+		results: []*metadata.WatchEventResult{{
+			Watched: true,
+			Events: []*metadata.WatchEventItem{
+				{Cursor: "c1", EventType: watch.Create, Detail: mapstr.MapStr{common.BKSetIDField: 1}},
+				{Cursor: "c2", EventType: watch.Update, Detail: mapstr.MapStr{common.BKSetIDField: 2}},
+				{Cursor: "c3", EventType: watch.Delete, Detail: mapstr.MapStr{common.BKSetIDField: 1}},
+				{Cursor: "c4", EventType: watch.Create, Detail: mapstr.MapStr{common.BKSetIDField: 3}},
+			},
+		}},
+		errs: []error{nil},
+		// set 3 is deleted or not match the synchronize condition
+		insts: map[int64]mapstr.MapStr{2: {common.BKSetIDField: 2, common.BKSetNameField: "set"}},
+	}
+	target := new(fakeTarget)
+	store := memoryCursorStore{incrementalCursorKeyPrefix + "test:set": "c0"}
+	fullSync := 0
+	s := newTestIncrementalSynchronize(source, target, store, &fullSync)
+
+	state := new(resourceWatchState)
+	if err := s.watchOnce(context.Background(), watch.Set, state); err != nil {
+		t.Fatalf("watch once failed, err: %v", err)
+	}
+
+	if fullSync != 0 {
+		t.Fatalf("full synchronize should not be triggered with cursor")
+	}
+	if source.opts[0].Cursor != "c0" {
+		t.Fatalf("watch with cursor %s, expect c0", source.opts[0].Cursor)
+	}
+	if len(source.fetchIDs) != 1 || len(source.fetchIDs[0]) != 2 {
+		t.Fatalf("fetch ids %v, expect [2 3]", source.fetchIDs)
+	}
+	if len(target.inputs) != 2 {
+		t.Fatalf("synchronize %d times, expect 2", len(target.inputs))
+	}
+
+	replace := target.inputs[0]
+	if replace.OperateType != metadata.SynchronizeOperateTypeRepalce || len(replace.InfoArray) != 1 ||
+		replace.InfoArray[0].ID != 2 || replace.DataClassify != common.BKInnerObjIDSet {
+		t.Fatalf("invalid replace input: %#v", replace)
+	}
+	deleted := target.inputs[1]
+	if deleted.OperateType != metadata.SynchronizeOperateTypeDelete || len(deleted.InfoArray) != 2 ||
+		deleted.InfoArray[0].ID != 1 || deleted.InfoArray[1].ID != 3 {
+		t.Fatalf("invalid delete input: %#v", deleted)
+	}
+
+	if store[incrementalCursorKeyPrefix+"test:set"] != "c4" {
+		t.Fatalf("cursor %s is not saved, expect c4", store[incrementalCursorKeyPrefix+"test:set"])
+	}
+}
+
+func TestIncrementalSynchronizeHostRelationOrder(t *testing.T) {
+	relation := func(hostID int64, moduleID int64) mapstr.MapStr {
+		return mapstr.MapStr{common.BKAppIDField: 1, common.BKHostIDField: hostID, common.BKModuleIDField: moduleID}
+	}
+	source := &fakeSource{
+		results: []*metadata.WatchEventResult{{
+			Watched: true,
+			Events: []*metadata.WatchEventItem{
+				{Cursor: "c1", EventType: watch.Delete, Detail: relation(1, 1)},
+				{Cursor: "c2", EventType: watch.Create, Detail: relation(1, 2)},
+				{Cursor: "c3", EventType: watch.Create, Detail: relation(2, 2)},
+				{Cursor: "c4", EventType: watch.Delete, Detail: relation(1, 2)},
+			},
+		}},
+		errs: []error{nil},
+	}
+	target := new(fakeTarget)
+	store := memoryCursorStore{incrementalCursorKeyPrefix + "test:host_relation": "c0"}
+	fullSync := 0
+	s := newTestIncrementalSynchronize(source, target, store, &fullSync)
+
+	if err := s.watchOnce(context.Background(), watch.ModuleHostRelation, new(resourceWatchState)); err != nil {
+		t.Fatalf("watch once failed, err: %v", err)
+	}
+
+	expects := []struct {
+		operateType metadata.SynchronizeOperateType
+		count       int
+	}{
+		{metadata.SynchronizeOperateTypeDelete, 1},
+		{metadata.SynchronizeOperateTypeRepalce, 2},
+		{metadata.SynchronizeOperateTypeDelete, 1},
+	}
+	if len(target.inputs) != len(expects) {
+		t.Fatalf("synchronize %d times, expect %d", len(target.inputs), len(expects))
+	}
+	for idx, expect := range expects {
+		input := target.inputs[idx]
+		if input.OperateType != expect.operateType || len(input.InfoArray) != expect.count ||
+			input.DataClassify != common.SynchronizeAssociationTypeModelHost {
+			t.Fatalf("invalid input %d: %#v", idx, input)
+		}
+	}
+	if store[incrementalCursorKeyPrefix+"test:host_relation"] != "c4" {
+		t.Fatalf("cursor is not saved")
+	}
+}
+
+func TestIncrementalSynchronizeResync(t *testing.T) {
+	source := &fakeSource{
+		results: []*metadata.WatchEventResult{
+			{Watched: false, Events: []*metadata.WatchEventItem{{Cursor: watch.NoEventCursor}}},
+			{Watched: false, Events: []*metadata.WatchEventItem{{Cursor: "c1"}}},
+			nil,
+		},
+		errs: []error{nil, nil, errWatchCursorExpired},
+	}
+	target := new(fakeTarget)
+	store := memoryCursorStore{}
+	fullSync := 0
+	s := newTestIncrementalSynchronize(source, target, store, &fullSync)
+	state := new(resourceWatchState)
+	key := incrementalCursorKeyPrefix + "test:host"
+
+	// no cursor, synchronize all data and watch from the start time of it
+	if err := s.watchOnce(context.Background(), watch.Host, state); err != nil {
+		t.Fatalf("watch once failed, err: %v", err)
+	}
+	if fullSync != 1 || source.opts[0].StartFrom == 0 || len(source.opts[0].Cursor) != 0 {
+		t.Fatalf("full synchronize is not triggered, count: %d, opts: %#v", fullSync, source.opts[0])
+	}
+	if len(store) != 0 {
+		t.Fatalf("no event cursor should not be saved")
+	}
+
+	// no events but a new cursor is returned, save it
+	if err := s.watchOnce(context.Background(), watch.Host, state); err != nil {
+		t.Fatalf("watch once failed, err: %v", err)
+	}
+	if source.opts[1].StartFrom != source.opts[0].StartFrom {
+		t.Fatalf("watch start from %d, expect %d", source.opts[1].StartFrom, source.opts[0].StartFrom)
+	}
+	if store[key] != "c1" {
+		t.Fatalf("cursor is not saved")
+	}
+
+	// cursor expired, synchronize all data again and watch from the start time of it
+	if err := s.watchOnce(context.Background(), watch.Host, state); err != nil {
+		t.Fatalf("watch once failed, err: %v", err)
+	}
+	if source.opts[2].Cursor != "c1" {
+		t.Fatalf("watch with cursor %s, expect c1", source.opts[2].Cursor)
+	}
+	if fullSync != 2 || len(state.cursor) != 0 || state.startFrom <= source.opts[0].StartFrom {
+		t.Fatalf("full synchronize is not triggered after cursor expired, count: %d, state: %#v", fullSync, state)
+	}
+}
@@ -65,9 +65,21 @@ func (fi *FetchInst) Pretreatment() errors.CCError {
 
 // Fetch fetch instance data
 func (fi *FetchInst) Fetch(ctx context.Context, objID string, start, limit int64) (*metadata.InstDataInfo, errors.CCError) {
+	return fi.fetch(ctx, objID, nil, start, limit)
+}
+
+// FetchByID fetch instance data with instance id, the instances not match the synchronize condition are not returned
+func (fi *FetchInst) FetchByID(ctx context.Context, objID string, instIDArr []int64) (*metadata.InstDataInfo, errors.CCError) {
+	conds := condition.CreateCondition()
+	conds.Field(common.GetInstIDField(objID)).In(instIDArr)
+	return fi.fetch(ctx, objID, conds.ToMapStr(), 0, int64(len(instIDArr)))
+}
+
+func (fi *FetchInst) fetch(ctx context.Context, objID string, extraConds mapstr.MapStr, start, limit int64) (*metadata.InstDataInfo, errors.CCError) {
 	input := &metadata.SynchronizeFindInfoParameter{
 		Condition: mapstr.New(),
 	}
+	input.Condition.Merge(extraConds)
 	input.Limit = uint64(limit)
 	input.Start = uint64(start)
 	switch objID {
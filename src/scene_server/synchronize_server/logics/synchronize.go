@@ -83,15 +83,49 @@ func (lgc *Logics) TriggerSynchronize(ctx context.Context, config *options.Confi
 
 }
 
-// Synchronize synchronize manager
+// Synchronize synchronize manager, the instances and host relations of the incremental config items are
+// synchronized by watching the events, only the data without watch cursor of them is synchronized here.
 func (lgc *Logics) Synchronize(ctx context.Context, config *options.Config) {
 
 	for idx := range config.ConifgItemArray {
+		if config.ConifgItemArray[idx].Incremental && lgc.cache != nil {
+			go lgc.SynchronizeItemUnwatched(ctx, config.ConifgItemArray[idx])
+			continue
+		}
 		go lgc.SynchronizeItem(ctx, config.ConifgItemArray[idx])
 	}
 
 }
 
+// SynchronizeItemUnwatched synchronize the models and the cloud areas of the incremental config item, which have no
+// watch cursor. the data is not cleared, the deleted models and cloud areas are cleared by the next full synchronize.
+func (lgc *Logics) SynchronizeItemUnwatched(ctx context.Context, syncConfig *options.ConfigItem) {
+	// do not run with the full synchronize of the incremental synchronize at the same time
+	lock := getResyncLock(syncConfig.Name)
+	lock.Lock()
+	defer lock.Unlock()
+
+	version := getVersion()
+	blog.InfoJSON("start synchonrize unwatched data config:%s, verison:%s", syncConfig, version)
+	synchronizeItem := lgc.NewSynchronizeItem(version, syncConfig)
+
+	exceptionMap := make(map[string][]metadata.ExceptionResult)
+	var err error
+	exceptionMap["model"], err = synchronizeItem.synchronizeModelTask(ctx)
+	if err != nil {
+		blog.Errorf("SynchronizeItemUnwatched model error, config:%#v,err:%s,version:%d,rid:%s", syncConfig, err.Error(), version, lgc.rid)
+		return
+	}
+
+	exceptionMap["instance"], err = synchronizeItem.synchronizeUnwatchedInstanceTask(ctx)
+	if err != nil {
+		blog.Errorf("SynchronizeItemUnwatched instance error, config:%#v,err:%s,version:%d,rid:%s", syncConfig, err.Error(), version, lgc.rid)
+	}
+	go synchronizeItem.synchronizeItemException(ctx, exceptionMap)
+
+	blog.InfoJSON("end synchonrize unwatched data config:%s, verison:%s", syncConfig, version)
+}
+
 // SynchronizeItem  synchronize data
 func (lgc *Logics) SynchronizeItem(ctx context.Context, syncConfig *options.ConfigItem) {
 	version := getVersion()
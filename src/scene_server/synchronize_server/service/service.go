@@ -82,6 +82,7 @@ func (s *Service) WebService() *restful.Container {
 
 	ws.Route(ws.POST("/search").To(s.Find))
 	ws.Route(ws.POST("/set/identifier/flag").To(s.SetIdentifierFlag))
+	ws.Route(ws.POST("/watch/resource/{resource}").To(s.Watch))
 
 	container.Add(ws)
 
@@ -146,4 +147,5 @@ func (s *Service) InitBackground() {
 
 	srvData := s.newSrvComm(header)
 	go srvData.lgc.TriggerSynchronize(srvData.ctx, s.Config)
+	go srvData.lgc.TriggerIncrementalSynchronize(srvData.ctx, s.Config)
 }
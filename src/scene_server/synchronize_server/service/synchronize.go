@@ -21,6 +21,7 @@ import (
 	"configcenter/src/common"
 	"configcenter/src/common/blog"
 	"configcenter/src/common/metadata"
+	"configcenter/src/common/watch"
 )
 
 func (s *Service) Find(req *restful.Request, resp *restful.Response) {
@@ -44,6 +45,26 @@ func (s *Service) Find(req *restful.Request, resp *restful.Response) {
 	})
 }
 
+// Watch watch the resource events of current cmdb for the incremental synchronization of the target cmdb
+func (s *Service) Watch(req *restful.Request, resp *restful.Response) {
+	srvData := s.newSrvComm(req.Request.Header)
+	input := &watch.WatchEventOptions{}
+	if err := json.NewDecoder(req.Request.Body).Decode(input); err != nil {
+		blog.Errorf("Watch , but decode body failed, err: %v,rid:%s", err, srvData.rid)
+		resp.WriteError(http.StatusBadRequest, &metadata.RespError{Msg: srvData.ccErr.Error(common.CCErrCommJSONUnmarshalFailed)})
+		return
+	}
+	input.Resource = watch.CursorType(req.PathParameter("resource"))
+
+	data, err := srvData.lgc.CoreAPI.EventServer().Watch(srvData.ctx, srvData.header, input)
+	if err != nil {
+		blog.Errorf("Watch error. error: %s,input:%#v,rid:%s", err.Error(), input, srvData.rid)
+		resp.WriteError(http.StatusInternalServerError, &metadata.RespError{Msg: srvData.ccErr.Error(common.CCErrCommHTTPDoRequestFailed)})
+		return
+	}
+	resp.WriteEntity(data)
+}
+
 // SetIdentifierFlag set cmdb synchronize identifier flag
 func (s *Service) SetIdentifierFlag(req *restful.Request, resp *restful.Response) {
 	srvData := s.newSrvComm(req.Request.Header)
@@ -36,6 +36,7 @@ var (
 // init for auto task
 func init() {
 	AddCodeTaskConfig("sync-settemplate2set", types.CC_MODULE_TOPO, "/topo/v3/internal/task", 1)
+	AddCodeTaskConfig("clone-biz-topo", types.CC_MODULE_TOPO, "/topo/v3/internal/task/clone_biz_topo", 1)
 }
 
 // AddCodeTaskConfig add task
@@ -144,6 +144,9 @@ func Run(ctx context.Context, cancel context.CancelFunc, op *options.ServerOptio
 		Config:      server.Config,
 	}
 
+	go server.Service.SetTemplateSyncScheduler(ctx)
+	go server.Service.ApprovalExpireScheduler(ctx)
+
 	err = backbone.StartServer(ctx, cancel, engine, server.Service.WebService(), true)
 	if err != nil {
 		return err
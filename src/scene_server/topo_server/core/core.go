@@ -38,6 +38,7 @@ type Core interface {
 	AuditOperation() operation.AuditOperationInterface
 	UniqueOperation() operation.UniqueOperationInterface
 	SetTemplateOperation() settemplate.SetTemplate
+	SchemaOperation() operation.SchemaOperationInterface
 }
 
 type core struct {
@@ -55,6 +56,7 @@ type core struct {
 	identifier     operation.IdentifierOperationInterface
 	unique         operation.UniqueOperationInterface
 	setTemplate    settemplate.SetTemplate
+	schema         operation.SchemaOperationInterface
 }
 
 // New create a logics manager
@@ -74,6 +76,7 @@ func New(client apimachinery.ClientSetInterface, authManager *extensions.AuthMan
 	audit := operation.NewAuditOperation(client)
 	unique := operation.NewUniqueOperation(client, authManager)
 	setTemplate := settemplate.NewSetTemplate(client)
+	schema := operation.NewSchemaOperation(client, authManager)
 
 	targetModel := model.New(client, languageIf)
 	targetInst := inst.New(client)
@@ -91,6 +94,7 @@ func New(client apimachinery.ClientSetInterface, authManager *extensions.AuthMan
 	businessOperation.SetProxy(setOperation, moduleOperation, instOperation, objectOperation)
 
 	graphics.SetProxy(objectOperation, associationOperation)
+	schema.SetProxy(classificationOperation, objectOperation, groupOperation, attributeOperation, unique, associationOperation)
 
 	return &core{
 		set:            setOperation,
@@ -107,6 +111,7 @@ func New(client apimachinery.ClientSetInterface, authManager *extensions.AuthMan
 		identifier:     identifier,
 		unique:         unique,
 		setTemplate:    setTemplate,
+		schema:         schema,
 	}
 }
 
@@ -155,3 +160,6 @@ func (c *core) UniqueOperation() operation.UniqueOperationInterface {
 func (c *core) SetTemplateOperation() settemplate.SetTemplate {
 	return c.setTemplate
 }
+func (c *core) SchemaOperation() operation.SchemaOperationInterface {
+	return c.schema
+}
@@ -38,6 +38,7 @@ type AssociationOperationInterface interface {
 	SearchMainlineAssociationTopo(kit *rest.Kit, targetObj model.Object) ([]*metadata.MainlineObjectTopo, error)
 	SearchMainlineAssociationInstTopo(kit *rest.Kit, objID string, instID int64, withStatistics bool, withDefault bool) ([]*metadata.TopoInstRst, errors.CCError)
 	IsMainlineObject(kit *rest.Kit, objID string) (bool, error)
+	DiffBusinessTopo(kit *rest.Kit, bizID int64, request *metadata.TopoDiffRequest) (*metadata.TopoDiffResult, error)
 
 	CreateCommonAssociation(kit *rest.Kit, data *metadata.Association) (*metadata.Association, error)
 	DeleteAssociationWithPreCheck(kit *rest.Kit, associationID int64) error
@@ -68,6 +69,7 @@ type AssociationOperationInterface interface {
 
 	SearchInst(kit *rest.Kit, request *metadata.SearchAssociationInstRequest) (resp *metadata.SearchAssociationInstResult, err error)
 	SearchAssociationRelatedInst(kit *rest.Kit, request *metadata.SearchAssociationRelatedInstRequest) (resp *metadata.SearchAssociationInstResult, err error)
+	SearchInstAssociationGraph(kit *rest.Kit, request *metadata.SearchInstAssociationGraphRequest) (*metadata.InstAssociationGraph, errors.CCErrorCoder)
 	CreateInst(kit *rest.Kit, request *metadata.CreateAssociationInstRequest) (resp *metadata.CreateAssociationInstResult, err error)
 	DeleteInst(kit *rest.Kit, assoID int64) (resp *metadata.DeleteAssociationInstResult, err error)
 
@@ -708,7 +710,7 @@ func (assoc *association) DeleteObject(kit *rest.Kit, asstID int) (resp *metadat
 }
 
 func (assoc *association) SearchInst(kit *rest.Kit, request *metadata.SearchAssociationInstRequest) (resp *metadata.SearchAssociationInstResult, err error) {
-	rsp, err := assoc.clientSet.CoreService().Association().ReadInstAssociation(context.Background(), kit.Header, &metadata.QueryCondition{Condition: request.Condition})
+	rsp, err := assoc.clientSet.CoreService().Association().ReadInstAssociation(context.Background(), kit.Header, &metadata.QueryCondition{Condition: request.Condition, Filter: request.Filter})
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,278 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package operation
+
+import (
+	"sort"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+)
+
+// the number of instances to search their associations in one request
+const instGraphBatchSize = 50
+
+type instGraphKey struct {
+	objID  string
+	instID int64
+}
+
+type instGraph struct {
+	request   *metadata.SearchInstAssociationGraphRequest
+	nodes     map[instGraphKey]*metadata.InstGraphNode
+	order     []instGraphKey
+	edges     map[int64]metadata.InstAsst
+	fanOut    map[instGraphKey]int
+	truncated bool
+}
+
+// SearchInstAssociationGraph traverse the instance associations from the start instance in breadth first order,
+// the instance visited is not expanded again, so the cycles in the graph end up as edges between known nodes.
+func (assoc *association) SearchInstAssociationGraph(kit *rest.Kit, request *metadata.SearchInstAssociationGraphRequest) (
+	*metadata.InstAssociationGraph, errors.CCErrorCoder) {
+
+	start := instGraphKey{objID: request.ObjectID, instID: request.InstID}
+	graph := &instGraph{
+		request: request,
+		nodes: map[instGraphKey]*metadata.InstGraphNode{
+			start: {ObjectID: start.objID, InstID: start.instID, Path: make([]int64, 0)},
+		},
+		order:  []instGraphKey{start},
+		edges:  make(map[int64]metadata.InstAsst),
+		fanOut: make(map[instGraphKey]int),
+	}
+
+	frontier := []instGraphKey{start}
+	for depth := 1; depth <= request.Depth && len(frontier) > 0; depth++ {
+		next := make([]instGraphKey, 0)
+		grouped := groupInstGraphKeys(frontier)
+		// 按模型顺序展开, 保证相同的图每次返回的节点顺序一致
+		objIDs := make([]string, 0, len(grouped))
+		for objID := range grouped {
+			objIDs = append(objIDs, objID)
+		}
+		sort.Strings(objIDs)
+		for _, objID := range objIDs {
+			instIDs := grouped[objID]
+			for begin := 0; begin < len(instIDs); begin += instGraphBatchSize {
+				end := begin + instGraphBatchSize
+				if end > len(instIDs) {
+					end = len(instIDs)
+				}
+
+				asstInsts, err := assoc.searchInstGraphEdges(kit, graph, objID, instIDs[begin:end])
+				if err != nil {
+					return nil, err
+				}
+				next = append(next, graph.expand(objID, instIDs[begin:end], depth, asstInsts)...)
+			}
+		}
+		frontier = next
+	}
+
+	result := &metadata.InstAssociationGraph{
+		Count:     len(graph.order),
+		Nodes:     make([]metadata.InstGraphNode, 0),
+		Edges:     make([]metadata.InstAsst, 0),
+		Truncated: graph.truncated,
+	}
+	if request.Page.Start >= len(graph.order) {
+		return result, nil
+	}
+	end := request.Page.Start + request.Page.Limit
+	if end > len(graph.order) {
+		end = len(graph.order)
+	}
+	pageKeys := graph.order[request.Page.Start:end]
+
+	if err := assoc.setInstGraphNodeName(kit, graph, pageKeys); err != nil {
+		return nil, err
+	}
+
+	inPage := make(map[instGraphKey]bool, len(pageKeys))
+	for _, key := range pageKeys {
+		inPage[key] = true
+		result.Nodes = append(result.Nodes, *graph.nodes[key])
+	}
+
+	edgeIDs := make(map[int64]bool)
+	for _, node := range result.Nodes {
+		for _, id := range node.Path {
+			edgeIDs[id] = true
+		}
+	}
+	for id, edge := range graph.edges {
+		if inPage[instGraphKey{edge.ObjectID, edge.InstID}] && inPage[instGraphKey{edge.AsstObjectID, edge.AsstInstID}] {
+			edgeIDs[id] = true
+		}
+	}
+	for id := range edgeIDs {
+		result.Edges = append(result.Edges, graph.edges[id])
+	}
+	sort.Slice(result.Edges, func(i, j int) bool {
+		return result.Edges[i].ID < result.Edges[j].ID
+	})
+
+	return result, nil
+}
+
+func groupInstGraphKeys(keys []instGraphKey) map[string][]int64 {
+	result := make(map[string][]int64)
+	for _, key := range keys {
+		result[key.objID] = append(result[key.objID], key.instID)
+	}
+	return result
+}
+
+// searchInstGraphEdges search the associations of the instances by the direction, association kind and object filter
+func (assoc *association) searchInstGraphEdges(kit *rest.Kit, graph *instGraph, objID string, instIDs []int64) (
+	[]metadata.InstAsst, errors.CCErrorCoder) {
+
+	request := graph.request
+	orCond := make([]mapstr.MapStr, 0)
+	if request.Direction != metadata.InstGraphDirectionIn {
+		cond := mapstr.MapStr{
+			common.BKObjIDField:  objID,
+			common.BKInstIDField: mapstr.MapStr{common.BKDBIN: instIDs},
+		}
+		if len(request.ObjectIDs) > 0 {
+			cond[common.BKAsstObjIDField] = mapstr.MapStr{common.BKDBIN: request.ObjectIDs}
+		}
+		orCond = append(orCond, cond)
+	}
+	if request.Direction != metadata.InstGraphDirectionOut {
+		cond := mapstr.MapStr{
+			common.BKAsstObjIDField:  objID,
+			common.BKAsstInstIDField: mapstr.MapStr{common.BKDBIN: instIDs},
+		}
+		if len(request.ObjectIDs) > 0 {
+			cond[common.BKObjIDField] = mapstr.MapStr{common.BKDBIN: request.ObjectIDs}
+		}
+		orCond = append(orCond, cond)
+	}
+
+	cond := mapstr.MapStr{common.BKDBOR: orCond}
+	if len(request.AssociationKindIDs) > 0 {
+		cond[common.AssociationKindIDField] = mapstr.MapStr{common.BKDBIN: request.AssociationKindIDs}
+	}
+
+	// 每个实例最多展开max_fan_out个关联, 多查一条用于判断是否被截断
+	limit := len(instIDs) * (request.MaxFanOut + 1)
+	query := &metadata.QueryCondition{
+		Condition: cond,
+		Page:      metadata.BasePage{Limit: limit, Sort: common.BKFieldID},
+	}
+	asstInsts, _, err := assoc.SearchInstAssociationList(kit, query)
+	if err != nil {
+		blog.Errorf("search instance association graph edges failed, cond: %#v, err: %v, rid: %s", cond, err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrObjectSelectInstFailed)
+	}
+	if len(asstInsts) >= limit {
+		graph.truncated = true
+	}
+	return asstInsts, nil
+}
+
+// expand add the associations of the instances to the graph, returns the new instances found
+func (g *instGraph) expand(objID string, instIDs []int64, depth int, asstInsts []metadata.InstAsst) []instGraphKey {
+	frontier := make(map[int64]bool, len(instIDs))
+	for _, id := range instIDs {
+		frontier[id] = true
+	}
+
+	next := make([]instGraphKey, 0)
+	for _, asst := range asstInsts {
+		src := instGraphKey{objID: asst.ObjectID, instID: asst.InstID}
+		dst := instGraphKey{objID: asst.AsstObjectID, instID: asst.AsstInstID}
+
+		if g.request.Direction != metadata.InstGraphDirectionIn && src.objID == objID && frontier[src.instID] {
+			if key, isNew := g.addEdge(src, dst, depth, asst); isNew {
+				next = append(next, key)
+			}
+		}
+		if g.request.Direction != metadata.InstGraphDirectionOut && dst.objID == objID && frontier[dst.instID] {
+			if key, isNew := g.addEdge(dst, src, depth, asst); isNew {
+				next = append(next, key)
+			}
+		}
+	}
+	return next
+}
+
+func (g *instGraph) addEdge(from, to instGraphKey, depth int, asst metadata.InstAsst) (instGraphKey, bool) {
+	g.fanOut[from]++
+	if g.fanOut[from] > g.request.MaxFanOut {
+		g.nodes[from].Truncated = true
+		g.truncated = true
+		return to, false
+	}
+	g.edges[asst.ID] = asst
+
+	if _, exists := g.nodes[to]; exists {
+		return to, false
+	}
+	if len(g.order) >= metadata.InstGraphMaxNodes {
+		g.truncated = true
+		return to, false
+	}
+
+	fromPath := g.nodes[from].Path
+	path := make([]int64, len(fromPath), len(fromPath)+1)
+	copy(path, fromPath)
+	g.nodes[to] = &metadata.InstGraphNode{
+		ObjectID: to.objID,
+		InstID:   to.instID,
+		Depth:    depth,
+		Path:     append(path, asst.ID),
+	}
+	g.order = append(g.order, to)
+	return to, true
+}
+
+// setInstGraphNodeName set the instance name of the nodes
+func (assoc *association) setInstGraphNodeName(kit *rest.Kit, graph *instGraph, keys []instGraphKey) errors.CCErrorCoder {
+	for objID, instIDs := range groupInstGraphKeys(keys) {
+		idField := common.GetInstIDField(objID)
+		nameField := common.GetInstNameField(objID)
+		query := &metadata.QueryCondition{
+			Condition: mapstr.MapStr{idField: mapstr.MapStr{common.BKDBIN: instIDs}},
+			Fields:    []string{idField, nameField},
+			Page:      metadata.BasePage{Limit: common.BKNoLimit},
+		}
+		rsp, err := assoc.clientSet.CoreService().Instance().ReadInstance(kit.Ctx, kit.Header, objID, query)
+		if err != nil {
+			blog.Errorf("search instance graph node name failed, obj: %s, err: %v, rid: %s", objID, err, kit.Rid)
+			return kit.CCError.CCError(common.CCErrCommHTTPDoRequestFailed)
+		}
+		if err := rsp.CCError(); err != nil {
+			blog.Errorf("search instance graph node name failed, obj: %s, err: %v, rid: %s", objID, err, kit.Rid)
+			return err
+		}
+
+		for _, item := range rsp.Data.Info {
+			instID, err := util.GetInt64ByInterface(item[idField])
+			if err != nil {
+				continue
+			}
+			if node, exists := graph.nodes[instGraphKey{objID: objID, instID: instID}]; exists {
+				node.InstName = util.GetStrByInterface(item[nameField])
+			}
+		}
+	}
+	return nil
+}
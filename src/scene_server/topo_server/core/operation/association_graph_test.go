@@ -0,0 +1,88 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package operation
+
+import (
+	"reflect"
+	"testing"
+
+	"configcenter/src/common/metadata"
+)
+
+func newTestInstGraph(request *metadata.SearchInstAssociationGraphRequest) *instGraph {
+	start := instGraphKey{objID: request.ObjectID, instID: request.InstID}
+	return &instGraph{
+		request: request,
+		nodes: map[instGraphKey]*metadata.InstGraphNode{
+			start: {ObjectID: start.objID, InstID: start.instID, Path: make([]int64, 0)},
+		},
+		order:  []instGraphKey{start},
+		edges:  make(map[int64]metadata.InstAsst),
+		fanOut: make(map[instGraphKey]int),
+	}
+}
+
+func TestInstGraphExpand(t *testing.T) {
+	request := &metadata.SearchInstAssociationGraphRequest{ObjectID: "switch", InstID: 1}
+	if _, err := request.Validate(); err != nil {
+		t.Fatalf("validate request failed, err: %v", err)
+	}
+	graph := newTestInstGraph(request)
+
+	// switch1 -> host1, switch1 -> host2, host1 -> app1
+	next := graph.expand("switch", []int64{1}, 1, []metadata.InstAsst{
+		{ID: 10, ObjectID: "switch", InstID: 1, AsstObjectID: "host", AsstInstID: 1},
+		{ID: 11, ObjectID: "switch", InstID: 1, AsstObjectID: "host", AsstInstID: 2},
+	})
+	want := []instGraphKey{{"host", 1}, {"host", 2}}
+	if !reflect.DeepEqual(next, want) {
+		t.Fatalf("unexpected frontier: %v, want: %v", next, want)
+	}
+
+	// app1 -> switch1 makes a cycle, the start node should not be visited again
+	next = graph.expand("host", []int64{1, 2}, 2, []metadata.InstAsst{
+		{ID: 12, ObjectID: "host", InstID: 1, AsstObjectID: "app", AsstInstID: 1},
+		{ID: 13, ObjectID: "app", InstID: 1, AsstObjectID: "host", AsstInstID: 2},
+		{ID: 10, ObjectID: "switch", InstID: 1, AsstObjectID: "host", AsstInstID: 1},
+	})
+	want = []instGraphKey{{"app", 1}}
+	if !reflect.DeepEqual(next, want) {
+		t.Fatalf("unexpected frontier: %v, want: %v", next, want)
+	}
+
+	app := graph.nodes[instGraphKey{"app", 1}]
+	if app.Depth != 2 || !reflect.DeepEqual(app.Path, []int64{10, 12}) {
+		t.Fatalf("unexpected node: %+v", app)
+	}
+	if len(graph.order) != 4 || len(graph.edges) != 4 {
+		t.Fatalf("unexpected graph, nodes: %d, edges: %d", len(graph.order), len(graph.edges))
+	}
+}
+
+func TestInstGraphFanOut(t *testing.T) {
+	request := &metadata.SearchInstAssociationGraphRequest{ObjectID: "switch", InstID: 1, MaxFanOut: 1,
+		Direction: metadata.InstGraphDirectionOut}
+	if _, err := request.Validate(); err != nil {
+		t.Fatalf("validate request failed, err: %v", err)
+	}
+	graph := newTestInstGraph(request)
+
+	next := graph.expand("switch", []int64{1}, 1, []metadata.InstAsst{
+		{ID: 10, ObjectID: "switch", InstID: 1, AsstObjectID: "host", AsstInstID: 1},
+		{ID: 11, ObjectID: "switch", InstID: 1, AsstObjectID: "host", AsstInstID: 2},
+		{ID: 12, ObjectID: "host", InstID: 3, AsstObjectID: "switch", AsstInstID: 1},
+	})
+	if len(next) != 1 || !graph.truncated || !graph.nodes[instGraphKey{"switch", 1}].Truncated {
+		t.Fatalf("fan out is not limited, frontier: %v, truncated: %v", next, graph.truncated)
+	}
+}
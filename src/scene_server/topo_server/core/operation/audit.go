@@ -21,7 +21,7 @@ import (
 )
 
 type AuditOperationInterface interface {
-	SearchAuditList(kit *rest.Kit, query metadata.QueryCondition) (int64, []metadata.AuditLog, error)
+	SearchAuditList(kit *rest.Kit, query metadata.QueryCondition) (*metadata.AuditQueryData, error)
 	SearchAuditDetail(kit *rest.Kit, query metadata.QueryCondition) ([]metadata.AuditLog, error)
 }
 
@@ -36,14 +36,14 @@ type audit struct {
 	clientSet apimachinery.ClientSetInterface
 }
 
-func (a *audit) SearchAuditList(kit *rest.Kit, query metadata.QueryCondition) (int64, []metadata.AuditLog, error) {
+func (a *audit) SearchAuditList(kit *rest.Kit, query metadata.QueryCondition) (*metadata.AuditQueryData, error) {
 	rsp, err := a.clientSet.CoreService().Audit().SearchAuditLog(kit.Ctx, kit.Header, query)
 	if nil != err {
 		blog.ErrorJSON("search audit log list failed, error: %s, query: %s, rid: %s", err.Error(), query, kit.Rid)
-		return 0, nil, err
+		return nil, err
 	}
 
-	return rsp.Data.Count, rsp.Data.Info, nil
+	return &rsp.Data, nil
 }
 
 func (a *audit) SearchAuditDetail(kit *rest.Kit, query metadata.QueryCondition) ([]metadata.AuditLog, error) {
@@ -0,0 +1,185 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package operation
+
+import (
+	"fmt"
+	"strconv"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+)
+
+func graphInstNodeID(objID string, instID int64) string {
+	return objID + "/" + strconv.FormatInt(instID, 10)
+}
+
+// ExportModelGraph export the objects and their associations, the mainline topology is the associations of bk_mainline kind
+func (g *graphics) ExportModelGraph(kit *rest.Kit, request *metadata.ExportModelGraphRequest) (*metadata.Graph, error) {
+	objCond := mapstr.MapStr{metadata.ModelFieldIsHidden: mapstr.MapStr{common.BKDBNE: true}}
+	if len(request.ClassificationIDs) > 0 {
+		objCond[common.BKClassificationIDField] = mapstr.MapStr{common.BKDBIN: request.ClassificationIDs}
+	}
+	objRsp, err := g.clientSet.CoreService().Model().ReadModel(kit.Ctx, kit.Header, &metadata.QueryCondition{Condition: objCond})
+	if err != nil {
+		blog.Errorf("export model graph, but read objects failed, err: %v, rid: %s", err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommHTTPDoRequestFailed)
+	}
+	if !objRsp.Result {
+		return nil, kit.CCError.New(objRsp.Code, objRsp.ErrMsg)
+	}
+
+	graph := &metadata.Graph{
+		Name:  "model",
+		Nodes: make([]metadata.GraphNode, 0),
+		Edges: make([]metadata.GraphEdge, 0),
+	}
+	objIDs := make([]string, 0)
+	for _, info := range objRsp.Data.Info {
+		obj := info.Spec
+		objIDs = append(objIDs, obj.ObjectID)
+		graph.Nodes = append(graph.Nodes, metadata.GraphNode{
+			ID:    obj.ObjectID,
+			Label: obj.ObjectName,
+			Type:  common.BKInnerObjIDObject,
+			Attributes: map[string]string{
+				common.BKClassificationIDField: obj.ObjCls,
+				common.BKIsPre:                 strconv.FormatBool(obj.IsPre),
+			},
+		})
+	}
+	if len(objIDs) == 0 {
+		return graph, nil
+	}
+
+	asstCond := mapstr.MapStr{
+		common.BKObjIDField:     mapstr.MapStr{common.BKDBIN: objIDs},
+		common.BKAsstObjIDField: mapstr.MapStr{common.BKDBIN: objIDs},
+	}
+	if len(request.AssociationKindIDs) > 0 {
+		asstCond[common.AssociationKindIDField] = mapstr.MapStr{common.BKDBIN: request.AssociationKindIDs}
+	}
+	asstRsp, err := g.clientSet.CoreService().Association().ReadModelAssociation(kit.Ctx, kit.Header,
+		&metadata.QueryCondition{Condition: asstCond})
+	if err != nil {
+		blog.Errorf("export model graph, but read associations failed, err: %v, rid: %s", err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommHTTPDoRequestFailed)
+	}
+	if !asstRsp.Result {
+		return nil, kit.CCError.New(asstRsp.Code, asstRsp.ErrMsg)
+	}
+
+	for _, asst := range asstRsp.Data.Info {
+		label := asst.AssociationAliasName
+		if label == "" {
+			label = asst.AsstKindID
+		}
+		graph.Edges = append(graph.Edges, metadata.GraphEdge{
+			ID:     asst.AssociationName,
+			Source: asst.ObjectID,
+			Target: asst.AsstObjID,
+			Label:  label,
+			Type:   asst.AsstKindID,
+			Attributes: map[string]string{
+				common.AssociationObjAsstIDField: asst.AssociationName,
+				"mapping":                        string(asst.Mapping),
+			},
+		})
+	}
+	return graph, nil
+}
+
+// ExportBizTopoGraph export the mainline instance topology of the business, the edges point from parent to child
+func (g *graphics) ExportBizTopoGraph(kit *rest.Kit, bizID int64) (*metadata.Graph, error) {
+	topo, err := g.asst.SearchMainlineAssociationInstTopo(kit, common.BKInnerObjIDApp, bizID, false, true)
+	if err != nil {
+		blog.Errorf("export business %d topo graph failed, err: %v, rid: %s", bizID, err, kit.Rid)
+		return nil, err
+	}
+
+	graph := &metadata.Graph{
+		Name:  fmt.Sprintf("biz_%d", bizID),
+		Nodes: make([]metadata.GraphNode, 0),
+		Edges: make([]metadata.GraphEdge, 0),
+	}
+	var walk func(parent, node *metadata.TopoInstRst)
+	walk = func(parent, node *metadata.TopoInstRst) {
+		nodeID := graphInstNodeID(node.ObjID, node.InstID)
+		graph.Nodes = append(graph.Nodes, metadata.GraphNode{
+			ID:    nodeID,
+			Label: node.InstName,
+			Type:  node.ObjID,
+			Attributes: map[string]string{
+				common.BKObjNameField: node.ObjName,
+				"default":             strconv.Itoa(node.Default),
+			},
+		})
+		if parent != nil {
+			parentID := graphInstNodeID(parent.ObjID, parent.InstID)
+			graph.Edges = append(graph.Edges, metadata.GraphEdge{
+				ID:     parentID + "->" + nodeID,
+				Source: parentID,
+				Target: nodeID,
+				Label:  common.AssociationKindMainline,
+				Type:   common.AssociationKindMainline,
+			})
+		}
+		for _, child := range node.Child {
+			walk(node, child)
+		}
+	}
+	for _, root := range topo {
+		walk(nil, root)
+	}
+	return graph, nil
+}
+
+// ExportInstGraph export the instance association subgraph around the start instance
+func (g *graphics) ExportInstGraph(kit *rest.Kit, request *metadata.SearchInstAssociationGraphRequest) (*metadata.Graph, error) {
+	// 导出时不分页, 返回遍历到的所有节点
+	request.Page = metadata.BasePage{Start: 0, Limit: metadata.InstGraphMaxNodes}
+	result, err := g.asst.SearchInstAssociationGraph(kit, request)
+	if err != nil {
+		return nil, err
+	}
+
+	graph := &metadata.Graph{
+		Name:  graphInstNodeID(request.ObjectID, request.InstID),
+		Nodes: make([]metadata.GraphNode, 0, len(result.Nodes)),
+		Edges: make([]metadata.GraphEdge, 0, len(result.Edges)),
+	}
+	for _, node := range result.Nodes {
+		graph.Nodes = append(graph.Nodes, metadata.GraphNode{
+			ID:    graphInstNodeID(node.ObjectID, node.InstID),
+			Label: node.InstName,
+			Type:  node.ObjectID,
+			Attributes: map[string]string{
+				"depth":     strconv.Itoa(node.Depth),
+				"truncated": strconv.FormatBool(node.Truncated),
+			},
+		})
+	}
+	for _, asst := range result.Edges {
+		graph.Edges = append(graph.Edges, metadata.GraphEdge{
+			ID:     strconv.FormatInt(asst.ID, 10),
+			Source: graphInstNodeID(asst.ObjectID, asst.InstID),
+			Target: graphInstNodeID(asst.AsstObjectID, asst.AsstInstID),
+			Label:  asst.ObjectAsstID,
+			Type:   asst.AssociationKindID,
+		})
+	}
+	return graph, nil
+}
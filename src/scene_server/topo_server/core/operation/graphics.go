@@ -29,6 +29,10 @@ type GraphicsOperationInterface interface {
 	SelectObjectTopoGraphics(kit *rest.Kit, scopeType, scopeID string) ([]metadata.TopoGraphics, error)
 	UpdateObjectTopoGraphics(kit *rest.Kit, scopeType, scopeID string, datas []metadata.TopoGraphics) error
 
+	ExportModelGraph(kit *rest.Kit, request *metadata.ExportModelGraphRequest) (*metadata.Graph, error)
+	ExportBizTopoGraph(kit *rest.Kit, bizID int64) (*metadata.Graph, error)
+	ExportInstGraph(kit *rest.Kit, request *metadata.SearchInstAssociationGraphRequest) (*metadata.Graph, error)
+
 	SetProxy(obj ObjectOperationInterface, asst AssociationOperationInterface)
 }
 
@@ -1070,7 +1070,8 @@ func (c *commonInst) FindOriginInst(kit *rest.Kit, objID string, cond *metadata.
 			return nil, kit.CCError.New(rsp.Code, rsp.ErrMsg)
 		}
 
-		return &metadata.InstResult{Count: rsp.Data.Count, Info: mapstr.NewArrayFromMapStr(rsp.Data.Info)}, nil
+		return &metadata.InstResult{Count: rsp.Data.Count, Info: mapstr.NewArrayFromMapStr(rsp.Data.Info),
+			NextCursor: rsp.Data.NextCursor}, nil
 
 	default:
 		queryCond, err := mapstr.NewFromInterface(cond.Condition)
@@ -1078,7 +1079,9 @@ func (c *commonInst) FindOriginInst(kit *rest.Kit, objID string, cond *metadata.
 		input.Page.Start = cond.Start
 		input.Page.Limit = cond.Limit
 		input.Page.Sort = cond.Sort
+		input.Page.Cursor = cond.Cursor
 		input.Fields = strings.Split(cond.Fields, ",")
+		input.Filter = cond.Filter
 		rsp, err := c.clientSet.CoreService().Instance().ReadInstance(kit.Ctx, kit.Header, objID, input)
 		if nil != err {
 			blog.Errorf("[operation-inst] failed to request object controller, err: %s, rid: %s", err.Error(), kit.Rid)
@@ -1089,7 +1092,7 @@ func (c *commonInst) FindOriginInst(kit *rest.Kit, objID string, cond *metadata.
 			blog.Errorf("[operation-inst] failed to delete the object(%s) inst by the condition(%#v), err: %s, rid: %s", objID, cond, rsp.ErrMsg, kit.Rid)
 			return nil, kit.CCError.New(rsp.Code, rsp.ErrMsg)
 		}
-		return &metadata.InstResult{Info: rsp.Data.Info, Count: rsp.Data.Count}, nil
+		return &metadata.InstResult{Info: rsp.Data.Info, Count: rsp.Data.Count, NextCursor: rsp.Data.NextCursor}, nil
 	}
 }
 
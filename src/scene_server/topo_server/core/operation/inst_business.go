@@ -42,6 +42,9 @@ type BusinessOperationInterface interface {
 	HasHosts(kit *rest.Kit, bizID int64) (bool, error)
 	SetProxy(set SetOperationInterface, module ModuleOperationInterface, inst InstOperationInterface, obj ObjectOperationInterface)
 	GenerateAchieveBusinessName(kit *rest.Kit, bizName string) (achieveName string, err error)
+	ValidateCloneTarget(kit *rest.Kit, bizID int64) error
+	CloneBusinessTopoStages(task metadata.CloneBizTopoTask) []CloneBizTopoStage
+	RegisterCloneCreators(kit *rest.Kit, created []metadata.IamInstanceWithCreator) error
 }
 
 // NewBusinessOperation create a business instance
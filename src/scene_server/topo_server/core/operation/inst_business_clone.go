@@ -0,0 +1,686 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package operation
+
+import (
+	"strconv"
+	"time"
+
+	"configcenter/src/ac/iam"
+	"configcenter/src/common"
+	"configcenter/src/common/auditlog"
+	"configcenter/src/common/auth"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/scene_server/topo_server/core/model"
+)
+
+// cloneSkipFields 克隆实例属性值时不复制的字段, 这些字段由拓扑关系或系统维护
+var cloneSkipFields = map[string]bool{
+	common.BKAppIDField:              true,
+	common.BKParentIDField:           true,
+	common.BKSetIDField:              true,
+	common.BKModuleIDField:           true,
+	common.BKInstIDField:             true,
+	common.BKObjIDField:              true,
+	common.BKOwnerIDField:            true,
+	common.BKDefaultField:            true,
+	common.MetadataField:             true,
+	common.CreateTimeField:           true,
+	common.LastTimeField:             true,
+	common.BKSetTemplateIDField:      true,
+	common.BKSetTemplateVersionField: true,
+	common.BKServiceTemplateIDField:  true,
+	common.BKServiceCategoryIDField:  true,
+	common.HostApplyEnabledField:     true,
+	"_id":                            true,
+}
+
+// cloneInstData 生成克隆实例的数据, 不克隆属性值时只保留实例名称
+func cloneInstData(objID string, detail mapstr.MapStr, withAttributes bool) mapstr.MapStr {
+	nameField := common.GetInstNameField(objID)
+	data := mapstr.MapStr{nameField: detail[nameField]}
+	if !withAttributes {
+		return data
+	}
+	idField := common.GetInstIDField(objID)
+	for key, value := range detail {
+		if key == idField || cloneSkipFields[key] {
+			continue
+		}
+		data[key] = value
+	}
+	return data
+}
+
+// ValidateCloneTarget 克隆的目标业务只能包含内置的空闲机集群与模块, 且没有服务模板和集群模板
+func (b *business) ValidateCloneTarget(kit *rest.Kit, bizID int64) error {
+	topo, err := b.clientSet.CoreService().Mainline().SearchMainlineInstanceTopo(kit.Ctx, kit.Header, bizID, true)
+	if err != nil {
+		blog.Errorf("search mainline instance topo failed, bizID: %d, err: %v, rid: %s", bizID, err, kit.Rid)
+		return err
+	}
+	for _, child := range topo.Children {
+		defaultVal, _ := util.GetInt64ByInterface(child.Detail[common.BKDefaultField])
+		if child.ObjectID != common.BKInnerObjIDSet || defaultVal == int64(common.DefaultFlagDefaultValue) {
+			return kit.CCError.CCError(common.CCErrorTopoCloneTargetBizNotEmpty)
+		}
+	}
+
+	svcTplOpt := &metadata.ListServiceTemplateOption{BusinessID: bizID, Page: metadata.BasePage{Limit: 1}}
+	svcTpls, ccErr := b.clientSet.CoreService().Process().ListServiceTemplates(kit.Ctx, kit.Header, svcTplOpt)
+	if ccErr != nil {
+		blog.Errorf("list service templates failed, bizID: %d, err: %v, rid: %s", bizID, ccErr, kit.Rid)
+		return ccErr
+	}
+	if svcTpls.Count > 0 {
+		return kit.CCError.CCError(common.CCErrorTopoCloneTargetBizNotEmpty)
+	}
+
+	setTplOpt := metadata.ListSetTemplateOption{Page: metadata.BasePage{Limit: 1}}
+	setTpls, ccErr := b.clientSet.CoreService().SetTemplate().ListSetTemplate(kit.Ctx, kit.Header, bizID, setTplOpt)
+	if ccErr != nil {
+		blog.Errorf("list set templates failed, bizID: %d, err: %v, rid: %s", bizID, ccErr, kit.Rid)
+		return ccErr
+	}
+	if setTpls.Count > 0 {
+		return kit.CCError.CCError(common.CCErrorTopoCloneTargetBizNotEmpty)
+	}
+	return nil
+}
+
+// CloneBizTopoStage 克隆业务拓扑的一个阶段, 每个阶段在独立的事务中执行, 返回创建的需要在事务提交后注册到iam的资源.
+// Name 记录在任务的执行进度中, 任务重试时跳过已完成的阶段
+type CloneBizTopoStage struct {
+	Name string
+	Run  func(kit *rest.Kit, result *metadata.CloneBizTopoResult) ([]metadata.IamInstanceWithCreator, error)
+}
+
+// CloneBusinessTopoStages 返回将源业务的主线拓扑与模板绑定克隆到空的目标业务的各个阶段, 按顺序执行后 result 中为新旧ID映射,
+// 不会克隆主机. 后面的阶段依赖前面阶段克隆的资源, 所以某个阶段失败后不能继续执行后面的阶段, 只能在重试时从该阶段继续执行.
+func (b *business) CloneBusinessTopoStages(task metadata.CloneBizTopoTask) []CloneBizTopoStage {
+	noCreator := func(clone func(kit *rest.Kit, result *metadata.CloneBizTopoResult) error) func(*rest.Kit,
+		*metadata.CloneBizTopoResult) ([]metadata.IamInstanceWithCreator, error) {
+
+		return func(kit *rest.Kit, result *metadata.CloneBizTopoResult) ([]metadata.IamInstanceWithCreator, error) {
+			return nil, clone(kit, result)
+		}
+	}
+
+	stages := []CloneBizTopoStage{
+		{Name: "service_category", Run: noCreator(b.cloneServiceCategories)},
+		{Name: "service_template", Run: b.cloneServiceTemplates},
+		{Name: "set_template", Run: b.cloneSetTemplates},
+		{Name: "mainline_topo", Run: noCreator(func(kit *rest.Kit, result *metadata.CloneBizTopoResult) error {
+			return b.cloneMainlineTopo(kit, result, task.Option)
+		})},
+	}
+	if task.Option.WithHostApplyRules {
+		stages = append(stages, CloneBizTopoStage{Name: "host_apply_rule", Run: noCreator(b.cloneHostApplyRules)})
+	}
+	if task.Option.WithDynamicGroups {
+		stages = append(stages, CloneBizTopoStage{Name: "dynamic_group", Run: b.cloneDynamicGroups})
+	}
+	return stages
+}
+
+// RegisterCloneCreators 将克隆创建的资源注册到iam, 需要在创建资源的事务提交后调用
+func (b *business) RegisterCloneCreators(kit *rest.Kit, created []metadata.IamInstanceWithCreator) error {
+	if !auth.EnableAuthorize() {
+		return nil
+	}
+
+	for _, iamInstance := range created {
+		if _, err := b.authManager.Authorizer.RegisterResourceCreatorAction(kit.Ctx, kit.Header, iamInstance); err != nil {
+			blog.Errorf("register cloned %s %s to iam failed, err: %v, rid: %s", iamInstance.Type, iamInstance.Name,
+				err, kit.Rid)
+			return err
+		}
+	}
+	return nil
+}
+
+// cloneServiceCategories 克隆源业务自定义的服务分类, 目标业务中已存在的同名分类直接复用
+func (b *business) cloneServiceCategories(kit *rest.Kit, result *metadata.CloneBizTopoResult) error {
+	listCategories := func(bizID int64) ([]metadata.ServiceCategory, error) {
+		option := metadata.ListServiceCategoriesOption{BusinessID: bizID}
+		categories, err := b.clientSet.CoreService().Process().ListServiceCategories(kit.Ctx, kit.Header, option)
+		if err != nil {
+			blog.Errorf("list service categories failed, bizID: %d, err: %v, rid: %s", bizID, err, kit.Rid)
+			return nil, err
+		}
+		bizCategories := make([]metadata.ServiceCategory, 0)
+		for _, category := range categories.Info {
+			if category.ServiceCategory.BizID == bizID {
+				bizCategories = append(bizCategories, category.ServiceCategory)
+			}
+		}
+		return bizCategories, nil
+	}
+
+	srcCategories, err := listCategories(result.SrcBizID)
+	if err != nil {
+		return err
+	}
+	dstCategories, err := listCategories(result.BizID)
+	if err != nil {
+		return err
+	}
+	type categoryKey struct {
+		parentID int64
+		name     string
+	}
+	existCategories := make(map[categoryKey]int64)
+	for _, category := range dstCategories {
+		existCategories[categoryKey{parentID: category.ParentID, name: category.Name}] = category.ID
+	}
+
+	// 服务分类只有两级, 先克隆一级分类, 再克隆挂在其下的二级分类
+	for _, firstLevel := range []bool{true, false} {
+		for _, category := range srcCategories {
+			if (category.ParentID == 0) != firstLevel {
+				continue
+			}
+			parentID := category.ParentID
+			if newParentID, ok := result.ServiceCategories[parentID]; ok {
+				parentID = newParentID
+			}
+			if id, exist := existCategories[categoryKey{parentID: parentID, name: category.Name}]; exist {
+				result.ServiceCategories[category.ID] = id
+				continue
+			}
+			newCategory := &metadata.ServiceCategory{
+				BizID:    result.BizID,
+				Name:     category.Name,
+				ParentID: parentID,
+			}
+			created, err := b.clientSet.CoreService().Process().CreateServiceCategory(kit.Ctx, kit.Header, newCategory)
+			if err != nil {
+				blog.Errorf("clone service category %d failed, err: %v, rid: %s", category.ID, err, kit.Rid)
+				return err
+			}
+			result.ServiceCategories[category.ID] = created.ID
+		}
+	}
+	return nil
+}
+
+// cloneServiceTemplates 克隆服务模板及其进程模板
+func (b *business) cloneServiceTemplates(kit *rest.Kit, result *metadata.CloneBizTopoResult) (
+	[]metadata.IamInstanceWithCreator, error) {
+
+	option := &metadata.ListServiceTemplateOption{
+		BusinessID: result.SrcBizID,
+		Page:       metadata.BasePage{Limit: common.BKNoLimit},
+	}
+	templates, err := b.clientSet.CoreService().Process().ListServiceTemplates(kit.Ctx, kit.Header, option)
+	if err != nil {
+		blog.Errorf("list service templates failed, bizID: %d, err: %v, rid: %s", result.SrcBizID, err, kit.Rid)
+		return nil, err
+	}
+
+	created := make([]metadata.IamInstanceWithCreator, 0)
+	for _, template := range templates.Info {
+		categoryID := template.ServiceCategoryID
+		if newCategoryID, ok := result.ServiceCategories[categoryID]; ok {
+			categoryID = newCategoryID
+		}
+		newTemplate := &metadata.ServiceTemplate{
+			BizID:             result.BizID,
+			Name:              template.Name,
+			ServiceCategoryID: categoryID,
+		}
+		tpl, err := b.clientSet.CoreService().Process().CreateServiceTemplate(kit.Ctx, kit.Header, newTemplate)
+		if err != nil {
+			blog.Errorf("clone service template %d failed, err: %v, rid: %s", template.ID, err, kit.Rid)
+			return nil, err
+		}
+		result.ServiceTemplates[template.ID] = tpl.ID
+		created = append(created, metadata.IamInstanceWithCreator{
+			Type:    string(iam.BizProcessServiceTemplate),
+			ID:      strconv.FormatInt(tpl.ID, 10),
+			Name:    tpl.Name,
+			Creator: kit.User,
+		})
+	}
+
+	if len(result.ServiceTemplates) == 0 {
+		return created, nil
+	}
+	procOption := &metadata.ListProcessTemplatesOption{
+		BusinessID: result.SrcBizID,
+		Page:       metadata.BasePage{Limit: common.BKNoLimit},
+	}
+	procTemplates, err := b.clientSet.CoreService().Process().ListProcessTemplates(kit.Ctx, kit.Header, procOption)
+	if err != nil {
+		blog.Errorf("list process templates failed, bizID: %d, err: %v, rid: %s", result.SrcBizID, err, kit.Rid)
+		return nil, err
+	}
+	for _, procTemplate := range procTemplates.Info {
+		svcTplID, ok := result.ServiceTemplates[procTemplate.ServiceTemplateID]
+		if !ok {
+			continue
+		}
+		newProcTemplate := &metadata.ProcessTemplate{
+			BizID:             result.BizID,
+			ServiceTemplateID: svcTplID,
+			Property:          procTemplate.Property,
+		}
+		procTpl, err := b.clientSet.CoreService().Process().CreateProcessTemplate(kit.Ctx, kit.Header, newProcTemplate)
+		if err != nil {
+			blog.Errorf("clone process template %d failed, err: %v, rid: %s", procTemplate.ID, err, kit.Rid)
+			return nil, err
+		}
+		result.ProcessTemplates[procTemplate.ID] = procTpl.ID
+	}
+	return created, nil
+}
+
+// cloneSetTemplates 克隆集群模板、与服务模板的绑定关系以及自动同步策略
+func (b *business) cloneSetTemplates(kit *rest.Kit, result *metadata.CloneBizTopoResult) (
+	[]metadata.IamInstanceWithCreator, error) {
+
+	setTplClient := b.clientSet.CoreService().SetTemplate()
+	option := metadata.ListSetTemplateOption{Page: metadata.BasePage{Limit: common.BKNoLimit}}
+	templates, err := setTplClient.ListSetTemplate(kit.Ctx, kit.Header, result.SrcBizID, option)
+	if err != nil {
+		blog.Errorf("list set templates failed, bizID: %d, err: %v, rid: %s", result.SrcBizID, err, kit.Rid)
+		return nil, err
+	}
+
+	created := make([]metadata.IamInstanceWithCreator, 0)
+	for _, template := range templates.Info {
+		relations, err := setTplClient.ListSetServiceTemplateRelations(kit.Ctx, kit.Header, result.SrcBizID, template.ID)
+		if err != nil {
+			blog.Errorf("list set template %d relations failed, err: %v, rid: %s", template.ID, err, kit.Rid)
+			return nil, err
+		}
+		createOption := metadata.CreateSetTemplateOption{
+			Name:               template.Name,
+			ServiceTemplateIDs: make([]int64, 0),
+		}
+		for _, relation := range relations {
+			if svcTplID, ok := result.ServiceTemplates[relation.ServiceTemplateID]; ok {
+				createOption.ServiceTemplateIDs = append(createOption.ServiceTemplateIDs, svcTplID)
+			}
+		}
+		setTpl, err := setTplClient.CreateSetTemplate(kit.Ctx, kit.Header, result.BizID, createOption)
+		if err != nil {
+			blog.Errorf("clone set template %d failed, err: %v, rid: %s", template.ID, err, kit.Rid)
+			return nil, err
+		}
+		result.SetTemplates[template.ID] = setTpl.ID
+		created = append(created, metadata.IamInstanceWithCreator{
+			Type:    string(iam.BizSetTemplate),
+			ID:      strconv.FormatInt(setTpl.ID, 10),
+			Name:    setTpl.Name,
+			Creator: kit.User,
+		})
+	}
+
+	if len(result.SetTemplates) == 0 {
+		return created, nil
+	}
+	policyOption := metadata.ListSetTemplateSyncPolicyOption{
+		BizID: result.SrcBizID,
+		Page:  metadata.BasePage{Limit: common.BKNoLimit},
+	}
+	policies, err := setTplClient.ListSetTemplateSyncPolicy(kit.Ctx, kit.Header, policyOption)
+	if err != nil {
+		blog.Errorf("list set template sync policies failed, bizID: %d, err: %v, rid: %s", result.SrcBizID, err, kit.Rid)
+		return nil, err
+	}
+	for _, policy := range policies.Info {
+		setTplID, ok := result.SetTemplates[policy.SetTemplateID]
+		if !ok {
+			continue
+		}
+		updateOption := metadata.UpdateSetTemplateSyncPolicyOption{
+			Mode:                policy.Mode,
+			Cron:                policy.Cron,
+			KeepModulesWithHost: policy.KeepModulesWithHost,
+			BatchSize:           policy.BatchSize,
+			NotifyURL:           policy.NotifyURL,
+		}
+		if _, err := setTplClient.UpdateSetTemplateSyncPolicy(kit.Ctx, kit.Header, result.BizID, setTplID, updateOption); err != nil {
+			blog.Errorf("clone set template %d sync policy failed, err: %v, rid: %s", policy.SetTemplateID, err, kit.Rid)
+			return nil, err
+		}
+	}
+	return created, nil
+}
+
+// topoCloner 按主线拓扑自上而下克隆实例
+type topoCloner struct {
+	b       *business
+	kit     *rest.Kit
+	result  *metadata.CloneBizTopoResult
+	option  metadata.CloneBizTopoOption
+	objects map[string]model.Object
+	// innerModules 目标业务空闲机集群下的内置模块, default -> module id
+	innerSetID   int64
+	innerModules map[int64]int64
+}
+
+func (b *business) cloneMainlineTopo(kit *rest.Kit, result *metadata.CloneBizTopoResult, option metadata.CloneBizTopoOption) error {
+	topo, err := b.clientSet.CoreService().Mainline().SearchMainlineInstanceTopo(kit.Ctx, kit.Header, result.SrcBizID, true)
+	if err != nil {
+		blog.Errorf("search mainline instance topo failed, bizID: %d, err: %v, rid: %s", result.SrcBizID, err, kit.Rid)
+		return err
+	}
+
+	_, innerTopo, innerErr := b.GetInternalModule(kit, result.BizID)
+	if innerErr != nil {
+		blog.Errorf("get internal module failed, bizID: %d, err: %v, rid: %s", result.BizID, innerErr, kit.Rid)
+		return innerErr
+	}
+	cloner := &topoCloner{
+		b:            b,
+		kit:          kit,
+		result:       result,
+		option:       option,
+		objects:      make(map[string]model.Object),
+		innerSetID:   innerTopo.SetID,
+		innerModules: make(map[int64]int64),
+	}
+	for _, module := range innerTopo.Module {
+		cloner.innerModules[int64(module.Default)] = module.ModuleID
+	}
+
+	for _, child := range topo.Children {
+		if err := cloner.clone(child, result.BizID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *topoCloner) object(objID string) (model.Object, error) {
+	if obj, ok := c.objects[objID]; ok {
+		return obj, nil
+	}
+	obj, err := c.b.obj.FindSingleObject(c.kit, objID)
+	if err != nil {
+		blog.Errorf("find object %s failed, err: %v, rid: %s", objID, err, c.kit.Rid)
+		return nil, err
+	}
+	c.objects[objID] = obj
+	return obj, nil
+}
+
+func (c *topoCloner) clone(node *metadata.TopoInstanceNode, parentID int64) error {
+	switch node.ObjectID {
+	case common.BKInnerObjIDSet:
+		return c.cloneSet(node, parentID)
+	case common.BKInnerObjIDModule:
+		return c.cloneModule(node, parentID, 0, 0)
+	}
+
+	obj, err := c.object(node.ObjectID)
+	if err != nil {
+		return err
+	}
+	data := cloneInstData(node.ObjectID, node.Detail, c.option.WithAttributes)
+	data.Set(common.BKAppIDField, c.result.BizID)
+	data.Set(common.BKParentIDField, parentID)
+	inst, err := c.b.inst.CreateInst(c.kit, obj, data)
+	if err != nil {
+		blog.Errorf("clone %s instance %d failed, err: %v, rid: %s", node.ObjectID, node.InstanceID, err, c.kit.Rid)
+		return err
+	}
+	instID, err := inst.GetInstID()
+	if err != nil {
+		return err
+	}
+	c.result.AddInst(node.ObjectID, node.InstanceID, instID)
+
+	for _, child := range node.Children {
+		if err := c.clone(child, instID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *topoCloner) cloneSet(node *metadata.TopoInstanceNode, parentID int64) error {
+	var setID, setTplID int64
+	defaultVal, _ := util.GetInt64ByInterface(node.Detail[common.BKDefaultField])
+	if defaultVal != int64(common.DefaultFlagDefaultValue) {
+		// 内置的空闲机集群在创建业务时已存在
+		setID = c.innerSetID
+	} else {
+		obj, err := c.object(common.BKInnerObjIDSet)
+		if err != nil {
+			return err
+		}
+		data := cloneInstData(common.BKInnerObjIDSet, node.Detail, c.option.WithAttributes)
+		data.Set(common.BKParentIDField, parentID)
+		srcSetTplID, _ := util.GetInt64ByInterface(node.Detail[common.BKSetTemplateIDField])
+		if newSetTplID, ok := c.result.SetTemplates[srcSetTplID]; ok {
+			setTplID = newSetTplID
+			data.Set(common.BKSetTemplateIDField, setTplID)
+		}
+		inst, err := c.b.set.CreateSet(c.kit, obj, c.result.BizID, data)
+		if err != nil {
+			blog.Errorf("clone set %d failed, err: %v, rid: %s", node.InstanceID, err, c.kit.Rid)
+			return err
+		}
+		if setID, err = inst.GetInstID(); err != nil {
+			return err
+		}
+	}
+	c.result.AddInst(common.BKInnerObjIDSet, node.InstanceID, setID)
+
+	// 由集群模板创建的集群, 其模块已按服务模板自动创建
+	templateModules := make(map[int64]int64)
+	if setTplID != 0 {
+		query := &metadata.QueryInput{
+			Condition: map[string]interface{}{common.BKSetIDField: setID},
+			Fields:    common.BKModuleIDField + "," + common.BKServiceTemplateIDField,
+			Limit:     common.BKNoLimit,
+		}
+		modules, err := c.b.inst.FindOriginInst(c.kit, common.BKInnerObjIDModule, query)
+		if err != nil {
+			blog.Errorf("find modules of set %d failed, err: %v, rid: %s", setID, err, c.kit.Rid)
+			return err
+		}
+		for _, module := range modules.Info {
+			moduleID, _ := util.GetInt64ByInterface(module[common.BKModuleIDField])
+			svcTplID, _ := util.GetInt64ByInterface(module[common.BKServiceTemplateIDField])
+			templateModules[svcTplID] = moduleID
+		}
+	}
+
+	for _, child := range node.Children {
+		svcTplID, _ := util.GetInt64ByInterface(child.Detail[common.BKServiceTemplateIDField])
+		newSvcTplID := c.result.ServiceTemplates[svcTplID]
+		if err := c.cloneModule(child, setID, setTplID, templateModules[newSvcTplID]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cloneModule 克隆模块, existID 不为0时表示模块已随集群模板创建, 只需要同步属性值
+func (c *topoCloner) cloneModule(node *metadata.TopoInstanceNode, setID, setTplID, existID int64) error {
+	moduleID := existID
+	defaultVal, _ := util.GetInt64ByInterface(node.Detail[common.BKDefaultField])
+	if moduleID == 0 && defaultVal != int64(common.DefaultFlagDefaultValue) {
+		moduleID = c.innerModules[defaultVal]
+	}
+
+	data := cloneInstData(common.BKInnerObjIDModule, node.Detail, c.option.WithAttributes)
+	if moduleID == 0 {
+		obj, err := c.object(common.BKInnerObjIDModule)
+		if err != nil {
+			return err
+		}
+		data.Set(common.BKParentIDField, setID)
+		data.Set(common.BKSetTemplateIDField, setTplID)
+		svcTplID, _ := util.GetInt64ByInterface(node.Detail[common.BKServiceTemplateIDField])
+		if newSvcTplID, ok := c.result.ServiceTemplates[svcTplID]; ok {
+			data.Set(common.BKServiceTemplateIDField, newSvcTplID)
+		} else {
+			categoryID, _ := util.GetInt64ByInterface(node.Detail[common.BKServiceCategoryIDField])
+			if newCategoryID, ok := c.result.ServiceCategories[categoryID]; ok {
+				categoryID = newCategoryID
+			}
+			data.Set(common.BKServiceCategoryIDField, categoryID)
+		}
+		inst, err := c.b.module.CreateModule(c.kit, obj, c.result.BizID, setID, data)
+		if err != nil {
+			blog.Errorf("clone module %d failed, err: %v, rid: %s", node.InstanceID, err, c.kit.Rid)
+			return err
+		}
+		if moduleID, err = inst.GetInstID(); err != nil {
+			return err
+		}
+		data = mapstr.MapStr{}
+	} else {
+		// 已存在的模块名称由内置模块或服务模板决定, 不做修改
+		data.Remove(common.BKModuleNameField)
+		if !c.option.WithAttributes {
+			data = mapstr.MapStr{}
+		}
+	}
+	c.result.AddInst(common.BKInnerObjIDModule, node.InstanceID, moduleID)
+
+	if c.option.WithHostApplyRules {
+		enabled, _ := node.Detail[common.HostApplyEnabledField].(bool)
+		data.Set(common.HostApplyEnabledField, enabled)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	updateOption := &metadata.UpdateOption{
+		Condition: map[string]interface{}{
+			common.BKAppIDField:    c.result.BizID,
+			common.BKModuleIDField: moduleID,
+		},
+		Data: data,
+	}
+	result, err := c.b.clientSet.CoreService().Instance().UpdateInstance(c.kit.Ctx, c.kit.Header, common.BKInnerObjIDModule, updateOption)
+	if err != nil {
+		blog.Errorf("update cloned module %d failed, err: %v, rid: %s", moduleID, err, c.kit.Rid)
+		return c.kit.CCError.Error(common.CCErrCommHTTPDoRequestFailed)
+	}
+	if ccErr := result.CCError(); ccErr != nil {
+		blog.ErrorJSON("update cloned module failed, option: %s, result: %s, rid: %s", updateOption, result, c.kit.Rid)
+		return ccErr
+	}
+	return nil
+}
+
+// cloneHostApplyRules 克隆主机属性自动应用规则, 规则绑定的拓扑节点替换为克隆后的节点
+func (b *business) cloneHostApplyRules(kit *rest.Kit, result *metadata.CloneBizTopoResult) error {
+	listOption := metadata.ListHostApplyRuleOption{Page: metadata.BasePage{Limit: common.BKNoLimit}}
+	rules, err := b.clientSet.CoreService().HostApplyRule().ListHostApplyRule(kit.Ctx, kit.Header, result.SrcBizID, listOption)
+	if err != nil {
+		blog.Errorf("list host apply rules failed, bizID: %d, err: %v, rid: %s", result.SrcBizID, err, kit.Rid)
+		return err
+	}
+
+	for _, rule := range rules.Info {
+		objID, instID := metadata.NewHostApplyScope(rule.ObjID, rule.InstID, rule.ModuleID).Values()
+		newInstID, ok := result.InstID(objID, instID)
+		if !ok {
+			blog.Warnf("skip host apply rule %d, topo node %s %d is not cloned, rid: %s", rule.ID, objID, instID, kit.Rid)
+			continue
+		}
+		option := metadata.CreateHostApplyRuleOption{
+			AttributeID:   rule.AttributeID,
+			PropertyValue: rule.PropertyValue,
+			ObjID:         objID,
+			InstID:        newInstID,
+			Priority:      rule.Priority,
+		}
+		if objID == common.BKInnerObjIDModule {
+			option.ModuleID = newInstID
+		}
+		created, err := b.clientSet.CoreService().HostApplyRule().CreateHostApplyRule(kit.Ctx, kit.Header, result.BizID, option)
+		if err != nil {
+			blog.Errorf("clone host apply rule %d failed, err: %v, rid: %s", rule.ID, err, kit.Rid)
+			return err
+		}
+		result.HostApplyRules[rule.ID] = created.ID
+	}
+	return nil
+}
+
+// cloneDynamicGroups 克隆动态分组, 引用了未克隆资源的分组会被跳过
+func (b *business) cloneDynamicGroups(kit *rest.Kit, result *metadata.CloneBizTopoResult) (
+	[]metadata.IamInstanceWithCreator, error) {
+
+	query := &metadata.QueryCondition{
+		Condition: mapstr.MapStr{common.BKAppIDField: result.SrcBizID},
+		Page:      metadata.BasePage{Limit: common.BKNoLimit},
+	}
+	groups, err := b.clientSet.CoreService().Host().SearchDynamicGroup(kit.Ctx, kit.Header, query)
+	if err != nil {
+		blog.Errorf("search dynamic groups failed, bizID: %d, err: %v, rid: %s", result.SrcBizID, err, kit.Rid)
+		return nil, kit.CCError.Error(common.CCErrCommHTTPDoRequestFailed)
+	}
+	if ccErr := groups.CCError(); ccErr != nil {
+		return nil, ccErr
+	}
+
+	audit := auditlog.NewDynamicGroupAuditLog(b.clientSet.CoreService())
+	auditParam := auditlog.NewGenerateAuditCommonParameter(kit, metadata.AuditCreate)
+	created := make([]metadata.IamInstanceWithCreator, 0)
+	for _, group := range groups.Data.Info {
+		info, err := result.CloneDynamicGroupInfo(group.Info)
+		if err != nil {
+			blog.Warnf("skip dynamic group %s, err: %v, rid: %s", group.ID, err, kit.Rid)
+			continue
+		}
+		newGroup := metadata.DynamicGroup{
+			AppID:      result.BizID,
+			Name:       group.Name,
+			ObjID:      group.ObjID,
+			Info:       info,
+			CreateUser: kit.User,
+			CreateTime: time.Now().UTC(),
+		}
+		resp, err := b.clientSet.CoreService().Host().CreateDynamicGroup(kit.Ctx, kit.Header, &newGroup)
+		if err != nil {
+			blog.Errorf("clone dynamic group %s failed, err: %v, rid: %s", group.ID, err, kit.Rid)
+			return nil, kit.CCError.Error(common.CCErrCommHTTPDoRequestFailed)
+		}
+		if ccErr := resp.CCError(); ccErr != nil {
+			blog.Errorf("clone dynamic group %s failed, err: %v, rid: %s", group.ID, ccErr, kit.Rid)
+			return nil, ccErr
+		}
+		newGroup.ID = resp.Data.ID
+		result.DynamicGroups[group.ID] = newGroup.ID
+		created = append(created, metadata.IamInstanceWithCreator{
+			Type:    string(iam.BizCustomQuery),
+			ID:      newGroup.ID,
+			Name:    newGroup.Name,
+			Creator: kit.User,
+		})
+
+		auditLogs, err := audit.GenerateAuditLog(auditParam, &newGroup)
+		if err != nil {
+			blog.Errorf("generate dynamic group %s audit log failed, err: %v, rid: %s", newGroup.ID, err, kit.Rid)
+			return nil, err
+		}
+		if err := audit.SaveAuditLog(kit, auditLogs...); err != nil {
+			blog.Errorf("save dynamic group %s audit log failed, err: %v, rid: %s", newGroup.ID, err, kit.Rid)
+			return nil, err
+		}
+	}
+	return created, nil
+}
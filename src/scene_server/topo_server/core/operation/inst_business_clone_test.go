@@ -0,0 +1,47 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package operation
+
+import (
+	"reflect"
+	"testing"
+
+	"configcenter/src/common/metadata"
+)
+
+func TestCloneBusinessTopoStages(t *testing.T) {
+	b := new(business)
+	tests := []struct {
+		option metadata.CloneBizTopoOption
+		want   []string
+	}{
+		{
+			option: metadata.CloneBizTopoOption{},
+			want:   []string{"service_category", "service_template", "set_template", "mainline_topo"},
+		},
+		{
+			option: metadata.CloneBizTopoOption{WithHostApplyRules: true, WithDynamicGroups: true},
+			want: []string{"service_category", "service_template", "set_template", "mainline_topo",
+				"host_apply_rule", "dynamic_group"},
+		},
+	}
+	for _, tt := range tests {
+		names := make([]string, 0)
+		for _, stage := range b.CloneBusinessTopoStages(metadata.CloneBizTopoTask{Option: tt.option}) {
+			names = append(names, stage.Name)
+		}
+		if !reflect.DeepEqual(names, tt.want) {
+			t.Errorf("clone stages with option %+v = %v, want %v", tt.option, names, tt.want)
+		}
+	}
+}
@@ -0,0 +1,758 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package operation
+
+import (
+	"sort"
+	"strconv"
+
+	"configcenter/src/ac"
+	"configcenter/src/ac/extensions"
+	"configcenter/src/ac/iam"
+	"configcenter/src/ac/meta"
+	"configcenter/src/apimachinery"
+	"configcenter/src/common"
+	"configcenter/src/common/auth"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/condition"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+)
+
+// SchemaOperationInterface model schema operation methods
+type SchemaOperationInterface interface {
+	ExportSchema(kit *rest.Kit, option metadata.ExportModelSchemaOption) (*metadata.ModelSchema, error)
+	PlanSchema(kit *rest.Kit, option metadata.ModelSchemaOption) (*metadata.ModelSchemaPlan, error)
+	AuthorizeSchemaPlan(kit *rest.Kit, plan *metadata.ModelSchemaPlan) error
+	ApplySchema(kit *rest.Kit, plan *metadata.ModelSchemaPlan) ([]metadata.IamInstanceWithCreator, error)
+	RegisterSchemaCreators(kit *rest.Kit, created []metadata.IamInstanceWithCreator) error
+
+	SetProxy(cls ClassificationOperationInterface, obj ObjectOperationInterface, grp GroupOperationInterface,
+		attr AttributeOperationInterface, unique UniqueOperationInterface, asst AssociationOperationInterface)
+}
+
+// NewSchemaOperation create a new model schema operation instance
+func NewSchemaOperation(client apimachinery.ClientSetInterface, authManager *extensions.AuthManager) SchemaOperationInterface {
+	return &schema{
+		clientSet:   client,
+		authManager: authManager,
+	}
+}
+
+type schema struct {
+	clientSet   apimachinery.ClientSetInterface
+	authManager *extensions.AuthManager
+	cls         ClassificationOperationInterface
+	obj         ObjectOperationInterface
+	grp         GroupOperationInterface
+	attr        AttributeOperationInterface
+	unique      UniqueOperationInterface
+	asst        AssociationOperationInterface
+}
+
+func (s *schema) SetProxy(cls ClassificationOperationInterface, obj ObjectOperationInterface, grp GroupOperationInterface,
+	attr AttributeOperationInterface, unique UniqueOperationInterface, asst AssociationOperationInterface) {
+
+	s.cls = cls
+	s.obj = obj
+	s.grp = grp
+	s.attr = attr
+	s.unique = unique
+	s.asst = asst
+}
+
+// ExportSchema exports the live model of the objects as a schema, the system attributes and mainline associations are not exported.
+func (s *schema) ExportSchema(kit *rest.Kit, option metadata.ExportModelSchemaOption) (*metadata.ModelSchema, error) {
+	live, err := s.loadLiveModel(kit, option.ObjectIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &metadata.ModelSchema{
+		Classifications: make([]metadata.SchemaClassification, 0),
+		Objects:         make([]metadata.SchemaObject, 0),
+		Associations:    make([]metadata.SchemaAssociation, 0),
+	}
+
+	objIDs := make([]string, 0)
+	for objID, obj := range live.objects {
+		// all the objects except the hidden ones are exported by default
+		if len(option.ObjectIDs) == 0 && obj.object.IsHidden {
+			continue
+		}
+		objIDs = append(objIDs, objID)
+	}
+	sort.Strings(objIDs)
+
+	usedCls := make(map[string]struct{})
+	for _, objID := range objIDs {
+		obj := live.objects[objID]
+		usedCls[obj.object.ObjCls] = struct{}{}
+		result.Objects = append(result.Objects, exportSchemaObject(obj))
+	}
+
+	clsIDs := make([]string, 0)
+	for clsID := range usedCls {
+		if _, exist := live.classifications[clsID]; exist {
+			clsIDs = append(clsIDs, clsID)
+		}
+	}
+	sort.Strings(clsIDs)
+	for _, clsID := range clsIDs {
+		cls := live.classifications[clsID]
+		result.Classifications = append(result.Classifications, metadata.SchemaClassification{
+			ClassificationID:   cls.ClassificationID,
+			ClassificationName: cls.ClassificationName,
+			ClassificationIcon: cls.ClassificationIcon,
+		})
+	}
+
+	exported := make(map[string]struct{}, len(objIDs))
+	for _, objID := range objIDs {
+		exported[objID] = struct{}{}
+	}
+	asstNames := make([]string, 0)
+	for name, asst := range live.associations {
+		if asst.AsstKindID == common.AssociationKindMainline {
+			continue
+		}
+		_, srcExported := exported[asst.ObjectID]
+		_, dstExported := exported[asst.AsstObjID]
+		if srcExported && dstExported {
+			asstNames = append(asstNames, name)
+		}
+	}
+	sort.Strings(asstNames)
+	for _, name := range asstNames {
+		asst := live.associations[name]
+		result.Associations = append(result.Associations, metadata.SchemaAssociation{
+			ObjectID:             asst.ObjectID,
+			AsstKindID:           asst.AsstKindID,
+			AsstObjID:            asst.AsstObjID,
+			AssociationAliasName: asst.AssociationAliasName,
+			Mapping:              asst.Mapping,
+			OnDelete:             asst.OnDelete,
+		})
+	}
+
+	return result, nil
+}
+
+func exportSchemaObject(obj *liveObject) metadata.SchemaObject {
+	result := metadata.SchemaObject{
+		ObjectID:    obj.object.ObjectID,
+		ObjectName:  obj.object.ObjectName,
+		ObjCls:      obj.object.ObjCls,
+		ObjIcon:     obj.object.ObjIcon,
+		Description: obj.object.Description,
+		Groups:      make([]metadata.SchemaAttributeGroup, 0),
+		Attributes:  make([]metadata.SchemaAttribute, 0),
+		Uniques:     make([]metadata.SchemaUnique, 0),
+	}
+
+	groups := make([]metadata.Group, 0, len(obj.groups))
+	for _, group := range obj.groups {
+		groups = append(groups, group)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].GroupIndex < groups[j].GroupIndex
+	})
+	groupIndex := make(map[string]int, len(groups))
+	for idx, group := range groups {
+		groupIndex[group.GroupID] = idx
+		result.Groups = append(result.Groups, metadata.SchemaAttributeGroup{
+			GroupID:    group.GroupID,
+			GroupName:  group.GroupName,
+			GroupIndex: group.GroupIndex,
+			IsCollapse: group.IsCollapse,
+		})
+	}
+
+	attrs := make([]metadata.Attribute, 0, len(obj.attributes))
+	for _, attr := range obj.attributes {
+		if attr.IsSystem || attr.IsAPI {
+			continue
+		}
+		attrs = append(attrs, attr)
+	}
+	sort.Slice(attrs, func(i, j int) bool {
+		if groupIndex[attrs[i].PropertyGroup] != groupIndex[attrs[j].PropertyGroup] {
+			return groupIndex[attrs[i].PropertyGroup] < groupIndex[attrs[j].PropertyGroup]
+		}
+		return attrs[i].PropertyIndex < attrs[j].PropertyIndex
+	})
+	for _, attr := range attrs {
+		result.Attributes = append(result.Attributes, metadata.SchemaAttribute{
+			PropertyID:    attr.PropertyID,
+			PropertyName:  attr.PropertyName,
+			PropertyType:  attr.PropertyType,
+			PropertyGroup: attr.PropertyGroup,
+			IsEditable:    attr.IsEditable,
+			IsRequired:    attr.IsRequired,
+			Option:        attr.Option,
+			Unit:          attr.Unit,
+			Placeholder:   attr.Placeholder,
+			Description:   attr.Description,
+		})
+	}
+
+	for _, uniqueID := range sortedKeys(obj.uniques) {
+		result.Uniques = append(result.Uniques, metadata.SchemaUnique{
+			Keys:      splitSchemaUniqueID(uniqueID),
+			MustCheck: obj.uniques[uniqueID].MustCheck,
+		})
+	}
+	return result
+}
+
+// PlanSchema diffs the schema with the live model, returns the changes to make them the same.
+func (s *schema) PlanSchema(kit *rest.Kit, option metadata.ModelSchemaOption) (*metadata.ModelSchemaPlan, error) {
+	if rawErr := option.Schema.Validate(); rawErr.ErrCode != 0 {
+		blog.Errorf("plan model schema, but schema is invalid, err: %v, rid: %s", rawErr, kit.Rid)
+		return nil, rawErr.ToCCError(kit.CCError)
+	}
+
+	// the objects referenced by the associations are loaded to check their existence
+	objIDs := make([]string, 0)
+	for _, obj := range option.Schema.Objects {
+		objIDs = append(objIDs, obj.ObjectID)
+	}
+	for _, asst := range option.Schema.Associations {
+		objIDs = append(objIDs, asst.ObjectID, asst.AsstObjID)
+	}
+
+	live, err := s.loadLiveModel(kit, util.StrArrayUnique(objIDs))
+	if err != nil {
+		return nil, err
+	}
+
+	changes, rawErr := planModelSchema(live, option.Schema, option.Prune)
+	if rawErr.ErrCode != 0 {
+		blog.Errorf("plan model schema failed, err: %v, rid: %s", rawErr, kit.Rid)
+		return nil, rawErr.ToCCError(kit.CCError)
+	}
+	return &metadata.ModelSchemaPlan{Changes: changes}, nil
+}
+
+// AuthorizeSchemaPlan authorizes each change of the plan as the api that makes the same change, the
+// resources of the objects created by the plan are covered by the object creation.
+func (s *schema) AuthorizeSchemaPlan(kit *rest.Kit, plan *metadata.ModelSchemaPlan) error {
+	if !s.authManager.Enabled() || len(plan.Changes) == 0 {
+		return nil
+	}
+
+	objIDs := make([]string, 0)
+	for _, change := range plan.Changes {
+		if len(change.ObjectID) != 0 {
+			objIDs = append(objIDs, change.ObjectID)
+		}
+		if asstObjID, _ := change.Data.String(common.BKAsstObjIDField); len(asstObjID) != 0 {
+			objIDs = append(objIDs, asstObjID)
+		}
+	}
+	live, err := s.loadLiveModel(kit, util.StrArrayUnique(objIDs))
+	if err != nil {
+		return err
+	}
+
+	// the associated objects of the deleted associations are not in the changes
+	missing := make([]string, 0)
+	for _, change := range plan.Changes {
+		if change.Kind != metadata.SchemaKindAssociation || change.Action != metadata.SchemaChangeDelete {
+			continue
+		}
+		asstObjID := live.associations[change.ID].AsstObjID
+		if _, exist := live.objects[asstObjID]; !exist && len(asstObjID) != 0 {
+			missing = append(missing, asstObjID)
+		}
+	}
+	if len(missing) != 0 {
+		asstLive, err := s.loadLiveModel(kit, util.StrArrayUnique(missing))
+		if err != nil {
+			return err
+		}
+		for objID, obj := range asstLive.objects {
+			live.objects[objID] = obj
+		}
+	}
+
+	resources := make([]meta.ResourceAttribute, 0)
+	for _, change := range plan.Changes {
+		resources = append(resources, schemaChangeResources(live, change)...)
+	}
+	if len(resources) == 0 {
+		return nil
+	}
+
+	if err := s.authManager.AuthorizeResources(kit.Ctx, kit.Header, resources...); err != nil {
+		if err == ac.NoAuthorizeError {
+			return kit.CCError.CCError(common.CCErrCommAuthNotHavePermission)
+		}
+		blog.Errorf("authorize model schema plan failed, err: %v, rid: %s", err, kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommCheckAuthorizeFailed)
+	}
+	return nil
+}
+
+var schemaChangeActions = map[metadata.SchemaChangeAction]meta.Action{
+	metadata.SchemaChangeCreate: meta.Create,
+	metadata.SchemaChangeUpdate: meta.Update,
+	metadata.SchemaChangeDelete: meta.Delete,
+}
+
+// schemaChangeResources returns the auth resources of the change, the same as the resources parsed from
+// the apis of the models, the changes on the objects created by the plan need no more authorization.
+func schemaChangeResources(live *liveModel, change metadata.SchemaChange) []meta.ResourceAttribute {
+	action := schemaChangeActions[change.Action]
+
+	switch change.Kind {
+	case metadata.SchemaKindClassification:
+		resource := meta.ResourceAttribute{Basic: meta.Basic{Type: meta.ModelClassification, Action: action}}
+		if change.Action != metadata.SchemaChangeCreate {
+			resource.InstanceID = change.TargetID
+		}
+		return []meta.ResourceAttribute{resource}
+
+	case metadata.SchemaKindObject:
+		if change.Action != metadata.SchemaChangeCreate {
+			return []meta.ResourceAttribute{
+				{Basic: meta.Basic{Type: meta.Model, Action: action, InstanceID: change.TargetID}},
+			}
+		}
+		resource := meta.ResourceAttribute{Basic: meta.Basic{Type: meta.Model, Action: meta.Create}}
+		clsID, _ := change.Data.String(common.BKClassificationIDField)
+		if cls, exist := live.classifications[clsID]; exist {
+			resource.Layers = []meta.Item{{Type: meta.ModelClassification, InstanceID: cls.ID}}
+		}
+		return []meta.ResourceAttribute{resource}
+
+	case metadata.SchemaKindAssociation:
+		objIDs := []string{change.ObjectID}
+		if asstObjID, _ := change.Data.String(common.BKAsstObjIDField); len(asstObjID) != 0 {
+			objIDs = append(objIDs, asstObjID)
+		} else if asst, exist := live.associations[change.ID]; exist {
+			objIDs = append(objIDs, asst.AsstObjID)
+		}
+
+		resources := make([]meta.ResourceAttribute, 0)
+		for _, objID := range util.StrArrayUnique(objIDs) {
+			if obj, exist := live.objects[objID]; exist {
+				resources = append(resources, meta.ResourceAttribute{
+					Basic: meta.Basic{Type: meta.Model, Action: meta.Update, InstanceID: obj.object.ID},
+				})
+			}
+		}
+		return resources
+	}
+
+	obj, exist := live.objects[change.ObjectID]
+	if !exist {
+		return nil
+	}
+
+	var resourceType meta.ResourceType
+	switch change.Kind {
+	case metadata.SchemaKindAttributeGroup:
+		resourceType = meta.ModelAttributeGroup
+	case metadata.SchemaKindAttribute:
+		resourceType = meta.ModelAttribute
+	case metadata.SchemaKindUnique:
+		resourceType = meta.ModelUnique
+	default:
+		return nil
+	}
+
+	resource := meta.ResourceAttribute{
+		Basic:  meta.Basic{Type: resourceType, Action: action},
+		Layers: []meta.Item{{Type: meta.Model, InstanceID: obj.object.ID}},
+	}
+	if change.Action != metadata.SchemaChangeCreate {
+		resource.InstanceID = change.TargetID
+	}
+	return []meta.ResourceAttribute{resource}
+}
+
+// ApplySchema executes the changes of the plan in order, it should be run in a transaction so that the model
+// is not left half applied when one of the changes failed. returns the created resources that need to be
+// registered to iam after the transaction is committed.
+func (s *schema) ApplySchema(kit *rest.Kit, plan *metadata.ModelSchemaPlan) ([]metadata.IamInstanceWithCreator, error) {
+	created := make([]metadata.IamInstanceWithCreator, 0)
+	for _, change := range plan.Changes {
+		instance, err := s.applySchemaChange(kit, change)
+		if err != nil {
+			blog.Errorf("apply model schema change %s failed, err: %v, rid: %s", change.String(), err, kit.Rid)
+			return nil, err
+		}
+		if instance != nil {
+			created = append(created, *instance)
+		}
+	}
+	return created, nil
+}
+
+// RegisterSchemaCreators registers the classifications and objects created by the schema to iam.
+func (s *schema) RegisterSchemaCreators(kit *rest.Kit, created []metadata.IamInstanceWithCreator) error {
+	if !auth.EnableAuthorize() {
+		return nil
+	}
+
+	for _, iamInstance := range created {
+		if _, err := s.authManager.Authorizer.RegisterResourceCreatorAction(kit.Ctx, kit.Header, iamInstance); err != nil {
+			blog.Errorf("register created %s %s to iam failed, err: %v, rid: %s", iamInstance.Type, iamInstance.Name,
+				err, kit.Rid)
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *schema) applySchemaChange(kit *rest.Kit, change metadata.SchemaChange) (*metadata.IamInstanceWithCreator, error) {
+	switch change.Kind {
+	case metadata.SchemaKindClassification:
+		return s.applyClassificationChange(kit, change)
+	case metadata.SchemaKindObject:
+		return s.applyObjectChange(kit, change)
+	case metadata.SchemaKindAttributeGroup:
+		return nil, s.applyGroupChange(kit, change)
+	case metadata.SchemaKindAttribute:
+		return nil, s.applyAttributeChange(kit, change)
+	case metadata.SchemaKindUnique:
+		return nil, s.applyUniqueChange(kit, change)
+	case metadata.SchemaKindAssociation:
+		return nil, s.applyAssociationChange(kit, change)
+	}
+	return nil, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, string(change.Kind))
+}
+
+// changedData returns the updated fields of the change
+func changedData(change metadata.SchemaChange) mapstr.MapStr {
+	data := mapstr.New()
+	for _, field := range change.Fields {
+		data[field] = change.Data[field]
+	}
+	return data
+}
+
+// newCreatedInstance returns the created resource to be registered to iam
+func newCreatedInstance(kit *rest.Kit, typ iam.TypeID, id int64, name string) *metadata.IamInstanceWithCreator {
+	return &metadata.IamInstanceWithCreator{
+		Type:    string(typ),
+		ID:      strconv.FormatInt(id, 10),
+		Name:    name,
+		Creator: kit.User,
+	}
+}
+
+func (s *schema) applyClassificationChange(kit *rest.Kit, change metadata.SchemaChange) (*metadata.IamInstanceWithCreator, error) {
+	switch change.Action {
+	case metadata.SchemaChangeCreate:
+		cls, err := s.cls.CreateClassification(kit, change.Data)
+		if err != nil {
+			return nil, err
+		}
+		return newCreatedInstance(kit, iam.SysModelGroup, cls.Classify().ID, cls.Classify().ClassificationName), nil
+	case metadata.SchemaChangeUpdate:
+		return nil, s.cls.UpdateClassification(kit, changedData(change), change.TargetID, condition.CreateCondition())
+	}
+	return nil, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, change.String())
+}
+
+func (s *schema) applyObjectChange(kit *rest.Kit, change metadata.SchemaChange) (*metadata.IamInstanceWithCreator, error) {
+	switch change.Action {
+	case metadata.SchemaChangeCreate:
+		obj, err := s.obj.CreateObject(kit, false, change.Data)
+		if err != nil {
+			return nil, err
+		}
+		return newCreatedInstance(kit, iam.SysModel, obj.Object().ID, obj.Object().ObjectName), nil
+	case metadata.SchemaChangeUpdate:
+		return nil, s.obj.UpdateObject(kit, changedData(change), change.TargetID)
+	}
+	return nil, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, change.String())
+}
+
+func (s *schema) applyGroupChange(kit *rest.Kit, change metadata.SchemaChange) error {
+	switch change.Action {
+	case metadata.SchemaChangeCreate:
+		_, err := s.grp.CreateObjectGroup(kit, change.Data, 0)
+		return err
+	case metadata.SchemaChangeUpdate:
+		groupID := change.TargetID
+		if groupID == 0 {
+			// the default group of the object created by this plan
+			live, err := s.loadLiveModel(kit, []string{change.ObjectID})
+			if err != nil {
+				return err
+			}
+			if obj, exist := live.objects[change.ObjectID]; exist {
+				groupID = obj.groups[change.ID].ID
+			}
+		}
+
+		cond := &metadata.UpdateGroupCondition{}
+		cond.Condition.ID = groupID
+		name, _ := change.Data.String(metadata.GroupFieldGroupName)
+		index, _ := change.Data.Int64(metadata.GroupFieldGroupIndex)
+		isCollapse, _ := change.Data.Bool(metadata.GroupFieldIsCollapse)
+		cond.Data.Name = &name
+		cond.Data.Index = &index
+		cond.Data.IsCollapse = &isCollapse
+		return s.grp.UpdateObjectGroup(kit, cond)
+	case metadata.SchemaChangeDelete:
+		return s.grp.DeleteObjectGroup(kit, change.TargetID)
+	}
+	return kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, change.String())
+}
+
+func (s *schema) applyAttributeChange(kit *rest.Kit, change metadata.SchemaChange) error {
+	switch change.Action {
+	case metadata.SchemaChangeCreate:
+		_, err := s.attr.CreateObjectAttribute(kit, change.Data, 0)
+		return err
+	case metadata.SchemaChangeUpdate:
+		data := changedData(change)
+		if data.Exists(common.BKPropertyGroupField) {
+			groupID, _ := data.String(common.BKPropertyGroupField)
+			index, _ := change.Data.Int64(common.BKPropertyIndexField)
+			attrGroup := metadata.PropertyGroupObjectAtt{}
+			attrGroup.Condition.OwnerID = kit.SupplierAccount
+			attrGroup.Condition.ObjectID = change.ObjectID
+			attrGroup.Condition.PropertyID = change.ID
+			attrGroup.Data.PropertyGroupID = groupID
+			attrGroup.Data.PropertyIndex = int(index)
+			if err := s.grp.UpdateObjectAttributeGroup(kit, []metadata.PropertyGroupObjectAtt{attrGroup}, 0); err != nil {
+				return err
+			}
+			data.Remove(common.BKPropertyGroupField)
+		}
+		if len(data) == 0 {
+			return nil
+		}
+		return s.attr.UpdateObjectAttribute(kit, data, change.TargetID, 0)
+	case metadata.SchemaChangeDelete:
+		return s.deleteAttribute(kit, change.TargetID)
+	}
+	return kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, change.String())
+}
+
+// deleteAttribute deletes the attribute and the host apply rules of it
+func (s *schema) deleteAttribute(kit *rest.Kit, attrID int64) error {
+	listRuleOption := metadata.ListHostApplyRuleOption{
+		AttributeIDs: []int64{attrID},
+		Page: metadata.BasePage{
+			Limit: common.BKNoLimit,
+		},
+	}
+	ruleResult, err := s.clientSet.CoreService().HostApplyRule().ListHostApplyRule(kit.Ctx, kit.Header, 0, listRuleOption)
+	if err != nil {
+		blog.Errorf("delete attribute %d, but list host apply rules failed, err: %v, rid: %s", attrID, err, kit.Rid)
+		return err
+	}
+
+	cond := condition.CreateCondition()
+	cond.Field(metadata.AttributeFieldID).Eq(attrID)
+	if err := s.attr.DeleteObjectAttribute(kit, cond, 0); err != nil {
+		return err
+	}
+
+	if len(ruleResult.Info) == 0 {
+		return nil
+	}
+	deleteRuleOption := metadata.DeleteHostApplyRuleOption{
+		RuleIDs: make([]int64, 0),
+	}
+	for _, rule := range ruleResult.Info {
+		deleteRuleOption.RuleIDs = append(deleteRuleOption.RuleIDs, rule.ID)
+	}
+	if err := s.clientSet.CoreService().HostApplyRule().DeleteHostApplyRule(kit.Ctx, kit.Header, 0, deleteRuleOption); err != nil {
+		blog.Errorf("delete attribute %d, but delete host apply rules failed, err: %v, rid: %s", attrID, err, kit.Rid)
+		return err
+	}
+	return nil
+}
+
+func (s *schema) applyUniqueChange(kit *rest.Kit, change metadata.SchemaChange) error {
+	// mainline object's unique can not be changed.
+	isMainline, err := s.asst.IsMainlineObject(kit, change.ObjectID)
+	if err != nil {
+		return err
+	}
+	if isMainline && change.ObjectID != common.BKInnerObjIDHost {
+		return kit.CCError.Error(common.CCErrorTopoMainlineObjectCanNotBeChanged)
+	}
+
+	if change.Action == metadata.SchemaChangeDelete {
+		return s.unique.Delete(kit, change.ObjectID, uint64(change.TargetID))
+	}
+
+	// the keys are resolved after the attributes are created
+	live, err := s.loadLiveModel(kit, []string{change.ObjectID})
+	if err != nil {
+		return err
+	}
+	obj, exist := live.objects[change.ObjectID]
+	if !exist {
+		return kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, change.ObjectID)
+	}
+
+	keys := make([]metadata.UniqueKey, 0)
+	for _, propertyID := range splitSchemaUniqueID(change.ID) {
+		attr, exist := obj.attributes[propertyID]
+		if !exist {
+			return kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, change.ObjectID+".uniques."+propertyID)
+		}
+		keys = append(keys, metadata.UniqueKey{Kind: metadata.UniqueKeyKindProperty, ID: uint64(attr.ID)})
+	}
+	mustCheck, _ := change.Data.Bool("must_check")
+
+	switch change.Action {
+	case metadata.SchemaChangeCreate:
+		request := &metadata.CreateUniqueRequest{ObjID: change.ObjectID, MustCheck: mustCheck, Keys: keys}
+		_, err := s.unique.Create(kit, change.ObjectID, request)
+		return err
+	case metadata.SchemaChangeUpdate:
+		uniqueID := uint64(change.TargetID)
+		if uniqueID == 0 {
+			// the default unique of the object created by this plan
+			uniqueID = obj.uniques[change.ID].ID
+		}
+		request := &metadata.UpdateUniqueRequest{MustCheck: mustCheck, Keys: keys}
+		return s.unique.Update(kit, change.ObjectID, uniqueID, request)
+	}
+	return kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, change.String())
+}
+
+func (s *schema) applyAssociationChange(kit *rest.Kit, change metadata.SchemaChange) error {
+	switch change.Action {
+	case metadata.SchemaChangeCreate:
+		asst := new(metadata.Association)
+		if err := change.Data.MarshalJSONInto(asst); err != nil {
+			blog.Errorf("create association %s, but parse data failed, err: %v, rid: %s", change.ID, err, kit.Rid)
+			return kit.CCError.CCError(common.CCErrCommJSONUnmarshalFailed)
+		}
+		_, err := s.asst.CreateCommonAssociation(kit, asst)
+		return err
+	case metadata.SchemaChangeUpdate:
+		return s.asst.UpdateAssociation(kit, changedData(change), change.TargetID)
+	case metadata.SchemaChangeDelete:
+		return s.asst.DeleteAssociationWithPreCheck(kit, change.TargetID)
+	}
+	return kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, change.String())
+}
+
+// loadLiveModel loads all the classifications, and the objects with their groups, attributes, uniques
+// and associations, all the objects are loaded if objIDs is empty.
+func (s *schema) loadLiveModel(kit *rest.Kit, objIDs []string) (*liveModel, error) {
+	live := newLiveModel()
+
+	clsRsp, err := s.clientSet.CoreService().Model().ReadModelClassification(kit.Ctx, kit.Header, &metadata.QueryCondition{})
+	if err != nil {
+		blog.Errorf("load live model, but read classifications failed, err: %v, rid: %s", err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommHTTPDoRequestFailed)
+	}
+	if !clsRsp.Result {
+		return nil, kit.CCError.New(clsRsp.Code, clsRsp.ErrMsg)
+	}
+	for _, cls := range clsRsp.Data.Info {
+		live.classifications[cls.ClassificationID] = cls
+	}
+
+	objCond := mapstr.New()
+	if len(objIDs) != 0 {
+		objCond[common.BKObjIDField] = mapstr.MapStr{common.BKDBIN: objIDs}
+	}
+	objRsp, err := s.clientSet.CoreService().Model().ReadModel(kit.Ctx, kit.Header, &metadata.QueryCondition{Condition: objCond})
+	if err != nil {
+		blog.Errorf("load live model, but read objects failed, err: %v, rid: %s", err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommHTTPDoRequestFailed)
+	}
+	if !objRsp.Result {
+		return nil, kit.CCError.New(objRsp.Code, objRsp.ErrMsg)
+	}
+	if len(objRsp.Data.Info) == 0 {
+		return live, nil
+	}
+
+	loadedIDs := make([]string, 0, len(objRsp.Data.Info))
+	for _, info := range objRsp.Data.Info {
+		live.objects[info.Spec.ObjectID] = newLiveObject(info.Spec)
+		loadedIDs = append(loadedIDs, info.Spec.ObjectID)
+	}
+
+	// only the global shared groups and attributes are managed by the schema
+	cond := mapstr.MapStr{common.BKObjIDField: mapstr.MapStr{common.BKDBIN: loadedIDs}}
+	util.AddModelBizIDConditon(cond, 0)
+
+	grpRsp, err := s.clientSet.CoreService().Model().ReadAttributeGroupByCondition(kit.Ctx, kit.Header, metadata.QueryCondition{Condition: cond})
+	if err != nil {
+		blog.Errorf("load live model, but read attribute groups failed, err: %v, rid: %s", err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommHTTPDoRequestFailed)
+	}
+	if !grpRsp.Result {
+		return nil, kit.CCError.New(grpRsp.Code, grpRsp.ErrMsg)
+	}
+	for _, group := range grpRsp.Data.Info {
+		if obj, exist := live.objects[group.ObjectID]; exist {
+			obj.groups[group.GroupID] = group
+		}
+	}
+
+	attrRsp, err := s.clientSet.CoreService().Model().ReadModelAttrByCondition(kit.Ctx, kit.Header, &metadata.QueryCondition{Condition: cond})
+	if err != nil {
+		blog.Errorf("load live model, but read attributes failed, err: %v, rid: %s", err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommHTTPDoRequestFailed)
+	}
+	if !attrRsp.Result {
+		return nil, kit.CCError.New(attrRsp.Code, attrRsp.ErrMsg)
+	}
+	for _, attr := range attrRsp.Data.Info {
+		if obj, exist := live.objects[attr.ObjectID]; exist {
+			obj.attributes[attr.PropertyID] = attr
+		}
+	}
+
+	objCond = mapstr.MapStr{common.BKObjIDField: mapstr.MapStr{common.BKDBIN: loadedIDs}}
+	uniqueRsp, err := s.clientSet.CoreService().Model().ReadModelAttrUnique(kit.Ctx, kit.Header, metadata.QueryCondition{Condition: objCond})
+	if err != nil {
+		blog.Errorf("load live model, but read uniques failed, err: %v, rid: %s", err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrTopoObjectUniqueSearchFailed)
+	}
+	if !uniqueRsp.Result {
+		return nil, kit.CCError.New(uniqueRsp.Code, uniqueRsp.ErrMsg)
+	}
+	for _, unique := range uniqueRsp.Data.Info {
+		obj, exist := live.objects[unique.ObjID]
+		if !exist {
+			continue
+		}
+		if uniqueID, ok := obj.uniqueID(unique); ok {
+			obj.uniques[uniqueID] = unique
+		}
+	}
+
+	asstRsp, err := s.clientSet.CoreService().Association().ReadModelAssociation(kit.Ctx, kit.Header, &metadata.QueryCondition{Condition: objCond})
+	if err != nil {
+		blog.Errorf("load live model, but read associations failed, err: %v, rid: %s", err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommHTTPDoRequestFailed)
+	}
+	if !asstRsp.Result {
+		return nil, kit.CCError.New(asstRsp.Code, asstRsp.ErrMsg)
+	}
+	for _, asst := range asstRsp.Data.Info {
+		live.associations[asst.AssociationName] = asst
+	}
+
+	return live, nil
+}
@@ -0,0 +1,562 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package operation
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"configcenter/src/common"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/scene_server/topo_server/core/model"
+)
+
+// liveModel is the live model that a schema is diffed with
+type liveModel struct {
+	classifications map[string]metadata.Classification
+	objects         map[string]*liveObject
+	// associations of the loaded objects, bk_obj_asst_id -> association
+	associations map[string]metadata.Association
+}
+
+type liveObject struct {
+	object     metadata.Object
+	groups     map[string]metadata.Group
+	attributes map[string]metadata.Attribute
+	// uniques schema unique id -> unique
+	uniques map[string]metadata.ObjectUnique
+	// isNew is true if the object is to be created by the plan
+	isNew bool
+}
+
+func newLiveModel() *liveModel {
+	return &liveModel{
+		classifications: make(map[string]metadata.Classification),
+		objects:         make(map[string]*liveObject),
+		associations:    make(map[string]metadata.Association),
+	}
+}
+
+func newLiveObject(object metadata.Object) *liveObject {
+	return &liveObject{
+		object:     object,
+		groups:     make(map[string]metadata.Group),
+		attributes: make(map[string]metadata.Attribute),
+		uniques:    make(map[string]metadata.ObjectUnique),
+	}
+}
+
+// newCreatedObject returns the object with the default group, instance name attribute and unique
+// which are created along with a new object, so that the schema is diffed with them as well.
+func newCreatedObject(objID string) *liveObject {
+	obj := newLiveObject(metadata.Object{ObjectID: objID})
+	obj.isNew = true
+	groupID := model.NewGroupID(true)
+	obj.groups[groupID] = metadata.Group{GroupID: groupID, GroupName: "Default", GroupIndex: -1, IsDefault: true}
+	obj.attributes[common.BKInstNameField] = metadata.Attribute{
+		ObjectID:      objID,
+		PropertyID:    common.BKInstNameField,
+		PropertyType:  common.FieldTypeSingleChar,
+		PropertyGroup: groupID,
+		IsPre:         true,
+	}
+	obj.uniques[common.BKInstNameField] = metadata.ObjectUnique{ObjID: objID, MustCheck: true}
+	return obj
+}
+
+// uniqueID returns the schema unique id of a live unique
+func (o *liveObject) uniqueID(unique metadata.ObjectUnique) (string, bool) {
+	attrIDs := make(map[uint64]string, len(o.attributes))
+	for _, attr := range o.attributes {
+		attrIDs[uint64(attr.ID)] = attr.PropertyID
+	}
+
+	keys := make([]string, 0, len(unique.Keys))
+	for _, key := range unique.Keys {
+		propertyID, exist := attrIDs[key.ID]
+		if key.Kind != metadata.UniqueKeyKindProperty || !exist {
+			return "", false
+		}
+		keys = append(keys, propertyID)
+	}
+	return metadata.SchemaUniqueID(keys), true
+}
+
+func invalidSchemaParam(param string) errors.RawErrorInfo {
+	return errors.RawErrorInfo{
+		ErrCode: common.CCErrCommParamsInvalid,
+		Args:    []interface{}{param},
+	}
+}
+
+// planModelSchema diffs the schema with the live model, returns the changes in dependency order:
+// classifications, objects, groups, attributes, uniques and associations are created or updated
+// first, then the pruned associations, uniques, attributes and groups are deleted.
+func planModelSchema(live *liveModel, schema metadata.ModelSchema, prune bool) ([]metadata.SchemaChange, errors.RawErrorInfo) {
+	changes := make([]metadata.SchemaChange, 0)
+
+	for _, cls := range schema.Classifications {
+		data := mapstr.MapStr{
+			common.BKClassificationIDField:   cls.ClassificationID,
+			common.BKClassificationNameField: cls.ClassificationName,
+			common.BKClassificationIconField: cls.ClassificationIcon,
+		}
+		liveCls, exist := live.classifications[cls.ClassificationID]
+		if !exist {
+			changes = append(changes, newSchemaChange(metadata.SchemaChangeCreate, metadata.SchemaKindClassification, "", cls.ClassificationID, 0, nil, data))
+			continue
+		}
+
+		fields := make([]string, 0)
+		if liveCls.ClassificationName != cls.ClassificationName {
+			fields = append(fields, common.BKClassificationNameField)
+		}
+		if len(cls.ClassificationIcon) != 0 && liveCls.ClassificationIcon != cls.ClassificationIcon {
+			fields = append(fields, common.BKClassificationIconField)
+		}
+		if len(fields) != 0 {
+			changes = append(changes, newSchemaChange(metadata.SchemaChangeUpdate, metadata.SchemaKindClassification, "", cls.ClassificationID, liveCls.ID, fields, data))
+		}
+	}
+
+	declaredCls := make(map[string]struct{}, len(schema.Classifications))
+	for _, cls := range schema.Classifications {
+		declaredCls[cls.ClassificationID] = struct{}{}
+	}
+
+	objects := make(map[string]*liveObject, len(schema.Objects))
+	for _, obj := range schema.Objects {
+		if _, exist := declaredCls[obj.ObjCls]; !exist {
+			if _, exist := live.classifications[obj.ObjCls]; !exist {
+				return nil, invalidSchemaParam(obj.ObjectID + "." + common.BKClassificationIDField)
+			}
+		}
+
+		data := mapstr.MapStr{
+			common.BKObjIDField:            obj.ObjectID,
+			common.BKObjNameField:          obj.ObjectName,
+			common.BKClassificationIDField: obj.ObjCls,
+			common.BKObjIconField:          obj.ObjIcon,
+			common.BKDescriptionField:      obj.Description,
+		}
+		liveObj, exist := live.objects[obj.ObjectID]
+		if !exist {
+			liveObj = newCreatedObject(obj.ObjectID)
+			changes = append(changes, newSchemaChange(metadata.SchemaChangeCreate, metadata.SchemaKindObject, obj.ObjectID, obj.ObjectID, 0, nil, data))
+		} else if !liveObj.object.IsPre {
+			// the classification of an object can not be changed
+			if liveObj.object.ObjCls != obj.ObjCls {
+				return nil, invalidSchemaParam(obj.ObjectID + "." + common.BKClassificationIDField)
+			}
+
+			fields := make([]string, 0)
+			if liveObj.object.ObjectName != obj.ObjectName {
+				fields = append(fields, common.BKObjNameField)
+			}
+			if len(obj.ObjIcon) != 0 && liveObj.object.ObjIcon != obj.ObjIcon {
+				fields = append(fields, common.BKObjIconField)
+			}
+			if liveObj.object.Description != obj.Description {
+				fields = append(fields, common.BKDescriptionField)
+			}
+			if len(fields) != 0 {
+				changes = append(changes, newSchemaChange(metadata.SchemaChangeUpdate, metadata.SchemaKindObject, obj.ObjectID, obj.ObjectID, liveObj.object.ID, fields, data))
+			}
+		}
+		objects[obj.ObjectID] = liveObj
+	}
+
+	for _, obj := range schema.Objects {
+		groupChanges, rawErr := planSchemaGroups(objects[obj.ObjectID], obj)
+		if rawErr.ErrCode != 0 {
+			return nil, rawErr
+		}
+		changes = append(changes, groupChanges...)
+	}
+
+	for _, obj := range schema.Objects {
+		attrChanges, rawErr := planSchemaAttributes(objects[obj.ObjectID], obj)
+		if rawErr.ErrCode != 0 {
+			return nil, rawErr
+		}
+		changes = append(changes, attrChanges...)
+	}
+
+	for _, obj := range schema.Objects {
+		uniqueChanges, rawErr := planSchemaUniques(objects[obj.ObjectID], obj)
+		if rawErr.ErrCode != 0 {
+			return nil, rawErr
+		}
+		changes = append(changes, uniqueChanges...)
+	}
+
+	asstChanges, rawErr := planSchemaAssociations(live, objects, schema.Associations)
+	if rawErr.ErrCode != 0 {
+		return nil, rawErr
+	}
+	changes = append(changes, asstChanges...)
+
+	if prune {
+		changes = append(changes, planSchemaPrune(live, objects, schema)...)
+	}
+	return changes, errors.RawErrorInfo{}
+}
+
+func planSchemaGroups(liveObj *liveObject, obj metadata.SchemaObject) ([]metadata.SchemaChange, errors.RawErrorInfo) {
+	changes := make([]metadata.SchemaChange, 0)
+	for _, group := range obj.Groups {
+		data := mapstr.MapStr{
+			common.BKObjIDField:           obj.ObjectID,
+			metadata.GroupFieldGroupID:    group.GroupID,
+			metadata.GroupFieldGroupName:  group.GroupName,
+			metadata.GroupFieldGroupIndex: group.GroupIndex,
+			metadata.GroupFieldIsCollapse: group.IsCollapse,
+		}
+		liveGroup, exist := liveObj.groups[group.GroupID]
+		if !exist {
+			changes = append(changes, newSchemaChange(metadata.SchemaChangeCreate, metadata.SchemaKindAttributeGroup, obj.ObjectID, group.GroupID, 0, nil, data))
+			continue
+		}
+
+		fields := make([]string, 0)
+		if liveGroup.GroupName != group.GroupName {
+			fields = append(fields, metadata.GroupFieldGroupName)
+		}
+		if liveGroup.GroupIndex != group.GroupIndex {
+			fields = append(fields, metadata.GroupFieldGroupIndex)
+		}
+		if liveGroup.IsCollapse != group.IsCollapse {
+			fields = append(fields, metadata.GroupFieldIsCollapse)
+		}
+		if len(fields) != 0 {
+			changes = append(changes, newSchemaChange(metadata.SchemaChangeUpdate, metadata.SchemaKindAttributeGroup, obj.ObjectID, group.GroupID, liveGroup.ID, fields, data))
+		}
+	}
+	return changes, errors.RawErrorInfo{}
+}
+
+func planSchemaAttributes(liveObj *liveObject, obj metadata.SchemaObject) ([]metadata.SchemaChange, errors.RawErrorInfo) {
+	groups := make(map[string]struct{})
+	for groupID := range liveObj.groups {
+		groups[groupID] = struct{}{}
+	}
+	for _, group := range obj.Groups {
+		groups[group.GroupID] = struct{}{}
+	}
+
+	changes := make([]metadata.SchemaChange, 0)
+	for _, attr := range obj.Attributes {
+		if len(attr.PropertyGroup) == 0 {
+			attr.PropertyGroup = model.NewGroupID(true)
+		}
+		if _, exist := groups[attr.PropertyGroup]; !exist {
+			return nil, invalidSchemaParam(obj.ObjectID + "." + attr.PropertyID + "." + common.BKPropertyGroupField)
+		}
+
+		data := mapstr.MapStr{
+			common.BKObjIDField:                obj.ObjectID,
+			common.BKPropertyIDField:           attr.PropertyID,
+			common.BKPropertyNameField:         attr.PropertyName,
+			common.BKPropertyTypeField:         attr.PropertyType,
+			common.BKPropertyGroupField:        attr.PropertyGroup,
+			metadata.AttributeFieldIsEditable:  attr.IsEditable,
+			metadata.AttributeFieldIsRequired:  attr.IsRequired,
+			metadata.AttributeFieldOption:      attr.Option,
+			metadata.AttributeFieldUnit:        attr.Unit,
+			metadata.AttributeFieldPlaceHolder: attr.Placeholder,
+			metadata.AttributeFieldDescription: attr.Description,
+		}
+		liveAttr, exist := liveObj.attributes[attr.PropertyID]
+		if !exist {
+			changes = append(changes, newSchemaChange(metadata.SchemaChangeCreate, metadata.SchemaKindAttribute, obj.ObjectID, attr.PropertyID, 0, nil, data))
+			continue
+		}
+
+		// the preset attributes are maintained by cmdb itself
+		if liveAttr.IsPre {
+			continue
+		}
+
+		if liveAttr.PropertyType != attr.PropertyType {
+			return nil, invalidSchemaParam(obj.ObjectID + "." + attr.PropertyID + "." + common.BKPropertyTypeField)
+		}
+
+		fields := make([]string, 0)
+		if liveAttr.PropertyName != attr.PropertyName {
+			fields = append(fields, common.BKPropertyNameField)
+		}
+		if liveAttr.PropertyGroup != attr.PropertyGroup {
+			// the attribute keeps its index when it is moved to another group
+			fields = append(fields, common.BKPropertyGroupField)
+			data[common.BKPropertyIndexField] = liveAttr.PropertyIndex
+		}
+		if liveAttr.IsEditable != attr.IsEditable {
+			fields = append(fields, metadata.AttributeFieldIsEditable)
+		}
+		if liveAttr.IsRequired != attr.IsRequired {
+			fields = append(fields, metadata.AttributeFieldIsRequired)
+		}
+		if !isSameSchemaOption(liveAttr.Option, attr.Option) {
+			fields = append(fields, metadata.AttributeFieldOption)
+		}
+		if liveAttr.Unit != attr.Unit {
+			fields = append(fields, metadata.AttributeFieldUnit)
+		}
+		if liveAttr.Placeholder != attr.Placeholder {
+			fields = append(fields, metadata.AttributeFieldPlaceHolder)
+		}
+		if liveAttr.Description != attr.Description {
+			fields = append(fields, metadata.AttributeFieldDescription)
+		}
+		if len(fields) != 0 {
+			changes = append(changes, newSchemaChange(metadata.SchemaChangeUpdate, metadata.SchemaKindAttribute, obj.ObjectID, attr.PropertyID, liveAttr.ID, fields, data))
+		}
+	}
+	return changes, errors.RawErrorInfo{}
+}
+
+// isSameSchemaOption compares the attribute options by their json, empty option equals to nil
+func isSameSchemaOption(liveOption, option interface{}) bool {
+	if isEmptySchemaOption(liveOption) && isEmptySchemaOption(option) {
+		return true
+	}
+	liveJs, err := json.Marshal(liveOption)
+	if err != nil {
+		return false
+	}
+	js, err := json.Marshal(option)
+	if err != nil {
+		return false
+	}
+	return string(liveJs) == string(js)
+}
+
+func isEmptySchemaOption(option interface{}) bool {
+	switch val := option.(type) {
+	case nil:
+		return true
+	case string:
+		return len(val) == 0
+	}
+	return false
+}
+
+func planSchemaUniques(liveObj *liveObject, obj metadata.SchemaObject) ([]metadata.SchemaChange, errors.RawErrorInfo) {
+	attributes := make(map[string]struct{})
+	for propertyID := range liveObj.attributes {
+		attributes[propertyID] = struct{}{}
+	}
+	for _, attr := range obj.Attributes {
+		attributes[attr.PropertyID] = struct{}{}
+	}
+
+	changes := make([]metadata.SchemaChange, 0)
+	for _, unique := range obj.Uniques {
+		for _, key := range unique.Keys {
+			if _, exist := attributes[key]; !exist {
+				return nil, invalidSchemaParam(obj.ObjectID + ".uniques." + key)
+			}
+		}
+
+		uniqueID := metadata.SchemaUniqueID(unique.Keys)
+		data := mapstr.MapStr{
+			"keys":       unique.Keys,
+			"must_check": unique.MustCheck,
+		}
+		liveUnique, exist := liveObj.uniques[uniqueID]
+		if !exist {
+			changes = append(changes, newSchemaChange(metadata.SchemaChangeCreate, metadata.SchemaKindUnique, obj.ObjectID, uniqueID, 0, nil, data))
+			continue
+		}
+
+		if !liveUnique.Ispre && liveUnique.MustCheck != unique.MustCheck {
+			changes = append(changes, newSchemaChange(metadata.SchemaChangeUpdate, metadata.SchemaKindUnique, obj.ObjectID, uniqueID, int64(liveUnique.ID), []string{"must_check"}, data))
+		}
+	}
+	return changes, errors.RawErrorInfo{}
+}
+
+func planSchemaAssociations(live *liveModel, objects map[string]*liveObject, associations []metadata.SchemaAssociation) (
+	[]metadata.SchemaChange, errors.RawErrorInfo) {
+
+	changes := make([]metadata.SchemaChange, 0)
+	for _, asst := range associations {
+		for _, objID := range []string{asst.ObjectID, asst.AsstObjID} {
+			if _, exist := objects[objID]; exist {
+				continue
+			}
+			if _, exist := live.objects[objID]; !exist {
+				return nil, invalidSchemaParam(asst.AssociationName() + "." + common.BKObjIDField)
+			}
+		}
+
+		if len(asst.OnDelete) == 0 {
+			asst.OnDelete = metadata.NoAction
+		}
+		name := asst.AssociationName()
+		data := mapstr.MapStr{
+			common.AssociationObjAsstIDField:   name,
+			common.BKObjIDField:                asst.ObjectID,
+			common.AssociationKindIDField:      asst.AsstKindID,
+			common.BKAsstObjIDField:            asst.AsstObjID,
+			metadata.AssociationFieldAliasName: asst.AssociationAliasName,
+			metadata.AssociationFieldMapping:   asst.Mapping,
+			metadata.AssociationFieldOnDelete:  asst.OnDelete,
+		}
+		liveAsst, exist := live.associations[name]
+		if !exist {
+			changes = append(changes, newSchemaChange(metadata.SchemaChangeCreate, metadata.SchemaKindAssociation, asst.ObjectID, name, 0, nil, data))
+			continue
+		}
+
+		if liveAsst.IsPre != nil && *liveAsst.IsPre {
+			continue
+		}
+
+		// the mapping of an association can not be changed
+		if liveAsst.Mapping != asst.Mapping {
+			return nil, invalidSchemaParam(name + "." + metadata.AssociationFieldMapping)
+		}
+
+		fields := make([]string, 0)
+		if liveAsst.AssociationAliasName != asst.AssociationAliasName {
+			fields = append(fields, metadata.AssociationFieldAliasName)
+		}
+		if liveAsst.OnDelete != asst.OnDelete {
+			fields = append(fields, metadata.AssociationFieldOnDelete)
+		}
+		if len(fields) != 0 {
+			changes = append(changes, newSchemaChange(metadata.SchemaChangeUpdate, metadata.SchemaKindAssociation, asst.ObjectID, name, liveAsst.ID, fields, data))
+		}
+	}
+	return changes, errors.RawErrorInfo{}
+}
+
+// planSchemaPrune deletes the resources of the declared objects not in the schema, the preset ones,
+// the default group and the must check uniques are kept, since cmdb depends on them.
+func planSchemaPrune(live *liveModel, objects map[string]*liveObject, schema metadata.ModelSchema) []metadata.SchemaChange {
+	changes := make([]metadata.SchemaChange, 0)
+
+	declaredAssts := make(map[string]struct{}, len(schema.Associations))
+	for _, asst := range schema.Associations {
+		declaredAssts[asst.AssociationName()] = struct{}{}
+	}
+	asstNames := make([]string, 0)
+	for name, asst := range live.associations {
+		if _, exist := declaredAssts[name]; exist {
+			continue
+		}
+		if asst.AsstKindID == common.AssociationKindMainline || (asst.IsPre != nil && *asst.IsPre) {
+			continue
+		}
+		_, srcDeclared := objects[asst.ObjectID]
+		_, dstDeclared := objects[asst.AsstObjID]
+		if !srcDeclared || !dstDeclared {
+			continue
+		}
+		asstNames = append(asstNames, name)
+	}
+	sort.Strings(asstNames)
+	for _, name := range asstNames {
+		asst := live.associations[name]
+		changes = append(changes, newSchemaChange(metadata.SchemaChangeDelete, metadata.SchemaKindAssociation, asst.ObjectID, name, asst.ID, nil, nil))
+	}
+
+	for _, obj := range schema.Objects {
+		liveObj := objects[obj.ObjectID]
+		declared := make(map[string]struct{}, len(obj.Uniques))
+		for _, unique := range obj.Uniques {
+			declared[metadata.SchemaUniqueID(unique.Keys)] = struct{}{}
+		}
+		for _, uniqueID := range sortedKeys(liveObj.uniques) {
+			unique := liveObj.uniques[uniqueID]
+			if _, exist := declared[uniqueID]; exist || unique.Ispre || unique.MustCheck {
+				continue
+			}
+			changes = append(changes, newSchemaChange(metadata.SchemaChangeDelete, metadata.SchemaKindUnique, obj.ObjectID, uniqueID, int64(unique.ID), nil, nil))
+		}
+	}
+
+	for _, obj := range schema.Objects {
+		liveObj := objects[obj.ObjectID]
+		declared := make(map[string]struct{}, len(obj.Attributes))
+		for _, attr := range obj.Attributes {
+			declared[attr.PropertyID] = struct{}{}
+		}
+		for _, propertyID := range sortedKeys(liveObj.attributes) {
+			attr := liveObj.attributes[propertyID]
+			if _, exist := declared[propertyID]; exist || attr.IsPre {
+				continue
+			}
+			changes = append(changes, newSchemaChange(metadata.SchemaChangeDelete, metadata.SchemaKindAttribute, obj.ObjectID, propertyID, attr.ID, nil, nil))
+		}
+	}
+
+	for _, obj := range schema.Objects {
+		liveObj := objects[obj.ObjectID]
+		declared := make(map[string]struct{}, len(obj.Groups))
+		for _, group := range obj.Groups {
+			declared[group.GroupID] = struct{}{}
+		}
+		for _, groupID := range sortedKeys(liveObj.groups) {
+			group := liveObj.groups[groupID]
+			if _, exist := declared[groupID]; exist || group.IsPre || group.IsDefault {
+				continue
+			}
+			changes = append(changes, newSchemaChange(metadata.SchemaChangeDelete, metadata.SchemaKindAttributeGroup, obj.ObjectID, groupID, group.ID, nil, nil))
+		}
+	}
+	return changes
+}
+
+// splitSchemaUniqueID returns the property ids of a schema unique id
+func splitSchemaUniqueID(uniqueID string) []string {
+	return strings.Split(uniqueID, ",")
+}
+
+func sortedKeys(m interface{}) []string {
+	keys := make([]string, 0)
+	switch val := m.(type) {
+	case map[string]metadata.ObjectUnique:
+		for key := range val {
+			keys = append(keys, key)
+		}
+	case map[string]metadata.Attribute:
+		for key := range val {
+			keys = append(keys, key)
+		}
+	case map[string]metadata.Group:
+		for key := range val {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func newSchemaChange(action metadata.SchemaChangeAction, kind metadata.SchemaResourceKind, objID, id string,
+	targetID int64, fields []string, data mapstr.MapStr) metadata.SchemaChange {
+
+	return metadata.SchemaChange{
+		Action:   action,
+		Kind:     kind,
+		ObjectID: objID,
+		ID:       id,
+		TargetID: targetID,
+		Fields:   fields,
+		Data:     data,
+	}
+}
@@ -0,0 +1,171 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package operation
+
+import (
+	"testing"
+
+	"configcenter/src/ac/meta"
+	"configcenter/src/common"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+)
+
+func newTestLiveModel() *liveModel {
+	live := newLiveModel()
+	live.classifications["bk_network"] = metadata.Classification{ID: 1, ClassificationID: "bk_network", ClassificationName: "network"}
+
+	host := newLiveObject(metadata.Object{ID: 1, ObjectID: common.BKInnerObjIDHost, ObjCls: "bk_host_manage", IsPre: true})
+	live.objects[common.BKInnerObjIDHost] = host
+
+	router := newLiveObject(metadata.Object{ID: 2, ObjectID: "router", ObjectName: "router", ObjCls: "bk_network"})
+	router.groups["default"] = metadata.Group{ID: 10, GroupID: "default", GroupName: "Default", GroupIndex: -1, IsDefault: true}
+	router.groups["extra"] = metadata.Group{ID: 11, GroupID: "extra", GroupName: "extra", GroupIndex: 1}
+	router.attributes[common.BKInstNameField] = metadata.Attribute{ID: 20, PropertyID: common.BKInstNameField, PropertyType: common.FieldTypeSingleChar, PropertyGroup: "default", IsPre: true}
+	router.attributes["vendor"] = metadata.Attribute{ID: 21, PropertyID: "vendor", PropertyName: "vendor", PropertyType: common.FieldTypeSingleChar, PropertyGroup: "default", IsEditable: true}
+	router.attributes["model"] = metadata.Attribute{ID: 22, PropertyID: "model", PropertyName: "model", PropertyType: common.FieldTypeSingleChar, PropertyGroup: "extra", PropertyIndex: 3}
+	router.uniques[common.BKInstNameField] = metadata.ObjectUnique{ID: 30, ObjID: "router", MustCheck: true}
+	live.objects["router"] = router
+	return live
+}
+
+func schemaChangeStrings(changes []metadata.SchemaChange) []string {
+	result := make([]string, 0, len(changes))
+	for _, change := range changes {
+		result = append(result, change.String())
+	}
+	return result
+}
+
+func TestPlanModelSchemaCreate(t *testing.T) {
+	schema := metadata.ModelSchema{
+		Objects: []metadata.SchemaObject{{
+			ObjectID:   "switch",
+			ObjectName: "switch",
+			ObjCls:     "bk_network",
+			Groups:     []metadata.SchemaAttributeGroup{{GroupID: "port", GroupName: "port", GroupIndex: 1}},
+			Attributes: []metadata.SchemaAttribute{
+				{PropertyID: common.BKInstNameField, PropertyName: "name", PropertyType: common.FieldTypeSingleChar},
+				{PropertyID: "port_num", PropertyName: "port num", PropertyType: common.FieldTypeInt, PropertyGroup: "port"},
+			},
+			Uniques: []metadata.SchemaUnique{{Keys: []string{"port_num"}}},
+		}},
+		Associations: []metadata.SchemaAssociation{{ObjectID: "switch", AsstKindID: "connect", AsstObjID: common.BKInnerObjIDHost, Mapping: metadata.OneToManyMapping}},
+	}
+
+	changes, rawErr := planModelSchema(newTestLiveModel(), schema, true)
+	if rawErr.ErrCode != 0 {
+		t.Fatalf("plan model schema failed, err: %v", rawErr)
+	}
+
+	want := []string{
+		"create object switch",
+		"create attribute_group switch.port",
+		"create attribute switch.port_num",
+		"create unique switch.port_num",
+		"create association switch.switch_connect_host",
+	}
+	got := schemaChangeStrings(changes)
+	if len(got) != len(want) {
+		t.Fatalf("unexpected changes: %v, want: %v", got, want)
+	}
+	for idx := range want {
+		if got[idx] != want[idx] {
+			t.Errorf("change %d is %s, want %s", idx, got[idx], want[idx])
+		}
+	}
+	if changes[4].Data[metadata.AssociationFieldOnDelete] != metadata.NoAction {
+		t.Errorf("association on delete should be none by default, got %v", changes[4].Data[metadata.AssociationFieldOnDelete])
+	}
+}
+
+func TestPlanModelSchemaUpdateAndPrune(t *testing.T) {
+	schema := metadata.ModelSchema{
+		Objects: []metadata.SchemaObject{{
+			ObjectID:   "router",
+			ObjectName: "router",
+			ObjCls:     "bk_network",
+			Attributes: []metadata.SchemaAttribute{
+				{PropertyID: "vendor", PropertyName: "vendor", PropertyType: common.FieldTypeSingleChar, IsEditable: true},
+				{PropertyID: "model", PropertyName: "router model", PropertyType: common.FieldTypeSingleChar},
+			},
+		}},
+	}
+
+	changes, rawErr := planModelSchema(newTestLiveModel(), schema, false)
+	if rawErr.ErrCode != 0 {
+		t.Fatalf("plan model schema failed, err: %v", rawErr)
+	}
+	if len(changes) != 1 || changes[0].String() != "update attribute router.model" || changes[0].TargetID != 22 {
+		t.Fatalf("unexpected changes: %v", schemaChangeStrings(changes))
+	}
+	if len(changes[0].Fields) != 2 || changes[0].Data[common.BKPropertyIndexField] != int64(3) {
+		t.Errorf("moved attribute should keep its index, got change: %#v", changes[0])
+	}
+
+	changes, rawErr = planModelSchema(newTestLiveModel(), schema, true)
+	if rawErr.ErrCode != 0 {
+		t.Fatalf("plan model schema failed, err: %v", rawErr)
+	}
+	got := schemaChangeStrings(changes)
+	if len(got) != 2 || got[1] != "delete attribute_group router.extra" {
+		t.Fatalf("unexpected pruned changes: %v", got)
+	}
+
+	schema.Objects[0].Attributes[0].PropertyType = common.FieldTypeInt
+	if _, rawErr := planModelSchema(newTestLiveModel(), schema, false); rawErr.ErrCode != common.CCErrCommParamsInvalid {
+		t.Errorf("change attribute type should be rejected, got: %v", rawErr)
+	}
+}
+
+func TestSchemaChangeResources(t *testing.T) {
+	live := newTestLiveModel()
+	live.associations["router_connect_host"] = metadata.Association{
+		ID: 40, AssociationName: "router_connect_host", ObjectID: "router", AsstObjID: common.BKInnerObjIDHost,
+	}
+
+	changes := []metadata.SchemaChange{
+		{Action: metadata.SchemaChangeCreate, Kind: metadata.SchemaKindObject, ObjectID: "switch", ID: "switch",
+			Data: mapstr.MapStr{common.BKClassificationIDField: "bk_network"}},
+		// the attribute of the created object is covered by the object creation
+		{Action: metadata.SchemaChangeCreate, Kind: metadata.SchemaKindAttribute, ObjectID: "switch", ID: "port_num"},
+		{Action: metadata.SchemaChangeUpdate, Kind: metadata.SchemaKindAttribute, ObjectID: "router", ID: "vendor", TargetID: 21},
+		{Action: metadata.SchemaChangeDelete, Kind: metadata.SchemaKindAttributeGroup, ObjectID: "router", ID: "extra", TargetID: 11},
+		{Action: metadata.SchemaChangeDelete, Kind: metadata.SchemaKindAssociation, ObjectID: "router", ID: "router_connect_host", TargetID: 40},
+	}
+	expects := [][]meta.ResourceAttribute{
+		{{Basic: meta.Basic{Type: meta.Model, Action: meta.Create},
+			Layers: []meta.Item{{Type: meta.ModelClassification, InstanceID: 1}}}},
+		nil,
+		{{Basic: meta.Basic{Type: meta.ModelAttribute, Action: meta.Update, InstanceID: 21},
+			Layers: []meta.Item{{Type: meta.Model, InstanceID: 2}}}},
+		{{Basic: meta.Basic{Type: meta.ModelAttributeGroup, Action: meta.Delete, InstanceID: 11},
+			Layers: []meta.Item{{Type: meta.Model, InstanceID: 2}}}},
+		{{Basic: meta.Basic{Type: meta.Model, Action: meta.Update, InstanceID: 2}},
+			{Basic: meta.Basic{Type: meta.Model, Action: meta.Update, InstanceID: 1}}},
+	}
+
+	for idx, change := range changes {
+		resources := schemaChangeResources(live, change)
+		if len(resources) != len(expects[idx]) {
+			t.Errorf("change %s got resources %+v, expect %+v", change.String(), resources, expects[idx])
+			continue
+		}
+		for i := range resources {
+			if resources[i].Basic != expects[idx][i].Basic || len(resources[i].Layers) != len(expects[idx][i].Layers) ||
+				(len(resources[i].Layers) != 0 && resources[i].Layers[0] != expects[idx][i].Layers[0]) {
+				t.Errorf("change %s got resource %+v, expect %+v", change.String(), resources[i], expects[idx][i])
+			}
+		}
+	}
+}
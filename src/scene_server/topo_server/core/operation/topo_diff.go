@@ -0,0 +1,483 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package operation
+
+import (
+	"sort"
+	"strings"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+)
+
+// topoDiffMaxAuditLogs the max number of audit logs replayed to rebuild the history topology
+const topoDiffMaxAuditLogs = 100000
+
+type topoNodeKey struct {
+	objID  string
+	instID int64
+}
+
+// topoSnapshot is the flat view of the business mainline topology, the host count of each node is the number of
+// distinct hosts in its subtree, which is maintained when the host transfer audit logs are reverted.
+type topoSnapshot struct {
+	bizID   int64
+	nodes   map[topoNodeKey]*metadata.TopoDiffNode
+	parents map[topoNodeKey]topoNodeKey
+	// parentObjs mainline object id -> its parent object id
+	parentObjs map[string]string
+}
+
+func newTopoSnapshot(bizID int64, topo []*metadata.TopoInstRst, parentObjs map[string]string) *topoSnapshot {
+	s := &topoSnapshot{
+		bizID:      bizID,
+		nodes:      make(map[topoNodeKey]*metadata.TopoDiffNode),
+		parents:    make(map[topoNodeKey]topoNodeKey),
+		parentObjs: parentObjs,
+	}
+
+	var walk func(parent *topoNodeKey, node *metadata.TopoInstRst)
+	walk = func(parent *topoNodeKey, node *metadata.TopoInstRst) {
+		key := topoNodeKey{objID: node.ObjID, instID: node.InstID}
+		s.nodes[key] = &metadata.TopoDiffNode{
+			ObjectID:          node.ObjID,
+			InstID:            node.InstID,
+			InstName:          node.InstName,
+			Default:           node.Default,
+			HostCount:         node.HostCount,
+			ServiceTemplateID: node.ServiceTemplateID,
+			SetTemplateID:     node.SetTemplateID,
+		}
+		if parent != nil {
+			s.parents[key] = *parent
+		}
+		for _, child := range node.Child {
+			walk(&key, child)
+		}
+	}
+	for _, node := range topo {
+		walk(nil, node)
+	}
+	return s
+}
+
+func (s *topoSnapshot) root() topoNodeKey {
+	return topoNodeKey{objID: common.BKInnerObjIDApp, instID: s.bizID}
+}
+
+// revert undo the change recorded in the audit log, the logs must be reverted from the newest to the oldest.
+func (s *topoSnapshot) revert(auditLog *metadata.AuditLog) {
+	switch detail := auditLog.OperationDetail.(type) {
+	case *metadata.InstanceOpDetail:
+		if detail.Details == nil {
+			return
+		}
+		instID, err := util.GetInt64ByInterface(auditLog.ResourceID)
+		if err != nil {
+			return
+		}
+		s.revertInstance(auditLog.Action, detail.ModelID, instID, detail.Details.PreData)
+	case *metadata.HostTransferOpDetail:
+		s.revertHostTransfer(detail.PreData, detail.CurData)
+	}
+}
+
+func (s *topoSnapshot) revertInstance(action metadata.ActionType, objID string, instID int64, preData mapstr.MapStr) {
+	if _, isMainline := s.parentObjs[objID]; !isMainline {
+		return
+	}
+
+	key := topoNodeKey{objID: objID, instID: instID}
+	switch action {
+	case metadata.AuditCreate:
+		delete(s.nodes, key)
+		delete(s.parents, key)
+	case metadata.AuditDelete:
+		s.setNode(key, preData, 0)
+	case metadata.AuditUpdate, metadata.AuditArchive, metadata.AuditRecover:
+		if node, exist := s.nodes[key]; exist {
+			s.setNode(key, preData, node.HostCount)
+		}
+	}
+}
+
+func (s *topoSnapshot) setNode(key topoNodeKey, data mapstr.MapStr, hostCount int64) {
+	if len(data) == 0 {
+		return
+	}
+
+	node := &metadata.TopoDiffNode{
+		ObjectID:  key.objID,
+		InstID:    key.instID,
+		InstName:  util.GetStrByInterface(data[metadata.GetInstNameFieldName(key.objID)]),
+		HostCount: hostCount,
+	}
+	node.Default, _ = util.GetIntByInterface(data[common.BKDefaultField])
+
+	parent := topoNodeKey{objID: s.parentObjs[key.objID]}
+	switch key.objID {
+	case common.BKInnerObjIDModule:
+		node.ServiceTemplateID, _ = util.GetInt64ByInterface(data[common.BKServiceTemplateIDField])
+		node.SetTemplateID, _ = util.GetInt64ByInterface(data[common.BKSetTemplateIDField])
+		parent.instID, _ = util.GetInt64ByInterface(data[common.BKSetIDField])
+	case common.BKInnerObjIDSet:
+		node.SetTemplateID, _ = util.GetInt64ByInterface(data[common.BKSetTemplateIDField])
+		parent.instID, _ = util.GetInt64ByInterface(data[common.BKParentIDField])
+		// the built-in idle set is the child of business
+		if node.Default == common.DefaultResSetFlag {
+			parent.objID = common.BKInnerObjIDApp
+		}
+	default:
+		parent.instID, _ = util.GetInt64ByInterface(data[common.BKParentIDField])
+	}
+
+	s.nodes[key] = node
+	s.parents[key] = parent
+}
+
+// hostNodes returns the nodes in this business that contain the host with the topology
+func (s *topoSnapshot) hostNodes(topo metadata.HostBizTopo) map[topoNodeKey]bool {
+	nodes := make(map[topoNodeKey]bool)
+	if topo.BizID != s.bizID {
+		return nodes
+	}
+
+	nodes[s.root()] = true
+	withAncestors := func(key topoNodeKey) {
+		for depth := 0; depth <= len(s.nodes); depth++ {
+			if _, exist := s.nodes[key]; !exist || nodes[key] {
+				return
+			}
+			nodes[key] = true
+			parent, exist := s.parents[key]
+			if !exist {
+				return
+			}
+			key = parent
+		}
+	}
+	for _, set := range topo.Set {
+		withAncestors(topoNodeKey{objID: common.BKInnerObjIDSet, instID: set.SetID})
+		for _, module := range set.Module {
+			withAncestors(topoNodeKey{objID: common.BKInnerObjIDModule, instID: module.ModuleID})
+		}
+	}
+	return nodes
+}
+
+// revertHostTransfer move the host from the nodes it was transferred to back to the nodes before transfer
+func (s *topoSnapshot) revertHostTransfer(pre, cur metadata.HostBizTopo) {
+	preNodes, curNodes := s.hostNodes(pre), s.hostNodes(cur)
+	for key := range curNodes {
+		if node, exist := s.nodes[key]; exist && !preNodes[key] && node.HostCount > 0 {
+			node.HostCount--
+		}
+	}
+	for key := range preNodes {
+		if node, exist := s.nodes[key]; exist && !curNodes[key] {
+			node.HostCount++
+		}
+	}
+}
+
+// fill fills the instance name path and template names of all nodes
+func (s *topoSnapshot) fill(serviceTemplates, setTemplates map[int64]string) {
+	root := s.root()
+	for key, node := range s.nodes {
+		path := make([]string, 0)
+		for depth := 0; key != root && depth <= len(s.nodes); depth++ {
+			current, exist := s.nodes[key]
+			if !exist {
+				break
+			}
+			path = append(path, current.InstName)
+			if key, exist = s.parents[key]; !exist {
+				break
+			}
+		}
+		for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+			path[i], path[j] = path[j], path[i]
+		}
+		node.Path = path
+		node.ServiceTemplateName = serviceTemplates[node.ServiceTemplateID]
+		node.SetTemplateName = setTemplates[node.SetTemplateID]
+	}
+}
+
+// matchKey returns the key to match the nodes of two snapshots, the nodes of different businesses are matched by
+// object and instance name path, the nodes of the same business are matched by instance id.
+func (s *topoSnapshot) matchKey(key topoNodeKey, byPath bool) string {
+	if !byPath {
+		return key.objID + "\n" + util.GetStrByInterface(key.instID)
+	}
+	return key.objID + "\n" + strings.Join(s.nodes[key].Path, "\n")
+}
+
+// diffTopoSnapshot compare the base and target snapshots, the snapshots must have been filled.
+func diffTopoSnapshot(base, target *topoSnapshot, byPath bool, result *metadata.TopoDiffResult) {
+	baseNodes := make(map[string]topoNodeKey)
+	for key := range base.nodes {
+		baseNodes[base.matchKey(key, byPath)] = key
+	}
+
+	result.Added = make([]metadata.TopoDiffNode, 0)
+	result.Removed = make([]metadata.TopoDiffNode, 0)
+	result.Changed = make([]metadata.TopoNodeChange, 0)
+	matched := make(map[string]bool)
+	for targetKey, targetNode := range target.nodes {
+		matchKey := target.matchKey(targetKey, byPath)
+		baseKey, exist := baseNodes[matchKey]
+		if !exist {
+			result.Added = append(result.Added, *targetNode)
+			continue
+		}
+		matched[matchKey] = true
+
+		baseNode := base.nodes[baseKey]
+		fields := make([]string, 0)
+		if !byPath {
+			if baseNode.InstName != targetNode.InstName {
+				fields = append(fields, common.BKInstNameField)
+			}
+			if base.parents[baseKey] != target.parents[targetKey] {
+				fields = append(fields, common.BKParentIDField)
+			}
+		}
+		if byPath && baseNode.ServiceTemplateName != targetNode.ServiceTemplateName ||
+			!byPath && baseNode.ServiceTemplateID != targetNode.ServiceTemplateID {
+			fields = append(fields, common.BKServiceTemplateIDField)
+		}
+		if byPath && baseNode.SetTemplateName != targetNode.SetTemplateName ||
+			!byPath && baseNode.SetTemplateID != targetNode.SetTemplateID {
+			fields = append(fields, common.BKSetTemplateIDField)
+		}
+		if baseNode.HostCount != targetNode.HostCount {
+			fields = append(fields, "host_count")
+		}
+		if len(fields) > 0 {
+			result.Changed = append(result.Changed, metadata.TopoNodeChange{
+				Fields: fields,
+				Base:   *baseNode,
+				Target: *targetNode,
+			})
+		}
+	}
+	for matchKey, key := range baseNodes {
+		if !matched[matchKey] {
+			result.Removed = append(result.Removed, *base.nodes[key])
+		}
+	}
+
+	sortTopoDiffNodes(result.Added)
+	sortTopoDiffNodes(result.Removed)
+	sort.Slice(result.Changed, func(i, j int) bool {
+		return topoDiffNodeLess(&result.Changed[i].Target, &result.Changed[j].Target)
+	})
+}
+
+func topoDiffNodeLess(a, b *metadata.TopoDiffNode) bool {
+	pathA, pathB := strings.Join(a.Path, "/"), strings.Join(b.Path, "/")
+	if pathA != pathB {
+		return pathA < pathB
+	}
+	if a.ObjectID != b.ObjectID {
+		return a.ObjectID < b.ObjectID
+	}
+	return a.InstID < b.InstID
+}
+
+func sortTopoDiffNodes(nodes []metadata.TopoDiffNode) {
+	sort.Slice(nodes, func(i, j int) bool {
+		return topoDiffNodeLess(&nodes[i], &nodes[j])
+	})
+}
+
+// DiffBusinessTopo compare the mainline topology of the business with another business, or with the topology of the
+// business at the time in the past which is rebuilt by reverting the topology audit logs since then.
+func (assoc *association) DiffBusinessTopo(kit *rest.Kit, bizID int64, request *metadata.TopoDiffRequest) (
+	*metadata.TopoDiffResult, error) {
+
+	parentObjs, err := assoc.mainlineParentObjects(kit)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &metadata.TopoDiffResult{
+		Base:   metadata.TopoDiffSide{BizID: bizID},
+		Target: metadata.TopoDiffSide{BizID: bizID},
+	}
+
+	topo, err := assoc.searchTopoDiffTopo(kit, bizID)
+	if err != nil {
+		return nil, err
+	}
+	serviceTemplates, setTemplates, err := assoc.getTopoDiffTemplateNames(kit, bizID)
+	if err != nil {
+		return nil, err
+	}
+	base := newTopoSnapshot(bizID, topo, parentObjs)
+
+	if request.TargetBizID != 0 {
+		targetTopo, err := assoc.searchTopoDiffTopo(kit, request.TargetBizID)
+		if err != nil {
+			return nil, err
+		}
+		targetSvcTemplates, targetSetTemplates, err := assoc.getTopoDiffTemplateNames(kit, request.TargetBizID)
+		if err != nil {
+			return nil, err
+		}
+		target := newTopoSnapshot(request.TargetBizID, targetTopo, parentObjs)
+		base.fill(serviceTemplates, setTemplates)
+		target.fill(targetSvcTemplates, targetSetTemplates)
+		result.Target.BizID = request.TargetBizID
+		diffTopoSnapshot(base, target, true, result)
+		return result, nil
+	}
+
+	target := newTopoSnapshot(bizID, topo, parentObjs)
+	result.Base.Time = request.Time
+	result.ReplayedAuditCount, err = assoc.revertTopoSnapshot(kit, base, request.Time)
+	if err != nil {
+		return nil, err
+	}
+	base.fill(serviceTemplates, setTemplates)
+	target.fill(serviceTemplates, setTemplates)
+	diffTopoSnapshot(base, target, false, result)
+	return result, nil
+}
+
+// mainlineParentObjects returns the map of mainline object id to its parent object id, host is not included
+func (assoc *association) mainlineParentObjects(kit *rest.Kit) (map[string]string, error) {
+	cond := &metadata.QueryCondition{
+		Condition: map[string]interface{}{common.AssociationKindIDField: common.AssociationKindMainline},
+	}
+	rsp, err := assoc.clientSet.CoreService().Association().ReadModelAssociation(kit.Ctx, kit.Header, cond)
+	if err != nil {
+		blog.Errorf("search mainline association failed, err: %v, rid: %s", err, kit.Rid)
+		return nil, err
+	}
+	if err := rsp.CCError(); err != nil {
+		blog.Errorf("search mainline association failed, err: %v, rid: %s", err, kit.Rid)
+		return nil, err
+	}
+
+	parentObjs := make(map[string]string)
+	for _, asst := range rsp.Data.Info {
+		if asst.ObjectID == common.BKInnerObjIDHost {
+			continue
+		}
+		parentObjs[asst.ObjectID] = asst.AsstObjID
+	}
+	return parentObjs, nil
+}
+
+func (assoc *association) searchTopoDiffTopo(kit *rest.Kit, bizID int64) ([]*metadata.TopoInstRst, error) {
+	topo, err := assoc.SearchMainlineAssociationInstTopo(kit, common.BKInnerObjIDApp, bizID, true, true)
+	if err != nil {
+		blog.Errorf("search business %d topo failed, err: %v, rid: %s", bizID, err, kit.Rid)
+		return nil, err
+	}
+	if len(topo) == 0 {
+		blog.Errorf("business %d is not found, rid: %s", bizID, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommNotFound)
+	}
+	return topo, nil
+}
+
+func (assoc *association) getTopoDiffTemplateNames(kit *rest.Kit, bizID int64) (map[int64]string,
+	map[int64]string, error) {
+
+	svcOption := &metadata.ListServiceTemplateOption{
+		BusinessID: bizID,
+		Page:       metadata.BasePage{Limit: common.BKNoLimit},
+	}
+	svcTemplates, err := assoc.clientSet.CoreService().Process().ListServiceTemplates(kit.Ctx, kit.Header, svcOption)
+	if err != nil {
+		blog.Errorf("list service templates failed, bizID: %d, err: %v, rid: %s", bizID, err, kit.Rid)
+		return nil, nil, err
+	}
+	serviceTemplates := make(map[int64]string)
+	for _, template := range svcTemplates.Info {
+		serviceTemplates[template.ID] = template.Name
+	}
+
+	setOption := metadata.ListSetTemplateOption{Page: metadata.BasePage{Limit: common.BKNoLimit}}
+	setTpls, err := assoc.clientSet.CoreService().SetTemplate().ListSetTemplate(kit.Ctx, kit.Header, bizID, setOption)
+	if err != nil {
+		blog.Errorf("list set templates failed, bizID: %d, err: %v, rid: %s", bizID, err, kit.Rid)
+		return nil, nil, err
+	}
+	setTemplates := make(map[int64]string)
+	for _, template := range setTpls.Info {
+		setTemplates[template.ID] = template.Name
+	}
+	return serviceTemplates, setTemplates, nil
+}
+
+// revertTopoSnapshot revert the topology audit logs of the business since the time from the newest to the oldest
+func (assoc *association) revertTopoSnapshot(kit *rest.Kit, snapshot *topoSnapshot, since string) (int64, error) {
+	cond := mapstr.MapStr{
+		common.BKAppIDField:         snapshot.bizID,
+		common.BKOperationTimeField: mapstr.MapStr{common.BKDBGTE: since},
+		common.BKDBOR: []mapstr.MapStr{
+			{
+				common.BKResourceTypeField: mapstr.MapStr{common.BKDBIN: []metadata.ResourceType{
+					metadata.SetRes, metadata.ModuleRes, metadata.MainlineInstanceRes}},
+			},
+			{
+				common.BKResourceTypeField: metadata.HostRes,
+				common.BKActionField: mapstr.MapStr{common.BKDBIN: []metadata.ActionType{
+					metadata.AuditTransferHostModule, metadata.AuditAssignHost, metadata.AuditUnassignHost}},
+			},
+		},
+	}
+	query := metadata.QueryCondition{
+		Condition: cond,
+		Page: metadata.BasePage{
+			Sort:   "-" + common.BKFieldID,
+			Limit:  common.BKMaxPageSize,
+			Cursor: metadata.CursorFirstPage,
+		},
+	}
+
+	var count int64
+	for {
+		rsp, err := assoc.clientSet.CoreService().Audit().SearchAuditLog(kit.Ctx, kit.Header, query)
+		if err != nil {
+			blog.Errorf("search topo audit logs failed, cond: %v, err: %v, rid: %s", cond, err, kit.Rid)
+			return 0, err
+		}
+		if err := rsp.CCError(); err != nil {
+			blog.Errorf("search topo audit logs failed, cond: %v, err: %v, rid: %s", cond, err, kit.Rid)
+			return 0, err
+		}
+
+		for index := range rsp.Data.Info {
+			snapshot.revert(&rsp.Data.Info[index])
+		}
+		count += int64(len(rsp.Data.Info))
+		if count > topoDiffMaxAuditLogs {
+			blog.Errorf("too many topo audit logs of business %d since %s, rid: %s", snapshot.bizID, since, kit.Rid)
+			return 0, kit.CCError.CCError(common.CCErrorTopoDiffAuditLogExceedLimit)
+		}
+
+		if rsp.Data.NextCursor == "" || len(rsp.Data.Info) == 0 {
+			return count, nil
+		}
+		query.Page.Cursor = rsp.Data.NextCursor
+	}
+}
@@ -0,0 +1,142 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package operation
+
+import (
+	"reflect"
+	"testing"
+
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+)
+
+var testTopoDiffParentObjs = map[string]string{"set": "biz", "module": "set"}
+
+// biz(2) -> idle set(3) -> idle(4), biz(2) -> web(5) -> nginx(6), redis(7)
+func newTestTopoSnapshot(bizID int64, nginxTemplate int64) *topoSnapshot {
+	topo := []*metadata.TopoInstRst{{
+		TopoInst: metadata.TopoInst{ObjID: "biz", InstID: bizID, InstName: "biz", HostCount: 3},
+		Child: []*metadata.TopoInstRst{
+			{
+				TopoInst: metadata.TopoInst{ObjID: "set", InstID: 3, InstName: "idle set", Default: 1, HostCount: 1},
+				Child: []*metadata.TopoInstRst{
+					{TopoInst: metadata.TopoInst{ObjID: "module", InstID: 4, InstName: "idle", Default: 1, HostCount: 1}},
+				},
+			},
+			{
+				TopoInst: metadata.TopoInst{ObjID: "set", InstID: 5, InstName: "web", HostCount: 2},
+				Child: []*metadata.TopoInstRst{
+					{TopoInst: metadata.TopoInst{ObjID: "module", InstID: 6, InstName: "nginx", HostCount: 2,
+						ServiceTemplateID: nginxTemplate}},
+					{TopoInst: metadata.TopoInst{ObjID: "module", InstID: 7, InstName: "redis"}},
+				},
+			},
+		},
+	}}
+	return newTopoSnapshot(bizID, topo, testTopoDiffParentObjs)
+}
+
+func TestTopoSnapshotRevert(t *testing.T) {
+	current := newTestTopoSnapshot(2, 1)
+	history := newTestTopoSnapshot(2, 1)
+
+	// audit logs from the newest to the oldest
+	logs := []metadata.AuditLog{
+		{
+			ResourceType: metadata.HostRes,
+			Action:       metadata.AuditTransferHostModule,
+			ResourceID:   100,
+			OperationDetail: &metadata.HostTransferOpDetail{
+				PreData: metadata.HostBizTopo{BizID: 2, Set: []metadata.Topo{
+					{SetID: 3, Module: []metadata.Module{{ModuleID: 4}}}}},
+				CurData: metadata.HostBizTopo{BizID: 2, Set: []metadata.Topo{
+					{SetID: 5, Module: []metadata.Module{{ModuleID: 6}}}}},
+			},
+		},
+		{
+			ResourceType: metadata.ModuleRes,
+			Action:       metadata.AuditUpdate,
+			ResourceID:   int64(6),
+			OperationDetail: &metadata.InstanceOpDetail{
+				BasicOpDetail: metadata.BasicOpDetail{Details: &metadata.BasicContent{
+					PreData: mapstr.MapStr{"bk_module_name": "nginx-old", "bk_set_id": 5, "service_template_id": 1},
+				}},
+				ModelID: "module",
+			},
+		},
+		{
+			ResourceType: metadata.ModuleRes,
+			Action:       metadata.AuditCreate,
+			ResourceID:   int64(7),
+			OperationDetail: &metadata.InstanceOpDetail{
+				BasicOpDetail: metadata.BasicOpDetail{Details: &metadata.BasicContent{
+					CurData: mapstr.MapStr{"bk_module_name": "redis", "bk_set_id": 5},
+				}},
+				ModelID: "module",
+			},
+		},
+	}
+	for index := range logs {
+		history.revert(&logs[index])
+	}
+	history.fill(nil, nil)
+	current.fill(nil, nil)
+
+	result := new(metadata.TopoDiffResult)
+	diffTopoSnapshot(history, current, false, result)
+
+	if len(result.Removed) != 0 {
+		t.Fatalf("unexpected removed nodes: %+v", result.Removed)
+	}
+	if len(result.Added) != 1 || result.Added[0].InstID != 7 || !reflect.DeepEqual(result.Added[0].Path, []string{"web", "redis"}) {
+		t.Fatalf("unexpected added nodes: %+v", result.Added)
+	}
+
+	changed := make(map[int64][]string)
+	for _, change := range result.Changed {
+		changed[change.Target.InstID] = change.Fields
+	}
+	want := map[int64][]string{
+		3: {"host_count"},
+		4: {"host_count"},
+		5: {"host_count"},
+		6: {"bk_inst_name", "host_count"},
+	}
+	if !reflect.DeepEqual(changed, want) {
+		t.Fatalf("unexpected changed nodes: %v, want: %v", changed, want)
+	}
+	if node := history.nodes[topoNodeKey{objID: "module", instID: 4}]; node.HostCount != 2 {
+		t.Fatalf("unexpected idle module host count: %d", node.HostCount)
+	}
+	if node := history.nodes[topoNodeKey{objID: "biz", instID: 2}]; node.HostCount != 3 {
+		t.Fatalf("unexpected business host count: %d", node.HostCount)
+	}
+}
+
+func TestDiffTopoSnapshotByPath(t *testing.T) {
+	base := newTestTopoSnapshot(2, 1)
+	target := newTestTopoSnapshot(8, 1)
+	delete(target.nodes, topoNodeKey{objID: "module", instID: 7})
+	base.fill(map[int64]string{1: "nginx"}, nil)
+	target.fill(map[int64]string{1: "nginx-v2"}, nil)
+
+	result := new(metadata.TopoDiffResult)
+	diffTopoSnapshot(base, target, true, result)
+
+	if len(result.Added) != 0 || len(result.Removed) != 1 || result.Removed[0].InstName != "redis" {
+		t.Fatalf("unexpected added: %+v or removed: %+v", result.Added, result.Removed)
+	}
+	if len(result.Changed) != 1 || !reflect.DeepEqual(result.Changed[0].Fields, []string{"service_template_id"}) {
+		t.Fatalf("unexpected changed nodes: %+v", result.Changed)
+	}
+}
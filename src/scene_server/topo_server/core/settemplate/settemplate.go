@@ -35,6 +35,7 @@ type SetTemplate interface {
 	GetLatestSyncTaskDetail(kit *rest.Kit, setID int64) (*metadata.APITaskDetail, errors.CCErrorCoder)
 	CheckSetInstUpdateToDateStatus(kit *rest.Kit, bizID int64, setTemplateID int64) (metadata.SetTemplateUpdateToDateStatus, errors.CCErrorCoder)
 	TriggerCheckSetTemplateSyncingStatus(kit *rest.Kit, bizID, setTemplateID, setID int64) errors.CCErrorCoder
+	AutoSyncSetTplToInst(kit *rest.Kit, policy metadata.SetTemplateSyncPolicy, trigger metadata.SetTemplateSyncTrigger) (metadata.SetTemplateSyncSummary, errors.CCErrorCoder)
 }
 
 func NewSetTemplate(client apimachinery.ClientSetInterface) SetTemplate {
@@ -177,8 +178,6 @@ func (st *setTemplate) DiffSetTplWithInst(ctx context.Context, header http.Heade
 }
 
 func (st *setTemplate) SyncSetTplToInst(kit *rest.Kit, bizID int64, setTemplateID int64, option metadata.SyncSetTplToInstOption) errors.CCErrorCoder {
-	rid := util.GetHTTPCCRequestID(kit.Header)
-
 	diffOption := metadata.DiffSetTplWithInstOption{
 		SetIDs: option.SetIDs,
 	}
@@ -188,70 +187,84 @@ func (st *setTemplate) SyncSetTplToInst(kit *rest.Kit, bizID int64, setTemplateI
 	}
 
 	for _, setDiff := range setDiffs {
-		indexKey := metadata.GetSetTemplateSyncIndex(setDiff.SetID)
-		blog.V(3).Infof("dispatch synchronize task on set [%s](%d), rid: %s", setDiff.SetDetail.SetName, setDiff.SetID, rid)
-		tasks := make([]metadata.SyncModuleTask, 0)
-		for _, moduleDiff := range setDiff.ModuleDiffs {
-			task := metadata.SyncModuleTask{
-				Set:                setDiff.SetDetail,
-				ModuleDiff:         moduleDiff,
-				SetTopoPath:        setDiff.TopoPath,
-				SetTemplateVersion: setDiff.SetTemplateVersion,
-			}
-			tasks = append(tasks, task)
-		}
-		taskDetail, err := st.DispatchTask4ModuleSync(kit.Ctx, kit.Header, indexKey, tasks...)
-		if err != nil {
-			return err
-		}
-		if blog.V(3) {
-			blog.InfoJSON("dispatch synchronize task on set [%s](%s) success, result: %s, rid: %s", setDiff.SetDetail.SetName, setDiff.SetID, taskDetail, rid)
-		}
-
-		// 修改任务到同步中的状态
-		err = st.client.CoreService().SetTemplate().ModifySetTemplateSyncStatus(kit.Ctx, kit.Header, setDiff.SetID, metadata.SyncStatusSyncing)
-		//_, err = st.UpdateSetSyncStatus(kit, setDiff.SetID)
-		if err != nil {
-			blog.Errorf("UpdateSetSyncStatus failed, setID: %d, err: %s", setDiff.SetID, err.Error())
+		if _, err := st.dispatchSetSyncTask(kit, setDiff); err != nil {
 			return err
 		}
 
 		// 定时更新 SetTemplateSyncStatus 状态，优化加载
 		go func(setID int64) {
-			// 指数增长轮询间隔
-			duration := 200 * time.Millisecond
-			maxDuration := 10 * time.Second
-			ticker := time.NewTimer(duration)
-			timeoutTimer := time.NewTimer(5 * time.Minute)
-
-			for {
-				select {
-				case <-timeoutTimer.C:
-					blog.Errorf("poll UpdateSetSyncStatus timeout, setID: %d", setID)
-					return
-				case <-ticker.C:
-					setSyncStatus, err := st.UpdateSetSyncStatus(kit.NewKit(), setID)
-					if err != nil {
-						blog.Errorf("UpdateSetSyncStatus failed, setID: %d, err: %s, rid: %s", setID, err.Error(), kit.Rid)
-						return
-					}
-					if setSyncStatus.Status.IsFinished() {
-						return
-					}
-
-					// set next timer
-					duration = duration * 2
-					if duration > maxDuration {
-						duration = maxDuration
-					}
-					ticker = time.NewTimer(duration)
-				}
+			if _, err := st.waitSetSyncFinished(kit.NewKit(), setID, 5*time.Minute); err != nil {
+				blog.Errorf("poll UpdateSetSyncStatus failed, setID: %d, err: %s, rid: %s", setID, err.Error(), kit.Rid)
 			}
 		}(setDiff.SetID)
 	}
 	return nil
 }
 
+// dispatchSetSyncTask create a task_server task that applies all the module differences of the set
+func (st *setTemplate) dispatchSetSyncTask(kit *rest.Kit, setDiff metadata.SetDiff) (metadata.APITaskDetail, errors.CCErrorCoder) {
+	indexKey := metadata.GetSetTemplateSyncIndex(setDiff.SetID)
+	blog.V(3).Infof("dispatch synchronize task on set [%s](%d), rid: %s", setDiff.SetDetail.SetName, setDiff.SetID, kit.Rid)
+	tasks := make([]metadata.SyncModuleTask, 0)
+	for _, moduleDiff := range setDiff.ModuleDiffs {
+		task := metadata.SyncModuleTask{
+			Set:                setDiff.SetDetail,
+			ModuleDiff:         moduleDiff,
+			SetTopoPath:        setDiff.TopoPath,
+			SetTemplateVersion: setDiff.SetTemplateVersion,
+		}
+		tasks = append(tasks, task)
+	}
+	taskDetail, err := st.DispatchTask4ModuleSync(kit.Ctx, kit.Header, indexKey, tasks...)
+	if err != nil {
+		return taskDetail, err
+	}
+	if blog.V(3) {
+		blog.InfoJSON("dispatch synchronize task on set [%s](%s) success, result: %s, rid: %s", setDiff.SetDetail.SetName, setDiff.SetID, taskDetail, kit.Rid)
+	}
+
+	// 修改任务到同步中的状态
+	err = st.client.CoreService().SetTemplate().ModifySetTemplateSyncStatus(kit.Ctx, kit.Header, setDiff.SetID, metadata.SyncStatusSyncing)
+	if err != nil {
+		blog.Errorf("UpdateSetSyncStatus failed, setID: %d, err: %s, rid: %s", setDiff.SetID, err.Error(), kit.Rid)
+		return taskDetail, err
+	}
+	return taskDetail, nil
+}
+
+// waitSetSyncFinished 指数增长轮询间隔更新集群的同步状态, 直到同步任务结束或超时
+func (st *setTemplate) waitSetSyncFinished(kit *rest.Kit, setID int64, timeout time.Duration) (metadata.SetTemplateSyncStatus, errors.CCErrorCoder) {
+	duration := 200 * time.Millisecond
+	maxDuration := 10 * time.Second
+	ticker := time.NewTimer(duration)
+	timeoutTimer := time.NewTimer(timeout)
+	defer timeoutTimer.Stop()
+
+	for {
+		select {
+		case <-timeoutTimer.C:
+			blog.Errorf("poll UpdateSetSyncStatus timeout, setID: %d, rid: %s", setID, kit.Rid)
+			return metadata.SetTemplateSyncStatus{}, kit.CCError.CCError(common.CCErrorTopoSyncModuleTaskFailed)
+		case <-ticker.C:
+			setSyncStatus, err := st.UpdateSetSyncStatus(kit, setID)
+			if err != nil {
+				blog.Errorf("UpdateSetSyncStatus failed, setID: %d, err: %s, rid: %s", setID, err.Error(), kit.Rid)
+				return setSyncStatus, err
+			}
+			if setSyncStatus.Status.IsFinished() {
+				return setSyncStatus, nil
+			}
+
+			// set next timer
+			duration = duration * 2
+			if duration > maxDuration {
+				duration = maxDuration
+			}
+			ticker = time.NewTimer(duration)
+		}
+	}
+}
+
 func (st *setTemplate) DispatchTask4ModuleSync(ctx context.Context, header http.Header, indexKey string, tasks ...metadata.SyncModuleTask) (metadata.APITaskDetail, errors.CCErrorCoder) {
 	taskDetail := metadata.APITaskDetail{}
 	rid := util.GetHTTPCCRequestID(header)
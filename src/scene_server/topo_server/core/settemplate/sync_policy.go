@@ -0,0 +1,324 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package settemplate
+
+import (
+	"net/url"
+	"strings"
+	"time"
+
+	"configcenter/src/common"
+	cc "configcenter/src/common/backbone/configcenter"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/http/httpclient"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/json"
+	"configcenter/src/common/metadata"
+)
+
+const (
+	// batchSyncTimeout 每批集群等待同步完成的最长时间
+	batchSyncTimeout = 10 * time.Minute
+	notifyTimeout    = 10 * time.Second
+
+	// notifyHostsConfigKey 允许推送集群模板同步摘要的回调地址的主机列表配置项
+	notifyHostsConfigKey = "topoServer.setTemplateSync.notifyHosts"
+)
+
+// AutoSyncSetTplToInst 按同步策略将集群模板同步到所有未同步的集群实例上,
+// 集群按批次提交到task_server, 上一批次全部结束后才会提交下一批次, 完成后记录并推送同步摘要.
+func (st *setTemplate) AutoSyncSetTplToInst(kit *rest.Kit, policy metadata.SetTemplateSyncPolicy,
+	trigger metadata.SetTemplateSyncTrigger) (metadata.SetTemplateSyncSummary, errors.CCErrorCoder) {
+
+	bizID, setTemplateID := policy.BizID, policy.SetTemplateID
+	summary := metadata.SetTemplateSyncSummary{
+		BizID:         bizID,
+		SetTemplateID: setTemplateID,
+		Trigger:       trigger,
+		StartTime:     time.Now(),
+		Sets:          make([]metadata.SetSyncSummary, 0),
+	}
+
+	status, err := st.CheckSetInstUpdateToDateStatus(kit, bizID, setTemplateID)
+	if err != nil {
+		blog.Errorf("AutoSyncSetTplToInst failed, CheckSetInstUpdateToDateStatus failed, bizID: %d, setTemplateID: %d, err: %s, rid: %s", bizID, setTemplateID, err.Error(), kit.Rid)
+		st.saveSyncFailure(kit, policy, summary.StartTime, err)
+		return summary, err
+	}
+	summary.SetTemplateVersion = status.SetTemplateVersion
+
+	setIDs := make([]int64, 0)
+	for _, set := range status.Sets {
+		if set.NeedSync {
+			setIDs = append(setIDs, set.SetID)
+		}
+	}
+
+	for _, batch := range SplitSetIDBatches(setIDs, policy.BatchSize) {
+		// the sets left when the maintenance window closes are synchronized in the next window
+		if trigger == metadata.SetTemplateSyncTriggerWindow && !policy.InWindow(time.Now()) {
+			blog.Warnf("maintenance window of set template %d is closed, stop syncing sets %v, rid: %s",
+				setTemplateID, batch, kit.Rid)
+			break
+		}
+
+		batchSummary, err := st.syncSetBatch(kit, policy, batch)
+		if err != nil {
+			blog.Errorf("AutoSyncSetTplToInst failed, sync set batch failed, bizID: %d, setTemplateID: %d, setIDs: %v, err: %s, rid: %s", bizID, setTemplateID, batch, err.Error(), kit.Rid)
+			st.saveSyncFailure(kit, policy, summary.StartTime, err)
+			return summary, err
+		}
+		summary.Sets = append(summary.Sets, batchSummary...)
+	}
+	summary.EndTime = time.Now()
+
+	result := metadata.UpdateSetTemplateSyncResultOption{
+		SyncTime: summary.StartTime,
+		Summary:  summary,
+	}
+	if err := st.client.CoreService().SetTemplate().UpdateSetTemplateSyncResult(kit.Ctx, kit.Header, bizID, setTemplateID, result); err != nil {
+		blog.Errorf("AutoSyncSetTplToInst failed, save sync result failed, bizID: %d, setTemplateID: %d, err: %s, rid: %s", bizID, setTemplateID, err.Error(), kit.Rid)
+		return summary, err
+	}
+
+	if len(policy.NotifyURL) != 0 && len(summary.Sets) != 0 {
+		st.notifySyncSummary(kit, policy.NotifyURL, summary)
+	}
+	return summary, nil
+}
+
+// saveSyncFailure record the failure of the automatic synchronization, the maintenance window scheduler retries
+// the failed policy with backoff according to it
+func (st *setTemplate) saveSyncFailure(kit *rest.Kit, policy metadata.SetTemplateSyncPolicy, failTime time.Time,
+	syncErr error) {
+
+	result := metadata.UpdateSetTemplateSyncResultOption{
+		SyncTime:  failTime,
+		Error:     syncErr.Error(),
+		FailCount: policy.FailCount + 1,
+	}
+	err := st.client.CoreService().SetTemplate().UpdateSetTemplateSyncResult(kit.Ctx, kit.Header, policy.BizID,
+		policy.SetTemplateID, result)
+	if err != nil {
+		blog.Errorf("save set template %d sync failure failed, err: %v, rid: %s", policy.SetTemplateID, err, kit.Rid)
+	}
+}
+
+// syncSetBatch dispatch the sync tasks of one batch sets and wait them to finish
+func (st *setTemplate) syncSetBatch(kit *rest.Kit, policy metadata.SetTemplateSyncPolicy, setIDs []int64) ([]metadata.SetSyncSummary, errors.CCErrorCoder) {
+	diffOption := metadata.DiffSetTplWithInstOption{
+		SetIDs: setIDs,
+	}
+	setDiffs, err := st.DiffSetTplWithInst(kit.Ctx, kit.Header, policy.BizID, policy.SetTemplateID, diffOption)
+	if err != nil {
+		return nil, err
+	}
+
+	moduleHostCount := make(map[int64]int64)
+	if policy.KeepModulesWithHost {
+		moduleHostCount, err = st.countRemovedModuleHosts(kit, policy.BizID, setDiffs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	summaries := make([]metadata.SetSyncSummary, 0)
+	dispatched := make(map[int64]int)
+	for _, setDiff := range setDiffs {
+		setSummary := metadata.SetSyncSummary{
+			SetID:   setDiff.SetID,
+			SetName: setDiff.SetDetail.SetName,
+			Changes: make([]metadata.SetModuleDiff, 0),
+			Skipped: make([]metadata.SetModuleDiff, 0),
+		}
+
+		// 已经有同步任务在执行的集群留给该任务处理
+		detail, err := st.GetLatestSyncTaskDetail(kit, setDiff.SetID)
+		if err != nil {
+			return nil, err
+		}
+		if detail != nil && !detail.Status.IsFinished() {
+			setSummary.Status = metadata.SyncStatusSyncing
+			setSummary.TaskID = detail.TaskID
+			setSummary.Message = "another sync task is running"
+			summaries = append(summaries, setSummary)
+			continue
+		}
+
+		setDiff.ModuleDiffs, setSummary.Skipped = FilterModuleDiffsWithHost(setDiff.ModuleDiffs, moduleHostCount)
+		for _, moduleDiff := range setDiff.ModuleDiffs {
+			if moduleDiff.DiffType != metadata.ModuleDiffUnchanged {
+				setSummary.Changes = append(setSummary.Changes, moduleDiff)
+			}
+		}
+		if len(setSummary.Changes) == 0 {
+			setSummary.Status = metadata.SyncStatusWaiting
+			setSummary.Message = "all changes are skipped by sync policy"
+			summaries = append(summaries, setSummary)
+			continue
+		}
+
+		// make sure the sync status record exists before it is changed to syncing
+		if _, err := st.UpdateSetSyncStatus(kit, setDiff.SetID); err != nil {
+			return nil, err
+		}
+		taskDetail, err := st.dispatchSetSyncTask(kit, setDiff)
+		if err != nil {
+			setSummary.Status = metadata.SyncStatusFailure
+			setSummary.Message = err.Error()
+			summaries = append(summaries, setSummary)
+			continue
+		}
+		setSummary.TaskID = taskDetail.TaskID
+		dispatched[setDiff.SetID] = len(summaries)
+		summaries = append(summaries, setSummary)
+	}
+
+	for setID, idx := range dispatched {
+		syncStatus, err := st.waitSetSyncFinished(kit, setID, batchSyncTimeout)
+		if err != nil {
+			summaries[idx].Status = metadata.SyncStatusFailure
+			summaries[idx].Message = err.Error()
+			continue
+		}
+		summaries[idx].Status = syncStatus.Status
+	}
+	return summaries, nil
+}
+
+// countRemovedModuleHosts count hosts of the modules that are going to be removed by the sync
+func (st *setTemplate) countRemovedModuleHosts(kit *rest.Kit, bizID int64, setDiffs []metadata.SetDiff) (map[int64]int64, errors.CCErrorCoder) {
+	moduleHostCount := make(map[int64]int64)
+	moduleIDs := make([]int64, 0)
+	for _, setDiff := range setDiffs {
+		for _, moduleDiff := range setDiff.ModuleDiffs {
+			if moduleDiff.DiffType == metadata.ModuleDiffRemove {
+				moduleIDs = append(moduleIDs, moduleDiff.ModuleID)
+			}
+		}
+	}
+	if len(moduleIDs) == 0 {
+		return moduleHostCount, nil
+	}
+
+	relationOption := &metadata.HostModuleRelationRequest{
+		ApplicationID: bizID,
+		ModuleIDArr:   moduleIDs,
+		Page: metadata.BasePage{
+			Limit: common.BKNoLimit,
+		},
+		Fields: []string{common.BKModuleIDField},
+	}
+	relationResult, err := st.client.CoreService().Host().GetHostModuleRelation(kit.Ctx, kit.Header, relationOption)
+	if err != nil {
+		blog.Errorf("countRemovedModuleHosts failed, GetHostModuleRelation failed, option: %+v, err: %s, rid: %s", relationOption, err.Error(), kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommHTTPDoRequestFailed)
+	}
+	if ccErr := relationResult.CCError(); ccErr != nil {
+		blog.Errorf("countRemovedModuleHosts failed, GetHostModuleRelation failed, option: %+v, result: %+v, rid: %s", relationOption, relationResult, kit.Rid)
+		return nil, ccErr
+	}
+	for _, relation := range relationResult.Data.Info {
+		moduleHostCount[relation.ModuleID]++
+	}
+	return moduleHostCount, nil
+}
+
+// notifySyncSummary post the sync summary to the notify url of the policy, failure only logs
+func (st *setTemplate) notifySyncSummary(kit *rest.Kit, notifyURL string, summary metadata.SetTemplateSyncSummary) {
+	body, err := json.Marshal(summary)
+	if err != nil {
+		blog.Errorf("notify set template sync summary failed, marshal summary failed, err: %v, rid: %s", err, kit.Rid)
+		return
+	}
+
+	// the allowed hosts may be changed after the policy is saved
+	if !IsNotifyURLAllowed(notifyURL, NotifyHosts()) {
+		blog.Errorf("notify set template sync summary failed, host of %s is not allowed, rid: %s", notifyURL, kit.Rid)
+		return
+	}
+
+	client := httpclient.NewHttpClient()
+	client.SetTimeOut(notifyTimeout)
+	client.SetHeader("Content-Type", "application/json")
+	statusCode, _, err := client.POSTEx(notifyURL, nil, body)
+	if err != nil {
+		blog.Errorf("notify set template sync summary to %s failed, err: %v, rid: %s", notifyURL, err, kit.Rid)
+		return
+	}
+	if statusCode < 200 || statusCode >= 300 {
+		blog.Errorf("notify set template sync summary to %s failed, status code: %d, rid: %s", notifyURL, statusCode, kit.Rid)
+	}
+}
+
+// NotifyHosts returns the hosts that the sync summary can be posted to, no host is allowed if it's not configured
+func NotifyHosts() []string {
+	if !cc.IsExist(notifyHostsConfigKey) {
+		return nil
+	}
+	hosts := make([]string, 0)
+	if err := cc.UnmarshalKey(notifyHostsConfigKey, &hosts); err != nil {
+		blog.Errorf("parse %s config failed, err: %v", notifyHostsConfigKey, err)
+		return nil
+	}
+	return hosts
+}
+
+// IsNotifyURLAllowed checks if the host of the notify url is in the allowed hosts, so that the sync summary is not
+// posted to the internal services, an allowed host with port only matches the url with the same port.
+func IsNotifyURLAllowed(notifyURL string, allowedHosts []string) bool {
+	u, err := url.Parse(notifyURL)
+	if err != nil || u.User != nil || len(u.Hostname()) == 0 {
+		return false
+	}
+	for _, allowed := range allowedHosts {
+		allowed = strings.ToLower(strings.TrimSpace(allowed))
+		if allowed == strings.ToLower(u.Hostname()) || allowed == strings.ToLower(u.Host) {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterModuleDiffsWithHost 将删除仍有主机的模块的变更剔除, 返回剩余的变更以及被剔除的变更
+func FilterModuleDiffsWithHost(moduleDiffs []metadata.SetModuleDiff, moduleHostCount map[int64]int64) (
+	[]metadata.SetModuleDiff, []metadata.SetModuleDiff) {
+
+	kept := make([]metadata.SetModuleDiff, 0)
+	skipped := make([]metadata.SetModuleDiff, 0)
+	for _, moduleDiff := range moduleDiffs {
+		if moduleDiff.DiffType == metadata.ModuleDiffRemove && moduleHostCount[moduleDiff.ModuleID] > 0 {
+			skipped = append(skipped, moduleDiff)
+			continue
+		}
+		kept = append(kept, moduleDiff)
+	}
+	return kept, skipped
+}
+
+// SplitSetIDBatches 将集群按批次大小拆分
+func SplitSetIDBatches(setIDs []int64, batchSize int64) [][]int64 {
+	if batchSize <= 0 {
+		batchSize = metadata.SetTemplateSyncDefaultBatchSize
+	}
+	batches := make([][]int64, 0)
+	for start := 0; start < len(setIDs); start += int(batchSize) {
+		end := start + int(batchSize)
+		if end > len(setIDs) {
+			end = len(setIDs)
+		}
+		batches = append(batches, setIDs[start:end])
+	}
+	return batches
+}
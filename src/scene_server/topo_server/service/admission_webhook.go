@@ -0,0 +1,114 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"strconv"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+)
+
+// CreateAdmissionWebhook create a webhook which reviews the writes of the model
+func (s *Service) CreateAdmissionWebhook(ctx *rest.Contexts) {
+	webhook := metadata.AdmissionWebhook{}
+	if err := ctx.DecodeInto(&webhook); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	result, err := s.Engine.CoreAPI.CoreService().Admission().CreateAdmissionWebhook(ctx.Kit.Ctx, ctx.Kit.Header, webhook)
+	if err != nil {
+		blog.Errorf("create admission webhook failed, name: %s, err: %v, rid: %s", webhook.Name, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(result)
+}
+
+// UpdateAdmissionWebhook replace the settings of the admission webhook
+func (s *Service) UpdateAdmissionWebhook(ctx *rest.Contexts) {
+	id, err := strconv.ParseInt(ctx.Request.PathParameter("id"), 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "id"))
+		return
+	}
+
+	webhook := metadata.AdmissionWebhook{}
+	if err := ctx.DecodeInto(&webhook); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	result, ccErr := s.Engine.CoreAPI.CoreService().Admission().UpdateAdmissionWebhook(ctx.Kit.Ctx, ctx.Kit.Header, id,
+		webhook)
+	if ccErr != nil {
+		blog.Errorf("update admission webhook failed, id: %d, err: %v, rid: %s", id, ccErr, ctx.Kit.Rid)
+		ctx.RespAutoError(ccErr)
+		return
+	}
+	ctx.RespEntity(result)
+}
+
+// DeleteAdmissionWebhook delete the admission webhook
+func (s *Service) DeleteAdmissionWebhook(ctx *rest.Contexts) {
+	id, err := strconv.ParseInt(ctx.Request.PathParameter("id"), 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "id"))
+		return
+	}
+
+	if err := s.Engine.CoreAPI.CoreService().Admission().DeleteAdmissionWebhook(ctx.Kit.Ctx, ctx.Kit.Header, id); err != nil {
+		blog.Errorf("delete admission webhook failed, id: %d, err: %v, rid: %s", id, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(nil)
+}
+
+// SearchAdmissionWebhooks search the admission webhooks
+func (s *Service) SearchAdmissionWebhooks(ctx *rest.Contexts) {
+	option := metadata.SearchAdmissionWebhookOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	result, err := s.Engine.CoreAPI.CoreService().Admission().SearchAdmissionWebhooks(ctx.Kit.Ctx, ctx.Kit.Header, option)
+	if err != nil {
+		blog.Errorf("search admission webhooks failed, err: %v, rid: %s", err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(result)
+}
+
+// SearchAdmissionWebhookLogs search the decision logs of the admission webhooks
+func (s *Service) SearchAdmissionWebhookLogs(ctx *rest.Contexts) {
+	option := metadata.SearchAdmissionWebhookLogOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	result, err := s.Engine.CoreAPI.CoreService().Admission().SearchAdmissionWebhookLogs(ctx.Kit.Ctx, ctx.Kit.Header,
+		option)
+	if err != nil {
+		blog.Errorf("search admission webhook logs failed, err: %v, rid: %s", err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(result)
+}
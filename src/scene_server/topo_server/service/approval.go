@@ -0,0 +1,455 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"configcenter/src/ac"
+	"configcenter/src/ac/meta"
+	"configcenter/src/ac/parser"
+	apiRest "configcenter/src/apimachinery/rest"
+	"configcenter/src/common"
+	"configcenter/src/common/approval"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/changefreeze"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+
+	"github.com/emicklei/go-restful"
+)
+
+// approvalExpireCheckInterval is the interval to expire the pending approval requests which are not approved in time
+const approvalExpireCheckInterval = time.Minute
+
+// approvalGate returns the gate which checks the sensitive operations of topo server against the approval policies
+func (s *Service) approvalGate() *approval.Gate {
+	return approval.NewGate(s.Engine.CoreAPI.CoreService(), metadata.ApprovalServerTopo, "/topo/v3")
+}
+
+// approvalInstPreview returns the instances to delete as the preview of the approval request
+func (s *Service) approvalInstPreview(kit *rest.Kit, objID string, cond mapstr.MapStr) approval.PreviewFunc {
+	return func() (interface{}, error) {
+		option := &metadata.QueryCondition{Condition: cond, Page: metadata.BasePage{Limit: common.BKNoLimit}}
+		result, err := s.Engine.CoreAPI.CoreService().Instance().ReadInstance(kit.Ctx, kit.Header, objID, option)
+		if err != nil {
+			blog.Errorf("read %s instances failed, cond: %+v, err: %v, rid: %s", objID, cond, err, kit.Rid)
+			return nil, kit.CCError.CCError(common.CCErrCommHTTPDoRequestFailed)
+		}
+		if !result.Result {
+			blog.Errorf("read %s instances failed, cond: %+v, err: %s, rid: %s", objID, cond, result.ErrMsg, kit.Rid)
+			return nil, kit.CCError.New(result.Code, result.ErrMsg)
+		}
+		return result.Data.Info, nil
+	}
+}
+
+// approvalModelPreview returns the model to delete as the preview of the approval request
+func (s *Service) approvalModelPreview(kit *rest.Kit, id int64) approval.PreviewFunc {
+	return func() (interface{}, error) {
+		option := &metadata.QueryCondition{Condition: mapstr.MapStr{common.BKFieldID: id}}
+		result, err := s.Engine.CoreAPI.CoreService().Model().ReadModel(kit.Ctx, kit.Header, option)
+		if err != nil {
+			blog.Errorf("read model %d failed, err: %v, rid: %s", id, err, kit.Rid)
+			return nil, kit.CCError.CCError(common.CCErrCommHTTPDoRequestFailed)
+		}
+		if !result.Result {
+			blog.Errorf("read model %d failed, err: %s, rid: %s", id, result.ErrMsg, kit.Rid)
+			return nil, kit.CCError.New(result.Code, result.ErrMsg)
+		}
+		return result.Data.Info, nil
+	}
+}
+
+// CreateApprovalPolicy create a policy which requires the operation to be approved
+func (s *Service) CreateApprovalPolicy(ctx *rest.Contexts) {
+	policy := metadata.ApprovalPolicy{}
+	if err := ctx.DecodeInto(&policy); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	result, err := s.Engine.CoreAPI.CoreService().Approval().CreateApprovalPolicy(ctx.Kit.Ctx, ctx.Kit.Header, policy)
+	if err != nil {
+		blog.Errorf("create approval policy failed, name: %s, err: %v, rid: %s", policy.Name, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(result)
+}
+
+// UpdateApprovalPolicy replace the settings of the approval policy
+func (s *Service) UpdateApprovalPolicy(ctx *rest.Contexts) {
+	id, err := strconv.ParseInt(ctx.Request.PathParameter("id"), 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "id"))
+		return
+	}
+
+	policy := metadata.ApprovalPolicy{}
+	if err := ctx.DecodeInto(&policy); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	result, ccErr := s.Engine.CoreAPI.CoreService().Approval().UpdateApprovalPolicy(ctx.Kit.Ctx, ctx.Kit.Header, id,
+		policy)
+	if ccErr != nil {
+		blog.Errorf("update approval policy failed, id: %d, err: %v, rid: %s", id, ccErr, ctx.Kit.Rid)
+		ctx.RespAutoError(ccErr)
+		return
+	}
+	ctx.RespEntity(result)
+}
+
+// DeleteApprovalPolicy delete the approval policy, the pending requests created by it can still be approved
+func (s *Service) DeleteApprovalPolicy(ctx *rest.Contexts) {
+	id, err := strconv.ParseInt(ctx.Request.PathParameter("id"), 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "id"))
+		return
+	}
+
+	if err := s.Engine.CoreAPI.CoreService().Approval().DeleteApprovalPolicy(ctx.Kit.Ctx, ctx.Kit.Header, id); err != nil {
+		blog.Errorf("delete approval policy failed, id: %d, err: %v, rid: %s", id, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(nil)
+}
+
+// SearchApprovalPolicies search the approval policies
+func (s *Service) SearchApprovalPolicies(ctx *rest.Contexts) {
+	option := metadata.SearchApprovalPolicyOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	result, err := s.Engine.CoreAPI.CoreService().Approval().SearchApprovalPolicies(ctx.Kit.Ctx, ctx.Kit.Header, option)
+	if err != nil {
+		blog.Errorf("search approval policies failed, err: %v, rid: %s", err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(result)
+}
+
+// SearchApprovalRequests search the approval requests which are requested by the user or waiting for the user
+func (s *Service) SearchApprovalRequests(ctx *rest.Contexts) {
+	filter := metadata.SearchUserApprovalRequestOption{}
+	if err := ctx.DecodeInto(&filter); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if rawErr := filter.Validate(); rawErr.ErrCode != 0 {
+		ctx.RespAutoError(rawErr.ToCCError(ctx.Kit.CCError))
+		return
+	}
+	option := filter.ToSearchOption(ctx.Kit.User)
+
+	result, err := s.Engine.CoreAPI.CoreService().Approval().SearchApprovalRequests(ctx.Kit.Ctx, ctx.Kit.Header, option)
+	if err != nil {
+		blog.Errorf("search approval requests failed, err: %v, rid: %s", err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(result)
+}
+
+// ApproveApprovalRequest approve the pending request, the operation is executed with the identity of the requester
+// right away, and the request is returned with the result of the operation.
+func (s *Service) ApproveApprovalRequest(ctx *rest.Contexts) {
+	request, option, err := s.decidePendingApprovalRequest(ctx)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	updateOption := metadata.UpdateApprovalStatusOption{
+		From:     metadata.ApprovalStatusPending,
+		To:       metadata.ApprovalStatusExecuting,
+		Approver: ctx.Kit.User,
+		Comment:  option.Comment,
+	}
+	id := request.ID
+	request, err = s.Engine.CoreAPI.CoreService().Approval().UpdateApprovalRequestStatus(ctx.Kit.Ctx, ctx.Kit.Header,
+		id, updateOption)
+	if err != nil {
+		blog.Errorf("approve approval request %d failed, err: %v, rid: %s", id, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	approval.SaveRequestAudit(ctx.Kit.Ctx, ctx.Kit.Header, ctx.Kit.Rid, s.Engine.CoreAPI.CoreService(), request,
+		metadata.AuditApprove)
+
+	ctx.RespEntity(s.executeApprovalRequest(ctx.Kit, request))
+}
+
+// RejectApprovalRequest reject the pending request, the operation is not executed
+func (s *Service) RejectApprovalRequest(ctx *rest.Contexts) {
+	request, option, err := s.decidePendingApprovalRequest(ctx)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	updateOption := metadata.UpdateApprovalStatusOption{
+		From:     metadata.ApprovalStatusPending,
+		To:       metadata.ApprovalStatusRejected,
+		Approver: ctx.Kit.User,
+		Comment:  option.Comment,
+	}
+	id := request.ID
+	request, err = s.Engine.CoreAPI.CoreService().Approval().UpdateApprovalRequestStatus(ctx.Kit.Ctx, ctx.Kit.Header,
+		id, updateOption)
+	if err != nil {
+		blog.Errorf("reject approval request %d failed, err: %v, rid: %s", id, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	approval.SaveRequestAudit(ctx.Kit.Ctx, ctx.Kit.Header, ctx.Kit.Rid, s.Engine.CoreAPI.CoreService(), request,
+		metadata.AuditReject)
+
+	ctx.RespEntity(request)
+}
+
+// decidePendingApprovalRequest get the request to approve or reject, only the approvers except the requester can
+// decide, and the expired request can not be decided even if it's not marked as expired yet.
+func (s *Service) decidePendingApprovalRequest(ctx *rest.Contexts) (*metadata.ApprovalRequest,
+	*metadata.ApprovalDecisionOption, errors.CCErrorCoder) {
+
+	id, err := strconv.ParseInt(ctx.Request.PathParameter("id"), 10, 64)
+	if err != nil {
+		return nil, nil, ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "id")
+	}
+
+	option := new(metadata.ApprovalDecisionOption)
+	if err := ctx.DecodeInto(option); err != nil {
+		return nil, nil, ctx.Kit.CCError.CCError(common.CCErrCommJSONUnmarshalFailed)
+	}
+
+	request, ccErr := s.Engine.CoreAPI.CoreService().Approval().GetApprovalRequest(ctx.Kit.Ctx, ctx.Kit.Header, id)
+	if ccErr != nil {
+		blog.Errorf("get approval request %d failed, err: %v, rid: %s", id, ccErr, ctx.Kit.Rid)
+		return nil, nil, ccErr
+	}
+
+	if !request.IsApprover(ctx.Kit.User) || request.Requester == ctx.Kit.User {
+		blog.Errorf("user %s can not decide approval request %d, rid: %s", ctx.Kit.User, id, ctx.Kit.Rid)
+		return nil, nil, ctx.Kit.CCError.CCErrorf(common.CCErrCommNotApprover, ctx.Kit.User, id)
+	}
+
+	if request.Status != metadata.ApprovalStatusPending || request.ExpireTime.Before(time.Now()) {
+		blog.Errorf("approval request %d is not pending, status: %s, expire time: %s, rid: %s", id, request.Status,
+			request.ExpireTime, ctx.Kit.Rid)
+		return nil, nil, ctx.Kit.CCError.CCErrorf(common.CCErrCommApprovalRequestNotPending, id)
+	}
+	return request, option, nil
+}
+
+// parseApprovalRequest parses the permissions needed by the saved request in the same way as api server does
+func (s *Service) parseApprovalRequest(request *metadata.ApprovalRequest, header http.Header) (*http.Request,
+	*meta.AuthAttribute, error) {
+
+	req, err := http.NewRequest(request.Request.Method, request.Request.PublicPath(),
+		bytes.NewReader(request.Request.Body))
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header = header.Clone()
+
+	attribute, err := parser.ParseAttribute(restful.NewRequest(req), s.Engine)
+	if err != nil {
+		return nil, nil, err
+	}
+	return req, attribute, nil
+}
+
+// authorizeApprovalRequest authorizes the saved request as the requester again before it's executed, because the
+// permissions of the requester may be revoked during the approval, and the approvers' permissions are not used.
+func (s *Service) authorizeApprovalRequest(kit *rest.Kit, request *metadata.ApprovalRequest,
+	header http.Header) errors.CCErrorCoder {
+
+	if !s.AuthManager.Enabled() {
+		return nil
+	}
+
+	_, attribute, err := s.parseApprovalRequest(request, header)
+	if err != nil {
+		blog.Errorf("parse auth attribute of approval request %d failed, err: %v, rid: %s", request.ID, err, kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommParseAuthAttributeFailed)
+	}
+
+	err = s.AuthManager.AuthorizeResources(kit.Ctx, header, attribute.Resources...)
+	if err == ac.NoAuthorizeError {
+		blog.Errorf("requester %s has no permission to execute approval request %d, rid: %s", request.Requester,
+			request.ID, kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommAuthNotHavePermission)
+	}
+	if err != nil {
+		blog.Errorf("authorize approval request %d failed, err: %v, rid: %s", request.ID, err, kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommCheckAuthorizeFailed)
+	}
+	return nil
+}
+
+// checkApprovalRequestFrozen checks the saved request against the change freeze windows as the requester, because
+// the approved operation is sent to the scene servers directly, not through the change freeze filter of api server.
+func (s *Service) checkApprovalRequestFrozen(kit *rest.Kit, request *metadata.ApprovalRequest,
+	header http.Header) errors.CCErrorCoder {
+
+	req, attribute, err := s.parseApprovalRequest(request, header)
+	if err != nil {
+		blog.Errorf("parse auth attribute of approval request %d failed, err: %v, rid: %s", request.ID, err, kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommParseAuthAttributeFailed)
+	}
+
+	requesterKit := &rest.Kit{
+		Rid:             kit.Rid,
+		Header:          header,
+		Ctx:             kit.Ctx,
+		CCError:         kit.CCError,
+		User:            request.Requester,
+		SupplierAccount: request.OwnerID,
+	}
+	return changefreeze.Check(requesterKit, s.Engine.CoreAPI.CoreService(), parser.ChangeFreezeResources(req,
+		attribute)...)
+}
+
+// executeApprovalRequest send the request of the approved operation again with the identity of the requester, and
+// save the result to the request.
+func (s *Service) executeApprovalRequest(kit *rest.Kit, request *metadata.ApprovalRequest) *metadata.ApprovalRequest {
+	var client apiRest.ClientInterface
+	switch request.Request.Server {
+	case metadata.ApprovalServerTopo:
+		client = s.Engine.CoreAPI.TopoServer().Client()
+	case metadata.ApprovalServerHost:
+		client = s.Engine.CoreAPI.HostServer().Client()
+	}
+
+	header := util.BuildHeader(request.Requester, request.OwnerID)
+	header.Set(common.BKHTTPCCRequestID, kit.Rid)
+	header.Set(common.BKHTTPLanguage, request.Request.Language)
+	header.Set(common.BKHTTPApprovalRequestID, strconv.FormatInt(request.ID, 10))
+
+	status := metadata.ApprovalStatusExecuted
+	var result interface{}
+	if client == nil {
+		status = metadata.ApprovalStatusFailed
+		result = map[string]interface{}{"message": "unknown server " + string(request.Request.Server)}
+	} else if err := s.authorizeApprovalRequest(kit, request, header); err != nil {
+		blog.Errorf("authorize approval request %d as requester %s failed, err: %v, rid: %s", request.ID,
+			request.Requester, err, kit.Rid)
+		status = metadata.ApprovalStatusFailed
+		result = map[string]interface{}{"message": err.Error()}
+	} else if err := s.checkApprovalRequestFrozen(kit, request, header); err != nil {
+		blog.Errorf("approval request %d is frozen by the change freeze windows, err: %v, rid: %s", request.ID, err,
+			kit.Rid)
+		status = metadata.ApprovalStatusFailed
+		result = map[string]interface{}{"code": err.GetCode(), "message": err.Error()}
+	} else {
+		resp := new(metadata.Response)
+		err := client.Verb(apiRest.VerbType(request.Request.Method)).
+			WithContext(kit.Ctx).
+			Body(request.Request.Body).
+			SubResourcef(request.Request.Path).
+			WithHeaders(header).
+			Do().
+			Into(resp)
+
+		switch {
+		case err != nil:
+			blog.Errorf("execute approval request %d failed, err: %v, rid: %s", request.ID, err, kit.Rid)
+			status = metadata.ApprovalStatusFailed
+			result = map[string]interface{}{"message": err.Error()}
+		case !resp.Result:
+			blog.Errorf("execute approval request %d failed, code: %d, err: %s, rid: %s", request.ID, resp.Code,
+				resp.ErrMsg, kit.Rid)
+			status = metadata.ApprovalStatusFailed
+			result = map[string]interface{}{"code": resp.Code, "message": resp.ErrMsg}
+		default:
+			result = resp.Data
+		}
+	}
+
+	updateOption := metadata.UpdateApprovalStatusOption{
+		From: metadata.ApprovalStatusExecuting,
+		To:   status,
+	}
+	if data, err := json.Marshal(result); err == nil {
+		updateOption.Result = data
+	} else {
+		blog.Errorf("marshal approval request %d result failed, err: %v, rid: %s", request.ID, err, kit.Rid)
+	}
+
+	updated, err := s.Engine.CoreAPI.CoreService().Approval().UpdateApprovalRequestStatus(kit.Ctx, kit.Header,
+		request.ID, updateOption)
+	if err != nil {
+		blog.Errorf("save approval request %d result failed, status: %s, err: %v, rid: %s", request.ID, status, err,
+			kit.Rid)
+		request.Status = status
+		request.Result = updateOption.Result
+		return request
+	}
+	return updated
+}
+
+// ApprovalExpireScheduler expire the pending approval requests which are not approved or rejected in time
+func (s *Service) ApprovalExpireScheduler(ctx context.Context) {
+	ticker := time.NewTicker(approvalExpireCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !s.Engine.ServiceManageInterface.IsMaster() {
+				continue
+			}
+			s.expireApprovalRequests()
+		}
+	}
+}
+
+func (s *Service) expireApprovalRequests() {
+	header := util.BuildHeader(common.CCSystemOperatorUserName, common.BKSuperOwnerID)
+	kit := newBackgroundKit(&rest.Kit{Header: header})
+
+	expired, err := s.Engine.CoreAPI.CoreService().Approval().ExpireApprovalRequests(kit.Ctx, kit.Header)
+	if err != nil {
+		blog.Errorf("expire approval requests failed, err: %v, rid: %s", err, kit.Rid)
+		return
+	}
+
+	for index := range expired {
+		// save the audit log with the supplier account of the request, so that it belongs to the right tenant
+		requestHeader := util.BuildHeader(common.CCSystemOperatorUserName, expired[index].OwnerID)
+		requestKit := newBackgroundKit(&rest.Kit{Header: requestHeader})
+		approval.SaveRequestAudit(requestKit.Ctx, requestKit.Header, requestKit.Rid, s.Engine.CoreAPI.CoreService(),
+			&expired[index], metadata.AuditExpire)
+	}
+	if len(expired) > 0 {
+		blog.Infof("expired %d approval requests, rid: %s", len(expired), kit.Rid)
+	}
+}
@@ -184,6 +184,37 @@ func SortTopoInst(instData []*metadata.TopoInstRst) {
 	}
 }
 
+// DiffBusinessTopo compare the mainline topology of the business with another business or with its history topology
+func (s *Service) DiffBusinessTopo(ctx *rest.Contexts) {
+	bizID, err := strconv.ParseInt(ctx.Request.PathParameter(common.BKAppIDField), 10, 64)
+	if err != nil || bizID <= 0 {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, common.BKAppIDField))
+		return
+	}
+
+	input := new(metadata.TopoDiffRequest)
+	if err := ctx.DecodeInto(input); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	if key, err := input.Validate(); err != nil {
+		blog.Errorf("diff business topo, invalid input: %+v, err: %v, rid: %s", input, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, key))
+		return
+	}
+	if input.TargetBizID == bizID {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, "target_biz_id"))
+		return
+	}
+
+	result, err := s.Core.AssociationOperation().DiffBusinessTopo(ctx.Kit, bizID, input)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(result)
+}
+
 // SearchBriefBizTopo search brief topo
 func (s *Service) SearchBriefBizTopo(ctx *rest.Contexts) {
 	bizID, err := strconv.ParseInt(ctx.Request.PathParameter(common.BKAppIDField), 10, 64)
@@ -495,6 +526,12 @@ func (s *Service) SearchAssociationType(ctx *rest.Contexts) {
 		request.Condition = make(map[string]interface{}, 0)
 	}
 
+	if err := metadata.ValidateUserQueryOperators(request.Condition); err != nil {
+		blog.Errorf("search association types failed, condition is invalid, err: %v, rid: %s", err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "condition"))
+		return
+	}
+
 	ret, err := s.Core.AssociationOperation().SearchType(ctx.Kit, request)
 	if err != nil {
 		ctx.RespAutoError(err)
@@ -637,6 +674,12 @@ func (s *Service) SearchAssociationInst(ctx *rest.Contexts) {
 		return
 	}
 
+	if err := metadata.ValidateUserQueryOperators(request.Condition); err != nil {
+		blog.Errorf("search association instances failed, condition is invalid, err: %v, rid: %s", err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "condition"))
+		return
+	}
+
 	ctx.SetReadPreference(common.SecondaryPreferredMode)
 	ret, err := s.Core.AssociationOperation().SearchInst(ctx.Kit, request)
 	if err != nil {
@@ -691,6 +734,29 @@ func (s *Service) SearchAssociationRelatedInst(ctx *rest.Contexts) {
 	ctx.RespEntity(ret.Data)
 }
 
+// SearchInstAssociationGraph search the instances associated with the start instance in several hops
+func (s *Service) SearchInstAssociationGraph(ctx *rest.Contexts) {
+	request := &metadata.SearchInstAssociationGraphRequest{}
+	if err := ctx.DecodeInto(request); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if key, err := request.Validate(); err != nil {
+		blog.Errorf("search instance association graph failed, invalid param, key: %s, err: %v, rid: %s", key, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, key))
+		return
+	}
+
+	result, err := s.Core.AssociationOperation().SearchInstAssociationGraph(ctx.Kit, request)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	ctx.RespEntity(result)
+}
+
 func (s *Service) CreateAssociationInst(ctx *rest.Contexts) {
 	request := &metadata.CreateAssociationInstRequest{}
 	if err := ctx.DecodeInto(request); err != nil {
@@ -111,13 +111,13 @@ func (s *Service) SearchAuditList(ctx *rest.Contexts) {
 	blog.V(5).Infof("AuditQuery, AuditOperation auditQuery: %+v, rid: %s", auditQuery, ctx.Kit.Rid)
 
 	ctx.SetReadPreference(common.SecondaryPreferredMode)
-	count, list, err := s.Core.AuditOperation().SearchAuditList(ctx.Kit, auditQuery)
+	result, err := s.Core.AuditOperation().SearchAuditList(ctx.Kit, auditQuery)
 	if nil != err {
 		ctx.RespAutoError(err)
 		return
 	}
 
-	ctx.RespEntityWithCount(count, list)
+	ctx.RespEntity(result)
 }
 
 // SearchAuditDetail search audit log detail by id
@@ -149,6 +149,10 @@ func (s *Service) SearchAuditDetail(ctx *rest.Contexts) {
 		ctx.RespAutoError(err)
 		return
 	}
+	if err := s.filterAuditRestrictedFields(ctx, list); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
 	ctx.RespEntity(list)
 }
 
@@ -0,0 +1,158 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"strconv"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+)
+
+func changeFreezeAuditData(window *metadata.ChangeFreezeWindow) map[string]interface{} {
+	return map[string]interface{}{
+		common.BKFieldID:      window.ID,
+		common.BKFieldName:    window.Name,
+		common.BKAppIDField:   window.BizID,
+		"resources":           window.Resources,
+		"schedule":            window.Schedule,
+		"exempt_users":        window.ExemptUsers,
+		"exempt_apps":         window.ExemptApps,
+		"enabled":             window.Enabled,
+		common.BKOwnerIDField: window.OwnerID,
+	}
+}
+
+// saveChangeFreezeAudit save the audit log of the change freeze window, pre is nil when it's created, and cur is nil
+// when it's deleted
+func (s *Service) saveChangeFreezeAudit(kit *rest.Kit, action metadata.ActionType, pre,
+	cur *metadata.ChangeFreezeWindow) {
+
+	detail := &metadata.BasicContent{}
+	window := cur
+	if pre != nil {
+		detail.PreData = changeFreezeAuditData(pre)
+		window = pre
+	}
+	if cur != nil {
+		detail.CurData = changeFreezeAuditData(cur)
+	}
+	audit := metadata.AuditLog{
+		AuditType:       metadata.ChangeFreezeType,
+		ResourceType:    metadata.ChangeFreezeRes,
+		Action:          action,
+		BusinessID:      window.BizID,
+		ResourceID:      window.ID,
+		ResourceName:    window.Name,
+		OperationDetail: &metadata.BasicOpDetail{Details: detail},
+	}
+	if _, err := s.Engine.CoreAPI.CoreService().Audit().SaveAuditLog(kit.Ctx, kit.Header, audit); err != nil {
+		blog.Errorf("save change freeze window %d audit log failed, err: %v, rid: %s", window.ID, err, kit.Rid)
+	}
+}
+
+// CreateChangeFreezeWindow create a window which forbids the changes of the business in the schedule
+func (s *Service) CreateChangeFreezeWindow(ctx *rest.Contexts) {
+	window := metadata.ChangeFreezeWindow{}
+	if err := ctx.DecodeInto(&window); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	result, err := s.Engine.CoreAPI.CoreService().ChangeFreeze().CreateChangeFreezeWindow(ctx.Kit.Ctx,
+		ctx.Kit.Header, window)
+	if err != nil {
+		blog.Errorf("create change freeze window failed, name: %s, err: %v, rid: %s", window.Name, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	s.saveChangeFreezeAudit(ctx.Kit, metadata.AuditCreate, nil, result)
+	ctx.RespEntity(result)
+}
+
+// UpdateChangeFreezeWindow replace the settings of the change freeze window
+func (s *Service) UpdateChangeFreezeWindow(ctx *rest.Contexts) {
+	id, err := strconv.ParseInt(ctx.Request.PathParameter("id"), 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "id"))
+		return
+	}
+
+	window := metadata.ChangeFreezeWindow{}
+	if err := ctx.DecodeInto(&window); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	client := s.Engine.CoreAPI.CoreService().ChangeFreeze()
+	origin, ccErr := client.GetChangeFreezeWindow(ctx.Kit.Ctx, ctx.Kit.Header, id)
+	if ccErr != nil {
+		blog.Errorf("get change freeze window failed, id: %d, err: %v, rid: %s", id, ccErr, ctx.Kit.Rid)
+		ctx.RespAutoError(ccErr)
+		return
+	}
+
+	result, ccErr := client.UpdateChangeFreezeWindow(ctx.Kit.Ctx, ctx.Kit.Header, id, window)
+	if ccErr != nil {
+		blog.Errorf("update change freeze window failed, id: %d, err: %v, rid: %s", id, ccErr, ctx.Kit.Rid)
+		ctx.RespAutoError(ccErr)
+		return
+	}
+	s.saveChangeFreezeAudit(ctx.Kit, metadata.AuditUpdate, origin, result)
+	ctx.RespEntity(result)
+}
+
+// DeleteChangeFreezeWindow delete the change freeze window
+func (s *Service) DeleteChangeFreezeWindow(ctx *rest.Contexts) {
+	id, err := strconv.ParseInt(ctx.Request.PathParameter("id"), 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "id"))
+		return
+	}
+
+	client := s.Engine.CoreAPI.CoreService().ChangeFreeze()
+	origin, ccErr := client.GetChangeFreezeWindow(ctx.Kit.Ctx, ctx.Kit.Header, id)
+	if ccErr != nil {
+		blog.Errorf("get change freeze window failed, id: %d, err: %v, rid: %s", id, ccErr, ctx.Kit.Rid)
+		ctx.RespAutoError(ccErr)
+		return
+	}
+
+	if err := client.DeleteChangeFreezeWindow(ctx.Kit.Ctx, ctx.Kit.Header, id); err != nil {
+		blog.Errorf("delete change freeze window failed, id: %d, err: %v, rid: %s", id, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	s.saveChangeFreezeAudit(ctx.Kit, metadata.AuditDelete, origin, nil)
+	ctx.RespEntity(nil)
+}
+
+// SearchChangeFreezeWindows search the change freeze windows
+func (s *Service) SearchChangeFreezeWindows(ctx *rest.Contexts) {
+	option := metadata.SearchChangeFreezeWindowOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	result, err := s.Engine.CoreAPI.CoreService().ChangeFreeze().SearchChangeFreezeWindows(ctx.Kit.Ctx,
+		ctx.Kit.Header, option)
+	if err != nil {
+		blog.Errorf("search change freeze windows failed, err: %v, rid: %s", err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(result)
+}
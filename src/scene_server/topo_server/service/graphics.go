@@ -13,7 +13,10 @@
 package service
 
 import (
+	"strconv"
+
 	"configcenter/src/common"
+	"configcenter/src/common/blog"
 	"configcenter/src/common/http/rest"
 	"configcenter/src/common/metadata"
 )
@@ -28,9 +31,7 @@ func (s *Service) SelectObjectTopoGraphics(ctx *rest.Contexts) {
 }
 
 func (s *Service) UpdateObjectTopoGraphics(ctx *rest.Contexts) {
-	requestBody := struct {
-		Data []metadata.TopoGraphics `json:"data" field:"data"`
-	}{}
+	requestBody := metadata.UpdateTopoGraphicsOption{}
 	if err := ctx.DecodeInto(&requestBody); err != nil {
 		ctx.RespAutoError(err)
 		return
@@ -73,3 +74,90 @@ func (s *Service) UpdateObjectTopoGraphicsNew(ctx *rest.Contexts) {
 	}
 	ctx.RespEntity(nil)
 }
+
+// ExportModelGraph export the model association graph in dot, graphml or json format
+func (s *Service) ExportModelGraph(ctx *rest.Contexts) {
+	request := new(metadata.ExportModelGraphRequest)
+	if err := ctx.DecodeInto(request); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	format, err := metadata.ValidateGraphFormat(request.Format)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "format"))
+		return
+	}
+
+	graph, err := s.Core.GraphicsOperation().ExportModelGraph(ctx.Kit, request)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	s.respGraph(ctx, graph, format)
+}
+
+// ExportBizTopoGraph export the mainline instance topology of the business
+func (s *Service) ExportBizTopoGraph(ctx *rest.Contexts) {
+	bizID, err := strconv.ParseInt(ctx.Request.PathParameter(common.BKAppIDField), 10, 64)
+	if err != nil || bizID <= 0 {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKAppIDField))
+		return
+	}
+
+	request := new(metadata.ExportBizTopoGraphRequest)
+	if err := ctx.DecodeInto(request); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	format, err := metadata.ValidateGraphFormat(request.Format)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "format"))
+		return
+	}
+
+	graph, err := s.Core.GraphicsOperation().ExportBizTopoGraph(ctx.Kit, bizID)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	s.respGraph(ctx, graph, format)
+}
+
+// ExportInstGraph export the instance association subgraph around an instance
+func (s *Service) ExportInstGraph(ctx *rest.Contexts) {
+	request := new(metadata.ExportInstGraphRequest)
+	if err := ctx.DecodeInto(request); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	format, err := metadata.ValidateGraphFormat(request.Format)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "format"))
+		return
+	}
+	if key, err := request.SearchInstAssociationGraphRequest.Validate(); err != nil {
+		blog.Errorf("export instance graph failed, invalid param, key: %s, err: %v, rid: %s", key, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, key))
+		return
+	}
+
+	graph, err := s.Core.GraphicsOperation().ExportInstGraph(ctx.Kit, &request.SearchInstAssociationGraphRequest)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	s.respGraph(ctx, graph, format)
+}
+
+func (s *Service) respGraph(ctx *rest.Contexts, graph *metadata.Graph, format string) {
+	result, err := graph.Export(format)
+	if err != nil {
+		blog.Errorf("export graph in %s format failed, err: %v, rid: %s", format, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "format"))
+		return
+	}
+	ctx.RespEntity(result)
+}
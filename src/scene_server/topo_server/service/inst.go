@@ -91,6 +91,15 @@ func (s *Service) CreateInst(ctx *rest.Contexts) {
 			return
 		}
 
+		batchData := make([]mapstr.MapStr, 0, len(batchInfo.BatchInfo))
+		for _, item := range batchInfo.BatchInfo {
+			batchData = append(batchData, item)
+		}
+		if err := s.checkRestrictedFieldsEdit(ctx, objID, batchData...); err != nil {
+			ctx.RespAutoError(err)
+			return
+		}
+
 		var setInst *operation.BatchResult
 		txnErr := s.Engine.CoreAPI.CoreService().Txn().AutoRunTxn(ctx.Kit.Ctx, s.EnableTxn, ctx.Kit.Header, func() error {
 			var err error
@@ -110,6 +119,11 @@ func (s *Service) CreateInst(ctx *rest.Contexts) {
 		return
 	}
 
+	if err := s.checkRestrictedFieldsEdit(ctx, objID, data); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
 	var setInst inst.Inst
 	txnErr := s.Engine.CoreAPI.CoreService().Txn().AutoRunTxn(ctx.Kit.Ctx, s.EnableTxn, ctx.Kit.Header, func() error {
 		var err error
@@ -334,6 +348,15 @@ func (s *Service) UpdateInsts(ctx *rest.Contexts) {
 		// TODO add custom mainline instance param validation
 	}
 
+	updateData := make([]mapstr.MapStr, 0, len(updateCondition.Update))
+	for _, item := range updateCondition.Update {
+		updateData = append(updateData, item.InstInfo)
+	}
+	if err := s.checkRestrictedFieldsEdit(ctx, objID, updateData...); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
 	txnErr := s.Engine.CoreAPI.CoreService().Txn().AutoRunTxn(ctx.Kit.Ctx, s.EnableTxn, ctx.Kit.Header, func() error {
 		instanceIDs := make([]int64, 0)
 		for _, item := range updateCondition.Update {
@@ -402,6 +425,11 @@ func (s *Service) UpdateInst(ctx *rest.Contexts) {
 		// TODO add custom mainline instance param validation
 	}
 
+	if err := s.checkRestrictedFieldsEdit(ctx, objID, data); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
 	cond := condition.CreateCondition()
 	cond.Field(obj.GetInstIDFieldName()).Eq(instID)
 
@@ -446,6 +474,15 @@ func (s *Service) SearchInsts(ctx *rest.Contexts) {
 	if queryCond.Condition == nil {
 		queryCond.Condition = mapstr.New()
 	}
+	if err := metadata.ValidateUserQueryOperators(queryCond.Condition); err != nil {
+		blog.Errorf("search instances failed, condition is invalid, err: %v, rid: %s", err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "condition"))
+		return
+	}
+	if err := s.checkRestrictedFieldsSearch(ctx, objID, searchParamsFields(queryCond)...); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
 	page := metadata.ParsePage(queryCond.Page)
 	query := &metadata.QueryInput{}
 	query.Condition = queryCond.Condition
@@ -453,17 +490,29 @@ func (s *Service) SearchInsts(ctx *rest.Contexts) {
 	query.Limit = page.Limit
 	query.Sort = page.Sort
 	query.Start = page.Start
+	query.Filter = queryCond.Filter
+	query.Cursor = page.Cursor
 
-	cnt, instItems, err := s.Core.InstOperation().FindInst(ctx.Kit, obj, query, false)
+	instResult, err := s.Core.InstOperation().FindOriginInst(ctx.Kit, obj.GetObjectID(), query)
 	if nil != err {
 		blog.Errorf("[api-inst] failed to find the objects(%s), error info is %s, rid: %s", ctx.Request.PathParameter("obj_id"), err.Error(), ctx.Kit.Rid)
 		ctx.RespAutoError(err)
 		return
 	}
 
+	if instResult.Info == nil {
+		instResult.Info = make([]mapstr.MapStr, 0)
+	}
+	if err := s.filterRestrictedFields(ctx, obj.GetObjectID(), instResult.Info...); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
 	result := mapstr.MapStr{}
-	result.Set("count", cnt)
-	result.Set("info", instItems)
+	result.Set("count", instResult.Count)
+	result.Set("info", instResult.Info)
+	if instResult.NextCursor != "" {
+		result.Set("next_cursor", instResult.NextCursor)
+	}
 	ctx.RespEntity(result)
 }
 
@@ -483,6 +532,15 @@ func (s *Service) SearchInstAndAssociationDetail(ctx *rest.Contexts) {
 	if queryCond.Condition == nil {
 		queryCond.Condition = mapstr.New()
 	}
+	if err := metadata.ValidateUserQueryOperators(queryCond.Condition); err != nil {
+		blog.Errorf("search instances failed, condition is invalid, err: %v, rid: %s", err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "condition"))
+		return
+	}
+	if err := s.checkRestrictedFieldsSearch(ctx, objID, searchParamsFields(queryCond)...); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
 	page := metadata.ParsePage(queryCond.Page)
 
 	query := &metadata.QueryInput{}
@@ -491,6 +549,7 @@ func (s *Service) SearchInstAndAssociationDetail(ctx *rest.Contexts) {
 	query.Limit = page.Limit
 	query.Sort = page.Sort
 	query.Start = page.Start
+	query.Filter = queryCond.Filter
 
 	result, err := s.Core.InstOperation().FindOriginInst(ctx.Kit, objID, query)
 	if nil != err {
@@ -498,6 +557,10 @@ func (s *Service) SearchInstAndAssociationDetail(ctx *rest.Contexts) {
 		ctx.RespAutoError(err)
 		return
 	}
+	if err := s.filterRestrictedFields(ctx, objID, result.Info...); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
 	ctx.RespEntity(result)
 }
 
@@ -523,6 +586,15 @@ func (s *Service) SearchInstByObject(ctx *rest.Contexts) {
 	if queryCond.Condition == nil {
 		queryCond.Condition = mapstr.New()
 	}
+	if err := metadata.ValidateUserQueryOperators(queryCond.Condition); err != nil {
+		blog.Errorf("search instances failed, condition is invalid, err: %v, rid: %s", err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "condition"))
+		return
+	}
+	if err := s.checkRestrictedFieldsSearch(ctx, objID, searchParamsFields(queryCond)...); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
 	page := metadata.ParsePage(queryCond.Page)
 	query := &metadata.QueryInput{}
 	query.Condition = queryCond.Condition
@@ -530,12 +602,17 @@ func (s *Service) SearchInstByObject(ctx *rest.Contexts) {
 	query.Limit = page.Limit
 	query.Sort = page.Sort
 	query.Start = page.Start
+	query.Filter = queryCond.Filter
 	cnt, instItems, err := s.Core.InstOperation().FindInst(ctx.Kit, obj, query, false)
 	if nil != err {
 		blog.Errorf("[api-inst] failed to find the objects(%s), error info is %s, rid: %s", ctx.Request.PathParameter("bk_obj_id"), err.Error(), ctx.Kit.Rid)
 		ctx.RespAutoError(err)
 		return
 	}
+	if err := s.filterInstRestrictedFields(ctx, objID, instItems); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
 
 	result := mapstr.MapStr{}
 	result.Set("count", cnt)
@@ -562,6 +639,10 @@ func (s *Service) SearchInstByAssociation(ctx *rest.Contexts) {
 		ctx.RespAutoError(err)
 		return
 	}
+	if err := s.filterRestrictedFields(ctx, objID, result.Info...); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
 
 	ctx.RespEntity(result)
 }
@@ -594,6 +675,10 @@ func (s *Service) SearchInstByInstID(ctx *rest.Contexts) {
 		ctx.RespAutoError(err)
 		return
 	}
+	if err := s.filterInstRestrictedFields(ctx, objID, instItems); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
 
 	result := mapstr.MapStr{}
 	result.Set("count", cnt)
@@ -32,6 +32,7 @@ import (
 	gparams "configcenter/src/common/paraparse"
 	"configcenter/src/common/util"
 	"configcenter/src/scene_server/topo_server/core/inst"
+	"configcenter/src/scene_server/topo_server/core/model"
 	"configcenter/src/thirdparty/hooks"
 )
 
@@ -61,37 +62,8 @@ func (s *Service) CreateBusiness(ctx *rest.Contexts) {
 	var business inst.Inst
 	txnErr := s.Engine.CoreAPI.CoreService().Txn().AutoRunTxn(ctx.Kit.Ctx, s.EnableTxn, ctx.Kit.Header, func() error {
 		var err error
-		business, err = s.Core.BusinessOperation().CreateBusiness(ctx.Kit, obj, data)
-		if err != nil {
-			blog.Errorf("create business failed, err: %v, rid: %s", err, ctx.Kit.Rid)
-			return err
-		}
-
-		// register business resource creator action to iam
-		if auth.EnableAuthorize() {
-			var bizID int64
-			if bizID, err = business.GetBizID(); err != nil {
-				blog.ErrorJSON("get biz id failed, err: %s, biz: %s, rid: %s", err, business, ctx.Kit.Rid)
-				return err
-			}
-			var bizName string
-			if bizName, err = business.GetInstName(); err != nil {
-				blog.ErrorJSON("get biz name failed, err: %s, biz: %s, rid: %s", err, business, ctx.Kit.Rid)
-				return err
-			}
-			iamInstance := metadata.IamInstanceWithCreator{
-				Type:    string(iam.Business),
-				ID:      strconv.FormatInt(bizID, 10),
-				Name:    bizName,
-				Creator: ctx.Kit.User,
-			}
-			_, err = s.AuthManager.Authorizer.RegisterResourceCreatorAction(ctx.Kit.Ctx, ctx.Kit.Header, iamInstance)
-			if err != nil {
-				blog.Errorf("register created business to iam failed, err: %v, rid: %s", err, ctx.Kit.Rid)
-				return err
-			}
-		}
-		return nil
+		business, err = s.createBusiness(ctx.Kit, obj, data)
+		return err
 	})
 
 	if txnErr != nil {
@@ -101,6 +73,41 @@ func (s *Service) CreateBusiness(ctx *rest.Contexts) {
 	ctx.RespEntity(business)
 }
 
+// createBusiness create business and register business resource creator action to iam
+func (s *Service) createBusiness(kit *rest.Kit, obj model.Object, data mapstr.MapStr) (inst.Inst, error) {
+	business, err := s.Core.BusinessOperation().CreateBusiness(kit, obj, data)
+	if err != nil {
+		blog.Errorf("create business failed, err: %v, rid: %s", err, kit.Rid)
+		return nil, err
+	}
+
+	// register business resource creator action to iam
+	if auth.EnableAuthorize() {
+		var bizID int64
+		if bizID, err = business.GetBizID(); err != nil {
+			blog.ErrorJSON("get biz id failed, err: %s, biz: %s, rid: %s", err, business, kit.Rid)
+			return nil, err
+		}
+		var bizName string
+		if bizName, err = business.GetInstName(); err != nil {
+			blog.ErrorJSON("get biz name failed, err: %s, biz: %s, rid: %s", err, business, kit.Rid)
+			return nil, err
+		}
+		iamInstance := metadata.IamInstanceWithCreator{
+			Type:    string(iam.Business),
+			ID:      strconv.FormatInt(bizID, 10),
+			Name:    bizName,
+			Creator: kit.User,
+		}
+		_, err = s.AuthManager.Authorizer.RegisterResourceCreatorAction(kit.Ctx, kit.Header, iamInstance)
+		if err != nil {
+			blog.Errorf("register created business to iam failed, err: %v, rid: %s", err, kit.Rid)
+			return nil, err
+		}
+	}
+	return business, nil
+}
+
 // DeleteBusiness delete the business
 func (s *Service) DeleteBusiness(ctx *rest.Contexts) {
 	obj, err := s.Core.ObjectOperation().FindSingleObject(ctx.Kit, common.BKInnerObjIDApp)
@@ -117,6 +124,11 @@ func (s *Service) DeleteBusiness(ctx *rest.Contexts) {
 		return
 	}
 
+	preview := s.approvalInstPreview(ctx.Kit, common.BKInnerObjIDApp, mapstr.MapStr{common.BKAppIDField: bizID})
+	if !s.approvalGate().Check(ctx, metadata.ApprovalOperationDeleteBusiness, bizID, nil, preview) {
+		return
+	}
+
 	txnErr := s.Engine.CoreAPI.CoreService().Txn().AutoRunTxn(ctx.Kit.Ctx, s.EnableTxn, ctx.Kit.Header, func() error {
 		if err := s.Core.BusinessOperation().DeleteBusiness(ctx.Kit, obj, bizID); err != nil {
 			return err
@@ -430,6 +442,18 @@ func (s *Service) SearchBusiness(ctx *rest.Contexts) {
 		userFields = append(userFields, attribute.PropertyID)
 	}
 
+	if err := metadata.ValidateUserQueryOperators(searchCond.Condition); err != nil {
+		blog.Errorf("search business failed, condition is invalid, err: %v, rid: %s", err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "condition"))
+		return
+	}
+	searchFields := append(metadata.QueryConditionFields(searchCond.Condition),
+		metadata.SortFields(searchCond.Page.Sort)...)
+	if err := s.checkRestrictedFieldsSearch(ctx, common.BKInnerObjIDApp, searchFields...); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
 	searchCond.Condition = handleSpecialBusinessFieldSearchCond(searchCond.Condition, userFields)
 
 	// parse business id from user's condition for testing.
@@ -528,6 +552,10 @@ func (s *Service) SearchBusiness(ctx *rest.Contexts) {
 		ctx.RespAutoError(err)
 		return
 	}
+	if err := s.filterRestrictedFields(ctx, common.BKInnerObjIDApp, instItems...); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
 
 	result := mapstr.MapStr{}
 	result.Set("count", cnt)
@@ -0,0 +1,193 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"strconv"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/thirdparty/hooks"
+
+	"github.com/rs/xid"
+)
+
+// CloneBusinessTopo 将业务的主线拓扑与模板绑定克隆到新建或空的业务中, 克隆通过 task_server 异步执行
+func (s *Service) CloneBusinessTopo(ctx *rest.Contexts) {
+	srcBizID, err := strconv.ParseInt(ctx.Request.PathParameter(common.BKAppIDField), 10, 64)
+	if err != nil || srcBizID <= 0 {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, common.BKAppIDField))
+		return
+	}
+
+	input := metadata.CloneBizTopoRequest{}
+	if err := ctx.DecodeInto(&input); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	if key, err := input.Validate(); err != nil {
+		blog.Errorf("clone business topo, invalid input: %+v, err: %v, rid: %s", input, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, key))
+		return
+	}
+	if input.TargetBizID == srcBizID {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, "bk_target_biz_id"))
+		return
+	}
+
+	bizID := input.TargetBizID
+	if bizID != 0 {
+		if err := s.checkCloneBizTopoTaskRunning(ctx.Kit, bizID); err != nil {
+			ctx.RespAutoError(err)
+			return
+		}
+		if err := s.Core.BusinessOperation().ValidateCloneTarget(ctx.Kit, bizID); err != nil {
+			ctx.RespAutoError(err)
+			return
+		}
+	} else {
+		if bizID, err = s.createCloneTargetBusiness(ctx.Kit, input.Business); err != nil {
+			ctx.RespAutoError(err)
+			return
+		}
+	}
+
+	task := metadata.CloneBizTopoTask{
+		CloneID:  xid.New().String(),
+		SrcBizID: srcBizID,
+		BizID:    bizID,
+		Option:   input.CloneBizTopoOption,
+	}
+	createResult, err := s.Engine.CoreAPI.TaskServer().Task().Create(ctx.Kit.Ctx, ctx.Kit.Header,
+		common.CloneBizTopoTaskName, metadata.GetCloneBizTopoIndex(bizID), []interface{}{task})
+	if err != nil {
+		blog.Errorf("dispatch clone business topo task failed, task: %+v, err: %v, rid: %s", task, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommHTTPDoRequestFailed))
+		return
+	}
+	if ccErr := createResult.CCError(); ccErr != nil {
+		blog.ErrorJSON("dispatch clone business topo task failed, task: %s, result: %s, rid: %s", task, createResult, ctx.Kit.Rid)
+		ctx.RespAutoError(ccErr)
+		return
+	}
+
+	ctx.RespEntity(metadata.CloneBizTopoTaskResult{
+		TaskID:     createResult.Data.TaskID,
+		SrcBizID:   srcBizID,
+		BizID:      bizID,
+		Status:     createResult.Data.Status,
+		CreateTime: createResult.Data.CreateTime,
+		LastTime:   createResult.Data.LastTime,
+	})
+}
+
+// createCloneTargetBusiness 新建克隆的目标业务
+func (s *Service) createCloneTargetBusiness(kit *rest.Kit, data mapstr.MapStr) (int64, error) {
+	if err := hooks.ValidateCreateBusinessHook(kit, s.Engine.CoreAPI, data); err != nil {
+		blog.Errorf("validate create business hook failed, err: %v, rid: %s", err, kit.Rid)
+		return 0, err
+	}
+
+	obj, err := s.Core.ObjectOperation().FindSingleObject(kit, common.BKInnerObjIDApp)
+	if err != nil {
+		blog.Errorf("failed to search the business, %v, rid: %s", err, kit.Rid)
+		return 0, err
+	}
+
+	data.Set(common.BKDefaultField, common.DefaultFlagDefaultValue)
+	var bizID int64
+	txnErr := s.Engine.CoreAPI.CoreService().Txn().AutoRunTxn(kit.Ctx, s.EnableTxn, kit.Header, func() error {
+		business, err := s.createBusiness(kit, obj, data)
+		if err != nil {
+			return err
+		}
+		bizID, err = business.GetBizID()
+		return err
+	})
+	if txnErr != nil {
+		return 0, txnErr
+	}
+	return bizID, nil
+}
+
+// checkCloneBizTopoTaskRunning 同一个目标业务同时只能有一个克隆任务
+func (s *Service) checkCloneBizTopoTaskRunning(kit *rest.Kit, bizID int64) errors.CCErrorCoder {
+	listOption := metadata.ListAPITaskRequest{
+		Condition: mapstr.MapStr{
+			"flag": metadata.GetCloneBizTopoIndex(bizID),
+			"status": map[string]interface{}{
+				common.BKDBIN: []metadata.APITaskStatus{
+					metadata.APITaskStatusNew,
+					metadata.APITaskStatusWaitExecute,
+					metadata.APITaskStatuExecute,
+				},
+			},
+		},
+		Page: metadata.BasePage{Limit: 1},
+	}
+	listResult, err := s.Engine.CoreAPI.TaskServer().Task().ListTask(kit.Ctx, kit.Header, common.CloneBizTopoTaskName, &listOption)
+	if err != nil {
+		blog.ErrorJSON("list clone business topo tasks failed, option: %s, err: %s, rid: %s", listOption, err, kit.Rid)
+		return kit.CCError.CCError(common.CCErrTaskListTaskFail)
+	}
+	if ccErr := listResult.CCError(); ccErr != nil {
+		return ccErr
+	}
+	if len(listResult.Data.Info) > 0 {
+		return kit.CCError.CCError(common.CCErrorTopoCloneBizTaskIsRunning)
+	}
+	return nil
+}
+
+// GetCloneBusinessTopoTask 查询克隆到目标业务的最近一次克隆任务的状态及新旧ID映射
+func (s *Service) GetCloneBusinessTopoTask(ctx *rest.Contexts) {
+	bizID, err := strconv.ParseInt(ctx.Request.PathParameter(common.BKAppIDField), 10, 64)
+	if err != nil || bizID <= 0 {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, common.BKAppIDField))
+		return
+	}
+
+	listOption := metadata.ListAPITaskRequest{
+		Condition: mapstr.MapStr{"flag": metadata.GetCloneBizTopoIndex(bizID)},
+		Page: metadata.BasePage{
+			Sort:  "-create_time",
+			Limit: 1,
+		},
+	}
+	listResult, err := s.Engine.CoreAPI.TaskServer().Task().ListTask(ctx.Kit.Ctx, ctx.Kit.Header, common.CloneBizTopoTaskName, &listOption)
+	if err != nil {
+		blog.ErrorJSON("list clone business topo tasks failed, option: %s, err: %s, rid: %s", listOption, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrTaskListTaskFail))
+		return
+	}
+	if ccErr := listResult.CCError(); ccErr != nil {
+		ctx.RespAutoError(ccErr)
+		return
+	}
+	if len(listResult.Data.Info) == 0 {
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommNotFound))
+		return
+	}
+
+	result, err := metadata.NewCloneBizTopoTaskResult(listResult.Data.Info[0])
+	if err != nil {
+		blog.Errorf("parse clone business topo task failed, task: %s, err: %v, rid: %s", listResult.Data.Info[0].TaskID, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommParseDataFailed))
+		return
+	}
+	ctx.RespEntity(result)
+}
@@ -305,11 +305,7 @@ func (s *Service) ListModulesByServiceTemplateID(ctx *rest.Contexts) {
 		return
 	}
 
-	requestBody := struct {
-		Page    *metadata.BasePage `field:"page" json:"page" mapstructure:"page"`
-		Keyword string             `field:"keyword" json:"keyword" mapstructure:"keyword"`
-		Modules []int64            `field:"bk_module_ids" json:"bk_module_ids" mapstructure:"bk_module_ids"`
-	}{}
+	requestBody := metadata.ListModulesByServiceTemplateOption{}
 	if err := ctx.DecodeInto(&requestBody); err != nil {
 		ctx.RespAutoError(err)
 		return
@@ -408,6 +404,15 @@ func (s *Service) searchModule(ctx *rest.Contexts, bizID, setID int64) {
 	if paramsCond.Condition == nil {
 		paramsCond.Condition = mapstr.New()
 	}
+	if err := metadata.ValidateUserQueryOperators(paramsCond.Condition); err != nil {
+		blog.Errorf("search module failed, condition is invalid, err: %v, rid: %s", err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "condition"))
+		return
+	}
+	if err := s.checkRestrictedFieldsSearch(ctx, common.BKInnerObjIDModule, searchParamsFields(paramsCond)...); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
 
 	paramsCond.Condition[common.BKAppIDField] = bizID
 
@@ -440,6 +445,10 @@ func (s *Service) searchModule(ctx *rest.Contexts, bizID, setID int64) {
 		ctx.RespAutoError(err)
 		return
 	}
+	if err := s.filterRestrictedFields(ctx, common.BKInnerObjIDModule, instItems...); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
 
 	result := mapstr.MapStr{}
 	result.Set("count", cnt)
@@ -508,12 +517,7 @@ func (s *Service) SearchModuleWithRelation(ctx *rest.Contexts) {
 		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKAppIDField))
 		return
 	}
-	data := struct {
-		BkSetIdS             []int64           `json:"bk_set_ids"`
-		BkServiceTemplateIds []int64           `json:"bk_service_template_ids"`
-		Fields               []string          `json:"fields"`
-		Page                 metadata.BasePage `json:"page"`
-	}{}
+	data := metadata.SearchModuleWithRelationOption{}
 	if err := ctx.DecodeInto(&data); nil != err {
 		ctx.RespAutoError(err)
 		return
@@ -580,12 +584,12 @@ func (s *Service) SearchRuleRelatedTopoNodes(ctx *rest.Contexts) {
 		return
 	}
 	if requestBody.QueryFilter == nil {
-		blog.V(3).Info("SearchRuleRelatedModules failed, search query_filter should'nt be empty, rid: %s", ctx.Kit.Rid)
+		blog.V(3).Infof("SearchRuleRelatedModules failed, search query_filter should'nt be empty, rid: %s", ctx.Kit.Rid)
 		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "query_filter"))
 		return
 	}
 	if key, err := requestBody.QueryFilter.Validate(); err != nil {
-		blog.V(3).Info("SearchRuleRelatedModules failed, search query_filter.%s validate failed, err: %+v, rid: %s", key, err, ctx.Kit.Rid)
+		blog.V(3).Infof("SearchRuleRelatedModules failed, search query_filter.%s validate failed, err: %+v, rid: %s", key, err, ctx.Kit.Rid)
 		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "query_filter."+key))
 		return
 	}
@@ -36,12 +36,7 @@ func (s *Service) BatchCreateSet(ctx *rest.Contexts) {
 		return
 	}
 
-	type BatchCreateSetRequest struct {
-		// shared fields
-		BkSupplierAccount string                   `json:"bk_supplier_account"`
-		Sets              []map[string]interface{} `json:"sets"`
-	}
-	batchBody := BatchCreateSetRequest{}
+	batchBody := metadata.BatchCreateSetRequest{}
 	if err := ctx.DecodeInto(&batchBody); err != nil {
 		ctx.RespAutoError(err)
 		return
@@ -54,12 +49,7 @@ func (s *Service) BatchCreateSet(ctx *rest.Contexts) {
 		return
 	}
 
-	type OneSetCreateResult struct {
-		Index    int         `json:"index"`
-		Data     interface{} `json:"data"`
-		ErrorMsg string      `json:"error_message"`
-	}
-	batchCreateResult := make([]OneSetCreateResult, 0)
+	batchCreateResult := make([]metadata.OneSetCreateResult, 0)
 	var firstErr error
 	for idx, set := range batchBody.Sets {
 		if _, ok := set[common.BkSupplierAccount]; ok == false {
@@ -86,7 +76,7 @@ func (s *Service) BatchCreateSet(ctx *rest.Contexts) {
 		if txnErr != nil {
 			errMsg = txnErr.Error()
 		}
-		batchCreateResult = append(batchCreateResult, OneSetCreateResult{
+		batchCreateResult = append(batchCreateResult, metadata.OneSetCreateResult{
 			Index:    idx,
 			Data:     result,
 			ErrorMsg: errMsg,
@@ -215,6 +205,12 @@ func (s *Service) DeleteSets(ctx *rest.Contexts) {
 		return
 	}
 
+	preview := s.approvalInstPreview(ctx.Kit, common.BKInnerObjIDSet,
+		mapstr.MapStr{common.BKSetIDField: mapstr.MapStr{common.BKDBIN: setIDs}})
+	if !s.approvalGate().Check(ctx, metadata.ApprovalOperationDeleteSet, bizID, data, preview) {
+		return
+	}
+
 	txnErr := s.Engine.CoreAPI.CoreService().Txn().AutoRunTxn(ctx.Kit.Ctx, s.EnableTxn, ctx.Kit.Header, func() error {
 		err = s.Core.SetOperation().DeleteSet(ctx.Kit, obj, bizID, data.Delete.InstID)
 		if err != nil {
@@ -266,6 +262,11 @@ func (s *Service) DeleteSet(ctx *rest.Contexts) {
 		return
 	}
 
+	preview := s.approvalInstPreview(ctx.Kit, common.BKInnerObjIDSet, mapstr.MapStr{common.BKSetIDField: setID})
+	if !s.approvalGate().Check(ctx, metadata.ApprovalOperationDeleteSet, bizID, nil, preview) {
+		return
+	}
+
 	txnErr := s.Engine.CoreAPI.CoreService().Txn().AutoRunTxn(ctx.Kit.Ctx, s.EnableTxn, ctx.Kit.Header, func() error {
 		err = s.Core.SetOperation().DeleteSet(ctx.Kit, obj, bizID, []int64{setID})
 		if err != nil {
@@ -350,6 +351,15 @@ func (s *Service) SearchSet(ctx *rest.Contexts) {
 	if paramsCond.Condition == nil {
 		paramsCond.Condition = mapstr.New()
 	}
+	if err := metadata.ValidateUserQueryOperators(paramsCond.Condition); err != nil {
+		blog.Errorf("search set failed, condition is invalid, err: %v, rid: %s", err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "condition"))
+		return
+	}
+	if err := s.checkRestrictedFieldsSearch(ctx, common.BKInnerObjIDSet, searchParamsFields(paramsCond)...); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
 
 	obj, err := s.Core.ObjectOperation().FindSingleObject(ctx.Kit, common.BKInnerObjIDSet)
 	if nil != err {
@@ -374,6 +384,10 @@ func (s *Service) SearchSet(ctx *rest.Contexts) {
 		ctx.RespAutoError(err)
 		return
 	}
+	if err := s.filterInstRestrictedFields(ctx, common.BKInnerObjIDSet, instItems); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
 
 	result := mapstr.MapStr{}
 	result.Set("count", cnt)
@@ -13,10 +13,16 @@
 package service
 
 import (
+	"time"
+
+	"configcenter/src/common"
 	"configcenter/src/common/blog"
 	"configcenter/src/common/http/rest"
+	"configcenter/src/common/json"
+	"configcenter/src/common/lock"
 	"configcenter/src/common/metadata"
 	"configcenter/src/scene_server/topo_server/core/settemplate"
+	"configcenter/src/storage/driver/redis"
 )
 
 func (s *Service) SyncModuleTaskHandler(ctx *rest.Contexts) {
@@ -48,3 +54,168 @@ func (s *Service) SyncModuleTaskHandler(ctx *rest.Contexts) {
 	ctx.RespEntity(nil)
 
 }
+
+const (
+	// cloneBizTopoLockExpire 克隆任务执行锁的过期时间, 需要大于克隆任务的执行时间
+	cloneBizTopoLockExpire = 30 * time.Minute
+	// cloneBizTopoProgressExpire 克隆任务执行进度的保留时间
+	cloneBizTopoProgressExpire = 7 * 24 * time.Hour
+)
+
+func cloneBizTopoProgressKey(cloneID string) string {
+	return common.BKCacheKeyV3Prefix + "topo:clone_biz_topo:progress:" + cloneID
+}
+
+// CloneBizTopoTaskHandler task_server 回调执行克隆业务拓扑, 返回的新旧ID映射由 task_server 记录在子任务的结果中.
+// 每个阶段提交后记录执行进度, 任务重试时跳过已完成的阶段, 且不再校验目标业务为空; 失败时返回已完成阶段的部分映射.
+func (s *Service) CloneBizTopoTaskHandler(ctx *rest.Contexts) {
+	task := metadata.CloneBizTopoTask{}
+	if err := ctx.DecodeInto(&task); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if len(task.CloneID) == 0 {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsNeedSet, "clone_id"))
+		return
+	}
+	if task.SrcBizID == task.BizID {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKAppIDField))
+		return
+	}
+
+	locker := lock.NewLocker(redis.Client())
+	locked, err := locker.Lock(lock.GetLockKey(lock.CloneBizTopoFormat, task.CloneID), cloneBizTopoLockExpire)
+	if err != nil {
+		blog.Errorf("get clone business topo lock failed, clone id: %s, err: %v, rid: %s", task.CloneID, err,
+			ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommRedisOPErr))
+		return
+	}
+	if !locked {
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrorTopoCloneBizTaskIsRunning))
+		return
+	}
+	defer locker.Unlock()
+
+	bizOperation := s.Core.BusinessOperation()
+	progress, err := s.getCloneBizTopoProgress(ctx.Kit, task.CloneID)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	if progress == nil {
+		// 首次执行, 目标业务需要为空; 重试时目标业务中已有之前阶段克隆的资源
+		if err := bizOperation.ValidateCloneTarget(ctx.Kit, task.BizID); err != nil {
+			ctx.RespAutoError(err)
+			return
+		}
+		progress = &metadata.CloneBizTopoProgress{
+			FinishedStages: make([]string, 0),
+			Result:         metadata.NewCloneBizTopoResult(task.SrcBizID, task.BizID),
+		}
+	}
+
+	// 上次执行中已提交但注册iam失败的资源
+	if err := s.registerCloneBizTopoCreators(ctx.Kit, task.CloneID, progress); err != nil {
+		ctx.RespEntityWithError(progress.Result, err)
+		return
+	}
+
+	// each stage is committed in its own transaction so that the transaction does not exceed the time limit with
+	// large topology, the created resources are registered to iam after the transaction is committed
+	for _, stage := range bizOperation.CloneBusinessTopoStages(task) {
+		if progress.IsFinished(stage.Name) {
+			continue
+		}
+
+		var stageResult *metadata.CloneBizTopoResult
+		var created []metadata.IamInstanceWithCreator
+		txnErr := s.Engine.CoreAPI.CoreService().Txn().AutoRunTxn(ctx.Kit.Ctx, s.EnableTxn, ctx.Kit.Header, func() error {
+			var err error
+			// the mappings of the stage are recorded on a copy, which is dropped if the stage is rolled back
+			if stageResult, err = progress.Result.Clone(); err != nil {
+				blog.Errorf("copy clone business topo result failed, err: %v, rid: %s", err, ctx.Kit.Rid)
+				return ctx.Kit.CCError.CCError(common.CCErrCommParseDataFailed)
+			}
+			created, err = stage.Run(ctx.Kit, stageResult)
+			if err != nil {
+				blog.ErrorJSON("clone business topo stage %s failed, task: %s, err: %s, rid: %s", stage.Name, task,
+					err, ctx.Kit.Rid)
+				return err
+			}
+			return nil
+		})
+		if txnErr != nil {
+			ctx.RespEntityWithError(progress.Result, txnErr)
+			return
+		}
+
+		progress.Result = stageResult
+		progress.FinishedStages = append(progress.FinishedStages, stage.Name)
+		progress.Unregistered = created
+		if err := s.registerCloneBizTopoCreators(ctx.Kit, task.CloneID, progress); err != nil {
+			ctx.RespEntityWithError(progress.Result, err)
+			return
+		}
+	}
+
+	if err := redis.Client().Del(ctx.Kit.Ctx, cloneBizTopoProgressKey(task.CloneID)).Err(); err != nil {
+		blog.Errorf("delete clone business topo progress failed, clone id: %s, err: %v, rid: %s", task.CloneID,
+			err, ctx.Kit.Rid)
+	}
+	ctx.RespEntity(progress.Result)
+}
+
+// registerCloneBizTopoCreators 保存执行进度后将未注册的资源注册到iam, 注册成功后再次保存进度
+func (s *Service) registerCloneBizTopoCreators(kit *rest.Kit, cloneID string,
+	progress *metadata.CloneBizTopoProgress) error {
+
+	if err := s.saveCloneBizTopoProgress(kit, cloneID, progress); err != nil {
+		return err
+	}
+	if len(progress.Unregistered) == 0 {
+		return nil
+	}
+
+	if err := s.Core.BusinessOperation().RegisterCloneCreators(kit, progress.Unregistered); err != nil {
+		return err
+	}
+	progress.Unregistered = nil
+	return s.saveCloneBizTopoProgress(kit, cloneID, progress)
+}
+
+// getCloneBizTopoProgress 获取克隆任务的执行进度, 任务未执行过时返回nil
+func (s *Service) getCloneBizTopoProgress(kit *rest.Kit, cloneID string) (*metadata.CloneBizTopoProgress, error) {
+	value, err := redis.Client().Get(kit.Ctx, cloneBizTopoProgressKey(cloneID)).Result()
+	if err != nil {
+		if redis.IsNilErr(err) {
+			return nil, nil
+		}
+		blog.Errorf("get clone business topo progress failed, clone id: %s, err: %v, rid: %s", cloneID, err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommRedisOPErr)
+	}
+
+	progress := new(metadata.CloneBizTopoProgress)
+	if err := json.Unmarshal([]byte(value), progress); err != nil {
+		blog.Errorf("unmarshal clone business topo progress %s failed, err: %v, rid: %s", value, err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommJSONUnmarshalFailed)
+	}
+	return progress, nil
+}
+
+func (s *Service) saveCloneBizTopoProgress(kit *rest.Kit, cloneID string,
+	progress *metadata.CloneBizTopoProgress) error {
+
+	value, err := json.Marshal(progress)
+	if err != nil {
+		blog.Errorf("marshal clone business topo progress failed, err: %v, rid: %s", err, kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommJSONMarshalFailed)
+	}
+	err = redis.Client().Set(kit.Ctx, cloneBizTopoProgressKey(cloneID), string(value), cloneBizTopoProgressExpire).Err()
+	if err != nil {
+		blog.Errorf("save clone business topo progress failed, clone id: %s, err: %v, rid: %s", cloneID, err, kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommRedisOPErr)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+)
+
+// ExportModelSchema export the live model as a declarative schema
+func (s *Service) ExportModelSchema(ctx *rest.Contexts) {
+	option := metadata.ExportModelSchemaOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	schema, err := s.Core.SchemaOperation().ExportSchema(ctx.Kit, option)
+	if err != nil {
+		blog.Errorf("export model schema failed, option: %#v, err: %v, rid: %s", option, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(schema)
+}
+
+// PlanModelSchema diff the schema with the live model, returns the changes without applying them
+func (s *Service) PlanModelSchema(ctx *rest.Contexts) {
+	option := metadata.ModelSchemaOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	plan, err := s.Core.SchemaOperation().PlanSchema(ctx.Kit, option)
+	if err != nil {
+		blog.Errorf("plan model schema failed, err: %v, rid: %s", err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(plan)
+}
+
+// ApplyModelSchema apply the changes of the schema to the live model in a transaction, the changes are authorized
+// before applied, and the created resources are registered to iam after the transaction is committed.
+func (s *Service) ApplyModelSchema(ctx *rest.Contexts) {
+	option := metadata.ModelSchemaOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	plan, err := s.Core.SchemaOperation().PlanSchema(ctx.Kit, option)
+	if err != nil {
+		blog.Errorf("apply model schema, but plan failed, err: %v, rid: %s", err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if err := s.Core.SchemaOperation().AuthorizeSchemaPlan(ctx.Kit, plan); err != nil {
+		blog.Errorf("apply model schema, but authorize plan failed, err: %v, rid: %s", err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+
+	var created []metadata.IamInstanceWithCreator
+	txnErr := s.Engine.CoreAPI.CoreService().Txn().AutoRunTxn(ctx.Kit.Ctx, s.EnableTxn, ctx.Kit.Header, func() error {
+		var err error
+		created, err = s.Core.SchemaOperation().ApplySchema(ctx.Kit, plan)
+		if err != nil {
+			blog.Errorf("apply model schema failed, err: %v, rid: %s", err, ctx.Kit.Rid)
+			return err
+		}
+		return nil
+	})
+
+	if txnErr != nil {
+		ctx.RespAutoError(txnErr)
+		return
+	}
+
+	if err := s.Core.SchemaOperation().RegisterSchemaCreators(ctx.Kit, created); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(plan)
+}
@@ -194,6 +194,10 @@ func (s *Service) DeleteObject(ctx *rest.Contexts) {
 		return
 	}
 
+	if !s.approvalGate().Check(ctx, metadata.ApprovalOperationDeleteModel, 0, nil, s.approvalModelPreview(ctx.Kit, id)) {
+		return
+	}
+
 	//delete model
 	txnErr := s.Engine.CoreAPI.CoreService().Txn().AutoRunTxn(ctx.Kit.Ctx, s.EnableTxn, ctx.Kit.Header, func() error {
 		err = s.Core.ObjectOperation().DeleteObject(ctx.Kit, id, true)
@@ -114,10 +114,7 @@ func (s *Service) DeleteObjectGroup(ctx *rest.Contexts) {
 
 // UpdateObjectAttributeGroupProperty update the object attribute belongs to group information
 func (s *Service) UpdateObjectAttributeGroupProperty(ctx *rest.Contexts) {
-	requestBody := struct {
-		Data       []metadata.PropertyGroupObjectAtt `json:"data" field:"json"`
-		ModelBizID int64                             `json:"bk_biz_id"`
-	}{}
+	requestBody := metadata.UpdatePropertyGroupObjectAttOption{}
 	if err := ctx.DecodeInto(&requestBody); err != nil {
 		ctx.RespAutoError(err)
 		return
@@ -0,0 +1,157 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"strings"
+
+	"configcenter/src/ac/meta"
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	paraparse "configcenter/src/common/paraparse"
+	"configcenter/src/common/querybuilder"
+	"configcenter/src/scene_server/topo_server/core/inst"
+)
+
+// SearchNoPermissionRestrictedFields search the restricted fields of the object that user has no permission to view
+func (s *Service) SearchNoPermissionRestrictedFields(ctx *rest.Contexts) {
+	objID := ctx.Request.PathParameter("bk_obj_id")
+
+	noPermission, err := s.AuthManager.GetNoPermissionRestrictedFields(ctx.Kit.Ctx, ctx.Kit.Header,
+		meta.ViewRestrictedField, objID)
+	if err != nil {
+		blog.Errorf("get no permission restricted fields failed, objID: %s, err: %v, rid: %s", objID, err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrCommAuthorizeFailed))
+		return
+	}
+
+	fields := noPermission[objID]
+	if fields == nil {
+		fields = make([]string, 0)
+	}
+	ctx.RespEntity(fields)
+}
+
+// filterRestrictedFields remove the restricted fields that user has no permission to view from the instances
+func (s *Service) filterRestrictedFields(ctx *rest.Contexts, objID string, instances ...mapstr.MapStr) errors.CCErrorCoder {
+	if err := s.AuthManager.FilterRestrictedFields(ctx.Kit.Ctx, ctx.Kit.Header, objID, instances...); err != nil {
+		blog.Errorf("filter restricted fields failed, objID: %s, err: %v, rid: %s", objID, err, ctx.Kit.Rid)
+		return ctx.Kit.CCError.CCError(common.CCErrCommAuthorizeFailed)
+	}
+	return nil
+}
+
+// filterInstRestrictedFields remove the restricted fields that user has no permission to view from the insts
+func (s *Service) filterInstRestrictedFields(ctx *rest.Contexts, objID string, insts []inst.Inst) errors.CCErrorCoder {
+	instances := make([]mapstr.MapStr, 0, len(insts))
+	for _, item := range insts {
+		instances = append(instances, item.GetValues())
+	}
+	return s.filterRestrictedFields(ctx, objID, instances...)
+}
+
+// checkRestrictedFieldsEdit check if user has the permission to edit the restricted fields in data
+func (s *Service) checkRestrictedFieldsEdit(ctx *rest.Contexts, objID string, data ...mapstr.MapStr) errors.CCErrorCoder {
+	denied, err := s.AuthManager.CheckRestrictedFieldsEdit(ctx.Kit.Ctx, ctx.Kit.Header, objID, data...)
+	if err != nil {
+		blog.Errorf("check restricted fields edit failed, objID: %s, err: %v, rid: %s", objID, err, ctx.Kit.Rid)
+		return ctx.Kit.CCError.CCError(common.CCErrCommAuthorizeFailed)
+	}
+	if len(denied) > 0 {
+		blog.Errorf("no permission to edit restricted fields %v of object %s, rid: %s", denied, objID, ctx.Kit.Rid)
+		return ctx.Kit.CCError.CCErrorf(common.CCErrCommRestrictedFieldNoPermission, strings.Join(denied, ","))
+	}
+	return nil
+}
+
+// checkRestrictedFieldsSearch check if user has the permission to view the restricted fields that are used in the
+// search condition, filter or sort, so that their values can not be probed by searching
+func (s *Service) checkRestrictedFieldsSearch(ctx *rest.Contexts, objID string, fields ...string) errors.CCErrorCoder {
+	if !s.AuthManager.Enabled() || len(fields) == 0 {
+		return nil
+	}
+
+	noPermission, err := s.AuthManager.GetNoPermissionRestrictedFields(ctx.Kit.Ctx, ctx.Kit.Header,
+		meta.ViewRestrictedField, objID)
+	if err != nil {
+		blog.Errorf("get no permission restricted fields failed, objID: %s, err: %v, rid: %s", objID, err, ctx.Kit.Rid)
+		return ctx.Kit.CCError.CCError(common.CCErrCommAuthorizeFailed)
+	}
+
+	fieldMap := make(map[string]struct{}, len(fields))
+	for _, field := range fields {
+		fieldMap[field] = struct{}{}
+	}
+	denied := make([]string, 0)
+	for _, field := range noPermission[objID] {
+		if _, exist := fieldMap[field]; exist {
+			denied = append(denied, field)
+		}
+	}
+	if len(denied) > 0 {
+		blog.Errorf("no permission to search by restricted fields %v of object %s, rid: %s", denied, objID, ctx.Kit.Rid)
+		return ctx.Kit.CCError.CCErrorf(common.CCErrCommRestrictedFieldNoPermission, strings.Join(denied, ","))
+	}
+	return nil
+}
+
+// searchParamsFields returns the fields used in the condition, filter and sort of the search params
+func searchParamsFields(params paraparse.SearchParams) []string {
+	fields := metadata.QueryConditionFields(params.Condition)
+	if params.Filter != nil {
+		fields = append(fields, querybuilder.RuleFields(params.Filter.Rule)...)
+	}
+	return append(fields, metadata.SortFields(metadata.ParsePage(params.Page).Sort)...)
+}
+
+// filterAuditRestrictedFields remove the restricted fields that user has no permission to view from instance audit details
+func (s *Service) filterAuditRestrictedFields(ctx *rest.Contexts, audits []metadata.AuditLog) errors.CCErrorCoder {
+	objDetails := make(map[string][]*metadata.BasicContent)
+	for _, audit := range audits {
+		detail, ok := audit.OperationDetail.(*metadata.InstanceOpDetail)
+		if !ok || detail.Details == nil || len(detail.ModelID) == 0 {
+			continue
+		}
+		objDetails[detail.ModelID] = append(objDetails[detail.ModelID], detail.Details)
+	}
+
+	if len(objDetails) == 0 {
+		return nil
+	}
+
+	objIDs := make([]string, 0, len(objDetails))
+	for objID := range objDetails {
+		objIDs = append(objIDs, objID)
+	}
+	noPermission, err := s.AuthManager.GetNoPermissionRestrictedFields(ctx.Kit.Ctx, ctx.Kit.Header,
+		meta.ViewRestrictedField, objIDs...)
+	if err != nil {
+		blog.Errorf("get no permission restricted fields failed, objIDs: %v, err: %v, rid: %s", objIDs, err, ctx.Kit.Rid)
+		return ctx.Kit.CCError.CCError(common.CCErrCommAuthorizeFailed)
+	}
+
+	for objID, fields := range noPermission {
+		for _, detail := range objDetails[objID] {
+			for _, field := range fields {
+				delete(detail.PreData, field)
+				delete(detail.CurData, field)
+				delete(detail.UpdateFields, field)
+			}
+		}
+	}
+	return nil
+}
@@ -20,7 +20,10 @@ import (
 	"configcenter/src/common/errors"
 	"configcenter/src/common/language"
 	"configcenter/src/common/mapstr"
+	"configcenter/src/common/openapi"
 	"configcenter/src/common/rdapi"
+	"configcenter/src/common/types"
+	"configcenter/src/common/version"
 	"configcenter/src/scene_server/topo_server/app/options"
 	"configcenter/src/scene_server/topo_server/core"
 	"configcenter/src/thirdparty/elasticsearch"
@@ -55,6 +58,7 @@ func (s *Service) WebService() *restful.Container {
 	healthz := new(restful.WebService).Produces(restful.MIME_JSON)
 	healthz.Route(healthz.GET("/healthz").To(s.Healthz))
 	container := restful.NewContainer().Add(api)
+	healthz.Route(healthz.GET("/openapi").To(openapi.RouteFunction(container, types.CC_MODULE_TOPO, version.CCVersion)))
 	container.Add(healthz)
 
 	return container
@@ -16,6 +16,10 @@ import (
 	"net/http"
 
 	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	paraparse "configcenter/src/common/paraparse"
+	"configcenter/src/scene_server/topo_server/core/operation"
 
 	"github.com/emicklei/go-restful"
 )
@@ -26,13 +30,19 @@ func (s *Service) initBusinessObject(web *restful.WebService) {
 		Language: s.Engine.Language,
 	})
 
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/createmany/object", Handler: s.CreateObjectBatch})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/object", Handler: s.SearchObjectBatch})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/create/object", Handler: s.CreateObject})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/object", Handler: s.SearchObject})
-	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/object/{id}", Handler: s.UpdateObject})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/createmany/object", Handler: s.CreateObjectBatch,
+		Request: map[string]operation.ImportObjectData{}, Response: mapstr.MapStr{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/object", Handler: s.SearchObjectBatch,
+		Request: operation.ExportObjectCondition{}, Response: mapstr.MapStr{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/create/object", Handler: s.CreateObject,
+		Request: metadata.Object{}, Response: metadata.Object{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/object", Handler: s.SearchObject,
+		Request: mapstr.MapStr{}, Response: []metadata.Object{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/object/{id}", Handler: s.UpdateObject,
+		Request: mapstr.MapStr{}})
 	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/delete/object/{id}", Handler: s.DeleteObject})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/objecttopology", Handler: s.SearchObjectTopo})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/objecttopology", Handler: s.SearchObjectTopo,
+		Request: mapstr.MapStr{}, Response: []metadata.ObjectTopo{}})
 
 	utility.AddToRestfulWebService(web)
 }
@@ -43,10 +53,14 @@ func (s *Service) initBusinessClassification(web *restful.WebService) {
 		Language: s.Engine.Language,
 	})
 
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/create/objectclassification", Handler: s.CreateClassification})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/classificationobject", Handler: s.SearchClassificationWithObjects})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/objectclassification", Handler: s.SearchClassification})
-	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/objectclassification/{id}", Handler: s.UpdateClassification})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/create/objectclassification", Handler: s.CreateClassification,
+		Request: metadata.Classification{}, Response: metadata.Classification{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/classificationobject", Handler: s.SearchClassificationWithObjects,
+		Request: mapstr.MapStr{}, Response: []metadata.ClassificationWithObject{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/objectclassification", Handler: s.SearchClassification,
+		Request: mapstr.MapStr{}, Response: []metadata.Classification{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/objectclassification/{id}", Handler: s.UpdateClassification,
+		Request: mapstr.MapStr{}})
 	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/delete/objectclassification/{id}", Handler: s.DeleteClassification})
 
 	utility.AddToRestfulWebService(web)
@@ -58,12 +72,18 @@ func (s *Service) initBusinessObjectAttribute(web *restful.WebService) {
 		Language: s.Engine.Language,
 	})
 
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/create/objectattr", Handler: s.CreateObjectAttribute})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/create/objectattr/biz/{bk_biz_id}", Handler: s.CreateObjectAttribute})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/objectattr", Handler: s.SearchObjectAttribute})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/objectattr/host", Handler: s.ListHostModelAttribute})
-	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/objectattr/{id}", Handler: s.UpdateObjectAttribute})
-	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/objectattr/biz/{bk_biz_id}/id/{id}", Handler: s.UpdateObjectAttribute})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/create/objectattr", Handler: s.CreateObjectAttribute,
+		Request: metadata.Attribute{}, Response: metadata.ObjAttDes{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/create/objectattr/biz/{bk_biz_id}", Handler: s.CreateObjectAttribute,
+		Request: metadata.Attribute{}, Response: metadata.ObjAttDes{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/objectattr", Handler: s.SearchObjectAttribute,
+		Request: mapstr.MapStr{}, Response: []metadata.ObjAttDes{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/objectattr/host", Handler: s.ListHostModelAttribute,
+		Request: mapstr.MapStr{}, Response: []metadata.HostObjAttDes{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/objectattr/{id}", Handler: s.UpdateObjectAttribute,
+		Request: mapstr.MapStr{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/objectattr/biz/{bk_biz_id}/id/{id}", Handler: s.UpdateObjectAttribute,
+		Request: mapstr.MapStr{}})
 	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/delete/objectattr/{id}", Handler: s.DeleteObjectAttribute})
 
 	utility.AddToRestfulWebService(web)
@@ -75,10 +95,14 @@ func (s *Service) initBusinessObjectUnique(web *restful.WebService) {
 		Language: s.Engine.Language,
 	})
 
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/create/objectunique/object/{bk_obj_id}", Handler: s.CreateObjectUnique})
-	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/objectunique/object/{bk_obj_id}/unique/{id}", Handler: s.UpdateObjectUnique})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/delete/objectunique/object/{bk_obj_id}/unique/{id}", Handler: s.DeleteObjectUnique})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/objectunique/object/{bk_obj_id}", Handler: s.SearchObjectUnique})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/create/objectunique/object/{bk_obj_id}", Handler: s.CreateObjectUnique,
+		Request: metadata.CreateUniqueRequest{}, Response: metadata.RspID{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/objectunique/object/{bk_obj_id}/unique/{id}", Handler: s.UpdateObjectUnique,
+		Request: metadata.UpdateUniqueRequest{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/delete/objectunique/object/{bk_obj_id}/unique/{id}", Handler: s.DeleteObjectUnique,
+		Request: rest.EmptyBody{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/objectunique/object/{bk_obj_id}", Handler: s.SearchObjectUnique,
+		Request: rest.EmptyBody{}, Response: []metadata.ObjectUnique{}})
 
 	utility.AddToRestfulWebService(web)
 }
@@ -89,11 +113,15 @@ func (s *Service) initBusinessObjectAttrGroup(web *restful.WebService) {
 		Language: s.Engine.Language,
 	})
 
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/create/objectattgroup", Handler: s.CreateObjectGroup})
-	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/objectattgroup", Handler: s.UpdateObjectGroup})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/create/objectattgroup", Handler: s.CreateObjectGroup,
+		Request: metadata.Group{}, Response: metadata.Group{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/objectattgroup", Handler: s.UpdateObjectGroup,
+		Request: metadata.UpdateGroupCondition{}})
 	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/delete/objectattgroup/{id}", Handler: s.DeleteObjectGroup})
-	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/objectattgroupproperty", Handler: s.UpdateObjectAttributeGroupProperty})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/objectattgroup/object/{bk_obj_id}", Handler: s.SearchGroupByObject})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/objectattgroupproperty", Handler: s.UpdateObjectAttributeGroupProperty,
+		Request: metadata.UpdatePropertyGroupObjectAttOption{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/objectattgroup/object/{bk_obj_id}", Handler: s.SearchGroupByObject,
+		Request: ModelType{}, Response: []metadata.Group{}})
 
 	utility.AddToRestfulWebService(web)
 }
@@ -104,8 +132,10 @@ func (s *Service) initBusinessGraphics(web *restful.WebService) {
 		Language: s.Engine.Language,
 	})
 
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/objecttopo/scope_type/{scope_type}/scope_id/{scope_id}", Handler: s.SelectObjectTopoGraphics})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/update/objecttopo/scope_type/{scope_type}/scope_id/{scope_id}", Handler: s.UpdateObjectTopoGraphicsNew})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/objecttopo/scope_type/{scope_type}/scope_id/{scope_id}", Handler: s.SelectObjectTopoGraphics,
+		Request: rest.EmptyBody{}, Response: []metadata.TopoGraphics{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/update/objecttopo/scope_type/{scope_type}/scope_id/{scope_id}", Handler: s.UpdateObjectTopoGraphicsNew,
+		Request: metadata.UpdateTopoGraphicsInput{}})
 
 	utility.AddToRestfulWebService(web)
 }
@@ -117,41 +147,69 @@ func (s *Service) initBusinessAssociation(web *restful.WebService) {
 	})
 
 	// mainline topo methods
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/create/topomodelmainline", Handler: s.CreateMainLineObject})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/create/topomodelmainline", Handler: s.CreateMainLineObject,
+		Request: metadata.MainLineObject{}, Response: metadata.Object{}})
 	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/delete/topomodelmainline/object/{bk_obj_id}", Handler: s.DeleteMainLineObject})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/topomodelmainline", Handler: s.SearchMainLineObjectTopo})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/topoinst/biz/{bk_biz_id}", Handler: s.SearchBusinessTopo})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/topoinst_with_statistics/biz/{bk_biz_id}", Handler: s.SearchBusinessTopoWithStatistics})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/topoinst/bk_biz_id/{bk_biz_id}/host_apply_rule_related", Handler: s.SearchRuleRelatedTopoNodes})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/topopath/biz/{bk_biz_id}", Handler: s.SearchTopoPath})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/topomodelmainline", Handler: s.SearchMainLineObjectTopo,
+		Request: rest.EmptyBody{}, Response: []metadata.MainlineObjectTopo{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/topoinst/biz/{bk_biz_id}", Handler: s.SearchBusinessTopo,
+		Request: rest.EmptyBody{}, Response: []metadata.TopoInstRst{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/topoinst_with_statistics/biz/{bk_biz_id}", Handler: s.SearchBusinessTopoWithStatistics,
+		Request: rest.EmptyBody{}, Response: []metadata.TopoInstRst{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/topoinst/bk_biz_id/{bk_biz_id}/host_apply_rule_related", Handler: s.SearchRuleRelatedTopoNodes,
+		Request: metadata.SearchRuleRelatedModulesOption{}, Response: []metadata.TopoNode{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/topopath/biz/{bk_biz_id}", Handler: s.SearchTopoPath,
+		Request: metadata.FindTopoPathRequest{}, Response: metadata.TopoPathResult{}})
 
 	// association type methods ,NOT SUPPORT BUSINESS
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/topoassociationtype", Handler: s.SearchObjectAssocWithAssocKindList})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/associationtype", Handler: s.SearchAssociationType})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/create/associationtype", Handler: s.CreateAssociationType})
-	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/associationtype/{id}", Handler: s.UpdateAssociationType})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/topoassociationtype", Handler: s.SearchObjectAssocWithAssocKindList,
+		Request: metadata.AssociationKindIDs{}, Response: metadata.AssociationList{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/associationtype", Handler: s.SearchAssociationType,
+		Request: metadata.SearchAssociationTypeRequest{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/create/associationtype", Handler: s.CreateAssociationType,
+		Request: metadata.AssociationKind{}, Response: metadata.RspID{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/associationtype/{id}", Handler: s.UpdateAssociationType,
+		Request: metadata.UpdateAssociationTypeRequest{}})
 	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/delete/associationtype/{id}", Handler: s.DeleteAssociationType})
 
 	// object association methods
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/objectassociation", Handler: s.SearchObjectAssociation})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/create/objectassociation", Handler: s.CreateObjectAssociation})
-	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/objectassociation/{id}", Handler: s.UpdateObjectAssociation})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/objectassociation", Handler: s.SearchObjectAssociation,
+		Request: mapstr.MapStr{}, Response: []metadata.Association{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/create/objectassociation", Handler: s.CreateObjectAssociation,
+		Request: metadata.Association{}, Response: metadata.Association{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/objectassociation/{id}", Handler: s.UpdateObjectAssociation,
+		Request: mapstr.MapStr{}})
 	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/delete/objectassociation/{id}", Handler: s.DeleteObjectAssociation})
 
 	// inst association methods
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/instassociation", Handler: s.SearchAssociationInst})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/instassociation/related", Handler: s.SearchAssociationRelatedInst})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/create/instassociation", Handler: s.CreateAssociationInst})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/instassociation", Handler: s.SearchAssociationInst,
+		Request: metadata.SearchAssociationInstRequest{}, Response: []metadata.InstAsst{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/instassociation/related", Handler: s.SearchAssociationRelatedInst,
+		Request: metadata.SearchAssociationRelatedInstRequest{}, Response: []metadata.InstAsst{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/instassociation/graph", Handler: s.SearchInstAssociationGraph,
+		Request: metadata.SearchInstAssociationGraphRequest{}, Response: metadata.InstAssociationGraph{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/graph/export/model", Handler: s.ExportModelGraph,
+		Request: metadata.ExportModelGraphRequest{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/graph/export/biz/{bk_biz_id}", Handler: s.ExportBizTopoGraph,
+		Request: metadata.ExportBizTopoGraphRequest{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/graph/export/instance", Handler: s.ExportInstGraph,
+		Request: metadata.ExportInstGraphRequest{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/create/instassociation", Handler: s.CreateAssociationInst,
+		Request: metadata.CreateAssociationInstRequest{}, Response: metadata.RspID{}})
 	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/delete/instassociation/{association_id}", Handler: s.DeleteAssociationInst})
 	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/delete/instassociation/batch", Handler: s.DeleteAssociationInstBatch})
 
 	// topo search methods
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/instassociation/object/{bk_obj_id}", Handler: s.SearchInstByAssociation})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/instassttopo/object/{bk_obj_id}/inst/{inst_id}", Handler: s.SearchInstTopo})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/instassociation/object/{bk_obj_id}", Handler: s.SearchInstByAssociation,
+		Request: operation.AssociationParams{}, Response: metadata.InstResult{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/instassttopo/object/{bk_obj_id}/inst/{inst_id}", Handler: s.SearchInstTopo,
+		Request: rest.EmptyBody{}, Response: []operation.CommonInstTopoV2{}})
 
 	// ATTENTION: the following methods is not recommended
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/insttopo/object/{bk_obj_id}/inst/{inst_id}", Handler: s.SearchInstChildTopo})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/import/instassociation/{bk_obj_id}", Handler: s.ImportInstanceAssociation})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/insttopo/object/{bk_obj_id}/inst/{inst_id}", Handler: s.SearchInstChildTopo,
+		Request: rest.EmptyBody{}, Response: []operation.CommonInstTopo{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/import/instassociation/{bk_obj_id}", Handler: s.ImportInstanceAssociation,
+		Request: metadata.RequestImportAssociation{}, Response: metadata.ResponeImportAssociationData{}})
 
 	utility.AddToRestfulWebService(web)
 }
@@ -162,13 +220,18 @@ func (s *Service) initBusinessInst(web *restful.WebService) {
 		Language: s.Engine.Language,
 	})
 
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/create/instance/object/{bk_obj_id}", Handler: s.CreateInst})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/create/instance/object/{bk_obj_id}", Handler: s.CreateInst,
+		Request: mapstr.MapStr{}, Response: mapstr.MapStr{}})
 	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/delete/instance/object/{bk_obj_id}/inst/{inst_id}", Handler: s.DeleteInst})
 	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/deletemany/instance/object/{bk_obj_id}", Handler: s.DeleteInsts})
-	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/instance/object/{bk_obj_id}/inst/{inst_id}", Handler: s.UpdateInst})
-	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/updatemany/instance/object/{bk_obj_id}", Handler: s.UpdateInsts})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/instance/object/{bk_obj_id}", Handler: s.SearchInstAndAssociationDetail})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/instdetail/object/{bk_obj_id}/inst/{inst_id}", Handler: s.SearchInstByInstID})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/instance/object/{bk_obj_id}/inst/{inst_id}", Handler: s.UpdateInst,
+		Request: mapstr.MapStr{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/updatemany/instance/object/{bk_obj_id}", Handler: s.UpdateInsts,
+		Request: operation.OpCondition{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/instance/object/{bk_obj_id}", Handler: s.SearchInstAndAssociationDetail,
+		Request: paraparse.SearchParams{}, Response: metadata.InstResult{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/instdetail/object/{bk_obj_id}/inst/{inst_id}", Handler: s.SearchInstByInstID,
+		Request: rest.EmptyBody{}, Response: mapstr.MapStr{}})
 
 	utility.AddToRestfulWebService(web)
 }
@@ -16,6 +16,7 @@ import (
 	"net/http"
 
 	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
 
 	"github.com/emicklei/go-restful"
 )
@@ -30,7 +31,10 @@ func (s *Service) initInternalTask(web *restful.WebService) {
 		Language: s.Engine.Language,
 	})
 
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/internal/task", Handler: s.SyncModuleTaskHandler})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/internal/task", Handler: s.SyncModuleTaskHandler,
+		Request: metadata.SyncModuleTask{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/internal/task/clone_biz_topo", Handler: s.CloneBizTopoTaskHandler,
+		Request: metadata.CloneBizTopoTask{}, Response: metadata.CloneBizTopoResult{}})
 
 	utility.AddToRestfulWebService(web)
 }
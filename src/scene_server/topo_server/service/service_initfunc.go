@@ -16,6 +16,11 @@ import (
 	"net/http"
 
 	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	paraparse "configcenter/src/common/paraparse"
+	"configcenter/src/scene_server/topo_server/core/operation"
+	"configcenter/src/source_controller/cacheservice/cache/topo_tree"
 
 	"github.com/emicklei/go-restful"
 )
@@ -27,46 +32,70 @@ func (s *Service) initAssociation(web *restful.WebService) {
 	})
 
 	// mainline topo methods
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/topo/model/mainline", Handler: s.CreateMainLineObject})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/topo/model/mainline", Handler: s.CreateMainLineObject,
+		Request: metadata.MainLineObject{}, Response: metadata.Object{}})
 	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/topo/model/mainline/owners/{owner_id}/objectids/{bk_obj_id}", Handler: s.DeleteMainLineObject})
-	utility.AddHandler(rest.Action{Verb: http.MethodGet, Path: "/topo/model/{owner_id}", Handler: s.SearchMainLineObjectTopo})
-	utility.AddHandler(rest.Action{Verb: http.MethodGet, Path: "/topo/model/{owner_id}/{cls_id}/{bk_obj_id}", Handler: s.SearchObjectByClassificationID})
-	utility.AddHandler(rest.Action{Verb: http.MethodGet, Path: "/topo/inst/{owner_id}/{bk_biz_id}", Handler: s.SearchBusinessTopo})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/topo/tree/brief/biz/{bk_biz_id}", Handler: s.SearchBriefBizTopo})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/topo/cache/topotree", Handler: s.SearchTopologyTree})
+	utility.AddHandler(rest.Action{Verb: http.MethodGet, Path: "/topo/model/{owner_id}", Handler: s.SearchMainLineObjectTopo,
+		Response: []metadata.MainlineObjectTopo{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodGet, Path: "/topo/model/{owner_id}/{cls_id}/{bk_obj_id}", Handler: s.SearchObjectByClassificationID,
+		Response: []metadata.MainlineObjectTopo{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodGet, Path: "/topo/inst/{owner_id}/{bk_biz_id}", Handler: s.SearchBusinessTopo,
+		Response: []metadata.TopoInstRst{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/topo/tree/brief/biz/{bk_biz_id}", Handler: s.SearchBriefBizTopo,
+		Request: metadata.SearchBriefBizTopoOption{}, Response: []metadata.SetTopo{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/topo/cache/topotree", Handler: s.SearchTopologyTree,
+		Request: topo_tree.SearchOption{}, Response: []topo_tree.Topology{}})
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/topo/cache/topo/node_path/biz/{bk_biz_id}",
-		Handler: s.SearchTopologyNodePath})
+		Handler: s.SearchTopologyNodePath,
+		Request: topo_tree.SearchNodePathOption{}, Response: []topo_tree.NodePaths{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/topo/diff/biz/{bk_biz_id}", Handler: s.DiffBusinessTopo,
+		Request: metadata.TopoDiffRequest{}, Response: metadata.TopoDiffResult{}})
 
 	// TODO: delete this api, it's not used by front.
-	utility.AddHandler(rest.Action{Verb: http.MethodGet, Path: "/topo/inst/child/{owner_id}/{obj_id}/{app_id}/{inst_id}", Handler: s.SearchMainLineChildInstTopo})
+	utility.AddHandler(rest.Action{Verb: http.MethodGet, Path: "/topo/inst/child/{owner_id}/{obj_id}/{app_id}/{inst_id}", Handler: s.SearchMainLineChildInstTopo,
+		Response: []metadata.TopoInstRst{}})
 
 	// association type methods
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/topo/association/type/action/search/batch", Handler: s.SearchObjectAssocWithAssocKindList})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/topo/association/type/action/search", Handler: s.SearchAssociationType})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/topo/association/type/action/create", Handler: s.CreateAssociationType})
-	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/topo/association/type/{id}/action/update", Handler: s.UpdateAssociationType})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/topo/association/type/action/search/batch", Handler: s.SearchObjectAssocWithAssocKindList,
+		Request: metadata.AssociationKindIDs{}, Response: metadata.AssociationList{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/topo/association/type/action/search", Handler: s.SearchAssociationType,
+		Request: metadata.SearchAssociationTypeRequest{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/topo/association/type/action/create", Handler: s.CreateAssociationType,
+		Request: metadata.AssociationKind{}, Response: metadata.RspID{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/topo/association/type/{id}/action/update", Handler: s.UpdateAssociationType,
+		Request: metadata.UpdateAssociationTypeRequest{}})
 	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/topo/association/type/{id}/action/delete", Handler: s.DeleteAssociationType})
 
 	// object association methods
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/object/association/action/search", Handler: s.SearchObjectAssociation})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/object/association/action/create", Handler: s.CreateObjectAssociation})
-	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/object/association/{id}/action/update", Handler: s.UpdateObjectAssociation})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/object/association/action/search", Handler: s.SearchObjectAssociation,
+		Request: mapstr.MapStr{}, Response: []metadata.Association{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/object/association/action/create", Handler: s.CreateObjectAssociation,
+		Request: metadata.Association{}, Response: metadata.Association{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/object/association/{id}/action/update", Handler: s.UpdateObjectAssociation,
+		Request: mapstr.MapStr{}})
 	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/object/association/{id}/action/delete", Handler: s.DeleteObjectAssociation})
 
 	// inst association methods
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/inst/association/action/search", Handler: s.SearchAssociationInst})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/inst/association/related/action/search", Handler: s.SearchAssociationRelatedInst})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/inst/association/action/create", Handler: s.CreateAssociationInst})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/inst/association/action/search", Handler: s.SearchAssociationInst,
+		Request: metadata.SearchAssociationInstRequest{}, Response: []metadata.InstAsst{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/inst/association/related/action/search", Handler: s.SearchAssociationRelatedInst,
+		Request: metadata.SearchAssociationRelatedInstRequest{}, Response: []metadata.InstAsst{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/inst/association/action/create", Handler: s.CreateAssociationInst,
+		Request: metadata.CreateAssociationInstRequest{}, Response: metadata.RspID{}})
 	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/inst/association/{association_id}/action/delete", Handler: s.DeleteAssociationInst})
 	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/inst/association/batch/action/delete", Handler: s.DeleteAssociationInstBatch})
 
 	// topo search methods
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/inst/association/search/owner/{owner_id}/object/{bk_obj_id}", Handler: s.SearchInstByAssociation})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/inst/association/topo/search/owner/{owner_id}/object/{bk_obj_id}/inst/{inst_id}", Handler: s.SearchInstTopo})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/inst/association/search/owner/{owner_id}/object/{bk_obj_id}", Handler: s.SearchInstByAssociation,
+		Request: operation.AssociationParams{}, Response: metadata.InstResult{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/inst/association/topo/search/owner/{owner_id}/object/{bk_obj_id}/inst/{inst_id}", Handler: s.SearchInstTopo,
+		Request: rest.EmptyBody{}, Response: []operation.CommonInstTopoV2{}})
 
 	// ATTENTION: the following methods is not recommended
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/inst/search/topo/owner/{owner_id}/object/{bk_obj_id}/inst/{inst_id}", Handler: s.SearchInstChildTopo})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/inst/association/action/{bk_obj_id}/import", Handler: s.ImportInstanceAssociation})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/inst/search/topo/owner/{owner_id}/object/{bk_obj_id}/inst/{inst_id}", Handler: s.SearchInstChildTopo,
+		Request: rest.EmptyBody{}, Response: []operation.CommonInstTopo{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/inst/association/action/{bk_obj_id}/import", Handler: s.ImportInstanceAssociation,
+		Request: metadata.RequestImportAssociation{}, Response: metadata.ResponeImportAssociationData{}})
 
 	utility.AddToRestfulWebService(web)
 }
@@ -78,8 +107,10 @@ func (s *Service) initAuditLog(web *restful.WebService) {
 	})
 
 	utility.AddHandler(rest.Action{Verb: http.MethodGet, Path: "/find/audit_dict", Handler: s.SearchAuditDict})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/audit_list", Handler: s.SearchAuditList})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/audit", Handler: s.SearchAuditDetail})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/audit_list", Handler: s.SearchAuditList,
+		Request: metadata.AuditQueryInput{}, Response: metadata.AuditQueryData{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/audit", Handler: s.SearchAuditDetail,
+		Request: metadata.AuditDetailQueryInput{}, Response: []metadata.AuditLog{}})
 
 	utility.AddToRestfulWebService(web)
 }
@@ -90,20 +121,35 @@ func (s *Service) initBusiness(web *restful.WebService) {
 		Language: s.Engine.Language,
 	})
 
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/app/search/{owner_id}", Handler: s.SearchBusiness})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/app/{owner_id}", Handler: s.CreateBusiness})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/app/search/{owner_id}", Handler: s.SearchBusiness,
+		Request: metadata.QueryBusinessRequest{}, Response: mapstr.MapStr{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/app/{owner_id}", Handler: s.CreateBusiness,
+		Request: mapstr.MapStr{}, Response: mapstr.MapStr{}})
 	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/app/{owner_id}/{app_id}", Handler: s.DeleteBusiness})
-	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/app/{owner_id}/{app_id}", Handler: s.UpdateBusiness})
-	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/app/status/{flag}/{owner_id}/{app_id}", Handler: s.UpdateBusinessStatus})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/app/{owner_id}/{app_id}", Handler: s.UpdateBusiness,
+		Request: mapstr.MapStr{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/app/status/{flag}/{owner_id}/{app_id}", Handler: s.UpdateBusinessStatus,
+		Request: metadata.UpdateBusinessStatusOption{}})
 	// utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/app/search/{owner_id}", Handler: s.SearchBusiness})
-	utility.AddHandler(rest.Action{Verb: http.MethodGet, Path: "/app/{app_id}/basic_info", Handler: s.GetBusinessBasicInfo})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/app/default/{owner_id}/search", Handler: s.SearchOwnerResourcePoolBusiness})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/app/default/{owner_id}", Handler: s.CreateDefaultBusiness})
-	utility.AddHandler(rest.Action{Verb: http.MethodGet, Path: "/topo/internal/{owner_id}/{app_id}", Handler: s.GetInternalModule})
-	utility.AddHandler(rest.Action{Verb: http.MethodGet, Path: "/topo/internal/{owner_id}/{app_id}/with_statistics", Handler: s.GetInternalModuleWithStatistics})
+	utility.AddHandler(rest.Action{Verb: http.MethodGet, Path: "/app/{app_id}/basic_info", Handler: s.GetBusinessBasicInfo,
+		Response: mapstr.MapStr{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/app/default/{owner_id}/search", Handler: s.SearchOwnerResourcePoolBusiness,
+		Request: rest.EmptyBody{}, Response: mapstr.MapStr{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/app/default/{owner_id}", Handler: s.CreateDefaultBusiness,
+		Request: mapstr.MapStr{}, Response: mapstr.MapStr{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodGet, Path: "/topo/internal/{owner_id}/{app_id}", Handler: s.GetInternalModule,
+		Response: metadata.InnterAppTopo{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodGet, Path: "/topo/internal/{owner_id}/{app_id}/with_statistics", Handler: s.GetInternalModuleWithStatistics,
+		Response: mapstr.MapStr{}})
 	// find reduced business list with only few fields for business itself.
-	utility.AddHandler(rest.Action{Verb: http.MethodGet, Path: "/app/with_reduced", Handler: s.SearchReducedBusinessList})
-	utility.AddHandler(rest.Action{Verb: http.MethodGet, Path: "/app/simplify", Handler: s.ListAllBusinessSimplify})
+	utility.AddHandler(rest.Action{Verb: http.MethodGet, Path: "/app/with_reduced", Handler: s.SearchReducedBusinessList,
+		Response: mapstr.MapStr{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodGet, Path: "/app/simplify", Handler: s.ListAllBusinessSimplify,
+		Response: mapstr.MapStr{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/createmany/topo/clone/biz/{bk_biz_id}", Handler: s.CloneBusinessTopo,
+		Request: metadata.CloneBizTopoRequest{}, Response: metadata.CloneBizTopoTaskResult{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/topo/clone/biz/{bk_biz_id}/task", Handler: s.GetCloneBusinessTopoTask,
+		Request: rest.EmptyBody{}, Response: metadata.CloneBizTopoTaskResult{}})
 
 	utility.AddToRestfulWebService(web)
 }
@@ -114,15 +160,23 @@ func (s *Service) initModule(web *restful.WebService) {
 		Language: s.Engine.Language,
 	})
 
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/module/{app_id}/{set_id}", Handler: s.CreateModule})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/module/{app_id}/{set_id}", Handler: s.CreateModule,
+		Request: mapstr.MapStr{}, Response: mapstr.MapStr{}})
 	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/module/{app_id}/{set_id}/{module_id}", Handler: s.DeleteModule})
-	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/module/{app_id}/{set_id}/{module_id}", Handler: s.UpdateModule})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/module/search/{owner_id}/{bk_biz_id}/{bk_set_id}", Handler: s.SearchModule})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/module/biz/{bk_biz_id}", Handler: s.SearchModuleByCondition})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/module/bk_biz_id/{bk_biz_id}", Handler: s.SearchModuleBatch})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/module/with_relation/biz/{bk_biz_id}", Handler: s.SearchModuleWithRelation})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/module/bk_biz_id/{bk_biz_id}/service_template_id/{service_template_id}", Handler: s.ListModulesByServiceTemplateID})
-	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/module/host_apply_enable_status/bk_biz_id/{bk_biz_id}/bk_module_id/{bk_module_id}", Handler: s.UpdateModuleHostApplyEnableStatus})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/module/{app_id}/{set_id}/{module_id}", Handler: s.UpdateModule,
+		Request: mapstr.MapStr{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/module/search/{owner_id}/{bk_biz_id}/{bk_set_id}", Handler: s.SearchModule,
+		Request: paraparse.SearchParams{}, Response: mapstr.MapStr{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/module/biz/{bk_biz_id}", Handler: s.SearchModuleByCondition,
+		Request: paraparse.SearchParams{}, Response: mapstr.MapStr{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/module/bk_biz_id/{bk_biz_id}", Handler: s.SearchModuleBatch,
+		Request: metadata.SearchInstBatchOption{}, Response: []mapstr.MapStr{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/module/with_relation/biz/{bk_biz_id}", Handler: s.SearchModuleWithRelation,
+		Request: metadata.SearchModuleWithRelationOption{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/module/bk_biz_id/{bk_biz_id}/service_template_id/{service_template_id}", Handler: s.ListModulesByServiceTemplateID,
+		Request: metadata.ListModulesByServiceTemplateOption{}, Response: metadata.InstDataInfo{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/module/host_apply_enable_status/bk_biz_id/{bk_biz_id}/bk_module_id/{bk_module_id}", Handler: s.UpdateModuleHostApplyEnableStatus,
+		Request: metadata.UpdateModuleHostApplyEnableStatusOption{}, Response: metadata.UpdatedCount{}})
 
 	utility.AddToRestfulWebService(web)
 }
@@ -133,12 +187,17 @@ func (s *Service) initSet(web *restful.WebService) {
 		Language: s.Engine.Language,
 	})
 
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/set/{app_id}", Handler: s.CreateSet})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/set/{app_id}/batch", Handler: s.BatchCreateSet})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/set/{app_id}", Handler: s.CreateSet,
+		Request: mapstr.MapStr{}, Response: mapstr.MapStr{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/set/{app_id}/batch", Handler: s.BatchCreateSet,
+		Request: metadata.BatchCreateSetRequest{}, Response: []metadata.OneSetCreateResult{}})
 	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/set/{app_id}/{set_id}", Handler: s.DeleteSet})
-	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/set/{app_id}/{set_id}", Handler: s.UpdateSet})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/set/search/{owner_id}/{app_id}", Handler: s.SearchSet})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/set/bk_biz_id/{bk_biz_id}", Handler: s.SearchSetBatch})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/set/{app_id}/{set_id}", Handler: s.UpdateSet,
+		Request: mapstr.MapStr{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/set/search/{owner_id}/{app_id}", Handler: s.SearchSet,
+		Request: paraparse.SearchParams{}, Response: mapstr.MapStr{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/set/bk_biz_id/{bk_biz_id}", Handler: s.SearchSetBatch,
+		Request: metadata.SearchInstBatchOption{}, Response: []mapstr.MapStr{}})
 
 	utility.AddToRestfulWebService(web)
 }
@@ -149,19 +208,28 @@ func (s *Service) initInst(web *restful.WebService) {
 		Language: s.Engine.Language,
 	})
 
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/inst/{owner_id}/{bk_obj_id}", Handler: s.CreateInst})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/inst/{owner_id}/{bk_obj_id}", Handler: s.CreateInst,
+		Request: mapstr.MapStr{}, Response: mapstr.MapStr{}})
 	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/inst/{owner_id}/{bk_obj_id}/{inst_id}", Handler: s.DeleteInst})
 	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/inst/{owner_id}/{bk_obj_id}/batch", Handler: s.DeleteInsts})
-	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/inst/{owner_id}/{bk_obj_id}/{inst_id}", Handler: s.UpdateInst})
-	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/inst/{owner_id}/{bk_obj_id}/batch/update", Handler: s.UpdateInsts})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/inst/search/{owner_id}/{bk_obj_id}", Handler: s.SearchInsts})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/inst/search/owner/{owner_id}/object/{bk_obj_id}/detail", Handler: s.SearchInstAndAssociationDetail})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/inst/search/owner/{owner_id}/object/{bk_obj_id}", Handler: s.SearchInstByObject})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/inst/search/{owner_id}/{bk_obj_id}/{inst_id}", Handler: s.SearchInstByInstID})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/inst/{owner_id}/{bk_obj_id}/{inst_id}", Handler: s.UpdateInst,
+		Request: mapstr.MapStr{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/inst/{owner_id}/{bk_obj_id}/batch/update", Handler: s.UpdateInsts,
+		Request: operation.OpCondition{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/inst/search/{owner_id}/{bk_obj_id}", Handler: s.SearchInsts,
+		Request: paraparse.SearchParams{}, Response: mapstr.MapStr{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/inst/search/owner/{owner_id}/object/{bk_obj_id}/detail", Handler: s.SearchInstAndAssociationDetail,
+		Request: paraparse.SearchParams{}, Response: metadata.InstResult{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/inst/search/owner/{owner_id}/object/{bk_obj_id}", Handler: s.SearchInstByObject,
+		Request: paraparse.SearchParams{}, Response: mapstr.MapStr{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/inst/search/{owner_id}/{bk_obj_id}/{inst_id}", Handler: s.SearchInstByInstID,
+		Request: rest.EmptyBody{}, Response: mapstr.MapStr{}})
 	// 2019-09-30 废弃接口
 	// utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/inst/association/object/{bk_obj_id}/inst_id/{id}/offset/{start}/limit/{limit}", Handler: s.SearchInstAssociation})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/inst/association/object/{bk_obj_id}/inst_id/{id}/offset/{start}/limit/{limit}/web", Handler: s.SearchInstAssociationUI})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/inst/association/association_object/inst_base_info", Handler: s.SearchInstAssociationWithOtherObject})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/inst/association/object/{bk_obj_id}/inst_id/{id}/offset/{start}/limit/{limit}/web", Handler: s.SearchInstAssociationUI,
+		Request: rest.EmptyBody{}, Response: mapstr.MapStr{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/inst/association/association_object/inst_base_info", Handler: s.SearchInstAssociationWithOtherObject,
+		Request: metadata.RequestInstAssociationObjectID{}, Response: mapstr.MapStr{}})
 
 	utility.AddToRestfulWebService(web)
 }
@@ -172,11 +240,17 @@ func (s *Service) initObjectAttribute(web *restful.WebService) {
 		Language: s.Engine.Language,
 	})
 
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/objectattr", Handler: s.CreateObjectAttribute})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/objectattr/search", Handler: s.SearchObjectAttribute})
-	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/objectattr/{id}", Handler: s.UpdateObjectAttribute})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/objectattr", Handler: s.CreateObjectAttribute,
+		Request: metadata.Attribute{}, Response: metadata.ObjAttDes{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/objectattr/search", Handler: s.SearchObjectAttribute,
+		Request: mapstr.MapStr{}, Response: []metadata.ObjAttDes{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/objectattr/{id}", Handler: s.UpdateObjectAttribute,
+		Request: mapstr.MapStr{}})
 	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/objectattr/{id}", Handler: s.DeleteObjectAttribute})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/update/objectattr/index/{bk_obj_id}/{id}", Handler: s.UpdateObjectAttributeIndex})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/update/objectattr/index/{bk_obj_id}/{id}", Handler: s.UpdateObjectAttributeIndex,
+		Request: mapstr.MapStr{}, Response: metadata.UpdateAttrIndexData{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodGet, Path: "/find/objectattr/restricted/no_permission/object/{bk_obj_id}", Handler: s.SearchNoPermissionRestrictedFields,
+		Response: []string{}})
 
 	utility.AddToRestfulWebService(web)
 }
@@ -187,10 +261,14 @@ func (s *Service) initObjectClassification(web *restful.WebService) {
 		Language: s.Engine.Language,
 	})
 
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/object/classification", Handler: s.CreateClassification})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/object/classification/{owner_id}/objects", Handler: s.SearchClassificationWithObjects})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/object/classifications", Handler: s.SearchClassification})
-	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/object/classification/{id}", Handler: s.UpdateClassification})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/object/classification", Handler: s.CreateClassification,
+		Request: metadata.Classification{}, Response: metadata.Classification{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/object/classification/{owner_id}/objects", Handler: s.SearchClassificationWithObjects,
+		Request: mapstr.MapStr{}, Response: []metadata.ClassificationWithObject{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/object/classifications", Handler: s.SearchClassification,
+		Request: mapstr.MapStr{}, Response: []metadata.Classification{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/object/classification/{id}", Handler: s.UpdateClassification,
+		Request: mapstr.MapStr{}})
 	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/object/classification/{id}", Handler: s.DeleteClassification})
 
 	utility.AddToRestfulWebService(web)
@@ -202,10 +280,13 @@ func (s *Service) initObjectObjectUnique(web *restful.WebService) {
 		Language: s.Engine.Language,
 	})
 
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/object/{bk_obj_id}/unique/action/create", Handler: s.CreateObjectUnique})
-	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/object/{bk_obj_id}/unique/{id}/action/update", Handler: s.UpdateObjectUnique})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/object/{bk_obj_id}/unique/action/create", Handler: s.CreateObjectUnique,
+		Request: metadata.CreateUniqueRequest{}, Response: metadata.RspID{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/object/{bk_obj_id}/unique/{id}/action/update", Handler: s.UpdateObjectUnique,
+		Request: metadata.UpdateUniqueRequest{}})
 	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/object/{bk_obj_id}/unique/{id}/action/delete", Handler: s.DeleteObjectUnique})
-	utility.AddHandler(rest.Action{Verb: http.MethodGet, Path: "/object/{bk_obj_id}/unique/action/search", Handler: s.SearchObjectUnique})
+	utility.AddHandler(rest.Action{Verb: http.MethodGet, Path: "/object/{bk_obj_id}/unique/action/search", Handler: s.SearchObjectUnique,
+		Response: []metadata.ObjectUnique{}})
 
 	utility.AddToRestfulWebService(web)
 }
@@ -216,12 +297,16 @@ func (s *Service) initObjectGroup(web *restful.WebService) {
 		Language: s.Engine.Language,
 	})
 
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/objectatt/group/new", Handler: s.CreateObjectGroup})
-	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/objectatt/group/update", Handler: s.UpdateObjectGroup})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/objectatt/group/new", Handler: s.CreateObjectGroup,
+		Request: metadata.Group{}, Response: metadata.Group{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/objectatt/group/update", Handler: s.UpdateObjectGroup,
+		Request: metadata.UpdateGroupCondition{}})
 	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/objectatt/group/groupid/{id}", Handler: s.DeleteObjectGroup})
-	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/objectatt/group/property", Handler: s.UpdateObjectAttributeGroupProperty})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/objectatt/group/property", Handler: s.UpdateObjectAttributeGroupProperty,
+		Request: metadata.UpdatePropertyGroupObjectAttOption{}})
 	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/objectatt/group/owner/{owner_id}/object/{bk_object_id}/propertyids/{property_id}/groupids/{group_id}", Handler: s.DeleteObjectAttributeGroup})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/objectatt/group/property/owner/{owner_id}/object/{bk_obj_id}", Handler: s.SearchGroupByObject})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/objectatt/group/property/owner/{owner_id}/object/{bk_obj_id}", Handler: s.SearchGroupByObject,
+		Request: ModelType{}, Response: []metadata.Group{}})
 
 	utility.AddToRestfulWebService(web)
 }
@@ -232,12 +317,18 @@ func (s *Service) initObject(web *restful.WebService) {
 		Language: s.Engine.Language,
 	})
 
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/object/batch", Handler: s.CreateObjectBatch})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/object/search/batch", Handler: s.SearchObjectBatch})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/object", Handler: s.CreateObject})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/objects", Handler: s.SearchObject})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/objects/topo", Handler: s.SearchObjectTopo})
-	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/object/{id}", Handler: s.UpdateObject})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/object/batch", Handler: s.CreateObjectBatch,
+		Request: map[string]operation.ImportObjectData{}, Response: mapstr.MapStr{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/object/search/batch", Handler: s.SearchObjectBatch,
+		Request: operation.ExportObjectCondition{}, Response: mapstr.MapStr{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/object", Handler: s.CreateObject,
+		Request: metadata.Object{}, Response: metadata.Object{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/objects", Handler: s.SearchObject,
+		Request: mapstr.MapStr{}, Response: []metadata.Object{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/objects/topo", Handler: s.SearchObjectTopo,
+		Request: mapstr.MapStr{}, Response: []metadata.ObjectTopo{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/object/{id}", Handler: s.UpdateObject,
+		Request: mapstr.MapStr{}})
 	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/object/{id}", Handler: s.DeleteObject})
 	utility.AddHandler(rest.Action{Verb: http.MethodGet, Path: "/object/statistics", Handler: s.GetModelStatistics})
 
@@ -250,8 +341,10 @@ func (s *Service) initGraphics(web *restful.WebService) {
 		Language: s.Engine.Language,
 	})
 
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/objects/topographics/scope_type/{scope_type}/scope_id/{scope_id}/action/search", Handler: s.SelectObjectTopoGraphics})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/objects/topographics/scope_type/{scope_type}/scope_id/{scope_id}/action/update", Handler: s.UpdateObjectTopoGraphics})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/objects/topographics/scope_type/{scope_type}/scope_id/{scope_id}/action/search", Handler: s.SelectObjectTopoGraphics,
+		Request: rest.EmptyBody{}, Response: []metadata.TopoGraphics{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/objects/topographics/scope_type/{scope_type}/scope_id/{scope_id}/action/update", Handler: s.UpdateObjectTopoGraphics,
+		Request: metadata.UpdateTopoGraphicsOption{}})
 
 	utility.AddToRestfulWebService(web)
 }
@@ -261,7 +354,8 @@ func (s *Service) initIdentifier(web *restful.WebService) {
 		Language: s.Engine.Language,
 	})
 
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/identifier/{obj_type}/search", Handler: s.SearchIdentifier})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/identifier/{obj_type}/search", Handler: s.SearchIdentifier,
+		Request: metadata.SearchIdentifierParam{}, Response: metadata.SearchHostIdentifierData{}})
 
 	utility.AddToRestfulWebService(web)
 }
@@ -273,7 +367,8 @@ func (s *Service) initFullTextSearch(web *restful.WebService) {
 		Language: s.Engine.Language,
 	})
 
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/full_text", Handler: s.FullTextFind})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/full_text", Handler: s.FullTextFind,
+		Request: Query{}, Response: SearchResults{}})
 
 	utility.AddToRestfulWebService(web)
 }
@@ -285,10 +380,93 @@ func (s *Service) initResourceDirectory(web *restful.WebService) {
 		Language: s.Engine.Language,
 	})
 
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/create/resource/directory", Handler: s.CreateResourceDirectory})
-	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/resource/directory/{bk_module_id}", Handler: s.UpdateResourceDirectory})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/resource/directory", Handler: s.SearchResourceDirectory})
-	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/delete/resource/directory/{bk_module_id}", Handler: s.DeleteResourceDirectory})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/create/resource/directory", Handler: s.CreateResourceDirectory,
+		Request: mapstr.MapStr{}, Response: metadata.CreateOneDataResult{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/resource/directory/{bk_module_id}", Handler: s.UpdateResourceDirectory,
+		Request: mapstr.MapStr{}, Response: metadata.UpdatedCount{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/resource/directory", Handler: s.SearchResourceDirectory,
+		Request: metadata.SearchResourceDirParams{}, Response: mapstr.MapStr{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/delete/resource/directory/{bk_module_id}", Handler: s.DeleteResourceDirectory,
+		Response: metadata.DeletedCount{}})
+
+	utility.AddToRestfulWebService(web)
+}
+
+// 模型声明式定义
+func (s *Service) initModelSchema(web *restful.WebService) {
+	utility := rest.NewRestUtility(rest.Config{
+		ErrorIf:  s.Engine.CCErr,
+		Language: s.Engine.Language,
+	})
+
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/model/schema/export", Handler: s.ExportModelSchema,
+		Request: metadata.ExportModelSchemaOption{}, Response: metadata.ModelSchema{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/find/model/schema/plan", Handler: s.PlanModelSchema,
+		Request: metadata.ModelSchemaOption{}, Response: metadata.ModelSchemaPlan{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/update/model/schema/apply", Handler: s.ApplyModelSchema,
+		Request: metadata.ModelSchemaOption{}, Response: metadata.ModelSchemaPlan{}})
+
+	utility.AddToRestfulWebService(web)
+}
+
+// 准入webhook
+func (s *Service) initAdmissionWebhook(web *restful.WebService) {
+	utility := rest.NewRestUtility(rest.Config{
+		ErrorIf:  s.Engine.CCErr,
+		Language: s.Engine.Language,
+	})
+
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/create/admission_webhook", Handler: s.CreateAdmissionWebhook,
+		Request: metadata.AdmissionWebhook{}, Response: metadata.AdmissionWebhook{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/admission_webhook/{id}", Handler: s.UpdateAdmissionWebhook,
+		Request: metadata.AdmissionWebhook{}, Response: metadata.AdmissionWebhook{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/delete/admission_webhook/{id}", Handler: s.DeleteAdmissionWebhook})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/admission_webhook", Handler: s.SearchAdmissionWebhooks,
+		Request: metadata.SearchAdmissionWebhookOption{}, Response: metadata.SearchAdmissionWebhookResult{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/admission_webhook/log", Handler: s.SearchAdmissionWebhookLogs,
+		Request: metadata.SearchAdmissionWebhookLogOption{}, Response: metadata.SearchAdmissionWebhookLogResult{}})
+
+	utility.AddToRestfulWebService(web)
+}
+
+// 敏感操作审批
+func (s *Service) initApproval(web *restful.WebService) {
+	utility := rest.NewRestUtility(rest.Config{
+		ErrorIf:  s.Engine.CCErr,
+		Language: s.Engine.Language,
+	})
+
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/create/approval_policy", Handler: s.CreateApprovalPolicy,
+		Request: metadata.ApprovalPolicy{}, Response: metadata.ApprovalPolicy{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/approval_policy/{id}", Handler: s.UpdateApprovalPolicy,
+		Request: metadata.ApprovalPolicy{}, Response: metadata.ApprovalPolicy{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/delete/approval_policy/{id}", Handler: s.DeleteApprovalPolicy})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/approval_policy", Handler: s.SearchApprovalPolicies,
+		Request: metadata.SearchApprovalPolicyOption{}, Response: metadata.SearchApprovalPolicyResult{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/approval_request", Handler: s.SearchApprovalRequests,
+		Request: metadata.SearchUserApprovalRequestOption{}, Response: metadata.SearchApprovalRequestResult{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/approval_request/{id}/approve", Handler: s.ApproveApprovalRequest,
+		Request: metadata.ApprovalDecisionOption{}, Response: metadata.ApprovalRequest{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/approval_request/{id}/reject", Handler: s.RejectApprovalRequest,
+		Request: metadata.ApprovalDecisionOption{}, Response: metadata.ApprovalRequest{}})
+
+	utility.AddToRestfulWebService(web)
+}
+
+// 变更冻结窗口
+func (s *Service) initChangeFreeze(web *restful.WebService) {
+	utility := rest.NewRestUtility(rest.Config{
+		ErrorIf:  s.Engine.CCErr,
+		Language: s.Engine.Language,
+	})
+
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/create/change_freeze_window", Handler: s.CreateChangeFreezeWindow,
+		Request: metadata.ChangeFreezeWindow{}, Response: metadata.ChangeFreezeWindow{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/change_freeze_window/{id}", Handler: s.UpdateChangeFreezeWindow,
+		Request: metadata.ChangeFreezeWindow{}, Response: metadata.ChangeFreezeWindow{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/delete/change_freeze_window/{id}", Handler: s.DeleteChangeFreezeWindow})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/change_freeze_window", Handler: s.SearchChangeFreezeWindows,
+		Request: metadata.SearchChangeFreezeWindowOption{}, Response: metadata.SearchChangeFreezeWindowResult{}})
 
 	utility.AddToRestfulWebService(web)
 }
@@ -322,4 +500,8 @@ func (s *Service) initService(web *restful.WebService) {
 	s.initInternalTask(web)
 
 	s.initResourceDirectory(web)
+	s.initModelSchema(web)
+	s.initAdmissionWebhook(web)
+	s.initApproval(web)
+	s.initChangeFreeze(web)
 }
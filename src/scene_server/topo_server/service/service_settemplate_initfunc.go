@@ -16,6 +16,7 @@ import (
 	"net/http"
 
 	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
 
 	"github.com/emicklei/go-restful"
 )
@@ -26,22 +27,41 @@ func (s *Service) initSetTemplate(web *restful.WebService) {
 		Language: s.Engine.Language,
 	})
 
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/create/topo/set_template/bk_biz_id/{bk_biz_id}/", Handler: s.CreateSetTemplate})
-	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/topo/set_template/{set_template_id}/bk_biz_id/{bk_biz_id}/", Handler: s.UpdateSetTemplate})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/create/topo/set_template/bk_biz_id/{bk_biz_id}/", Handler: s.CreateSetTemplate,
+		Request: metadata.CreateSetTemplateOption{}, Response: metadata.SetTemplate{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/topo/set_template/{set_template_id}/bk_biz_id/{bk_biz_id}/", Handler: s.UpdateSetTemplate,
+		Request: metadata.UpdateSetTemplateOption{}, Response: metadata.SetTemplate{}})
 	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/deletemany/topo/set_template/bk_biz_id/{bk_biz_id}/", Handler: s.DeleteSetTemplate})
-	utility.AddHandler(rest.Action{Verb: http.MethodGet, Path: "/find/topo/set_template/{set_template_id}/bk_biz_id/{bk_biz_id}/", Handler: s.GetSetTemplate})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/topo/set_template/bk_biz_id/{bk_biz_id}/", Handler: s.ListSetTemplate})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/topo/set_template/bk_biz_id/{bk_biz_id}/web/", Handler: s.ListSetTemplateWeb})
-	utility.AddHandler(rest.Action{Verb: http.MethodGet, Path: "/findmany/topo/set_template/{set_template_id}/bk_biz_id/{bk_biz_id}/service_templates", Handler: s.ListSetTplRelatedSvcTpl})
-	utility.AddHandler(rest.Action{Verb: http.MethodGet, Path: "/findmany/topo/set_template/{set_template_id}/bk_biz_id/{bk_biz_id}/service_templates/with_statistics", Handler: s.ListSetTplRelatedSvcTplWithStatistics})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/topo/set_template/{set_template_id}/bk_biz_id/{bk_biz_id}/sets/web", Handler: s.ListSetTplRelatedSetsWeb})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/topo/set_template/{set_template_id}/bk_biz_id/{bk_biz_id}/diff_with_instances", Handler: s.DiffSetTplWithInst})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/updatemany/topo/set_template/{set_template_id}/bk_biz_id/{bk_biz_id}/sync_to_instances", Handler: s.SyncSetTplToInst})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/topo/set_template/{set_template_id}/bk_biz_id/{bk_biz_id}/instances_sync_status", Handler: s.GetSetSyncDetails})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/topo/set_template_sync_status/bk_biz_id/{bk_biz_id}", Handler: s.ListSetTemplateSyncStatus})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/topo/set_template_sync_history/bk_biz_id/{bk_biz_id}", Handler: s.ListSetTemplateSyncHistory})
-	utility.AddHandler(rest.Action{Verb: http.MethodGet, Path: "/findmany/topo/set_template/{set_template_id}/bk_biz_id/{bk_biz_id}/set_template_status", Handler: s.CheckSetInstUpdateToDateStatus})
-	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/topo/set_template/bk_biz_id/{bk_biz_id}/set_template_status", Handler: s.BatchCheckSetInstUpdateToDateStatus})
+	utility.AddHandler(rest.Action{Verb: http.MethodGet, Path: "/find/topo/set_template/{set_template_id}/bk_biz_id/{bk_biz_id}/", Handler: s.GetSetTemplate,
+		Response: metadata.SetTemplate{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/topo/set_template/bk_biz_id/{bk_biz_id}/", Handler: s.ListSetTemplate,
+		Request: metadata.ListSetTemplateOption{}, Response: metadata.MultipleSetTemplateResult{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/topo/set_template/bk_biz_id/{bk_biz_id}/web/", Handler: s.ListSetTemplateWeb,
+		Request: metadata.ListSetTemplateOption{}, Response: metadata.MultipleSetTemplateWithStatisticsResult{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodGet, Path: "/findmany/topo/set_template/{set_template_id}/bk_biz_id/{bk_biz_id}/service_templates", Handler: s.ListSetTplRelatedSvcTpl,
+		Response: []metadata.ServiceTemplate{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodGet, Path: "/findmany/topo/set_template/{set_template_id}/bk_biz_id/{bk_biz_id}/service_templates/with_statistics", Handler: s.ListSetTplRelatedSvcTplWithStatistics,
+		Response: []metadata.ServiceTemplateWithModuleInfo{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/topo/set_template/{set_template_id}/bk_biz_id/{bk_biz_id}/sets/web", Handler: s.ListSetTplRelatedSetsWeb,
+		Request: metadata.ListSetByTemplateOption{}, Response: metadata.InstDataInfo{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/topo/set_template/{set_template_id}/bk_biz_id/{bk_biz_id}/diff_with_instances", Handler: s.DiffSetTplWithInst,
+		Request: metadata.DiffSetTplWithInstOption{}, Response: metadata.SetTplDiffResult{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/updatemany/topo/set_template/{set_template_id}/bk_biz_id/{bk_biz_id}/sync_to_instances", Handler: s.SyncSetTplToInst,
+		Request: metadata.SyncSetTplToInstOption{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/topo/set_template/{set_template_id}/bk_biz_id/{bk_biz_id}/instances_sync_status", Handler: s.GetSetSyncDetails,
+		Request: metadata.SetSyncStatusOption{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/topo/set_template_sync_status/bk_biz_id/{bk_biz_id}", Handler: s.ListSetTemplateSyncStatus,
+		Request: metadata.ListSetTemplateSyncStatusOption{}, Response: metadata.MultipleSetTemplateSyncStatus{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/topo/set_template_sync_history/bk_biz_id/{bk_biz_id}", Handler: s.ListSetTemplateSyncHistory,
+		Request: metadata.ListSetTemplateSyncStatusOption{}, Response: metadata.MultipleSetTemplateSyncStatus{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodGet, Path: "/findmany/topo/set_template/{set_template_id}/bk_biz_id/{bk_biz_id}/set_template_status", Handler: s.CheckSetInstUpdateToDateStatus,
+		Response: metadata.SetTemplateUpdateToDateStatus{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/topo/set_template/bk_biz_id/{bk_biz_id}/set_template_status", Handler: s.BatchCheckSetInstUpdateToDateStatus,
+		Request: metadata.BatchCheckSetInstUpdateToDateStatusOption{}, Response: []metadata.SetTemplateUpdateToDateStatus{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/topo/set_template/{set_template_id}/bk_biz_id/{bk_biz_id}/sync_policy", Handler: s.UpdateSetTemplateSyncPolicy,
+		Request: metadata.UpdateSetTemplateSyncPolicyOption{}, Response: metadata.SetTemplateSyncPolicy{}})
+	utility.AddHandler(rest.Action{Verb: http.MethodGet, Path: "/find/topo/set_template/{set_template_id}/bk_biz_id/{bk_biz_id}/sync_policy", Handler: s.GetSetTemplateSyncPolicy,
+		Response: metadata.SetTemplateSyncPolicy{}})
 
 	utility.AddToRestfulWebService(web)
 }
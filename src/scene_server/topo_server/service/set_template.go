@@ -143,6 +143,8 @@ func (s *Service) UpdateSetTemplate(ctx *rest.Contexts) {
 		ctx.RespAutoError(txnErr)
 		return
 	}
+
+	s.triggerSetTemplateAutoSync(ctx.Kit, bizID, setTemplateID)
 	ctx.RespEntity(setTemplate)
 }
 
@@ -403,14 +405,9 @@ func (s *Service) ListSetTplRelatedSvcTplWithStatistics(ctx *rest.Contexts) {
 		moduleHostIDs[item.ModuleID] = append(moduleHostIDs[item.ModuleID], item.HostID)
 	}
 
-	type ServiceTemplateWithModuleInfo struct {
-		ServiceTemplate metadata.ServiceTemplate `json:"service_template"`
-		HostCount       int                      `json:"host_count"`
-		Modules         []metadata.ModuleInst    `json:"modules"`
-	}
-	result := make([]ServiceTemplateWithModuleInfo, 0)
+	result := make([]metadata.ServiceTemplateWithModuleInfo, 0)
 	for _, svcTpl := range serviceTemplates {
-		info := ServiceTemplateWithModuleInfo{
+		info := metadata.ServiceTemplateWithModuleInfo{
 			ServiceTemplate: svcTpl,
 		}
 		modules, ok := svcTpl2Modules[svcTpl.ID]
@@ -0,0 +1,322 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"configcenter/src/ac/parser"
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/changefreeze"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/lock"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/scene_server/topo_server/core/settemplate"
+	"configcenter/src/storage/driver/redis"
+)
+
+const (
+	// setTemplateSyncCheckInterval 检查维护窗口同步策略是否到期的间隔
+	setTemplateSyncCheckInterval = time.Minute
+	// setTemplateSyncMaxConcurrency 同时执行的维护窗口同步的最大数量
+	setTemplateSyncMaxConcurrency = 5
+	// setTemplateAutoSyncLockExpire 集群模板自动同步锁的过期时间, 需要大于同步的执行时间
+	setTemplateAutoSyncLockExpire = 30 * time.Minute
+	// setTemplateAutoSyncPendingFormat 同步执行期间集群模板又发生变更的标记, 同步结束后合并为一次同步执行
+	setTemplateAutoSyncPendingFormat = common.BKCacheKeyV3Prefix + "topo:settemplate:auto:sync:pending:%d"
+)
+
+func (s *Service) UpdateSetTemplateSyncPolicy(ctx *rest.Contexts) {
+	bizIDStr := ctx.Request.PathParameter(common.BKAppIDField)
+	bizID, err := strconv.ParseInt(bizIDStr, 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKAppIDField))
+		return
+	}
+
+	setTemplateIDStr := ctx.Request.PathParameter(common.BKSetTemplateIDField)
+	setTemplateID, err := strconv.ParseInt(setTemplateIDStr, 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKSetTemplateIDField))
+		return
+	}
+
+	option := metadata.UpdateSetTemplateSyncPolicyOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if len(option.NotifyURL) != 0 && !settemplate.IsNotifyURLAllowed(option.NotifyURL, settemplate.NotifyHosts()) {
+		blog.Errorf("UpdateSetTemplateSyncPolicy failed, notify url %s is not allowed, rid: %s", option.NotifyURL,
+			ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "notify_url"))
+		return
+	}
+
+	policy, err := s.Engine.CoreAPI.CoreService().SetTemplate().UpdateSetTemplateSyncPolicy(ctx.Kit.Ctx, ctx.Kit.Header, bizID, setTemplateID, option)
+	if err != nil {
+		blog.Errorf("UpdateSetTemplateSyncPolicy failed, bizID: %d, setTemplateID: %d, option: %+v, err: %s, rid: %s", bizID, setTemplateID, option, err.Error(), ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(policy)
+}
+
+func (s *Service) GetSetTemplateSyncPolicy(ctx *rest.Contexts) {
+	bizIDStr := ctx.Request.PathParameter(common.BKAppIDField)
+	bizID, err := strconv.ParseInt(bizIDStr, 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKAppIDField))
+		return
+	}
+
+	setTemplateIDStr := ctx.Request.PathParameter(common.BKSetTemplateIDField)
+	setTemplateID, err := strconv.ParseInt(setTemplateIDStr, 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKSetTemplateIDField))
+		return
+	}
+
+	policy, err := s.getSetTemplateSyncPolicy(ctx.Kit, bizID, setTemplateID)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(policy)
+}
+
+// getSetTemplateSyncPolicy returns the sync policy of the set template, set template without
+// policy is synchronized manually.
+func (s *Service) getSetTemplateSyncPolicy(kit *rest.Kit, bizID, setTemplateID int64) (metadata.SetTemplateSyncPolicy, error) {
+	option := metadata.ListSetTemplateSyncPolicyOption{
+		BizID:          bizID,
+		SetTemplateIDs: []int64{setTemplateID},
+	}
+	result, err := s.Engine.CoreAPI.CoreService().SetTemplate().ListSetTemplateSyncPolicy(kit.Ctx, kit.Header, option)
+	if err != nil {
+		blog.Errorf("getSetTemplateSyncPolicy failed, bizID: %d, setTemplateID: %d, err: %s, rid: %s", bizID, setTemplateID, err.Error(), kit.Rid)
+		return metadata.SetTemplateSyncPolicy{}, err
+	}
+	if len(result.Info) == 0 {
+		return metadata.SetTemplateSyncPolicy{
+			BizID:         bizID,
+			SetTemplateID: setTemplateID,
+			Mode:          metadata.SetTemplateSyncModeManual,
+			BatchSize:     metadata.SetTemplateSyncDefaultBatchSize,
+		}, nil
+	}
+	return result.Info[0], nil
+}
+
+// triggerSetTemplateAutoSync 集群模板变更后, 如果集群模板配置了自动同步, 则在后台开始同步
+func (s *Service) triggerSetTemplateAutoSync(kit *rest.Kit, bizID, setTemplateID int64) {
+	policy, err := s.getSetTemplateSyncPolicy(kit, bizID, setTemplateID)
+	if err != nil {
+		return
+	}
+	if policy.Mode != metadata.SetTemplateSyncModeAuto {
+		return
+	}
+
+	go s.runSetTemplateAutoSync(newBackgroundKit(kit), policy, metadata.SetTemplateSyncTriggerTemplateChanged)
+}
+
+// runSetTemplateAutoSync 执行集群模板的自动同步, 同步期间模板的变更在同步结束后合并为一次同步执行
+func (s *Service) runSetTemplateAutoSync(kit *rest.Kit, policy metadata.SetTemplateSyncPolicy, trigger metadata.SetTemplateSyncTrigger) {
+	for s.lockAndSyncSetTemplate(kit, policy, trigger) {
+		// the policy may be changed during the sync, only the auto sync policy synchronizes the template changes
+		var err error
+		policy, err = s.getSetTemplateSyncPolicy(kit, policy.BizID, policy.SetTemplateID)
+		if err != nil || policy.Mode != metadata.SetTemplateSyncModeAuto {
+			return
+		}
+		trigger = metadata.SetTemplateSyncTriggerTemplateChanged
+	}
+}
+
+// lockAndSyncSetTemplate 获取集群模板的同步锁后执行同步, 返回同步期间集群模板是否又发生了变更. 如果集群模板正在同步,
+// 模板变更触发的同步会被标记, 由正在执行的同步结束后再执行一次, 维护窗口触发的同步由调度器稍后重试.
+func (s *Service) lockAndSyncSetTemplate(kit *rest.Kit, policy metadata.SetTemplateSyncPolicy,
+	trigger metadata.SetTemplateSyncTrigger) bool {
+
+	pendingKey := fmt.Sprintf(setTemplateAutoSyncPendingFormat, policy.SetTemplateID)
+	locker := lock.NewLocker(redis.Client())
+	locked, err := locker.Lock(lock.GetLockKey(lock.SetTemplateAutoSyncFormat, policy.SetTemplateID),
+		setTemplateAutoSyncLockExpire)
+	if err != nil {
+		blog.Errorf("get auto sync set template lock failed, setTemplateID: %d, err: %v, rid: %s",
+			policy.SetTemplateID, err, kit.Rid)
+		return false
+	}
+	if !locked {
+		blog.Infof("set template %d is being synchronized, trigger: %s, rid: %s", policy.SetTemplateID, trigger,
+			kit.Rid)
+		if trigger == metadata.SetTemplateSyncTriggerTemplateChanged {
+			err := redis.Client().Set(kit.Ctx, pendingKey, trigger, setTemplateAutoSyncLockExpire).Err()
+			if err != nil {
+				blog.Errorf("mark set template %d pending auto sync failed, err: %v, rid: %s", policy.SetTemplateID,
+					err, kit.Rid)
+			}
+		}
+		return false
+	}
+
+	s.syncSetTemplate(kit, policy, trigger)
+	if err := locker.Unlock(); err != nil {
+		blog.Errorf("release auto sync set template lock failed, setTemplateID: %d, err: %v, rid: %s",
+			policy.SetTemplateID, err, kit.Rid)
+	}
+
+	pending, err := redis.Client().Del(kit.Ctx, pendingKey).Result()
+	if err != nil {
+		blog.Errorf("get set template %d pending auto sync failed, err: %v, rid: %s", policy.SetTemplateID, err,
+			kit.Rid)
+		return false
+	}
+	return pending > 0
+}
+
+func (s *Service) syncSetTemplate(kit *rest.Kit, policy metadata.SetTemplateSyncPolicy, trigger metadata.SetTemplateSyncTrigger) {
+	blog.Infof("start auto sync set template, bizID: %d, setTemplateID: %d, trigger: %s, rid: %s", policy.BizID, policy.SetTemplateID, trigger, kit.Rid)
+	// the auto syncs change the topology of the business, they are skipped in the change freeze windows like the
+	// manual syncs, the maintenance window syncs are tried again by the scheduler later.
+	frozen := parser.FrozenResource{BizID: policy.BizID, Resource: metadata.ChangeFreezeTopology}
+	if err := changefreeze.Check(kit, s.Engine.CoreAPI.CoreService(), frozen); err != nil {
+		blog.Errorf("skip auto sync set template, bizID: %d, setTemplateID: %d, trigger: %s, err: %v, rid: %s", policy.BizID, policy.SetTemplateID, trigger, err, kit.Rid)
+		return
+	}
+	summary, err := s.Core.SetTemplateOperation().AutoSyncSetTplToInst(kit, policy, trigger)
+	if err != nil {
+		blog.Errorf("auto sync set template failed, bizID: %d, setTemplateID: %d, trigger: %s, err: %s, rid: %s", policy.BizID, policy.SetTemplateID, trigger, err.Error(), kit.Rid)
+		return
+	}
+	blog.InfoJSON("auto sync set template finished, summary: %s, rid: %s", summary, kit.Rid)
+}
+
+// SetTemplateSyncScheduler 定时检查维护窗口模式的集群模板同步策略, 到达维护窗口时开始同步, 只在master上执行
+func (s *Service) SetTemplateSyncScheduler(ctx context.Context) {
+	ticker := time.NewTicker(setTemplateSyncCheckInterval)
+	defer ticker.Stop()
+
+	worker := newSetTemplateSyncWorker(setTemplateSyncMaxConcurrency)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !s.Engine.ServiceManageInterface.IsMaster() {
+				continue
+			}
+			s.runDueSetTemplateSyncPolicies(worker)
+		}
+	}
+}
+
+func (s *Service) runDueSetTemplateSyncPolicies(worker *setTemplateSyncWorker) {
+	header := util.BuildHeader(common.CCSystemOperatorUserName, common.BKSuperOwnerID)
+	kit := newBackgroundKit(&rest.Kit{Header: header})
+
+	option := metadata.ListSetTemplateSyncPolicyOption{
+		Modes: []metadata.SetTemplateSyncMode{metadata.SetTemplateSyncModeWindow},
+	}
+	result, err := s.Engine.CoreAPI.CoreService().SetTemplate().ListSetTemplateSyncPolicy(kit.Ctx, kit.Header, option)
+	if err != nil {
+		blog.Errorf("list maintenance window set template sync policies failed, err: %s, rid: %s", err.Error(), kit.Rid)
+		return
+	}
+
+	now := time.Now()
+	for _, policy := range result.Info {
+		if !policy.IsDue(now) {
+			continue
+		}
+
+		policy := policy
+		started := worker.tryRun(policy.SetTemplateID, func() {
+			// the policy may wait for a long time in the previous syncs, check the window when it really starts
+			if !policy.InWindow(time.Now()) {
+				blog.Warnf("maintenance window of set template %d is closed, skip sync", policy.SetTemplateID)
+				return
+			}
+
+			// sync with the supplier account of the policy, so that the audit logs belong to the right tenant
+			policyHeader := util.BuildHeader(common.CCSystemOperatorUserName, policy.SupplierAccount)
+			s.runSetTemplateAutoSync(newBackgroundKit(&rest.Kit{Header: policyHeader}), policy,
+				metadata.SetTemplateSyncTriggerWindow)
+		})
+		if !started {
+			blog.V(4).Infof("set template %d sync is running or the sync worker is busy, try later, rid: %s",
+				policy.SetTemplateID, kit.Rid)
+		}
+	}
+}
+
+// setTemplateSyncWorker runs the maintenance window syncs in background with limited concurrency, so that the long
+// syncs do not block the scheduler, and a set template is not synchronized again while its sync is running.
+type setTemplateSyncWorker struct {
+	lock    sync.Mutex
+	limit   int
+	running map[int64]bool
+}
+
+func newSetTemplateSyncWorker(limit int) *setTemplateSyncWorker {
+	return &setTemplateSyncWorker{
+		limit:   limit,
+		running: make(map[int64]bool),
+	}
+}
+
+// tryRun runs the sync of the set template in background, returns false if the set template is being synchronized
+// or the worker is busy, the policy is checked again by the scheduler later.
+func (w *setTemplateSyncWorker) tryRun(setTemplateID int64, run func()) bool {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.running[setTemplateID] || len(w.running) >= w.limit {
+		return false
+	}
+	w.running[setTemplateID] = true
+
+	go func() {
+		defer func() {
+			w.lock.Lock()
+			delete(w.running, setTemplateID)
+			w.lock.Unlock()
+		}()
+		run()
+	}()
+	return true
+}
+
+// newBackgroundKit 生成用于后台任务的kit, 不继承原请求的context与事务信息
+func newBackgroundKit(kit *rest.Kit) *rest.Kit {
+	header := util.CCHeader(kit.Header)
+	if len(util.GetHTTPCCRequestID(header)) == 0 {
+		header.Set(common.BKHTTPCCRequestID, util.GenerateRID())
+	}
+	return &rest.Kit{
+		Rid:             util.GetHTTPCCRequestID(header),
+		Header:          header,
+		Ctx:             util.NewContextFromHTTPHeader(header),
+		CCError:         util.GetDefaultCCError(header),
+		User:            util.GetUser(header),
+		SupplierAccount: util.GetOwnerID(header),
+	}
+}
@@ -0,0 +1,57 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSetTemplateSyncWorker(t *testing.T) {
+	worker := newSetTemplateSyncWorker(2)
+	release := make(chan struct{})
+	done := make(chan struct{}, 2)
+	block := func() {
+		<-release
+		done <- struct{}{}
+	}
+
+	if !worker.tryRun(1, block) {
+		t.Fatalf("the first sync should be started")
+	}
+	if worker.tryRun(1, block) {
+		t.Fatalf("the set template being synchronized should not be started again")
+	}
+	if !worker.tryRun(2, block) {
+		t.Fatalf("the second sync should be started")
+	}
+	if worker.tryRun(3, block) {
+		t.Fatalf("the sync should not be started when the worker is busy")
+	}
+
+	close(release)
+	<-done
+	<-done
+	for i := 0; i < 100; i++ {
+		worker.lock.Lock()
+		running := len(worker.running)
+		worker.lock.Unlock()
+		if running == 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if !worker.tryRun(1, func() {}) {
+		t.Fatalf("the set template should be synchronized again after the sync finished")
+	}
+}
@@ -14,6 +14,7 @@ package options
 
 import (
 	"configcenter/src/common/core/cc/config"
+	"configcenter/src/source_controller/cacheservice/cache/instance"
 	"configcenter/src/storage/dal/mongo"
 	"configcenter/src/storage/dal/redis"
 
@@ -29,6 +30,8 @@ type ServerOption struct {
 type Config struct {
 	Mongo mongo.Config
 	Redis redis.Config
+	// Instance is the config of model instance cache
+	Instance instance.Config
 }
 
 //NewServerOption create a ServerOption object
@@ -23,6 +23,7 @@ import (
 	"configcenter/src/common/blog"
 	"configcenter/src/common/types"
 	"configcenter/src/source_controller/cacheservice/app/options"
+	"configcenter/src/source_controller/cacheservice/cache/instance"
 	cachesvr "configcenter/src/source_controller/cacheservice/service"
 	"configcenter/src/storage/driver/mongodb"
 	"configcenter/src/storage/driver/redis"
@@ -84,6 +85,7 @@ func Run(ctx context.Context, cancel context.CancelFunc, op *options.ServerOptio
 	}
 
 	cacheSvr.Core = engine
+	cacheSvr.Config.Instance = parseInstanceCacheConfig()
 
 	if err := initResource(cacheSvr); err != nil {
 		return nil
@@ -129,3 +131,22 @@ func initResource(cacheSvr *CacheServer) error {
 
 	return nil
 }
+
+// parseInstanceCacheConfig parse the model instance cache config, the instances are cached only when the objects
+// are configured.
+func parseInstanceCacheConfig() instance.Config {
+	conf := instance.Config{}
+	objects, _ := cc.String("cacheService.instance.objects")
+	conf.Objects = instance.ParseObjects(objects)
+
+	if maxCount, err := cc.Int("cacheService.instance.maxCount"); err == nil {
+		conf.MaxCount = int64(maxCount)
+	}
+	if maxDetailSize, err := cc.Int("cacheService.instance.maxDetailSize"); err == nil {
+		conf.MaxDetailSize = maxDetailSize
+	}
+	if interval, err := cc.Int("cacheService.instance.checkIntervalMinutes"); err == nil {
+		conf.CheckInterval = time.Duration(interval) * time.Minute
+	}
+	return conf
+}
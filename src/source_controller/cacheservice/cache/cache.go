@@ -17,11 +17,13 @@ import (
 
 	"configcenter/src/source_controller/cacheservice/cache/business"
 	"configcenter/src/source_controller/cacheservice/cache/host"
+	"configcenter/src/source_controller/cacheservice/cache/instance"
+	"configcenter/src/source_controller/cacheservice/cache/process"
 	"configcenter/src/source_controller/cacheservice/cache/topo_tree"
 	"configcenter/src/storage/reflector"
 )
 
-func NewCache(event reflector.Interface) (*ClientSet, error) {
+func NewCache(event reflector.Interface, instConf instance.Config, isMaster func() bool) (*ClientSet, error) {
 	if err := business.NewCache(event); err != nil {
 		return nil, fmt.Errorf("new business cache failed, err: %v", err)
 	}
@@ -30,6 +32,14 @@ func NewCache(event reflector.Interface) (*ClientSet, error) {
 		return nil, fmt.Errorf("new host cache failed, err: %v", err)
 	}
 
+	if err := instance.NewCache(event, instConf, isMaster); err != nil {
+		return nil, fmt.Errorf("new instance cache failed, err: %v", err)
+	}
+
+	if err := process.NewCache(event); err != nil {
+		return nil, fmt.Errorf("new service instance cache failed, err: %v", err)
+	}
+
 	bizClient := business.NewClient()
 	hostClient := host.NewClient()
 
@@ -37,6 +47,8 @@ func NewCache(event reflector.Interface) (*ClientSet, error) {
 		Topology: topo_tree.NewTopologyTree(bizClient),
 		Host:     hostClient,
 		Business: bizClient,
+		Instance: instance.NewClient(),
+		Process:  process.NewClient(),
 	}
 	return cache, nil
 }
@@ -45,4 +57,6 @@ type ClientSet struct {
 	Topology *topo_tree.TopologyTree
 	Host     *host.Client
 	Business *business.Client
+	Instance *instance.Client
+	Process  *process.Client
 }
@@ -0,0 +1,115 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package instance
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/storage/reflector"
+)
+
+const (
+	defaultMaxCount      = 100000
+	defaultMaxDetailSize = 64 * 1024
+)
+
+// ObjectNotCachedError is returned when the instances of the object is not configured to be cached.
+var ObjectNotCachedError = errors.New("object instance is not cached")
+
+// Config is the config of the model instance cache, it's opt-in for each model.
+type Config struct {
+	// Objects are the models whose instances are cached, the inner models are cached by other caches.
+	Objects []string
+	// MaxCount is the max number of cached instances of each model, the instances over it are read from mongodb.
+	MaxCount int64
+	// MaxDetailSize is the max bytes of a cached instance detail, the larger ones are read from mongodb.
+	MaxDetailSize int
+	// CheckInterval is the interval to check the consistency between the cache and mongodb and repair it,
+	// 0 means do not check periodically.
+	CheckInterval time.Duration
+}
+
+// ParseObjects parse the comma separated object ids, the inner models are ignored.
+func ParseObjects(objects string) []string {
+	all := make([]string, 0)
+	for _, objID := range strings.Split(objects, ",") {
+		objID = strings.TrimSpace(objID)
+		if len(objID) == 0 {
+			continue
+		}
+		if common.IsInnerModel(objID) {
+			blog.Warnf("instance cache of inner model %s is not supported, skip", objID)
+			continue
+		}
+		all = append(all, objID)
+	}
+	return all
+}
+
+var client *Client
+var clientOnce sync.Once
+var cache *instanceCache
+var store *instanceStore
+
+// NewClient can only be called after NewCache.
+func NewClient() *Client {
+
+	if client != nil {
+		return client
+	}
+
+	clientOnce.Do(func() {
+		client = &Client{
+			store: store,
+		}
+	})
+
+	return client
+}
+
+// Attention, it can only be called for once.
+// isMaster is used to do the periodic consistency check on the master only.
+func NewCache(event reflector.Interface, conf Config, isMaster func() bool) error {
+
+	if cache != nil {
+		return nil
+	}
+
+	if conf.MaxCount <= 0 {
+		conf.MaxCount = defaultMaxCount
+	}
+	if conf.MaxDetailSize <= 0 {
+		conf.MaxDetailSize = defaultMaxDetailSize
+	}
+
+	store = newInstanceStore(conf)
+
+	// cache has not been initialized.
+	cache = &instanceCache{
+		store:    store,
+		event:    event,
+		isMaster: isMaster,
+	}
+
+	if err := cache.Run(); err != nil {
+		return fmt.Errorf("run instance cache failed, err: %v", err)
+	}
+
+	return nil
+}
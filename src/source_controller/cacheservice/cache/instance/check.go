@@ -0,0 +1,156 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package instance
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/storage/driver/redis"
+)
+
+const checkPageSize = 500
+
+func (c *instanceCache) loopCheckConsistency() {
+	for {
+		time.Sleep(c.store.config.CheckInterval)
+
+		if !c.isMaster() {
+			blog.V(4).Infof("loop check instance cache consistency, but not master, skip.")
+			continue
+		}
+
+		for _, objID := range c.store.config.Objects {
+			result, err := c.store.checkConsistency(context.Background(), objID, true)
+			if err != nil {
+				blog.Errorf("check object %s instance cache consistency failed, err: %v", objID, err)
+				continue
+			}
+			blog.Infof("check object %s instance cache consistency, result: %+v", objID, *result)
+		}
+	}
+}
+
+// checkConsistency compare the cached instances with mongodb, and repair the cache if needed.
+func (s *instanceStore) checkConsistency(ctx context.Context, objID string, repair bool) (
+	*metadata.InstCacheConsistencyResult, error) {
+
+	rid := ctx.Value(common.ContextRequestIDField)
+	result := &metadata.InstCacheConsistencyResult{ObjID: objID, Repaired: repair}
+
+	cachedList, err := redis.Client().SMembers(context.Background(), instKey.IDListKey(objID)).Result()
+	if err != nil {
+		blog.Errorf("check object %s instance cache, but get cached id list failed, err: %v, rid: %v", objID, err, rid)
+		return nil, err
+	}
+	cachedIDs := make(map[int64]bool)
+	for _, id := range cachedList {
+		instID, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			blog.Errorf("check object %s instance cache, got invalid cached id %s, rid: %v", objID, id, rid)
+			continue
+		}
+		cachedIDs[instID] = true
+	}
+
+	// existIDs are the cached instance ids which exist in mongodb.
+	existIDs := make(map[int64]bool)
+	lastID := int64(0)
+	for {
+		filter := mapstr.MapStr{
+			common.BKObjIDField: objID,
+			common.BKInstIDField: mapstr.MapStr{
+				common.BKDBGT: lastID,
+			},
+		}
+		instances, err := listInstancesFromMongo(filter, 0, checkPageSize)
+		if err != nil {
+			return nil, err
+		}
+		if len(instances) == 0 {
+			break
+		}
+		lastID = instances[len(instances)-1].id
+
+		keys := make([]string, len(instances))
+		for idx, inst := range instances {
+			keys[idx] = instKey.DetailKey(objID, inst.id)
+		}
+		details, err := redis.Client().MGet(context.Background(), keys...).Result()
+		if err != nil {
+			blog.Errorf("check object %s instance cache, but get details from redis failed, err: %v, rid: %v",
+				objID, err, rid)
+			return nil, err
+		}
+
+		toRepair := make([]int64, 0)
+		for idx, inst := range instances {
+			result.Total++
+			if cachedIDs[inst.id] {
+				existIDs[inst.id] = true
+			}
+
+			detail, ok := details[idx].(string)
+			switch {
+			case ok && detail == inst.detail:
+				result.Consistent++
+			case ok:
+				result.Inconsistent++
+				toRepair = append(toRepair, inst.id)
+			case len(inst.detail) > s.config.MaxDetailSize,
+				!cachedIDs[inst.id] && int64(len(cachedIDs)) >= s.config.MaxCount:
+				result.Uncached++
+			default:
+				result.Missing++
+				toRepair = append(toRepair, inst.id)
+			}
+		}
+
+		if repair && len(toRepair) != 0 {
+			// get the details again, the cache may be refreshed by event during the check.
+			latest, err := listInstancesWithIDFromMongo(objID, toRepair)
+			if err != nil {
+				return nil, err
+			}
+			for _, inst := range latest {
+				s.upsertInstance(objID, inst.id, inst.detail)
+			}
+			blog.Infof("check object %s instance cache, repair instances: %v, rid: %v", objID, toRepair, rid)
+		}
+
+		if len(instances) < checkPageSize {
+			break
+		}
+	}
+
+	for id := range cachedIDs {
+		if existIDs[id] {
+			continue
+		}
+		result.Redundant++
+		if repair {
+			if err := s.deleteInstance(objID, id); err != nil {
+				return nil, fmt.Errorf("delete redundant instance %d failed, err: %v", id, err)
+			}
+			blog.Infof("check object %s instance cache, delete redundant instance %d, rid: %v", objID, id, rid)
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,213 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package instance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/json"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/storage/driver/redis"
+)
+
+// InstanceNotFoundError is returned when the instance can not be found with the unique key.
+var InstanceNotFoundError = errors.New("instance not found")
+
+type Client struct {
+	store *instanceStore
+}
+
+// ListInstances list the object's instances from redis with instance id list.
+// if an instance is not exist in cache and still can not find in mongodb,
+// then it will not be return. so the returned array may not equal to
+// the request instance ids length and the sequence is also may not same.
+func (c *Client) ListInstances(ctx context.Context, objID string, opt *metadata.ListWithIDOption) ([]string, error) {
+	rid := ctx.Value(common.ContextRequestIDField)
+	if !c.store.isCached(objID) {
+		return nil, ObjectNotCachedError
+	}
+
+	if len(opt.IDs) > 500 {
+		return nil, errors.New("instance id length is over limit")
+	}
+
+	if len(opt.IDs) == 0 {
+		return nil, errors.New("instance id array is empty")
+	}
+
+	keys := make([]string, len(opt.IDs))
+	for idx, id := range opt.IDs {
+		keys[idx] = instKey.DetailKey(objID, id)
+	}
+
+	details, err := redis.Client().MGet(context.Background(), keys...).Result()
+	if err != nil {
+		blog.Errorf("list object %s instances with ids, but get from redis failed, err: %v, rid: %v", objID, err, rid)
+		return nil, err
+	}
+
+	toRefresh := make([]int64, 0)
+	list := make([]string, 0)
+	for idx, inst := range details {
+		if inst == nil {
+			toRefresh = append(toRefresh, opt.IDs[idx])
+			continue
+		}
+		detail, ok := inst.(string)
+		if !ok {
+			blog.Errorf("list object %s instances with ids, but got invalid detail %v, rid: %v", objID, inst, rid)
+			return nil, fmt.Errorf("got invalid instance cache %v", inst)
+		}
+		list = append(list, cutDetailWithFields(detail, opt.Fields))
+	}
+
+	if len(toRefresh) != 0 {
+		// can not found in the cache, get from mongodb and try to refresh the cache.
+		refresh, err := listInstancesWithIDFromMongo(objID, toRefresh)
+		if err != nil {
+			blog.Errorf("list object %s instances with ids, but get from db failed, ids: %v, rid: %v", objID,
+				toRefresh, rid)
+			return nil, err
+		}
+
+		for _, inst := range refresh {
+			c.store.upsertInstance(objID, inst.id, inst.detail)
+			list = append(list, cutDetailWithFields(inst.detail, opt.Fields))
+		}
+	}
+	return list, nil
+}
+
+// GetInstanceWithUniqueKey get the object's instance with the values of a unique rule of the object.
+func (c *Client) GetInstanceWithUniqueKey(ctx context.Context, objID string,
+	opt *metadata.SearchInstWithUniqueKeyOption) (string, error) {
+
+	rid := ctx.Value(common.ContextRequestIDField)
+	if !c.store.isCached(objID) {
+		return "", ObjectNotCachedError
+	}
+
+	rules, err := c.store.rules.get(objID)
+	if err != nil {
+		blog.Errorf("get object %s instance with unique key, but get unique rules failed, err: %v, rid: %v",
+			objID, err, rid)
+		return "", err
+	}
+
+	rule, value, err := matchUniqueRule(rules, opt.Condition)
+	if err != nil {
+		return "", err
+	}
+
+	detail, err := c.getDetailWithUniqueKey(objID, rule, value)
+	if err != nil {
+		blog.Errorf("get object %s instance with unique key from redis failed, err: %v, rid: %v", objID, err, rid)
+		return "", err
+	}
+
+	if len(detail) != 0 {
+		return cutDetailWithFields(detail, opt.Fields), nil
+	}
+
+	// can not found in the cache, get from mongodb and try to refresh the cache.
+	filter := mapstr.MapStr{
+		common.BKObjIDField: objID,
+	}
+	for field, value := range opt.Condition {
+		filter[field] = convertNumber(value)
+	}
+	instances, err := listInstancesFromMongo(filter, 0, 1)
+	if err != nil {
+		return "", err
+	}
+
+	if len(instances) == 0 {
+		return "", InstanceNotFoundError
+	}
+
+	c.store.upsertInstance(objID, instances[0].id, instances[0].detail)
+	return cutDetailWithFields(instances[0].detail, opt.Fields), nil
+}
+
+// getDetailWithUniqueKey get the instance detail with unique value from cache, returns empty detail if
+// it's not cached or the cached detail has a different unique value.
+func (c *Client) getDetailWithUniqueKey(objID string, rule *uniqueRule, value string) (string, error) {
+	id, err := redis.Client().Get(context.Background(), instKey.UniqueKey(objID, rule.id, value)).Result()
+	if err != nil {
+		if redis.IsNilErr(err) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	instID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("got invalid unique key value %s, err: %v", id, err)
+	}
+
+	detail, err := redis.Client().Get(context.Background(), instKey.DetailKey(objID, instID)).Result()
+	if err != nil {
+		if redis.IsNilErr(err) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	// the unique key may be stale, check with the detail.
+	if current, ok := rule.uniqueValueWithDetail(detail); !ok || current != value {
+		return "", nil
+	}
+	return detail, nil
+}
+
+// CheckConsistency check the consistency between the cached instances and mongodb, and repair the cache if needed.
+func (c *Client) CheckConsistency(ctx context.Context, objID string, opt *metadata.CheckInstCacheConsistencyOption) (
+	*metadata.InstCacheConsistencyResult, error) {
+
+	if !c.store.isCached(objID) {
+		return nil, ObjectNotCachedError
+	}
+	return c.store.checkConsistency(ctx, objID, opt.Repair)
+}
+
+func cutDetailWithFields(detail string, fields []string) string {
+	if len(fields) == 0 {
+		return detail
+	}
+	return *json.CutJsonDataWithFields(&detail, fields)
+}
+
+// convertNumber convert the json number in request to the int64 or float64 value which can be used in mongodb.
+func convertNumber(value interface{}) interface{} {
+	number, ok := value.(interface {
+		Int64() (int64, error)
+		Float64() (float64, error)
+	})
+	if !ok {
+		return value
+	}
+
+	if i, err := number.Int64(); err == nil {
+		return i
+	}
+	if f, err := number.Float64(); err == nil {
+		return f
+	}
+	return value
+}
@@ -0,0 +1,197 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package instance
+
+import (
+	"context"
+	"fmt"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/storage/driver/redis"
+	"configcenter/src/storage/reflector"
+	"configcenter/src/storage/stream/types"
+
+	"github.com/tidwall/gjson"
+)
+
+type instanceCache struct {
+	store    *instanceStore
+	event    reflector.Interface
+	isMaster func() bool
+}
+
+func (c *instanceCache) Run() error {
+	for _, objID := range c.store.config.Objects {
+		if err := c.runWatch(objID); err != nil {
+			blog.Errorf("run object %s instance cache watch failed, err: %v", objID, err)
+			return err
+		}
+	}
+
+	if err := c.runDeleteWatch(); err != nil {
+		blog.Errorf("run instance cache delete watch failed, err: %v", err)
+		return err
+	}
+
+	if c.store.config.CheckInterval > 0 {
+		go c.loopCheckConsistency()
+	}
+	return nil
+}
+
+func (c *instanceCache) runWatch(objID string) error {
+
+	opts := types.Options{
+		EventStruct: new(map[string]interface{}),
+		Collection:  common.BKTableNameBaseInst,
+		Filter: mapstr.MapStr{
+			common.BKObjIDField: objID,
+		},
+	}
+
+	_, err := redis.Client().Get(context.Background(), instKey.ListDoneKey(objID)).Result()
+	if err != nil {
+		if !redis.IsNilErr(err) {
+			blog.Errorf("get object %s instance list done redis key failed, err: %v", objID, err)
+			return fmt.Errorf("get object %s instance list done redis key failed, err: %v", objID, err)
+		}
+		listCap := &reflector.Capable{
+			OnChange: reflector.OnChangeEvent{
+				OnLister: c.onUpsert,
+				OnAdd:    c.onUpsert,
+				OnUpdate: c.onUpsert,
+				OnListerDone: func() {
+					c.onListDone(objID)
+				},
+				OnDelete: c.onDelete,
+			},
+		}
+		// do with list watcher.
+		page := 500
+		listOpts := &types.ListWatchOptions{
+			Options:  opts,
+			PageSize: &page,
+		}
+		blog.Infof("do object %s instance cache with list watcher.", objID)
+		return c.event.ListWatcher(context.Background(), listOpts, listCap)
+	}
+
+	watchCap := &reflector.Capable{
+		OnChange: reflector.OnChangeEvent{
+			OnAdd:    c.onUpsert,
+			OnUpdate: c.onUpsert,
+			OnDelete: c.onDelete,
+		},
+	}
+	// do with watcher only.
+	watchOpts := &types.WatchOptions{
+		Options: opts,
+	}
+	blog.Infof("do object %s instance cache with only watcher.", objID)
+	return c.event.Watcher(context.Background(), watchOpts, watchCap)
+}
+
+// runDeleteWatch watch the delete events of all the instances, the delete event has no full document, so it can not
+// be filtered by the object id, the object id and instance id are resolved from the oid relation in onDelete.
+func (c *instanceCache) runDeleteWatch() error {
+	deleteType := types.Delete
+	watchOpts := &types.WatchOptions{
+		Options: types.Options{
+			OperationType: &deleteType,
+			EventStruct:   new(map[string]interface{}),
+			Collection:    common.BKTableNameBaseInst,
+		},
+	}
+
+	watchCap := &reflector.Capable{
+		OnChange: reflector.OnChangeEvent{
+			OnAdd:    c.onUpsert,
+			OnUpdate: c.onUpsert,
+			OnDelete: c.onDelete,
+		},
+	}
+	blog.Infof("do instance cache delete event with only watcher.")
+	return c.event.Watcher(context.Background(), watchOpts, watchCap)
+}
+
+func (c *instanceCache) onUpsert(e *types.Event) {
+	blog.V(4).Infof("received instance upsert event, detail: %s", e.String())
+
+	fields := gjson.GetManyBytes(e.DocBytes, common.BKObjIDField, common.BKInstIDField)
+	objID := fields[0].String()
+	instID := fields[1].Int()
+	if len(objID) == 0 || instID <= 0 {
+		blog.Errorf("received instance upsert event, but got invalid object id or instance id, detail: %s", e.String())
+		return
+	}
+
+	// get instance details from db again to avoid dirty data.
+	detail, err := getInstanceFromMongo(objID, instID)
+	if err != nil {
+		blog.Errorf("received instance %s/%d upsert event, but get detail from mongodb failed, err: %v", objID, instID, err)
+		return
+	}
+	c.store.upsertInstance(objID, instID, detail)
+
+	// record the object id relation for delete usage, even if the instance is not cached.
+	value := instKey.genObjectIDKeyValue(objID, instID)
+	if err := redis.Client().HSet(context.Background(), instKey.ObjectIDKey(), e.Oid, value).Err(); err != nil {
+		blog.Errorf("upsert instance oid: %s relation failed, key: %s, value: %s, err: %v", e.Oid,
+			instKey.ObjectIDKey(), value, err)
+	}
+}
+
+func (c *instanceCache) onDelete(e *types.Event) {
+	blog.V(4).Infof("received instance delete event, detail: %s", e.String())
+
+	// get the object id and instance id from oid key
+	value, err := redis.Client().HGet(context.Background(), instKey.ObjectIDKey(), e.Oid).Result()
+	if err != nil {
+		if redis.IsNilErr(err) {
+			// the instance does not belong to the cached objects.
+			blog.V(4).Infof("received instance delete event, but oid relation not exist, skip, oid: %s", e.Oid)
+			return
+		}
+		blog.Errorf("received instance delete event, but get oid relation failed, detail: %s, err: %v", e.String(), err)
+		return
+	}
+
+	objID, instID, err := instKey.parseObjectIDKeyValue(value)
+	if err != nil {
+		blog.Errorf("received instance delete event, but parse oid relation failed, detail: %s, err: %v", e.String(), err)
+		return
+	}
+
+	if err := c.store.deleteInstance(objID, instID); err != nil {
+		return
+	}
+
+	if err := redis.Client().HDel(context.Background(), instKey.ObjectIDKey(), e.Oid).Err(); err != nil {
+		blog.Errorf("delete instance oid: %s relation failed, key: %s, err: %v", e.Oid, instKey.ObjectIDKey(), err)
+		return
+	}
+	blog.V(4).Infof("received instance delete event, detail: %s, delete related caches success", e.String())
+}
+
+// onListDone is to tell us that all the instances of the object has been list from mongodb and already
+// sync it to cache.
+func (c *instanceCache) onListDone(objID string) {
+	if err := redis.Client().Set(context.Background(), instKey.ListDoneKey(objID), "done", 0).Err(); err != nil {
+		blog.Errorf("list object %s instances to cache and list done, but set list done key: %s failed, err: %v",
+			objID, instKey.ListDoneKey(objID), err)
+		return
+	}
+	blog.Infof("list object %s instances to cache and list done", objID)
+}
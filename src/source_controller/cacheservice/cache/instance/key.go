@@ -0,0 +1,76 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package instance
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"configcenter/src/common"
+)
+
+const instKeyNamespace = common.BKCacheKeyV3Prefix + "instance"
+
+var instKey = instKeyGenerator{namespace: instKeyNamespace}
+
+type instKeyGenerator struct {
+	namespace string
+}
+
+// key to store the instance detail, it has no ttl, the cache is refreshed by the event and consistency check.
+func (k instKeyGenerator) DetailKey(objID string, instID int64) string {
+	return k.namespace + ":" + objID + ":detail:" + strconv.FormatInt(instID, 10)
+}
+
+// a redis set key to store the cached instance ids of the object, which is used to limit the number of
+// cached instances and to find the redundant instances in consistency check.
+func (k instKeyGenerator) IDListKey(objID string) string {
+	return k.namespace + ":" + objID + ":id_list"
+}
+
+// key to store the relation with the unique value and instance id:
+// key: unique id:md5 of the unique field values
+// value: instance id
+func (k instKeyGenerator) UniqueKey(objID string, uniqueID uint64, value string) string {
+	sum := md5.Sum([]byte(value))
+	return fmt.Sprintf("%s:%s:unique:%d:%s", k.namespace, objID, uniqueID, hex.EncodeToString(sum[:]))
+}
+
+func (k instKeyGenerator) ListDoneKey(objID string) string {
+	return k.namespace + ":" + objID + ":listdone"
+}
+
+// this key is to save the document object id(as is _id) relations with the object id and instance id
+func (k instKeyGenerator) ObjectIDKey() string {
+	return k.namespace + ":oid"
+}
+
+func (k instKeyGenerator) genObjectIDKeyValue(objID string, instID int64) string {
+	return objID + ":" + strconv.FormatInt(instID, 10)
+}
+
+func (k instKeyGenerator) parseObjectIDKeyValue(value string) (string, int64, error) {
+	idx := strings.LastIndex(value, ":")
+	if idx <= 0 {
+		return "", 0, fmt.Errorf("invalid oid value: %s", value)
+	}
+
+	instID, err := strconv.ParseInt(value[idx+1:], 10, 64)
+	if err != nil {
+		return "", 0, fmt.Errorf("oid value: %s with invalid inst id, err: %v", value, err)
+	}
+	return value[:idx], instID, nil
+}
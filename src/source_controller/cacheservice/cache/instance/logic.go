@@ -0,0 +1,248 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package instance
+
+import (
+	"context"
+	"fmt"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/json"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/util"
+	"configcenter/src/storage/driver/mongodb"
+	"configcenter/src/storage/driver/redis"
+)
+
+// NOTE: this script depends on the id list key is a redis set.
+// add the instance id to the id list if it's already cached or the cached count is less than the limit,
+// returns 1 if the instance can be cached, otherwise returns 0.
+const admitInstanceScript = `
+if (redis.call('sismember', KEYS[1], ARGV[1]) == 1) then
+	return 1
+end;
+
+if (redis.call('scard', KEYS[1]) >= tonumber(ARGV[2])) then
+	return 0
+end;
+
+redis.call('sadd', KEYS[1], ARGV[1]);
+return 1
+`
+
+// instanceStore operate the instance cache with the size limits.
+type instanceStore struct {
+	config Config
+	// key is object id
+	objects map[string]bool
+	rules   *uniqueRules
+}
+
+func newInstanceStore(conf Config) *instanceStore {
+	objects := make(map[string]bool)
+	for _, objID := range conf.Objects {
+		objects[objID] = true
+	}
+
+	return &instanceStore{
+		config:  conf,
+		objects: objects,
+		rules:   newUniqueRules(),
+	}
+}
+
+// isCached check if the instances of the object is configured to be cached.
+func (s *instanceStore) isCached(objID string) bool {
+	return s.objects[objID]
+}
+
+// admit check if the instance can be cached with the count limit.
+func (s *instanceStore) admit(objID string, instID int64) (bool, error) {
+	result, err := redis.Client().Eval(context.Background(), admitInstanceScript, []string{instKey.IDListKey(objID)},
+		instID, s.config.MaxCount).Result()
+	if err != nil {
+		return false, fmt.Errorf("run admitInstanceScript in redis failed, err: %v", err)
+	}
+
+	admitted, err := util.GetInt64ByInterface(result)
+	if err != nil {
+		return false, fmt.Errorf("run admitInstanceScript in redis, but get invalid result: %v", result)
+	}
+	return admitted == 1, nil
+}
+
+// upsertInstance refresh the instance detail and it's unique keys, the instance is not cached if it's over the
+// size limits. returns true if the instance is cached.
+func (s *instanceStore) upsertInstance(objID string, instID int64, detail string) bool {
+	if len(detail) > s.config.MaxDetailSize {
+		blog.V(4).Infof("instance %s/%d detail size %d is over limit %d, do not cache it", objID, instID,
+			len(detail), s.config.MaxDetailSize)
+		// remove the old one, so that it will be read from mongodb.
+		s.deleteInstance(objID, instID)
+		return false
+	}
+
+	admitted, err := s.admit(objID, instID)
+	if err != nil {
+		blog.Errorf("upsert instance %s/%d cache, but check count limit failed, err: %v", objID, instID, err)
+		return false
+	}
+
+	if !admitted {
+		blog.V(4).Infof("instance %s cached count is over limit %d, do not cache instance %d", objID,
+			s.config.MaxCount, instID)
+		return false
+	}
+
+	old, err := redis.Client().Get(context.Background(), instKey.DetailKey(objID, instID)).Result()
+	if err != nil && !redis.IsNilErr(err) {
+		blog.Errorf("upsert instance %s/%d cache, but get old detail failed, err: %v", objID, instID, err)
+		return false
+	}
+
+	rules, err := s.rules.get(objID)
+	if err != nil {
+		// the detail is still cached, the instance can be found with id.
+		blog.Errorf("upsert instance %s/%d cache, but get unique rules failed, err: %v", objID, instID, err)
+	}
+
+	pipeline := redis.Client().Pipeline()
+	defer pipeline.Close()
+
+	uniqueKeys := make(map[string]bool)
+	for _, rule := range rules {
+		value, ok := rule.uniqueValueWithDetail(detail)
+		if !ok {
+			continue
+		}
+		key := instKey.UniqueKey(objID, rule.id, value)
+		uniqueKeys[key] = true
+		pipeline.Set(key, instID, 0)
+	}
+
+	// remove the unique keys of the changed unique values.
+	if len(old) != 0 {
+		for _, rule := range rules {
+			value, ok := rule.uniqueValueWithDetail(old)
+			if !ok {
+				continue
+			}
+			key := instKey.UniqueKey(objID, rule.id, value)
+			if !uniqueKeys[key] {
+				pipeline.Del(key)
+			}
+		}
+	}
+
+	pipeline.Set(instKey.DetailKey(objID, instID), detail, 0)
+
+	if _, err = pipeline.Exec(); err != nil {
+		blog.Errorf("upsert instance %s/%d cache, but upsert to redis failed, err: %v", objID, instID, err)
+		return false
+	}
+	blog.V(4).Infof("refresh instance cache success, object: %s, instance id: %d", objID, instID)
+	return true
+}
+
+// deleteInstance delete the instance detail, unique keys and remove it from id list.
+func (s *instanceStore) deleteInstance(objID string, instID int64) error {
+	old, err := redis.Client().Get(context.Background(), instKey.DetailKey(objID, instID)).Result()
+	if err != nil && !redis.IsNilErr(err) {
+		blog.Errorf("delete instance %s/%d cache, but get old detail failed, err: %v", objID, instID, err)
+		return err
+	}
+
+	pipeline := redis.Client().Pipeline()
+	defer pipeline.Close()
+
+	if len(old) != 0 {
+		rules, err := s.rules.get(objID)
+		if err != nil {
+			blog.Errorf("delete instance %s/%d cache, but get unique rules failed, err: %v", objID, instID, err)
+		}
+		for _, rule := range rules {
+			if value, ok := rule.uniqueValueWithDetail(old); ok {
+				pipeline.Del(instKey.UniqueKey(objID, rule.id, value))
+			}
+		}
+	}
+
+	pipeline.Del(instKey.DetailKey(objID, instID))
+	pipeline.SRem(instKey.IDListKey(objID), instID)
+
+	if _, err = pipeline.Exec(); err != nil {
+		blog.Errorf("delete instance %s/%d cache failed, err: %v", objID, instID, err)
+		return err
+	}
+	return nil
+}
+
+type instanceBase struct {
+	id     int64
+	detail string
+}
+
+func getInstanceFromMongo(objID string, instID int64) (string, error) {
+	filter := mapstr.MapStr{
+		common.BKObjIDField:  objID,
+		common.BKInstIDField: instID,
+	}
+	instance := make(map[string]interface{})
+	err := mongodb.Client().Table(common.BKTableNameBaseInst).Find(filter).One(context.Background(), &instance)
+	if err != nil {
+		blog.Errorf("get object: %s, inst: %d from mongodb failed, err: %v", objID, instID, err)
+		return "", err
+	}
+
+	js, err := json.Marshal(instance)
+	if err != nil {
+		return "", err
+	}
+	return string(js), nil
+}
+
+func listInstancesFromMongo(filter mapstr.MapStr, start uint64, limit uint64) ([]*instanceBase, error) {
+	instances := make([]map[string]interface{}, 0)
+	err := mongodb.Client().Table(common.BKTableNameBaseInst).Find(filter).Sort(common.BKInstIDField).
+		Start(start).Limit(limit).All(context.Background(), &instances)
+	if err != nil {
+		blog.Errorf("list instances from mongodb failed, filter: %v, err: %v", filter, err)
+		return nil, err
+	}
+
+	all := make([]*instanceBase, len(instances))
+	for idx := range instances {
+		js, err := json.Marshal(instances[idx])
+		if err != nil {
+			return nil, err
+		}
+
+		id, err := util.GetInt64ByInterface(instances[idx][common.BKInstIDField])
+		if err != nil {
+			return nil, fmt.Errorf("got invalid instance id: %v, err: %v", instances[idx][common.BKInstIDField], err)
+		}
+		all[idx] = &instanceBase{id: id, detail: string(js)}
+	}
+	return all, nil
+}
+
+func listInstancesWithIDFromMongo(objID string, instIDs []int64) ([]*instanceBase, error) {
+	filter := mapstr.MapStr{
+		common.BKObjIDField: objID,
+		common.BKInstIDField: mapstr.MapStr{
+			common.BKDBIN: instIDs,
+		},
+	}
+	return listInstancesFromMongo(filter, 0, uint64(len(instIDs)))
+}
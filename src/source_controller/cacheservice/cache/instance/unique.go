@@ -0,0 +1,197 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package instance
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/json"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/storage/driver/mongodb"
+
+	"github.com/tidwall/gjson"
+)
+
+// the unique rules are changed rarely, so they are cached in memory and reloaded after this interval.
+const uniqueRuleRefreshInterval = 5 * time.Minute
+
+// uniqueRule is a unique rule of the object with the property keys only, the rules with association keys
+// can not be used to find the instance.
+type uniqueRule struct {
+	id uint64
+	// the property ids of the rule, sorted.
+	fields []string
+}
+
+type objectRules struct {
+	rules    []uniqueRule
+	loadTime time.Time
+}
+
+type uniqueRules struct {
+	lock sync.RWMutex
+	// key is object id
+	rules map[string]*objectRules
+	// loadRules load the unique rules of the object, it can be replaced in test.
+	loadRules func(objID string) ([]uniqueRule, error)
+}
+
+func newUniqueRules() *uniqueRules {
+	return &uniqueRules{
+		rules:     make(map[string]*objectRules),
+		loadRules: getUniqueRulesFromMongo,
+	}
+}
+
+// get the unique rules of the object, reload it if expired.
+func (u *uniqueRules) get(objID string) ([]uniqueRule, error) {
+	u.lock.RLock()
+	obj, exist := u.rules[objID]
+	u.lock.RUnlock()
+	if exist && time.Since(obj.loadTime) < uniqueRuleRefreshInterval {
+		return obj.rules, nil
+	}
+
+	rules, err := u.loadRules(objID)
+	if err != nil {
+		if exist {
+			blog.Errorf("reload object %s unique rules failed, use the old rules, err: %v", objID, err)
+			return obj.rules, nil
+		}
+		return nil, err
+	}
+
+	u.lock.Lock()
+	u.rules[objID] = &objectRules{rules: rules, loadTime: time.Now()}
+	u.lock.Unlock()
+	return rules, nil
+}
+
+func getUniqueRulesFromMongo(objID string) ([]uniqueRule, error) {
+	filter := mapstr.MapStr{
+		common.BKObjIDField: objID,
+	}
+
+	uniques := make([]metadata.ObjectUnique, 0)
+	err := mongodb.Client().Table(common.BKTableNameObjUnique).Find(filter).All(context.Background(), &uniques)
+	if err != nil {
+		blog.Errorf("get object %s unique rules from mongodb failed, err: %v", objID, err)
+		return nil, err
+	}
+
+	attributes := make([]metadata.Attribute, 0)
+	err = mongodb.Client().Table(common.BKTableNameObjAttDes).Find(filter).
+		Fields(common.BKFieldID, common.BKPropertyIDField).All(context.Background(), &attributes)
+	if err != nil {
+		blog.Errorf("get object %s attributes from mongodb failed, err: %v", objID, err)
+		return nil, err
+	}
+
+	propertyMap := make(map[uint64]string)
+	for _, attr := range attributes {
+		propertyMap[uint64(attr.ID)] = attr.PropertyID
+	}
+
+	rules := make([]uniqueRule, 0)
+	for _, unique := range uniques {
+		rule := uniqueRule{id: unique.ID}
+		for _, key := range unique.Keys {
+			if key.Kind != metadata.UniqueKeyKindProperty {
+				rule.fields = nil
+				break
+			}
+			property, exist := propertyMap[key.ID]
+			if !exist {
+				rule.fields = nil
+				break
+			}
+			rule.fields = append(rule.fields, property)
+		}
+		if len(rule.fields) == 0 {
+			continue
+		}
+		sort.Strings(rule.fields)
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// canonicalValue convert the json value to the compared value, the string is unquoted and others use the raw json.
+func canonicalValue(value gjson.Result) string {
+	if value.Type == gjson.String {
+		return value.String()
+	}
+	return value.Raw
+}
+
+// isEmptyValue the empty value is not checked by unique rule, so it can not be used to find the instance.
+func isEmptyValue(value gjson.Result) bool {
+	return !value.Exists() || value.Type == gjson.Null || (value.Type == gjson.String && len(value.Str) == 0)
+}
+
+// uniqueValueWithDetail get the unique value of the instance detail, returns false if any of the fields is empty.
+func (r uniqueRule) uniqueValueWithDetail(detail string) (string, bool) {
+	values := gjson.GetMany(detail, r.fields...)
+	all := make([]string, len(values))
+	for idx, value := range values {
+		if isEmptyValue(value) {
+			return "", false
+		}
+		all[idx] = canonicalValue(value)
+	}
+	return strings.Join(all, "\n"), true
+}
+
+// matchCondition check if the condition has the same fields with the rule, and returns the unique value of it.
+func (r uniqueRule) matchCondition(cond map[string]interface{}) (string, bool) {
+	if len(cond) != len(r.fields) {
+		return "", false
+	}
+
+	all := make([]string, len(r.fields))
+	for idx, field := range r.fields {
+		value, exist := cond[field]
+		if !exist {
+			return "", false
+		}
+
+		js, err := json.Marshal(value)
+		if err != nil {
+			return "", false
+		}
+		result := gjson.ParseBytes(js)
+		if isEmptyValue(result) {
+			return "", false
+		}
+		all[idx] = canonicalValue(result)
+	}
+	return strings.Join(all, "\n"), true
+}
+
+// matchUniqueRule find the unique rule with the same fields as the condition.
+func matchUniqueRule(rules []uniqueRule, cond map[string]interface{}) (*uniqueRule, string, error) {
+	for idx := range rules {
+		if value, ok := rules[idx].matchCondition(cond); ok {
+			return &rules[idx], value, nil
+		}
+	}
+	return nil, "", fmt.Errorf("no unique rule matches the condition fields")
+}
@@ -0,0 +1,81 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package instance
+
+import (
+	"testing"
+
+	"configcenter/src/common/json"
+)
+
+func TestUniqueRuleMatchCondition(t *testing.T) {
+	rules := []uniqueRule{
+		{id: 1, fields: []string{"bk_inst_name"}},
+		{id: 2, fields: []string{"port", "sn"}},
+	}
+	detail := `{"bk_inst_id":1,"bk_inst_name":"switch-1","sn":"a\"b","port":8080,"desc":""}`
+
+	cond := make(map[string]interface{})
+	// decode the condition as the request does, so the number is a json number.
+	if err := json.UnmarshalFromString(`{"sn":"a\"b","port":8080}`, &cond); err != nil {
+		t.Fatal(err)
+	}
+
+	rule, value, err := matchUniqueRule(rules, cond)
+	if err != nil {
+		t.Fatalf("match unique rule failed, err: %v", err)
+	}
+	if rule.id != 2 {
+		t.Fatalf("matched rule %d, expect 2", rule.id)
+	}
+
+	detailValue, ok := rule.uniqueValueWithDetail(detail)
+	if !ok || detailValue != value {
+		t.Fatalf("unique value of detail %q is not same as condition %q", detailValue, value)
+	}
+
+	if _, _, err := matchUniqueRule(rules, map[string]interface{}{"sn": "a"}); err == nil {
+		t.Fatalf("condition with part of the rule fields should not match")
+	}
+
+	emptyRule := uniqueRule{id: 3, fields: []string{"desc"}}
+	if _, ok := emptyRule.uniqueValueWithDetail(detail); ok {
+		t.Fatalf("empty value should not have unique key")
+	}
+
+	if convertNumber(cond["port"]) != int64(8080) {
+		t.Fatalf("convert json number failed, got %#v", convertNumber(cond["port"]))
+	}
+}
+
+func TestObjectIDKeyValue(t *testing.T) {
+	value := instKey.genObjectIDKeyValue("switch", 10)
+	objID, instID, err := instKey.parseObjectIDKeyValue(value)
+	if err != nil {
+		t.Fatalf("parse oid value %s failed, err: %v", value, err)
+	}
+	if objID != "switch" || instID != 10 {
+		t.Fatalf("parse oid value %s, got %s/%d", value, objID, instID)
+	}
+
+	if _, _, err := instKey.parseObjectIDKeyValue("switch"); err == nil {
+		t.Fatalf("invalid oid value should fail")
+	}
+}
+
+func TestParseObjects(t *testing.T) {
+	objects := ParseObjects(" switch, ,host,database ")
+	if len(objects) != 2 || objects[0] != "switch" || objects[1] != "database" {
+		t.Fatalf("parse objects got %v, expect [switch database]", objects)
+	}
+}
@@ -0,0 +1,87 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package process
+
+import (
+	"context"
+	"errors"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/metadata"
+	"configcenter/src/storage/driver/redis"
+)
+
+type Client struct{}
+
+// ListServiceInstancesWithHostIDs list the service instances, processes and bind info on the hosts from redis
+// with host id list. the hosts without service instance are returned with empty service instances,
+// the sequence may not same as the request host ids.
+func (c *Client) ListServiceInstancesWithHostIDs(ctx context.Context, opt *metadata.ListServiceInstanceWithHostOption) (
+	[]string, error) {
+
+	rid := ctx.Value(common.ContextRequestIDField)
+	if len(opt.HostIDs) > 500 {
+		return nil, errors.New("host id length is over limit")
+	}
+
+	if len(opt.HostIDs) == 0 {
+		return nil, errors.New("host id array is empty")
+	}
+
+	keys := make([]string, len(opt.HostIDs))
+	for idx, hostID := range opt.HostIDs {
+		keys[idx] = processKey.HostDetailKey(hostID)
+	}
+
+	details, err := redis.Client().MGet(context.Background(), keys...).Result()
+	if err != nil {
+		blog.Errorf("list service instances with host ids, but get from redis failed, err: %v, rid: %v", err, rid)
+		return nil, err
+	}
+
+	toRefresh := make([]int64, 0)
+	list := make([]string, 0)
+	for idx, h := range details {
+		if h == nil {
+			toRefresh = append(toRefresh, opt.HostIDs[idx])
+			continue
+		}
+		detail, ok := h.(string)
+		if !ok {
+			blog.Errorf("list service instances with host ids, but got invalid detail type, not string, "+
+				"detail: %v, rid: %v", h, rid)
+			return nil, errors.New("invalid host service instance detail type, not string")
+		}
+		list = append(list, filterWithBiz(detail, opt.BizID))
+	}
+
+	if len(toRefresh) != 0 {
+		// can not found in the cache, need refresh the cache
+		refresh, err := listHostServiceInstancesFromMongo(toRefresh)
+		if err != nil {
+			blog.Errorf("list service instances with host ids, but get from db failed, hosts: %v, err: %v, rid: %v",
+				toRefresh, err, rid)
+			return nil, err
+		}
+
+		if err := refreshHostCache(refresh); err != nil {
+			blog.Errorf("list service instances with host ids, but refresh cache failed, err: %v, rid: %v", err, rid)
+		}
+
+		for _, host := range refresh {
+			list = append(list, filterWithBiz(host.detail, opt.BizID))
+		}
+	}
+	return list, nil
+}
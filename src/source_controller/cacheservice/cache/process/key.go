@@ -0,0 +1,50 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package process
+
+import (
+	"math/rand"
+	"strconv"
+	"time"
+
+	"configcenter/src/common"
+)
+
+const processKeyNamespace = common.BKCacheKeyV3Prefix + "service_instance"
+
+var processKey = processKeyGenerator{
+	namespace: processKeyNamespace,
+	// 30 minutes
+	expireSeconds:      30 * 60 * time.Second,
+	expireRangeSeconds: [2]int{-600, 600},
+}
+
+type processKeyGenerator struct {
+	namespace string
+	// expireSeconds is defined how long is the ttl for the key, it's always used with the expireRangeSeconds
+	// to avoid the keys is expired at same time.
+	expireSeconds time.Duration
+	// min:[0], max:[1]
+	expireRangeSeconds [2]int
+}
+
+// key to store the service instances, processes and bind info on a host.
+func (p processKeyGenerator) HostDetailKey(hostID int64) string {
+	return p.namespace + ":host:" + strconv.FormatInt(hostID, 10)
+}
+
+func (p processKeyGenerator) WithRandomExpireSeconds() time.Duration {
+	rand.Seed(time.Now().UnixNano())
+	seconds := rand.Intn(p.expireRangeSeconds[1]-p.expireRangeSeconds[0]) + p.expireRangeSeconds[0]
+	return p.expireSeconds + time.Duration(seconds)*time.Second
+}
@@ -0,0 +1,177 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package process
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/json"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/storage/driver/mongodb"
+	"configcenter/src/storage/driver/redis"
+
+	"github.com/tidwall/gjson"
+)
+
+type hostDetail struct {
+	hostID int64
+	detail string
+}
+
+// listHostServiceInstancesFromMongo get the service instances, processes and bind info on the hosts from mongodb,
+// the hosts without service instance are returned with empty service instances.
+func listHostServiceInstancesFromMongo(hostIDs []int64) ([]*hostDetail, error) {
+	filter := mapstr.MapStr{
+		common.BKHostIDField: mapstr.MapStr{
+			common.BKDBIN: hostIDs,
+		},
+	}
+
+	instances := make([]metadata.ServiceInstance, 0)
+	err := mongodb.Client().Table(common.BKTableNameServiceInstance).Find(filter).Sort(common.BKFieldID).
+		All(context.Background(), &instances)
+	if err != nil {
+		blog.Errorf("get service instances with hosts %v from mongodb failed, err: %v", hostIDs, err)
+		return nil, err
+	}
+
+	relations := make([]metadata.ProcessInstanceRelation, 0)
+	err = mongodb.Client().Table(common.BKTableNameProcessInstanceRelation).Find(filter).
+		Sort(common.BKProcessIDField).All(context.Background(), &relations)
+	if err != nil {
+		blog.Errorf("get process relations with hosts %v from mongodb failed, err: %v", hostIDs, err)
+		return nil, err
+	}
+
+	processMap := make(map[int64]mapstr.MapStr)
+	if len(relations) != 0 {
+		processIDs := make([]int64, len(relations))
+		for idx, relation := range relations {
+			processIDs[idx] = relation.ProcessID
+		}
+
+		processFilter := mapstr.MapStr{
+			common.BKProcessIDField: mapstr.MapStr{
+				common.BKDBIN: processIDs,
+			},
+		}
+		processes := make([]mapstr.MapStr, 0)
+		err = mongodb.Client().Table(common.BKTableNameBaseProcess).Find(processFilter).All(context.Background(),
+			&processes)
+		if err != nil {
+			blog.Errorf("get processes %v from mongodb failed, err: %v", processIDs, err)
+			return nil, err
+		}
+
+		for _, process := range processes {
+			processID, err := util.GetInt64ByInterface(process[common.BKProcessIDField])
+			if err != nil {
+				blog.Errorf("got invalid process id, process: %v, err: %v", process, err)
+				continue
+			}
+			processMap[processID] = process
+		}
+	}
+
+	// key is service instance id
+	processInstances := make(map[int64][]metadata.ProcessInstance)
+	for _, relation := range relations {
+		process, exist := processMap[relation.ProcessID]
+		if !exist {
+			continue
+		}
+		processInstances[relation.ServiceInstanceID] = append(processInstances[relation.ServiceInstanceID],
+			metadata.ProcessInstance{Property: process, Relation: relation})
+	}
+
+	hostMap := make(map[int64]*metadata.HostServiceInstanceCache)
+	for _, hostID := range hostIDs {
+		hostMap[hostID] = &metadata.HostServiceInstanceCache{
+			HostID:           hostID,
+			ServiceInstances: make([]metadata.ServiceInstanceCache, 0),
+		}
+	}
+	for _, instance := range instances {
+		host, exist := hostMap[instance.HostID]
+		if !exist {
+			continue
+		}
+		processes := processInstances[instance.ID]
+		if processes == nil {
+			processes = make([]metadata.ProcessInstance, 0)
+		}
+		host.ServiceInstances = append(host.ServiceInstances, metadata.ServiceInstanceCache{
+			ServiceInstance: instance,
+			Processes:       processes,
+		})
+	}
+
+	list := make([]*hostDetail, 0)
+	for _, hostID := range hostIDs {
+		host, exist := hostMap[hostID]
+		if !exist {
+			// duplicate host id
+			continue
+		}
+		delete(hostMap, hostID)
+
+		js, err := json.Marshal(host)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, &hostDetail{hostID: hostID, detail: string(js)})
+	}
+	return list, nil
+}
+
+// refreshHostCache refresh the host's service instance cache.
+func refreshHostCache(list []*hostDetail) error {
+	if len(list) == 0 {
+		return nil
+	}
+
+	pipeline := redis.Client().Pipeline()
+	defer pipeline.Close()
+
+	for _, host := range list {
+		pipeline.Set(processKey.HostDetailKey(host.hostID), host.detail, processKey.WithRandomExpireSeconds())
+	}
+
+	if _, err := pipeline.Exec(); err != nil {
+		return fmt.Errorf("refresh host service instance cache failed, err: %v", err)
+	}
+	return nil
+}
+
+// filterWithBiz only keep the service instances of the business in the host detail.
+func filterWithBiz(detail string, bizID int64) string {
+	if bizID == 0 {
+		return detail
+	}
+
+	elements := gjson.GetMany(detail, common.BKHostIDField, "service_instances")
+	instances := make([]string, 0)
+	for _, instance := range elements[1].Array() {
+		if instance.Get(common.BKAppIDField).Int() == bizID {
+			instances = append(instances, instance.Raw)
+		}
+	}
+	return fmt.Sprintf(`{"%s":%d,"service_instances":[%s]}`, common.BKHostIDField, elements[0].Int(),
+		strings.Join(instances, ","))
+}
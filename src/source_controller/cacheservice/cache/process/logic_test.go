@@ -0,0 +1,41 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package process
+
+import (
+	"testing"
+)
+
+func TestFilterWithBiz(t *testing.T) {
+	detail := `{"bk_host_id":1,"service_instances":[{"id":1,"bk_biz_id":2,"processes":[]},` +
+		`{"id":2,"bk_biz_id":3,"processes":[]}]}`
+
+	if got := filterWithBiz(detail, 0); got != detail {
+		t.Fatalf("filter without biz should return the detail, got: %s", got)
+	}
+
+	expect := `{"bk_host_id":1,"service_instances":[{"id":2,"bk_biz_id":3,"processes":[]}]}`
+	if got := filterWithBiz(detail, 3); got != expect {
+		t.Fatalf("filter with biz 3, expect: %s, got: %s", expect, got)
+	}
+
+	expect = `{"bk_host_id":1,"service_instances":[]}`
+	if got := filterWithBiz(detail, 4); got != expect {
+		t.Fatalf("filter with biz 4, expect: %s, got: %s", expect, got)
+	}
+
+	empty := `{"bk_host_id":5,"service_instances":[]}`
+	if got := filterWithBiz(empty, 2); got != empty {
+		t.Fatalf("filter host without service instance, expect: %s, got: %s", empty, got)
+	}
+}
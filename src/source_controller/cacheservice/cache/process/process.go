@@ -0,0 +1,173 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package process
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/storage/driver/mongodb"
+	"configcenter/src/storage/reflector"
+	"configcenter/src/storage/stream/types"
+
+	"github.com/tidwall/gjson"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/x/bsonx"
+)
+
+// a service instance with processes is created or deleted with several events of the host,
+// so the hosts are refreshed together after this interval.
+const refreshInterval = 500 * time.Millisecond
+
+type processCache struct {
+	event reflector.Interface
+	// pending is the hosts to refresh, key is host id.
+	pending map[int64]struct{}
+	lock    sync.Mutex
+}
+
+func (p *processCache) Run() error {
+	for _, collection := range []string{common.BKTableNameServiceInstance, common.BKTableNameBaseProcess,
+		common.BKTableNameProcessInstanceRelation} {
+
+		watchCap := &reflector.Capable{
+			OnChange: reflector.OnChangeEvent{
+				OnAdd:    p.onUpsert,
+				OnUpdate: p.onUpsert,
+				OnDelete: p.onDelete,
+			},
+		}
+		// do with watcher only, the host is cached when it's searched.
+		watchOpts := &types.WatchOptions{
+			Options: types.Options{
+				EventStruct: new(map[string]interface{}),
+				Collection:  collection,
+			},
+		}
+		blog.Infof("do %s service instance cache with only watcher", collection)
+		if err := p.event.Watcher(context.Background(), watchOpts, watchCap); err != nil {
+			return err
+		}
+	}
+
+	go p.loopRefresh()
+	return nil
+}
+
+func (p *processCache) onUpsert(e *types.Event) {
+	blog.V(4).Infof("received service instance upsert event, detail: %s", e.String())
+
+	elements := gjson.GetManyBytes(e.DocBytes, common.BKHostIDField, common.BKProcessIDField)
+	hostID := elements[0].Int()
+	if hostID <= 0 {
+		// the process has no host id, get it with process relation.
+		processID := elements[1].Int()
+		if processID <= 0 {
+			blog.Errorf("received service instance upsert event, but got invalid host id, detail: %s", e.String())
+			return
+		}
+
+		relation := new(metadata.ProcessInstanceRelation)
+		filter := mapstr.MapStr{common.BKProcessIDField: processID}
+		err := mongodb.Client().Table(common.BKTableNameProcessInstanceRelation).Find(filter).
+			One(context.Background(), relation)
+		if err != nil {
+			// the relation is created after the process, and the host is refreshed with it's event.
+			blog.V(4).Infof("received process %d upsert event, but get relation failed, err: %v", processID, err)
+			return
+		}
+		hostID = relation.HostID
+	}
+
+	p.addPending(hostID)
+}
+
+func (p *processCache) onDelete(e *types.Event) {
+	blog.V(4).Infof("received service instance delete event, detail: %s", e.String())
+
+	filter := mapstr.MapStr{
+		"oid": e.Oid,
+	}
+	doc := bsonx.Doc{}
+	err := mongodb.Client().Table(common.BKTableNameDelArchive).Find(filter).One(context.Background(), &doc)
+	if err != nil {
+		blog.Errorf("received service instance delete event, but get archive deleted doc from mongodb failed, "+
+			"oid: %s, err: %v", e.Oid, err)
+		return
+	}
+
+	byt, err := bson.MarshalExtJSON(doc.Lookup("detail"), false, false)
+	if err != nil {
+		blog.Errorf("received service instance delete event, but marshal doc to bytes failed, oid: %s, err: %v",
+			e.Oid, err)
+		return
+	}
+
+	// the deleted process has no host id, the host is refreshed with the process relation delete event.
+	hostID := gjson.GetBytes(byt, common.BKHostIDField).Int()
+	if hostID <= 0 {
+		return
+	}
+	p.addPending(hostID)
+}
+
+func (p *processCache) addPending(hostID int64) {
+	p.lock.Lock()
+	p.pending[hostID] = struct{}{}
+	p.lock.Unlock()
+}
+
+func (p *processCache) loopRefresh() {
+	for {
+		time.Sleep(refreshInterval)
+
+		p.lock.Lock()
+		pending := p.pending
+		p.pending = make(map[int64]struct{})
+		p.lock.Unlock()
+
+		if len(pending) == 0 {
+			continue
+		}
+
+		hostIDs := make([]int64, 0, len(pending))
+		for hostID := range pending {
+			hostIDs = append(hostIDs, hostID)
+		}
+
+		for start := 0; start < len(hostIDs); start += common.BKMaxPageSize {
+			end := start + common.BKMaxPageSize
+			if end > len(hostIDs) {
+				end = len(hostIDs)
+			}
+
+			list, err := listHostServiceInstancesFromMongo(hostIDs[start:end])
+			if err != nil {
+				blog.Errorf("refresh host %v service instance cache, but get from mongodb failed, err: %v",
+					hostIDs[start:end], err)
+				continue
+			}
+
+			if err := refreshHostCache(list); err != nil {
+				blog.Errorf("refresh host %v service instance cache failed, err: %v", hostIDs[start:end], err)
+				continue
+			}
+			blog.V(4).Infof("refresh host %v service instance cache success", hostIDs[start:end])
+		}
+	}
+}
@@ -72,6 +72,11 @@ func NewEvent(watch stream.Interface, isMaster discovery.ServiceManageInterface)
 		return err
 	}
 
+	if err := e.runHostSnapshotDrift(context.Background()); err != nil {
+		blog.Errorf("run host snapshot drift event flow failed, err: %v", err)
+		return err
+	}
+
 	return nil
 }
 
@@ -178,3 +183,14 @@ func (e *Event) runProcessInstanceRelation(ctx context.Context) error {
 
 	return newFlow(ctx, opts)
 }
+
+func (e *Event) runHostSnapshotDrift(ctx context.Context) error {
+	opts := FlowOptions{
+		Collection: common.BKTableNameHostSnapshotDrift,
+		key:        HostSnapshotDriftKey,
+		watch:      e.watch,
+		isMaster:   e.isMaster,
+	}
+
+	return newFlow(ctx, opts)
+}
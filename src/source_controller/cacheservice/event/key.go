@@ -184,6 +184,25 @@ var ProcessInstanceRelationKey = Key{
 	},
 }
 
+var hostSnapshotDriftFields = []string{common.BKHostIDField, common.BKHostInnerIPField, common.BKCloudIDField}
+var HostSnapshotDriftKey = Key{
+	namespace:  watchCacheNamespace + "host_snapshot_drift",
+	ttlSeconds: 6 * 60 * 60,
+	validator: func(doc []byte) error {
+		fields := gjson.GetManyBytes(doc, hostSnapshotDriftFields...)
+		for idx := range hostSnapshotDriftFields {
+			if !fields[idx].Exists() {
+				return fmt.Errorf("field %s not exist", hostSnapshotDriftFields[idx])
+			}
+		}
+		return nil
+	},
+	instName: func(doc []byte) string {
+		fields := gjson.GetManyBytes(doc, hostSnapshotDriftFields...)
+		return fields[1].String() + ":" + fields[2].String()
+	},
+}
+
 type Key struct {
 	namespace string
 	// the valid event's life time.
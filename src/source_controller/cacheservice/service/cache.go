@@ -18,6 +18,7 @@ import (
 	"configcenter/src/common"
 	"configcenter/src/common/http/rest"
 	"configcenter/src/common/metadata"
+	"configcenter/src/source_controller/cacheservice/cache/instance"
 	"configcenter/src/source_controller/cacheservice/cache/topo_tree"
 )
 
@@ -234,3 +235,95 @@ func (s *cacheService) SearchTopologyNodePath(ctx *rest.Contexts) {
 
 	ctx.RespEntity(paths)
 }
+
+// ListInstanceInCache list the model's instances with id from cache, if not exist in cache, then get from mongodb.
+// only the models configured to be cached are supported.
+func (s *cacheService) ListInstanceInCache(ctx *rest.Contexts) {
+	objID := ctx.Request.PathParameter(common.BKObjIDField)
+
+	opt := new(metadata.ListWithIDOption)
+	if err := ctx.DecodeInto(&opt); nil != err {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	details, err := s.cacheSet.Instance.ListInstances(ctx.Kit.Ctx, objID, opt)
+	if err != nil {
+		if err == instance.ObjectNotCachedError {
+			ctx.RespErrorCodeOnly(common.CCErrCommParamsIsInvalid, "list %s instance with id in cache, but %v", objID, err)
+			return
+		}
+		ctx.RespErrorCodeOnly(common.CCErrCommDBSelectFailed, "list %s instance with id in cache failed, err: %v", objID, err)
+		return
+	}
+	ctx.RespStringArray(details)
+}
+
+// SearchInstanceWithUniqueKeyInCache get the model's instance with the values of a unique rule of the model.
+func (s *cacheService) SearchInstanceWithUniqueKeyInCache(ctx *rest.Contexts) {
+	objID := ctx.Request.PathParameter(common.BKObjIDField)
+
+	opt := new(metadata.SearchInstWithUniqueKeyOption)
+	if err := ctx.DecodeInto(&opt); nil != err {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if len(opt.Condition) == 0 {
+		ctx.RespErrorCodeOnly(common.CCErrCommParamsNeedSet, "search %s instance with unique key, but condition is empty", objID)
+		return
+	}
+
+	detail, err := s.cacheSet.Instance.GetInstanceWithUniqueKey(ctx.Kit.Ctx, objID, opt)
+	if err != nil {
+		switch err {
+		case instance.ObjectNotCachedError:
+			ctx.RespErrorCodeOnly(common.CCErrCommParamsIsInvalid, "search %s instance with unique key, but %v", objID, err)
+		case instance.InstanceNotFoundError:
+			ctx.RespErrorCodeOnly(common.CCErrCommNotFound, "search %s instance with unique key, but %v", objID, err)
+		default:
+			ctx.RespErrorCodeOnly(common.CCErrCommDBSelectFailed, "search %s instance with unique key failed, err: %v", objID, err)
+		}
+		return
+	}
+	ctx.RespString(detail)
+}
+
+// CheckInstanceCacheConsistency compare the model's cached instances with mongodb, and repair the cache if needed.
+func (s *cacheService) CheckInstanceCacheConsistency(ctx *rest.Contexts) {
+	objID := ctx.Request.PathParameter(common.BKObjIDField)
+
+	opt := new(metadata.CheckInstCacheConsistencyOption)
+	if err := ctx.DecodeInto(&opt); nil != err {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	result, err := s.cacheSet.Instance.CheckConsistency(ctx.Kit.Ctx, objID, opt)
+	if err != nil {
+		if err == instance.ObjectNotCachedError {
+			ctx.RespErrorCodeOnly(common.CCErrCommParamsIsInvalid, "check %s instance cache, but %v", objID, err)
+			return
+		}
+		ctx.RespErrorCodeOnly(common.CCErrCommDBSelectFailed, "check %s instance cache consistency failed, err: %v", objID, err)
+		return
+	}
+	ctx.RespEntity(result)
+}
+
+// ListServiceInstanceWithHostInCache list the service instances, processes and bind info on the hosts with host id
+// from cache, if not exist in cache, then get from mongodb directly.
+func (s *cacheService) ListServiceInstanceWithHostInCache(ctx *rest.Contexts) {
+	opt := new(metadata.ListServiceInstanceWithHostOption)
+	if err := ctx.DecodeInto(&opt); nil != err {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	details, err := s.cacheSet.Process.ListServiceInstancesWithHostIDs(ctx.Kit.Ctx, opt)
+	if err != nil {
+		ctx.RespErrorCodeOnly(common.CCErrCommDBSelectFailed, "list service instance with host id in cache failed, err: %v", err)
+		return
+	}
+	ctx.RespStringArray(details)
+}
@@ -75,7 +75,7 @@ func (s *cacheService) SetConfig(cfg options.Config, engine *backbone.Engine, er
 		return eventErr
 	}
 
-	c, cacheErr := cacheop.NewCache(event)
+	c, cacheErr := cacheop.NewCache(event, s.cfg.Instance, engine.ServiceManageInterface.IsMaster)
 	if cacheErr != nil {
 		blog.Errorf("new cache instance failed, err: %v", cacheErr)
 		return cacheErr
@@ -90,6 +90,26 @@ func (s *cacheService) initCache(web *restful.WebService) {
 		Path:    "/find/cache/topo/node_path",
 		Handler: s.SearchTopologyNodePath,
 	})
+	utility.AddHandler(rest.Action{
+		Verb:    http.MethodPost,
+		Path:    "/findmany/cache/instance/{bk_obj_id}",
+		Handler: s.ListInstanceInCache,
+	})
+	utility.AddHandler(rest.Action{
+		Verb:    http.MethodPost,
+		Path:    "/find/cache/instance/{bk_obj_id}/with_unique_key",
+		Handler: s.SearchInstanceWithUniqueKeyInCache,
+	})
+	utility.AddHandler(rest.Action{
+		Verb:    http.MethodPost,
+		Path:    "/find/cache/instance/{bk_obj_id}/consistency",
+		Handler: s.CheckInstanceCacheConsistency,
+	})
+	utility.AddHandler(rest.Action{
+		Verb:    http.MethodPost,
+		Path:    "/findmany/cache/service_instance/with_host_id",
+		Handler: s.ListServiceInstanceWithHostInCache,
+	})
 
 	utility.AddToRestfulWebService(web)
 }
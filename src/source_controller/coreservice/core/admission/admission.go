@@ -0,0 +1,280 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package admission
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/source_controller/coreservice/core"
+	"configcenter/src/storage/dal"
+)
+
+var _ core.AdmissionOperation = (*admissionOperation)(nil)
+
+type admissionOperation struct {
+	dbProxy dal.DB
+	client  *http.Client
+
+	// enabled webhooks of all supplier accounts, reloaded when expired or changed
+	lock     sync.RWMutex
+	webhooks []metadata.AdmissionWebhook
+	loadTime time.Time
+}
+
+// New create a new admission webhook manager instance
+func New(dbProxy dal.DB) core.AdmissionOperation {
+	return &admissionOperation{
+		dbProxy: dbProxy,
+		// the timeout of each call is controlled by the timeout of the webhook
+		client: &http.Client{},
+	}
+}
+
+// CreateAdmissionWebhook create an admission webhook, the name is unique for each supplier account
+func (a *admissionOperation) CreateAdmissionWebhook(kit *rest.Kit, webhook metadata.AdmissionWebhook) (
+	*metadata.AdmissionWebhook, errors.CCErrorCoder) {
+
+	if rawErr := webhook.Validate(); rawErr.ErrCode != 0 {
+		blog.Errorf("CreateAdmissionWebhook failed, webhook invalid, err: %+v, rid: %s", rawErr, kit.Rid)
+		return nil, rawErr.ToCCError(kit.CCError)
+	}
+	if err := a.checkNameUnique(kit, webhook.Name, 0); err != nil {
+		return nil, err
+	}
+	if err := a.checkObjectExist(kit, webhook.ObjID); err != nil {
+		return nil, err
+	}
+
+	id, err := a.dbProxy.NextSequence(kit.Ctx, common.BKTableNameAdmissionWebhook)
+	if err != nil {
+		blog.Errorf("CreateAdmissionWebhook failed, generate id failed, err: %v, rid: %s", err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommGenerateRecordIDFailed)
+	}
+
+	now := time.Now()
+	webhook.ID = int64(id)
+	webhook.Creator = kit.User
+	webhook.Modifier = kit.User
+	webhook.CreateTime = now
+	webhook.LastTime = now
+	webhook.OwnerID = kit.SupplierAccount
+	if err := a.dbProxy.Table(common.BKTableNameAdmissionWebhook).Insert(kit.Ctx, webhook); err != nil {
+		blog.Errorf("CreateAdmissionWebhook failed, db insert failed, name: %s, err: %v, rid: %s", webhook.Name, err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommDBInsertFailed)
+	}
+
+	a.invalidate()
+	return &webhook, nil
+}
+
+// UpdateAdmissionWebhook replace the admission webhook with the new settings
+func (a *admissionOperation) UpdateAdmissionWebhook(kit *rest.Kit, id int64, webhook metadata.AdmissionWebhook) (
+	*metadata.AdmissionWebhook, errors.CCErrorCoder) {
+
+	origin, err := a.getWebhook(kit, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if rawErr := webhook.Validate(); rawErr.ErrCode != 0 {
+		blog.Errorf("UpdateAdmissionWebhook failed, webhook invalid, err: %+v, rid: %s", rawErr, kit.Rid)
+		return nil, rawErr.ToCCError(kit.CCError)
+	}
+	if err := a.checkNameUnique(kit, webhook.Name, id); err != nil {
+		return nil, err
+	}
+	if err := a.checkObjectExist(kit, webhook.ObjID); err != nil {
+		return nil, err
+	}
+
+	webhook.ID = origin.ID
+	webhook.Creator = origin.Creator
+	webhook.CreateTime = origin.CreateTime
+	webhook.OwnerID = origin.OwnerID
+	webhook.Modifier = kit.User
+	webhook.LastTime = time.Now()
+
+	filter := util.SetModOwner(map[string]interface{}{common.BKFieldID: id}, kit.SupplierAccount)
+	if err := a.dbProxy.Table(common.BKTableNameAdmissionWebhook).Update(kit.Ctx, filter, webhook); err != nil {
+		blog.Errorf("UpdateAdmissionWebhook failed, db update failed, id: %d, err: %v, rid: %s", id, err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommDBUpdateFailed)
+	}
+
+	a.invalidate()
+	return &webhook, nil
+}
+
+// DeleteAdmissionWebhook delete the admission webhook, the decision logs are kept for auditing
+func (a *admissionOperation) DeleteAdmissionWebhook(kit *rest.Kit, id int64) errors.CCErrorCoder {
+	if _, err := a.getWebhook(kit, id); err != nil {
+		return err
+	}
+
+	filter := util.SetModOwner(map[string]interface{}{common.BKFieldID: id}, kit.SupplierAccount)
+	if err := a.dbProxy.Table(common.BKTableNameAdmissionWebhook).Delete(kit.Ctx, filter); err != nil {
+		blog.Errorf("DeleteAdmissionWebhook failed, db delete failed, id: %d, err: %v, rid: %s", id, err, kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommDBDeleteFailed)
+	}
+
+	a.invalidate()
+	return nil
+}
+
+// SearchAdmissionWebhooks search the admission webhooks of the supplier account
+func (a *admissionOperation) SearchAdmissionWebhooks(kit *rest.Kit, option metadata.SearchAdmissionWebhookOption) (
+	*metadata.SearchAdmissionWebhookResult, errors.CCErrorCoder) {
+
+	if key, err := option.Page.Validate(false); err != nil {
+		blog.Errorf("SearchAdmissionWebhooks failed, page invalid, key: %s, err: %v, rid: %s", key, err, kit.Rid)
+		return nil, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, key)
+	}
+	if err := metadata.ValidateQueryOperators(option.Condition); err != nil {
+		blog.Errorf("SearchAdmissionWebhooks failed, condition invalid, err: %v, rid: %s", err, kit.Rid)
+		return nil, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "condition")
+	}
+
+	filter := util.SetQueryOwner(map[string]interface{}(option.Condition), kit.SupplierAccount)
+	total, err := a.dbProxy.Table(common.BKTableNameAdmissionWebhook).Find(filter).Count(kit.Ctx)
+	if err != nil {
+		blog.ErrorJSON("SearchAdmissionWebhooks failed, db count failed, filter: %s, err: %s, rid: %s", filter, err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+
+	result := &metadata.SearchAdmissionWebhookResult{
+		Count: int64(total),
+		Info:  make([]metadata.AdmissionWebhook, 0),
+	}
+	sort := option.Page.Sort
+	if len(sort) == 0 {
+		sort = common.BKFieldID
+	}
+	query := a.dbProxy.Table(common.BKTableNameAdmissionWebhook).Find(filter).Start(uint64(option.Page.Start)).
+		Limit(uint64(option.Page.Limit)).Sort(sort)
+	if err := query.All(kit.Ctx, &result.Info); err != nil {
+		blog.ErrorJSON("SearchAdmissionWebhooks failed, db select failed, filter: %s, err: %s, rid: %s", filter, err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+	return result, nil
+}
+
+// SearchAdmissionWebhookLogs search the decision logs of the admission webhooks
+func (a *admissionOperation) SearchAdmissionWebhookLogs(kit *rest.Kit, option metadata.SearchAdmissionWebhookLogOption) (
+	*metadata.SearchAdmissionWebhookLogResult, errors.CCErrorCoder) {
+
+	if key, err := option.Page.Validate(false); err != nil {
+		blog.Errorf("SearchAdmissionWebhookLogs failed, page invalid, key: %s, err: %v, rid: %s", key, err, kit.Rid)
+		return nil, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, key)
+	}
+
+	filter := mapstr.MapStr{}
+	if option.WebhookID != 0 {
+		filter["webhook_id"] = option.WebhookID
+	}
+	if len(option.ObjID) != 0 {
+		filter[common.BKObjIDField] = option.ObjID
+	}
+	if len(option.Decision) != 0 {
+		filter["decision"] = option.Decision
+	}
+	if len(option.RequestID) != 0 {
+		filter["rid"] = option.RequestID
+	}
+	timeFilter := mapstr.MapStr{}
+	if option.StartTime != nil {
+		timeFilter[common.BKDBGTE] = *option.StartTime
+	}
+	if option.EndTime != nil {
+		timeFilter[common.BKDBLTE] = *option.EndTime
+	}
+	if len(timeFilter) != 0 {
+		filter[common.CreateTimeField] = timeFilter
+	}
+	filter = util.SetQueryOwner(filter, kit.SupplierAccount)
+
+	total, err := a.dbProxy.Table(common.BKTableNameAdmissionWebhookLog).Find(filter).Count(kit.Ctx)
+	if err != nil {
+		blog.ErrorJSON("SearchAdmissionWebhookLogs failed, db count failed, filter: %s, err: %s, rid: %s", filter, err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+
+	result := &metadata.SearchAdmissionWebhookLogResult{
+		Count: int64(total),
+		Info:  make([]metadata.AdmissionWebhookLog, 0),
+	}
+	sort := option.Page.Sort
+	if len(sort) == 0 {
+		sort = "-" + common.CreateTimeField
+	}
+	query := a.dbProxy.Table(common.BKTableNameAdmissionWebhookLog).Find(filter).Start(uint64(option.Page.Start)).
+		Limit(uint64(option.Page.Limit)).Sort(sort)
+	if err := query.All(kit.Ctx, &result.Info); err != nil {
+		blog.ErrorJSON("SearchAdmissionWebhookLogs failed, db select failed, filter: %s, err: %s, rid: %s", filter, err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+	return result, nil
+}
+
+func (a *admissionOperation) getWebhook(kit *rest.Kit, id int64) (*metadata.AdmissionWebhook, errors.CCErrorCoder) {
+	filter := util.SetQueryOwner(map[string]interface{}{common.BKFieldID: id}, kit.SupplierAccount)
+	webhooks := make([]metadata.AdmissionWebhook, 0)
+	if err := a.dbProxy.Table(common.BKTableNameAdmissionWebhook).Find(filter).All(kit.Ctx, &webhooks); err != nil {
+		blog.Errorf("get admission webhook failed, db select failed, id: %d, err: %v, rid: %s", id, err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+	if len(webhooks) == 0 {
+		blog.Errorf("get admission webhook failed, webhook %d not found, rid: %s", id, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommNotFound)
+	}
+	return &webhooks[0], nil
+}
+
+func (a *admissionOperation) checkNameUnique(kit *rest.Kit, name string, exceptID int64) errors.CCErrorCoder {
+	filter := map[string]interface{}{
+		common.BKFieldName: name,
+	}
+	if exceptID != 0 {
+		filter[common.BKFieldID] = map[string]interface{}{common.BKDBNE: exceptID}
+	}
+	filter = util.SetQueryOwner(filter, kit.SupplierAccount)
+	count, err := a.dbProxy.Table(common.BKTableNameAdmissionWebhook).Find(filter).Count(kit.Ctx)
+	if err != nil {
+		blog.Errorf("check admission webhook name failed, db count failed, filter: %+v, err: %v, rid: %s", filter, err, kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+	if count > 0 {
+		return kit.CCError.CCErrorf(common.CCErrCommDuplicateItem, common.BKFieldName)
+	}
+	return nil
+}
+
+func (a *admissionOperation) checkObjectExist(kit *rest.Kit, objID string) errors.CCErrorCoder {
+	filter := util.SetQueryOwner(map[string]interface{}{common.BKObjIDField: objID}, kit.SupplierAccount)
+	count, err := a.dbProxy.Table(common.BKTableNameObjDes).Find(filter).Count(kit.Ctx)
+	if err != nil {
+		blog.Errorf("check admission webhook object failed, db count failed, filter: %+v, err: %v, rid: %s", filter, err, kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+	if count == 0 {
+		return kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, common.BKObjIDField)
+	}
+	return nil
+}
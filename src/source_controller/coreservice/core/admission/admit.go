@@ -0,0 +1,274 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package admission
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+)
+
+// webhookReloadInterval the webhooks changed by other coreservice instances take effect after this interval
+const webhookReloadInterval = 30 * time.Second
+
+// maxWebhookResponseSize is the max bytes of the webhook response, the larger response is regarded as a failed call
+const maxWebhookResponseSize = 1 << 20
+
+// Admit reviews the write with the matched admission webhooks, returns the object patched by the mutating webhooks.
+// mutating webhooks are called one by one before validating webhooks, so that the validating webhooks review the
+// final object. the returned bool value is true if the object is patched.
+func (a *admissionOperation) Admit(kit *rest.Kit, objID string, operation metadata.AdmissionOperation,
+	object mapstr.MapStr, oldObjects []mapstr.MapStr) (mapstr.MapStr, bool, errors.CCErrorCoder) {
+
+	webhooks, err := a.matchWebhooks(kit, objID, operation)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(webhooks) == 0 {
+		return object, false, nil
+	}
+
+	request := &metadata.AdmissionReviewRequest{
+		UID:             kit.Rid,
+		ObjID:           objID,
+		Operation:       operation,
+		User:            kit.User,
+		SupplierAccount: kit.SupplierAccount,
+		Object:          object,
+		OldObjects:      oldObjects,
+	}
+
+	patched := false
+	for idx := range webhooks {
+		webhook := &webhooks[idx]
+		start := time.Now()
+		response, callErr := a.call(kit, webhook, request)
+		if callErr == nil && len(response.Patch) != 0 {
+			callErr = checkPatch(webhook, objID, response.Patch)
+		}
+
+		log := &metadata.AdmissionWebhookLog{
+			WebhookID:   webhook.ID,
+			WebhookName: webhook.Name,
+			ObjID:       objID,
+			Operation:   operation,
+			RequestID:   kit.Rid,
+			User:        kit.User,
+			Duration:    int64(time.Since(start) / time.Millisecond),
+			CreateTime:  time.Now(),
+			OwnerID:     kit.SupplierAccount,
+		}
+
+		switch {
+		case callErr != nil:
+			blog.Errorf("call admission webhook %s failed, failure policy: %s, err: %v, rid: %s", webhook.Name,
+				webhook.FailurePolicy, callErr, kit.Rid)
+			log.Decision = metadata.AdmissionDecisionFailed
+			log.Message = callErr.Error()
+			log.FailurePolicy = webhook.FailurePolicy
+			a.saveLog(kit, log)
+			if webhook.FailurePolicy == metadata.AdmissionFailurePolicyFail {
+				return nil, false, kit.CCError.CCErrorf(common.CCErrCoreServiceAdmissionWebhookFailed, webhook.Name,
+					callErr.Error())
+			}
+
+		case !response.Allowed:
+			blog.Infof("admission webhook %s denied the %s of %s, message: %s, rid: %s", webhook.Name, operation, objID,
+				response.Message, kit.Rid)
+			log.Decision = metadata.AdmissionDecisionDenied
+			log.Message = response.Message
+			a.saveLog(kit, log)
+			return nil, false, kit.CCError.CCErrorf(common.CCErrCoreServiceAdmissionDenied, webhook.Name,
+				response.Message)
+
+		default:
+			log.Decision = metadata.AdmissionDecisionAllowed
+			if len(response.Patch) != 0 {
+				// copy the object before patching so that the caller's data is not changed if the write is denied later
+				mutated := make(mapstr.MapStr, len(request.Object)+len(response.Patch))
+				for key, value := range request.Object {
+					mutated[key] = value
+				}
+				for key, value := range response.Patch {
+					mutated[key] = value
+				}
+				request.Object = mutated
+				log.Patched = true
+				patched = true
+			}
+			a.saveLog(kit, log)
+		}
+	}
+
+	return request.Object, patched, nil
+}
+
+// matchWebhooks get the webhooks to call, the mutating webhooks are in front, and then ordered by id
+func (a *admissionOperation) matchWebhooks(kit *rest.Kit, objID string, operation metadata.AdmissionOperation) (
+	[]metadata.AdmissionWebhook, errors.CCErrorCoder) {
+
+	all, err := a.getWebhooks(kit)
+	if err != nil {
+		return nil, err
+	}
+
+	matched := make([]metadata.AdmissionWebhook, 0)
+	for _, webhook := range all {
+		if webhook.OwnerID == kit.SupplierAccount && webhook.Match(objID, operation) {
+			matched = append(matched, webhook)
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		if matched[i].Type != matched[j].Type {
+			return matched[i].Type == metadata.AdmissionWebhookMutating
+		}
+		return matched[i].ID < matched[j].ID
+	})
+	return matched, nil
+}
+
+// getWebhooks get the enabled webhooks from cache, the cache is reloaded from db when expired
+func (a *admissionOperation) getWebhooks(kit *rest.Kit) ([]metadata.AdmissionWebhook, errors.CCErrorCoder) {
+	a.lock.RLock()
+	if time.Since(a.loadTime) < webhookReloadInterval {
+		webhooks := a.webhooks
+		a.lock.RUnlock()
+		return webhooks, nil
+	}
+	a.lock.RUnlock()
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if time.Since(a.loadTime) < webhookReloadInterval {
+		return a.webhooks, nil
+	}
+
+	// load without the transaction of the write, the webhooks are not changed by it.
+	webhooks := make([]metadata.AdmissionWebhook, 0)
+	filter := map[string]interface{}{"enabled": true}
+	err := a.dbProxy.Table(common.BKTableNameAdmissionWebhook).Find(filter).All(context.Background(), &webhooks)
+	if err != nil {
+		blog.Errorf("load admission webhooks failed, err: %v, rid: %s", err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+	a.webhooks = webhooks
+	a.loadTime = time.Now()
+	return webhooks, nil
+}
+
+// invalidate make the webhooks reloaded at the next review
+func (a *admissionOperation) invalidate() {
+	a.lock.Lock()
+	a.loadTime = time.Time{}
+	a.lock.Unlock()
+}
+
+// call post the admission review to the webhook and get its response
+func (a *admissionOperation) call(kit *rest.Kit, webhook *metadata.AdmissionWebhook,
+	request *metadata.AdmissionReviewRequest) (*metadata.AdmissionReviewResponse, error) {
+
+	body, err := json.Marshal(metadata.AdmissionReview{Request: request})
+	if err != nil {
+		return nil, fmt.Errorf("marshal admission review failed, err: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), webhook.GetTimeout())
+	defer cancel()
+	req, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(common.BKHTTPCCRequestID, kit.Rid)
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxWebhookResponseSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("read response failed, err: %v", err)
+	}
+	if len(data) > maxWebhookResponseSize {
+		return nil, fmt.Errorf("invalid response, response exceeds %d bytes", maxWebhookResponseSize)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	review := new(metadata.AdmissionReview)
+	if err := json.Unmarshal(data, review); err != nil {
+		return nil, fmt.Errorf("invalid response, err: %v", err)
+	}
+	if review.Response == nil {
+		return nil, fmt.Errorf("invalid response, response is not set")
+	}
+	return review.Response, nil
+}
+
+// protectedFields can not be patched by the mutating webhooks
+var protectedFields = []string{
+	common.BKObjIDField,
+	common.BKOwnerIDField,
+	common.CreatorField,
+	common.CreateTimeField,
+	common.LastTimeField,
+}
+
+// checkPatch checks if the patch of a mutating webhook can be applied
+func checkPatch(webhook *metadata.AdmissionWebhook, objID string, patch mapstr.MapStr) error {
+	if webhook.Type != metadata.AdmissionWebhookMutating {
+		return fmt.Errorf("invalid response, validating webhook can not patch the object")
+	}
+
+	if _, exists := patch[common.GetInstIDField(objID)]; exists {
+		return fmt.Errorf("invalid response, field %s can not be patched", common.GetInstIDField(objID))
+	}
+	for _, field := range protectedFields {
+		if _, exists := patch[field]; exists {
+			return fmt.Errorf("invalid response, field %s can not be patched", field)
+		}
+	}
+	return nil
+}
+
+// saveLog save the decision log, the log is saved out of the transaction of the write, so that the denied writes
+// which are rolled back are also recorded.
+func (a *admissionOperation) saveLog(kit *rest.Kit, log *metadata.AdmissionWebhookLog) {
+	ctx := context.Background()
+	id, err := a.dbProxy.NextSequence(ctx, common.BKTableNameAdmissionWebhookLog)
+	if err != nil {
+		blog.Errorf("save admission webhook log failed, generate id failed, err: %v, rid: %s", err, kit.Rid)
+		return
+	}
+	log.ID = int64(id)
+	if err := a.dbProxy.Table(common.BKTableNameAdmissionWebhookLog).Insert(ctx, log); err != nil {
+		blog.Errorf("save admission webhook log failed, webhook: %s, err: %v, rid: %s", log.WebhookName, err, kit.Rid)
+	}
+}
@@ -0,0 +1,127 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package admission
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+)
+
+func newTestOperation(webhooks []metadata.AdmissionWebhook) *admissionOperation {
+	return &admissionOperation{
+		client:   &http.Client{},
+		webhooks: webhooks,
+		loadTime: time.Now(),
+	}
+}
+
+func TestMatchWebhooks(t *testing.T) {
+	ops := []metadata.AdmissionOperation{metadata.AdmissionOperationCreate}
+	a := newTestOperation([]metadata.AdmissionWebhook{
+		{ID: 1, ObjID: "host", Operations: ops, Type: metadata.AdmissionWebhookValidating, Enabled: true, OwnerID: "0"},
+		{ID: 2, ObjID: "host", Operations: ops, Type: metadata.AdmissionWebhookMutating, Enabled: true, OwnerID: "0"},
+		{ID: 3, ObjID: "host", Operations: ops, Type: metadata.AdmissionWebhookMutating, Enabled: true, OwnerID: "1"},
+		{ID: 4, ObjID: "set", Operations: ops, Type: metadata.AdmissionWebhookValidating, Enabled: true, OwnerID: "0"},
+		{ID: 5, ObjID: "host", Operations: ops, Type: metadata.AdmissionWebhookMutating, Enabled: true, OwnerID: "0"},
+	})
+
+	kit := &rest.Kit{Rid: "test", SupplierAccount: "0"}
+	matched, err := a.matchWebhooks(kit, "host", metadata.AdmissionOperationCreate)
+	if err != nil {
+		t.Fatalf("match webhooks failed, err: %v", err)
+	}
+	expected := []int64{2, 5, 1}
+	if len(matched) != len(expected) {
+		t.Fatalf("expect %d webhooks, got %d", len(expected), len(matched))
+	}
+	for idx := range expected {
+		if matched[idx].ID != expected[idx] {
+			t.Errorf("webhook %d should be %d, got %d", idx, expected[idx], matched[idx].ID)
+		}
+	}
+
+	matched, _ = a.matchWebhooks(kit, "host", metadata.AdmissionOperationDelete)
+	if len(matched) != 0 {
+		t.Errorf("expect no webhook for delete, got %d", len(matched))
+	}
+}
+
+func TestCall(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		review := new(metadata.AdmissionReview)
+		if err := json.NewDecoder(r.Body).Decode(review); err != nil || review.Request == nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if r.URL.Path == "/slow" {
+			time.Sleep(2 * time.Second)
+		}
+		resp := &metadata.AdmissionReviewResponse{Allowed: review.Request.Object["bk_host_name"] != "forbidden"}
+		if r.URL.Path == "/large" {
+			resp.Message = strings.Repeat("x", maxWebhookResponseSize)
+		}
+		_ = json.NewEncoder(w).Encode(metadata.AdmissionReview{Response: resp})
+	}))
+	defer server.Close()
+
+	a := newTestOperation(nil)
+	kit := &rest.Kit{Rid: "test"}
+	webhook := &metadata.AdmissionWebhook{Name: "test", URL: server.URL}
+	request := &metadata.AdmissionReviewRequest{ObjID: "host", Object: mapstr.MapStr{"bk_host_name": "forbidden"}}
+
+	resp, err := a.call(kit, webhook, request)
+	if err != nil {
+		t.Fatalf("call webhook failed, err: %v", err)
+	}
+	if resp.Allowed {
+		t.Errorf("the request should be denied")
+	}
+
+	webhook.URL = server.URL + "/slow"
+	webhook.TimeoutSeconds = 1
+	if _, err := a.call(kit, webhook, request); err == nil {
+		t.Errorf("the call should be timeout")
+	}
+
+	webhook.URL = server.URL + "/large"
+	if _, err := a.call(kit, webhook, request); err == nil {
+		t.Errorf("the call with too large response should fail")
+	}
+}
+
+func TestCheckPatch(t *testing.T) {
+	mutating := &metadata.AdmissionWebhook{Type: metadata.AdmissionWebhookMutating}
+	if err := checkPatch(mutating, "host", mapstr.MapStr{"bk_comment": "patched"}); err != nil {
+		t.Errorf("patch should be allowed, err: %v", err)
+	}
+	if err := checkPatch(mutating, "host", mapstr.MapStr{common.BKHostIDField: 1}); err == nil {
+		t.Errorf("instance id field should not be patched")
+	}
+	if err := checkPatch(mutating, "host", mapstr.MapStr{common.BKOwnerIDField: "1"}); err == nil {
+		t.Errorf("supplier account should not be patched")
+	}
+
+	validating := &metadata.AdmissionWebhook{Type: metadata.AdmissionWebhookValidating}
+	if err := checkPatch(validating, "host", mapstr.MapStr{"bk_comment": "patched"}); err == nil {
+		t.Errorf("validating webhook should not patch the object")
+	}
+}
@@ -0,0 +1,372 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package approval
+
+import (
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/source_controller/coreservice/core"
+	"configcenter/src/storage/dal"
+)
+
+var _ core.ApprovalOperation = (*approvalOperation)(nil)
+
+type approvalOperation struct {
+	dbProxy dal.DB
+}
+
+// New create a new approval manager instance
+func New(dbProxy dal.DB) core.ApprovalOperation {
+	return &approvalOperation{
+		dbProxy: dbProxy,
+	}
+}
+
+// CreateApprovalPolicy create an approval policy, the name is unique for each supplier account
+func (a *approvalOperation) CreateApprovalPolicy(kit *rest.Kit, policy metadata.ApprovalPolicy) (
+	*metadata.ApprovalPolicy, errors.CCErrorCoder) {
+
+	if rawErr := policy.Validate(); rawErr.ErrCode != 0 {
+		blog.Errorf("CreateApprovalPolicy failed, policy invalid, err: %+v, rid: %s", rawErr, kit.Rid)
+		return nil, rawErr.ToCCError(kit.CCError)
+	}
+	if err := a.checkNameUnique(kit, policy.Name, 0); err != nil {
+		return nil, err
+	}
+
+	id, err := a.dbProxy.NextSequence(kit.Ctx, common.BKTableNameApprovalPolicy)
+	if err != nil {
+		blog.Errorf("CreateApprovalPolicy failed, generate id failed, err: %v, rid: %s", err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommGenerateRecordIDFailed)
+	}
+
+	now := time.Now()
+	policy.ID = int64(id)
+	policy.Creator = kit.User
+	policy.Modifier = kit.User
+	policy.CreateTime = now
+	policy.LastTime = now
+	policy.OwnerID = kit.SupplierAccount
+	if err := a.dbProxy.Table(common.BKTableNameApprovalPolicy).Insert(kit.Ctx, policy); err != nil {
+		blog.Errorf("CreateApprovalPolicy failed, db insert failed, name: %s, err: %v, rid: %s", policy.Name, err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommDBInsertFailed)
+	}
+	return &policy, nil
+}
+
+// UpdateApprovalPolicy replace the approval policy with the new settings, the pending requests are not affected
+func (a *approvalOperation) UpdateApprovalPolicy(kit *rest.Kit, id int64, policy metadata.ApprovalPolicy) (
+	*metadata.ApprovalPolicy, errors.CCErrorCoder) {
+
+	origin, err := a.getPolicy(kit, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if rawErr := policy.Validate(); rawErr.ErrCode != 0 {
+		blog.Errorf("UpdateApprovalPolicy failed, policy invalid, err: %+v, rid: %s", rawErr, kit.Rid)
+		return nil, rawErr.ToCCError(kit.CCError)
+	}
+	if err := a.checkNameUnique(kit, policy.Name, id); err != nil {
+		return nil, err
+	}
+
+	policy.ID = origin.ID
+	policy.Creator = origin.Creator
+	policy.CreateTime = origin.CreateTime
+	policy.OwnerID = origin.OwnerID
+	policy.Modifier = kit.User
+	policy.LastTime = time.Now()
+
+	filter := util.SetModOwner(map[string]interface{}{common.BKFieldID: id}, kit.SupplierAccount)
+	if err := a.dbProxy.Table(common.BKTableNameApprovalPolicy).Update(kit.Ctx, filter, policy); err != nil {
+		blog.Errorf("UpdateApprovalPolicy failed, db update failed, id: %d, err: %v, rid: %s", id, err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommDBUpdateFailed)
+	}
+	return &policy, nil
+}
+
+// DeleteApprovalPolicy delete the approval policy, the requests created by it are kept for auditing
+func (a *approvalOperation) DeleteApprovalPolicy(kit *rest.Kit, id int64) errors.CCErrorCoder {
+	if _, err := a.getPolicy(kit, id); err != nil {
+		return err
+	}
+
+	filter := util.SetModOwner(map[string]interface{}{common.BKFieldID: id}, kit.SupplierAccount)
+	if err := a.dbProxy.Table(common.BKTableNameApprovalPolicy).Delete(kit.Ctx, filter); err != nil {
+		blog.Errorf("DeleteApprovalPolicy failed, db delete failed, id: %d, err: %v, rid: %s", id, err, kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommDBDeleteFailed)
+	}
+	return nil
+}
+
+// SearchApprovalPolicies search the approval policies of the supplier account
+func (a *approvalOperation) SearchApprovalPolicies(kit *rest.Kit, option metadata.SearchApprovalPolicyOption) (
+	*metadata.SearchApprovalPolicyResult, errors.CCErrorCoder) {
+
+	if key, err := option.Page.Validate(false); err != nil {
+		blog.Errorf("SearchApprovalPolicies failed, page invalid, key: %s, err: %v, rid: %s", key, err, kit.Rid)
+		return nil, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, key)
+	}
+	if err := metadata.ValidateQueryOperators(option.Condition); err != nil {
+		blog.Errorf("SearchApprovalPolicies failed, condition invalid, err: %v, rid: %s", err, kit.Rid)
+		return nil, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "condition")
+	}
+
+	filter := util.SetQueryOwner(map[string]interface{}(option.Condition), kit.SupplierAccount)
+	total, err := a.dbProxy.Table(common.BKTableNameApprovalPolicy).Find(filter).Count(kit.Ctx)
+	if err != nil {
+		blog.ErrorJSON("SearchApprovalPolicies failed, db count failed, filter: %s, err: %s, rid: %s", filter, err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+
+	result := &metadata.SearchApprovalPolicyResult{
+		Count: int64(total),
+		Info:  make([]metadata.ApprovalPolicy, 0),
+	}
+	sort := option.Page.Sort
+	if len(sort) == 0 {
+		sort = common.BKFieldID
+	}
+	query := a.dbProxy.Table(common.BKTableNameApprovalPolicy).Find(filter).Start(uint64(option.Page.Start)).
+		Limit(uint64(option.Page.Limit)).Sort(sort)
+	if err := query.All(kit.Ctx, &result.Info); err != nil {
+		blog.ErrorJSON("SearchApprovalPolicies failed, db select failed, filter: %s, err: %s, rid: %s", filter, err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+	return result, nil
+}
+
+// CreateApprovalRequest create a pending approval request of the requester
+func (a *approvalOperation) CreateApprovalRequest(kit *rest.Kit, request metadata.ApprovalRequest) (
+	*metadata.ApprovalRequest, errors.CCErrorCoder) {
+
+	if !request.Operation.IsValid() {
+		blog.Errorf("CreateApprovalRequest failed, operation %s is invalid, rid: %s", request.Operation, kit.Rid)
+		return nil, kit.CCError.CCErrorf(common.CCErrCommParamsIsInvalid, "operation")
+	}
+	if len(request.Approvers) == 0 {
+		blog.Errorf("CreateApprovalRequest failed, approvers are not set, rid: %s", kit.Rid)
+		return nil, kit.CCError.CCErrorf(common.CCErrCommParamsNeedSet, "approvers")
+	}
+	if request.ExpireTime.IsZero() {
+		blog.Errorf("CreateApprovalRequest failed, expire time is not set, rid: %s", kit.Rid)
+		return nil, kit.CCError.CCErrorf(common.CCErrCommParamsNeedSet, "expire_time")
+	}
+
+	id, err := a.dbProxy.NextSequence(kit.Ctx, common.BKTableNameApprovalRequest)
+	if err != nil {
+		blog.Errorf("CreateApprovalRequest failed, generate id failed, err: %v, rid: %s", err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommGenerateRecordIDFailed)
+	}
+
+	now := time.Now()
+	request.ID = int64(id)
+	request.Status = metadata.ApprovalStatusPending
+	request.Requester = kit.User
+	request.Approver = ""
+	request.Comment = ""
+	request.Result = nil
+	request.CreateTime = now
+	request.LastTime = now
+	request.OwnerID = kit.SupplierAccount
+	if err := a.dbProxy.Table(common.BKTableNameApprovalRequest).Insert(kit.Ctx, request); err != nil {
+		blog.Errorf("CreateApprovalRequest failed, db insert failed, operation: %s, err: %v, rid: %s", request.Operation,
+			err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommDBInsertFailed)
+	}
+	return &request, nil
+}
+
+// GetApprovalRequest get the approval request by id
+func (a *approvalOperation) GetApprovalRequest(kit *rest.Kit, id int64) (*metadata.ApprovalRequest,
+	errors.CCErrorCoder) {
+
+	filter := util.SetQueryOwner(map[string]interface{}{common.BKFieldID: id}, kit.SupplierAccount)
+	requests := make([]metadata.ApprovalRequest, 0)
+	if err := a.dbProxy.Table(common.BKTableNameApprovalRequest).Find(filter).All(kit.Ctx, &requests); err != nil {
+		blog.Errorf("get approval request failed, db select failed, id: %d, err: %v, rid: %s", id, err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+	if len(requests) == 0 {
+		blog.Errorf("get approval request failed, request %d not found, rid: %s", id, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommNotFound)
+	}
+	return &requests[0], nil
+}
+
+// SearchApprovalRequests search the approval requests of the supplier account
+func (a *approvalOperation) SearchApprovalRequests(kit *rest.Kit, option metadata.SearchApprovalRequestOption) (
+	*metadata.SearchApprovalRequestResult, errors.CCErrorCoder) {
+
+	if key, err := option.Page.Validate(false); err != nil {
+		blog.Errorf("SearchApprovalRequests failed, page invalid, key: %s, err: %v, rid: %s", key, err, kit.Rid)
+		return nil, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, key)
+	}
+	if err := metadata.ValidateQueryOperators(option.Condition); err != nil {
+		blog.Errorf("SearchApprovalRequests failed, condition invalid, err: %v, rid: %s", err, kit.Rid)
+		return nil, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "condition")
+	}
+
+	filter := util.SetQueryOwner(map[string]interface{}(option.Condition), kit.SupplierAccount)
+	total, err := a.dbProxy.Table(common.BKTableNameApprovalRequest).Find(filter).Count(kit.Ctx)
+	if err != nil {
+		blog.ErrorJSON("SearchApprovalRequests failed, db count failed, filter: %s, err: %s, rid: %s", filter, err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+
+	result := &metadata.SearchApprovalRequestResult{
+		Count: int64(total),
+		Info:  make([]metadata.ApprovalRequest, 0),
+	}
+	sort := option.Page.Sort
+	if len(sort) == 0 {
+		sort = "-" + common.CreateTimeField
+	}
+	query := a.dbProxy.Table(common.BKTableNameApprovalRequest).Find(filter).Start(uint64(option.Page.Start)).
+		Limit(uint64(option.Page.Limit)).Sort(sort)
+	if err := query.All(kit.Ctx, &result.Info); err != nil {
+		blog.ErrorJSON("SearchApprovalRequests failed, db select failed, filter: %s, err: %s, rid: %s", filter, err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+	return result, nil
+}
+
+// UpdateApprovalRequestStatus change the status of the request if it's still in the from status, the request is
+// approved or rejected only once even if the approvers decide at the same time.
+func (a *approvalOperation) UpdateApprovalRequestStatus(kit *rest.Kit, id int64,
+	option metadata.UpdateApprovalStatusOption) (*metadata.ApprovalRequest, errors.CCErrorCoder) {
+
+	request, err := a.GetApprovalRequest(kit, id)
+	if err != nil {
+		return nil, err
+	}
+	if request.Status != option.From {
+		blog.Errorf("update approval request %d status failed, status is %s, not %s, rid: %s", id, request.Status,
+			option.From, kit.Rid)
+		return nil, kit.CCError.CCErrorf(common.CCErrCommApprovalRequestNotPending, id)
+	}
+
+	// the update time is used to check who wins the concurrent updates, the time is saved in milliseconds
+	now := time.Now()
+	doc := mapstr.MapStr{
+		"status":             option.To,
+		common.LastTimeField: now,
+	}
+	if len(option.Approver) != 0 {
+		doc["approver"] = option.Approver
+		doc["comment"] = option.Comment
+	}
+	if option.Result != nil {
+		doc["result"] = option.Result
+	}
+	filter := util.SetModOwner(map[string]interface{}{
+		common.BKFieldID:     id,
+		"status":             option.From,
+		common.LastTimeField: request.LastTime,
+	}, kit.SupplierAccount)
+	if err := a.dbProxy.Table(common.BKTableNameApprovalRequest).Update(kit.Ctx, filter, doc); err != nil {
+		blog.Errorf("update approval request %d status failed, db update failed, err: %v, rid: %s", id, err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommDBUpdateFailed)
+	}
+
+	updated, err := a.GetApprovalRequest(kit, id)
+	if err != nil {
+		return nil, err
+	}
+	if updated.Status != option.To || updated.LastTime.UnixNano()/int64(time.Millisecond) !=
+		now.UnixNano()/int64(time.Millisecond) {
+		blog.Errorf("update approval request %d status failed, it's changed by others, rid: %s", id, kit.Rid)
+		return nil, kit.CCError.CCErrorf(common.CCErrCommApprovalRequestNotPending, id)
+	}
+	return updated, nil
+}
+
+// ExpireApprovalRequests expire the pending requests of all supplier accounts which are not approved in time
+func (a *approvalOperation) ExpireApprovalRequests(kit *rest.Kit) ([]metadata.ApprovalRequest,
+	errors.CCErrorCoder) {
+
+	now := time.Now()
+	filter := map[string]interface{}{
+		"status":      metadata.ApprovalStatusPending,
+		"expire_time": map[string]interface{}{common.BKDBLT: now},
+	}
+	requests := make([]metadata.ApprovalRequest, 0)
+	if err := a.dbProxy.Table(common.BKTableNameApprovalRequest).Find(filter).All(kit.Ctx, &requests); err != nil {
+		blog.Errorf("expire approval requests failed, db select failed, err: %v, rid: %s", err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+	if len(requests) == 0 {
+		return requests, nil
+	}
+
+	ids := make([]int64, len(requests))
+	for index, request := range requests {
+		ids[index] = request.ID
+	}
+	filter[common.BKFieldID] = map[string]interface{}{common.BKDBIN: ids}
+	doc := mapstr.MapStr{
+		"status":             metadata.ApprovalStatusExpired,
+		common.LastTimeField: now,
+	}
+	if err := a.dbProxy.Table(common.BKTableNameApprovalRequest).Update(kit.Ctx, filter, doc); err != nil {
+		blog.Errorf("expire approval requests failed, db update failed, ids: %v, err: %v, rid: %s", ids, err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommDBUpdateFailed)
+	}
+
+	for index := range requests {
+		requests[index].Status = metadata.ApprovalStatusExpired
+		requests[index].LastTime = now
+	}
+	return requests, nil
+}
+
+func (a *approvalOperation) getPolicy(kit *rest.Kit, id int64) (*metadata.ApprovalPolicy, errors.CCErrorCoder) {
+	filter := util.SetQueryOwner(map[string]interface{}{common.BKFieldID: id}, kit.SupplierAccount)
+	policies := make([]metadata.ApprovalPolicy, 0)
+	if err := a.dbProxy.Table(common.BKTableNameApprovalPolicy).Find(filter).All(kit.Ctx, &policies); err != nil {
+		blog.Errorf("get approval policy failed, db select failed, id: %d, err: %v, rid: %s", id, err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+	if len(policies) == 0 {
+		blog.Errorf("get approval policy failed, policy %d not found, rid: %s", id, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommNotFound)
+	}
+	return &policies[0], nil
+}
+
+func (a *approvalOperation) checkNameUnique(kit *rest.Kit, name string, exceptID int64) errors.CCErrorCoder {
+	filter := map[string]interface{}{
+		common.BKFieldName: name,
+	}
+	if exceptID != 0 {
+		filter[common.BKFieldID] = map[string]interface{}{common.BKDBNE: exceptID}
+	}
+	filter = util.SetQueryOwner(filter, kit.SupplierAccount)
+	count, err := a.dbProxy.Table(common.BKTableNameApprovalPolicy).Find(filter).Count(kit.Ctx)
+	if err != nil {
+		blog.Errorf("check approval policy name failed, db count failed, filter: %+v, err: %v, rid: %s", filter, err, kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+	if count > 0 {
+		return kit.CCError.CCErrorf(common.CCErrCommDuplicateItem, common.BKFieldName)
+	}
+	return nil
+}
@@ -232,8 +232,36 @@ func (m *associationInstance) CreateManyInstanceAssociation(kit *rest.Kit, input
 }
 
 func (m *associationInstance) SearchInstanceAssociation(kit *rest.Kit, inputParam metadata.QueryCondition) (*metadata.QueryResult, error) {
+	if err := metadata.ValidateQueryOperators(inputParam.Condition); err != nil {
+		blog.Errorf("search inst association with invalid condition, err: %v, rid: %s", err, kit.Rid)
+		return &metadata.QueryResult{}, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "condition")
+	}
+	cond, key, err := metadata.MergeQueryFilter(inputParam.Condition, inputParam.Filter,
+		metadata.InstAsstFilterFieldTypes())
+	if err != nil {
+		blog.Errorf("search inst association with invalid filter, key: %s, err: %v, rid: %s", key, err, kit.Rid)
+		return &metadata.QueryResult{}, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, key)
+	}
+	inputParam.Condition = cond
 	inputParam.Condition = util.SetQueryOwner(inputParam.Condition, kit.SupplierAccount)
-	instAsstItems, err := m.searchInstanceAssociation(kit, inputParam)
+
+	findParam := inputParam
+	if inputParam.Page.IsCursorPage() {
+		if key, err := inputParam.Page.Validate(false); err != nil {
+			blog.Errorf("invalid cursor page: %+v, err: %v, rid: %s", inputParam.Page, err, kit.Rid)
+			return &metadata.QueryResult{}, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "page."+key)
+		}
+		cursor, err := inputParam.Page.ParseCursor(common.BKFieldID)
+		if err != nil {
+			blog.Errorf("parse page cursor failed, page: %+v, err: %v, rid: %s", inputParam.Page, err, kit.Rid)
+			return &metadata.QueryResult{}, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "page.cursor")
+		}
+		findParam.Condition = cursor.MergeCondition(inputParam.Condition)
+		findParam.Page.Sort = cursor.Sort
+		findParam.Fields = cursor.Fields(inputParam.Fields)
+	}
+
+	instAsstItems, err := m.searchInstanceAssociation(kit, findParam)
 	if nil != err {
 		blog.Errorf("search inst association array err [%#v], rid: %s", err, kit.Rid)
 		return &metadata.QueryResult{}, err
@@ -250,6 +278,15 @@ func (m *associationInstance) SearchInstanceAssociation(kit *rest.Kit, inputPara
 		dataResult.Info = append(dataResult.Info, mapstr.NewFromStruct(item, "field"))
 	}
 
+	if len(dataResult.Info) > 0 {
+		last := dataResult.Info[len(dataResult.Info)-1]
+		dataResult.NextCursor, err = inputParam.Page.NextCursor(common.BKFieldID, len(dataResult.Info), last)
+		if err != nil {
+			blog.Errorf("generate next cursor failed, err: %v, rid: %s", err, kit.Rid)
+			return &metadata.QueryResult{}, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "page.sort")
+		}
+	}
+
 	return dataResult, nil
 }
 
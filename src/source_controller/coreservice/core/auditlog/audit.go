@@ -67,7 +67,7 @@ func (m *auditManager) CreateAuditLog(kit *rest.Kit, logs ...metadata.AuditLog)
 	return mongodb.Client().Table(common.BKTableNameAuditLog).Insert(kit.Ctx, logRows)
 }
 
-func (m *auditManager) SearchAuditLog(kit *rest.Kit, param metadata.QueryCondition) ([]metadata.AuditLog, uint64, error) {
+func (m *auditManager) SearchAuditLog(kit *rest.Kit, param metadata.QueryCondition) ([]metadata.AuditLog, uint64, string, error) {
 	condition := param.Condition
 	condition = util.SetQueryOwner(condition, kit.SupplierAccount)
 
@@ -76,20 +76,20 @@ func (m *auditManager) SearchAuditLog(kit *rest.Kit, param metadata.QueryConditi
 		timeCond, err := condition.MapStr(common.BKOperationTimeField)
 		if err != nil {
 			blog.Errorf("parse operation time condition failed, error: %s, rid: %s", err, kit.Rid)
-			return nil, 0, err
+			return nil, 0, "", err
 		}
 
 		for key, value := range timeCond {
 			timeVal, ok := value.(string)
 			if !ok {
 				blog.Errorf("parse operation time failed, time(%v) is not string type, rid: %s", value, kit.Rid)
-				return nil, 0, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKOperationTimeField)
+				return nil, 0, "", kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKOperationTimeField)
 			}
 
 			t, err := timeparser.TimeParserInLocation(timeVal, time.Local)
 			if nil != err {
 				blog.Errorf("parse operation time failed, error: %s, time: %s, rid: %s", err, timeVal, kit.Rid)
-				return nil, 0, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKOperationTimeField)
+				return nil, 0, "", kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKOperationTimeField)
 			}
 			timeCond[key] = t.Local()
 		}
@@ -97,19 +97,42 @@ func (m *auditManager) SearchAuditLog(kit *rest.Kit, param metadata.QueryConditi
 
 	blog.V(5).Infof("Search table common.BKTableNameAuditLog with parameters: %+v, rid: %s", condition, kit.Rid)
 
+	findCond, sort, fields := condition, param.Page.Sort, param.Fields
+	if param.Page.IsCursorPage() {
+		if key, err := param.Page.Validate(false); err != nil {
+			blog.Errorf("invalid cursor page: %+v, err: %v, rid: %s", param.Page, err, kit.Rid)
+			return nil, 0, "", kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "page."+key)
+		}
+		cursor, err := param.Page.ParseCursor(common.BKFieldID)
+		if err != nil {
+			blog.Errorf("parse page cursor failed, page: %+v, err: %v, rid: %s", param.Page, err, kit.Rid)
+			return nil, 0, "", kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "page.cursor")
+		}
+		findCond, sort, fields = cursor.MergeCondition(condition), cursor.Sort, cursor.Fields(fields)
+	}
+
 	rows := make([]metadata.AuditLog, 0)
-	err := mongodb.Client().Table(common.BKTableNameAuditLog).Find(condition).Sort(param.Page.Sort).Fields(param.
-		Fields...).Start(uint64(param.Page.Start)).Limit(uint64(param.Page.Limit)).All(kit.Ctx, &rows)
+	err := mongodb.Client().Table(common.BKTableNameAuditLog).Find(findCond).Sort(sort).Fields(fields...).
+		Start(uint64(param.Page.Start)).Limit(uint64(param.Page.Limit)).All(kit.Ctx, &rows)
 	if nil != err {
 
 		blog.Errorf("query database error:%s, condition:%v, rid: %s", err.Error(), condition, kit.Rid)
-		return nil, 0, err
+		return nil, 0, "", err
 	}
 	cnt, err := mongodb.Client().Table(common.BKTableNameAuditLog).Find(condition).Count(kit.Ctx)
 	if nil != err {
 		blog.Errorf("query database error:%s, condition:%v, rid: %s", err.Error(), condition, kit.Rid)
-		return nil, 0, err
+		return nil, 0, "", err
+	}
+
+	nextCursor := ""
+	if len(rows) > 0 {
+		nextCursor, err = param.Page.NextCursor(common.BKFieldID, len(rows), rows[len(rows)-1].CursorValues())
+		if err != nil {
+			blog.Errorf("generate next cursor failed, err: %v, rid: %s", err, kit.Rid)
+			return nil, 0, "", kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "page.sort")
+		}
 	}
 
-	return rows, cnt, nil
+	return rows, cnt, nextCursor, nil
 }
@@ -0,0 +1,140 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package auth
+
+import (
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+)
+
+// CreateAPIToken save the api token, the token name is unique for each creator
+func (a *authOperation) CreateAPIToken(kit *rest.Kit, option metadata.SaveAPITokenOption) (*metadata.APIToken, errors.CCErrorCoder) {
+	token := option.Token
+	if len(option.TokenHash) == 0 {
+		return nil, kit.CCError.CCErrorf(common.CCErrCommParamsNeedSet, "token_hash")
+	}
+	token.TokenHash = option.TokenHash
+
+	filter := map[string]interface{}{
+		common.CreatorField: kit.User,
+		"name":              token.Name,
+	}
+	filter = util.SetQueryOwner(filter, kit.SupplierAccount)
+	count, err := a.dbProxy.Table(common.BKTableNameAPIToken).Find(filter).Count(kit.Ctx)
+	if err != nil {
+		blog.Errorf("CreateAPIToken failed, db count failed, filter: %+v, err: %v, rid: %s", filter, err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+	if count > 0 {
+		return nil, kit.CCError.CCErrorf(common.CCErrCommDuplicateItem, "name")
+	}
+
+	id, err := a.dbProxy.NextSequence(kit.Ctx, common.BKTableNameAPIToken)
+	if err != nil {
+		blog.Errorf("CreateAPIToken failed, generate id failed, err: %v, rid: %s", err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommGenerateRecordIDFailed)
+	}
+
+	now := time.Now()
+	token.ID = int64(id)
+	token.Revoked = false
+	token.RevokeTime = nil
+	token.LastUsedTime = nil
+	token.Creator = kit.User
+	token.CreateTime = now
+	token.LastTime = now
+	token.SupplierAccount = kit.SupplierAccount
+	if err := a.dbProxy.Table(common.BKTableNameAPIToken).Insert(kit.Ctx, token); err != nil {
+		blog.Errorf("CreateAPIToken failed, db insert failed, name: %s, err: %v, rid: %s", token.Name, err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommDBInsertFailed)
+	}
+	return &token, nil
+}
+
+// ListAPITokens search the api tokens of the supplier account
+func (a *authOperation) ListAPITokens(kit *rest.Kit, option metadata.ListAPITokenOption) (*metadata.MultipleAPIToken, errors.CCErrorCoder) {
+	if key, err := option.Page.Validate(false); err != nil {
+		blog.Errorf("ListAPITokens failed, page invalid, key: %s, err: %v, rid: %s", key, err, kit.Rid)
+		return nil, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, key)
+	}
+
+	filter := option.ToFilter()
+	filter = util.SetQueryOwner(filter, kit.SupplierAccount)
+	total, err := a.dbProxy.Table(common.BKTableNameAPIToken).Find(filter).Count(kit.Ctx)
+	if err != nil {
+		blog.ErrorJSON("ListAPITokens failed, db count failed, filter: %s, err: %s, rid: %s", filter, err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+
+	result := &metadata.MultipleAPIToken{
+		Count: int64(total),
+		Info:  make([]metadata.APIToken, 0),
+	}
+	query := a.dbProxy.Table(common.BKTableNameAPIToken).Find(filter)
+	if option.Page.Start != 0 {
+		query = query.Start(uint64(option.Page.Start))
+	}
+	if option.Page.Limit != 0 {
+		query = query.Limit(uint64(option.Page.Limit))
+	}
+	if len(option.Page.Sort) != 0 {
+		query = query.Sort(option.Page.Sort)
+	}
+	if err := query.All(kit.Ctx, &result.Info); err != nil {
+		blog.ErrorJSON("ListAPITokens failed, db select failed, filter: %s, err: %s, rid: %s", filter, err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+	return result, nil
+}
+
+// RevokeAPIToken revoke the api token, the revoked token is kept for auditing
+func (a *authOperation) RevokeAPIToken(kit *rest.Kit, id int64) errors.CCErrorCoder {
+	filter := map[string]interface{}{
+		common.BKFieldID: id,
+	}
+	filter = util.SetModOwner(filter, kit.SupplierAccount)
+	now := time.Now()
+	doc := map[string]interface{}{
+		"revoked":            true,
+		"revoke_time":        now,
+		common.LastTimeField: now,
+	}
+	if err := a.dbProxy.Table(common.BKTableNameAPIToken).Update(kit.Ctx, filter, doc); err != nil {
+		blog.Errorf("RevokeAPIToken failed, db update failed, id: %d, err: %v, rid: %s", id, err, kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommDBUpdateFailed)
+	}
+	return nil
+}
+
+// UpdateAPITokenLastUsed record the time and address the api token is used at
+func (a *authOperation) UpdateAPITokenLastUsed(kit *rest.Kit, id int64, option metadata.UpdateAPITokenLastUsedOption) errors.CCErrorCoder {
+	filter := map[string]interface{}{
+		common.BKFieldID: id,
+	}
+	filter = util.SetModOwner(filter, kit.SupplierAccount)
+	doc := map[string]interface{}{
+		"last_used_time": option.LastUsedTime,
+		"last_used_ip":   option.LastUsedIP,
+	}
+	if err := a.dbProxy.Table(common.BKTableNameAPIToken).Update(kit.Ctx, filter, doc); err != nil {
+		blog.Errorf("UpdateAPITokenLastUsed failed, db update failed, id: %d, err: %v, rid: %s", id, err, kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommDBUpdateFailed)
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package changefreeze
+
+import (
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/source_controller/coreservice/core"
+	"configcenter/src/storage/dal"
+)
+
+var _ core.ChangeFreezeOperation = (*changeFreezeOperation)(nil)
+
+type changeFreezeOperation struct {
+	dbProxy dal.DB
+}
+
+// New create a new change freeze window manager instance
+func New(dbProxy dal.DB) core.ChangeFreezeOperation {
+	return &changeFreezeOperation{
+		dbProxy: dbProxy,
+	}
+}
+
+// CreateChangeFreezeWindow create a change freeze window, the name is unique for each supplier account
+func (c *changeFreezeOperation) CreateChangeFreezeWindow(kit *rest.Kit, window metadata.ChangeFreezeWindow) (
+	*metadata.ChangeFreezeWindow, errors.CCErrorCoder) {
+
+	if rawErr := window.Validate(); rawErr.ErrCode != 0 {
+		blog.Errorf("CreateChangeFreezeWindow failed, window invalid, err: %+v, rid: %s", rawErr, kit.Rid)
+		return nil, rawErr.ToCCError(kit.CCError)
+	}
+	if err := c.checkNameUnique(kit, window.Name, 0); err != nil {
+		return nil, err
+	}
+
+	id, err := c.dbProxy.NextSequence(kit.Ctx, common.BKTableNameChangeFreezeWindow)
+	if err != nil {
+		blog.Errorf("CreateChangeFreezeWindow failed, generate id failed, err: %v, rid: %s", err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommGenerateRecordIDFailed)
+	}
+
+	now := time.Now()
+	window.ID = int64(id)
+	window.Creator = kit.User
+	window.Modifier = kit.User
+	window.CreateTime = now
+	window.LastTime = now
+	window.OwnerID = kit.SupplierAccount
+	if err := c.dbProxy.Table(common.BKTableNameChangeFreezeWindow).Insert(kit.Ctx, window); err != nil {
+		blog.Errorf("CreateChangeFreezeWindow failed, db insert failed, name: %s, err: %v, rid: %s", window.Name, err,
+			kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommDBInsertFailed)
+	}
+	return &window, nil
+}
+
+// UpdateChangeFreezeWindow replace the change freeze window with the new settings
+func (c *changeFreezeOperation) UpdateChangeFreezeWindow(kit *rest.Kit, id int64,
+	window metadata.ChangeFreezeWindow) (*metadata.ChangeFreezeWindow, errors.CCErrorCoder) {
+
+	origin, err := c.GetChangeFreezeWindow(kit, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if rawErr := window.Validate(); rawErr.ErrCode != 0 {
+		blog.Errorf("UpdateChangeFreezeWindow failed, window invalid, err: %+v, rid: %s", rawErr, kit.Rid)
+		return nil, rawErr.ToCCError(kit.CCError)
+	}
+	if err := c.checkNameUnique(kit, window.Name, id); err != nil {
+		return nil, err
+	}
+
+	window.ID = origin.ID
+	window.Creator = origin.Creator
+	window.CreateTime = origin.CreateTime
+	window.OwnerID = origin.OwnerID
+	window.Modifier = kit.User
+	window.LastTime = time.Now()
+
+	filter := util.SetModOwner(map[string]interface{}{common.BKFieldID: id}, kit.SupplierAccount)
+	if err := c.dbProxy.Table(common.BKTableNameChangeFreezeWindow).Update(kit.Ctx, filter, window); err != nil {
+		blog.Errorf("UpdateChangeFreezeWindow failed, db update failed, id: %d, err: %v, rid: %s", id, err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommDBUpdateFailed)
+	}
+	return &window, nil
+}
+
+// DeleteChangeFreezeWindow delete the change freeze window
+func (c *changeFreezeOperation) DeleteChangeFreezeWindow(kit *rest.Kit, id int64) errors.CCErrorCoder {
+	if _, err := c.GetChangeFreezeWindow(kit, id); err != nil {
+		return err
+	}
+
+	filter := util.SetModOwner(map[string]interface{}{common.BKFieldID: id}, kit.SupplierAccount)
+	if err := c.dbProxy.Table(common.BKTableNameChangeFreezeWindow).Delete(kit.Ctx, filter); err != nil {
+		blog.Errorf("DeleteChangeFreezeWindow failed, db delete failed, id: %d, err: %v, rid: %s", id, err, kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommDBDeleteFailed)
+	}
+	return nil
+}
+
+// GetChangeFreezeWindow get the change freeze window by id
+func (c *changeFreezeOperation) GetChangeFreezeWindow(kit *rest.Kit, id int64) (*metadata.ChangeFreezeWindow,
+	errors.CCErrorCoder) {
+
+	filter := util.SetQueryOwner(map[string]interface{}{common.BKFieldID: id}, kit.SupplierAccount)
+	windows := make([]metadata.ChangeFreezeWindow, 0)
+	if err := c.dbProxy.Table(common.BKTableNameChangeFreezeWindow).Find(filter).All(kit.Ctx, &windows); err != nil {
+		blog.Errorf("get change freeze window failed, db select failed, id: %d, err: %v, rid: %s", id, err, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+	if len(windows) == 0 {
+		blog.Errorf("get change freeze window failed, window %d not found, rid: %s", id, kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommNotFound)
+	}
+	return &windows[0], nil
+}
+
+// SearchChangeFreezeWindows search the change freeze windows of the supplier account, the windows of all the
+// supplier accounts are returned for the super supplier account
+func (c *changeFreezeOperation) SearchChangeFreezeWindows(kit *rest.Kit,
+	option metadata.SearchChangeFreezeWindowOption) (*metadata.SearchChangeFreezeWindowResult, errors.CCErrorCoder) {
+
+	if key, err := option.Page.Validate(false); err != nil {
+		blog.Errorf("SearchChangeFreezeWindows failed, page invalid, key: %s, err: %v, rid: %s", key, err, kit.Rid)
+		return nil, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, key)
+	}
+	if err := metadata.ValidateQueryOperators(option.Condition); err != nil {
+		blog.Errorf("SearchChangeFreezeWindows failed, condition invalid, err: %v, rid: %s", err, kit.Rid)
+		return nil, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "condition")
+	}
+
+	filter := util.SetQueryOwner(map[string]interface{}(option.Condition), kit.SupplierAccount)
+	total, err := c.dbProxy.Table(common.BKTableNameChangeFreezeWindow).Find(filter).Count(kit.Ctx)
+	if err != nil {
+		blog.ErrorJSON("SearchChangeFreezeWindows failed, db count failed, filter: %s, err: %s, rid: %s", filter, err,
+			kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+
+	result := &metadata.SearchChangeFreezeWindowResult{
+		Count: int64(total),
+		Info:  make([]metadata.ChangeFreezeWindow, 0),
+	}
+	sort := option.Page.Sort
+	if len(sort) == 0 {
+		sort = common.BKFieldID
+	}
+	query := c.dbProxy.Table(common.BKTableNameChangeFreezeWindow).Find(filter).Start(uint64(option.Page.Start)).
+		Limit(uint64(option.Page.Limit)).Sort(sort)
+	if err := query.All(kit.Ctx, &result.Info); err != nil {
+		blog.ErrorJSON("SearchChangeFreezeWindows failed, db select failed, filter: %s, err: %s, rid: %s", filter, err,
+			kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+	return result, nil
+}
+
+func (c *changeFreezeOperation) checkNameUnique(kit *rest.Kit, name string, exceptID int64) errors.CCErrorCoder {
+	filter := map[string]interface{}{
+		common.BKFieldName: name,
+	}
+	if exceptID != 0 {
+		filter[common.BKFieldID] = map[string]interface{}{common.BKDBNE: exceptID}
+	}
+	filter = util.SetQueryOwner(filter, kit.SupplierAccount)
+	count, err := c.dbProxy.Table(common.BKTableNameChangeFreezeWindow).Find(filter).Count(kit.Ctx)
+	if err != nil {
+		blog.Errorf("check change freeze window name failed, db count failed, filter: %+v, err: %v, rid: %s", filter,
+			err, kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+	if count > 0 {
+		return kit.CCError.CCErrorf(common.CCErrCommDuplicateItem, common.BKFieldName)
+	}
+	return nil
+}
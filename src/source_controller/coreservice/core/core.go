@@ -173,7 +173,7 @@ type AssociationOperation interface {
 
 type AuditOperation interface {
 	CreateAuditLog(kit *rest.Kit, logs ...metadata.AuditLog) error
-	SearchAuditLog(kit *rest.Kit, param metadata.QueryCondition) ([]metadata.AuditLog, uint64, error)
+	SearchAuditLog(kit *rest.Kit, param metadata.QueryCondition) ([]metadata.AuditLog, uint64, string, error)
 }
 
 type StatisticOperation interface {
@@ -207,6 +207,9 @@ type Core interface {
 	AuthOperation() AuthOperation
 	EventOperation() EventOperation
 	CommonOperation() CommonOperation
+	AdmissionOperation() AdmissionOperation
+	ApprovalOperation() ApprovalOperation
+	ChangeFreezeOperation() ChangeFreezeOperation
 }
 
 // ProcessOperation methods
@@ -275,6 +278,9 @@ type SetTemplateOperation interface {
 	ListSetTemplateSyncHistory(kit *rest.Kit, option metadata.ListSetTemplateSyncStatusOption) (metadata.MultipleSetTemplateSyncStatus, errors.CCErrorCoder)
 	DeleteSetTemplateSyncStatus(kit *rest.Kit, option metadata.DeleteSetTemplateSyncStatusOption) errors.CCErrorCoder
 	ModifySetTemplateSyncStatus(kit *rest.Kit, setID int64, sysncStatus metadata.SyncStatus) errors.CCErrorCoder
+	UpdateSetTemplateSyncPolicy(kit *rest.Kit, bizID int64, setTemplateID int64, option metadata.UpdateSetTemplateSyncPolicyOption) (metadata.SetTemplateSyncPolicy, errors.CCErrorCoder)
+	ListSetTemplateSyncPolicy(kit *rest.Kit, option metadata.ListSetTemplateSyncPolicyOption) (metadata.MultipleSetTemplateSyncPolicy, errors.CCErrorCoder)
+	UpdateSetTemplateSyncResult(kit *rest.Kit, bizID int64, setTemplateID int64, option metadata.UpdateSetTemplateSyncResultOption) errors.CCErrorCoder
 }
 
 type HostApplyRuleOperation interface {
@@ -312,6 +318,10 @@ type SystemOperation interface {
 
 type AuthOperation interface {
 	SearchAuthResource(kit *rest.Kit, param metadata.PullResourceParam) (int64, []map[string]interface{}, errors.CCErrorCoder)
+	CreateAPIToken(kit *rest.Kit, option metadata.SaveAPITokenOption) (*metadata.APIToken, errors.CCErrorCoder)
+	ListAPITokens(kit *rest.Kit, option metadata.ListAPITokenOption) (*metadata.MultipleAPIToken, errors.CCErrorCoder)
+	RevokeAPIToken(kit *rest.Kit, id int64) errors.CCErrorCoder
+	UpdateAPITokenLastUsed(kit *rest.Kit, id int64, option metadata.UpdateAPITokenLastUsedOption) errors.CCErrorCoder
 }
 
 type EventOperation interface {
@@ -325,6 +335,39 @@ type CommonOperation interface {
 	GetDistinctField(kit *rest.Kit, param *metadata.DistinctFieldOption) ([]interface{}, errors.CCErrorCoder)
 }
 
+// AdmissionOperation manage the admission webhooks and review the writes with them
+type AdmissionOperation interface {
+	CreateAdmissionWebhook(kit *rest.Kit, webhook metadata.AdmissionWebhook) (*metadata.AdmissionWebhook, errors.CCErrorCoder)
+	UpdateAdmissionWebhook(kit *rest.Kit, id int64, webhook metadata.AdmissionWebhook) (*metadata.AdmissionWebhook, errors.CCErrorCoder)
+	DeleteAdmissionWebhook(kit *rest.Kit, id int64) errors.CCErrorCoder
+	SearchAdmissionWebhooks(kit *rest.Kit, option metadata.SearchAdmissionWebhookOption) (*metadata.SearchAdmissionWebhookResult, errors.CCErrorCoder)
+	SearchAdmissionWebhookLogs(kit *rest.Kit, option metadata.SearchAdmissionWebhookLogOption) (*metadata.SearchAdmissionWebhookLogResult, errors.CCErrorCoder)
+	Admit(kit *rest.Kit, objID string, operation metadata.AdmissionOperation, object mapstr.MapStr,
+		oldObjects []mapstr.MapStr) (mapstr.MapStr, bool, errors.CCErrorCoder)
+}
+
+// ApprovalOperation manage the approval policies and the approval requests of the sensitive operations
+type ApprovalOperation interface {
+	CreateApprovalPolicy(kit *rest.Kit, policy metadata.ApprovalPolicy) (*metadata.ApprovalPolicy, errors.CCErrorCoder)
+	UpdateApprovalPolicy(kit *rest.Kit, id int64, policy metadata.ApprovalPolicy) (*metadata.ApprovalPolicy, errors.CCErrorCoder)
+	DeleteApprovalPolicy(kit *rest.Kit, id int64) errors.CCErrorCoder
+	SearchApprovalPolicies(kit *rest.Kit, option metadata.SearchApprovalPolicyOption) (*metadata.SearchApprovalPolicyResult, errors.CCErrorCoder)
+	CreateApprovalRequest(kit *rest.Kit, request metadata.ApprovalRequest) (*metadata.ApprovalRequest, errors.CCErrorCoder)
+	GetApprovalRequest(kit *rest.Kit, id int64) (*metadata.ApprovalRequest, errors.CCErrorCoder)
+	SearchApprovalRequests(kit *rest.Kit, option metadata.SearchApprovalRequestOption) (*metadata.SearchApprovalRequestResult, errors.CCErrorCoder)
+	UpdateApprovalRequestStatus(kit *rest.Kit, id int64, option metadata.UpdateApprovalStatusOption) (*metadata.ApprovalRequest, errors.CCErrorCoder)
+	ExpireApprovalRequests(kit *rest.Kit) ([]metadata.ApprovalRequest, errors.CCErrorCoder)
+}
+
+// ChangeFreezeOperation manage the change freeze windows of the businesses
+type ChangeFreezeOperation interface {
+	CreateChangeFreezeWindow(kit *rest.Kit, window metadata.ChangeFreezeWindow) (*metadata.ChangeFreezeWindow, errors.CCErrorCoder)
+	UpdateChangeFreezeWindow(kit *rest.Kit, id int64, window metadata.ChangeFreezeWindow) (*metadata.ChangeFreezeWindow, errors.CCErrorCoder)
+	DeleteChangeFreezeWindow(kit *rest.Kit, id int64) errors.CCErrorCoder
+	GetChangeFreezeWindow(kit *rest.Kit, id int64) (*metadata.ChangeFreezeWindow, errors.CCErrorCoder)
+	SearchChangeFreezeWindows(kit *rest.Kit, option metadata.SearchChangeFreezeWindowOption) (*metadata.SearchChangeFreezeWindowResult, errors.CCErrorCoder)
+}
+
 type core struct {
 	model           ModelOperation
 	instance        InstanceOperation
@@ -343,6 +386,9 @@ type core struct {
 	auth            AuthOperation
 	event           EventOperation
 	common          CommonOperation
+	admission       AdmissionOperation
+	approval        ApprovalOperation
+	changeFreeze    ChangeFreezeOperation
 }
 
 // New create core
@@ -363,6 +409,9 @@ func New(
 	auth AuthOperation,
 	event EventOperation,
 	common CommonOperation,
+	admission AdmissionOperation,
+	approval ApprovalOperation,
+	changeFreeze ChangeFreezeOperation,
 ) Core {
 	return &core{
 		model:           model,
@@ -382,6 +431,9 @@ func New(
 		auth:            auth,
 		event:           event,
 		common:          common,
+		admission:       admission,
+		approval:        approval,
+		changeFreeze:    changeFreeze,
 	}
 }
 
@@ -452,3 +504,15 @@ func (m *core) EventOperation() EventOperation {
 func (m *core) CommonOperation() CommonOperation {
 	return m.common
 }
+
+func (m *core) AdmissionOperation() AdmissionOperation {
+	return m.admission
+}
+
+func (m *core) ApprovalOperation() ApprovalOperation {
+	return m.approval
+}
+
+func (m *core) ChangeFreezeOperation() ChangeFreezeOperation {
+	return m.changeFreeze
+}
@@ -92,6 +92,17 @@ func (a *association) saveSynchronizeAssociationModuleHostConfig(kit *rest.Kit)
 		newItem := item.Info.Clone()
 
 		newItem.Remove(common.MetadataField)
+		if a.base.syncData.OperateType == metadata.SynchronizeOperateTypeDelete {
+			// the relation removed in the source cmdb by incremental synchronize
+			if err := mongodb.Client().Table(tableName).Delete(kit.Ctx, newItem); err != nil {
+				blog.Errorf("saveSynchronizeAssociationModuleHostConfig delete data error,err:%s.DataSign:%s,condition:%#v,rid:%s", err.Error(), a.DataClassify, newItem, kit.Rid)
+				a.base.errorArray[item.ID] = synchronizeAdapterError{
+					instInfo: item,
+					err:      kit.CCError.Error(common.CCErrCommDBDeleteFailed),
+				}
+			}
+			continue
+		}
 		cnt, err := mongodb.Client().Table(tableName).Find(newItem).Count(kit.Ctx)
 		if err != nil {
 			blog.Errorf("saveSynchronizeAssociationModuleHostConfig query db error,err:%s.DataSign:%s,condition:%#v,rid:%s", err.Error(), a.DataClassify, newItem, kit.Rid)
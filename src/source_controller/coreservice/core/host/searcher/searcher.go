@@ -104,7 +104,8 @@ func (s *Searcher) ListHosts(ctx context.Context, option metadata.ListHosts) (se
 		finalFilter[common.BKDBAND] = filters
 	}
 
-	if needHostIDFilter && len(filters) == 1 && option.BizID != 0 {
+	// 游标分页需要按排序字段+主机ID续传, 不走缓存和业务主机的快速分页流程
+	if needHostIDFilter && len(filters) == 1 && option.BizID != 0 && !option.Page.IsCursorPage() {
 		sort := strings.TrimLeft(option.Page.Sort, "+-")
 		if len(option.Page.Sort) == 0 || sort == common.BKHostIDField || strings.Contains(sort, ",") == false &&
 			strings.HasPrefix(sort, common.BKHostIDField+":") {
@@ -117,7 +118,7 @@ func (s *Searcher) ListHosts(ctx context.Context, option metadata.ListHosts) (se
 
 	}
 
-	if len(filters) == 0 {
+	if len(filters) == 0 && !option.Page.IsCursorPage() {
 		// return info use cache
 		// fix: has question when multi-supplier
 		sort := strings.TrimLeft(option.Page.Sort, "+-")
@@ -144,12 +145,21 @@ func (s *Searcher) ListHosts(ctx context.Context, option metadata.ListHosts) (se
 
 	limit := uint64(option.Page.Limit)
 	start := uint64(option.Page.Start)
-	query := mongodb.Client().Table(common.BKTableNameBaseHost).Find(finalFilter).Limit(limit).Start(start).Fields(option.Fields...)
+	findFilter, fields := finalFilter, option.Fields
+	sort := common.BKHostIDField
 	if len(option.Page.Sort) > 0 {
-		query = query.Sort(option.Page.Sort)
-	} else {
-		query = query.Sort(common.BKHostIDField)
+		sort = option.Page.Sort
+	}
+	if option.Page.IsCursorPage() {
+		cursor, err := option.Page.ParseCursor(common.BKHostIDField)
+		if err != nil {
+			blog.Errorf("ListHosts failed, parse page cursor failed, page: %+v, err: %v, rid: %s", option.Page, err, rid)
+			return nil, err
+		}
+		findFilter, sort, fields = cursor.MergeCondition(finalFilter), cursor.Sort, cursor.Fields(fields)
 	}
+	query := mongodb.Client().Table(common.BKTableNameBaseHost).Find(findFilter).Limit(limit).Start(start).
+		Fields(fields...).Sort(sort)
 
 	hosts := make([]metadata.HostMapStr, 0)
 	if err := query.All(ctx, &hosts); err != nil {
@@ -160,6 +170,14 @@ func (s *Searcher) ListHosts(ctx context.Context, option metadata.ListHosts) (se
 	for index, host := range hosts {
 		searchResult.Info[index] = host
 	}
+
+	if len(hosts) > 0 {
+		searchResult.NextCursor, err = option.Page.NextCursor(common.BKHostIDField, len(hosts), hosts[len(hosts)-1])
+		if err != nil {
+			blog.Errorf("ListHosts failed, generate next cursor failed, err: %v, rid: %s", err, rid)
+			return nil, err
+		}
+	}
 	return searchResult, nil
 }
 
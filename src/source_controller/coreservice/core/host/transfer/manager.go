@@ -34,6 +34,8 @@ type OperationDependence interface {
 	AutoCreateServiceInstanceModuleHost(kit *rest.Kit, hostIDs []int64, moduleIDs []int64) errors.CCErrorCoder
 	SelectObjectAttWithParams(kit *rest.Kit, objID string, bizID int64) (attribute []metadata.Attribute, err error)
 	UpdateModelInstance(kit *rest.Kit, objID string, param metadata.UpdateOption) (*metadata.UpdatedCount, error)
+	Admit(kit *rest.Kit, objID string, operation metadata.AdmissionOperation, object mapstr.MapStr,
+		oldObjects []mapstr.MapStr) (mapstr.MapStr, bool, errors.CCErrorCoder)
 }
 
 type HostApplyRuleDependence interface {
@@ -77,6 +79,10 @@ func (manager *TransferManager) TransferToInnerModule(kit *rest.Kit, input *meta
 		return err
 	}
 
+	if err := manager.admitTransfer(kit, input.ApplicationID, 0, input.HostID, []int64{input.ModuleID}, false); err != nil {
+		return err
+	}
+
 	transferErr := transfer.Transfer(kit, input.HostID)
 	if transferErr != nil {
 		blog.ErrorJSON("TransferHostToInnerModule failed, Transfer module host relation failed, input:%s, hostID:%s, err:%s, rid:%s", input, transferErr.Error(), kit.Rid)
@@ -122,6 +128,10 @@ func (manager *TransferManager) TransferToNormalModule(kit *rest.Kit, input *met
 		return err
 	}
 
+	if err := manager.admitTransfer(kit, input.ApplicationID, 0, input.HostID, input.ModuleID, input.IsIncrement); err != nil {
+		return err
+	}
+
 	err = transfer.Transfer(kit, input.HostID)
 	if err != nil {
 		blog.ErrorJSON("transfer to normal module failed, transfer module host relation failed. input: %s, err: %s, rid: %s", input, err, kit.Rid)
@@ -248,6 +258,11 @@ func (manager *TransferManager) TransferToAnotherBusiness(kit *rest.Kit, input *
 		return err
 	}
 
+	if err := manager.admitTransfer(kit, input.DstApplicationID, input.SrcApplicationID, input.HostIDArr,
+		input.DstModuleIDArr, false); err != nil {
+		return err
+	}
+
 	err = transfer.Transfer(kit, input.HostIDArr)
 	if err != nil {
 		blog.ErrorJSON("transfer to another business failed, transfer module host relation error. err: %s, input: %s, rid: %s", err.Error(), input, kit.Rid)
@@ -270,6 +285,28 @@ func (manager *TransferManager) TransferToAnotherBusiness(kit *rest.Kit, input *
 	return nil
 }
 
+// admitTransfer review the transfer with the admission webhooks of host, srcBizID is set when transfer across business
+func (manager *TransferManager) admitTransfer(kit *rest.Kit, bizID, srcBizID int64, hostIDs, moduleIDs []int64,
+	isIncrement bool) errors.CCErrorCoder {
+
+	object := mapstr.MapStr{
+		common.BKAppIDField:    bizID,
+		common.BKHostIDField:   hostIDs,
+		common.BKModuleIDField: moduleIDs,
+		"is_increment":         isIncrement,
+	}
+	if srcBizID != 0 {
+		object["src_"+common.BKAppIDField] = srcBizID
+	}
+
+	if _, _, err := manager.dependence.Admit(kit, common.BKInnerObjIDHost, metadata.AdmissionOperationTransfer, object,
+		nil); err != nil {
+		blog.Errorf("transfer host failed, admission review failed, option: %+v, err: %v, rid: %s", object, err, kit.Rid)
+		return err
+	}
+	return nil
+}
+
 func (manager *TransferManager) clearLegacyPrivateField(kit *rest.Kit, attributes []metadata.Attribute, hostIDs ...int64) errors.CCErrorCoder {
 	doc := make(map[string]interface{}, 0)
 	for _, attribute := range attributes {
@@ -25,8 +25,6 @@ import (
 	"configcenter/src/common/metadata"
 	"configcenter/src/common/util"
 	"configcenter/src/storage/driver/mongodb"
-
-	"github.com/google/go-cmp/cmp"
 )
 
 // GenerateApplyPlan 生成主机属性自动应用执行计划
@@ -102,6 +100,19 @@ func (p *hostApplyRule) GenerateApplyPlan(kit *rest.Kit, bizID int64, option met
 		return result, err
 	}
 
+	// rules bound to set, custom level and business are inherited by modules through topo path
+	moduleIDs := make([]int64, 0)
+	for _, item := range option.HostModules {
+		moduleIDs = append(moduleIDs, item.ModuleIDs...)
+	}
+	modulePaths, err := p.getModuleTopoPaths(kit, moduleIDs)
+	if err != nil {
+		blog.ErrorJSON("GenerateApplyPlan failed, getModuleTopoPaths failed, moduleIDs: %s, err: %s, rid: %s",
+			moduleIDs, err.Error(), rid)
+		return result, err
+	}
+	scopeRules := groupRulesByScope(option.Rules)
+
 	// compute apply plan one by one
 	hostApplyPlans := make([]metadata.OneHostApplyPlan, 0)
 	var hostApplyPlan metadata.OneHostApplyPlan
@@ -119,7 +130,9 @@ func (p *hostApplyRule) GenerateApplyPlan(kit *rest.Kit, bizID int64, option met
 			hostApplyPlans = append(hostApplyPlans, hostApplyPlan)
 			continue
 		}
-		hostApplyPlan, err = p.generateOneHostApplyPlan(kit, hostModule.HostID, host, hostModule.ModuleIDs, option.Rules, attributes, option.ConflictResolvers)
+		candidates := hostAttributeCandidates(hostModule.ModuleIDs, modulePaths, scopeRules)
+		hostApplyPlan, err = p.generateOneHostApplyPlan(kit, hostModule.HostID, host, hostModule.ModuleIDs, candidates,
+			attributes, option.ConflictResolvers, option.ConflictPolicy)
 		if err != nil {
 			blog.ErrorJSON("generateOneHostApplyPlan failed, host: %s, moduleIDs: %s, rules: %s, err: %s, rid: %s", host, hostModule.ModuleIDs, option.Rules, err.Error(), rid)
 			return result, err
@@ -161,9 +174,10 @@ func (p *hostApplyRule) generateOneHostApplyPlan(
 	hostID int64,
 	host map[string]interface{},
 	moduleIDs []int64,
-	rules []metadata.HostApplyRule,
+	attributeCandidates map[int64][]ruleCandidate,
 	attributes []metadata.Attribute,
 	resolvers []metadata.HostApplyConflictResolver,
+	policy metadata.HostApplyConflictPolicy,
 ) (metadata.OneHostApplyPlan, errors.CCErrorCoder) {
 	rid := util.ExtractRequestUserFromContext(kit.Ctx)
 
@@ -184,29 +198,14 @@ func (p *hostApplyRule) generateOneHostApplyPlan(
 		UnresolvedConflictCount: 0,
 	}
 
-	moduleIDSet := make(map[int64]bool)
-	for _, moduleID := range moduleIDs {
-		moduleIDSet[moduleID] = true
-	}
-	attributeRules := make(map[int64][]metadata.HostApplyRule)
-	for _, rule := range rules {
-		if _, exist := moduleIDSet[rule.ModuleID]; !exist {
-			continue
-		}
-		if _, exist := attributeRules[rule.AttributeID]; !exist {
-			attributeRules[rule.AttributeID] = make([]metadata.HostApplyRule, 0)
-		}
-		attributeRules[rule.AttributeID] = append(attributeRules[rule.AttributeID], rule)
-	}
-
 	attributeMap := make(map[int64]metadata.Attribute)
 	for _, attribute := range attributes {
 		attributeMap[attribute.ID] = attribute
 	}
 
 	// update host if conflicts not exist
-	for attributeID, targetRules := range attributeRules {
-		if len(targetRules) == 0 {
+	for attributeID, candidates := range attributeCandidates {
+		if len(candidates) == 0 {
 			continue
 		}
 		attribute, exist := attributeMap[attributeID]
@@ -224,55 +223,53 @@ func (p *hostApplyRule) generateOneHostApplyPlan(
 		}
 
 		// check conflicts
-		firstValue := targetRules[0].PropertyValue
-		conflictedStillExist := false
-		for _, rule := range targetRules {
-			if cmp.Equal(firstValue, rule.PropertyValue) {
-				continue
+		resolverValue, hasResolver := resolverMap[attribute.ID]
+		resolution := resolveAttribute(candidates, resolverValue, hasResolver, policy)
+		if resolution.conflict {
+			targetRules := make([]metadata.HostApplyRule, 0)
+			for _, candidate := range candidates {
+				targetRules = append(targetRules, candidate.rule)
 			}
-
-			conflictedStillExist = true
-			if propertyValue, exist := resolverMap[attribute.ID]; exist {
-				conflictedStillExist = false
-				firstValue = propertyValue
-			}
-
 			plan.ConflictFields = append(plan.ConflictFields, metadata.HostApplyConflictField{
 				AttributeID:             attributeID,
 				PropertyID:              propertyIDField,
 				PropertyValue:           originalValue,
 				Rules:                   targetRules,
-				UnresolvedConflictExist: conflictedStillExist,
+				UnresolvedConflictExist: resolution.unresolved,
 			})
-			break
 		}
 
-		if conflictedStillExist {
+		if resolution.unresolved {
 			plan.UnresolvedConflictCount += 1
 			continue
 		}
 
 		// validate property value before update to host
-		if value, ok := firstValue.(string); ok {
-			firstValue = strings.TrimSpace(value)
-			targetRules[0].PropertyValue = firstValue
+		value := resolution.value
+		if strValue, ok := value.(string); ok {
+			value = strings.TrimSpace(strValue)
+			if resolution.rule != nil {
+				resolution.rule.PropertyValue = value
+			}
 		}
-		rawErr := attribute.Validate(kit.Ctx, firstValue, propertyIDField)
+		rawErr := attribute.Validate(kit.Ctx, value, propertyIDField)
 		if rawErr.ErrCode != 0 {
 			err := rawErr.ToCCError(kit.CCError)
 			blog.ErrorJSON("generateOneHostApplyPlan failed, Validate failed, "+
-				"attribute: %s, firstValue: %s, propertyIDField: %s, rawErr: %s, rid: %s",
-				attribute, firstValue, propertyIDField, rawErr, rid)
+				"attribute: %s, value: %s, propertyIDField: %s, rawErr: %s, rid: %s",
+				attribute, value, propertyIDField, rawErr, rid)
 			plan.ErrCode = err.GetCode()
 			plan.ErrMsg = err.Error()
 			break
 		}
 
-		plan.ExpectHost[propertyIDField] = firstValue
+		plan.ExpectHost[propertyIDField] = value
 		plan.UpdateFields = append(plan.UpdateFields, metadata.HostApplyUpdateField{
 			AttributeID:   attributeID,
 			PropertyID:    propertyIDField,
-			PropertyValue: firstValue,
+			PropertyValue: value,
+			Rule:          resolution.rule,
+			Reason:        resolution.reason,
 		})
 	}
 
@@ -340,7 +337,8 @@ func (p *hostApplyRule) RunHostApplyOnHosts(kit *rest.Kit, bizID int64, option m
 		})
 	}
 	listHostApplyRuleOption := metadata.ListHostApplyRuleOption{
-		ModuleIDs: moduleIDs,
+		ModuleIDs:     moduleIDs,
+		WithInherited: true,
 		Page: metadata.BasePage{
 			Limit: common.BKNoLimit,
 		},
@@ -0,0 +1,136 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hostapplyrule
+
+import (
+	"sort"
+
+	"configcenter/src/common"
+	"configcenter/src/common/metadata"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// ruleCandidate 在主机的某个模块上生效的规则
+type ruleCandidate struct {
+	rule     metadata.HostApplyRule
+	moduleID int64
+	// depth 规则绑定的拓扑节点到模块的距离, 模块自身为0
+	depth int
+}
+
+// higherPriority 规则的比较顺序: 优先级高的优先, 其次离模块近的优先, 最后按模块ID和规则ID保证结果稳定
+func higherPriority(a, b ruleCandidate) bool {
+	if a.rule.Priority != b.rule.Priority {
+		return a.rule.Priority > b.rule.Priority
+	}
+	if a.depth != b.depth {
+		return a.depth < b.depth
+	}
+	if a.moduleID != b.moduleID {
+		return a.moduleID < b.moduleID
+	}
+	return a.rule.ID < b.rule.ID
+}
+
+// groupRulesByScope 按规则绑定的拓扑节点对规则分组
+func groupRulesByScope(rules []metadata.HostApplyRule) map[metadata.HostApplyScope][]metadata.HostApplyRule {
+	scopeRules := make(map[metadata.HostApplyScope][]metadata.HostApplyRule)
+	for _, rule := range rules {
+		scope := rule.Scope()
+		scopeRules[scope] = append(scopeRules[scope], rule)
+	}
+	return scopeRules
+}
+
+// moduleEffectiveRules 计算模块上每个属性生效的规则, 模块继承拓扑路径上所有节点的规则,
+// 同一属性在路径上存在多条规则时, 按 higherPriority 选出一条
+func moduleEffectiveRules(moduleID int64, path []metadata.HostApplyScope,
+	scopeRules map[metadata.HostApplyScope][]metadata.HostApplyRule) map[int64]ruleCandidate {
+
+	if len(path) == 0 {
+		path = []metadata.HostApplyScope{{ObjID: common.BKInnerObjIDModule, InstID: moduleID}}
+	}
+
+	effective := make(map[int64]ruleCandidate)
+	for depth, scope := range path {
+		for _, rule := range scopeRules[scope] {
+			candidate := ruleCandidate{rule: rule, moduleID: moduleID, depth: depth}
+			current, exist := effective[rule.AttributeID]
+			if !exist || higherPriority(candidate, current) {
+				effective[rule.AttributeID] = candidate
+			}
+		}
+	}
+	return effective
+}
+
+// hostAttributeCandidates 汇总主机所有模块上生效的规则, 按属性分组, 每组按 higherPriority 排序
+func hostAttributeCandidates(moduleIDs []int64, modulePaths map[int64][]metadata.HostApplyScope,
+	scopeRules map[metadata.HostApplyScope][]metadata.HostApplyRule) map[int64][]ruleCandidate {
+
+	attributeCandidates := make(map[int64][]ruleCandidate)
+	for _, moduleID := range moduleIDs {
+		for attributeID, candidate := range moduleEffectiveRules(moduleID, modulePaths[moduleID], scopeRules) {
+			attributeCandidates[attributeID] = append(attributeCandidates[attributeID], candidate)
+		}
+	}
+	for _, candidates := range attributeCandidates {
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return higherPriority(candidates[i], candidates[j])
+		})
+	}
+	return attributeCandidates
+}
+
+// attributeResolution 某个属性最终的应用结果
+type attributeResolution struct {
+	value  interface{}
+	rule   *metadata.HostApplyRule
+	reason metadata.HostApplyReason
+	// conflict 主机的多个模块上生效的规则值不同
+	conflict bool
+	// unresolved 冲突未被解决, 该属性不会被更新
+	unresolved bool
+}
+
+// resolveAttribute 计算属性的应用值, candidates 需已按 higherPriority 排序.
+// 冲突时优先使用用户指定的值, 其次按冲突处理策略处理, manual 策略下冲突不会被自动解决
+func resolveAttribute(candidates []ruleCandidate, resolverValue interface{}, hasResolver bool,
+	policy metadata.HostApplyConflictPolicy) attributeResolution {
+
+	best := candidates[0]
+	conflict := false
+	for _, candidate := range candidates[1:] {
+		if !cmp.Equal(best.rule.PropertyValue, candidate.rule.PropertyValue) {
+			conflict = true
+			break
+		}
+	}
+
+	if !conflict {
+		rule := best.rule
+		return attributeResolution{value: rule.PropertyValue, rule: &rule, reason: metadata.HostApplyReasonRule}
+	}
+
+	if hasResolver {
+		return attributeResolution{value: resolverValue, reason: metadata.HostApplyReasonResolver, conflict: true}
+	}
+
+	if policy == metadata.HostApplyConflictPolicyPriority {
+		rule := best.rule
+		return attributeResolution{value: rule.PropertyValue, rule: &rule, reason: metadata.HostApplyReasonPriority, conflict: true}
+	}
+
+	return attributeResolution{conflict: true, unresolved: true}
+}
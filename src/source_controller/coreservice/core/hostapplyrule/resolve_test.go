@@ -0,0 +1,106 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hostapplyrule
+
+import (
+	"testing"
+
+	"configcenter/src/common"
+	"configcenter/src/common/metadata"
+)
+
+var (
+	testBiz   = metadata.HostApplyScope{ObjID: common.BKInnerObjIDApp, InstID: 2}
+	testLevel = metadata.HostApplyScope{ObjID: "idc", InstID: 30}
+	testSet   = metadata.HostApplyScope{ObjID: common.BKInnerObjIDSet, InstID: 10}
+	testPaths = map[int64][]metadata.HostApplyScope{
+		100: {{ObjID: common.BKInnerObjIDModule, InstID: 100}, testSet, testLevel, testBiz},
+		101: {{ObjID: common.BKInnerObjIDModule, InstID: 101}, testSet, testLevel, testBiz},
+	}
+)
+
+func newTestRule(id int64, scope metadata.HostApplyScope, priority int64, value interface{}) metadata.HostApplyRule {
+	rule := metadata.HostApplyRule{
+		ID:            id,
+		ObjID:         scope.ObjID,
+		InstID:        scope.InstID,
+		AttributeID:   1,
+		Priority:      priority,
+		PropertyValue: value,
+	}
+	if scope.ObjID == common.BKInnerObjIDModule {
+		rule.ModuleID = scope.InstID
+	}
+	return rule
+}
+
+func TestModuleInheritsNearestRule(t *testing.T) {
+	rules := []metadata.HostApplyRule{
+		newTestRule(1, testBiz, 0, "biz"),
+		newTestRule(2, testSet, 0, "set"),
+	}
+	candidates := hostAttributeCandidates([]int64{100}, testPaths, groupRulesByScope(rules))
+	if len(candidates[1]) != 1 || candidates[1][0].rule.ID != 2 {
+		t.Fatalf("set rule should override biz rule, got: %+v", candidates[1])
+	}
+
+	// legacy rule without scope is bound to module
+	legacy := metadata.HostApplyRule{ID: 3, ModuleID: 100, AttributeID: 1, PropertyValue: "module"}
+	candidates = hostAttributeCandidates([]int64{100}, testPaths, groupRulesByScope(append(rules, legacy)))
+	if candidates[1][0].rule.ID != 3 {
+		t.Fatalf("module rule should override set rule, got: %+v", candidates[1])
+	}
+}
+
+func TestPriorityOverridesNearerRule(t *testing.T) {
+	rules := []metadata.HostApplyRule{
+		newTestRule(1, testLevel, 10, "level"),
+		newTestRule(2, metadata.HostApplyScope{ObjID: common.BKInnerObjIDModule, InstID: 100}, 0, "module"),
+	}
+	candidates := hostAttributeCandidates([]int64{100}, testPaths, groupRulesByScope(rules))
+	if candidates[1][0].rule.ID != 1 {
+		t.Fatalf("rule with higher priority should take effect, got: %+v", candidates[1])
+	}
+}
+
+func TestResolveConflict(t *testing.T) {
+	rules := []metadata.HostApplyRule{
+		newTestRule(1, metadata.HostApplyScope{ObjID: common.BKInnerObjIDModule, InstID: 100}, 0, "a"),
+		newTestRule(2, metadata.HostApplyScope{ObjID: common.BKInnerObjIDModule, InstID: 101}, 5, "b"),
+	}
+	candidates := hostAttributeCandidates([]int64{100, 101}, testPaths, groupRulesByScope(rules))[1]
+
+	manual := resolveAttribute(candidates, nil, false, metadata.HostApplyConflictPolicyManual)
+	if !manual.conflict || !manual.unresolved {
+		t.Fatalf("conflict should not be resolved with manual policy, got: %+v", manual)
+	}
+
+	resolved := resolveAttribute(candidates, "c", true, metadata.HostApplyConflictPolicyPriority)
+	if resolved.unresolved || resolved.value != "c" || resolved.reason != metadata.HostApplyReasonResolver {
+		t.Fatalf("conflict resolver should be used first, got: %+v", resolved)
+	}
+
+	priority := resolveAttribute(candidates, nil, false, metadata.HostApplyConflictPolicyPriority)
+	if priority.unresolved || priority.value != "b" || priority.rule.ID != 2 || priority.reason != metadata.HostApplyReasonPriority {
+		t.Fatalf("rule with higher priority should be used, got: %+v", priority)
+	}
+}
+
+func TestInheritedRuleIsNotConflict(t *testing.T) {
+	rules := []metadata.HostApplyRule{newTestRule(1, testSet, 0, "set")}
+	candidates := hostAttributeCandidates([]int64{100, 101}, testPaths, groupRulesByScope(rules))[1]
+	resolution := resolveAttribute(candidates, nil, false, metadata.HostApplyConflictPolicyManual)
+	if resolution.conflict || resolution.value != "set" || resolution.rule.ID != 1 {
+		t.Fatalf("same rule inherited by modules should not conflict, got: %+v", resolution)
+	}
+}
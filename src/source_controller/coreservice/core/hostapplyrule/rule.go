@@ -120,6 +120,9 @@ func (p *hostApplyRule) CreateHostApplyRule(kit *rest.Kit, bizID int64, option m
 		BizID:           bizID,
 		AttributeID:     option.AttributeID,
 		ModuleID:        option.ModuleID,
+		ObjID:           option.ObjID,
+		InstID:          option.InstID,
+		Priority:        option.Priority,
 		PropertyValue:   option.PropertyValue,
 		Creator:         kit.User,
 		Modifier:        kit.User,
@@ -132,9 +135,9 @@ func (p *hostApplyRule) CreateHostApplyRule(kit *rest.Kit, bizID int64, option m
 		return rule, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, key)
 	}
 
-	// validate bk_module_id
-	if err := p.validateModuleID(kit, bizID, rule.ModuleID); err != nil {
-		blog.Errorf("CreateHostApplyRule failed, validate bk_module_id failed, bizID: %d, moduleID: %d, err: %s, rid: %s", bizID, err.Error(), kit.Rid)
+	// validate the topo node that rule bound to
+	if err := p.validateScope(kit, bizID, rule.Scope()); err != nil {
+		blog.Errorf("CreateHostApplyRule failed, validate scope failed, bizID: %d, scope: %+v, err: %s, rid: %s", bizID, rule.Scope(), err.Error(), kit.Rid)
 		return rule, err
 	}
 
@@ -199,6 +202,12 @@ func (p *hostApplyRule) UpdateHostApplyRule(kit *rest.Kit, bizID int64, ruleID i
 	rule.LastTime = time.Now()
 	rule.Modifier = kit.User
 	rule.PropertyValue = option.PropertyValue
+	if option.Priority != nil {
+		if *option.Priority < 0 {
+			return rule, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.HostApplyRulePriorityField)
+		}
+		rule.Priority = *option.Priority
+	}
 
 	filter := map[string]interface{}{
 		common.BKFieldID: ruleID,
@@ -251,12 +260,15 @@ func (p *hostApplyRule) GetHostApplyRule(kit *rest.Kit, bizID int64, ruleID int6
 	return rule, nil
 }
 
-func (p *hostApplyRule) GetHostApplyRuleByAttributeID(kit *rest.Kit, bizID, moduleID, attributeID int64) (metadata.HostApplyRule, errors.CCErrorCoder) {
+func (p *hostApplyRule) GetHostApplyRuleByAttributeID(kit *rest.Kit, bizID int64, scope metadata.HostApplyScope,
+	attributeID int64) (metadata.HostApplyRule, errors.CCErrorCoder) {
+
 	rule := metadata.HostApplyRule{}
 	filter := map[string]interface{}{
 		common.BkSupplierAccount:  kit.SupplierAccount,
 		common.BKAppIDField:       bizID,
-		common.BKModuleIDField:    moduleID,
+		common.BKObjIDField:       scope.ObjID,
+		common.BKInstIDField:      scope.InstID,
 		common.BKAttributeIDField: attributeID,
 	}
 	if err := mongodb.Client().Table(common.BKTableNameHostApplyRule).Find(filter).One(kit.Ctx, &rule); err != nil {
@@ -283,11 +295,52 @@ func (p *hostApplyRule) ListHostApplyRule(kit *rest.Kit, bizID int64, option met
 	if bizID != 0 {
 		filter[common.BKAppIDField] = bizID
 	}
+
+	scopes := option.Scopes
+	if option.WithInherited {
+		modulePaths, ccErr := p.getModuleTopoPaths(kit, option.ModuleIDs)
+		if ccErr != nil {
+			blog.ErrorJSON("ListHostApplyRule failed, getModuleTopoPaths failed, moduleIDs: %s, err: %s, rid: %s", option.ModuleIDs, ccErr.Error(), kit.Rid)
+			return result, ccErr
+		}
+		result.ModuleScopes = modulePaths
+		for _, path := range modulePaths {
+			// module itself is matched by bk_module_id
+			if len(path) > 1 {
+				scopes = append(scopes, path[1:]...)
+			}
+		}
+	}
+
+	scopeConditions := make([]map[string]interface{}, 0)
 	if option.ModuleIDs != nil {
-		filter[common.BKModuleIDField] = map[string]interface{}{
-			common.BKDBIN: option.ModuleIDs,
+		scopeConditions = append(scopeConditions, map[string]interface{}{
+			common.BKModuleIDField: map[string]interface{}{
+				common.BKDBIN: option.ModuleIDs,
+			},
+		})
+	}
+	scopeInstIDs := make(map[string][]int64)
+	for _, scope := range scopes {
+		scopeInstIDs[scope.ObjID] = append(scopeInstIDs[scope.ObjID], scope.InstID)
+	}
+	for objID, instIDs := range scopeInstIDs {
+		scopeConditions = append(scopeConditions, map[string]interface{}{
+			common.BKObjIDField: objID,
+			common.BKInstIDField: map[string]interface{}{
+				common.BKDBIN: util.IntArrayUnique(instIDs),
+			},
+		})
+	}
+	switch {
+	case len(scopeConditions) == 1:
+		for key, value := range scopeConditions[0] {
+			filter[key] = value
 		}
+	case len(scopeConditions) > 1:
+		filter[common.BKDBOR] = scopeConditions
 	}
+
 	if len(option.AttributeIDs) != 0 {
 		filter[common.BKAttributeIDField] = map[string]interface{}{
 			common.BKDBIN: option.AttributeIDs,
@@ -469,11 +522,18 @@ func (p *hostApplyRule) BatchUpdateHostApplyRule(kit *rest.Kit, bizID int64, opt
 		itemResult := metadata.CreateOrUpdateHostApplyRuleResult{
 			Index: index,
 		}
+		scope := item.Scope()
+		if scope.InstID <= 0 || item.Priority < 0 {
+			itemResult.SetError(kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKInstIDField))
+			batchResult.Items = append(batchResult.Items, itemResult)
+			continue
+		}
 		ruleFilter := map[string]interface{}{
 			common.BKAppIDField:       bizID,
 			common.BkSupplierAccount:  kit.SupplierAccount,
 			common.BKAttributeIDField: item.AttributeID,
-			common.BKModuleIDField:    item.ModuleID,
+			common.BKObjIDField:       scope.ObjID,
+			common.BKInstIDField:      scope.InstID,
 		}
 		count, err := mongodb.Client().Table(common.BKTableNameHostApplyRule).Find(ruleFilter).Count(kit.Ctx)
 		if err != nil {
@@ -507,9 +567,10 @@ func (p *hostApplyRule) BatchUpdateHostApplyRule(kit *rest.Kit, bizID int64, opt
 		// update rule
 		if count > 0 {
 			updateData := map[string]interface{}{
-				common.BKPropertyValueField: item.PropertyValue,
-				common.LastTimeField:        now,
-				common.ModifierField:        kit.User,
+				common.BKPropertyValueField:       item.PropertyValue,
+				common.HostApplyRulePriorityField: item.Priority,
+				common.LastTimeField:              now,
+				common.ModifierField:              kit.User,
 			}
 			if err := mongodb.Client().Table(common.BKTableNameHostApplyRule).Update(kit.Ctx, ruleFilter, updateData); err != nil {
 				blog.ErrorJSON("BatchUpdateHostApplyRule failed, update rule failed, filter: %s, doc: %s, err: %s, rid: %s", ruleFilter, updateData, err.Error(), rid)
@@ -521,6 +582,12 @@ func (p *hostApplyRule) BatchUpdateHostApplyRule(kit *rest.Kit, bizID int64, opt
 		}
 
 		// create new rule
+		if ccErr := p.validateScope(kit, bizID, scope); ccErr != nil {
+			blog.Errorf("BatchUpdateHostApplyRule failed, validate scope failed, scope: %+v, err: %s, rid: %s", scope, ccErr.Error(), rid)
+			itemResult.SetError(ccErr)
+			batchResult.Items = append(batchResult.Items, itemResult)
+			continue
+		}
 		newRuleID, err := mongodb.Client().NextSequence(kit.Ctx, common.BKTableNameHostApplyRule)
 		if err != nil {
 			blog.ErrorJSON("BatchUpdateHostApplyRule failed, generate id field failed, err: %s, rid: %s", err.Error(), rid)
@@ -532,7 +599,9 @@ func (p *hostApplyRule) BatchUpdateHostApplyRule(kit *rest.Kit, bizID int64, opt
 		rule := metadata.HostApplyRule{
 			ID:              int64(newRuleID),
 			BizID:           bizID,
-			ModuleID:        item.ModuleID,
+			ObjID:           scope.ObjID,
+			InstID:          scope.InstID,
+			Priority:        item.Priority,
 			AttributeID:     item.AttributeID,
 			PropertyValue:   item.PropertyValue,
 			Creator:         kit.User,
@@ -541,6 +610,9 @@ func (p *hostApplyRule) BatchUpdateHostApplyRule(kit *rest.Kit, bizID int64, opt
 			LastTime:        now,
 			SupplierAccount: kit.SupplierAccount,
 		}
+		if scope.ObjID == common.BKInnerObjIDModule {
+			rule.ModuleID = scope.InstID
+		}
 		if err := mongodb.Client().Table(common.BKTableNameHostApplyRule).Insert(kit.Ctx, rule); err != nil {
 			blog.ErrorJSON("BatchUpdateHostApplyRule failed, insert rule failed, doc: %s, err: %s, rid: %s", rule, err.Error(), rid)
 			ccErr := kit.CCError.CCError(common.CCErrCommDBInsertFailed)
@@ -552,9 +624,9 @@ func (p *hostApplyRule) BatchUpdateHostApplyRule(kit *rest.Kit, bizID int64, opt
 	}
 
 	for index, item := range option.Rules {
-		rule, ccErr := p.GetHostApplyRuleByAttributeID(kit, bizID, item.ModuleID, item.AttributeID)
+		rule, ccErr := p.GetHostApplyRuleByAttributeID(kit, bizID, item.Scope(), item.AttributeID)
 		if ccErr != nil {
-			blog.Errorf("GetHostApplyRuleByAttributeID failed, bizID: %d, moduleID: %d, attribute: %d, err: %s, rid: %s", bizID, item.ModuleID, item.AttributeID, ccErr.Error(), rid)
+			blog.Errorf("GetHostApplyRuleByAttributeID failed, bizID: %d, scope: %+v, attribute: %d, err: %s, rid: %s", bizID, item.Scope(), item.AttributeID, ccErr.Error(), rid)
 			if err := batchResult.Items[index].GetError(); err == nil {
 				batchResult.Items[index].SetError(ccErr)
 			}
@@ -0,0 +1,172 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hostapplyrule
+
+import (
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/storage/driver/mongodb"
+)
+
+// topoInstance 自定义层级实例, 仅包含查询拓扑路径所需的字段
+type topoInstance struct {
+	InstID   int64 `bson:"bk_inst_id"`
+	ParentID int64 `bson:"bk_parent_id"`
+}
+
+// validateScope check the topo node that the rule is bound to exists in the business
+func (p *hostApplyRule) validateScope(kit *rest.Kit, bizID int64, scope metadata.HostApplyScope) errors.CCErrorCoder {
+	switch scope.ObjID {
+	case common.BKInnerObjIDModule:
+		return p.validateModuleID(kit, bizID, scope.InstID)
+	case common.BKInnerObjIDApp:
+		if scope.InstID != bizID {
+			return kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKInstIDField)
+		}
+		return nil
+	}
+
+	table := common.GetInstTableName(scope.ObjID)
+	filter := map[string]interface{}{
+		common.BKAppIDField:                bizID,
+		common.GetInstIDField(scope.ObjID): scope.InstID,
+	}
+	if scope.ObjID != common.BKInnerObjIDSet {
+		filter[common.BKObjIDField] = scope.ObjID
+
+		// only mainline topo nodes can be bound with host apply rules
+		mainlineFilter := map[string]interface{}{
+			common.AssociationKindIDField: common.AssociationKindMainline,
+			common.BKObjIDField:           scope.ObjID,
+		}
+		count, err := mongodb.Client().Table(common.BKTableNameObjAsst).Find(mainlineFilter).Count(kit.Ctx)
+		if err != nil {
+			blog.Errorf("validateScope failed, count mainline association failed, filter: %+v, err: %v, rid: %s", mainlineFilter, err, kit.Rid)
+			return kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+		}
+		if count == 0 {
+			return kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKObjIDField)
+		}
+	}
+
+	count, err := mongodb.Client().Table(table).Find(filter).Count(kit.Ctx)
+	if err != nil {
+		blog.Errorf("validateScope failed, count topo instance failed, table: %s, filter: %+v, err: %v, rid: %s", table, filter, err, kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+	if count == 0 {
+		return kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKInstIDField)
+	}
+	return nil
+}
+
+// getModuleTopoPaths 查询模块到业务的拓扑路径, 路径中第一个节点为模块自身, 最后一个节点为业务
+func (p *hostApplyRule) getModuleTopoPaths(kit *rest.Kit, moduleIDs []int64) (map[int64][]metadata.HostApplyScope, errors.CCErrorCoder) {
+	paths := make(map[int64][]metadata.HostApplyScope)
+	if len(moduleIDs) == 0 {
+		return paths, nil
+	}
+
+	modules := make([]metadata.ModuleInst, 0)
+	moduleFilter := map[string]interface{}{
+		common.BKModuleIDField: map[string]interface{}{
+			common.BKDBIN: util.IntArrayUnique(moduleIDs),
+		},
+	}
+	fields := []string{common.BKModuleIDField, common.BKParentIDField, common.BKAppIDField}
+	if err := mongodb.Client().Table(common.BKTableNameBaseModule).Find(moduleFilter).Fields(fields...).All(kit.Ctx, &modules); err != nil {
+		blog.ErrorJSON("getModuleTopoPaths failed, list modules failed, filter: %s, err: %s, rid: %s", moduleFilter, err.Error(), kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+
+	setIDs := make([]int64, 0)
+	for _, module := range modules {
+		setIDs = append(setIDs, module.ParentID)
+	}
+	sets := make([]metadata.SetInst, 0)
+	setFilter := map[string]interface{}{
+		common.BKSetIDField: map[string]interface{}{
+			common.BKDBIN: util.IntArrayUnique(setIDs),
+		},
+	}
+	fields = []string{common.BKSetIDField, common.BKParentIDField}
+	if err := mongodb.Client().Table(common.BKTableNameBaseSet).Find(setFilter).Fields(fields...).All(kit.Ctx, &sets); err != nil {
+		blog.ErrorJSON("getModuleTopoPaths failed, list sets failed, filter: %s, err: %s, rid: %s", setFilter, err.Error(), kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+	setParent := make(map[int64]int64)
+	for _, set := range sets {
+		setParent[set.SetID] = set.ParentID
+	}
+
+	// child object -> parent object of mainline model
+	associations := make([]metadata.Association, 0)
+	asstFilter := map[string]interface{}{
+		common.AssociationKindIDField: common.AssociationKindMainline,
+	}
+	asstFilter = util.SetQueryOwner(asstFilter, kit.SupplierAccount)
+	if err := mongodb.Client().Table(common.BKTableNameObjAsst).Find(asstFilter).All(kit.Ctx, &associations); err != nil {
+		blog.ErrorJSON("getModuleTopoPaths failed, list mainline associations failed, filter: %s, err: %s, rid: %s", asstFilter, err.Error(), kit.Rid)
+		return nil, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+	parentObject := make(map[string]string)
+	for _, asst := range associations {
+		parentObject[asst.ObjectID] = asst.AsstObjID
+	}
+
+	// load custom level instances level by level, from set's parent up to business
+	customParent := make(map[metadata.HostApplyScope]int64)
+	parentIDs := make([]int64, 0)
+	for _, parentID := range setParent {
+		parentIDs = append(parentIDs, parentID)
+	}
+	for objID := parentObject[common.BKInnerObjIDSet]; objID != "" && objID != common.BKInnerObjIDApp; objID = parentObject[objID] {
+		instances := make([]topoInstance, 0)
+		instFilter := map[string]interface{}{
+			common.BKObjIDField: objID,
+			common.BKInstIDField: map[string]interface{}{
+				common.BKDBIN: util.IntArrayUnique(parentIDs),
+			},
+		}
+		fields = []string{common.BKInstIDField, common.BKParentIDField}
+		if err := mongodb.Client().Table(common.BKTableNameBaseInst).Find(instFilter).Fields(fields...).All(kit.Ctx, &instances); err != nil {
+			blog.ErrorJSON("getModuleTopoPaths failed, list custom level instances failed, filter: %s, err: %s, rid: %s", instFilter, err.Error(), kit.Rid)
+			return nil, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+		}
+		parentIDs = make([]int64, 0)
+		for _, inst := range instances {
+			customParent[metadata.HostApplyScope{ObjID: objID, InstID: inst.InstID}] = inst.ParentID
+			parentIDs = append(parentIDs, inst.ParentID)
+		}
+	}
+
+	for _, module := range modules {
+		path := []metadata.HostApplyScope{
+			{ObjID: common.BKInnerObjIDModule, InstID: module.ModuleID},
+			{ObjID: common.BKInnerObjIDSet, InstID: module.ParentID},
+		}
+		parentID := setParent[module.ParentID]
+		for objID := parentObject[common.BKInnerObjIDSet]; objID != "" && objID != common.BKInnerObjIDApp; objID = parentObject[objID] {
+			scope := metadata.HostApplyScope{ObjID: objID, InstID: parentID}
+			path = append(path, scope)
+			parentID = customParent[scope]
+		}
+		path = append(path, metadata.HostApplyScope{ObjID: common.BKInnerObjIDApp, InstID: module.BizID})
+		paths[module.ModuleID] = path
+	}
+	return paths, nil
+}
@@ -13,7 +13,9 @@
 package instances
 
 import (
+	"configcenter/src/common/errors"
 	"configcenter/src/common/http/rest"
+	"configcenter/src/common/mapstr"
 	"configcenter/src/common/metadata"
 )
 
@@ -32,4 +34,8 @@ type OperationDependences interface {
 
 	// SearchUnique search unique attribute
 	SearchUnique(kit *rest.Kit, objID string) (uniqueAttr []metadata.ObjectUnique, err error)
+
+	// Admit review the write with the admission webhooks, returns the object patched by mutating webhooks
+	Admit(kit *rest.Kit, objID string, operation metadata.AdmissionOperation, object mapstr.MapStr,
+		oldObjects []mapstr.MapStr) (mapstr.MapStr, bool, errors.CCErrorCoder)
 }
@@ -23,6 +23,7 @@ import (
 	"configcenter/src/common/language"
 	"configcenter/src/common/mapstr"
 	"configcenter/src/common/metadata"
+	"configcenter/src/common/querybuilder"
 	"configcenter/src/common/util"
 	"configcenter/src/source_controller/coreservice/core"
 	"configcenter/src/storage/driver/mongodb"
@@ -60,6 +61,10 @@ func (m *instanceManager) CreateModelInstance(kit *rest.Kit, objID string, input
 		blog.Errorf("CreateModelInstance failed, valid error: %+v, rid: %s", err, rid)
 		return nil, err
 	}
+	inputParam.Data, err = m.admitCreate(kit, objID, inputParam.Data)
+	if err != nil {
+		return nil, err
+	}
 	id, err := m.save(kit, objID, inputParam.Data)
 	if err != nil {
 		blog.ErrorJSON("CreateModelInstance create objID(%s) instance error. err:%s, data:%s, rid:%s", objID, err.Error(), inputParam.Data, kit.Rid)
@@ -84,6 +89,16 @@ func (m *instanceManager) CreateManyModelInstance(kit *rest.Kit, objID string, i
 			})
 			continue
 		}
+		item, err = m.admitCreate(kit, objID, item)
+		if err != nil {
+			dataResult.Exceptions = append(dataResult.Exceptions, metadata.ExceptionResult{
+				Message:     err.Error(),
+				Code:        int64(err.(errors.CCErrorCoder).GetCode()),
+				Data:        inputParam.Datas[itemIdx],
+				OriginIndex: int64(itemIdx),
+			})
+			continue
+		}
 		item.Set(common.BKOwnerIDField, kit.SupplierAccount)
 		id, err := m.save(kit, objID, item)
 		if nil != err {
@@ -130,6 +145,23 @@ func (m *instanceManager) UpdateModelInstance(kit *rest.Kit, objID string, input
 		}
 	}
 
+	admitted, patched, ccErr := m.dependent.Admit(kit, objID, metadata.AdmissionOperationUpdate, inputParam.Data, origins)
+	if ccErr != nil {
+		blog.Errorf("UpdateModelInstance failed, admission review failed, err: %v, rid: %s", ccErr, kit.Rid)
+		return nil, ccErr
+	}
+	if patched {
+		// validate the data patched by the mutating webhooks again
+		for _, origin := range origins {
+			instID, _ := util.GetInt64ByInterface(origin[instIDFieldName])
+			if err := m.validUpdateInstanceData(kit, objID, admitted, uint64(instID), inputParam.CanEditAll); err != nil {
+				blog.Errorf("update model instance validate patched data error :%v ,rid:%s", err, kit.Rid)
+				return nil, err
+			}
+		}
+		inputParam.Data = admitted
+	}
+
 	err = m.update(kit, objID, inputParam.Data, inputParam.Condition)
 	if err != nil {
 		blog.ErrorJSON("UpdateModelInstance update objID(%s) inst error. err:%s, condition:%s, rid:%s", objID, err, inputParam.Condition, kit.Rid)
@@ -145,6 +177,25 @@ func (m *instanceManager) UpdateModelInstance(kit *rest.Kit, objID string, input
 	return &metadata.UpdatedCount{Count: uint64(len(origins))}, nil
 }
 
+// admitCreate review the instance to create with the admission webhooks, the data patched by the mutating
+// webhooks is validated again.
+func (m *instanceManager) admitCreate(kit *rest.Kit, objID string, data mapstr.MapStr) (mapstr.MapStr, error) {
+	admitted, patched, err := m.dependent.Admit(kit, objID, metadata.AdmissionOperationCreate, data, nil)
+	if err != nil {
+		blog.Errorf("create model instance failed, admission review failed, err: %v, rid: %s", err, kit.Rid)
+		return data, err
+	}
+	if !patched {
+		return data, nil
+	}
+
+	if err := m.validCreateInstanceData(kit, objID, admitted); err != nil {
+		blog.Errorf("create model instance failed, validate patched data failed, err: %v, rid: %s", err, kit.Rid)
+		return data, err
+	}
+	return admitted, nil
+}
+
 // updateHostProcessBindIP if hosts' ips are updated, update processes which binds the changed ip
 func (m *instanceManager) updateHostProcessBindIP(kit *rest.Kit, updateData mapstr.MapStr, origins []mapstr.MapStr) error {
 	innerIP, innerIPExist := updateData[common.BKHostInnerIPField]
@@ -314,13 +365,41 @@ func (m *instanceManager) SearchModelInstance(kit *rest.Kit, objID string, input
 		}
 		inputParam.Condition[common.BKObjIDField] = objID
 	}
+
+	if err := metadata.ValidateQueryOperators(inputParam.Condition); err != nil {
+		blog.Errorf("search instance with invalid condition, err: %v, rid: %s", err, kit.Rid)
+		return nil, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "condition")
+	}
+	if inputParam.Filter != nil {
+		cond, err := m.mergeQueryFilter(kit, objID, inputParam.Condition, inputParam.Filter)
+		if err != nil {
+			return nil, err
+		}
+		inputParam.Condition = cond
+	}
 	inputParam.Condition = util.SetQueryOwner(inputParam.Condition, kit.SupplierAccount)
 
+	// 游标分页, 使用排序字段+实例ID作为续传位置, 避免深度翻页的skip查询
+	findCond, sort, fields := mapstr.MapStr(inputParam.Condition), inputParam.Page.Sort, inputParam.Fields
+	idField := common.GetInstIDField(objID)
+	if inputParam.Page.IsCursorPage() {
+		if key, err := inputParam.Page.Validate(false); err != nil {
+			blog.Errorf("invalid cursor page: %+v, err: %v, rid: %s", inputParam.Page, err, kit.Rid)
+			return nil, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "page."+key)
+		}
+		cursor, err := inputParam.Page.ParseCursor(idField)
+		if err != nil {
+			blog.Errorf("parse page cursor failed, page: %+v, err: %v, rid: %s", inputParam.Page, err, kit.Rid)
+			return nil, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "page.cursor")
+		}
+		findCond, sort, fields = cursor.MergeCondition(findCond), cursor.Sort, cursor.Fields(fields)
+	}
+
 	instItems := make([]mapstr.MapStr, 0)
-	query := mongodb.Client().Table(tableName).Find(inputParam.Condition).Start(uint64(inputParam.Page.Start)).
+	query := mongodb.Client().Table(tableName).Find(findCond).Start(uint64(inputParam.Page.Start)).
 		Limit(uint64(inputParam.Page.Limit)).
-		Sort(inputParam.Page.Sort).
-		Fields(inputParam.Fields...)
+		Sort(sort).
+		Fields(fields...)
 	var instErr error
 	if objID == common.BKInnerObjIDHost {
 		hosts := make([]metadata.HostMapStr, 0)
@@ -357,6 +436,14 @@ func (m *instanceManager) SearchModelInstance(kit *rest.Kit, objID string, input
 		Info:  instItems,
 	}
 
+	if len(instItems) > 0 {
+		dataResult.NextCursor, instErr = inputParam.Page.NextCursor(idField, len(instItems), instItems[len(instItems)-1])
+		if instErr != nil {
+			blog.Errorf("generate next cursor failed, err: %v, rid: %s", instErr, kit.Rid)
+			return nil, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "page.sort")
+		}
+	}
+
 	return dataResult, nil
 }
 
@@ -385,6 +472,13 @@ func (m *instanceManager) DeleteModelInstance(kit *rest.Kit, objID string, input
 		}
 	}
 
+	if len(origins) > 0 {
+		if _, _, err := m.dependent.Admit(kit, objID, metadata.AdmissionOperationDelete, nil, origins); err != nil {
+			blog.Errorf("DeleteModelInstance failed, admission review failed, err: %v, rid: %s", err, kit.Rid)
+			return &metadata.DeletedCount{}, err
+		}
+	}
+
 	err = mongodb.Client().Table(tableName).Delete(kit.Ctx, inputParam.Condition)
 	if nil != err {
 		blog.ErrorJSON("DeleteModelInstance delete objID(%s) instance error. err:%s, coniditon:%s, rid:%s", objID, err.Error(), inputParam.Condition, kit.Rid)
@@ -404,6 +498,13 @@ func (m *instanceManager) CascadeDeleteModelInstance(kit *rest.Kit, objID string
 		return &metadata.DeletedCount{}, err
 	}
 
+	if len(origins) > 0 {
+		if _, _, err := m.dependent.Admit(kit, objID, metadata.AdmissionOperationDelete, nil, origins); err != nil {
+			blog.Errorf("cascade delete model instance failed, admission review failed, err: %v, rid: %s", err, kit.Rid)
+			return &metadata.DeletedCount{}, err
+		}
+	}
+
 	for _, origin := range origins {
 		instID, err := util.GetInt64ByInterface(origin[instIDFieldName])
 		if nil != err {
@@ -421,3 +522,24 @@ func (m *instanceManager) CascadeDeleteModelInstance(kit *rest.Kit, objID string
 	}
 	return &metadata.DeletedCount{Count: uint64(len(origins))}, nil
 }
+
+// mergeQueryFilter validates the filter with the attributes of the object and merges it into the condition
+func (m *instanceManager) mergeQueryFilter(kit *rest.Kit, objID string, cond mapstr.MapStr,
+	filter *querybuilder.QueryFilter) (mapstr.MapStr, error) {
+
+	// the business private attributes can only be used when the condition specifies the business
+	bizID, _ := util.GetInt64ByInterface(cond[common.BKAppIDField])
+	attributes, err := m.dependent.SelectObjectAttWithParams(kit, objID, bizID)
+	if err != nil {
+		blog.Errorf("get attributes of object %s failed, err: %v, rid: %s", objID, err, kit.Rid)
+		return nil, err
+	}
+
+	merged, key, err := metadata.MergeQueryFilter(cond, filter, metadata.InstFilterFieldTypes(objID, attributes))
+	if err != nil {
+		blog.Errorf("search instance of object %s with invalid filter, key: %s, err: %v, rid: %s", objID, key, err,
+			kit.Rid)
+		return nil, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, key)
+	}
+	return merged, nil
+}
@@ -318,6 +318,12 @@ func (p *setTemplateOperation) DeleteSetTemplate(kit *rest.Kit, bizID int64, opt
 		return kit.CCError.CCError(common.CCErrCommDBDeleteFailed)
 	}
 
+	// delete sync policies, relation filter has the same fields
+	if err := mongodb.Client().Table(common.BKTableNameSetTemplateSyncPolicy).Delete(kit.Ctx, relationFilter); err != nil {
+		blog.Errorf("DeleteSetTemplate failed, db remove sync policies failed, filter: %+v, err: %+v, rid: %s", relationFilter, err, kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommDBDeleteFailed)
+	}
+
 	return nil
 }
 
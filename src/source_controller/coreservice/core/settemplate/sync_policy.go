@@ -0,0 +1,141 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package settemplate
+
+import (
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	"configcenter/src/storage/driver/mongodb"
+)
+
+// UpdateSetTemplateSyncPolicy create or update the sync policy of a set template
+func (p *setTemplateOperation) UpdateSetTemplateSyncPolicy(kit *rest.Kit, bizID int64, setTemplateID int64,
+	option metadata.UpdateSetTemplateSyncPolicyOption) (metadata.SetTemplateSyncPolicy, errors.CCErrorCoder) {
+
+	policy := metadata.SetTemplateSyncPolicy{}
+	if _, err := p.GetSetTemplate(kit, bizID, setTemplateID); err != nil {
+		blog.Errorf("UpdateSetTemplateSyncPolicy failed, get set template failed, bizID: %d, setTemplateID: %d, err: %s, rid: %s", bizID, setTemplateID, err.Error(), kit.Rid)
+		return policy, err
+	}
+
+	filter := map[string]interface{}{
+		common.BKAppIDField:         bizID,
+		common.BKSetTemplateIDField: setTemplateID,
+	}
+	filter = util.SetQueryOwner(filter, kit.SupplierAccount)
+
+	existPolicies := make([]metadata.SetTemplateSyncPolicy, 0)
+	if err := mongodb.Client().Table(common.BKTableNameSetTemplateSyncPolicy).Find(filter).All(kit.Ctx, &existPolicies); err != nil {
+		blog.Errorf("UpdateSetTemplateSyncPolicy failed, db select failed, filter: %+v, err: %s, rid: %s", filter, err.Error(), kit.Rid)
+		return policy, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+
+	now := time.Now()
+	if len(existPolicies) > 0 {
+		policy = existPolicies[0]
+	} else {
+		policy = metadata.SetTemplateSyncPolicy{
+			BizID:           bizID,
+			SetTemplateID:   setTemplateID,
+			Creator:         kit.User,
+			CreateTime:      now,
+			SupplierAccount: kit.SupplierAccount,
+		}
+	}
+	policy.Mode = option.Mode
+	policy.Cron = option.Cron
+	policy.KeepModulesWithHost = option.KeepModulesWithHost
+	policy.BatchSize = option.BatchSize
+	policy.NotifyURL = option.NotifyURL
+	policy.Modifier = kit.User
+	policy.LastTime = now
+
+	if key, err := policy.Validate(); err != nil {
+		blog.Errorf("UpdateSetTemplateSyncPolicy failed, policy invalid, key: %s, err: %s, rid: %s", key, err.Error(), kit.Rid)
+		return policy, kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, key)
+	}
+
+	if err := mongodb.Client().Table(common.BKTableNameSetTemplateSyncPolicy).Upsert(kit.Ctx, filter, policy); err != nil {
+		blog.Errorf("UpdateSetTemplateSyncPolicy failed, db upsert failed, filter: %+v, policy: %+v, err: %s, rid: %s", filter, policy, err.Error(), kit.Rid)
+		return policy, kit.CCError.CCError(common.CCErrCommDBUpdateFailed)
+	}
+
+	return policy, nil
+}
+
+func (p *setTemplateOperation) ListSetTemplateSyncPolicy(kit *rest.Kit, option metadata.ListSetTemplateSyncPolicyOption) (metadata.MultipleSetTemplateSyncPolicy, errors.CCErrorCoder) {
+	result := metadata.MultipleSetTemplateSyncPolicy{
+		Info: make([]metadata.SetTemplateSyncPolicy, 0),
+	}
+
+	filter := option.ToFilter()
+	filter = util.SetQueryOwner(filter, kit.SupplierAccount)
+	total, err := mongodb.Client().Table(common.BKTableNameSetTemplateSyncPolicy).Find(filter).Count(kit.Ctx)
+	if err != nil {
+		blog.ErrorJSON("ListSetTemplateSyncPolicy failed, db count failed, filter: %s, err: %s, rid: %s", filter, err.Error(), kit.Rid)
+		return result, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+	result.Count = int64(total)
+
+	query := mongodb.Client().Table(common.BKTableNameSetTemplateSyncPolicy).Find(filter)
+	if option.Page.Start != 0 {
+		query = query.Start(uint64(option.Page.Start))
+	}
+	if option.Page.Limit != 0 {
+		query = query.Limit(uint64(option.Page.Limit))
+	}
+	if len(option.Page.Sort) != 0 {
+		query = query.Sort(option.Page.Sort)
+	}
+	if err := query.All(kit.Ctx, &result.Info); err != nil {
+		blog.ErrorJSON("ListSetTemplateSyncPolicy failed, db select failed, filter: %s, err: %s, rid: %s", filter, err.Error(), kit.Rid)
+		return result, kit.CCError.CCError(common.CCErrCommDBSelectFailed)
+	}
+
+	return result, nil
+}
+
+// UpdateSetTemplateSyncResult save the time and summary of the latest automatic synchronization
+func (p *setTemplateOperation) UpdateSetTemplateSyncResult(kit *rest.Kit, bizID int64, setTemplateID int64,
+	option metadata.UpdateSetTemplateSyncResultOption) errors.CCErrorCoder {
+
+	filter := map[string]interface{}{
+		common.BKAppIDField:         bizID,
+		common.BKSetTemplateIDField: setTemplateID,
+	}
+	filter = util.SetQueryOwner(filter, kit.SupplierAccount)
+	doc := map[string]interface{}{
+		"last_sync_time": option.SyncTime,
+		"last_summary":   option.Summary,
+		"fail_count":     0,
+		"last_error":     "",
+	}
+	if len(option.Error) != 0 {
+		doc = map[string]interface{}{
+			"last_fail_time": option.SyncTime,
+			"fail_count":     option.FailCount,
+			"last_error":     option.Error,
+		}
+	}
+	if err := mongodb.Client().Table(common.BKTableNameSetTemplateSyncPolicy).Update(kit.Ctx, filter, doc); err != nil {
+		blog.Errorf("UpdateSetTemplateSyncResult failed, db update failed, filter: %+v, err: %s, rid: %s", filter, err.Error(), kit.Rid)
+		return kit.CCError.CCError(common.CCErrCommDBUpdateFailed)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"strconv"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+)
+
+func (s *coreService) CreateAdmissionWebhook(ctx *rest.Contexts) {
+	webhook := metadata.AdmissionWebhook{}
+	if err := ctx.DecodeInto(&webhook); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	result, err := s.core.AdmissionOperation().CreateAdmissionWebhook(ctx.Kit, webhook)
+	if err != nil {
+		blog.Errorf("CreateAdmissionWebhook failed, name: %s, err: %v, rid: %s", webhook.Name, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(result)
+}
+
+func (s *coreService) UpdateAdmissionWebhook(ctx *rest.Contexts) {
+	id, err := strconv.ParseInt(ctx.Request.PathParameter("id"), 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "id"))
+		return
+	}
+
+	webhook := metadata.AdmissionWebhook{}
+	if err := ctx.DecodeInto(&webhook); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	result, ccErr := s.core.AdmissionOperation().UpdateAdmissionWebhook(ctx.Kit, id, webhook)
+	if ccErr != nil {
+		blog.Errorf("UpdateAdmissionWebhook failed, id: %d, err: %v, rid: %s", id, ccErr, ctx.Kit.Rid)
+		ctx.RespAutoError(ccErr)
+		return
+	}
+	ctx.RespEntity(result)
+}
+
+func (s *coreService) DeleteAdmissionWebhook(ctx *rest.Contexts) {
+	id, err := strconv.ParseInt(ctx.Request.PathParameter("id"), 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "id"))
+		return
+	}
+
+	if err := s.core.AdmissionOperation().DeleteAdmissionWebhook(ctx.Kit, id); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(nil)
+}
+
+func (s *coreService) SearchAdmissionWebhooks(ctx *rest.Contexts) {
+	option := metadata.SearchAdmissionWebhookOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	result, err := s.core.AdmissionOperation().SearchAdmissionWebhooks(ctx.Kit, option)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(result)
+}
+
+func (s *coreService) SearchAdmissionWebhookLogs(ctx *rest.Contexts) {
+	option := metadata.SearchAdmissionWebhookLogOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	result, err := s.core.AdmissionOperation().SearchAdmissionWebhookLogs(ctx.Kit, option)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(result)
+}
@@ -0,0 +1,167 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"strconv"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+)
+
+func (s *coreService) CreateApprovalPolicy(ctx *rest.Contexts) {
+	policy := metadata.ApprovalPolicy{}
+	if err := ctx.DecodeInto(&policy); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	result, err := s.core.ApprovalOperation().CreateApprovalPolicy(ctx.Kit, policy)
+	if err != nil {
+		blog.Errorf("CreateApprovalPolicy failed, name: %s, err: %v, rid: %s", policy.Name, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(result)
+}
+
+func (s *coreService) UpdateApprovalPolicy(ctx *rest.Contexts) {
+	id, err := strconv.ParseInt(ctx.Request.PathParameter("id"), 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "id"))
+		return
+	}
+
+	policy := metadata.ApprovalPolicy{}
+	if err := ctx.DecodeInto(&policy); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	result, ccErr := s.core.ApprovalOperation().UpdateApprovalPolicy(ctx.Kit, id, policy)
+	if ccErr != nil {
+		blog.Errorf("UpdateApprovalPolicy failed, id: %d, err: %v, rid: %s", id, ccErr, ctx.Kit.Rid)
+		ctx.RespAutoError(ccErr)
+		return
+	}
+	ctx.RespEntity(result)
+}
+
+func (s *coreService) DeleteApprovalPolicy(ctx *rest.Contexts) {
+	id, err := strconv.ParseInt(ctx.Request.PathParameter("id"), 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "id"))
+		return
+	}
+
+	if err := s.core.ApprovalOperation().DeleteApprovalPolicy(ctx.Kit, id); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(nil)
+}
+
+func (s *coreService) SearchApprovalPolicies(ctx *rest.Contexts) {
+	option := metadata.SearchApprovalPolicyOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	result, err := s.core.ApprovalOperation().SearchApprovalPolicies(ctx.Kit, option)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(result)
+}
+
+func (s *coreService) CreateApprovalRequest(ctx *rest.Contexts) {
+	request := metadata.ApprovalRequest{}
+	if err := ctx.DecodeInto(&request); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	result, err := s.core.ApprovalOperation().CreateApprovalRequest(ctx.Kit, request)
+	if err != nil {
+		blog.Errorf("CreateApprovalRequest failed, operation: %s, err: %v, rid: %s", request.Operation, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(result)
+}
+
+func (s *coreService) GetApprovalRequest(ctx *rest.Contexts) {
+	id, err := strconv.ParseInt(ctx.Request.PathParameter("id"), 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "id"))
+		return
+	}
+
+	result, ccErr := s.core.ApprovalOperation().GetApprovalRequest(ctx.Kit, id)
+	if ccErr != nil {
+		ctx.RespAutoError(ccErr)
+		return
+	}
+	ctx.RespEntity(result)
+}
+
+func (s *coreService) SearchApprovalRequests(ctx *rest.Contexts) {
+	option := metadata.SearchApprovalRequestOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	result, err := s.core.ApprovalOperation().SearchApprovalRequests(ctx.Kit, option)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(result)
+}
+
+func (s *coreService) UpdateApprovalRequestStatus(ctx *rest.Contexts) {
+	id, err := strconv.ParseInt(ctx.Request.PathParameter("id"), 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "id"))
+		return
+	}
+
+	option := metadata.UpdateApprovalStatusOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	result, ccErr := s.core.ApprovalOperation().UpdateApprovalRequestStatus(ctx.Kit, id, option)
+	if ccErr != nil {
+		blog.Errorf("UpdateApprovalRequestStatus failed, id: %d, option: %+v, err: %v, rid: %s", id, option, ccErr,
+			ctx.Kit.Rid)
+		ctx.RespAutoError(ccErr)
+		return
+	}
+	ctx.RespEntity(result)
+}
+
+func (s *coreService) ExpireApprovalRequests(ctx *rest.Contexts) {
+	result, err := s.core.ApprovalOperation().ExpireApprovalRequests(ctx.Kit)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(result)
+}
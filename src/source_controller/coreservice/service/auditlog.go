@@ -43,12 +43,16 @@ func (s *coreService) SearchAuditLog(ctx *rest.Contexts) {
 		return
 	}
 
-	auditLogs, count, err := s.core.AuditOperation().SearchAuditLog(ctx.Kit, inputData)
+	auditLogs, count, nextCursor, err := s.core.AuditOperation().SearchAuditLog(ctx.Kit, inputData)
 	if err != nil {
 		blog.Errorf("SearchAuditLog err:%v, rid:%s", err, ctx.Kit.Rid)
 		ctx.RespAutoError(ctx.Kit.CCError.CCError(common.CCErrAuditSelectFailed))
 		return
 	}
 
-	ctx.RespEntityWithCount(int64(count), auditLogs)
+	ctx.RespEntity(metadata.AuditQueryData{
+		Count:      int64(count),
+		Info:       auditLogs,
+		NextCursor: nextCursor,
+	})
 }
@@ -0,0 +1,104 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"strconv"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/http/rest"
+	"configcenter/src/common/metadata"
+)
+
+func (s *coreService) CreateChangeFreezeWindow(ctx *rest.Contexts) {
+	window := metadata.ChangeFreezeWindow{}
+	if err := ctx.DecodeInto(&window); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	result, err := s.core.ChangeFreezeOperation().CreateChangeFreezeWindow(ctx.Kit, window)
+	if err != nil {
+		blog.Errorf("CreateChangeFreezeWindow failed, name: %s, err: %v, rid: %s", window.Name, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(result)
+}
+
+func (s *coreService) UpdateChangeFreezeWindow(ctx *rest.Contexts) {
+	id, err := strconv.ParseInt(ctx.Request.PathParameter("id"), 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "id"))
+		return
+	}
+
+	window := metadata.ChangeFreezeWindow{}
+	if err := ctx.DecodeInto(&window); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	result, ccErr := s.core.ChangeFreezeOperation().UpdateChangeFreezeWindow(ctx.Kit, id, window)
+	if ccErr != nil {
+		blog.Errorf("UpdateChangeFreezeWindow failed, id: %d, err: %v, rid: %s", id, ccErr, ctx.Kit.Rid)
+		ctx.RespAutoError(ccErr)
+		return
+	}
+	ctx.RespEntity(result)
+}
+
+func (s *coreService) DeleteChangeFreezeWindow(ctx *rest.Contexts) {
+	id, err := strconv.ParseInt(ctx.Request.PathParameter("id"), 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "id"))
+		return
+	}
+
+	if err := s.core.ChangeFreezeOperation().DeleteChangeFreezeWindow(ctx.Kit, id); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(nil)
+}
+
+func (s *coreService) GetChangeFreezeWindow(ctx *rest.Contexts) {
+	id, err := strconv.ParseInt(ctx.Request.PathParameter("id"), 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "id"))
+		return
+	}
+
+	result, ccErr := s.core.ChangeFreezeOperation().GetChangeFreezeWindow(ctx.Kit, id)
+	if ccErr != nil {
+		ctx.RespAutoError(ccErr)
+		return
+	}
+	ctx.RespEntity(result)
+}
+
+func (s *coreService) SearchChangeFreezeWindows(ctx *rest.Contexts) {
+	option := metadata.SearchChangeFreezeWindowOption{}
+	if err := ctx.DecodeInto(&option); err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	result, err := s.core.ChangeFreezeOperation().SearchChangeFreezeWindows(ctx.Kit, option)
+	if err != nil {
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(result)
+}
@@ -194,11 +194,49 @@ func (s *coreService) GetHosts(ctx *rest.Contexts) {
 			blog.Errorf("SetModOwner failed condition %#v, error %s", condition, err.Error())
 		}
 	}
-	condition = util.SetModOwner(cond, ctx.Kit.SupplierAccount)
+	if err := metadata.ValidateQueryOperators(cond); err != nil {
+		blog.Errorf("get hosts with invalid condition, err: %v, rid: %s", err, ctx.Kit.Rid)
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "condition"))
+		return
+	}
+	if dat.Filter != nil {
+		attributes, err := s.SelectObjectAttWithParams(ctx.Kit, common.BKInnerObjIDHost, 0)
+		if err != nil {
+			ctx.RespAutoError(err)
+			return
+		}
+		fieldTypes := metadata.InstFilterFieldTypes(common.BKInnerObjIDHost, attributes)
+		merged, key, err := metadata.MergeQueryFilter(cond, dat.Filter, fieldTypes)
+		if err != nil {
+			blog.Errorf("get hosts with invalid filter, key: %s, err: %v, rid: %s", key, err, ctx.Kit.Rid)
+			ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, key))
+			return
+		}
+		cond = merged
+	}
+	cond = util.SetModOwner(cond, ctx.Kit.SupplierAccount)
+	condition = cond
 	fieldArr := util.SplitStrField(dat.Fields, ",")
 
+	page := metadata.BasePage{Sort: dat.Sort, Limit: dat.Limit, Start: dat.Start, Cursor: dat.Cursor}
+	findCond, sort := cond, dat.Sort
+	if page.IsCursorPage() {
+		if key, err := page.Validate(false); err != nil {
+			blog.Errorf("get hosts with invalid cursor page: %+v, err: %v, rid: %s", page, err, ctx.Kit.Rid)
+			ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, key))
+			return
+		}
+		cursor, err := page.ParseCursor(common.BKHostIDField)
+		if err != nil {
+			blog.Errorf("get hosts parse page cursor failed, page: %+v, err: %v, rid: %s", page, err, ctx.Kit.Rid)
+			ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "cursor"))
+			return
+		}
+		findCond, sort, fieldArr = cursor.MergeCondition(cond), cursor.Sort, cursor.Fields(fieldArr)
+	}
+
 	result := make([]metadata.HostMapStr, 0)
-	dbInst := mongodb.Client().Table(common.BKTableNameBaseHost).Find(condition).Sort(dat.Sort).Start(uint64(dat.Start)).Limit(uint64(dat.Limit))
+	dbInst := mongodb.Client().Table(common.BKTableNameBaseHost).Find(findCond).Sort(sort).Start(uint64(dat.Start)).Limit(uint64(dat.Limit))
 	if 0 < len(fieldArr) {
 		dbInst.Fields(fieldArr...)
 	}
@@ -224,9 +262,21 @@ func (s *coreService) GetHosts(ctx *rest.Contexts) {
 	for index, host := range result {
 		info[index] = mapstr.MapStr(host)
 	}
+
+	nextCursor := ""
+	if len(result) > 0 {
+		var err error
+		nextCursor, err = page.NextCursor(common.BKHostIDField, len(result), result[len(result)-1])
+		if err != nil {
+			blog.Errorf("get hosts generate next cursor failed, err: %v, rid: %s", err, ctx.Kit.Rid)
+			ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, "sort"))
+			return
+		}
+	}
 	ctx.RespEntity(metadata.HostInfo{
-		Count: int(finalCount),
-		Info:  info,
+		Count:      int(finalCount),
+		Info:       info,
+		NextCursor: nextCursor,
 	})
 }
 
@@ -15,6 +15,7 @@ package service
 import (
 	"configcenter/src/common"
 	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
 	"configcenter/src/common/http/rest"
 	"configcenter/src/common/mapstr"
 	"configcenter/src/common/metadata"
@@ -106,3 +107,8 @@ func (s *coreService) SearchUnique(kit *rest.Kit, objID string) (uniqueAttr []me
 func (s *coreService) UpdateModelInstance(kit *rest.Kit, objID string, param metadata.UpdateOption) (*metadata.UpdatedCount, error) {
 	return s.core.InstanceOperation().UpdateModelInstance(kit, objID, param)
 }
+
+func (s *coreService) Admit(kit *rest.Kit, objID string, operation metadata.AdmissionOperation, object mapstr.MapStr,
+	oldObjects []mapstr.MapStr) (mapstr.MapStr, bool, errors.CCErrorCoder) {
+	return s.core.AdmissionOperation().Admit(kit, objID, operation, object, oldObjects)
+}
@@ -23,9 +23,12 @@ import (
 	"configcenter/src/common/util"
 	"configcenter/src/source_controller/coreservice/app/options"
 	"configcenter/src/source_controller/coreservice/core"
+	"configcenter/src/source_controller/coreservice/core/admission"
+	"configcenter/src/source_controller/coreservice/core/approval"
 	"configcenter/src/source_controller/coreservice/core/association"
 	"configcenter/src/source_controller/coreservice/core/auditlog"
 	"configcenter/src/source_controller/coreservice/core/auth"
+	"configcenter/src/source_controller/coreservice/core/changefreeze"
 	"configcenter/src/source_controller/coreservice/core/cloud"
 	coreCommon "configcenter/src/source_controller/coreservice/core/common"
 	"configcenter/src/source_controller/coreservice/core/datasynchronize"
@@ -122,6 +125,9 @@ func (s *coreService) SetConfig(cfg options.Config, engine *backbone.Engine, err
 		auth.New(mongodb.Client()),
 		e.New(mongodb.Client(), redis.Client()),
 		coreCommon.New(),
+		admission.New(mongodb.Client()),
+		approval.New(mongodb.Client()),
+		changefreeze.New(mongodb.Client()),
 	)
 	return nil
 }
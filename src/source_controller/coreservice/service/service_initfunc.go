@@ -363,6 +363,10 @@ func (s *coreService) initAuth(web *restful.WebService) {
 	})
 
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/search/auth/resource", Handler: s.SearchAuthResource})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/create/auth/api_token", Handler: s.CreateAPIToken})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/auth/api_token", Handler: s.ListAPITokens})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/auth/api_token/{id}/revoke", Handler: s.RevokeAPIToken})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/auth/api_token/{id}/last_used", Handler: s.UpdateAPITokenLastUsed})
 
 	utility.AddToRestfulWebService(web)
 }
@@ -392,6 +396,55 @@ func (s *coreService) initCommon(web *restful.WebService) {
 	utility.AddToRestfulWebService(web)
 }
 
+func (s *coreService) initAdmission(web *restful.WebService) {
+	utility := rest.NewRestUtility(rest.Config{
+		ErrorIf:  s.engine.CCErr,
+		Language: s.engine.Language,
+	})
+
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/create/admission/webhook", Handler: s.CreateAdmissionWebhook})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/admission/webhook/{id}", Handler: s.UpdateAdmissionWebhook})
+	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/delete/admission/webhook/{id}", Handler: s.DeleteAdmissionWebhook})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/admission/webhook", Handler: s.SearchAdmissionWebhooks})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/admission/webhook/log", Handler: s.SearchAdmissionWebhookLogs})
+
+	utility.AddToRestfulWebService(web)
+}
+
+func (s *coreService) initApproval(web *restful.WebService) {
+	utility := rest.NewRestUtility(rest.Config{
+		ErrorIf:  s.engine.CCErr,
+		Language: s.engine.Language,
+	})
+
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/create/approval/policy", Handler: s.CreateApprovalPolicy})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/approval/policy/{id}", Handler: s.UpdateApprovalPolicy})
+	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/delete/approval/policy/{id}", Handler: s.DeleteApprovalPolicy})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/approval/policy", Handler: s.SearchApprovalPolicies})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/create/approval/request", Handler: s.CreateApprovalRequest})
+	utility.AddHandler(rest.Action{Verb: http.MethodGet, Path: "/find/approval/request/{id}", Handler: s.GetApprovalRequest})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/approval/request", Handler: s.SearchApprovalRequests})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/approval/request/{id}/status", Handler: s.UpdateApprovalRequestStatus})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/approval/request/expire", Handler: s.ExpireApprovalRequests})
+
+	utility.AddToRestfulWebService(web)
+}
+
+func (s *coreService) initChangeFreeze(web *restful.WebService) {
+	utility := rest.NewRestUtility(rest.Config{
+		ErrorIf:  s.engine.CCErr,
+		Language: s.engine.Language,
+	})
+
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/create/change_freeze/window", Handler: s.CreateChangeFreezeWindow})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/change_freeze/window/{id}", Handler: s.UpdateChangeFreezeWindow})
+	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/delete/change_freeze/window/{id}", Handler: s.DeleteChangeFreezeWindow})
+	utility.AddHandler(rest.Action{Verb: http.MethodGet, Path: "/find/change_freeze/window/{id}", Handler: s.GetChangeFreezeWindow})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/change_freeze/window", Handler: s.SearchChangeFreezeWindows})
+
+	utility.AddToRestfulWebService(web)
+}
+
 func (s *coreService) initService(web *restful.WebService) {
 	s.initModelClassification(web)
 	s.initModel(web)
@@ -417,4 +470,7 @@ func (s *coreService) initService(web *restful.WebService) {
 	s.initAuth(web)
 	s.initEvent(web)
 	s.initCommon(web)
+	s.initAdmission(web)
+	s.initApproval(web)
+	s.initChangeFreeze(web)
 }
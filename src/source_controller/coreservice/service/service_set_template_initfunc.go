@@ -38,6 +38,9 @@ func (s *coreService) initSetTemplate(web *restful.WebService) {
 	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/topo/set_template_sync_history/bk_biz_id/{bk_biz_id}", Handler: s.ListSetTemplateSyncHistory})
 	utility.AddHandler(rest.Action{Verb: http.MethodDelete, Path: "/deletemany/topo/set_template_sync_status/bk_biz_id/{bk_biz_id}", Handler: s.DeleteSetTemplateSyncStatus})
 	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/topo/set_template_sync_status/bk_set_id/{bk_set_id}/status/{status}", Handler: s.ModifySetTemplateSyncStatus})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/topo/set_template_sync_policy/{set_template_id}/bk_biz_id/{bk_biz_id}", Handler: s.UpdateSetTemplateSyncPolicy})
+	utility.AddHandler(rest.Action{Verb: http.MethodPost, Path: "/findmany/topo/set_template_sync_policy", Handler: s.ListSetTemplateSyncPolicy})
+	utility.AddHandler(rest.Action{Verb: http.MethodPut, Path: "/update/topo/set_template_sync_result/{set_template_id}/bk_biz_id/{bk_biz_id}", Handler: s.UpdateSetTemplateSyncResult})
 
 	utility.AddToRestfulWebService(web)
 }
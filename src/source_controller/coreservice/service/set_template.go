@@ -356,3 +356,78 @@ func (s *coreService) ModifySetTemplateSyncStatus(ctx *rest.Contexts) {
 	}
 	ctx.RespEntity(nil)
 }
+
+func (s *coreService) UpdateSetTemplateSyncPolicy(ctx *rest.Contexts) {
+	bizIDStr := ctx.Request.PathParameter(common.BKAppIDField)
+	bizID, err := strconv.ParseInt(bizIDStr, 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKAppIDField))
+		return
+	}
+
+	setTemplateIDStr := ctx.Request.PathParameter(common.BKSetTemplateIDField)
+	setTemplateID, err := strconv.ParseInt(setTemplateIDStr, 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKSetTemplateIDField))
+		return
+	}
+
+	option := metadata.UpdateSetTemplateSyncPolicyOption{}
+	if err := ctx.DecodeInto(&option); nil != err {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	policy, ccErr := s.core.SetTemplateOperation().UpdateSetTemplateSyncPolicy(ctx.Kit, bizID, setTemplateID, option)
+	if ccErr != nil {
+		blog.Errorf("UpdateSetTemplateSyncPolicy failed, bizID: %d, setTemplateID: %d, option: %+v, err: %+v, rid: %s", bizID, setTemplateID, option, ccErr, ctx.Kit.Rid)
+		ctx.RespAutoError(ccErr)
+		return
+	}
+	ctx.RespEntity(policy)
+}
+
+func (s *coreService) ListSetTemplateSyncPolicy(ctx *rest.Contexts) {
+	option := metadata.ListSetTemplateSyncPolicyOption{}
+	if err := ctx.DecodeInto(&option); nil != err {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	result, err := s.core.SetTemplateOperation().ListSetTemplateSyncPolicy(ctx.Kit, option)
+	if err != nil {
+		blog.Errorf("ListSetTemplateSyncPolicy failed, option: %+v, err: %+v, rid: %s", option, err, ctx.Kit.Rid)
+		ctx.RespAutoError(err)
+		return
+	}
+	ctx.RespEntity(result)
+}
+
+func (s *coreService) UpdateSetTemplateSyncResult(ctx *rest.Contexts) {
+	bizIDStr := ctx.Request.PathParameter(common.BKAppIDField)
+	bizID, err := strconv.ParseInt(bizIDStr, 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKAppIDField))
+		return
+	}
+
+	setTemplateIDStr := ctx.Request.PathParameter(common.BKSetTemplateIDField)
+	setTemplateID, err := strconv.ParseInt(setTemplateIDStr, 10, 64)
+	if err != nil {
+		ctx.RespAutoError(ctx.Kit.CCError.CCErrorf(common.CCErrCommParamsInvalid, common.BKSetTemplateIDField))
+		return
+	}
+
+	option := metadata.UpdateSetTemplateSyncResultOption{}
+	if err := ctx.DecodeInto(&option); nil != err {
+		ctx.RespAutoError(err)
+		return
+	}
+
+	if ccErr := s.core.SetTemplateOperation().UpdateSetTemplateSyncResult(ctx.Kit, bizID, setTemplateID, option); ccErr != nil {
+		blog.Errorf("UpdateSetTemplateSyncResult failed, bizID: %d, setTemplateID: %d, err: %+v, rid: %s", bizID, setTemplateID, ccErr, ctx.Kit.Rid)
+		ctx.RespAutoError(ccErr)
+		return
+	}
+	ctx.RespEntity(nil)
+}
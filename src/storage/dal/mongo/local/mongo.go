@@ -224,7 +224,7 @@ type Find struct {
 	filter     types.Filter
 	start      int64
 	limit      int64
-	sort       map[string]interface{}
+	sort       bson.D
 }
 
 // Fields 查询字段
@@ -242,30 +242,39 @@ func (f *Find) Fields(fields ...string) types.Find {
 func (f *Find) Sort(sort string) types.Find {
 	if sort != "" {
 		sortArr := strings.Split(sort, ",")
-		f.sort = make(map[string]interface{}, 0)
+		// use ordered document, the order of the sort keys matters when sorting by multiple keys
+		f.sort = make(bson.D, 0, len(sortArr))
 		for _, sortItem := range sortArr {
 			sortItemArr := strings.Split(sortItem, ":")
 			sortKey := strings.TrimLeft(sortItemArr[0], "+-")
+			sortValue := 1
 			if len(sortItemArr) == 2 {
 				sortDescFlag := strings.TrimSpace(sortItemArr[1])
 				if sortDescFlag == "-1" {
-					f.sort[sortKey] = -1
-				} else {
-					f.sort[sortKey] = 1
+					sortValue = -1
 				}
 			} else {
 				if strings.HasPrefix(sortItemArr[0], "-") {
-					f.sort[sortKey] = -1
-				} else {
-					f.sort[sortKey] = 1
+					sortValue = -1
 				}
 			}
+			f.sort = setSortKey(f.sort, sortKey, sortValue)
 		}
 	}
 
 	return f
 }
 
+func setSortKey(sort bson.D, key string, value int) bson.D {
+	for idx := range sort {
+		if sort[idx].Key == key {
+			sort[idx].Value = value
+			return sort
+		}
+	}
+	return append(sort, bson.E{Key: key, Value: value})
+}
+
 // Start 查询上标
 func (f *Find) Start(start uint64) types.Find {
 	// change to int64,后续改成int64
@@ -521,6 +530,8 @@ func (c *Collection) tryArchiveDeletedDoc(ctx context.Context, filter types.Filt
 	case common.BKTableNameBaseInst:
 	case common.BKTableNameBaseProcess:
 	case common.BKTableNameProcessInstanceRelation:
+	case common.BKTableNameServiceInstance:
+	case common.BKTableNameHostSnapshotDrift:
 	default:
 		// do not archive the delete docs
 		return nil
@@ -0,0 +1,204 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"configcenter/src/apimachinery"
+	"configcenter/src/common"
+	"configcenter/src/common/mapstr"
+	"configcenter/src/common/metadata"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(NewExportCommand())
+}
+
+type exportConf struct {
+	file            string
+	objID           string
+	fields          []string
+	sort            string
+	pageSize        int
+	user            string
+	supplierAccount string
+}
+
+func NewExportCommand() *cobra.Command {
+	conf := new(exportConf)
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "export all the data page by page with cursor, each line of the output is a json record",
+		Run: func(cmd *cobra.Command, args []string) {
+			_ = cmd.Help()
+		},
+	}
+
+	subCmds := make([]*cobra.Command, 0)
+
+	instCmd := &cobra.Command{
+		Use:   "instance",
+		Short: "export the instances of the object, host is also supported",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runExport(conf, exportInstance)
+		},
+	}
+	instCmd.Flags().StringVar(&conf.objID, "object", common.BKInnerObjIDHost, "the object id of the instances to export")
+	subCmds = append(subCmds, instCmd)
+
+	auditCmd := &cobra.Command{
+		Use:   "audit",
+		Short: "export the audit logs",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runExport(conf, exportAuditLog)
+		},
+	}
+	subCmds = append(subCmds, auditCmd)
+
+	for _, subCmd := range subCmds {
+		cmd.AddCommand(subCmd)
+	}
+	conf.addFlags(cmd)
+
+	return cmd
+}
+
+func (c *exportConf) addFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVarP(&c.file, "file", "f", "", "the output file path, print to stdout if it's empty")
+	cmd.PersistentFlags().StringSliceVar(&c.fields, "fields", []string{}, "the fields to export, all the fields are exported by default")
+	cmd.PersistentFlags().StringVar(&c.sort, "sort", "", "the sort field, support at most one field besides id, e.g: -create_time")
+	cmd.PersistentFlags().IntVar(&c.pageSize, "page-size", 500, "the number of records fetched in one request")
+	cmd.PersistentFlags().StringVar(&c.user, "user", "admin", "the name of the user who exports the data")
+	cmd.PersistentFlags().StringVar(&c.supplierAccount, "supplier-account", "0", "the supplier id that this user belongs to")
+}
+
+type exportService struct {
+	clientSet apimachinery.ClientSetInterface
+	header    http.Header
+	conf      *exportConf
+}
+
+// exportPageFunc fetch one page of data by the cursor, returns the records and the next cursor
+type exportPageFunc func(srv *exportService, page metadata.BasePage) ([]interface{}, string, error)
+
+func runExport(c *exportConf, fetch exportPageFunc) error {
+	if c.pageSize <= 0 || c.pageSize > common.BKMaxPageSize {
+		return fmt.Errorf("page-size must between 1 and %d", common.BKMaxPageSize)
+	}
+
+	clientSet, err := newClientSet()
+	if err != nil {
+		return err
+	}
+	srv := &exportService{
+		clientSet: clientSet,
+		header:    newHeader(c.user, c.supplierAccount),
+		conf:      c,
+	}
+
+	var out io.Writer = os.Stdout
+	if c.file != "" {
+		file, err := os.Create(c.file)
+		if err != nil {
+			return fmt.Errorf("create file %s failed, err: %v", c.file, err)
+		}
+		defer file.Close()
+		out = file
+	}
+	writer := bufio.NewWriter(out)
+	defer writer.Flush()
+	encoder := json.NewEncoder(writer)
+
+	total := 0
+	page := metadata.BasePage{Sort: c.sort, Limit: c.pageSize, Cursor: metadata.CursorFirstPage}
+	for {
+		records, next, err := fetch(srv, page)
+		if err != nil {
+			return err
+		}
+		for _, record := range records {
+			if err := encoder.Encode(record); err != nil {
+				return err
+			}
+		}
+		total += len(records)
+		if next == "" {
+			break
+		}
+		page.Cursor = next
+	}
+
+	if c.file != "" {
+		fmt.Printf("export %d records to %s\n", total, c.file)
+	}
+	return nil
+}
+
+func exportInstance(srv *exportService, page metadata.BasePage) ([]interface{}, string, error) {
+	if srv.conf.objID == "" {
+		return nil, "", errors.New("object must be set")
+	}
+
+	input := &metadata.QueryCondition{
+		Condition:      mapstr.MapStr{},
+		Fields:         srv.conf.fields,
+		Page:           page,
+		DisableCounter: true,
+	}
+	rsp, err := srv.clientSet.CoreService().Instance().ReadInstance(context.Background(), srv.header,
+		srv.conf.objID, input)
+	if err != nil {
+		return nil, "", err
+	}
+	if !rsp.Result {
+		return nil, "", errors.New(rsp.ErrMsg)
+	}
+
+	records := make([]interface{}, len(rsp.Data.Info))
+	for idx := range rsp.Data.Info {
+		records[idx] = rsp.Data.Info[idx]
+	}
+	return records, rsp.Data.NextCursor, nil
+}
+
+func exportAuditLog(srv *exportService, page metadata.BasePage) ([]interface{}, string, error) {
+	input := metadata.QueryCondition{
+		Condition: mapstr.MapStr{},
+		Fields:    srv.conf.fields,
+		Page:      page,
+	}
+	rsp, err := srv.clientSet.CoreService().Audit().SearchAuditLog(context.Background(), srv.header, input)
+	if err != nil {
+		return nil, "", err
+	}
+	if !rsp.Result {
+		return nil, "", errors.New(rsp.ErrMsg)
+	}
+
+	records := make([]interface{}, len(rsp.Data.Info))
+	for idx := range rsp.Data.Info {
+		records[idx] = rsp.Data.Info[idx]
+	}
+	return records, rsp.Data.NextCursor, nil
+}
@@ -0,0 +1,133 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"configcenter/src/common/metadata"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(NewInventoryCommand())
+}
+
+type inventoryConf struct {
+	option             metadata.HostInventoryOption
+	bizIDs             []int
+	setIDs             []int
+	moduleIDs          []int
+	serviceTemplateIDs []int
+	list               bool
+	host               string
+	user               string
+	supplierAccount    string
+}
+
+func NewInventoryCommand() *cobra.Command {
+	conf := new(inventoryConf)
+
+	cmd := &cobra.Command{
+		Use:   "inventory",
+		Short: "print the ansible dynamic inventory or the ssh config of the hosts in the businesses",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runInventory(conf)
+		},
+	}
+	conf.addFlags(cmd)
+
+	return cmd
+}
+
+func (c *inventoryConf) addFlags(cmd *cobra.Command) {
+	cmd.Flags().IntSliceVar(&c.bizIDs, "biz-id", []int{}, "the ids of the businesses whose hosts are in the inventory")
+	cmd.Flags().IntSliceVar(&c.setIDs, "set-id", []int{}, "only the hosts in these sets are in the inventory")
+	cmd.Flags().IntSliceVar(&c.moduleIDs, "module-id", []int{}, "only the hosts in these modules are in the inventory")
+	cmd.Flags().IntSliceVar(&c.serviceTemplateIDs, "service-template-id", []int{}, "only the hosts in the modules of these service templates are in the inventory")
+	cmd.Flags().StringSliceVar(&c.option.HostVars, "host-vars", []string{}, "the host attributes added to the host vars")
+	cmd.Flags().StringVar(&c.option.Format, "format", metadata.HostInventoryAnsible, "the output format, ansible or ssh_config")
+	cmd.Flags().StringVar(&c.option.SSHUser, "ssh-user", "", "the User of the hosts in the ssh config")
+	cmd.Flags().Int64Var(&c.option.SSHPort, "ssh-port", 0, "the Port of the hosts in the ssh config")
+	cmd.Flags().BoolVar(&c.list, "list", false, "print the whole ansible inventory, it's the default behavior and is only for the ansible inventory script protocol")
+	cmd.Flags().StringVar(&c.host, "host", "", "only print the vars of the host in the ansible inventory")
+	cmd.Flags().StringVar(&c.user, "user", "admin", "the name of the user whose permissions are used to get the hosts")
+	cmd.Flags().StringVar(&c.supplierAccount, "supplier-account", "0", "the supplier id that this user belongs to")
+}
+
+func toInt64Slice(ids []int) []int64 {
+	result := make([]int64, len(ids))
+	for idx, id := range ids {
+		result[idx] = int64(id)
+	}
+	return result
+}
+
+func runInventory(c *inventoryConf) error {
+	c.option.BizIDs = toInt64Slice(c.bizIDs)
+	c.option.SetIDs = toInt64Slice(c.setIDs)
+	c.option.ModuleIDs = toInt64Slice(c.moduleIDs)
+	c.option.ServiceTemplateIDs = toInt64Slice(c.serviceTemplateIDs)
+	if len(c.option.BizIDs) == 0 {
+		return errors.New("business ids must be set via biz-id flag")
+	}
+	if c.list && c.host != "" {
+		return errors.New("list and host flags can not be set at the same time")
+	}
+	if c.host != "" && c.option.Format != metadata.HostInventoryAnsible {
+		return errors.New("host flag can only be used with the ansible format")
+	}
+
+	clientSet, err := newClientSet()
+	if err != nil {
+		return err
+	}
+	header := newHeader(c.user, c.supplierAccount)
+	resp, err := clientSet.HostServer().HostInventory(context.Background(), header, &c.option)
+	if err != nil {
+		return err
+	}
+	if !resp.Result {
+		return fmt.Errorf("get host inventory failed, code: %d, err: %s", resp.Code, resp.ErrMsg)
+	}
+
+	if c.option.Format == metadata.HostInventorySSHConfig {
+		fmt.Print(resp.Data.SSHConfig)
+		return nil
+	}
+
+	inventory := resp.Data.Ansible
+	if inventory == nil {
+		inventory = &metadata.AnsibleInventory{}
+	}
+	var data []byte
+	if c.host != "" {
+		// ansible calls the script with --host for each host only if the _meta is missing, return the vars anyway
+		vars, exists := inventory.HostVars[c.host]
+		if !exists {
+			vars = make(map[string]interface{})
+		}
+		data, err = json.MarshalIndent(vars, "", "    ")
+	} else {
+		data, err = json.MarshalIndent(inventory, "", "    ")
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	return nil
+}
@@ -0,0 +1,284 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"configcenter/src/apimachinery"
+	"configcenter/src/common/metadata"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+func init() {
+	rootCmd.AddCommand(NewSchemaCommand())
+}
+
+type schemaConf struct {
+	file            string
+	objects         []string
+	prune           bool
+	user            string
+	supplierAccount string
+}
+
+func NewSchemaCommand() *cobra.Command {
+	conf := new(schemaConf)
+
+	cmd := &cobra.Command{
+		Use:   "schema",
+		Short: "manage the model with a declarative schema file",
+		Run: func(cmd *cobra.Command, args []string) {
+			_ = cmd.Help()
+		},
+	}
+
+	subCmds := make([]*cobra.Command, 0)
+
+	exportCmd := &cobra.Command{
+		Use:   "export",
+		Short: "export the live model as a schema file",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSchemaExport(conf)
+		},
+	}
+	exportCmd.Flags().StringSliceVar(&conf.objects, "objects", []string{}, "the objects to export, all the objects except the hidden ones are exported by default")
+	subCmds = append(subCmds, exportCmd)
+
+	planCmd := &cobra.Command{
+		Use:   "plan",
+		Short: "show the changes to make the live model the same as the schema file",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSchemaPlan(conf, false)
+		},
+	}
+	planCmd.Flags().BoolVar(&conf.prune, "prune", false, "delete the groups, attributes, uniques and associations of the declared objects not in the schema")
+	subCmds = append(subCmds, planCmd)
+
+	applyCmd := &cobra.Command{
+		Use:   "apply",
+		Short: "apply the changes of the schema file to the live model",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSchemaPlan(conf, true)
+		},
+	}
+	applyCmd.Flags().BoolVar(&conf.prune, "prune", false, "delete the groups, attributes, uniques and associations of the declared objects not in the schema")
+	subCmds = append(subCmds, applyCmd)
+
+	for _, subCmd := range subCmds {
+		cmd.AddCommand(subCmd)
+	}
+	conf.addFlags(cmd)
+
+	return cmd
+}
+
+func (c *schemaConf) addFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVarP(&c.file, "file", "f", "", "the schema file path, yaml or json format is decided by the file extension, export prints to stdout if it's empty")
+	cmd.PersistentFlags().StringVar(&c.user, "user", "admin", "the name of the user who operates the model")
+	cmd.PersistentFlags().StringVar(&c.supplierAccount, "supplier-account", "0", "the supplier id that this user belongs to")
+}
+
+type schemaService struct {
+	clientSet apimachinery.ClientSetInterface
+	header    http.Header
+}
+
+func newSchemaService(c *schemaConf) (*schemaService, error) {
+	clientSet, err := newClientSet()
+	if err != nil {
+		return nil, err
+	}
+
+	return &schemaService{
+		clientSet: clientSet,
+		header:    newHeader(c.user, c.supplierAccount),
+	}, nil
+}
+
+func runSchemaExport(c *schemaConf) error {
+	srv, err := newSchemaService(c)
+	if err != nil {
+		return err
+	}
+
+	option := &metadata.ExportModelSchemaOption{ObjectIDs: c.objects}
+	resp, err := srv.clientSet.TopoServer().Object().ExportModelSchema(context.Background(), srv.header, option)
+	if err != nil {
+		return err
+	}
+	if !resp.Result {
+		return fmt.Errorf("export model schema failed, code: %d, err: %s", resp.Code, resp.ErrMsg)
+	}
+
+	if c.file == "" {
+		data, err := yaml.Marshal(schemaToYaml(resp.Data))
+		if err != nil {
+			return err
+		}
+		fmt.Print(string(data))
+		return nil
+	}
+
+	var data []byte
+	if isYamlSchemaFile(c.file) {
+		data, err = yaml.Marshal(schemaToYaml(resp.Data))
+	} else {
+		data, err = json.MarshalIndent(resp.Data, "", "    ")
+	}
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(c.file, data, 0644); err != nil {
+		return fmt.Errorf("write schema file %s failed, err: %v", c.file, err)
+	}
+	fmt.Print(WithGreenColor(fmt.Sprintf("export %d objects to %s", len(resp.Data.Objects), c.file)))
+	return nil
+}
+
+func runSchemaPlan(c *schemaConf, apply bool) error {
+	if c.file == "" {
+		return errors.New("schema file must be set via file flag")
+	}
+	schema, err := readSchemaFile(c.file)
+	if err != nil {
+		return err
+	}
+
+	srv, err := newSchemaService(c)
+	if err != nil {
+		return err
+	}
+
+	option := &metadata.ModelSchemaOption{Schema: *schema, Prune: c.prune}
+	var resp *metadata.ModelSchemaPlanResult
+	if apply {
+		resp, err = srv.clientSet.TopoServer().Object().ApplyModelSchema(context.Background(), srv.header, option)
+	} else {
+		resp, err = srv.clientSet.TopoServer().Object().PlanModelSchema(context.Background(), srv.header, option)
+	}
+	if err != nil {
+		return err
+	}
+	if !resp.Result {
+		return fmt.Errorf("%s model schema failed, code: %d, err: %s", planOrApply(apply), resp.Code, resp.ErrMsg)
+	}
+
+	printSchemaPlan(resp.Data, apply)
+	return nil
+}
+
+func planOrApply(apply bool) string {
+	if apply {
+		return "apply"
+	}
+	return "plan"
+}
+
+func printSchemaPlan(plan metadata.ModelSchemaPlan, apply bool) {
+	if len(plan.Changes) == 0 {
+		fmt.Print(WithGreenColor("no changes, the live model is the same as the schema"))
+		return
+	}
+
+	for _, change := range plan.Changes {
+		line := change.String()
+		if len(change.Fields) != 0 {
+			line += fmt.Sprintf(" (%s)", strings.Join(change.Fields, ", "))
+		}
+		switch change.Action {
+		case metadata.SchemaChangeCreate:
+			fmt.Print(WithGreenColor(line))
+		case metadata.SchemaChangeUpdate:
+			fmt.Print(WithBlueColor(line))
+		case metadata.SchemaChangeDelete:
+			fmt.Print(WithRedColor(line))
+		}
+	}
+
+	if apply {
+		fmt.Printf("%d changes applied\n", len(plan.Changes))
+		return
+	}
+	fmt.Printf("%d changes to apply\n", len(plan.Changes))
+}
+
+func isYamlSchemaFile(file string) bool {
+	ext := strings.ToLower(filepath.Ext(file))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// readSchemaFile reads the schema from a yaml or json file, the yaml file uses the same field names as json.
+func readSchemaFile(file string) (*metadata.ModelSchema, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("read schema file %s failed, err: %v", file, err)
+	}
+
+	if isYamlSchemaFile(file) {
+		var raw interface{}
+		if err := yaml.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("parse yaml schema file %s failed, err: %v", file, err)
+		}
+		data, err = json.Marshal(yamlToJson(raw))
+		if err != nil {
+			return nil, fmt.Errorf("parse yaml schema file %s failed, err: %v", file, err)
+		}
+	}
+
+	schema := new(metadata.ModelSchema)
+	if err := json.Unmarshal(data, schema); err != nil {
+		return nil, fmt.Errorf("parse schema file %s failed, err: %v", file, err)
+	}
+	return schema, nil
+}
+
+// yamlToJson converts the yaml maps with interface keys to the json maps with string keys.
+func yamlToJson(val interface{}) interface{} {
+	switch v := val.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			m[fmt.Sprint(key)] = yamlToJson(item)
+		}
+		return m
+	case []interface{}:
+		for idx, item := range v {
+			v[idx] = yamlToJson(item)
+		}
+		return v
+	}
+	return val
+}
+
+// schemaToYaml converts the schema to yaml maps keeping the json field names and order.
+func schemaToYaml(schema metadata.ModelSchema) interface{} {
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return schema
+	}
+	var raw yaml.MapSlice
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return schema
+	}
+	return raw
+}
@@ -14,6 +14,15 @@ package cmd
 
 import (
 	"fmt"
+	"net/http"
+	"time"
+
+	"configcenter/src/apimachinery"
+	"configcenter/src/apimachinery/discovery"
+	"configcenter/src/apimachinery/util"
+	"configcenter/src/common"
+	"configcenter/src/common/backbone/service_mange/zk"
+	"configcenter/src/tools/cmdb_ctl/app/config"
 )
 
 func WithRedColor(str string) string {
@@ -26,3 +35,36 @@ func WithGreenColor(str string) string {
 func WithBlueColor(str string) string {
 	return fmt.Sprintf("%c[1;40;34m>> %s %c[0m\n", 0x1B, str, 0x1B)
 }
+
+// newClientSet create the api client set by the services registered in zookeeper
+func newClientSet() (apimachinery.ClientSetInterface, error) {
+	client := zk.NewZkClient(config.Conf.ZkAddr, 40*time.Second)
+	if err := client.Start(); err != nil {
+		return nil, fmt.Errorf("connect regdiscv [%s] failed: %v", config.Conf.ZkAddr, err)
+	}
+	if err := client.Ping(); err != nil {
+		return nil, fmt.Errorf("connect regdiscv [%s] failed: %v", config.Conf.ZkAddr, err)
+	}
+	serviceDiscovery, err := discovery.NewServiceDiscovery(client)
+	if err != nil {
+		return nil, fmt.Errorf("connect regdiscv [%s] failed: %v", config.Conf.ZkAddr, err)
+	}
+	apiMachineryConfig := &util.APIMachineryConfig{
+		QPS:       1000,
+		Burst:     2000,
+		TLSConfig: nil,
+	}
+	clientSet, err := apimachinery.NewApiMachinery(apiMachineryConfig, serviceDiscovery)
+	if err != nil {
+		return nil, fmt.Errorf("new api machinery failed, err: %v", err)
+	}
+	return clientSet, nil
+}
+
+func newHeader(user, supplierAccount string) http.Header {
+	header := make(http.Header)
+	header.Add(common.BKHTTPOwnerID, supplierAccount)
+	header.Add(common.BKHTTPHeaderUser, user)
+	header.Add("Content-Type", "application/json")
+	return header
+}
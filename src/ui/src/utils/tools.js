@@ -319,6 +319,11 @@ export function transformHostSearchParams (params) {
     return transformedParams
 }
 
+// the regular expressions of the fuzzy search are anchored at the beginning, the special characters are escaped
+export function getPrefixRegex (value) {
+    return '^' + String(value).replace(/[.*+?^${}()|[\]\\]/g, '\\$&')
+}
+
 const defaultPaginationConfig = window.innerHeight > 750
     ? { limit: 20, 'limit-list': [20, 50, 100, 500] }
     : { limit: 10, 'limit-list': [10, 50, 100, 500] }
@@ -384,6 +389,7 @@ export default {
     getSort,
     getValue,
     transformHostSearchParams,
+    getPrefixRegex,
     getDefaultPaginationConfig,
     getPageParams,
     localSort,
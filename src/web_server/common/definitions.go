@@ -17,3 +17,9 @@ var ResourcePath string = "/tmp"
 const API_VERSION = "v3"
 
 const IsSkipLogin = "skiplogin"
+
+// LogoutContextKey marks the request is logging out, the login plugins return their logout url for it
+const LogoutContextKey = "cc_logout"
+
+// LoginRedirectSessionKey is the page to open after the single sign-on login callback
+const LoginRedirectSessionKey = "login_redirect_url"
@@ -61,8 +61,17 @@ func (lgc *Logics) GetObjFieldIDs(objID string, filterFields []string, customFie
 		return nil, fmt.Errorf("get object fields failed, err: %+v", err)
 	}
 
+	restricted, err := lgc.getNoPermRestrictedFields(objID, header)
+	if nil != err {
+		return nil, err
+	}
+
 	ret := make(map[string]Property)
 	for _, field := range fields {
+		// restricted fields that user has no permission to view are neither exported nor imported
+		if util.InStrArr(restricted, field.ID) {
+			continue
+		}
 		if util.InStrArr(filterFields, field.ID) {
 			field.NotExport = true
 		}
@@ -72,6 +81,22 @@ func (lgc *Logics) GetObjFieldIDs(objID string, filterFields []string, customFie
 	return ret, nil
 }
 
+// getNoPermRestrictedFields get the restricted fields of the object that user has no permission to view
+func (lgc *Logics) getNoPermRestrictedFields(objID string, header http.Header) ([]string, error) {
+	rid := util.GetHTTPCCRequestID(header)
+	result, err := lgc.Engine.CoreAPI.ApiServer().GetNoPermRestrictedFields(context.Background(), header, objID)
+	if nil != err {
+		blog.Errorf("get %s no permission restricted fields failed, err: %+v, rid: %s", objID, err, rid)
+		return nil, fmt.Errorf("get restricted fields failed, err: %+v", err)
+	}
+	if !result.Result {
+		blog.Errorf("get %s no permission restricted fields failed, error code: %d, error message: %s, rid: %s",
+			objID, result.Code, result.ErrMsg, rid)
+		return nil, fmt.Errorf("get restricted fields result false, result: %+v", result)
+	}
+	return result.Data, nil
+}
+
 func (lgc *Logics) getObjectGroup(objID string, header http.Header, modelBizID int64) ([]PropertyGroup, error) {
 	rid := util.GetHTTPCCRequestID(header)
 	ownerID := util.GetOwnerID(header)
@@ -0,0 +1,149 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ldap
+
+import (
+	"errors"
+	"strings"
+
+	cc "configcenter/src/common/backbone/configcenter"
+)
+
+const (
+	defaultUsernameAttribute    = "sAMAccountName"
+	defaultDisplayNameAttribute = "displayName"
+	defaultEmailAttribute       = "mail"
+	defaultPhoneAttribute       = "telephoneNumber"
+	defaultGroupAttribute       = "memberOf"
+	defaultUserFilter           = "(&(objectCategory=person)(objectClass=user)(sAMAccountName=%s))"
+	defaultUserListFilter       = "(&(objectCategory=person)(objectClass=user))"
+	defaultPoolSize             = 10
+	defaultTimeoutSeconds       = 10
+	defaultSessionTTLSeconds    = 24 * 60 * 60
+	defaultUserListCacheSeconds = 5 * 60
+	// defaultSearchPageSize is below the default MaxPageSize 1000 of active directory
+	defaultSearchPageSize = 500
+)
+
+// Config is the ldap login configuration at webServer.ldap in common.yaml
+type Config struct {
+	// URL is the directory address, like ldap://127.0.0.1:389 or ldaps://ad.example.com:636
+	URL string `mapstructure:"url"`
+	// StartTLS upgrades the ldap:// connection to tls
+	StartTLS           bool   `mapstructure:"startTLS"`
+	InsecureSkipVerify bool   `mapstructure:"insecureSkipVerify"`
+	CAFile             string `mapstructure:"caFile"`
+
+	// BindDN and BindPassword is the service account to search users, empty BindDN means anonymous search
+	BindDN       string `mapstructure:"bindDN"`
+	BindPassword string `mapstructure:"bindPassword"`
+	BaseDN       string `mapstructure:"baseDN"`
+	// UserFilter finds the login user, %s is replaced by the escaped user name
+	UserFilter string `mapstructure:"userFilter"`
+	// UserListFilter finds all the users that can be selected by the user fields
+	UserListFilter string `mapstructure:"userListFilter"`
+
+	UsernameAttribute    string `mapstructure:"usernameAttribute"`
+	DisplayNameAttribute string `mapstructure:"displayNameAttribute"`
+	EmailAttribute       string `mapstructure:"emailAttribute"`
+	PhoneAttribute       string `mapstructure:"phoneAttribute"`
+	GroupAttribute       string `mapstructure:"groupAttribute"`
+
+	// GroupMappings maps the directory groups of a user to the suppliers and roles, the first matched
+	// mapping decides the current supplier of the user.
+	GroupMappings []GroupMapping `mapstructure:"groupMappings"`
+	// RequireGroup denies the users not in any of the mapped groups
+	RequireGroup bool `mapstructure:"requireGroup"`
+
+	PoolSize             int `mapstructure:"poolSize"`
+	TimeoutSeconds       int `mapstructure:"timeout"`
+	SessionTTLSeconds    int `mapstructure:"sessionTTL"`
+	UserListCacheSeconds int `mapstructure:"userListCacheTTL"`
+}
+
+// GroupMapping maps a directory group to a supplier and role
+type GroupMapping struct {
+	// Group is the dn or the common name of the group
+	Group    string `mapstructure:"group"`
+	Supplier string `mapstructure:"supplier"`
+	Role     string `mapstructure:"role"`
+}
+
+// loadConfig loads the ldap configuration from common.yaml
+func loadConfig() (Config, error) {
+	conf := Config{}
+	if err := cc.UnmarshalKey("webServer.ldap", &conf); err != nil {
+		return conf, err
+	}
+	conf.setDefault()
+	return conf, conf.validate()
+}
+
+func (c *Config) setDefault() {
+	if len(c.UsernameAttribute) == 0 {
+		c.UsernameAttribute = defaultUsernameAttribute
+	}
+	if len(c.DisplayNameAttribute) == 0 {
+		c.DisplayNameAttribute = defaultDisplayNameAttribute
+	}
+	if len(c.EmailAttribute) == 0 {
+		c.EmailAttribute = defaultEmailAttribute
+	}
+	if len(c.PhoneAttribute) == 0 {
+		c.PhoneAttribute = defaultPhoneAttribute
+	}
+	if len(c.GroupAttribute) == 0 {
+		c.GroupAttribute = defaultGroupAttribute
+	}
+	if len(c.UserFilter) == 0 {
+		c.UserFilter = defaultUserFilter
+	}
+	if len(c.UserListFilter) == 0 {
+		c.UserListFilter = defaultUserListFilter
+	}
+	if c.PoolSize <= 0 {
+		c.PoolSize = defaultPoolSize
+	}
+	if c.TimeoutSeconds <= 0 {
+		c.TimeoutSeconds = defaultTimeoutSeconds
+	}
+	if c.SessionTTLSeconds <= 0 {
+		c.SessionTTLSeconds = defaultSessionTTLSeconds
+	}
+	if c.UserListCacheSeconds < 0 {
+		c.UserListCacheSeconds = 0
+	} else if c.UserListCacheSeconds == 0 {
+		c.UserListCacheSeconds = defaultUserListCacheSeconds
+	}
+}
+
+func (c *Config) validate() error {
+	if !strings.HasPrefix(c.URL, "ldap://") && !strings.HasPrefix(c.URL, "ldaps://") {
+		return errors.New("webServer.ldap.url should start with ldap:// or ldaps://")
+	}
+	if strings.HasPrefix(c.URL, "ldaps://") && c.StartTLS {
+		return errors.New("webServer.ldap.startTLS can not be used with ldaps://")
+	}
+	if len(c.BaseDN) == 0 {
+		return errors.New("webServer.ldap.baseDN is not set")
+	}
+	if strings.Count(c.UserFilter, "%s") != 1 {
+		return errors.New("webServer.ldap.userFilter should contain one %s for the user name")
+	}
+	for _, mapping := range c.GroupMappings {
+		if len(mapping.Group) == 0 || len(mapping.Supplier) == 0 {
+			return errors.New("webServer.ldap.groupMappings should have both group and supplier")
+		}
+	}
+	return nil
+}
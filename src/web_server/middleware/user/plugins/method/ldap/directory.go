@@ -0,0 +1,256 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ldap
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"configcenter/src/common"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/metadata"
+
+	ldapv3 "github.com/go-ldap/ldap/v3"
+)
+
+var errInvalidCredentials = errors.New("invalid user name or password")
+
+// ldapUser is the user entry in the directory
+type ldapUser struct {
+	DN          string
+	Name        string
+	DisplayName string
+	Email       string
+	Phone       string
+	Groups      []string
+}
+
+// directory authenticates and searches the users in the ldap directory
+type directory struct {
+	conf Config
+	pool *connPool
+
+	cacheLock sync.Mutex
+	users     []*ldapUser
+	expireAt  time.Time
+}
+
+func newDirectory(conf Config) (*directory, error) {
+	tlsConfig, err := newTLSConfig(conf)
+	if err != nil {
+		return nil, err
+	}
+	return &directory{
+		conf: conf,
+		pool: newConnPool(conf, tlsConfig),
+	}, nil
+}
+
+func newTLSConfig(conf Config) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: conf.InsecureSkipVerify,
+	}
+	if u, err := url.Parse(conf.URL); err == nil {
+		tlsConfig.ServerName = u.Hostname()
+	}
+	if len(conf.CAFile) == 0 {
+		return tlsConfig, nil
+	}
+
+	ca, err := ioutil.ReadFile(conf.CAFile)
+	if err != nil {
+		return nil, fmt.Errorf("read ldap ca file %s failed, err: %v", conf.CAFile, err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, fmt.Errorf("ldap ca file %s has no valid certificate", conf.CAFile)
+	}
+	tlsConfig.RootCAs = pool
+	return tlsConfig, nil
+}
+
+func (d *directory) close() {
+	d.pool.close()
+}
+
+func (d *directory) attributes() []string {
+	return []string{d.conf.UsernameAttribute, d.conf.DisplayNameAttribute, d.conf.EmailAttribute,
+		d.conf.PhoneAttribute, d.conf.GroupAttribute}
+}
+
+func (d *directory) newUser(entry *ldapv3.Entry) *ldapUser {
+	return &ldapUser{
+		DN:          entry.DN,
+		Name:        entry.GetEqualFoldAttributeValue(d.conf.UsernameAttribute),
+		DisplayName: entry.GetEqualFoldAttributeValue(d.conf.DisplayNameAttribute),
+		Email:       entry.GetEqualFoldAttributeValue(d.conf.EmailAttribute),
+		Phone:       entry.GetEqualFoldAttributeValue(d.conf.PhoneAttribute),
+		Groups:      entry.GetEqualFoldAttributeValues(d.conf.GroupAttribute),
+	}
+}
+
+// authenticate finds the user with the service account, then binds with the user's dn and password.
+func (d *directory) authenticate(userName, password string) (*ldapUser, error) {
+	if len(userName) == 0 || len(password) == 0 {
+		return nil, errInvalidCredentials
+	}
+
+	conn, err := d.pool.get()
+	if err != nil {
+		return nil, err
+	}
+
+	filter := fmt.Sprintf(d.conf.UserFilter, ldapv3.EscapeFilter(userName))
+	request := ldapv3.NewSearchRequest(d.conf.BaseDN, ldapv3.ScopeWholeSubtree, ldapv3.NeverDerefAliases, 2,
+		d.conf.TimeoutSeconds, false, filter, d.attributes(), nil)
+	result, err := conn.Search(request)
+	if err != nil {
+		blog.Errorf("search ldap user %s failed, filter: %s, err: %v", userName, filter, err)
+		conn.Close()
+		return nil, err
+	}
+	if len(result.Entries) != 1 {
+		blog.Errorf("search ldap user %s got %d entries, filter: %s", userName, len(result.Entries), filter)
+		d.pool.put(conn)
+		return nil, errInvalidCredentials
+	}
+	user := d.newUser(result.Entries[0])
+	if len(user.Name) == 0 {
+		user.Name = userName
+	}
+
+	bindErr := conn.Bind(user.DN, password)
+
+	// the connection is returned to the pool only if it's bound with the service account again
+	if err := d.pool.bindServiceAccount(conn); err != nil {
+		conn.Close()
+	} else {
+		d.pool.put(conn)
+	}
+
+	if bindErr != nil {
+		if ldapv3.IsErrorWithCode(bindErr, ldapv3.LDAPResultInvalidCredentials) {
+			return nil, errInvalidCredentials
+		}
+		blog.Errorf("bind ldap user %s failed, err: %v", user.DN, bindErr)
+		return nil, bindErr
+	}
+	return user, nil
+}
+
+// searchUsers returns all the users matching the user list filter, the result is cached for a while
+// since the user fields of all the models search the users.
+func (d *directory) searchUsers() ([]*ldapUser, error) {
+	d.cacheLock.Lock()
+	defer d.cacheLock.Unlock()
+	if d.users != nil && time.Now().Before(d.expireAt) {
+		return d.users, nil
+	}
+
+	conn, err := d.pool.get()
+	if err != nil {
+		return nil, err
+	}
+
+	request := ldapv3.NewSearchRequest(d.conf.BaseDN, ldapv3.ScopeWholeSubtree, ldapv3.NeverDerefAliases, 0,
+		d.conf.TimeoutSeconds, false, d.conf.UserListFilter, d.attributes(), nil)
+	result, err := conn.SearchWithPaging(request, defaultSearchPageSize)
+	if err != nil {
+		blog.Errorf("search ldap users failed, filter: %s, err: %v", d.conf.UserListFilter, err)
+		conn.Close()
+		return nil, err
+	}
+	d.pool.put(conn)
+
+	users := make([]*ldapUser, 0, len(result.Entries))
+	for _, entry := range result.Entries {
+		user := d.newUser(entry)
+		if len(user.Name) == 0 {
+			continue
+		}
+		users = append(users, user)
+	}
+
+	d.users = users
+	d.expireAt = time.Now().Add(time.Duration(d.conf.UserListCacheSeconds) * time.Second)
+	return users, nil
+}
+
+// loginUserInfo converts the user to the login user, the suppliers and role are decided by the group mappings.
+func (d *directory) loginUserInfo(user *ldapUser) (*metadata.LoginUserInfo, bool) {
+	userInfo := &metadata.LoginUserInfo{
+		UserName:    user.Name,
+		ChName:      user.DisplayName,
+		Phone:       user.Phone,
+		Email:       user.Email,
+		OnwerUin:    common.BKDefaultOwnerID,
+		OwnerUinArr: make([]metadata.LoginUserInfoOwnerUinList, 0),
+	}
+	if len(userInfo.ChName) == 0 {
+		userInfo.ChName = user.Name
+	}
+
+	suppliers := make(map[string]struct{})
+	for _, mapping := range d.conf.GroupMappings {
+		if !isMemberOf(user.Groups, mapping.Group) {
+			continue
+		}
+		if _, exist := suppliers[mapping.Supplier]; exist {
+			continue
+		}
+		suppliers[mapping.Supplier] = struct{}{}
+
+		// the first matched mapping is the current supplier
+		if len(userInfo.OwnerUinArr) == 0 {
+			userInfo.OnwerUin = mapping.Supplier
+			userInfo.Role = mapping.Role
+		}
+		userInfo.OwnerUinArr = append(userInfo.OwnerUinArr, metadata.LoginUserInfoOwnerUinList{
+			OwnerID:   mapping.Supplier,
+			OwnerName: mapping.Supplier,
+		})
+	}
+
+	if len(userInfo.OwnerUinArr) == 0 && d.conf.RequireGroup {
+		return nil, false
+	}
+	userInfo.MultiSupplier = len(userInfo.OwnerUinArr) > 1
+	return userInfo, true
+}
+
+// isMemberOf checks if the group is one of the user's groups, the group can be a dn or the common name.
+func isMemberOf(userGroups []string, group string) bool {
+	for _, userGroup := range userGroups {
+		if strings.EqualFold(userGroup, group) {
+			return true
+		}
+
+		dn, err := ldapv3.ParseDN(userGroup)
+		if err != nil || len(dn.RDNs) == 0 {
+			continue
+		}
+		for _, attr := range dn.RDNs[0].Attributes {
+			if strings.EqualFold(attr.Type, "cn") && strings.EqualFold(attr.Value, group) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,333 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ldap
+
+import (
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"configcenter/src/common"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+)
+
+type fakeEntry struct {
+	dn       string
+	password string
+	attrs    map[string][]string
+}
+
+// fakeServer is an in-process ldap server which supports bind, search with and, or, not,
+// equality and present filters, and unbind.
+type fakeServer struct {
+	listener net.Listener
+	entries  []fakeEntry
+
+	lock     sync.Mutex
+	dials    int
+	searches int
+}
+
+func newFakeServer(t *testing.T, entries []fakeEntry) *fakeServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen failed, err: %v", err)
+	}
+	s := &fakeServer{listener: listener, entries: entries}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			s.lock.Lock()
+			s.dials++
+			s.lock.Unlock()
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *fakeServer) url() string {
+	return "ldap://" + s.listener.Addr().String()
+}
+
+func (s *fakeServer) close() {
+	s.listener.Close()
+}
+
+func (s *fakeServer) serve(conn net.Conn) {
+	defer conn.Close()
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil {
+			return
+		}
+		msgID := packet.Children[0].Value.(int64)
+		op := packet.Children[1]
+		switch op.Tag {
+		case 0:
+			name := op.Children[1].Data.String()
+			password := op.Children[2].Data.String()
+			code := int64(49)
+			if s.checkBind(name, password) {
+				code = 0
+			}
+			conn.Write(newFakeResponse(msgID, 1, code).Bytes())
+		case 2:
+			return
+		case 3:
+			s.lock.Lock()
+			s.searches++
+			s.lock.Unlock()
+			baseDN := strings.ToLower(op.Children[0].Data.String())
+			for _, entry := range s.entries {
+				if strings.HasSuffix(strings.ToLower(entry.dn), baseDN) && matchFakeFilter(op.Children[6], entry) {
+					conn.Write(newFakeEntry(msgID, entry).Bytes())
+				}
+			}
+			conn.Write(newFakeResponse(msgID, 5, 0).Bytes())
+		}
+	}
+}
+
+func (s *fakeServer) checkBind(name, password string) bool {
+	if len(name) == 0 && len(password) == 0 {
+		return true
+	}
+	for _, entry := range s.entries {
+		if strings.EqualFold(entry.dn, name) && len(entry.password) != 0 && entry.password == password {
+			return true
+		}
+	}
+	return false
+}
+
+func matchFakeFilter(filter *ber.Packet, entry fakeEntry) bool {
+	switch filter.Tag {
+	case 0:
+		for _, child := range filter.Children {
+			if !matchFakeFilter(child, entry) {
+				return false
+			}
+		}
+		return true
+	case 1:
+		for _, child := range filter.Children {
+			if matchFakeFilter(child, entry) {
+				return true
+			}
+		}
+		return false
+	case 2:
+		return !matchFakeFilter(filter.Children[0], entry)
+	case 3:
+		attr, value := filter.Children[0].Data.String(), filter.Children[1].Data.String()
+		for key, values := range entry.attrs {
+			if !strings.EqualFold(key, attr) {
+				continue
+			}
+			for _, val := range values {
+				if strings.EqualFold(val, value) {
+					return true
+				}
+			}
+		}
+		return false
+	case 7:
+		for key := range entry.attrs {
+			if strings.EqualFold(key, filter.Data.String()) {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
+
+func newFakeResponse(msgID int64, tag ber.Tag, code int64) *ber.Packet {
+	envelope := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+	envelope.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, msgID, "MessageID"))
+	response := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "Response")
+	response.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, code, "Result Code"))
+	response.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Matched DN"))
+	response.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Diagnostic Message"))
+	envelope.AppendChild(response)
+	return envelope
+}
+
+func newFakeEntry(msgID int64, entry fakeEntry) *ber.Packet {
+	envelope := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+	envelope.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, msgID, "MessageID"))
+	result := ber.Encode(ber.ClassApplication, ber.TypeConstructed, 4, nil, "Search Result Entry")
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, entry.dn, "DN"))
+	attrs := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attributes")
+	for key, values := range entry.attrs {
+		attr := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attribute")
+		attr.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, key, "Type"))
+		vals := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "Values")
+		for _, val := range values {
+			vals.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, val, "Value"))
+		}
+		attr.AppendChild(vals)
+		attrs.AppendChild(attr)
+	}
+	result.AppendChild(attrs)
+	envelope.AppendChild(result)
+	return envelope
+}
+
+func newTestDirectory(t *testing.T) (*directory, *fakeServer) {
+	server := newFakeServer(t, []fakeEntry{
+		{
+			dn:       "cn=admin,dc=example,dc=com",
+			password: "secret",
+			attrs:    map[string][]string{"objectClass": {"organizationalRole"}, "cn": {"admin"}},
+		},
+		{
+			dn:       "uid=alice,ou=people,dc=example,dc=com",
+			password: "alice-pwd",
+			attrs: map[string][]string{
+				"objectClass": {"person"},
+				"uid":         {"alice"},
+				"cn":          {"Alice Liddell"},
+				"mail":        {"alice@example.com"},
+				"memberOf":    {"cn=cmdb-admins,ou=groups,dc=example,dc=com", "cn=ops,ou=groups,dc=example,dc=com"},
+			},
+		},
+		{
+			dn:       "uid=bob,ou=people,dc=example,dc=com",
+			password: "bob-pwd",
+			attrs:    map[string][]string{"objectClass": {"person"}, "uid": {"bob"}},
+		},
+	})
+
+	conf := Config{
+		URL:                  server.url(),
+		BindDN:               "cn=admin,dc=example,dc=com",
+		BindPassword:         "secret",
+		BaseDN:               "dc=example,dc=com",
+		UserFilter:           "(&(objectClass=person)(uid=%s))",
+		UserListFilter:       "(objectClass=person)",
+		UsernameAttribute:    "uid",
+		DisplayNameAttribute: "cn",
+		GroupMappings: []GroupMapping{
+			{Group: "cmdb-admins", Supplier: "0", Role: "admin"},
+			{Group: "cn=ops,ou=groups,dc=example,dc=com", Supplier: "1", Role: "ops"},
+		},
+	}
+	conf.setDefault()
+	if err := conf.validate(); err != nil {
+		t.Fatalf("invalid config, err: %v", err)
+	}
+
+	dir, err := newDirectory(conf)
+	if err != nil {
+		t.Fatalf("new directory failed, err: %v", err)
+	}
+	return dir, server
+}
+
+func TestAuthenticate(t *testing.T) {
+	dir, server := newTestDirectory(t)
+	defer server.close()
+	defer dir.close()
+
+	user, err := dir.authenticate("alice", "alice-pwd")
+	if err != nil {
+		t.Fatalf("authenticate alice failed, err: %v", err)
+	}
+	if user.Name != "alice" || user.DisplayName != "Alice Liddell" || user.Email != "alice@example.com" || len(user.Groups) != 2 {
+		t.Errorf("unexpected user: %+v", user)
+	}
+
+	if _, err := dir.authenticate("alice", "wrong"); err != errInvalidCredentials {
+		t.Errorf("authenticate with wrong password should fail, err: %v", err)
+	}
+	if _, err := dir.authenticate("carol", "carol-pwd"); err != errInvalidCredentials {
+		t.Errorf("authenticate unknown user should fail, err: %v", err)
+	}
+	if _, err := dir.authenticate("*", "alice-pwd"); err != errInvalidCredentials {
+		t.Errorf("user name should be escaped in the filter, err: %v", err)
+	}
+	if _, err := dir.authenticate("alice", ""); err != errInvalidCredentials {
+		t.Errorf("authenticate with empty password should fail, err: %v", err)
+	}
+
+	// the connection is bound back to the service account and reused
+	server.lock.Lock()
+	dials := server.dials
+	server.lock.Unlock()
+	if dials != 1 {
+		t.Errorf("connection should be reused, got %d dials", dials)
+	}
+}
+
+func TestLoginUserInfo(t *testing.T) {
+	dir, server := newTestDirectory(t)
+	defer server.close()
+	defer dir.close()
+
+	alice, err := dir.authenticate("alice", "alice-pwd")
+	if err != nil {
+		t.Fatalf("authenticate alice failed, err: %v", err)
+	}
+	userInfo, ok := dir.loginUserInfo(alice)
+	if !ok {
+		t.Fatalf("alice should be allowed to login")
+	}
+	if userInfo.OnwerUin != "0" || userInfo.Role != "admin" || !userInfo.MultiSupplier || len(userInfo.OwnerUinArr) != 2 {
+		t.Errorf("unexpected login user info: %+v", userInfo)
+	}
+
+	bob, err := dir.authenticate("bob", "bob-pwd")
+	if err != nil {
+		t.Fatalf("authenticate bob failed, err: %v", err)
+	}
+	userInfo, ok = dir.loginUserInfo(bob)
+	if !ok || userInfo.OnwerUin != common.BKDefaultOwnerID || userInfo.ChName != "bob" || userInfo.MultiSupplier {
+		t.Errorf("unexpected login user info: %+v", userInfo)
+	}
+
+	dir.conf.RequireGroup = true
+	if _, ok := dir.loginUserInfo(bob); ok {
+		t.Errorf("bob is not in any mapped group, should be denied")
+	}
+}
+
+func TestSearchUsers(t *testing.T) {
+	dir, server := newTestDirectory(t)
+	defer server.close()
+	defer dir.close()
+
+	users, err := dir.searchUsers()
+	if err != nil {
+		t.Fatalf("search users failed, err: %v", err)
+	}
+	if len(users) != 2 || users[0].Name != "alice" || users[1].Name != "bob" {
+		t.Fatalf("unexpected users: %+v", users)
+	}
+
+	if _, err := dir.searchUsers(); err != nil {
+		t.Fatalf("search users failed, err: %v", err)
+	}
+	server.lock.Lock()
+	searches := server.searches
+	server.lock.Unlock()
+	if searches != 1 {
+		t.Errorf("users should be cached, got %d searches", searches)
+	}
+}
@@ -0,0 +1,121 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ldap
+
+import (
+	"crypto/tls"
+	"net"
+	"time"
+
+	"configcenter/src/common/blog"
+
+	ldapv3 "github.com/go-ldap/ldap/v3"
+)
+
+// connPool keeps the idle connections bound with the service account, so that the user search
+// does not need to dial and bind every time.
+type connPool struct {
+	conf      Config
+	tlsConfig *tls.Config
+	conns     chan *ldapv3.Conn
+}
+
+func newConnPool(conf Config, tlsConfig *tls.Config) *connPool {
+	return &connPool{
+		conf:      conf,
+		tlsConfig: tlsConfig,
+		conns:     make(chan *ldapv3.Conn, conf.PoolSize),
+	}
+}
+
+// get returns an idle connection or dials a new one, the connection is bound with the service account.
+func (p *connPool) get() (*ldapv3.Conn, error) {
+	for {
+		select {
+		case conn := <-p.conns:
+			if conn.IsClosing() {
+				continue
+			}
+			return conn, nil
+		default:
+			return p.dial()
+		}
+	}
+}
+
+// put returns the connection to the pool, it is closed if the pool is full.
+// the connection must be bound with the service account.
+func (p *connPool) put(conn *ldapv3.Conn) {
+	if conn.IsClosing() {
+		return
+	}
+	select {
+	case p.conns <- conn:
+	default:
+		conn.Close()
+	}
+}
+
+// close closes all the idle connections
+func (p *connPool) close() {
+	for {
+		select {
+		case conn := <-p.conns:
+			conn.Close()
+		default:
+			return
+		}
+	}
+}
+
+func (p *connPool) dial() (*ldapv3.Conn, error) {
+	timeout := time.Duration(p.conf.TimeoutSeconds) * time.Second
+	conn, err := ldapv3.DialURL(p.conf.URL, ldapv3.DialWithDialer(&net.Dialer{Timeout: timeout}),
+		ldapv3.DialWithTLSConfig(p.tlsConfig))
+	if err != nil {
+		blog.Errorf("dial ldap server %s failed, err: %v", p.conf.URL, err)
+		return nil, err
+	}
+	conn.SetTimeout(timeout)
+
+	if p.conf.StartTLS {
+		if err := conn.StartTLS(p.tlsConfig); err != nil {
+			blog.Errorf("start tls with ldap server %s failed, err: %v", p.conf.URL, err)
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	if err := p.bindServiceAccount(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// bindServiceAccount binds the connection with the service account, or anonymous if it's not configured.
+func (p *connPool) bindServiceAccount(conn *ldapv3.Conn) error {
+	if len(p.conf.BindDN) == 0 {
+		if err := conn.UnauthenticatedBind(""); err != nil {
+			blog.Errorf("anonymous bind ldap server %s failed, err: %v", p.conf.URL, err)
+			return err
+		}
+		return nil
+	}
+
+	if err := conn.Bind(p.conf.BindDN, p.conf.BindPassword); err != nil {
+		blog.Errorf("bind ldap server %s with %s failed, err: %v", p.conf.URL, p.conf.BindDN, err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ldap
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+
+	"configcenter/src/common"
+	cc "configcenter/src/common/backbone/configcenter"
+	"configcenter/src/common/blog"
+	"configcenter/src/common/errors"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	webCommon "configcenter/src/web_server/common"
+	"configcenter/src/web_server/middleware/user/plugins/manager"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	plugin := &metadata.LoginPluginInfo{
+		Name:       "ldap system",
+		Version:    common.BKLDAPLoginPluginVersion,
+		HandleFunc: &user{},
+	}
+	manager.RegisterPlugin(plugin)
+}
+
+type user struct {
+	lock sync.Mutex
+	dir  *directory
+}
+
+// getDirectory returns the directory of the current configuration, it's rebuilt when the configuration is changed.
+func (m *user) getDirectory() (*directory, error) {
+	conf, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.dir != nil && reflect.DeepEqual(m.dir.conf, conf) {
+		return m.dir, nil
+	}
+
+	dir, err := newDirectory(conf)
+	if err != nil {
+		return nil, err
+	}
+	if m.dir != nil {
+		m.dir.close()
+	}
+	m.dir = dir
+	return dir, nil
+}
+
+// LoginUser authenticate the user of the login form with the directory, the later requests are
+// authorized by the bk_token in session until the cookie expires.
+func (m *user) LoginUser(c *gin.Context, config map[string]string, isMultiOwner bool) (*metadata.LoginUserInfo, bool) {
+	rid := util.GetHTTPCCRequestID(c.Request.Header)
+	if c.Request.Method != http.MethodPost || c.Request.URL.Path != "/login" {
+		return nil, false
+	}
+
+	dir, err := m.getDirectory()
+	if err != nil {
+		blog.Errorf("ldap login config is invalid, err: %v, rid: %s", err, rid)
+		return nil, false
+	}
+
+	userName := c.PostForm("username")
+	ldapUser, err := dir.authenticate(userName, c.PostForm("password"))
+	if err != nil {
+		blog.Errorf("authenticate ldap user %s failed, err: %v, rid: %s", userName, err, rid)
+		return nil, false
+	}
+
+	userInfo, ok := dir.loginUserInfo(ldapUser)
+	if !ok {
+		blog.Errorf("ldap user %s is not in any of the mapped groups, rid: %s", userName, rid)
+		return nil, false
+	}
+
+	token, err := newToken()
+	if err != nil {
+		blog.Errorf("generate token for ldap user %s failed, err: %v, rid: %s", userName, err, rid)
+		return nil, false
+	}
+	userInfo.BkToken = token
+	userInfo.Language = webCommon.GetLanguageByHTTPRequest(c)
+
+	c.SetCookie(common.HTTPCookieBKToken, token, dir.conf.SessionTTLSeconds, "/", "", false, true)
+	c.SetCookie(common.BKHTTPOwnerID, userInfo.OnwerUin, 0, "/", "", false, false)
+	return userInfo, true
+}
+
+func newToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func (m *user) GetLoginUrl(c *gin.Context, config map[string]string, input *metadata.LogoutRequestParams) string {
+	var siteURL string
+	var err error
+	if common.LogoutHTTPSchemeHTTPS == input.HTTPScheme {
+		siteURL, err = cc.String("webServer.site.httpsDomainUrl")
+	} else {
+		siteURL, err = cc.String("webServer.site.domainUrl")
+	}
+	if err != nil {
+		siteURL = ""
+	}
+	return fmt.Sprintf("%s/login?c_url=%s%s", siteURL, siteURL, c.Request.URL.String())
+}
+
+func (m *user) GetUserList(c *gin.Context, config map[string]string) ([]*metadata.LoginSystemUserInfo, *errors.RawErrorInfo) {
+	rid := util.GetHTTPCCRequestID(c.Request.Header)
+	dir, err := m.getDirectory()
+	if err != nil {
+		blog.Errorf("ldap login config is invalid, err: %v, rid: %s", err, rid)
+		return nil, &errors.RawErrorInfo{
+			ErrCode: common.CCErrWebLDAPConfigInvalid,
+		}
+	}
+
+	ldapUsers, err := dir.searchUsers()
+	if err != nil {
+		blog.Errorf("search ldap users failed, err: %v, rid: %s", err, rid)
+		return nil, &errors.RawErrorInfo{
+			ErrCode: common.CCErrWebLDAPSearchUserFailed,
+		}
+	}
+
+	users := make([]*metadata.LoginSystemUserInfo, 0, len(ldapUsers))
+	for _, ldapUser := range ldapUsers {
+		cnName := ldapUser.DisplayName
+		if len(cnName) == 0 {
+			cnName = ldapUser.Name
+		}
+		users = append(users, &metadata.LoginSystemUserInfo{
+			CnName: cnName,
+			EnName: ldapUser.Name,
+		})
+	}
+	return users, nil
+}
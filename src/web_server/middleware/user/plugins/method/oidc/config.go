@@ -0,0 +1,132 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package oidc
+
+import (
+	"errors"
+	"strings"
+
+	cc "configcenter/src/common/backbone/configcenter"
+)
+
+const (
+	defaultUsernameClaim    = "preferred_username"
+	defaultDisplayNameClaim = "name"
+	defaultEmailClaim       = "email"
+	defaultPhoneClaim       = "phone_number"
+	defaultLanguageClaim    = "locale"
+	defaultTimeoutSeconds   = 10
+	// defaultCallbackPath is the route of web_server that the identity provider redirects to
+	defaultCallbackPath = "/login/callback"
+)
+
+var defaultScopes = []string{"openid", "profile", "email"}
+
+// Config is the oidc login configuration at webServer.oidc in common.yaml
+type Config struct {
+	// Issuer is the identity provider, the metadata is discovered from issuer/.well-known/openid-configuration
+	Issuer       string `mapstructure:"issuer"`
+	ClientID     string `mapstructure:"clientID"`
+	ClientSecret string `mapstructure:"clientSecret"`
+	// RedirectURL is the registered redirect uri of the client, default is webServer.site.domainUrl/login/callback
+	RedirectURL string `mapstructure:"redirectURL"`
+	// PostLogoutRedirectURL is where the identity provider redirects to after logout, default is webServer.site.domainUrl
+	PostLogoutRedirectURL string `mapstructure:"postLogoutRedirectURL"`
+	// Scopes requested, openid is always added, add offline_access to get the refresh token for some providers
+	Scopes []string `mapstructure:"scopes"`
+
+	// the claims of the id token that are mapped to the login user, nested claims are separated by .
+	UsernameClaim    string `mapstructure:"usernameClaim"`
+	DisplayNameClaim string `mapstructure:"displayNameClaim"`
+	EmailClaim       string `mapstructure:"emailClaim"`
+	PhoneClaim       string `mapstructure:"phoneClaim"`
+	LanguageClaim    string `mapstructure:"languageClaim"`
+	// OwnerClaim is the supplier account of the user, a string or an array of strings whose first
+	// element is the current supplier, empty means all the users belong to the default supplier
+	OwnerClaim string `mapstructure:"ownerClaim"`
+
+	InsecureSkipVerify bool   `mapstructure:"insecureSkipVerify"`
+	CAFile             string `mapstructure:"caFile"`
+	TimeoutSeconds     int    `mapstructure:"timeout"`
+	// SessionTTLSeconds is how long the login is trusted before it is refreshed with the refresh token,
+	// 0 means it follows the expiry of the id token.
+	SessionTTLSeconds int `mapstructure:"sessionTTL"`
+}
+
+// loadConfig loads the oidc configuration from common.yaml
+func loadConfig() (Config, error) {
+	conf := Config{}
+	if err := cc.UnmarshalKey("webServer.oidc", &conf); err != nil {
+		return conf, err
+	}
+	siteURL, _ := cc.String("webServer.site.domainUrl")
+	conf.setDefault(strings.TrimSuffix(siteURL, "/"))
+	return conf, conf.validate()
+}
+
+func (c *Config) setDefault(siteURL string) {
+	c.Issuer = strings.TrimSuffix(c.Issuer, "/")
+	if len(c.RedirectURL) == 0 {
+		c.RedirectURL = siteURL + defaultCallbackPath
+	}
+	if len(c.PostLogoutRedirectURL) == 0 {
+		c.PostLogoutRedirectURL = siteURL
+	}
+
+	if len(c.Scopes) == 0 {
+		c.Scopes = append([]string{}, defaultScopes...)
+	} else {
+		scopes := []string{"openid"}
+		for _, scope := range c.Scopes {
+			if scope != "openid" && len(scope) != 0 {
+				scopes = append(scopes, scope)
+			}
+		}
+		c.Scopes = scopes
+	}
+
+	if len(c.UsernameClaim) == 0 {
+		c.UsernameClaim = defaultUsernameClaim
+	}
+	if len(c.DisplayNameClaim) == 0 {
+		c.DisplayNameClaim = defaultDisplayNameClaim
+	}
+	if len(c.EmailClaim) == 0 {
+		c.EmailClaim = defaultEmailClaim
+	}
+	if len(c.PhoneClaim) == 0 {
+		c.PhoneClaim = defaultPhoneClaim
+	}
+	if len(c.LanguageClaim) == 0 {
+		c.LanguageClaim = defaultLanguageClaim
+	}
+	if c.TimeoutSeconds <= 0 {
+		c.TimeoutSeconds = defaultTimeoutSeconds
+	}
+	if c.SessionTTLSeconds < 0 {
+		c.SessionTTLSeconds = 0
+	}
+}
+
+func (c *Config) validate() error {
+	if !strings.HasPrefix(c.Issuer, "https://") && !strings.HasPrefix(c.Issuer, "http://") {
+		return errors.New("webServer.oidc.issuer should start with https:// or http://")
+	}
+	if len(c.ClientID) == 0 {
+		return errors.New("webServer.oidc.clientID is not set")
+	}
+	if !strings.HasPrefix(c.RedirectURL, "https://") && !strings.HasPrefix(c.RedirectURL, "http://") {
+		return errors.New("webServer.oidc.redirectURL or webServer.site.domainUrl should be an absolute url")
+	}
+	return nil
+}
@@ -0,0 +1,314 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package oidc
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeProvider is an in-process identity provider which supports discovery, jwks, the authorization
+// code grant with S256 pkce and the refresh token grant.
+type fakeProvider struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+	kid    string
+
+	lock       sync.Mutex
+	challenges map[string]string
+	claims     map[string]interface{}
+	jwksHits   int
+}
+
+func newFakeProvider(t *testing.T) *fakeProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate rsa key failed, err: %v", err)
+	}
+	f := &fakeProvider{key: key, kid: "key1", challenges: make(map[string]string)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(providerMetadata{
+			Issuer:                f.server.URL,
+			AuthorizationEndpoint: f.server.URL + "/authorize",
+			TokenEndpoint:         f.server.URL + "/token",
+			JWKSURI:               f.server.URL + "/jwks",
+			EndSessionEndpoint:    f.server.URL + "/logout",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		f.lock.Lock()
+		defer f.lock.Unlock()
+		f.jwksHits++
+		json.NewEncoder(w).Encode(jsonWebKeySet{Keys: []*jsonWebKey{{
+			Kty: "RSA",
+			Kid: f.kid,
+			Use: "sig",
+			N:   base64.RawURLEncoding.EncodeToString(f.key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(f.key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", f.token)
+	f.server = httptest.NewServer(mux)
+	return f
+}
+
+// authorize registers the code with its pkce challenge, like the user logged in at the provider
+func (f *fakeProvider) authorize(code, challenge string, claims map[string]interface{}) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.challenges[code] = challenge
+	f.claims = claims
+}
+
+func (f *fakeProvider) token(w http.ResponseWriter, r *http.Request) {
+	clientID, secret, ok := r.BasicAuth()
+	if !ok || clientID != "cmdb" || secret != "secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(tokenResponse{Error: "invalid_client"})
+		return
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	switch r.PostFormValue("grant_type") {
+	case "authorization_code":
+		challenge, exist := f.challenges[r.PostFormValue("code")]
+		delete(f.challenges, r.PostFormValue("code"))
+		if !exist || codeChallenge(r.PostFormValue("code_verifier")) != challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(tokenResponse{Error: "invalid_grant"})
+			return
+		}
+	case "refresh_token":
+		if r.PostFormValue("refresh_token") != "refresh1" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(tokenResponse{Error: "invalid_grant"})
+			return
+		}
+		delete(f.claims, "nonce")
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(tokenResponse{Error: "unsupported_grant_type"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(tokenResponse{
+		AccessToken:  "access",
+		TokenType:    "Bearer",
+		IDToken:      signRS256(f.key, f.kid, f.claims),
+		RefreshToken: "refresh1",
+		ExpiresIn:    300,
+	})
+}
+
+func (f *fakeProvider) config() Config {
+	conf := Config{
+		Issuer:       f.server.URL,
+		ClientID:     "cmdb",
+		ClientSecret: "secret",
+		OwnerClaim:   "cmdb.suppliers",
+	}
+	conf.setDefault("http://cmdb.example.com")
+	return conf
+}
+
+func signRS256(key *rsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+	signature, _ := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func testClaims(issuer string) map[string]interface{} {
+	return map[string]interface{}{
+		"iss":                issuer,
+		"sub":                "10001",
+		"aud":                "cmdb",
+		"exp":                time.Now().Add(10 * time.Minute).Unix(),
+		"iat":                time.Now().Unix(),
+		"nonce":              "nonce1",
+		"preferred_username": "alice",
+		"name":               "Alice",
+		"email":              "alice@example.com",
+		"locale":             "zh-CN",
+		"cmdb":               map[string]interface{}{"suppliers": []string{"0", "1"}},
+	}
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	f := newFakeProvider(t)
+	defer f.server.Close()
+	p, err := newProvider(f.config())
+	if err != nil {
+		t.Fatalf("new provider failed, err: %v", err)
+	}
+
+	claims, err := p.verifyIDToken(signRS256(f.key, f.kid, testClaims(f.server.URL)), "nonce1")
+	if err != nil {
+		t.Fatalf("verify valid id token failed, err: %v", err)
+	}
+	if claims.stringValue("preferred_username") != "alice" {
+		t.Errorf("unexpected claims %v", claims)
+	}
+
+	invalid := map[string]func(map[string]interface{}){
+		"issuer":   func(c map[string]interface{}) { c["iss"] = "https://evil.example.com" },
+		"audience": func(c map[string]interface{}) { c["aud"] = []string{"other"} },
+		"azp":      func(c map[string]interface{}) { c["aud"] = []string{"cmdb", "other"} },
+		"expired":  func(c map[string]interface{}) { c["exp"] = time.Now().Add(-time.Hour).Unix() },
+		"nonce":    func(c map[string]interface{}) { c["nonce"] = "replayed" },
+	}
+	for name, modify := range invalid {
+		claims := testClaims(f.server.URL)
+		modify(claims)
+		if _, err := p.verifyIDToken(signRS256(f.key, f.kid, claims), "nonce1"); err == nil {
+			t.Errorf("id token with invalid %s should be rejected", name)
+		}
+	}
+
+	// signed by a key the provider does not own
+	otherKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	if _, err := p.verifyIDToken(signRS256(otherKey, f.kid, testClaims(f.server.URL)), "nonce1"); err == nil {
+		t.Error("id token with invalid signature should be rejected")
+	}
+
+	// unsigned token
+	parts := strings.Split(signRS256(f.key, f.kid, testClaims(f.server.URL)), ".")
+	none := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + "." + parts[1] + "."
+	if _, err := p.verifyIDToken(none, "nonce1"); err == nil {
+		t.Error("unsigned id token should be rejected")
+	}
+
+	// the keys are reloaded when the provider rotates its key
+	newKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	f.lock.Lock()
+	f.key, f.kid = newKey, "key2"
+	f.lock.Unlock()
+	p.keysFetched = time.Now().Add(-2 * minKeysRefreshInterval)
+	if _, err := p.verifyIDToken(signRS256(newKey, "key2", testClaims(f.server.URL)), "nonce1"); err != nil {
+		t.Errorf("id token signed by the rotated key should be accepted, err: %v", err)
+	}
+}
+
+func TestVerifyES256(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	jwk := &jsonWebKey{
+		Kty: "EC",
+		Crv: "P-256",
+		X:   base64.RawURLEncoding.EncodeToString(key.X.Bytes()),
+		Y:   base64.RawURLEncoding.EncodeToString(key.Y.Bytes()),
+	}
+	if err := jwk.parse(); err != nil {
+		t.Fatalf("parse ec key failed, err: %v", err)
+	}
+	if !jwk.supports("ES256") || jwk.supports("RS256") {
+		t.Fatal("ec key should only support the ecdsa algorithms")
+	}
+
+	signed := []byte("header.payload")
+	digest := sha256.Sum256(signed)
+	r, s, _ := ecdsa.Sign(rand.Reader, key, digest[:])
+	signature := make([]byte, 64)
+	rBytes, sBytes := r.Bytes(), s.Bytes()
+	copy(signature[32-len(rBytes):32], rBytes)
+	copy(signature[64-len(sBytes):], sBytes)
+	if err := jwk.verify("ES256", signed, signature); err != nil {
+		t.Errorf("verify es256 signature failed, err: %v", err)
+	}
+	if err := jwk.verify("ES256", []byte("header.tampered"), signature); err == nil {
+		t.Error("tampered es256 signature should be rejected")
+	}
+}
+
+func TestAuthorizationCodeFlow(t *testing.T) {
+	f := newFakeProvider(t)
+	defer f.server.Close()
+	p, err := newProvider(f.config())
+	if err != nil {
+		t.Fatalf("new provider failed, err: %v", err)
+	}
+
+	authURL, err := url.Parse(p.authCodeURL("state1", "nonce1", codeChallenge("verifier1")))
+	if err != nil {
+		t.Fatalf("parse authorization url failed, err: %v", err)
+	}
+	query := authURL.Query()
+	if query.Get("code_challenge_method") != "S256" || query.Get("redirect_uri") != "http://cmdb.example.com/login/callback" ||
+		query.Get("scope") != "openid profile email" || query.Get("state") != "state1" {
+		t.Errorf("unexpected authorization url %s", authURL)
+	}
+
+	f.authorize("code1", query.Get("code_challenge"), testClaims(f.server.URL))
+	if _, err := p.exchange("code1", "wrong-verifier"); err == nil {
+		t.Error("code redeemed with a wrong pkce verifier should be rejected")
+	}
+
+	f.authorize("code2", query.Get("code_challenge"), testClaims(f.server.URL))
+	token, err := p.exchange("code2", "verifier1")
+	if err != nil {
+		t.Fatalf("exchange code failed, err: %v", err)
+	}
+	claims, err := p.verifyIDToken(token.IDToken, "nonce1")
+	if err != nil {
+		t.Fatalf("verify id token failed, err: %v", err)
+	}
+
+	userInfo, err := loginUserInfo(p.conf, claims)
+	if err != nil {
+		t.Fatalf("map claims failed, err: %v", err)
+	}
+	if userInfo.UserName != "alice" || userInfo.ChName != "Alice" || userInfo.Email != "alice@example.com" ||
+		userInfo.OnwerUin != "0" || len(userInfo.OwnerUinArr) != 2 || !userInfo.MultiSupplier {
+		t.Errorf("unexpected login user %+v", userInfo)
+	}
+	if language := mapLanguage(claims.stringValue(p.conf.LanguageClaim)); language != "zh-cn" {
+		t.Errorf("locale zh-CN should be mapped to zh-cn, got %s", language)
+	}
+	if ttl := loginTTL(p.conf, claims, token); ttl <= 0 || ttl > 600 {
+		t.Errorf("login ttl should follow the id token expiry, got %d", ttl)
+	}
+
+	refreshed, err := p.refresh(token.RefreshToken)
+	if err != nil {
+		t.Fatalf("refresh token failed, err: %v", err)
+	}
+	if _, err := p.verifyIDToken(refreshed.IDToken, ""); err != nil {
+		t.Errorf("verify refreshed id token failed, err: %v", err)
+	}
+	if _, err := p.refresh("revoked"); err == nil {
+		t.Error("revoked refresh token should be rejected")
+	}
+
+	if logoutURL := p.logoutURL(); !strings.HasPrefix(logoutURL, f.server.URL+"/logout?") ||
+		!strings.Contains(logoutURL, "post_logout_redirect_uri=http%3A%2F%2Fcmdb.example.com") {
+		t.Errorf("unexpected logout url %s", logoutURL)
+	}
+}
@@ -0,0 +1,267 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package oidc
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"configcenter/src/common/blog"
+)
+
+// minKeysRefreshInterval limits the jwks refreshing caused by the unknown key ids
+const minKeysRefreshInterval = time.Minute
+
+// providerMetadata is the discovered metadata of the identity provider
+type providerMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+	EndSessionEndpoint    string `json:"end_session_endpoint"`
+}
+
+// tokenResponse is the response of the token endpoint
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	IDToken          string `json:"id_token"`
+	RefreshToken     string `json:"refresh_token"`
+	ExpiresIn        int64  `json:"expires_in"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+type provider struct {
+	conf   Config
+	client *http.Client
+	meta   providerMetadata
+
+	lock        sync.RWMutex
+	keys        []*jsonWebKey
+	keysFetched time.Time
+}
+
+// newProvider discovers the metadata of the identity provider and loads its signing keys
+func newProvider(conf Config) (*provider, error) {
+	tlsConfig, err := newTLSConfig(conf)
+	if err != nil {
+		return nil, err
+	}
+	p := &provider{
+		conf: conf,
+		client: &http.Client{
+			Timeout:   time.Duration(conf.TimeoutSeconds) * time.Second,
+			Transport: &http.Transport{Proxy: http.ProxyFromEnvironment, TLSClientConfig: tlsConfig},
+		},
+	}
+
+	if err := p.getJSON(conf.Issuer+"/.well-known/openid-configuration", &p.meta); err != nil {
+		return nil, fmt.Errorf("discover oidc provider %s failed, err: %v", conf.Issuer, err)
+	}
+	if strings.TrimSuffix(p.meta.Issuer, "/") != conf.Issuer {
+		return nil, fmt.Errorf("oidc provider issuer %s does not match the configured %s", p.meta.Issuer, conf.Issuer)
+	}
+	if len(p.meta.AuthorizationEndpoint) == 0 || len(p.meta.TokenEndpoint) == 0 || len(p.meta.JWKSURI) == 0 {
+		return nil, fmt.Errorf("oidc provider %s has no authorization, token or jwks endpoint", conf.Issuer)
+	}
+
+	if err := p.refreshKeys(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func newTLSConfig(conf Config) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: conf.InsecureSkipVerify,
+	}
+	if len(conf.CAFile) == 0 {
+		return tlsConfig, nil
+	}
+
+	ca, err := ioutil.ReadFile(conf.CAFile)
+	if err != nil {
+		return nil, fmt.Errorf("read oidc ca file %s failed, err: %v", conf.CAFile, err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, fmt.Errorf("oidc ca file %s has no valid certificate", conf.CAFile)
+	}
+	tlsConfig.RootCAs = pool
+	return tlsConfig, nil
+}
+
+// authCodeURL returns the authorization url of the authorization code flow with pkce
+func (p *provider) authCodeURL(state, nonce, codeChallenge string) string {
+	values := url.Values{}
+	values.Set("response_type", "code")
+	values.Set("client_id", p.conf.ClientID)
+	values.Set("redirect_uri", p.conf.RedirectURL)
+	values.Set("scope", strings.Join(p.conf.Scopes, " "))
+	values.Set("state", state)
+	values.Set("nonce", nonce)
+	values.Set("code_challenge", codeChallenge)
+	values.Set("code_challenge_method", "S256")
+	return appendQuery(p.meta.AuthorizationEndpoint, values)
+}
+
+// logoutURL returns the rp-initiated logout url of the provider, or the post logout url if it's not supported.
+func (p *provider) logoutURL() string {
+	if len(p.meta.EndSessionEndpoint) == 0 {
+		return p.conf.PostLogoutRedirectURL
+	}
+	values := url.Values{}
+	values.Set("client_id", p.conf.ClientID)
+	values.Set("post_logout_redirect_uri", p.conf.PostLogoutRedirectURL)
+	return appendQuery(p.meta.EndSessionEndpoint, values)
+}
+
+func appendQuery(endpoint string, values url.Values) string {
+	if strings.Contains(endpoint, "?") {
+		return endpoint + "&" + values.Encode()
+	}
+	return endpoint + "?" + values.Encode()
+}
+
+// exchange redeems the authorization code with the pkce code verifier
+func (p *provider) exchange(code, codeVerifier string) (*tokenResponse, error) {
+	values := url.Values{}
+	values.Set("grant_type", "authorization_code")
+	values.Set("code", code)
+	values.Set("redirect_uri", p.conf.RedirectURL)
+	values.Set("code_verifier", codeVerifier)
+	return p.requestToken(values)
+}
+
+// refresh gets new tokens with the refresh token
+func (p *provider) refresh(refreshToken string) (*tokenResponse, error) {
+	values := url.Values{}
+	values.Set("grant_type", "refresh_token")
+	values.Set("refresh_token", refreshToken)
+	return p.requestToken(values)
+}
+
+func (p *provider) requestToken(values url.Values) (*tokenResponse, error) {
+	values.Set("client_id", p.conf.ClientID)
+	req, err := http.NewRequest(http.MethodPost, p.meta.TokenEndpoint, strings.NewReader(values.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	// confidential clients authenticate with client_secret_basic, public clients rely on pkce only
+	if len(p.conf.ClientSecret) != 0 {
+		req.SetBasicAuth(url.QueryEscape(p.conf.ClientID), url.QueryEscape(p.conf.ClientSecret))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	token := new(tokenResponse)
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(token); err != nil {
+		return nil, fmt.Errorf("decode token response failed, status: %d, err: %v", resp.StatusCode, err)
+	}
+	if len(token.Error) != 0 {
+		return nil, fmt.Errorf("token endpoint returns error %s: %s", token.Error, token.ErrorDescription)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token endpoint returns status %d", resp.StatusCode)
+	}
+	return token, nil
+}
+
+func (p *provider) getJSON(address string, result interface{}) error {
+	resp, err := p.client.Get(address)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("get %s returns status %d", address, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(result)
+}
+
+// refreshKeys reloads the signing keys from the jwks endpoint
+func (p *provider) refreshKeys() error {
+	set := jsonWebKeySet{}
+	if err := p.getJSON(p.meta.JWKSURI, &set); err != nil {
+		return fmt.Errorf("get oidc provider keys failed, err: %v", err)
+	}
+
+	keys := make([]*jsonWebKey, 0, len(set.Keys))
+	for _, key := range set.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+		if err := key.parse(); err != nil {
+			blog.Warnf("skip invalid oidc provider key %s, err: %v", key.Kid, err)
+			continue
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return errors.New("oidc provider has no valid signing key")
+	}
+
+	p.lock.Lock()
+	p.keys = keys
+	p.keysFetched = time.Now()
+	p.lock.Unlock()
+	return nil
+}
+
+// signingKeys returns the keys that can verify the token signed by key id and alg, the keys are
+// refreshed when the key id is unknown, which happens after the provider rotates its keys.
+func (p *provider) signingKeys(kid, alg string) []*jsonWebKey {
+	keys, lastFetched := p.matchKeys(kid, alg)
+	if len(keys) != 0 || len(kid) == 0 || time.Since(lastFetched) < minKeysRefreshInterval {
+		return keys
+	}
+	if err := p.refreshKeys(); err != nil {
+		blog.Errorf("refresh oidc provider keys failed, err: %v", err)
+		return nil
+	}
+	keys, _ = p.matchKeys(kid, alg)
+	return keys
+}
+
+func (p *provider) matchKeys(kid, alg string) ([]*jsonWebKey, time.Time) {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	keys := make([]*jsonWebKey, 0)
+	for _, key := range p.keys {
+		if len(kid) != 0 && key.Kid != kid {
+			continue
+		}
+		if !key.supports(alg) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return keys, p.keysFetched
+}
@@ -0,0 +1,333 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package oidc
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// allowedClockSkew tolerates the clock difference between cmdb and the identity provider
+const allowedClockSkew = time.Minute
+
+// signingHashes are the supported signing algorithms of the id token
+var signingHashes = map[string]crypto.Hash{
+	"RS256": crypto.SHA256,
+	"RS384": crypto.SHA384,
+	"RS512": crypto.SHA512,
+	"PS256": crypto.SHA256,
+	"PS384": crypto.SHA384,
+	"PS512": crypto.SHA512,
+	"ES256": crypto.SHA256,
+	"ES384": crypto.SHA384,
+	"ES512": crypto.SHA512,
+}
+
+type jsonWebKeySet struct {
+	Keys []*jsonWebKey `json:"keys"`
+}
+
+// jsonWebKey is a rsa or ec public key of the provider
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+
+	publicKey crypto.PublicKey
+}
+
+func (k *jsonWebKey) parse() error {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return errors.New("rsa exponent is too large")
+		}
+		k.publicKey = &rsa.PublicKey{N: n, E: int(e.Int64())}
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return fmt.Errorf("unsupported curve %s", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return errors.New("ec point is not on the curve")
+		}
+		k.publicKey = &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+	default:
+		return fmt.Errorf("unsupported key type %s", k.Kty)
+	}
+	return nil
+}
+
+// supports checks if the key can verify the signature of alg
+func (k *jsonWebKey) supports(alg string) bool {
+	if len(k.Alg) != 0 && k.Alg != alg {
+		return false
+	}
+	switch k.publicKey.(type) {
+	case *rsa.PublicKey:
+		return strings.HasPrefix(alg, "RS") || strings.HasPrefix(alg, "PS")
+	case *ecdsa.PublicKey:
+		return strings.HasPrefix(alg, "ES")
+	}
+	return false
+}
+
+func (k *jsonWebKey) verify(alg string, signed, signature []byte) error {
+	hash, ok := signingHashes[alg]
+	if !ok {
+		return fmt.Errorf("unsupported signing algorithm %s", alg)
+	}
+	hasher := hash.New()
+	hasher.Write(signed)
+	digest := hasher.Sum(nil)
+
+	switch key := k.publicKey.(type) {
+	case *rsa.PublicKey:
+		if strings.HasPrefix(alg, "PS") {
+			return rsa.VerifyPSS(key, hash, digest, signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		}
+		return rsa.VerifyPKCS1v15(key, hash, digest, signature)
+	case *ecdsa.PublicKey:
+		size := (key.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return errors.New("invalid ecdsa signature length")
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(key, digest, r, s) {
+			return errors.New("invalid ecdsa signature")
+		}
+		return nil
+	}
+	return errors.New("unsupported public key")
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+	if err != nil || len(data) == 0 {
+		return nil, fmt.Errorf("invalid key parameter %s", value)
+	}
+	return new(big.Int).SetBytes(data), nil
+}
+
+// idTokenClaims is the payload of the id token
+type idTokenClaims map[string]interface{}
+
+// verifyIDToken verifies the signature of the id token with the provider keys, and checks the
+// issuer, audience, expiry and nonce of the token, empty nonce is not checked for the refreshed tokens.
+func (p *provider) verifyIDToken(raw, nonce string) (idTokenClaims, error) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("id token is not a jws compact serialization")
+	}
+
+	header := struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}{}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("decode id token header failed, err: %v", err)
+	}
+	if _, ok := signingHashes[header.Alg]; !ok {
+		return nil, fmt.Errorf("id token signing algorithm %s is not supported", header.Alg)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("decode id token signature failed, err: %v", err)
+	}
+
+	keys := p.signingKeys(header.Kid, header.Alg)
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no provider key %s for the id token", header.Kid)
+	}
+	verified := false
+	signed := []byte(parts[0] + "." + parts[1])
+	for _, key := range keys {
+		if key.verify(header.Alg, signed, signature) == nil {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, errors.New("id token signature is invalid")
+	}
+
+	claims := idTokenClaims{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("decode id token claims failed, err: %v", err)
+	}
+	if err := claims.validate(p.meta.Issuer, p.conf.ClientID, nonce, time.Now()); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func decodeSegment(segment string, result interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(result)
+}
+
+func (c idTokenClaims) validate(issuer, clientID, nonce string, now time.Time) error {
+	if iss := c.stringValue("iss"); iss != issuer {
+		return fmt.Errorf("id token issuer %s is not %s", iss, issuer)
+	}
+
+	audiences := c.stringValues("aud")
+	matched := false
+	for _, aud := range audiences {
+		if aud == clientID {
+			matched = true
+			break
+		}
+	}
+	if !matched {
+		return fmt.Errorf("id token audience %v does not contain the client %s", audiences, clientID)
+	}
+	if azp := c.stringValue("azp"); len(audiences) > 1 && azp != clientID {
+		return fmt.Errorf("id token authorized party %s is not the client %s", azp, clientID)
+	}
+
+	exp, ok := c.expiry()
+	if !ok {
+		return errors.New("id token has no expiry")
+	}
+	if now.After(exp.Add(allowedClockSkew)) {
+		return fmt.Errorf("id token expired at %s", exp)
+	}
+
+	if len(nonce) != 0 && c.stringValue("nonce") != nonce {
+		return errors.New("id token nonce does not match")
+	}
+	return nil
+}
+
+// expiry returns the exp claim of the token
+func (c idTokenClaims) expiry() (time.Time, bool) {
+	value, ok := c.lookup("exp")
+	if !ok {
+		return time.Time{}, false
+	}
+	number, ok := value.(json.Number)
+	if !ok {
+		return time.Time{}, false
+	}
+	seconds, err := number.Float64()
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(seconds), 0), true
+}
+
+// lookup finds the claim by the name, the name can be a path of the nested claims separated by .
+func (c idTokenClaims) lookup(name string) (interface{}, bool) {
+	if value, ok := c[name]; ok {
+		return value, true
+	}
+
+	var current interface{} = map[string]interface{}(c)
+	for _, field := range strings.Split(name, ".") {
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if current, ok = object[field]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// stringValue returns the claim as a string, the first element is used if the claim is an array
+func (c idTokenClaims) stringValue(name string) string {
+	values := c.stringValues(name)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// stringValues returns the claim as a string array
+func (c idTokenClaims) stringValues(name string) []string {
+	value, ok := c.lookup(name)
+	if !ok {
+		return nil
+	}
+
+	switch v := value.(type) {
+	case string:
+		if len(v) == 0 {
+			return nil
+		}
+		return []string{v}
+	case json.Number:
+		return []string{v.String()}
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			switch i := item.(type) {
+			case string:
+				values = append(values, i)
+			case json.Number:
+				values = append(values, i.String())
+			}
+		}
+		return values
+	}
+	return nil
+}
@@ -0,0 +1,367 @@
+/*
+ * Tencent is pleased to support the open source community by making 蓝鲸 available.
+ * Copyright (C) 2017-2018 THL A29 Limited, a Tencent company. All rights reserved.
+ * Licensed under the MIT License (the "License"); you may not use this file except
+ * in compliance with the License. You may obtain a copy of the License at
+ * http://opensource.org/licenses/MIT
+ * Unless required by applicable law or agreed to in writing, software distributed under
+ * the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+ * either express or implied. See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"configcenter/src/common"
+	cc "configcenter/src/common/backbone/configcenter"
+	"configcenter/src/common/blog"
+	ccErr "configcenter/src/common/errors"
+	"configcenter/src/common/metadata"
+	"configcenter/src/common/util"
+	webCommon "configcenter/src/web_server/common"
+	"configcenter/src/web_server/middleware/user/plugins/manager"
+
+	"github.com/gin-gonic/gin"
+	"github.com/holmeswang/contrib/sessions"
+)
+
+const (
+	// defaultLoginTTLSeconds is used when neither the id token nor the token response has the expiry
+	defaultLoginTTLSeconds = 60 * 60
+
+	sessionStateKey        = "oidc_state"
+	sessionNonceKey        = "oidc_nonce"
+	sessionCodeVerifierKey = "oidc_code_verifier"
+	sessionRefreshTokenKey = "oidc_refresh_token"
+	sessionClaimsKey       = "oidc_claims"
+)
+
+var errNoRefreshToken = errors.New("no refresh token in session")
+
+func init() {
+	plugin := &metadata.LoginPluginInfo{
+		Name:       "oidc system",
+		Version:    common.BKOIDCLoginPluginVersion,
+		HandleFunc: &user{},
+	}
+	manager.RegisterPlugin(plugin)
+}
+
+type user struct {
+	lock     sync.Mutex
+	provider *provider
+}
+
+// getProvider returns the provider of the current configuration, it's rebuilt when the configuration is changed.
+func (m *user) getProvider() (*provider, error) {
+	conf, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.provider != nil && reflect.DeepEqual(m.provider.conf, conf) {
+		return m.provider, nil
+	}
+
+	p, err := newProvider(conf)
+	if err != nil {
+		return nil, err
+	}
+	m.provider = p
+	return p, nil
+}
+
+// LoginUser completes the authorization code flow at the login callback. For the other requests it's called
+// when the bk_token cookie expired, and the login is extended with the refresh token kept in session.
+func (m *user) LoginUser(c *gin.Context, config map[string]string, isMultiOwner bool) (*metadata.LoginUserInfo, bool) {
+	rid := util.GetHTTPCCRequestID(c.Request.Header)
+	p, err := m.getProvider()
+	if err != nil {
+		blog.Errorf("oidc login config is invalid, err: %v, rid: %s", err, rid)
+		return nil, false
+	}
+
+	session := sessions.Default(c)
+	var token *tokenResponse
+	var claims idTokenClaims
+	if c.Request.URL.Path == defaultCallbackPath {
+		token, claims, err = handleCallback(c, p, session)
+	} else {
+		token, claims, err = refreshLogin(p, session)
+	}
+	if err == errNoRefreshToken {
+		blog.V(4).Infof("oidc login expired and can not be refreshed, rid: %s", rid)
+		return nil, false
+	}
+	if err != nil {
+		blog.Errorf("oidc login failed, err: %v, rid: %s", err, rid)
+		return nil, false
+	}
+
+	userInfo, err := loginUserInfo(p.conf, claims)
+	if err != nil {
+		blog.Errorf("map oidc claims to user failed, err: %v, rid: %s", err, rid)
+		return nil, false
+	}
+
+	bkToken, err := randomString(16)
+	if err != nil {
+		blog.Errorf("generate token for oidc user %s failed, err: %v, rid: %s", userInfo.UserName, err, rid)
+		return nil, false
+	}
+	userInfo.BkToken = bkToken
+
+	// the claims are kept to build the user when the refreshed tokens has no id token
+	if data, err := json.Marshal(claims); err == nil {
+		session.Set(sessionClaimsKey, string(data))
+	}
+	if len(token.RefreshToken) != 0 {
+		session.Set(sessionRefreshTokenKey, token.RefreshToken)
+	}
+
+	// the language of the user's own choice is kept
+	if cookieLanguage, err := c.Cookie(common.BKHTTPCookieLanugageKey); err != nil || len(cookieLanguage) == 0 {
+		if language := mapLanguage(claims.stringValue(p.conf.LanguageClaim)); len(language) != 0 {
+			c.SetCookie(common.BKHTTPCookieLanugageKey, language, 0, "/", "", false, true)
+			userInfo.Language = language
+		}
+	}
+	if len(userInfo.Language) == 0 {
+		userInfo.Language = webCommon.GetLanguageByHTTPRequest(c)
+	}
+
+	c.SetCookie(common.HTTPCookieBKToken, bkToken, loginTTL(p.conf, claims, token), "/", "", false, true)
+	c.SetCookie(common.BKHTTPOwnerID, userInfo.OnwerUin, 0, "/", "", false, false)
+	return userInfo, true
+}
+
+// handleCallback checks the state of the callback, redeems the code and verifies the id token
+func handleCallback(c *gin.Context, p *provider, session sessions.Session) (*tokenResponse, idTokenClaims, error) {
+	state, _ := session.Get(sessionStateKey).(string)
+	nonce, _ := session.Get(sessionNonceKey).(string)
+	codeVerifier, _ := session.Get(sessionCodeVerifierKey).(string)
+	// the state can only be used once
+	session.Delete(sessionStateKey)
+	session.Delete(sessionNonceKey)
+	session.Delete(sessionCodeVerifierKey)
+
+	if errCode := c.Query("error"); len(errCode) != 0 {
+		return nil, nil, fmt.Errorf("authorization failed, error: %s, description: %s", errCode,
+			c.Query("error_description"))
+	}
+	if len(state) == 0 || subtle.ConstantTimeCompare([]byte(state), []byte(c.Query("state"))) != 1 {
+		return nil, nil, errors.New("state of the callback does not match the session")
+	}
+
+	token, err := p.exchange(c.Query("code"), codeVerifier)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(token.IDToken) == 0 {
+		return nil, nil, errors.New("token response has no id token")
+	}
+	claims, err := p.verifyIDToken(token.IDToken, nonce)
+	if err != nil {
+		return nil, nil, err
+	}
+	return token, claims, nil
+}
+
+// refreshLogin gets new tokens with the refresh token, the subject of the refreshed id token must not change
+func refreshLogin(p *provider, session sessions.Session) (*tokenResponse, idTokenClaims, error) {
+	refreshToken, _ := session.Get(sessionRefreshTokenKey).(string)
+	data, _ := session.Get(sessionClaimsKey).(string)
+	if len(refreshToken) == 0 || len(data) == 0 {
+		return nil, nil, errNoRefreshToken
+	}
+	lastClaims := idTokenClaims{}
+	if err := decodeClaims(data, &lastClaims); err != nil {
+		return nil, nil, fmt.Errorf("decode claims in session failed, err: %v", err)
+	}
+
+	token, err := p.refresh(refreshToken)
+	if err != nil {
+		session.Delete(sessionRefreshTokenKey)
+		return nil, nil, err
+	}
+	if len(token.IDToken) == 0 {
+		return token, lastClaims, nil
+	}
+
+	claims, err := p.verifyIDToken(token.IDToken, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	if claims.stringValue("sub") != lastClaims.stringValue("sub") {
+		return nil, nil, errors.New("subject of the refreshed id token changed")
+	}
+	return token, claims, nil
+}
+
+func decodeClaims(data string, claims *idTokenClaims) error {
+	decoder := json.NewDecoder(strings.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(claims)
+}
+
+// loginTTL is how long the login is trusted before it's refreshed
+func loginTTL(conf Config, claims idTokenClaims, token *tokenResponse) int {
+	if conf.SessionTTLSeconds > 0 {
+		return conf.SessionTTLSeconds
+	}
+	if exp, ok := claims.expiry(); ok {
+		if ttl := int(time.Until(exp).Seconds()); ttl > 0 {
+			return ttl
+		}
+	}
+	if token.ExpiresIn > 0 {
+		return int(token.ExpiresIn)
+	}
+	return defaultLoginTTLSeconds
+}
+
+// loginUserInfo maps the claims of the id token to the login user
+func loginUserInfo(conf Config, claims idTokenClaims) (*metadata.LoginUserInfo, error) {
+	userName := claims.stringValue(conf.UsernameClaim)
+	if len(userName) == 0 {
+		return nil, fmt.Errorf("id token has no username claim %s", conf.UsernameClaim)
+	}
+
+	userInfo := &metadata.LoginUserInfo{
+		UserName:    userName,
+		ChName:      claims.stringValue(conf.DisplayNameClaim),
+		Phone:       claims.stringValue(conf.PhoneClaim),
+		Email:       claims.stringValue(conf.EmailClaim),
+		OnwerUin:    common.BKDefaultOwnerID,
+		OwnerUinArr: make([]metadata.LoginUserInfoOwnerUinList, 0),
+	}
+	if len(userInfo.ChName) == 0 {
+		userInfo.ChName = userName
+	}
+	if len(conf.OwnerClaim) == 0 {
+		return userInfo, nil
+	}
+
+	for _, owner := range claims.stringValues(conf.OwnerClaim) {
+		if len(owner) == 0 {
+			continue
+		}
+		// the first owner is the current supplier
+		if len(userInfo.OwnerUinArr) == 0 {
+			userInfo.OnwerUin = owner
+		}
+		userInfo.OwnerUinArr = append(userInfo.OwnerUinArr, metadata.LoginUserInfoOwnerUinList{
+			OwnerID:   owner,
+			OwnerName: owner,
+		})
+	}
+	userInfo.MultiSupplier = len(userInfo.OwnerUinArr) > 1
+	return userInfo, nil
+}
+
+// mapLanguage converts the locale claim like zh-CN, zh_Hans or en-US to the language of cmdb
+func mapLanguage(locale string) string {
+	locale = strings.ToLower(locale)
+	switch {
+	case strings.HasPrefix(locale, "zh"):
+		return "zh-cn"
+	case strings.HasPrefix(locale, "en"):
+		return "en"
+	}
+	return ""
+}
+
+func randomString(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// GetLoginUrl returns the authorization url of the provider and keeps the state, nonce and pkce code verifier
+// in session. When the user is logging out, it returns the logout url of the provider instead.
+func (m *user) GetLoginUrl(c *gin.Context, config map[string]string, input *metadata.LogoutRequestParams) string {
+	rid := util.GetHTTPCCRequestID(c.Request.Header)
+	p, err := m.getProvider()
+	if err != nil {
+		blog.Errorf("oidc login config is invalid, err: %v, rid: %s", err, rid)
+		siteURL, _ := cc.String("webServer.site.domainUrl")
+		return siteURL
+	}
+
+	if logout, _ := c.Get(webCommon.LogoutContextKey); logout == true {
+		return p.logoutURL()
+	}
+
+	state, err := randomString(32)
+	if err != nil {
+		blog.Errorf("generate oidc state failed, err: %v, rid: %s", err, rid)
+		return p.conf.PostLogoutRedirectURL
+	}
+	nonce, err := randomString(32)
+	if err != nil {
+		blog.Errorf("generate oidc nonce failed, err: %v, rid: %s", err, rid)
+		return p.conf.PostLogoutRedirectURL
+	}
+	codeVerifier, err := randomString(32)
+	if err != nil {
+		blog.Errorf("generate oidc code verifier failed, err: %v, rid: %s", err, rid)
+		return p.conf.PostLogoutRedirectURL
+	}
+
+	session := sessions.Default(c)
+	session.Set(sessionStateKey, state)
+	session.Set(sessionNonceKey, nonce)
+	session.Set(sessionCodeVerifierKey, codeVerifier)
+	// the page is opened again after login
+	if c.Request.Method == http.MethodGet && !strings.HasPrefix(c.Request.URL.Path, "/login") {
+		session.Set(webCommon.LoginRedirectSessionKey, c.Request.URL.RequestURI())
+	}
+	if err := session.Save(); err != nil {
+		blog.Errorf("save oidc state to session failed, err: %v, rid: %s", err, rid)
+	}
+
+	return p.authCodeURL(state, nonce, codeChallenge(codeVerifier))
+}
+
+// codeChallenge is the S256 pkce code challenge of the code verifier
+func codeChallenge(codeVerifier string) string {
+	sum := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// GetUserList returns the current login user, the users of the identity provider can not be listed with oidc.
+func (m *user) GetUserList(c *gin.Context, config map[string]string) ([]*metadata.LoginSystemUserInfo, *ccErr.RawErrorInfo) {
+	session := sessions.Default(c)
+	users := make([]*metadata.LoginSystemUserInfo, 0)
+	userName, _ := session.Get(common.WEBSessionUinKey).(string)
+	if len(userName) == 0 {
+		return users, nil
+	}
+	chName, _ := session.Get(common.WEBSessionChineseNameKey).(string)
+	if len(chName) == 0 {
+		chName = userName
+	}
+	users = append(users, &metadata.LoginSystemUserInfo{
+		CnName: chName,
+		EnName: userName,
+	})
+	return users, nil
+}
@@ -54,6 +54,11 @@ func (m *publicUser) LoginUser(c *gin.Context) bool {
 		blog.Infof("login user with plugin failed, rid: %s", rid)
 		return false
 	}
+	// the names with the service account prefix are reserved for the api tokens of service accounts
+	if metadata.IsServiceAccount(userInfo.UserName) {
+		blog.Errorf("user %s uses the reserved service account prefix, can not login, rid: %s", userInfo.UserName, rid)
+		return false
+	}
 	if true == isMultiOwner || true == userInfo.MultiSupplier {
 		ownerM := NewOwnerManager(userInfo.UserName, userInfo.OnwerUin, userInfo.Language)
 		ownerM.CacheCli = m.cacheCli
@@ -13,6 +13,7 @@
 package service
 
 import (
+	"net/http"
 	"strings"
 	"time"
 
@@ -21,6 +22,7 @@ import (
 	"configcenter/src/common/blog"
 	"configcenter/src/common/metadata"
 	"configcenter/src/common/util"
+	webCommon "configcenter/src/web_server/common"
 	"configcenter/src/web_server/middleware/user"
 
 	"github.com/gin-gonic/gin"
@@ -32,6 +34,7 @@ func (s *Service) LogOutUser(c *gin.Context) {
 	session := sessions.Default(c)
 	session.Clear()
 	c.Request.URL.Path = ""
+	c.Set(webCommon.LogoutContextKey, true)
 	userManger := user.NewUser(*s.Config, s.Engine, s.CacheCli)
 	loginURL := userManger.GetLoginUrl(c)
 	ret := metadata.LogoutResult{}
@@ -43,9 +46,38 @@ func (s *Service) LogOutUser(c *gin.Context) {
 
 // Login html file
 func (s *Service) Login(c *gin.Context) {
+	// oidc users login at the identity provider, the site redirects to it
+	if s.Config.LoginVersion == common.BKOIDCLoginPluginVersion {
+		c.Redirect(302, s.Config.Site.DomainUrl)
+		return
+	}
 	c.HTML(200, "login.html", gin.H{})
 }
 
+// LoginCallback is redirected to by the single sign-on login system after the user is authenticated
+func (s *Service) LoginCallback(c *gin.Context) {
+	defErr := s.CCErr.CreateDefaultCCErrorIf(webCommon.GetLanguageByHTTPRequest(c))
+	userManger := user.NewUser(*s.Config, s.Engine, s.CacheCli)
+	if !userManger.LoginUser(c) {
+		c.String(http.StatusUnauthorized, defErr.CCError(common.CCErrWebOIDCLoginFailed).Error())
+		return
+	}
+
+	redirectURL := s.Config.Site.DomainUrl
+	session := sessions.Default(c)
+	if page, ok := session.Get(webCommon.LoginRedirectSessionKey).(string); ok {
+		// only the page of this site is opened
+		if strings.HasPrefix(page, "/") && !strings.HasPrefix(page, "//") {
+			redirectURL = strings.TrimSuffix(s.Config.Site.DomainUrl, "/") + page
+		}
+		session.Delete(webCommon.LoginRedirectSessionKey)
+		if err := session.Save(); err != nil {
+			blog.Warnf("save session failed, err: %v, rid: %s", err, util.GetHTTPCCRequestID(c.Request.Header))
+		}
+	}
+	c.Redirect(302, redirectURL)
+}
+
 // LoginUser log in user
 func (s *Service) LoginUser(c *gin.Context) {
 	rid := util.GetHTTPCCRequestID(c.Request.Header)
@@ -56,7 +88,22 @@ func (s *Service) LoginUser(c *gin.Context) {
 		c.HTML(200, "login.html", gin.H{
 			"error": defErr.CCError(common.CCErrWebNeedFillinUsernamePasswd).Error(),
 		})
+		return
 	}
+
+	// ldap users are authenticated by the login plugin with the directory
+	if s.Config.LoginVersion == common.BKLDAPLoginPluginVersion {
+		userManger := user.NewUser(*s.Config, s.Engine, s.CacheCli)
+		if !userManger.LoginUser(c) {
+			c.HTML(200, "login.html", gin.H{
+				"error": defErr.CCError(common.CCErrWebUsernamePasswdWrong).Error(),
+			})
+			return
+		}
+		c.Redirect(302, s.loginRedirectURL(c))
+		return
+	}
+
 	userInfo, err := cc.String("webServer.session.userInfo")
 	if err != nil {
 		c.HTML(200, "login.html", gin.H{
@@ -83,13 +130,7 @@ func (s *Service) LoginUser(c *gin.Context) {
 			}
 			userManger := user.NewUser(*s.Config, s.Engine, s.CacheCli)
 			userManger.LoginUser(c)
-			var redirectURL string
-			if c.Param("c_url") != "" {
-				redirectURL = c.Param("c_url")
-			} else {
-				redirectURL = s.Config.Site.DomainUrl
-			}
-			c.Redirect(302, redirectURL)
+			c.Redirect(302, s.loginRedirectURL(c))
 			return
 		}
 	}
@@ -98,3 +139,11 @@ func (s *Service) LoginUser(c *gin.Context) {
 	})
 	return
 }
+
+// loginRedirectURL returns the url to redirect to after login
+func (s *Service) loginRedirectURL(c *gin.Context) string {
+	if c.Param("c_url") != "" {
+		return c.Param("c_url")
+	}
+	return s.Config.Site.DomainUrl
+}